@@ -0,0 +1,239 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+// ClusterConfirmations is the number of confirmations a block must have before
+// its transactions are used to link addresses. Clusters cannot be split when a
+// block is orphaned, so only the blocks unlikely to be reorganized out of the
+// main chain are processed.
+const ClusterConfirmations = 6
+
+// ClusterStore is the storage of the address clusters, and the source of the
+// mainchain blocks' transaction inputs.
+type ClusterStore interface {
+	Height() int64
+	BlockHash(height int64) (string, error)
+	AddressClusterTip() (int64, string, error)
+	ClusterInputs(height int64) ([][]string, error)
+	AddressClusterIDs(addrs []string) (map[string]int64, error)
+	StoreClusterBlock(block *dbtypes.ClusterBlock) error
+	AddressCluster(address string) (*apitypes.AddressCluster, error)
+}
+
+// AddressClusters links addresses into clusters with the common-input-ownership
+// heuristic: the addresses spent by the inputs of a transaction are assumed to
+// be owned by the same wallet. Stake transactions and CSPP mix transactions,
+// which have inputs from many wallets, are not used.
+type AddressClusters struct {
+	store  ClusterStore
+	update chan struct{}
+}
+
+// NewAddressClusters creates an AddressClusters with the ClusterStore.
+func NewAddressClusters(store ClusterStore) *AddressClusters {
+	return &AddressClusters{
+		store:  store,
+		update: make(chan struct{}, 1),
+	}
+}
+
+// Store signals Run to process the blocks up to the new block. Store satisfies
+// blockdata.BlockDataSaver.
+func (c *AddressClusters) Store(_ *blockdata.BlockData, _ *wire.MsgBlock) error {
+	select {
+	case c.update <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run processes the blocks not yet processed, and then the new blocks as they
+// are signaled by Store, until the context is canceled.
+func (c *AddressClusters) Run(ctx context.Context) {
+	for {
+		if err := c.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Failed to update address clusters: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.update:
+		}
+	}
+}
+
+// sync processes the blocks with at least ClusterConfirmations confirmations.
+func (c *AddressClusters) sync(ctx context.Context) error {
+	height, hash, err := c.store.AddressClusterTip()
+	if err != nil {
+		return err
+	}
+	if height >= 0 {
+		mainHash, err := c.store.BlockHash(height)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if mainHash != hash {
+			log.Warnf("Address clusters include the links of side chain block %s "+
+				"at height %d.", hash, height)
+		}
+	}
+
+	last := c.store.Height() - ClusterConfirmations + 1
+	if last-height > 1000 {
+		log.Infof("Clustering addresses for blocks %d to %d...", height+1, last)
+	}
+	for h := height + 1; h <= last; h++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		hash, err := c.store.BlockHash(h)
+		if err != nil {
+			return err
+		}
+		inputs, err := c.store.ClusterInputs(h)
+		if err != nil {
+			return err
+		}
+
+		var addrs []string
+		for _, txAddrs := range inputs {
+			if len(txAddrs) > 1 {
+				addrs = append(addrs, txAddrs...)
+			}
+		}
+		var ids map[string]int64
+		if len(addrs) > 0 {
+			if ids, err = c.store.AddressClusterIDs(addrs); err != nil {
+				return err
+			}
+		}
+
+		err = c.store.StoreClusterBlock(&dbtypes.ClusterBlock{
+			Height: h,
+			Hash:   hash,
+			Merges: clusterMerges(inputs, ids),
+		})
+		if err != nil {
+			return err
+		}
+
+		if h%10000 == 0 && h < last {
+			log.Infof("Clustered addresses up to block %d.", h)
+		}
+	}
+	return nil
+}
+
+// unionFind is a disjoint-set forest of strings.
+type unionFind map[string]string
+
+// find returns the root of the set of x, compressing the path to it.
+func (uf unionFind) find(x string) string {
+	parent, ok := uf[x]
+	if !ok {
+		uf[x] = x
+		return x
+	}
+	if parent == x {
+		return x
+	}
+	root := uf.find(parent)
+	uf[x] = root
+	return root
+}
+
+// union merges the sets of x and y.
+func (uf unionFind) union(x, y string) {
+	rx, ry := uf.find(x), uf.find(y)
+	if rx != ry {
+		uf[ry] = rx
+	}
+}
+
+// clusterMerges computes the cluster changes that link the input addresses of
+// each transaction, given the cluster ids of the addresses already clustered.
+// The addresses of a transaction, and the clusters they are in, are merged
+// into the cluster with the lowest id, or a new cluster if none of the
+// addresses are clustered. Transactions with a single input address are
+// ignored. The merges are in a deterministic order.
+func clusterMerges(inputs [][]string, ids map[string]int64) []dbtypes.ClusterMerge {
+	uf := make(unionFind)
+	for _, txAddrs := range inputs {
+		if len(txAddrs) < 2 {
+			continue
+		}
+		for _, addr := range txAddrs[1:] {
+			uf.union(txAddrs[0], addr)
+		}
+	}
+
+	// Group the addresses by the root of their set, splitting the clustered
+	// addresses into their cluster ids.
+	type component struct {
+		clusters  map[int64]struct{}
+		addresses []string
+	}
+	components := make(map[string]*component)
+	for addr := range uf {
+		root := uf.find(addr)
+		comp := components[root]
+		if comp == nil {
+			comp = &component{clusters: make(map[int64]struct{})}
+			components[root] = comp
+		}
+		if id, ok := ids[addr]; ok {
+			comp.clusters[id] = struct{}{}
+			continue
+		}
+		comp.addresses = append(comp.addresses, addr)
+	}
+
+	var merges []dbtypes.ClusterMerge
+	for _, comp := range components {
+		clusters := make([]int64, 0, len(comp.clusters))
+		for id := range comp.clusters {
+			clusters = append(clusters, id)
+		}
+		// The addresses are already in a single cluster.
+		if len(clusters) == 1 && len(comp.addresses) == 0 {
+			continue
+		}
+		sort.Slice(clusters, func(i, j int) bool { return clusters[i] < clusters[j] })
+		sort.Strings(comp.addresses)
+
+		merge := dbtypes.ClusterMerge{Addresses: comp.addresses}
+		if len(clusters) > 0 {
+			merge.Target = clusters[0]
+			merge.Merged = clusters[1:]
+		}
+		merges = append(merges, merge)
+	}
+
+	sort.Slice(merges, func(i, j int) bool {
+		mi, mj := &merges[i], &merges[j]
+		if mi.Target != mj.Target {
+			return mi.Target < mj.Target
+		}
+		return mi.Addresses[0] < mj.Addresses[0]
+	})
+	return merges
+}
+
+// AddressCluster retrieves the cluster of the address and the unspent value of
+// the cluster's addresses.
+func (c *AddressClusters) AddressCluster(address string) (*apitypes.AddressCluster, error) {
+	return c.store.AddressCluster(address)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+func TestClusterMerges(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs [][]string
+		ids    map[string]int64
+		want   []dbtypes.ClusterMerge
+	}{
+		{
+			name:   "single input addresses",
+			inputs: [][]string{{"a"}, {"b"}},
+		},
+		{
+			name:   "new cluster",
+			inputs: [][]string{{"b", "a"}},
+			want: []dbtypes.ClusterMerge{
+				{Addresses: []string{"a", "b"}},
+			},
+		},
+		{
+			name:   "transitive link",
+			inputs: [][]string{{"a", "b"}, {"c", "b"}, {"d", "e"}},
+			want: []dbtypes.ClusterMerge{
+				{Addresses: []string{"a", "b", "c"}},
+				{Addresses: []string{"d", "e"}},
+			},
+		},
+		{
+			name:   "add to cluster",
+			inputs: [][]string{{"a", "b"}},
+			ids:    map[string]int64{"a": 7},
+			want: []dbtypes.ClusterMerge{
+				{Target: 7, Merged: []int64{}, Addresses: []string{"b"}},
+			},
+		},
+		{
+			name:   "already clustered",
+			inputs: [][]string{{"a", "b"}},
+			ids:    map[string]int64{"a": 7, "b": 7},
+		},
+		{
+			name:   "merge clusters",
+			inputs: [][]string{{"a", "b"}, {"b", "c", "d"}},
+			ids:    map[string]int64{"a": 9, "c": 4, "d": 4},
+			want: []dbtypes.ClusterMerge{
+				{Target: 4, Merged: []int64{9}, Addresses: []string{"b"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clusterMerges(tt.inputs, tt.ids)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clusterMerges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// memClusterStore is a ClusterStore of a chain of blocks in which block i
+// spends the addresses in inputs[i], with the clusters kept in memory.
+type memClusterStore struct {
+	hashes  []string
+	inputs  [][][]string
+	tip     int64
+	nextID  int64
+	members map[string]int64
+}
+
+func (s *memClusterStore) Height() int64 { return int64(len(s.hashes)) - 1 }
+
+func (s *memClusterStore) BlockHash(height int64) (string, error) {
+	if height >= int64(len(s.hashes)) {
+		return "", sql.ErrNoRows
+	}
+	return s.hashes[height], nil
+}
+
+func (s *memClusterStore) AddressClusterTip() (int64, string, error) {
+	if s.tip < 0 {
+		return -1, "", nil
+	}
+	return s.tip, s.hashes[s.tip], nil
+}
+
+func (s *memClusterStore) ClusterInputs(height int64) ([][]string, error) {
+	return s.inputs[height], nil
+}
+
+func (s *memClusterStore) AddressClusterIDs(addrs []string) (map[string]int64, error) {
+	ids := make(map[string]int64)
+	for _, addr := range addrs {
+		if id, ok := s.members[addr]; ok {
+			ids[addr] = id
+		}
+	}
+	return ids, nil
+}
+
+func (s *memClusterStore) StoreClusterBlock(block *dbtypes.ClusterBlock) error {
+	for _, merge := range block.Merges {
+		target := merge.Target
+		if target == 0 {
+			s.nextID++
+			target = s.nextID
+		}
+		for addr, id := range s.members {
+			for _, merged := range merge.Merged {
+				if id == merged {
+					s.members[addr] = target
+				}
+			}
+		}
+		for _, addr := range merge.Addresses {
+			s.members[addr] = target
+		}
+	}
+	s.tip = block.Height
+	return nil
+}
+
+func (s *memClusterStore) AddressCluster(address string) (*apitypes.AddressCluster, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestAddressClustersSync(t *testing.T) {
+	store := &memClusterStore{
+		inputs: [][][]string{
+			{{"a", "b"}},
+			{{"c", "d"}},
+			{{"b", "c"}, {"e"}},
+			{{"e", "f"}},
+		},
+		tip:     -1,
+		members: make(map[string]int64),
+	}
+	// Only the first two blocks have ClusterConfirmations confirmations.
+	for i := 0; i < ClusterConfirmations+1; i++ {
+		store.hashes = append(store.hashes, fmt.Sprintf("main%d", i))
+		store.inputs = append(store.inputs, nil)
+	}
+
+	c := NewAddressClusters(store)
+	if err := c.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if store.tip != 1 {
+		t.Fatalf("processed up to block %d, want 1", store.tip)
+	}
+	want := map[string]int64{"a": 1, "b": 1, "c": 2, "d": 2}
+	if !reflect.DeepEqual(store.members, want) {
+		t.Fatalf("clusters %v, want %v", store.members, want)
+	}
+
+	// Two more blocks are confirmed, linking the clusters of block 0 and 1.
+	store.hashes = append(store.hashes, "main7", "main8")
+	store.inputs = append(store.inputs, nil, nil)
+	if err := c.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if store.tip != 3 {
+		t.Fatalf("processed up to block %d, want 3", store.tip)
+	}
+	want = map[string]int64{"a": 1, "b": 1, "c": 1, "d": 1, "e": 3, "f": 3}
+	if !reflect.DeepEqual(store.members, want) {
+		t.Fatalf("clusters %v, want %v", store.members, want)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package analytics materializes chain analytics in the database block by
+// block: the coin days destroyed by each block, the distribution of the
+// unspent value by the age of the outputs, and the clusters of addresses
+// linked by the common-input-ownership heuristic. It also records the history
+// of the transactions seen in mempool.
+package analytics
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const secondsPerDay = 86400
+
+// Band is a range of coin ages. MaxDays is the exclusive upper limit of the
+// ages in the band, or zero if there is no limit.
+type Band struct {
+	Label   string
+	MaxDays int64
+}
+
+// Bands are the coin age bands of the unspent value distribution, in
+// ascending order of age.
+var Bands = []Band{
+	{"<1d", 1},
+	{"1d-1w", 7},
+	{"1w-1m", 30},
+	{"1m-3m", 91},
+	{"3m-6m", 182},
+	{"6m-1y", 365},
+	{"1y-2y", 730},
+	{"2y-3y", 1095},
+	{"3y-5y", 1826},
+	{">5y", 0},
+}
+
+// bandIndex is the index of the band of the age, in days.
+func bandIndex(ageDays int64) int {
+	for i, b := range Bands {
+		if b.MaxDays == 0 || ageDays < b.MaxDays {
+			return i
+		}
+	}
+	return len(Bands) - 1
+}
+
+// dayStart is the UNIX time of the start of the day (UTC) of the UNIX time t.
+func dayStart(t int64) int64 {
+	return t - t%secondsPerDay
+}
+
+// Store is the storage of the coin age records, and the source of the
+// mainchain blocks' flows of value.
+type Store interface {
+	Height() int64
+	BlockHash(height int64) (string, error)
+	CoinAgeTip() (int64, string, dbtypes.TimeDef, error)
+	CoinAgeFlows(height int64) (*dbtypes.CoinAgeFlows, error)
+	StoreCoinAgeBlock(block *dbtypes.CoinAgeBlock) error
+	CoinAgeDays(height int64) (map[int64]int64, error)
+	StoreCoinAgeBands(bands *dbtypes.CoinAgeBands) error
+	PurgeCoinAgeAbove(height int64) error
+	CoinDaysDestroyed(grouping dbtypes.TimeBasedGrouping) (*apitypes.CoinDaysDestroyed, error)
+	CoinAgeBands() (*apitypes.CoinAgeBands, error)
+}
+
+// CoinAge computes the coin days destroyed by each mainchain block, and the
+// change to the unspent value created on each day, from which the unspent
+// value in each coin age band is snapshotted at the first block of each day.
+// The blocks are processed one behind the best block since the next block
+// decides the validity of a block's regular transactions.
+type CoinAge struct {
+	store  Store
+	update chan struct{}
+}
+
+// NewCoinAge creates a CoinAge with the Store.
+func NewCoinAge(store Store) *CoinAge {
+	return &CoinAge{
+		store:  store,
+		update: make(chan struct{}, 1),
+	}
+}
+
+// Store signals Run to process the blocks up to the new block. Store satisfies
+// blockdata.BlockDataSaver.
+func (c *CoinAge) Store(_ *blockdata.BlockData, _ *wire.MsgBlock) error {
+	select {
+	case c.update <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run processes the blocks not yet processed, and then the new blocks as they
+// are signaled by Store, until the context is canceled.
+func (c *CoinAge) Run(ctx context.Context) {
+	for {
+		if err := c.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Failed to update coin age: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.update:
+		}
+	}
+}
+
+// sync removes the records of the processed blocks that are no longer in the
+// main chain, and then processes the blocks up to the block before the best
+// block.
+func (c *CoinAge) sync(ctx context.Context) error {
+	height, hash, lastTime, err := c.store.CoinAgeTip()
+	if err != nil {
+		return err
+	}
+	for height >= 0 {
+		mainHash, err := c.store.BlockHash(height)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if mainHash == hash {
+			break
+		}
+		log.Infof("Removing coin age records of side chain block %s at height %d.",
+			hash, height)
+		if err = c.store.PurgeCoinAgeAbove(height - 1); err != nil {
+			return err
+		}
+		if height, hash, lastTime, err = c.store.CoinAgeTip(); err != nil {
+			return err
+		}
+	}
+
+	last := c.store.Height() - 1
+	if last-height > 1000 {
+		log.Infof("Computing coin age for blocks %d to %d...", height+1, last)
+	}
+	for h := height + 1; h <= last; h++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		flows, err := c.store.CoinAgeFlows(h)
+		if err != nil {
+			return err
+		}
+		block := coinAgeBlock(flows)
+		if err = c.store.StoreCoinAgeBlock(block); err != nil {
+			return err
+		}
+
+		if dayStart(block.Time.UNIX()) != dayStart(lastTime.UNIX()) {
+			days, err := c.store.CoinAgeDays(h)
+			if err != nil {
+				return err
+			}
+			err = c.store.StoreCoinAgeBands(&dbtypes.CoinAgeBands{
+				Height: h,
+				Time:   block.Time,
+				Bands:  ageBands(days, block.Time.UNIX()),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		lastTime = block.Time
+
+		if h%10000 == 0 && h < last {
+			log.Infof("Computed coin age up to block %d.", h)
+		}
+	}
+	return nil
+}
+
+// coinAgeBlock computes the value spent, the coin days destroyed, and the
+// changes to the unspent value created on each day by the block's flows.
+func coinAgeBlock(flows *dbtypes.CoinAgeFlows) *dbtypes.CoinAgeBlock {
+	blockTime := flows.Time.UNIX()
+	block := &dbtypes.CoinAgeBlock{
+		Height:    flows.Height,
+		Hash:      flows.Hash,
+		Time:      flows.Time,
+		DayDeltas: make(map[int64]int64),
+	}
+	block.DayDeltas[dayStart(blockTime)] += flows.Created
+	for _, in := range flows.Spent {
+		fundingTime := in.FundingTime.UNIX()
+		block.Spent += in.Value
+		block.DayDeltas[dayStart(fundingTime)] -= in.Value
+		// Block times are not strictly increasing.
+		if age := blockTime - fundingTime; age > 0 {
+			block.CoinDaysDestroyed += dcrutil.Amount(in.Value).ToCoin() *
+				float64(age) / secondsPerDay
+		}
+	}
+	for day, delta := range block.DayDeltas {
+		if delta == 0 {
+			delete(block.DayDeltas, day)
+		}
+	}
+	return block
+}
+
+// ageBands sums the unspent value created on each day by the band of the
+// value's age at the UNIX time t.
+func ageBands(days map[int64]int64, t int64) []int64 {
+	bands := make([]int64, len(Bands))
+	today := dayStart(t)
+	for day, value := range days {
+		age := (today - day) / secondsPerDay
+		if age < 0 {
+			age = 0
+		}
+		bands[bandIndex(age)] += value
+	}
+	return bands
+}
+
+// CoinDaysDestroyed retrieves the value spent and the coin days destroyed in
+// each period of the time grouping.
+func (c *CoinAge) CoinDaysDestroyed(grouping dbtypes.TimeBasedGrouping) (*apitypes.CoinDaysDestroyed, error) {
+	return c.store.CoinDaysDestroyed(grouping)
+}
+
+// AgeBands retrieves the daily snapshots of the unspent value in each coin age
+// band.
+func (c *CoinAge) AgeBands() (*apitypes.CoinAgeBands, error) {
+	bands, err := c.store.CoinAgeBands()
+	if err != nil {
+		return nil, err
+	}
+	bands.Bands = make([]string, len(Bands))
+	for i, b := range Bands {
+		bands.Bands[i] = b.Label
+	}
+	return bands, nil
+}
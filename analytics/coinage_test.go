@@ -0,0 +1,201 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+// t0 is the start of a day.
+var t0 = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+// memStore is a Store of a chain of blocks 12 hours apart, each creating 1 DCR
+// and spending the output of the previous block.
+type memStore struct {
+	hashes []string
+	blocks []*dbtypes.CoinAgeBlock
+	bands  []*dbtypes.CoinAgeBands
+}
+
+func newMemStore(n int) *memStore {
+	s := new(memStore)
+	for i := 0; i < n; i++ {
+		s.hashes = append(s.hashes, fmt.Sprintf("main%d", i))
+	}
+	return s
+}
+
+func (s *memStore) blockTime(height int64) int64 {
+	return t0 + height*secondsPerDay/2
+}
+
+func (s *memStore) Height() int64 { return int64(len(s.hashes)) - 1 }
+
+func (s *memStore) BlockHash(height int64) (string, error) {
+	if height >= int64(len(s.hashes)) {
+		return "", sql.ErrNoRows
+	}
+	return s.hashes[height], nil
+}
+
+func (s *memStore) CoinAgeTip() (int64, string, dbtypes.TimeDef, error) {
+	if len(s.blocks) == 0 {
+		return -1, "", dbtypes.TimeDef{}, nil
+	}
+	b := s.blocks[len(s.blocks)-1]
+	return b.Height, b.Hash, b.Time, nil
+}
+
+func (s *memStore) CoinAgeFlows(height int64) (*dbtypes.CoinAgeFlows, error) {
+	flows := &dbtypes.CoinAgeFlows{
+		Height:  height,
+		Hash:    s.hashes[height],
+		Time:    dbtypes.NewTimeDefFromUNIX(s.blockTime(height)),
+		Created: 1e8,
+	}
+	if height > 0 {
+		flows.Spent = []dbtypes.CoinAgeInput{{
+			Value:       1e8,
+			FundingTime: dbtypes.NewTimeDefFromUNIX(s.blockTime(height - 1)),
+		}}
+	}
+	return flows, nil
+}
+
+func (s *memStore) StoreCoinAgeBlock(block *dbtypes.CoinAgeBlock) error {
+	s.blocks = append(s.blocks, block)
+	return nil
+}
+
+func (s *memStore) CoinAgeDays(height int64) (map[int64]int64, error) {
+	days := make(map[int64]int64)
+	for _, b := range s.blocks {
+		if b.Height > height {
+			continue
+		}
+		for day, delta := range b.DayDeltas {
+			days[day] += delta
+		}
+	}
+	return days, nil
+}
+
+func (s *memStore) StoreCoinAgeBands(bands *dbtypes.CoinAgeBands) error {
+	s.bands = append(s.bands, bands)
+	return nil
+}
+
+func (s *memStore) PurgeCoinAgeAbove(height int64) error {
+	for len(s.blocks) > 0 && s.blocks[len(s.blocks)-1].Height > height {
+		s.blocks = s.blocks[:len(s.blocks)-1]
+	}
+	for len(s.bands) > 0 && s.bands[len(s.bands)-1].Height > height {
+		s.bands = s.bands[:len(s.bands)-1]
+	}
+	return nil
+}
+
+func (s *memStore) CoinDaysDestroyed(dbtypes.TimeBasedGrouping) (*apitypes.CoinDaysDestroyed, error) {
+	return nil, nil
+}
+
+func (s *memStore) CoinAgeBands() (*apitypes.CoinAgeBands, error) {
+	return new(apitypes.CoinAgeBands), nil
+}
+
+func TestCoinAgeBlock(t *testing.T) {
+	blockTime := t0 + 3*secondsPerDay + 3600
+	block := coinAgeBlock(&dbtypes.CoinAgeFlows{
+		Height:  10,
+		Hash:    "h",
+		Time:    dbtypes.NewTimeDefFromUNIX(blockTime),
+		Created: 5e8,
+		Spent: []dbtypes.CoinAgeInput{
+			{Value: 2e8, FundingTime: dbtypes.NewTimeDefFromUNIX(blockTime - 2*secondsPerDay)},
+			{Value: 3e8, FundingTime: dbtypes.NewTimeDefFromUNIX(t0)},
+			// Funded "after" the block by timestamp.
+			{Value: 1e8, FundingTime: dbtypes.NewTimeDefFromUNIX(blockTime + 60)},
+		},
+	})
+	if block.Spent != 6e8 {
+		t.Errorf("spent %d, expected %d", block.Spent, int64(6e8))
+	}
+	wantCDD := 2*2 + 3*(3+1.0/24)
+	if diff := block.CoinDaysDestroyed - wantCDD; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("coin days destroyed %v, expected %v", block.CoinDaysDestroyed, wantCDD)
+	}
+	wantDeltas := map[int64]int64{
+		t0 + secondsPerDay: -2e8,
+		t0:                 -3e8,
+		// +5e8 created and -1e8 spent on the block's day.
+		t0 + 3*secondsPerDay: 4e8,
+	}
+	if !reflect.DeepEqual(block.DayDeltas, wantDeltas) {
+		t.Errorf("day deltas %v, expected %v", block.DayDeltas, wantDeltas)
+	}
+}
+
+func TestAgeBands(t *testing.T) {
+	now := t0 + 400*secondsPerDay + 10
+	days := map[int64]int64{
+		dayStart(now):                   1,
+		dayStart(now) - secondsPerDay:   2,
+		dayStart(now) - 6*secondsPerDay: 4,
+		dayStart(now) - 7*secondsPerDay: 8,
+		t0:                              16,
+	}
+	want := []int64{1, 6, 8, 0, 0, 0, 16, 0, 0, 0}
+	if got := ageBands(days, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("ageBands() = %v, expected %v", got, want)
+	}
+	if bandIndex(5000) != len(Bands)-1 {
+		t.Errorf("old coins not in the last band")
+	}
+}
+
+func TestSyncReorg(t *testing.T) {
+	store := newMemStore(6)
+	c := NewCoinAge(store)
+	if err := c.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// One block behind the best block.
+	if n := len(store.blocks); n != 5 {
+		t.Fatalf("%d blocks processed, expected 5", n)
+	}
+	// A snapshot at the first block of each day: heights 0, 2 and 4.
+	if n := len(store.bands); n != 3 {
+		t.Fatalf("%d snapshots, expected 3", n)
+	}
+	// Only the output created by block 4 is unspent.
+	want := []int64{1e8, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	if got := store.bands[2].Bands; !reflect.DeepEqual(got, want) {
+		t.Errorf("bands %v, expected %v", got, want)
+	}
+
+	// Reorganize blocks 3 and above.
+	store.hashes = append(store.hashes[:3], "side3", "side4", "side5", "side6")
+	if err := c.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(store.blocks); n != 6 {
+		t.Fatalf("%d blocks processed, expected 6", n)
+	}
+	for _, b := range store.blocks {
+		if b.Hash != store.hashes[b.Height] {
+			t.Errorf("block %d has hash %s, expected %s", b.Height, b.Hash, store.hashes[b.Height])
+		}
+	}
+	if n := len(store.bands); n != 3 {
+		t.Errorf("%d snapshots, expected 3", n)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+	"github.com/decred/dcrdata/mempool/v5"
+)
+
+// MempoolEvictionDelay is how long a transaction must be missing from mempool
+// without being found in a mainchain block before it is recorded as evicted.
+// The mempool is collected when a new block is connected, which may be before
+// the block is stored.
+const MempoolEvictionDelay = time.Hour
+
+// MempoolHistoryStore is the storage of the mempool history.
+type MempoolHistoryStore interface {
+	StoreMempoolTxs(txs []*dbtypes.MempoolHistoryTx, seenAt time.Time) error
+	ResolveMempoolTxs(seenAt, evictBefore time.Time) (mined, evicted int64, err error)
+	MempoolTxHistory(txid string) (*apitypes.MempoolTxHistory, error)
+	MempoolCongestion(grouping dbtypes.TimeBasedGrouping) (*apitypes.MempoolCongestion, error)
+}
+
+// MempoolHistory records each transaction seen in mempool, when it was first
+// and last seen, its fee rate, and its fate. New transactions are recorded as
+// they enter mempool, and the fates of the transactions that left mempool are
+// resolved with each collection of the full mempool, after a new block.
+type MempoolHistory struct {
+	store MempoolHistoryStore
+	now   func() time.Time
+
+	// mtx orders the updates, so that the last seen times only increase.
+	mtx sync.Mutex
+}
+
+// NewMempoolHistory creates a MempoolHistory with the MempoolHistoryStore.
+func NewMempoolHistory(store MempoolHistoryStore) *MempoolHistory {
+	return &MempoolHistory{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// historyTx converts a mempool transaction for the mempool history.
+func historyTx(tx *exptypes.MempoolTx) *dbtypes.MempoolHistoryTx {
+	fees, _ := dcrutil.NewAmount(tx.Fees)
+	feeRate, _ := dcrutil.NewAmount(tx.FeeRate)
+	return &dbtypes.MempoolHistoryTx{
+		TxHash:    tx.Hash,
+		TxType:    tx.Type,
+		FirstSeen: tx.Time,
+		Size:      tx.Size,
+		Fees:      int64(fees),
+		FeeRate:   int64(feeRate),
+	}
+}
+
+// StoreMPTx records a transaction that entered mempool. StoreMPTx satisfies
+// mempool.MempoolTxSaver.
+func (h *MempoolHistory) StoreMPTx(tx *exptypes.MempoolTx) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	err := h.store.StoreMempoolTxs([]*dbtypes.MempoolHistoryTx{historyTx(tx)}, h.now())
+	if err != nil {
+		log.Errorf("Failed to record mempool transaction %s: %v", tx.Hash, err)
+	}
+}
+
+// StoreMPData records the transactions in mempool, and resolves the fates of
+// the transactions that are no longer in mempool. StoreMPData satisfies
+// mempool.MempoolDataSaver.
+func (h *MempoolHistory) StoreMPData(_ *mempool.StakeData, txs []exptypes.MempoolTx, _ *exptypes.MempoolInfo) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	seenAt := h.now()
+	historyTxs := make([]*dbtypes.MempoolHistoryTx, 0, len(txs))
+	for i := range txs {
+		historyTxs = append(historyTxs, historyTx(&txs[i]))
+	}
+	if err := h.store.StoreMempoolTxs(historyTxs, seenAt); err != nil {
+		log.Errorf("Failed to record mempool transactions: %v", err)
+		return
+	}
+
+	mined, evicted, err := h.store.ResolveMempoolTxs(seenAt, seenAt.Add(-MempoolEvictionDelay))
+	if err != nil {
+		log.Errorf("Failed to resolve mempool transactions: %v", err)
+		return
+	}
+	log.Debugf("Mempool history: %d transactions in mempool, %d mined, %d evicted.",
+		len(historyTxs), mined, evicted)
+}
+
+// MempoolTxHistory retrieves the mempool history of the transaction.
+func (h *MempoolHistory) MempoolTxHistory(txid string) (*apitypes.MempoolTxHistory, error) {
+	return h.store.MempoolTxHistory(txid)
+}
+
+// MempoolCongestion retrieves the mempool history aggregated by the period of
+// the time grouping in which the transactions were first seen.
+func (h *MempoolHistory) MempoolCongestion(grouping dbtypes.TimeBasedGrouping) (*apitypes.MempoolCongestion, error) {
+	return h.store.MempoolCongestion(grouping)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package analytics
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+)
+
+// memMempoolHistoryStore records the calls of a MempoolHistory.
+type memMempoolHistoryStore struct {
+	stored      [][]*dbtypes.MempoolHistoryTx
+	seenAt      []time.Time
+	resolvedAt  []time.Time
+	evictBefore []time.Time
+}
+
+func (s *memMempoolHistoryStore) StoreMempoolTxs(txs []*dbtypes.MempoolHistoryTx, seenAt time.Time) error {
+	s.stored = append(s.stored, txs)
+	s.seenAt = append(s.seenAt, seenAt)
+	return nil
+}
+
+func (s *memMempoolHistoryStore) ResolveMempoolTxs(seenAt, evictBefore time.Time) (int64, int64, error) {
+	s.resolvedAt = append(s.resolvedAt, seenAt)
+	s.evictBefore = append(s.evictBefore, evictBefore)
+	return 0, 0, nil
+}
+
+func (s *memMempoolHistoryStore) MempoolTxHistory(txid string) (*apitypes.MempoolTxHistory, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *memMempoolHistoryStore) MempoolCongestion(grouping dbtypes.TimeBasedGrouping) (*apitypes.MempoolCongestion, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestMempoolHistory(t *testing.T) {
+	store := new(memMempoolHistoryStore)
+	h := NewMempoolHistory(store)
+	now := time.Unix(1600000000, 0)
+	h.now = func() time.Time { return now }
+
+	txs := []exptypes.MempoolTx{
+		{Hash: "aa", Type: "Regular", Time: 1599999000, Size: 250, Fees: 0.0000253, FeeRate: 0.0001012},
+		{Hash: "bb", Type: "Ticket", Time: 1599999500, Size: 298, Fees: 0.0000298, FeeRate: 0.0001},
+	}
+	want := []*dbtypes.MempoolHistoryTx{
+		{TxHash: "aa", TxType: "Regular", FirstSeen: 1599999000, Size: 250, Fees: 2530, FeeRate: 10120},
+		{TxHash: "bb", TxType: "Ticket", FirstSeen: 1599999500, Size: 298, Fees: 2980, FeeRate: 10000},
+	}
+
+	// A new transaction is recorded without resolving the others.
+	h.StoreMPTx(&txs[0])
+	if len(store.stored) != 1 || !reflect.DeepEqual(store.stored[0], want[:1]) {
+		t.Fatalf("StoreMPTx stored %v, want %v", store.stored, want[:1])
+	}
+	if len(store.resolvedAt) != 0 {
+		t.Fatal("StoreMPTx resolved the mempool history")
+	}
+
+	// The full mempool is recorded, and the others are resolved.
+	now = now.Add(time.Minute)
+	h.StoreMPData(nil, txs, nil)
+	if len(store.stored) != 2 || !reflect.DeepEqual(store.stored[1], want) {
+		t.Fatalf("StoreMPData stored %v, want %v", store.stored[1:], want)
+	}
+	if !store.seenAt[1].Equal(now) {
+		t.Errorf("seen at %v, want %v", store.seenAt[1], now)
+	}
+	if len(store.resolvedAt) != 1 || !store.resolvedAt[0].Equal(now) {
+		t.Fatalf("resolved at %v, want %v", store.resolvedAt, now)
+	}
+	if evictBefore := now.Add(-MempoolEvictionDelay); !store.evictBefore[0].Equal(evictBefore) {
+		t.Errorf("evicting before %v, want %v", store.evictBefore[0], evictBefore)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	m "github.com/decred/dcrdata/middleware/v3"
+	"github.com/go-chi/chi"
+)
+
+const (
+	// maxAdminJobs is the number of recent jobs listed by /admin/sync/status.
+	maxAdminJobs = 20
+
+	adminTaskReindex    = "reindex-addresses"
+	adminTaskCachePurge = "purge-address-cache"
+
+	// maxTagLabelLen and maxTagCategoryLen limit the lengths of the label and
+	// category of an address label.
+	maxTagLabelLen    = 64
+	maxTagCategoryLen = 32
+)
+
+// AdminBackend performs the maintenance tasks started from the admin
+// endpoints.
+type AdminBackend interface {
+	ReindexAddressTable(barLoad chan *dbtypes.ProgressBarLoad) error
+	PurgeAddressCache() int
+	SetAddressTag(tag *dbtypes.AddressTag) error
+	DeleteAddressTag(address string) error
+}
+
+// adminContext is the context of the admin endpoint handlers, with the recent
+// jobs, most recent last.
+type adminContext struct {
+	app     *appContext
+	backend AdminBackend
+
+	mtx    sync.Mutex
+	jobs   []*apitypes.AdminJob
+	nextID int64
+}
+
+// NewAdminRouter creates the router for the admin endpoints, with which
+// operators monitor the sync and start maintenance tasks without restarting
+// dcrdata. Requests must have the token in an "Authorization: Bearer" header.
+func NewAdminRouter(app *appContext, backend AdminBackend, token, JSONIndent string,
+	useRealIP bool) *chi.Mux {
+	admin := &adminContext{
+		app:     app,
+		backend: backend,
+	}
+
+	mux := stackedMux(useRealIP)
+	mux.Use(requireBearerToken(token))
+	mux.Use(m.Indent(JSONIndent))
+
+	mux.Get("/sync/status", admin.syncStatus)
+	mux.Post("/reindex/start", admin.startReindex)
+	mux.Post("/cache/purge", admin.purgeCache)
+	mux.Route("/tags/{address}", func(r chi.Router) {
+		r.Use(m.AddressPathCtxN(1))
+		r.Put("/", admin.setAddressTag)
+		r.Delete("/", admin.deleteAddressTag)
+	})
+	return mux
+}
+
+// requireBearerToken is middleware that responds with 401 Unauthorized to
+// requests without the token in an "Authorization: Bearer" header.
+func requireBearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const prefix = "Bearer "
+			auth := r.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, prefix) ||
+				subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="dcrdata admin"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// newJob records a new running job of the task. If exclusive is set and a job
+// of the task is running, no job is created and the running job is returned
+// with false.
+func (a *adminContext) newJob(task string, exclusive bool) (*apitypes.AdminJob, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if exclusive {
+		for _, job := range a.jobs {
+			if job.Task == task && job.Finished == 0 {
+				jobCopy := *job
+				return &jobCopy, false
+			}
+		}
+	}
+
+	a.nextID++
+	job := &apitypes.AdminJob{
+		ID:      a.nextID,
+		Task:    task,
+		Started: time.Now().Unix(),
+	}
+	a.jobs = append(a.jobs, job)
+	if len(a.jobs) > maxAdminJobs {
+		a.jobs = a.jobs[len(a.jobs)-maxAdminJobs:]
+	}
+	jobCopy := *job
+	return &jobCopy, true
+}
+
+// updateJob sets the progress of the job with the given id.
+func (a *adminContext) updateJob(id int64, progress string) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, job := range a.jobs {
+		if job.ID == id {
+			job.Progress = progress
+			return
+		}
+	}
+}
+
+// finishJob marks the job with the given id as finished with the result, or
+// the error if it failed, and returns a copy of the job.
+func (a *adminContext) finishJob(id int64, result string, err error) *apitypes.AdminJob {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, job := range a.jobs {
+		if job.ID == id {
+			job.Finished = time.Now().Unix()
+			job.Progress = result
+			if err != nil {
+				job.Error = err.Error()
+			}
+			jobCopy := *job
+			return &jobCopy
+		}
+	}
+	return nil
+}
+
+// recentJobs returns copies of the recent jobs, most recent first.
+func (a *adminContext) recentJobs() []*apitypes.AdminJob {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	jobs := make([]*apitypes.AdminJob, 0, len(a.jobs))
+	for i := len(a.jobs) - 1; i >= 0; i-- {
+		jobCopy := *a.jobs[i]
+		jobs = append(jobs, &jobCopy)
+	}
+	return jobs
+}
+
+// syncStatus serves the node and database sync status, and the recent jobs.
+// GET /admin/sync/status
+func (a *adminContext) syncStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, &apitypes.AdminSyncStatus{
+		Status: a.app.Status.API(),
+		Jobs:   a.recentJobs(),
+	}, m.GetIndentCtx(r))
+}
+
+// startReindex starts reindexing the addresses table in the background, and
+// serves the new job with 202 Accepted. If a reindex is already running, the
+// running job is served with 409 Conflict.
+// POST /admin/reindex/start
+func (a *adminContext) startReindex(w http.ResponseWriter, r *http.Request) {
+	job, started := a.newJob(adminTaskReindex, true)
+	if !started {
+		writeJSONWithStatus(w, job, http.StatusConflict, m.GetIndentCtx(r))
+		return
+	}
+
+	apiLog.Infof("Admin job %d: reindexing the addresses table.", job.ID)
+	go func(id int64) {
+		barLoad := make(chan *dbtypes.ProgressBarLoad)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for load := range barLoad {
+				if subtitle := strings.TrimSpace(load.Subtitle); subtitle != "" {
+					a.updateJob(id, subtitle)
+				}
+			}
+		}()
+
+		err := a.backend.ReindexAddressTable(barLoad)
+		close(barLoad)
+		<-done
+
+		result := "Reindexed the addresses table."
+		if err != nil {
+			apiLog.Errorf("Admin job %d failed: %v", id, err)
+			result = "Reindexing failed."
+		} else {
+			apiLog.Infof("Admin job %d: reindexed the addresses table.", id)
+		}
+		a.finishJob(id, result, err)
+	}(job.ID)
+
+	writeJSONWithStatus(w, job, http.StatusAccepted, m.GetIndentCtx(r))
+}
+
+// purgeCache clears the address cache, and serves the finished job.
+// POST /admin/cache/purge
+func (a *adminContext) purgeCache(w http.ResponseWriter, r *http.Request) {
+	job, _ := a.newJob(adminTaskCachePurge, false)
+	numCleared := a.backend.PurgeAddressCache()
+	result := fmt.Sprintf("Purged %d addresses.", numCleared)
+	if finished := a.finishJob(job.ID, result, nil); finished != nil {
+		job = finished
+	}
+	writeJSON(w, job, m.GetIndentCtx(r))
+}
+
+// setAddressTag sets the label of the address from the JSON of an
+// apitypes.AddressTagRequest, and serves the stored label.
+// PUT /admin/tags/{address}
+func (a *adminContext) setAddressTag(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, a.app.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	var req apitypes.AddressTagRequest
+	if err = json.NewDecoder(io.LimitReader(r.Body, 1<<12)).Decode(&req); err != nil {
+		http.Error(w, "failed to unmarshal JSON request", http.StatusBadRequest)
+		return
+	}
+	tag := &dbtypes.AddressTag{
+		Address:  addresses[0],
+		Label:    strings.TrimSpace(req.Label),
+		Category: strings.ToLower(strings.TrimSpace(req.Category)),
+	}
+	if tag.Label == "" || len(tag.Label) > maxTagLabelLen ||
+		len(tag.Category) > maxTagCategoryLen {
+		http.Error(w, fmt.Sprintf("label must have 1 to %d characters and category "+
+			"at most %d", maxTagLabelLen, maxTagCategoryLen), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err = a.backend.SetAddressTag(tag); err != nil {
+		apiLog.Errorf("SetAddressTag(%s): %v", tag.Address, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	apiLog.Infof("Admin: labeled address %s %q (%s).", tag.Address, tag.Label, tag.Category)
+	writeJSON(w, tag, m.GetIndentCtx(r))
+}
+
+// deleteAddressTag deletes the label of the address, responding with 204 No
+// Content, or 404 if the address has no label.
+// DELETE /admin/tags/{address}
+func (a *adminContext) deleteAddressTag(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, a.app.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	err = a.backend.DeleteAddressTag(addresses[0])
+	if err == sql.ErrNoRows {
+		http.Error(w, "No label for the address.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("DeleteAddressTag(%s): %v", addresses[0], err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	apiLog.Infof("Admin: deleted the label of address %s.", addresses[0])
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const adminTestToken = "s3cret"
+
+// adminBackendStub satisfies AdminBackend. The methods not defined here panic.
+type adminBackendStub struct {
+	AdminBackend
+
+	tags map[string]*dbtypes.AddressTag
+	err  error
+
+	// ReindexAddressTable returns reindexErr after release is closed, so that
+	// the running job can be checked.
+	release    chan struct{}
+	reindexErr error
+	purged     int
+}
+
+func (b *adminBackendStub) ReindexAddressTable(barLoad chan *dbtypes.ProgressBarLoad) error {
+	barLoad <- &dbtypes.ProgressBarLoad{Subtitle: "Indexing addresses..."}
+	<-b.release
+	return b.reindexErr
+}
+
+func (b *adminBackendStub) PurgeAddressCache() int {
+	return b.purged
+}
+
+func (b *adminBackendStub) SetAddressTag(tag *dbtypes.AddressTag) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.tags[tag.Address] = tag
+	return nil
+}
+
+func (b *adminBackendStub) DeleteAddressTag(address string) error {
+	if b.err != nil {
+		return b.err
+	}
+	if _, ok := b.tags[address]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(b.tags, address)
+	return nil
+}
+
+// serveAdmin serves the admin request with the token, and decodes the JSON
+// response into v if it is not nil.
+func serveAdmin(t *testing.T, router http.Handler, method, path, token string, v interface{}) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if v != nil {
+		if err := json.Unmarshal(rr.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: invalid JSON %s: %v", method, path, rr.Body.String(), err)
+		}
+	}
+	return rr.Code
+}
+
+// waitAdminJob polls /sync/status until the job with the given id finishes.
+func waitAdminJob(t *testing.T, router http.Handler, id int64) *apitypes.AdminJob {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		var status apitypes.AdminSyncStatus
+		serveAdmin(t, router, http.MethodGet, "/sync/status", adminTestToken, &status)
+		for _, job := range status.Jobs {
+			if job.ID == id && job.Finished != 0 {
+				return job
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %d did not finish", id)
+	return nil
+}
+
+func TestAdminAuthorization(t *testing.T) {
+	app := &appContext{Status: apitypes.NewStatus(1, 8, APIVersion, "test", "mainnet")}
+	router := NewAdminRouter(app, new(adminBackendStub), adminTestToken, "", false)
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+	}{
+		{"token", "Bearer " + adminTestToken, http.StatusOK},
+		{"no header", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer guess", http.StatusUnauthorized},
+		{"token prefix", "Bearer " + adminTestToken[:3], http.StatusUnauthorized},
+		{"other scheme", "Basic " + adminTestToken, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/sync/status", nil)
+		if test.auth != "" {
+			req.Header.Set("Authorization", test.auth)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+		}
+		if rr.Code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected a WWW-Authenticate header", test.name)
+		}
+	}
+}
+
+func TestAdminReindex(t *testing.T) {
+	app := &appContext{Status: apitypes.NewStatus(1, 8, APIVersion, "test", "mainnet")}
+	backend := &adminBackendStub{release: make(chan struct{})}
+	router := NewAdminRouter(app, backend, adminTestToken, "", false)
+
+	var job apitypes.AdminJob
+	code := serveAdmin(t, router, http.MethodPost, "/reindex/start", adminTestToken, &job)
+	if code != http.StatusAccepted || job.ID != 1 || job.Task != adminTaskReindex {
+		t.Fatalf("expected job 1 accepted, got status %d, job %+v", code, job)
+	}
+
+	// Only one reindex runs at a time.
+	var running apitypes.AdminJob
+	code = serveAdmin(t, router, http.MethodPost, "/reindex/start", adminTestToken, &running)
+	if code != http.StatusConflict || running.ID != 1 || running.Finished != 0 {
+		t.Errorf("expected running job 1 with a conflict, got status %d, job %+v",
+			code, running)
+	}
+
+	close(backend.release)
+	finished := waitAdminJob(t, router, 1)
+	if finished.Error != "" || finished.Progress != "Reindexed the addresses table." {
+		t.Errorf("expected a successful reindex, got %+v", finished)
+	}
+
+	// A failed reindex records the error, and another reindex may start.
+	backend.reindexErr = errors.New("connection refused")
+	code = serveAdmin(t, router, http.MethodPost, "/reindex/start", adminTestToken, &job)
+	if code != http.StatusAccepted || job.ID != 2 {
+		t.Fatalf("expected job 2 accepted, got status %d, job %+v", code, job)
+	}
+	finished = waitAdminJob(t, router, 2)
+	if finished.Error != backend.reindexErr.Error() {
+		t.Errorf("expected error %q, got %q", backend.reindexErr, finished.Error)
+	}
+}
+
+func TestAdminCachePurge(t *testing.T) {
+	app := &appContext{Status: apitypes.NewStatus(1, 8, APIVersion, "test", "mainnet")}
+	backend := &adminBackendStub{purged: 7}
+	router := NewAdminRouter(app, backend, adminTestToken, "", false)
+
+	// Purges finish immediately, and are not exclusive.
+	for id := int64(1); id <= maxAdminJobs+5; id++ {
+		var job apitypes.AdminJob
+		code := serveAdmin(t, router, http.MethodPost, "/cache/purge", adminTestToken, &job)
+		if code != http.StatusOK || job.ID != id || job.Task != adminTaskCachePurge ||
+			job.Finished == 0 || job.Progress != "Purged 7 addresses." {
+			t.Fatalf("expected finished job %d, got status %d, job %+v", id, code, job)
+		}
+	}
+
+	// The most recent jobs are listed first.
+	var status apitypes.AdminSyncStatus
+	serveAdmin(t, router, http.MethodGet, "/sync/status", adminTestToken, &status)
+	if len(status.Jobs) != maxAdminJobs {
+		t.Fatalf("expected %d jobs, got %d", maxAdminJobs, len(status.Jobs))
+	}
+	for i, job := range status.Jobs {
+		if want := int64(maxAdminJobs + 5 - i); job.ID != want {
+			t.Errorf("expected job %d at %d, got job %d", want, i, job.ID)
+		}
+	}
+	if status.Status.NetworkName != "mainnet" || status.Status.Height != 1 {
+		t.Errorf("unexpected status %+v", status.Status)
+	}
+}
+
+func TestAdminAddressTags(t *testing.T) {
+	const taggedAddr = "DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87"
+
+	tests := []struct {
+		name       string
+		method     string
+		address    string
+		body       string
+		token      string
+		backendErr error
+		wantStatus int
+		// The expected label of the address after the request, as
+		// "label/category", or "" for none.
+		wantTag string
+	}{{
+		name:       "set",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": " Exchange A ", "category": " Exchange "}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusOK,
+		wantTag:    "Exchange A/exchange",
+	}, {
+		name:       "replace",
+		method:     http.MethodPut,
+		address:    taggedAddr,
+		body:       `{"label": "VSP fees", "category": "vsp"}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusOK,
+		wantTag:    "VSP fees/vsp",
+	}, {
+		name:       "wrong token",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": "Exchange A"}`,
+		token:      "guess",
+		wantStatus: http.StatusUnauthorized,
+	}, {
+		name:       "empty label",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": "  ", "category": "exchange"}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "long label",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": "` + strings.Repeat("x", maxTagLabelLen+1) + `"}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "long category",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": "x", "category": "` + strings.Repeat("x", maxTagCategoryLen+1) + `"}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "invalid JSON",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": `,
+		token:      adminTestToken,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid address",
+		method:     http.MethodPut,
+		address:    stubAddress,
+		body:       `{"label": "Exchange A"}`,
+		token:      adminTestToken,
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "set failed",
+		method:     http.MethodPut,
+		address:    stubMainnetAddress,
+		body:       `{"label": "Exchange A"}`,
+		token:      adminTestToken,
+		backendErr: errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+	}, {
+		name:       "delete",
+		method:     http.MethodDelete,
+		address:    taggedAddr,
+		token:      adminTestToken,
+		wantStatus: http.StatusNoContent,
+	}, {
+		name:       "delete missing",
+		method:     http.MethodDelete,
+		address:    stubMainnetAddress,
+		token:      adminTestToken,
+		wantStatus: http.StatusNotFound,
+	}, {
+		name:       "delete failed",
+		method:     http.MethodDelete,
+		address:    taggedAddr,
+		token:      adminTestToken,
+		backendErr: errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+		wantTag:    "Exchange B/exchange",
+	}}
+
+	for _, test := range tests {
+		backend := &adminBackendStub{
+			tags: map[string]*dbtypes.AddressTag{
+				taggedAddr: {Address: taggedAddr, Label: "Exchange B", Category: "exchange"},
+			},
+			err: test.backendErr,
+		}
+		app := &appContext{Params: chaincfg.MainNetParams()}
+		router := NewAdminRouter(app, backend, adminTestToken, "", false)
+
+		req := httptest.NewRequest(test.method, "/tags/"+test.address, strings.NewReader(test.body))
+		req.Header.Set("Authorization", "Bearer "+test.token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d (%s)", test.name, test.wantStatus,
+				rr.Code, strings.TrimSpace(rr.Body.String()))
+			continue
+		}
+		if test.address == stubAddress {
+			continue
+		}
+		var gotTag string
+		if tag := backend.tags[test.address]; tag != nil {
+			gotTag = tag.Label + "/" + tag.Category
+		}
+		if gotTag != test.wantTag {
+			t.Errorf("%s: expected label %q, got %q", test.name, test.wantTag, gotTag)
+		}
+	}
+}
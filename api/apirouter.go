@@ -11,7 +11,6 @@ import (
 	m "github.com/decred/dcrdata/middleware/v3"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
-	"github.com/rs/cors"
 )
 
 type apiMux struct {
@@ -23,11 +22,18 @@ type fileMux struct {
 }
 
 // NewAPIRouter creates a new HTTP request path router/mux for the given API,
-// appContext.
-func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge bool) apiMux {
+// appContext. If limiter is not nil, requests are rate limited by client IP
+// address or API key.
+func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge bool,
+	limiter *m.RateLimiter) apiMux {
 	// chi router
 	mux := stackedMux(useRealIP)
 
+	// Put the limiter after RealIP, which sets RemoteAddr.
+	if limiter != nil {
+		mux.Use(m.RateLimit(limiter))
+	}
+
 	// Check for and validate the "indent" URL query. Each API request handler
 	// may now access the configured indentation string if indent was specified
 	// and parsed as a boolean, otherwise the empty string, from
@@ -38,14 +44,19 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 
 	mux.Get("/status", app.status)
 	mux.Get("/status/happy", app.statusHappy)
+	mux.Get("/sync", app.SyncProgress)
 	mux.Get("/supply", app.coinSupply)
 	mux.Get("/supply/circulating", app.coinSupplyCirculating)
+	mux.Get("/supply/schedule", app.getSupplySchedule)
+	mux.Get("/utxoset/stats", app.getUTXOSetStats)
+	mux.Get("/chaininfo", app.getChainInfo)
 
 	compMiddleware := m.Next
 	if compressLarge {
 		log.Debug("Enabling compressed responses for large JSON payload endpoints.")
 		compMiddleware = middleware.Compress(3)
 	}
+	ioCompress := middleware.Compress(3, "text/csv", "application/json")
 
 	mux.Route("/block", func(r chi.Router) {
 		r.Route("/best", func(rd chi.Router) {
@@ -87,6 +98,9 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			})
 		})
 
+		r.With(m.BlockHashPathCtx).Get("/side/{blockhash}", app.getSideChainBlock)
+		r.Get("/timestamp-anomalies", app.getTimestampAnomalies)
+
 		r.Route("/{idx}", func(rd chi.Router) {
 			rd.Use(m.BlockIndexPathCtx)
 			rd.Get("/", app.getBlockSummary)
@@ -123,6 +137,8 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		r.Route("/vote", func(rd chi.Router) {
 			rd.Use(app.StakeVersionLatestCtx)
 			rd.Get("/info", app.getVoteInfo)
+			rd.With(m.ChartGroupingCtx).Get("/versions/{chartgrouping}", app.getVoteVersionHistory)
+			rd.Get("/bits", app.getVoteBitsStats)
 		})
 		r.Route("/pool", func(rd chi.Router) {
 			rd.With(app.BlockIndexLatestCtx).Get("/", app.getTicketPoolInfo)
@@ -135,10 +151,12 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.Get("/", app.getStakeDiffSummary)
 			rd.Get("/current", app.getStakeDiffCurrent)
 			rd.Get("/estimates", app.getStakeDiffEstimates)
+			rd.Get("/prediction", app.getStakeDiffPrediction)
 			rd.With(m.BlockIndexPathCtx).Get("/b/{idx}", app.getStakeDiff)
 			rd.With(m.BlockIndex0PathCtx, m.BlockIndexPathCtx).Get("/r/{idx0}/{idx}", app.getStakeDiffRange)
 		})
 		r.Get("/powerless", app.getPowerlessTickets)
+		r.Get("/roi", app.getStakeROI)
 	})
 
 	mux.Route("/tx", func(r chi.Router) {
@@ -157,19 +175,68 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 				})
 				rd.Get("/vinfo", app.getTxVoteInfo)
 				rd.Get("/tinfo", app.getTxTicketInfo)
+				rd.Get("/proof", app.getTxInclusionProof)
+				rd.Get("/raw", app.getTransactionRaw)
+				rd.Get("/firstseen", app.getTxFirstSeen)
+				rd.Get("/swaps", app.getTxSwaps)
+				rd.Get("/graph", app.getTxGraph)
 			})
 		})
 		r.With(m.TransactionHashCtx).Get("/hex/{txid}", app.getTransactionHex)
 		r.With(m.TransactionHashCtx).Get("/decoded/{txid}", app.getDecodedTx)
 	})
 
+	mux.Route("/chain", func(r chi.Router) {
+		r.Route("/reorgs", func(rr chi.Router) {
+			rr.Get("/", app.getChainReorgs)
+			rr.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getChainReorgs)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getChainReorgs)
+			})
+		})
+	})
+
+	mux.Route("/outputs/nonstandard", func(r chi.Router) {
+		r.Get("/", app.getNonstandardOutputs)
+		r.Route("/count/{N}", func(ri chi.Router) {
+			ri.Use(m.NPathCtx)
+			ri.Get("/", app.getNonstandardOutputs)
+			ri.With(m.MPathCtx).Get("/skip/{M}", app.getNonstandardOutputs)
+		})
+	})
+
+	mux.Route("/treasury", func(r chi.Router) {
+		r.Get("/balance", app.getTreasuryBalance)
+		r.With(m.ChartGroupingCtx).Get("/balance/{chartgrouping}", app.getTreasuryBalanceHistory)
+		r.Route("/tspend/{txid}", func(rd chi.Router) {
+			rd.Use(m.TransactionHashCtx)
+			rd.Get("/", app.getTSpend)
+			rd.Get("/votes", app.getTSpendVotes)
+		})
+	})
+
+	mux.Route("/swaps", func(r chi.Router) {
+		r.Get("/recent", app.getRecentSwaps)
+	})
+
 	mux.Route("/txs", func(r chi.Router) {
 		r.Use(middleware.AllowContentType("application/json"),
-			m.ValidateTxnsPostCtx, m.PostTxnsCtx)
-		r.Post("/", app.getTransactions)
-		r.Post("/trimmed", app.getDecodedTransactions)
+			m.ValidateTxnsPostCtx)
+		r.With(m.PostTxnsCtx).Post("/", app.getTransactions)
+		r.With(m.PostTxnsCtx).Post("/trimmed", app.getDecodedTransactions)
+		// The POST body of /decode has transaction hex strings, not txids.
+		r.With(m.PostRawTxnsCtx).Post("/decode", app.decodeTransactions)
 	})
 
+	mux.Route("/watch", func(r chi.Router) {
+		r.With(middleware.AllowContentType("application/json")).Post("/", app.registerAddressWatch)
+		r.With(m.NPathCtx).Delete("/{N}", app.unregisterAddressWatch)
+	})
+
+	mux.With(app.faucetAuth, middleware.AllowContentType("application/json")).
+		Post("/faucet", app.requestFaucetCoins)
+
 	// DO NOT CHANGE maxExistAddrs.
 	// maxExistsAddrs must be <= 64 so that the bit mask can fit into a uint64.
 	const maxExistAddrs = 64
@@ -180,10 +247,16 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.Group(func(re chi.Router) {
 				re.Use(m.AddressPathCtxN(1))
 				re.Get("/totals", app.addressTotals)
+				re.Get("/cluster", app.getAddressCluster)
+				re.Get("/tag", app.getAddressTag)
+				re.With(m.BlockIndexPathCtx).Get("/balance/{idx}", app.addressBalanceAtHeight)
 				re.Get("/", app.getAddressTransactions)
 				re.With(m.ChartGroupingCtx).Get("/types/{chartgrouping}", app.getAddressTxTypesData)
 				re.With(m.ChartGroupingCtx).Get("/amountflow/{chartgrouping}", app.getAddressTxAmountFlowData)
 				re.With(compMiddleware).Get("/raw", app.getAddressTransactionsRaw)
+				// Full history exports are always gzipped when accepted.
+				re.With(ioCompress).Get("/io/csv", app.addressIOHistoryCSV)
+				re.With(ioCompress).Get("/io/json", app.addressIOHistoryJSON)
 				re.Route("/count/{N}", func(ri chi.Router) {
 					ri.Use(m.NPathCtx)
 					ri.Get("/", app.getAddressTransactions)
@@ -199,6 +272,15 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		})
 	})
 
+	mux.Route("/addresses", func(r chi.Router) {
+		r.Get("/rich", app.getRichList)
+		r.Get("/tags", app.getAddressTags)
+	})
+
+	mux.Get("/vsps", app.getVSPs)
+
+	mux.Get("/search", app.getSearch)
+
 	// Returns agenda data like; description, name, lockedin activated and other
 	// high level agenda details for all agendas.
 	mux.Route("/agendas", func(r chi.Router) {
@@ -208,10 +290,13 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	// Returns the charts data for the respective individual agendas.
 	mux.Route("/agenda", func(r chi.Router) {
 		r.With(m.AgendaIdCtx).Get("/{agendaId}", app.getAgendaData)
+		r.With(m.AgendaIdCtx).Get("/{agendaId}/votes/timeseries", app.getAgendaVoteTimeSeries)
 	})
 
 	mux.Route("/mempool", func(r chi.Router) {
 		r.Get("/", http.NotFound /*app.getMempoolOverview*/)
+		r.Get("/feerates", app.getMempoolFeeRates)
+		r.With(m.ChartGroupingCtx).Get("/history/{chartgrouping}", app.getMempoolCongestion)
 		// ticket purchases
 		r.Route("/sstx", func(rd chi.Router) {
 			rd.Get("/", app.getSSTxSummary)
@@ -229,9 +314,21 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.With(m.StickWidthContext).Get("/candlestick/{bin}", app.getCandlestickChart)
 			rd.Get("/depth", app.getDepthChart)
 		})
+		r.Route("/coin-age", func(rc chi.Router) {
+			rc.With(m.ChartGroupingCtx).Get("/cdd/{chartgrouping}", app.getCoinDaysDestroyed)
+			rc.Get("/bands", app.getCoinAgeBands)
+		})
+		r.Get("/block-propagation", app.getBlockPropagation)
+		r.Get("/script-types", app.getScriptTypeCounts)
+		r.With(m.ChartGroupingCtx).Get("/script-types/{chartgrouping}", app.getScriptTypeCounts)
 		r.With(m.ChartTypeCtx).Get("/{charttype}", app.ChartTypeData)
 	})
 
+	mux.Route("/ticket/{txid}", func(r chi.Router) {
+		r.Use(m.TransactionHashCtx)
+		r.Get("/lifecycle", app.getTicketLifecycle)
+	})
+
 	mux.Route("/ticketpool", func(r chi.Router) {
 		r.Get("/", app.getTicketPoolByDate)
 		r.With(m.TicketPoolCtx).Get("/bydate/{tp}", app.getTicketPoolByDate)
@@ -240,13 +337,23 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 
 	mux.Route("/proposal", func(r chi.Router) {
 		r.With(m.ProposalTokenCtx).Get("/{token}", app.getProposalChartData)
+		r.With(m.ProposalTokenCtx).Get("/{token}/snapshots", app.getProposalVoteSnapshots)
+		r.With(m.ProposalTokenCtx).Get("/{token}/spending", app.getProposalSpending)
 	})
 
 	mux.Route("/exchanges", func(r chi.Router) {
 		r.Get("/", app.getExchanges)
 		r.Get("/codes", app.getCurrencyCodes)
+		r.Get("/depth", app.getConsolidatedDepth)
+	})
+
+	mux.Route("/market/{token}", func(r chi.Router) {
+		r.Use(m.ExchangeTokenContext)
+		r.Get("/candles", app.getMarketCandles)
 	})
 
+	mux.Get("/exchangerate", app.getExchangeRate)
+
 	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, r.URL.RequestURI()+" ain't no country I've ever heard of! (404)", http.StatusNotFound)
 	})
@@ -302,12 +409,6 @@ func NewFileRouter(app *appContext, useRealIP bool) fileMux {
 	return fileMux{mux}
 }
 
-type loggerFunc func(string, ...interface{})
-
-func (lw loggerFunc) Printf(str string, args ...interface{}) {
-	lw(str, args...)
-}
-
 // Stacks some middleware common to both file and api router.
 func stackedMux(useRealIP bool) *chi.Mux {
 	mux := chi.NewRouter()
@@ -316,9 +417,6 @@ func stackedMux(useRealIP bool) *chi.Mux {
 	}
 	mux.Use(middleware.Logger)
 	mux.Use(middleware.Recoverer)
-	corsMW := cors.Default()
-	corsMW.Log = loggerFunc(apiLog.Tracef)
-	mux.Use(corsMW.Handler)
 	return mux
 }
 
@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"reflect"
 	"sort"
@@ -27,14 +28,18 @@ import (
 	"github.com/decred/dcrd/dcrutil/v2"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
 	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrd/txscript/v2"
 	"github.com/decred/dcrd/wire"
 	apitypes "github.com/decred/dcrdata/api/types/v5"
 	"github.com/decred/dcrdata/db/cache/v3"
 	"github.com/decred/dcrdata/db/dbtypes/v2"
 	"github.com/decred/dcrdata/exchanges/v2"
 	"github.com/decred/dcrdata/gov/v3/agendas"
+	pitypes "github.com/decred/dcrdata/gov/v3/politeia/types"
 	m "github.com/decred/dcrdata/middleware/v3"
+	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
 	"github.com/decred/dcrdata/txhelpers/v4"
+	"github.com/decred/dcrdata/v5/faucet"
 	appver "github.com/decred/dcrdata/v5/version"
 )
 
@@ -42,6 +47,18 @@ import (
 // once.
 const maxBlockRangeCount = 1000
 
+// rawCacheBytes is the size of the cache of serialized blocks and transactions
+// served by the raw endpoints.
+const rawCacheBytes = 64 << 20
+
+// The formats of the raw block and transaction endpoints, given by the
+// ?format= URL query.
+const (
+	rawFormatJSON   = "json"
+	rawFormatHex    = "hex"
+	rawFormatBinary = "binary"
+)
+
 // DataSource specifies an interface for advanced data collection using the
 // auxiliary DB (e.g. PostgreSQL).
 type DataSource interface {
@@ -63,11 +80,41 @@ type DataSource interface {
 	TicketPoolVisualization(interval dbtypes.TimeBasedGrouping) (
 		*dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, int64, error)
 	AgendaVotes(agendaID string, chartType int) (*dbtypes.AgendaVoteChoices, error)
+	AgendaVoteTimeSeries(agendaID string) (*apitypes.AgendaVoteTimeSeries, error)
 	AddressTxIoCsv(address string) ([][]string, error)
+	AddressRowsCompact(address string) ([]*dbtypes.AddressRowCompact, error)
+	AddressBalanceAtHeight(address string, height int64) (int64, error)
+	TreasuryBalance() (*apitypes.TreasuryBalance, error)
+	TreasuryBalanceHistory(grouping dbtypes.TimeBasedGrouping) (*apitypes.TreasuryBalanceHistory, error)
+	TSpend(txid string) (*apitypes.TSpend, error)
+	TSpendVotes(txid string) (*apitypes.TSpendVoteTally, error)
+	InvoicePayments(address string, txids []string) ([]apitypes.ProposalPayment, error)
+	TxInclusionProof(txid string) (*apitypes.TxInclusionProof, error)
+	ChainReorgs(N, offset int64) (*apitypes.ChainReorgs, error)
+	NonstandardOutputs(N, offset int64) (*apitypes.NonstandardOutputs, error)
+	ScriptTypeCounts(grouping dbtypes.TimeBasedGrouping) (*apitypes.ScriptTypeCounts, error)
+	BlockPropagation(N int64) (*apitypes.BlockPropagation, error)
+	RichList(N int) (*apitypes.RichList, error)
+	VSPs() ([]*dbtypes.VSPStats, error)
+	MarketCandles(exchange, bin string, from, to time.Time) ([]*dbtypes.MarketCandle, error)
+	SideChainBlock(hash string) (*apitypes.SideChainBlock, error)
+	VoutValue(txID string, vout uint32) (uint64, error)
+	SupplySchedule(startHeight, endHeight int64) (*apitypes.SupplySchedule, error)
+	UTXOSetStats(height int64) (*apitypes.UTXOSetStats, error)
+	VoteVersionHistory(grouping dbtypes.TimeBasedGrouping, version uint32) (*apitypes.VoteVersionHistory, error)
+	VoteBitsStats(version uint32, startHeight, endHeight int64) (*apitypes.VoteBitsStats, error)
+	RecentSwaps(N, offset int64) ([]*apitypes.AtomicSwap, error)
+	TxSwaps(txid string) ([]*apitypes.AtomicSwap, error)
+	TxGraph(txid string, depth, maxEdges int) (*apitypes.TxGraph, error)
+	StakeROI(amount dcrutil.Amount, start int64) (*apitypes.StakeROI, error)
+	AddressTag(address string) *dbtypes.AddressTag
+	AddressTags(category string) []*dbtypes.AddressTag
 	Height() int64
 	AllAgendas() (map[string]dbtypes.MileStone, error)
 	GetTicketInfo(txid string) (*apitypes.TicketInfo, error)
+	TicketLifecycle(txid string) (*apitypes.TicketLifecycle, error)
 	ProposalVotes(proposalToken string) (*dbtypes.ProposalChartsData, error)
+	ProposalVoteSnapshots(token string) (*apitypes.ProposalVoteSnapshots, error)
 	PowerlessTickets() (*apitypes.PowerlessTickets, error)
 	GetStakeInfoExtendedByHash(hash string) *apitypes.StakeInfoExtended
 	GetStakeInfoExtendedByHeight(idx int) *apitypes.StakeInfoExtended
@@ -82,6 +129,7 @@ type DataSource interface {
 	GetBlockVerboseByHash(hash string, verboseTx bool) *chainjson.GetBlockVerboseResult
 	GetRawAPITransaction(txid *chainhash.Hash) *apitypes.Tx
 	GetTransactionHex(txid *chainhash.Hash) string
+	RawTransaction(txid *chainhash.Hash) ([]byte, int64, error)
 	GetTrimmedTransaction(txid *chainhash.Hash) *apitypes.TrimmedTx
 	GetVoteInfo(txid *chainhash.Hash) (*apitypes.VoteInfo, error)
 	GetVoteVersionInfo(ver uint32) (*chainjson.GetVoteInfoResult, error)
@@ -106,6 +154,68 @@ type DataSource interface {
 	GetMempoolPriceCountTime() *apitypes.PriceCountTime
 }
 
+// FeeRateEstimator provides the fee rates of the transactions in mempool, and
+// fee rate estimates for confirmation within a number of blocks.
+type FeeRateEstimator interface {
+	FeeRates(targets []int) *apitypes.MempoolFeeRates
+}
+
+// AddressWatcher manages the registrations of callback URLs for notifications
+// of the transactions involving an address.
+type AddressWatcher interface {
+	Register(address, callbackURL string) (*dbtypes.AddressWatch, error)
+	Unregister(id int64, secret string) error
+}
+
+// Faucet sends testnet or simnet coins to a requested address, with each
+// address limited to one grant per interval.
+type Faucet interface {
+	Request(address string) (*dbtypes.FaucetGrant, error)
+}
+
+// ProposalBillingSource provides the billing of the approved Politeia
+// proposals. ProposalBilling returns nil if the proposal has no billing.
+type ProposalBillingSource interface {
+	ProposalBilling(token string) (*pitypes.ProposalBilling, error)
+}
+
+// CoinAgeCharts provides the coin days destroyed and the coin age
+// distribution of the unspent value.
+type CoinAgeCharts interface {
+	CoinDaysDestroyed(grouping dbtypes.TimeBasedGrouping) (*apitypes.CoinDaysDestroyed, error)
+	AgeBands() (*apitypes.CoinAgeBands, error)
+}
+
+// Searcher finds the blocks, transactions, addresses and proposals matching a
+// partial query.
+type Searcher interface {
+	Search(query string, limit int) (*apitypes.SearchResults, error)
+}
+
+// AddressClusters provides the cluster of addresses linked to an address by
+// the common-input-ownership heuristic.
+type AddressClusters interface {
+	AddressCluster(address string) (*apitypes.AddressCluster, error)
+}
+
+// MempoolHistory provides the recorded mempool history of transactions.
+type MempoolHistory interface {
+	MempoolTxHistory(txid string) (*apitypes.MempoolTxHistory, error)
+	MempoolCongestion(grouping dbtypes.TimeBasedGrouping) (*apitypes.MempoolCongestion, error)
+}
+
+// StakeDiffProjector provides the projected ticket price of the next stake
+// difficulty window, which is updated as the mempool changes.
+type StakeDiffProjector interface {
+	StakeDiffProjection() *pstypes.StakeDiffProjection
+}
+
+// SyncProgressor provides the progress of the DB sync with the node, which is
+// updated periodically.
+type SyncProgressor interface {
+	SyncProgress() *pstypes.SyncProgress
+}
+
 // dcrdata application context used by all route handlers
 type appContext struct {
 	nodeClient   *rpcclient.Client
@@ -116,7 +226,20 @@ type appContext struct {
 	AgendaDB     *agendas.AgendaDB
 	maxCSVAddrs  int
 	charts       *cache.ChartData
+	feeRates     FeeRateEstimator
+	watcher      AddressWatcher
+	faucet       Faucet
+	faucetToken  string
+	coinAge      CoinAgeCharts
+	clusters     AddressClusters
+	sdiffProj    StakeDiffProjector
+	syncProg     SyncProgressor
+	mpHistory    MempoolHistory
+	searcher     Searcher
+	rawCache     *cache.RawCache
+	chainInfo    *apitypes.ChainInfo
 	isPiDisabled bool // is piparser disabled
+	piBilling    ProposalBillingSource
 }
 
 // AppContextConfig is the configuration for the appContext and the only
@@ -129,7 +252,18 @@ type AppContextConfig struct {
 	AgendasDBInstance  *agendas.AgendaDB
 	MaxAddrs           int
 	Charts             *cache.ChartData
+	FeeRates           FeeRateEstimator
+	Watcher            AddressWatcher
+	Faucet             Faucet
+	FaucetToken        string
+	CoinAge            CoinAgeCharts
+	Clusters           AddressClusters
+	StakeDiffProjector StakeDiffProjector
+	SyncProgressor     SyncProgressor
+	MempoolHistory     MempoolHistory
+	Searcher           Searcher
 	IsPiparserDisabled bool
+	ProposalBilling    ProposalBillingSource
 }
 
 // NewContext constructs a new appContext from the RPC client, primary and
@@ -153,7 +287,20 @@ func NewContext(cfg *AppContextConfig) *appContext {
 		Status:       apitypes.NewStatus(uint32(nodeHeight), conns, APIVersion, appver.Version(), cfg.Params.Name),
 		maxCSVAddrs:  cfg.MaxAddrs,
 		charts:       cfg.Charts,
+		feeRates:     cfg.FeeRates,
+		watcher:      cfg.Watcher,
+		faucet:       cfg.Faucet,
+		faucetToken:  cfg.FaucetToken,
+		coinAge:      cfg.CoinAge,
+		clusters:     cfg.Clusters,
+		sdiffProj:    cfg.StakeDiffProjector,
+		syncProg:     cfg.SyncProgressor,
+		mpHistory:    cfg.MempoolHistory,
+		searcher:     cfg.Searcher,
+		rawCache:     cache.NewRawCache(rawCacheBytes),
+		chainInfo:    newChainInfo(cfg.Params),
 		isPiDisabled: cfg.IsPiparserDisabled,
+		piBilling:    cfg.ProposalBilling,
 	}
 }
 
@@ -290,6 +437,38 @@ func writeJSONBytes(w http.ResponseWriter, data []byte) {
 	}
 }
 
+// rawFormat gets the format of a raw endpoint from the ?format= URL query, or
+// def if it is not set. ok is false for an unknown format.
+func rawFormat(r *http.Request, def string) (format string, ok bool) {
+	format = r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return def, true
+	case rawFormatJSON, rawFormatHex, rawFormatBinary:
+		return format, true
+	}
+	return "", false
+}
+
+// writeRaw sets the length and type headers, and writes the serialized data as
+// binary for rawFormatBinary, and as hex text otherwise. The hex is encoded as
+// it is written.
+func writeRaw(w http.ResponseWriter, data []byte, format string) {
+	var err error
+	if format == rawFormatBinary {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		_, err = w.Write(data)
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(hex.EncodedLen(len(data))))
+		_, err = hex.NewEncoder(w).Write(data)
+	}
+	if err != nil {
+		apiLog.Warnf("ResponseWriter.Write error: %v", err)
+	}
+}
+
 // Measures length, sets common headers, formats, and sends CSV data.
 func writeCSV(w http.ResponseWriter, rows [][]string, filename string, useCRLF bool) {
 	w.Header().Set("Content-Disposition",
@@ -351,6 +530,23 @@ func (c *appContext) status(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, c.Status.API(), m.GetIndentCtx(r))
 }
 
+// SyncProgress serves the progress of the DB sync with the node, including the
+// recent sync rate and the estimated time to reach the node's best block. This
+// is exported so that it may be served during the sync, when the rest of the
+// API is not.
+// /sync
+func (c *appContext) SyncProgress(w http.ResponseWriter, r *http.Request) {
+	var sp *pstypes.SyncProgress
+	if c.syncProg != nil {
+		sp = c.syncProg.SyncProgress()
+	}
+	if sp == nil {
+		http.Error(w, "Sync progress not available.", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, sp, m.GetIndentCtx(r))
+}
+
 func (c *appContext) statusHappy(w http.ResponseWriter, r *http.Request) {
 	happy := c.Status.Happy()
 	statusCode := http.StatusOK
@@ -399,6 +595,174 @@ func (c *appContext) coinSupplyCirculating(w http.ResponseWriter, r *http.Reques
 	writeJSONBytes(w, []byte(strconv.FormatInt(supply.Mined, 10)))
 }
 
+// defaultSupplyScheduleBlocks is the number of blocks of the supply schedule
+// served when the end height is not specified.
+const defaultSupplyScheduleBlocks = 1000000
+
+// getSupplySchedule serves the block subsidy schedule and the projected coin
+// supply for the heights from the "from" URL query parameter (default is the
+// best block) to the "to" parameter.
+func (c *appContext) getSupplySchedule(w http.ResponseWriter, r *http.Request) {
+	from := c.DataSource.Height()
+	if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+		var err error
+		from, err = strconv.ParseInt(fromParam, 10, 64)
+		if err != nil || from < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+	to := from + defaultSupplyScheduleBlocks
+	if toParam := r.URL.Query().Get("to"); toParam != "" {
+		var err error
+		to, err = strconv.ParseInt(toParam, 10, 64)
+		if err != nil || to < from {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	schedule, err := c.DataSource.SupplySchedule(from, to)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("SupplySchedule: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("SupplySchedule: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, schedule, m.GetIndentCtx(r))
+}
+
+// getUTXOSetStats serves the number and total value of the unspent outputs, in
+// total and by script type, after the block at the height given by the
+// "height" URL query parameter (default is the best block).
+func (c *appContext) getUTXOSetStats(w http.ResponseWriter, r *http.Request) {
+	height := int64(-1)
+	if heightParam := r.URL.Query().Get("height"); heightParam != "" {
+		var err error
+		height, err = strconv.ParseInt(heightParam, 10, 64)
+		if err != nil || height < 0 || height > c.DataSource.Height() {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := c.DataSource.UTXOSetStats(height)
+	if err == sql.ErrNoRows {
+		http.Error(w, "The outputs at this height have been pruned.", http.StatusNotFound)
+		return
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("UTXOSetStats: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("UTXOSetStats: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats, m.GetIndentCtx(r))
+}
+
+// newChainInfo collects the consensus parameters of the network for
+// getChainInfo. The deployments are ordered by stake version.
+func newChainInfo(params *chaincfg.Params) *apitypes.ChainInfo {
+	info := &apitypes.ChainInfo{
+		Network:            params.Name,
+		Net:                uint32(params.Net),
+		GenesisHash:        params.GenesisHash.String(),
+		DefaultPort:        params.DefaultPort,
+		AddressPrefix:      params.NetworkAddressPrefix,
+		TargetBlockTime:    int64(params.TargetTimePerBlock.Seconds()),
+		WorkDiffWindowSize: params.WorkDiffWindowSize,
+		WorkDiffWindows:    params.WorkDiffWindows,
+		MaxTxSize:          params.MaxTxSize,
+		CoinbaseMaturity:   params.CoinbaseMaturity,
+		Stake: apitypes.ChainStakeParams{
+			TicketPoolSize:        params.TicketPoolSize,
+			TicketsPerBlock:       params.TicketsPerBlock,
+			TicketMaturity:        params.TicketMaturity,
+			TicketExpiry:          params.TicketExpiry,
+			SStxChangeMaturity:    params.SStxChangeMaturity,
+			MinimumStakeDiff:      params.MinimumStakeDiff,
+			StakeDiffWindowSize:   params.StakeDiffWindowSize,
+			StakeDiffWindows:      params.StakeDiffWindows,
+			StakeVersionInterval:  params.StakeVersionInterval,
+			MaxFreshStakePerBlock: params.MaxFreshStakePerBlock,
+			StakeEnabledHeight:    params.StakeEnabledHeight,
+			StakeValidationHeight: params.StakeValidationHeight,
+		},
+		Subsidy: apitypes.ChainSubsidyParams{
+			BlockOne:           params.BlockOneSubsidy(),
+			Base:               params.BaseSubsidyValue(),
+			Multiplier:         params.SubsidyReductionMultiplier(),
+			Divisor:            params.SubsidyReductionDivisor(),
+			ReductionInterval:  params.SubsidyReductionIntervalBlocks(),
+			WorkProportion:     params.WorkSubsidyProportion(),
+			StakeProportion:    params.StakeSubsidyProportion(),
+			TreasuryProportion: params.TreasurySubsidyProportion(),
+		},
+		RuleChange: apitypes.ChainRuleChange{
+			Quorum:     params.RuleChangeActivationQuorum,
+			Multiplier: params.RuleChangeActivationMultiplier,
+			Divisor:    params.RuleChangeActivationDivisor,
+			Interval:   params.RuleChangeActivationInterval,
+		},
+		Deployments: []apitypes.ChainDeployment{},
+		Checkpoints: make([]apitypes.ChainCheckpoint, 0, len(params.Checkpoints)),
+	}
+	if len(params.MaximumBlockSizes) > 0 {
+		info.MaxBlockSize = params.MaximumBlockSizes[0]
+	}
+
+	versions := make([]uint32, 0, len(params.Deployments))
+	for version := range params.Deployments {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	for _, version := range versions {
+		for _, deployment := range params.Deployments[version] {
+			choices := make([]apitypes.ChainDeploymentChoice, 0, len(deployment.Vote.Choices))
+			for _, choice := range deployment.Vote.Choices {
+				choices = append(choices, apitypes.ChainDeploymentChoice{
+					ID:          choice.Id,
+					Description: choice.Description,
+					Bits:        choice.Bits,
+					IsAbstain:   choice.IsAbstain,
+					IsNo:        choice.IsNo,
+				})
+			}
+			info.Deployments = append(info.Deployments, apitypes.ChainDeployment{
+				Version:     version,
+				ID:          deployment.Vote.Id,
+				Description: deployment.Vote.Description,
+				Mask:        deployment.Vote.Mask,
+				Choices:     choices,
+				StartTime:   deployment.StartTime,
+				ExpireTime:  deployment.ExpireTime,
+			})
+		}
+	}
+
+	for _, checkpoint := range params.Checkpoints {
+		info.Checkpoints = append(info.Checkpoints, apitypes.ChainCheckpoint{
+			Height: checkpoint.Height,
+			Hash:   checkpoint.Hash.String(),
+		})
+	}
+	return info
+}
+
+// getChainInfo serves the consensus parameters of the network, including the
+// agenda deployments, subsidy parameters and checkpoints.
+func (c *appContext) getChainInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, c.chainInfo, m.GetIndentCtx(r))
+}
+
 func (c *appContext) currentHeight(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	if _, err := io.WriteString(w, strconv.Itoa(int(c.Status.Height()))); err != nil {
@@ -515,38 +879,105 @@ func (c *appContext) getBlockHeader(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, blockHeader, m.GetIndentCtx(r))
 }
 
+// getBlockRaw serves the serialized block as a types.BlockRaw, or with the
+// ?format=hex or ?format=binary URL query, as hex text or binary.
 func (c *appContext) getBlockRaw(w http.ResponseWriter, r *http.Request) {
+	format, ok := rawFormat(r, rawFormatJSON)
+	if !ok {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
 	hash, err := c.getBlockHashCtx(r)
 	if err != nil {
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
-	msgBlock, err := c.DataSource.GetBlockByHash(hash)
+	blockBytes, err := c.rawBlock(hash)
 	if err != nil {
 		apiLog.Errorf("Unable to get block %s: %v", hash, err)
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
-	var hexString strings.Builder
-	hexString.Grow(msgBlock.SerializeSize())
-	err = msgBlock.Serialize(hex.NewEncoder(&hexString))
-	if err != nil {
-		apiLog.Errorf("Unable to serialize block %s: %v", hash, err)
+	if format != rawFormatJSON {
+		writeRaw(w, blockBytes, format)
+		return
+	}
+
+	var header wire.BlockHeader
+	if err = header.Deserialize(bytes.NewReader(blockBytes)); err != nil {
+		apiLog.Errorf("Unable to deserialize block header %s: %v", hash, err)
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
 	blockRaw := &apitypes.BlockRaw{
-		Height: msgBlock.Header.Height,
+		Height: header.Height,
 		Hash:   hash,
-		Hex:    hexString.String(),
+		Hex:    hex.EncodeToString(blockBytes),
 	}
 
 	writeJSON(w, blockRaw, m.GetIndentCtx(r))
 }
 
+// rawBlock gets the serialized block from the raw cache, or from dcrd.
+func (c *appContext) rawBlock(hash string) ([]byte, error) {
+	key := "block:" + hash
+	if blockBytes, ok := c.rawCache.Get(key); ok {
+		return blockBytes, nil
+	}
+
+	msgBlock, err := c.DataSource.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(msgBlock.SerializeSize())
+	if err = msgBlock.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	blockBytes := buf.Bytes()
+	c.rawCache.Set(key, blockBytes)
+	return blockBytes, nil
+}
+
+// getTransactionRaw serves the serialized transaction as hex text, or with the
+// ?format=binary URL query, as binary.
+func (c *appContext) getTransactionRaw(w http.ResponseWriter, r *http.Request) {
+	format, ok := rawFormat(r, rawFormatHex)
+	if !ok || format == rawFormatJSON {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	// The signature scripts of an unconfirmed transaction may change without
+	// changing its ID, so only confirmed transactions are cached.
+	key := "tx:" + txid.String()
+	txBytes, ok := c.rawCache.Get(key)
+	if !ok {
+		var confirmations int64
+		txBytes, confirmations, err = c.DataSource.RawTransaction(txid)
+		if err != nil {
+			apiLog.Debugf("Unable to get transaction %s: %v", txid, err)
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if confirmations > 0 {
+			c.rawCache.Set(key, txBytes)
+		}
+	}
+
+	writeRaw(w, txBytes, format)
+}
+
 func (c *appContext) getBlockHeaderRaw(w http.ResponseWriter, r *http.Request) {
 	hash, err := c.getBlockHashCtx(r)
 	if err != nil {
@@ -611,10 +1042,88 @@ func (c *appContext) getVoteInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, voteVersionInfo, m.GetIndentCtx(r))
 }
 
+// getVoteVersionHistory serves the number of votes with each vote version for
+// each period of the time grouping, and the percentage of the votes with a
+// version older than the "version" URL query parameter (default is the latest
+// stake version).
+// /stake/vote/versions/{chartgrouping}
+func (c *appContext) getVoteVersionHistory(w http.ResponseWriter, r *http.Request) {
+	chartGrouping := m.GetChartGroupingCtx(r)
+	grouping := dbtypes.TimeGroupingFromStr(chartGrouping)
+	if grouping == dbtypes.UnknownGrouping {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	ver, verStr, err := getVoteVersionQuery(r)
+	if err != nil || ver < 0 {
+		http.Error(w, "Invalid stake version "+verStr, 422)
+		return
+	}
+
+	history, err := c.DataSource.VoteVersionHistory(grouping, uint32(ver))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("VoteVersionHistory: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("VoteVersionHistory(%s, %d): %v", chartGrouping, ver, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, history, m.GetIndentCtx(r))
+}
+
+// getVoteBitsStats serves the number of votes with the vote version given by
+// the "version" URL query parameter (default is the latest stake version) by
+// their vote bits, and the resulting choice counts of the version's agendas,
+// for the blocks with heights from the "from" parameter (default is the start
+// of the current rule change interval) to the "to" parameter (default is the
+// best block).
+// /stake/vote/bits
+func (c *appContext) getVoteBitsStats(w http.ResponseWriter, r *http.Request) {
+	ver, verStr, err := getVoteVersionQuery(r)
+	if err != nil || ver < 0 {
+		http.Error(w, "Invalid stake version "+verStr, 422)
+		return
+	}
+
+	to := c.DataSource.Height()
+	if toParam := r.URL.Query().Get("to"); toParam != "" {
+		to, err = strconv.ParseInt(toParam, 10, 64)
+		if err != nil || to < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+	interval := int64(c.Params.RuleChangeActivationInterval)
+	from := to - to%interval
+	if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+		from, err = strconv.ParseInt(fromParam, 10, 64)
+		if err != nil || from < 0 || from > to {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := c.DataSource.VoteBitsStats(uint32(ver), from, to)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("VoteBitsStats: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("VoteBitsStats(%d, %d, %d): %v", ver, from, to, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats, m.GetIndentCtx(r))
+}
+
 // setOutputSpends retrieves spending transaction information for each output of
 // the specified transaction. This sets the vouts[i].Spend fields for each
-// output that is spent. For unspent outputs, the Spend field remains a nil
-// pointer.
+// output that is spent. For unspent outputs, and the outputs of a transaction
+// in a pruned block, the Spend field remains a nil pointer.
 func (c *appContext) setOutputSpends(txid string, vouts []apitypes.Vout) error {
 	// For each output of this transaction, look up any spending transactions,
 	// and the index of the spending transaction input.
@@ -622,6 +1131,9 @@ func (c *appContext) setOutputSpends(txid string, vouts []apitypes.Vout) error {
 	if dbtypes.IsTimeoutErr(err) {
 		return fmt.Errorf("SpendingTransactions: %v", err)
 	}
+	if err == dbtypes.ErrPruned {
+		return nil
+	}
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("unable to get spending transaction info for outputs of %s", txid)
 	}
@@ -704,6 +1216,38 @@ func (c *appContext) getTransactionHex(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, hex)
 }
 
+// getTxFirstSeen serves the mempool history of a transaction: when it was
+// first and last seen in mempool, its fee rate, and its fate.
+// /tx/{txid}/firstseen
+func (c *appContext) getTxFirstSeen(w http.ResponseWriter, r *http.Request) {
+	if c.mpHistory == nil {
+		http.Error(w, "Mempool history disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	hist, err := c.mpHistory.MempoolTxHistory(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("MempoolTxHistory: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "Transaction not seen in mempool.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("MempoolTxHistory(%s): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, hist, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getDecodedTx(w http.ResponseWriter, r *http.Request) {
 	// Look up any spending transactions for each output of this transaction
 	// when the client requests spends with the URL query ?spends=true.
@@ -809,6 +1353,224 @@ func (c *appContext) getDecodedTransactions(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, txns, m.GetIndentCtx(r))
 }
 
+// watchSecretHeader is the HTTP header with the secret of an address watch
+// registration, which is required to delete the registration.
+const watchSecretHeader = "X-Watch-Secret"
+
+// registerAddressWatch registers a callback URL for notifications of the
+// transactions involving an address. The response includes the secret of the
+// registration, which is not revealed again.
+// POST /watch
+func (c *appContext) registerAddressWatch(w http.ResponseWriter, r *http.Request) {
+	if c.watcher == nil {
+		http.Error(w, "Address watches disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	var req apitypes.AddressWatchRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<12)).Decode(&req); err != nil {
+		http.Error(w, "failed to unmarshal JSON request", http.StatusBadRequest)
+		return
+	}
+	watch, err := c.watcher.Register(req.Address, req.CallbackURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, watch, m.GetIndentCtx(r))
+}
+
+// unregisterAddressWatch deletes an address watch registration, provided its
+// secret in the X-Watch-Secret header.
+// DELETE /watch/{N}
+func (c *appContext) unregisterAddressWatch(w http.ResponseWriter, r *http.Request) {
+	if c.watcher == nil {
+		http.Error(w, "Address watches disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	id := m.GetNCtx(r)
+	err := c.watcher.Unregister(int64(id), r.Header.Get(watchSecretHeader))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Unregister: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unregister(%d): %v", id, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// faucetAuth requires the faucet token in an "Authorization: Bearer" header
+// of the faucet requests.
+func (c *appContext) faucetAuth(next http.Handler) http.Handler {
+	if c.faucet == nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Faucet disabled.", http.StatusServiceUnavailable)
+		})
+	}
+	return requireBearerToken(c.faucetToken)(next)
+}
+
+// requestFaucetCoins sends the faucet amount to the requested address. An
+// address granted coins recently is refused with a Retry-After header.
+// POST /faucet
+func (c *appContext) requestFaucetCoins(w http.ResponseWriter, r *http.Request) {
+	var req apitypes.FaucetRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<12)).Decode(&req); err != nil {
+		http.Error(w, "failed to unmarshal JSON request", http.StatusBadRequest)
+		return
+	}
+	grant, err := c.faucet.Request(req.Address)
+	switch err := err.(type) {
+	case nil:
+	case *faucet.InvalidAddressError:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case *faucet.RateLimitError:
+		retryAfter := int64(math.Ceil(err.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, grant, m.GetIndentCtx(r))
+}
+
+// maxDecodeTxns is the maximum number of transactions decoded by a request to
+// /txs/decode.
+const maxDecodeTxns = 500
+
+// decodeTransactions serves the transactions decoded from the posted
+// transaction hex strings. A transaction that cannot be decoded has an error
+// in its result rather than failing the request.
+// /txs/decode
+func (c *appContext) decodeTransactions(w http.ResponseWriter, r *http.Request) {
+	hexes, err := m.GetRawTxnsCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	if len(hexes) > maxDecodeTxns {
+		http.Error(w, fmt.Sprintf("maximum of %d transactions allowed", maxDecodeTxns), 422)
+		return
+	}
+
+	results := make([]apitypes.DecodeTxResult, 0, len(hexes))
+	for _, txhex := range hexes {
+		msgTx, err := txhelpers.MsgTxFromHex(txhex)
+		if err != nil {
+			results = append(results, apitypes.DecodeTxResult{
+				Error: fmt.Sprintf("failed to decode transaction: %v", err),
+			})
+			continue
+		}
+		tx, err := c.decodeTx(msgTx)
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("decodeTx: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			apiLog.Errorf("decodeTx(%v): %v", msgTx.TxHash(), err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		results = append(results, apitypes.DecodeTxResult{Tx: tx})
+	}
+
+	writeJSON(w, results, m.GetIndentCtx(r))
+}
+
+// decodeTx decodes the transaction's inputs and outputs. The amount of an input
+// is from its witness data, or from the previous output if it is stored.
+func (c *appContext) decodeTx(msgTx *wire.MsgTx) (*apitypes.DecodedTx, error) {
+	tx := &apitypes.DecodedTx{
+		TxID:     msgTx.TxHash().String(),
+		Type:     txhelpers.DetermineTxTypeString(msgTx),
+		Size:     msgTx.SerializeSize(),
+		Version:  msgTx.Version,
+		Locktime: msgTx.LockTime,
+		Expiry:   msgTx.Expiry,
+		Vin:      make([]apitypes.DecodedTxIn, 0, len(msgTx.TxIn)),
+		Vout:     make([]apitypes.Vout, 0, len(msgTx.TxOut)),
+	}
+
+	var amtIn int64
+	inputsKnown := true
+	for _, txIn := range msgTx.TxIn {
+		vin := apitypes.DecodedTxIn{
+			PrevVout:  txIn.PreviousOutPoint.Index,
+			PrevTree:  txIn.PreviousOutPoint.Tree,
+			Sequence:  txIn.Sequence,
+			ScriptSig: hex.EncodeToString(txIn.SignatureScript),
+		}
+		amt := txIn.ValueIn
+		if txhelpers.IsGeneratedInput(txIn) {
+			vin.Generated = true
+		} else {
+			vin.PrevTxID = txIn.PreviousOutPoint.Hash.String()
+			if amt == wire.NullValueIn {
+				value, err := c.DataSource.VoutValue(vin.PrevTxID, vin.PrevVout)
+				if err == nil {
+					amt = int64(value)
+				} else if err != sql.ErrNoRows {
+					return nil, err
+				}
+			}
+		}
+		if amt == wire.NullValueIn {
+			inputsKnown = false
+		} else {
+			amtIn += amt
+			amtCoin := dcrutil.Amount(amt).ToCoin()
+			vin.AmountIn = &amtCoin
+		}
+		tx.Vin = append(tx.Vin, vin)
+	}
+
+	var amtOut int64
+	for i, txOut := range msgTx.TxOut {
+		script := txhelpers.DecodeOutputScript(msgTx, i, c.Params)
+		asm, _ := txscript.DisasmString(txOut.PkScript)
+		spk := apitypes.ScriptPubKey{
+			Asm:       asm,
+			Hex:       hex.EncodeToString(txOut.PkScript),
+			ReqSigs:   int32(script.ReqSigs),
+			Type:      script.Class,
+			Addresses: script.Addresses,
+		}
+		if script.CommitAmt != nil {
+			commitAmt := script.CommitAmt.ToCoin()
+			spk.CommitAmt = &commitAmt
+		}
+		tx.Vout = append(tx.Vout, apitypes.Vout{
+			Value:               dcrutil.Amount(txOut.Value).ToCoin(),
+			N:                   uint32(i),
+			Version:             txOut.Version,
+			ScriptPubKeyDecoded: spk,
+		})
+		amtOut += txOut.Value
+	}
+	tx.TotalOut = dcrutil.Amount(amtOut).ToCoin()
+
+	if inputsKnown {
+		totalIn := dcrutil.Amount(amtIn).ToCoin()
+		fee := dcrutil.Amount(amtIn - amtOut).ToCoin()
+		feeRate := dcrutil.Amount(txhelpers.FeeRate(amtIn, amtOut, int64(tx.Size))).ToCoin()
+		tx.TotalIn, tx.Fee, tx.FeeRate = &totalIn, &fee, &feeRate
+	}
+
+	return tx, nil
+}
+
 func (c *appContext) getTxVoteInfo(w http.ResponseWriter, r *http.Request) {
 	txid, err := m.GetTxIDCtx(r)
 	if err != nil {
@@ -844,8 +1606,473 @@ func (c *appContext) getTxTicketInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, tinfo, m.GetIndentCtx(r))
 }
 
-// getTransactionInputs serves []TxIn
-func (c *appContext) getTransactionInputs(w http.ResponseWriter, r *http.Request) {
+// For /ticket/{txid}/lifecycle
+func (c *appContext) getTicketLifecycle(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	lifecycle, err := c.DataSource.TicketLifecycle(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketLifecycle: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "ticket not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TicketLifecycle(%v): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, lifecycle, m.GetIndentCtx(r))
+}
+
+// getTxInclusionProof serves the merkle proof that a confirmed transaction is
+// committed to by its block header.
+// /tx/{txid}/proof
+func (c *appContext) getTxInclusionProof(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	proof, err := c.DataSource.TxInclusionProof(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TxInclusionProof: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "transaction not in a mainchain block", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TxInclusionProof(%v): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, proof, m.GetIndentCtx(r))
+}
+
+// getChainReorgs serves a page of the recorded chain reorganizations, most
+// recent first.
+// /chain/reorgs
+// /chain/reorgs/count/{N}
+// /chain/reorgs/count/{N}/skip/{M}
+func (c *appContext) getChainReorgs(w http.ResponseWriter, r *http.Request) {
+	count := int64(m.GetNCtx(r))
+	skip := int64(m.GetMCtx(r))
+	if count <= 0 {
+		count = 100
+	} else if count > 2000 {
+		count = 2000
+	}
+	if skip <= 0 {
+		skip = 0
+	}
+
+	reorgs, err := c.DataSource.ChainReorgs(count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ChainReorgs: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("ChainReorgs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, reorgs, m.GetIndentCtx(r))
+}
+
+// getNonstandardOutputs serves a page of the mainchain outputs with nonstandard
+// scripts and their disassembly, most recent first.
+// /outputs/nonstandard
+// /outputs/nonstandard/count/{N}
+// /outputs/nonstandard/count/{N}/skip/{M}
+func (c *appContext) getNonstandardOutputs(w http.ResponseWriter, r *http.Request) {
+	count := int64(m.GetNCtx(r))
+	skip := int64(m.GetMCtx(r))
+	if count <= 0 {
+		count = 100
+	} else if count > 2000 {
+		count = 2000
+	}
+	if skip <= 0 {
+		skip = 0
+	}
+
+	outputs, err := c.DataSource.NonstandardOutputs(count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("NonstandardOutputs: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("NonstandardOutputs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, outputs, m.GetIndentCtx(r))
+}
+
+// getRichList serves the top addresses by balance and the balance
+// distribution. The number of addresses is set with the URL query ?n=N, up to
+// the number materialized in the rich list.
+// /addresses/rich
+func (c *appContext) getRichList(w http.ResponseWriter, r *http.Request) {
+	n := 100
+	if nParam := r.URL.Query().Get("n"); nParam != "" {
+		var err error
+		n, err = strconv.Atoi(nParam)
+		if err != nil || n <= 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	richList, err := c.DataSource.RichList(n)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("RichList: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("RichList: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, richList, m.GetIndentCtx(r))
+}
+
+// getVSPs serves the most recent statistics of each voting service provider
+// (VSP) polled by the VSP statistics collector.
+func (c *appContext) getVSPs(w http.ResponseWriter, r *http.Request) {
+	vsps, err := c.DataSource.VSPs()
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("VSPs: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("VSPs: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, vsps, m.GetIndentCtx(r))
+}
+
+// getTreasuryBalance serves the current treasury balance.
+// /treasury/balance
+func (c *appContext) getTreasuryBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := c.DataSource.TreasuryBalance()
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TreasuryBalance: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TreasuryBalance: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, balance, m.GetIndentCtx(r))
+}
+
+// getTreasuryBalanceHistory serves the treasury additions, spends, and balance
+// for each period of the time grouping.
+// /treasury/balance/{chartgrouping}
+func (c *appContext) getTreasuryBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	chartGrouping := m.GetChartGroupingCtx(r)
+	grouping := dbtypes.TimeGroupingFromStr(chartGrouping)
+	if grouping == dbtypes.UnknownGrouping {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	history, err := c.DataSource.TreasuryBalanceHistory(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TreasuryBalanceHistory: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TreasuryBalanceHistory(%s): %v", chartGrouping, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, history, m.GetIndentCtx(r))
+}
+
+// maxRecentSwaps is the maximum number of atomic swaps served by
+// getRecentSwaps.
+const maxRecentSwaps = 500
+
+// getRecentSwaps serves the most recent atomic swap redemptions and refunds,
+// with optional ?n=N&skip=M pagination.
+// /swaps/recent
+func (c *appContext) getRecentSwaps(w http.ResponseWriter, r *http.Request) {
+	N, skip := int64(20), int64(0)
+	for param, val := range map[string]*int64{"n": &N, "skip": &skip} {
+		str := r.URL.Query().Get(param)
+		if str == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid %s", param), http.StatusBadRequest)
+			return
+		}
+		*val = n
+	}
+	if N > maxRecentSwaps {
+		N = maxRecentSwaps
+	}
+
+	swaps, err := c.DataSource.RecentSwaps(N, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("RecentSwaps: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("RecentSwaps(%d, %d): %v", N, skip, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, swaps, m.GetIndentCtx(r))
+}
+
+// getTxSwaps serves the atomic swap redemptions and refunds of the contracts
+// created or spent by the transaction.
+// /tx/{txid}/swaps
+func (c *appContext) getTxSwaps(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	swaps, err := c.DataSource.TxSwaps(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TxSwaps: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TxSwaps(%s): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, swaps, m.GetIndentCtx(r))
+}
+
+// getStakeROI serves the simulated returns of staking the "amount" URL query
+// in DCR from the "start" height, using the historical ticket prices, pool
+// sizes, and vote rewards.
+// /stake/roi?amount=X&start=H
+func (c *appContext) getStakeROI(w http.ResponseWriter, r *http.Request) {
+	dcr, err := strconv.ParseFloat(r.URL.Query().Get("amount"), 64)
+	if err != nil || dcr <= 0 {
+		http.Error(w, "invalid amount", http.StatusUnprocessableEntity)
+		return
+	}
+	amount, err := dcrutil.NewAmount(dcr)
+	if err != nil || amount > dcrutil.MaxAmount {
+		http.Error(w, "invalid amount", http.StatusUnprocessableEntity)
+		return
+	}
+
+	start := c.Params.StakeEnabledHeight
+	if startParam := r.URL.Query().Get("start"); startParam != "" {
+		start, err = strconv.ParseInt(startParam, 10, 64)
+		if err != nil || start < 0 {
+			http.Error(w, "invalid start height", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	roi, err := c.DataSource.StakeROI(amount, start)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("StakeROI: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "start height is above the best block", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("StakeROI(%v, %d): %v", amount, start, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, roi, m.GetIndentCtx(r))
+}
+
+const (
+	// maxTxGraphDepth is the maximum depth of a transaction graph.
+	maxTxGraphDepth = 6
+	// maxTxGraphEdges is the maximum number of edges of a transaction graph in
+	// each direction, past which the graph is truncated.
+	maxTxGraphEdges = 2000
+)
+
+// getTxGraph serves the graph of the ancestors and descendants of the
+// transaction up to the hops of the "depth" URL query, which is 1 by default.
+// /tx/{txid}/graph?depth=N
+func (c *appContext) getTxGraph(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	depth := 1
+	if depthParam := r.URL.Query().Get("depth"); depthParam != "" {
+		depth, err = strconv.Atoi(depthParam)
+		if err != nil || depth < 1 || depth > maxTxGraphDepth {
+			http.Error(w, fmt.Sprintf("invalid depth, expected 1 to %d",
+				maxTxGraphDepth), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	graph, err := c.DataSource.TxGraph(txid.String(), depth, maxTxGraphEdges)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TxGraph: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "Transaction not found in the main chain.", http.StatusNotFound)
+		return
+	}
+	if err == dbtypes.ErrPruned {
+		http.Error(w, "The inputs and outputs of the transaction have been pruned.",
+			http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TxGraph(%s, %d): %v", txid, depth, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, graph, m.GetIndentCtx(r))
+}
+
+// getMempoolCongestion serves the mempool history aggregated by the period of
+// the time grouping in which the transactions were first seen.
+// /mempool/history/{chartgrouping}
+func (c *appContext) getMempoolCongestion(w http.ResponseWriter, r *http.Request) {
+	if c.mpHistory == nil {
+		http.Error(w, "Mempool history disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	chartGrouping := m.GetChartGroupingCtx(r)
+	grouping := dbtypes.TimeGroupingFromStr(chartGrouping)
+	// Each transaction has its own first seen time, so there is no "all".
+	if grouping == dbtypes.UnknownGrouping || grouping == dbtypes.AllGrouping {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	congestion, err := c.mpHistory.MempoolCongestion(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("MempoolCongestion: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("MempoolCongestion(%s): %v", chartGrouping, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, congestion, m.GetIndentCtx(r))
+}
+
+// getTSpend serves the details of a mined TSpend.
+// /treasury/tspend/{txid}
+func (c *appContext) getTSpend(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	tspend, err := c.DataSource.TSpend(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TSpend: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "not a mined TSpend", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TSpend(%v): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, tspend, m.GetIndentCtx(r))
+}
+
+// getSideChainBlock serves a side chain block, such as a block orphaned by a
+// reorg, with its transactions.
+// /block/side/{blockhash}
+func (c *appContext) getSideChainBlock(w http.ResponseWriter, r *http.Request) {
+	hash, err := m.GetBlockHashCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	block, err := c.DataSource.SideChainBlock(hash)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("SideChainBlock: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "not a stored side chain block", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("SideChainBlock(%s): %v", hash, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, block, m.GetIndentCtx(r))
+}
+
+// getTSpendVotes serves the stakeholder vote tally of a TSpend.
+// /treasury/tspend/{txid}/votes
+func (c *appContext) getTSpendVotes(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	tally, err := c.DataSource.TSpendVotes(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TSpendVotes: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TSpendVotes(%v): %v", txid, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, tally, m.GetIndentCtx(r))
+}
+
+// getTransactionInputs serves []TxIn
+func (c *appContext) getTransactionInputs(w http.ResponseWriter, r *http.Request) {
 	txid, err := m.GetTxIDCtx(r)
 	if err != nil {
 		http.Error(w, http.StatusText(422), 422)
@@ -1032,6 +2259,47 @@ func (c *appContext) getStakeDiffEstimates(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, stakeDiff.Estimates, m.GetIndentCtx(r))
 }
 
+// getStakeDiffPrediction serves the ticket price estimates for the next stake
+// difficulty window, without and with the ticket purchases in mempool assumed
+// to be mined in the current window.
+// /stake/diff/prediction
+func (c *appContext) getStakeDiffPrediction(w http.ResponseWriter, r *http.Request) {
+	var proj *pstypes.StakeDiffProjection
+	if c.sdiffProj != nil {
+		proj = c.sdiffProj.StakeDiffProjection()
+	}
+	if proj == nil {
+		http.Error(w, "Ticket price prediction not available.", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, stakeDiffPrediction(proj), m.GetIndentCtx(r))
+}
+
+// stakeDiffPrediction converts the ticket price projection into the estimate
+// bands without and with the mempool ticket purchases. The mempool tickets
+// raise the lower bound of the estimate to the projected price, since they
+// will be counted if mined in the current window.
+func stakeDiffPrediction(proj *pstypes.StakeDiffProjection) *apitypes.StakeDiffPrediction {
+	return &apitypes.StakeDiffPrediction{
+		Height:           proj.Height,
+		IdxBlockInWindow: proj.IdxBlockInWindow,
+		WindowSize:       proj.WindowSize,
+		BlocksRemaining:  proj.WindowSize - int64(proj.IdxBlockInWindow) - 1,
+		Current:          proj.Current,
+		MempoolTickets:   proj.MempoolTickets,
+		Estimate: apitypes.StakeDiffBand{
+			Min:      proj.Min,
+			Max:      proj.Max,
+			Expected: proj.Expected,
+		},
+		WithMempool: apitypes.StakeDiffBand{
+			Min:      math.Max(proj.Min, proj.Projected),
+			Max:      math.Max(proj.Max, proj.Projected),
+			Expected: math.Max(proj.Expected, proj.Projected),
+		},
+	}
+}
+
 func (c *appContext) getSSTxSummary(w http.ResponseWriter, r *http.Request) {
 	sstxSummary := c.DataSource.GetMempoolSSTxSummary()
 	if sstxSummary == nil {
@@ -1055,6 +2323,35 @@ func (c *appContext) getSSTxFees(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, sstxFees, m.GetIndentCtx(r))
 }
 
+// defaultFeeRateTargets are the numbers of blocks for which fee rates are
+// estimated by /mempool/feerates without a blocks query.
+var defaultFeeRateTargets = []int{1, 2, 3, 6, 12}
+
+// maxFeeRateTarget is the largest number of blocks for a fee rate estimate.
+const maxFeeRateTarget = 100
+
+// route: /mempool/feerates?blocks=N
+func (c *appContext) getMempoolFeeRates(w http.ResponseWriter, r *http.Request) {
+	if c.feeRates == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable),
+			http.StatusServiceUnavailable)
+		return
+	}
+
+	targets := defaultFeeRateTargets
+	if blocks := r.URL.Query().Get("blocks"); blocks != "" {
+		n, err := strconv.Atoi(blocks)
+		if err != nil || n < 1 || n > maxFeeRateTarget {
+			http.Error(w, fmt.Sprintf("Invalid blocks (1-%d).", maxFeeRateTarget),
+				http.StatusBadRequest)
+			return
+		}
+		targets = []int{n}
+	}
+
+	writeJSON(w, c.feeRates.FeeRates(targets), m.GetIndentCtx(r))
+}
+
 func (c *appContext) getSSTxDetails(w http.ResponseWriter, r *http.Request) {
 	N := m.GetNCtx(r)
 	sstxDetails := c.DataSource.GetMempoolSSTxDetails(N)
@@ -1154,6 +2451,104 @@ func (c *appContext) getProposalChartData(w http.ResponseWriter, r *http.Request
 	writeJSON(w, votesData, m.GetIndentCtx(r))
 }
 
+// getProposalVoteSnapshots serves the snapshots of a proposal's vote tally,
+// which are recorded while its vote is live.
+// /proposal/{token}/snapshots
+func (c *appContext) getProposalVoteSnapshots(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		errMsg := "piparser is disabled."
+		apiLog.Errorf("%s. Remove the disable-piparser flag to activate it.", errMsg)
+		http.Error(w, errMsg, http.StatusServiceUnavailable)
+		return
+	}
+
+	token := m.GetProposalTokenCtx(r)
+	snaps, err := c.DataSource.ProposalVoteSnapshots(token)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ProposalVoteSnapshots: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "no vote snapshots for the proposal", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("ProposalVoteSnapshots(%s): %v", token, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, snaps, m.GetIndentCtx(r))
+}
+
+// getProposalSpending serves the billing of an approved proposal with the
+// payments of its invoices, so the spending of treasury funds on the proposal
+// may be audited.
+// /proposal/{token}/spending
+func (c *appContext) getProposalSpending(w http.ResponseWriter, r *http.Request) {
+	if c.piBilling == nil {
+		http.Error(w, "proposal billing is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	token := m.GetProposalTokenCtx(r)
+	billing, err := c.piBilling.ProposalBilling(token)
+	if err != nil {
+		apiLog.Errorf("ProposalBilling(%s): %v", token, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if billing == nil {
+		http.Error(w, "no billing for the proposal", http.StatusNotFound)
+		return
+	}
+
+	spending := &apitypes.ProposalSpending{
+		Token:       billing.Token,
+		Title:       billing.Title,
+		TotalBilled: float64(billing.TotalBilled) / 100,
+		Invoices:    make([]apitypes.ProposalInvoice, 0, len(billing.Invoices)),
+		UpdatedAt:   billing.UpdatedAt,
+	}
+	for _, inv := range billing.Invoices {
+		invoice := apitypes.ProposalInvoice{
+			Token:          inv.Token,
+			Month:          inv.Month,
+			Year:           inv.Year,
+			Contractor:     inv.Contractor,
+			Billed:         float64(inv.Billed) / 100,
+			ExchangeRate:   float64(inv.ExchangeRate) / 100,
+			PaymentAddress: inv.PaymentAddress,
+			Payments:       []apitypes.ProposalPayment{},
+		}
+		if inv.ExchangeRate > 0 {
+			invoice.BilledDCR = float64(inv.Billed) / float64(inv.ExchangeRate)
+			spending.TotalBilledDCR += invoice.BilledDCR
+		}
+		if len(inv.TxIDs) > 0 && inv.PaymentAddress != "" {
+			payments, err := c.DataSource.InvoicePayments(inv.PaymentAddress, inv.TxIDs)
+			if dbtypes.IsTimeoutErr(err) {
+				apiLog.Errorf("InvoicePayments: %v", err)
+				http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				apiLog.Errorf("InvoicePayments(%s): %v", inv.PaymentAddress, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			for _, p := range payments {
+				invoice.Paid += p.Amount
+				invoice.Payments = append(invoice.Payments, p)
+			}
+		}
+		spending.Invoices = append(spending.Invoices, invoice)
+	}
+
+	writeJSON(w, spending, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getBlockSize(w http.ResponseWriter, r *http.Request) {
 	idx, err := c.getBlockHeightCtx(r)
 	if err != nil {
@@ -1482,6 +2877,7 @@ func (c *appContext) addressTotals(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	totals.Tag = c.DataSource.AddressTag(address)
 
 	writeJSON(w, totals, m.GetIndentCtx(r))
 }
@@ -1502,23 +2898,217 @@ func (c *appContext) addressExists(w http.ResponseWriter, r *http.Request) {
 		log.Warnf("existsaddress error: %v", err)
 		http.Error(w, http.StatusText(422), 422)
 	}
-	b, err := hex.DecodeString(strMask)
+	b, err := hex.DecodeString(strMask)
+	if err != nil {
+		log.Warnf("existsaddress error: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+	}
+	mask := binary.LittleEndian.Uint64(append(b, make([]byte, 8-len(b))...))
+	exists := make([]bool, 0, len(addresses))
+	for n := range addresses {
+		exists = append(exists, (mask&(1<<uint8(n))) != 0)
+	}
+	writeJSON(w, exists, m.GetIndentCtx(r))
+}
+
+// Handler for address activity CSV file download.
+// /download/address/io/{address}?cr=[true|false]
+func (c *appContext) addressIoCsv(w http.ResponseWriter, r *http.Request) {
+	// Check if ?cr=true was specified.
+	var useCRLF bool
+	if crlfParam := r.URL.Query().Get("cr"); crlfParam != "" {
+		b, err := strconv.ParseBool(crlfParam)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		useCRLF = b
+	}
+
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	address := addresses[0]
+
+	_, _, addrErr := txhelpers.AddressValidation(address, c.Params)
+	if addrErr != nil {
+		log.Debugf("Error validating address %s: %v", address, addrErr)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := c.DataSource.AddressTxIoCsv(address)
+	if err != nil {
+		log.Errorf("Failed to fetch AddressTxIoCsv: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("address-io-%s-%d-%s.csv", address,
+		c.Status.Height(), strconv.FormatInt(time.Now().Unix(), 10))
+
+	writeCSV(w, rows, filename, useCRLF)
+}
+
+// Handler for the balance of an address as of the mainchain block at a given
+// height.
+// /address/{address}/balance/{idx}
+func (c *appContext) addressBalanceAtHeight(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+	address := addresses[0]
+
+	height := int64(m.GetBlockIndexCtx(r))
+	if height < 0 {
+		http.Error(w, "invalid height", http.StatusBadRequest)
+		return
+	}
+
+	balance, err := c.DataSource.AddressBalanceAtHeight(address, height)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressBalanceAtHeight: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "no mainchain block at that height", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AddressBalanceAtHeight(%s, %d): %v", address, height, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &apitypes.AddressBalanceAtHeight{
+		Address: address,
+		Height:  height,
+		Balance: dcrutil.Amount(balance).ToCoin(),
+		Atoms:   balance,
+	}, m.GetIndentCtx(r))
+}
+
+// addressIOHistory computes the oldest-first funding and spending entries of
+// the valid mainchain address rows, with the running balance of the address.
+// Within a block time, funding entries precede spending entries so that the
+// balance is never negative.
+func addressIOHistory(rows []*dbtypes.AddressRowCompact) []*apitypes.AddressTxIO {
+	valid := make([]*dbtypes.AddressRowCompact, 0, len(rows))
+	for _, r := range rows {
+		if r.ValidMainChain {
+			valid = append(valid, r)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		if valid[i].TxBlockTime != valid[j].TxBlockTime {
+			return valid[i].TxBlockTime < valid[j].TxBlockTime
+		}
+		return valid[i].IsFunding && !valid[j].IsFunding
+	})
+
+	entries := make([]*apitypes.AddressTxIO, 0, len(valid))
+	var balance int64
+	for _, r := range valid {
+		amount := int64(r.Value)
+		var matching string
+		if !r.IsFunding {
+			amount = -amount
+		}
+		if r.MatchingTxHash != (chainhash.Hash{}) {
+			matching = r.MatchingTxHash.String()
+		}
+		balance += amount
+		entries = append(entries, &apitypes.AddressTxIO{
+			Time:           r.TxBlockTime,
+			TxHash:         r.TxHash.String(),
+			IOIndex:        r.TxVinVoutIndex,
+			TxType:         txhelpers.TxTypeToString(int(r.TxType)),
+			Amount:         dcrutil.Amount(amount).ToCoin(),
+			Balance:        dcrutil.Amount(balance).ToCoin(),
+			MatchingTxHash: matching,
+		})
+	}
+	return entries
+}
+
+// addressIOPage handles the common parts of the address history export
+// endpoints: address validation, retrieval of the address rows, and the
+// optional ?count=N&skip=M pagination. It writes an error response and returns
+// nil on failure. A count of 0 or no count selects the rest of the history.
+func (c *appContext) addressIOPage(w http.ResponseWriter, r *http.Request) *apitypes.AddressTxIOPage {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return nil
+	}
+	address := addresses[0]
+
+	var count, skip int
+	for param, val := range map[string]*int{"count": &count, "skip": &skip} {
+		str := r.URL.Query().Get(param)
+		if str == "" {
+			continue
+		}
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid %s", param), http.StatusBadRequest)
+			return nil
+		}
+		*val = n
+	}
+
+	rows, err := c.DataSource.AddressRowsCompact(address)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressRowsCompact: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return nil
+	}
 	if err != nil {
-		log.Warnf("existsaddress error: %v", err)
-		http.Error(w, http.StatusText(422), 422)
+		apiLog.Errorf("Failed to fetch address rows for %s: %v", address, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
 	}
-	mask := binary.LittleEndian.Uint64(append(b, make([]byte, 8-len(b))...))
-	exists := make([]bool, 0, len(addresses))
-	for n := range addresses {
-		exists = append(exists, (mask&(1<<uint8(n))) != 0)
+
+	// The running balance requires the full history, so the page is taken
+	// after computing it.
+	entries := addressIOHistory(rows)
+	page := &apitypes.AddressTxIOPage{
+		Address: address,
+		Total:   len(entries),
+		Skip:    skip,
 	}
-	writeJSON(w, exists, m.GetIndentCtx(r))
+	if skip > len(entries) {
+		skip = len(entries)
+	}
+	end := len(entries)
+	if count > 0 && skip+count < end {
+		end = skip + count
+	}
+	page.Entries = entries[skip:end]
+	return page
 }
 
-// Handler for address activity CSV file download.
-// /download/address/io/{address}?cr=[true|false]
-func (c *appContext) addressIoCsv(w http.ResponseWriter, r *http.Request) {
-	// Check if ?cr=true was specified.
+// Handler for the oldest-first address history with running balance as JSON.
+// /address/{address}/io/json?count=N&skip=M
+func (c *appContext) addressIOHistoryJSON(w http.ResponseWriter, r *http.Request) {
+	page := c.addressIOPage(w, r)
+	if page == nil {
+		return
+	}
+	writeJSON(w, page, m.GetIndentCtx(r))
+}
+
+// Handler for the oldest-first address history with running balance as CSV.
+// The rows are streamed rather than buffered, and the total number of entries
+// in the history is given by the X-Total-Count header.
+// /address/{address}/io/csv?count=N&skip=M&cr=[true|false]
+func (c *appContext) addressIOHistoryCSV(w http.ResponseWriter, r *http.Request) {
 	var useCRLF bool
 	if crlfParam := r.URL.Query().Get("cr"); crlfParam != "" {
 		b, err := strconv.ParseBool(crlfParam)
@@ -1526,35 +3116,40 @@ func (c *appContext) addressIoCsv(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-
 		useCRLF = b
 	}
 
-	addresses, err := m.GetAddressCtx(r, c.Params)
-	if err != nil || len(addresses) > 1 {
-		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	page := c.addressIOPage(w, r)
+	if page == nil {
 		return
 	}
-	address := addresses[0]
 
-	_, _, addrErr := txhelpers.AddressValidation(address, c.Params)
-	if addrErr != nil {
-		log.Debugf("Error validating address %s: %v", address, addrErr)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-		return
-	}
+	filename := fmt.Sprintf("address-history-%s-%d-%d.csv", page.Address,
+		c.Status.Height(), page.Skip)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment;filename=%s", filename))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
 
-	rows, err := c.DataSource.AddressTxIoCsv(address)
-	if err != nil {
-		log.Errorf("Failed to fetch AddressTxIoCsv: %v", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		return
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = useCRLF
+	_ = writer.Write([]string{"time_stamp", "tx_hash", "io_index", "tx_type",
+		"amount", "balance", "matching_tx_hash"})
+	for _, e := range page.Entries {
+		_ = writer.Write([]string{
+			strconv.FormatInt(e.Time, 10),
+			e.TxHash,
+			strconv.FormatUint(uint64(e.IOIndex), 10),
+			e.TxType,
+			strconv.FormatFloat(e.Amount, 'f', -1, 64),
+			strconv.FormatFloat(e.Balance, 'f', -1, 64),
+			e.MatchingTxHash,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		apiLog.Warnf("Failed to write address history CSV: %v", err)
 	}
-
-	filename := fmt.Sprintf("address-io-%s-%d-%s.csv", address,
-		c.Status.Height(), strconv.FormatInt(time.Now().Unix(), 10))
-
-	writeCSV(w, rows, filename, useCRLF)
 }
 
 func (c *appContext) getAddressTxTypesData(w http.ResponseWriter, r *http.Request) {
@@ -1625,7 +3220,17 @@ func (c *appContext) ChartTypeData(w http.ResponseWriter, r *http.Request) {
 		bin = r.URL.Query().Get("zoom")
 	}
 	axis := r.URL.Query().Get("axis")
-	chartData, err := c.charts.Chart(chartType, bin, axis)
+	var chartData []byte
+	var err error
+	if chartRange := r.URL.Query().Get("range"); chartRange != "" {
+		chartData, err = c.charts.RangedChart(chartType, bin, axis, chartRange)
+	} else {
+		chartData, err = c.charts.Chart(chartType, bin, axis)
+	}
+	if err == cache.InvalidRangeErr {
+		http.Error(w, "Invalid chart range.", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.NotFound(w, r)
 		log.Warnf(`Error fetching chart %s at bin level '%s': %v`, chartType, bin, err)
@@ -1634,6 +3239,161 @@ func (c *appContext) ChartTypeData(w http.ResponseWriter, r *http.Request) {
 	writeJSONBytes(w, chartData)
 }
 
+// getTimestampAnomalies serves the blocks with timestamps earlier than their
+// parents', most recent first.
+func (c *appContext) getTimestampAnomalies(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, c.charts.TimestampAnomalies(), m.GetIndentCtx(r))
+}
+
+// getCoinDaysDestroyed serves the value spent and the coin days destroyed in
+// each period of the time grouping.
+// /chart/coin-age/cdd/{chartgrouping}
+func (c *appContext) getCoinDaysDestroyed(w http.ResponseWriter, r *http.Request) {
+	if c.coinAge == nil {
+		http.Error(w, "Coin age analytics disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	chartGrouping := m.GetChartGroupingCtx(r)
+	grouping := dbtypes.TimeGroupingFromStr(chartGrouping)
+	if grouping == dbtypes.UnknownGrouping {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	cdd, err := c.coinAge.CoinDaysDestroyed(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("CoinDaysDestroyed: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("CoinDaysDestroyed(%s): %v", chartGrouping, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, cdd, m.GetIndentCtx(r))
+}
+
+// getCoinAgeBands serves the daily snapshots of the unspent value in each coin
+// age band.
+// /chart/coin-age/bands
+func (c *appContext) getCoinAgeBands(w http.ResponseWriter, r *http.Request) {
+	if c.coinAge == nil {
+		http.Error(w, "Coin age analytics disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	bands, err := c.coinAge.AgeBands()
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AgeBands: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AgeBands: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, bands, m.GetIndentCtx(r))
+}
+
+// getScriptTypeCounts serves the number and value of the mainchain outputs of
+// each script type in each period of the time grouping, by default each day.
+// /chart/script-types
+// /chart/script-types/{chartgrouping}
+func (c *appContext) getScriptTypeCounts(w http.ResponseWriter, r *http.Request) {
+	chartGrouping := m.GetChartGroupingCtx(r)
+	if chartGrouping == "" {
+		chartGrouping = dbtypes.DayGrouping.String()
+	}
+	grouping := dbtypes.TimeGroupingFromStr(chartGrouping)
+	if grouping == dbtypes.UnknownGrouping {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	counts, err := c.DataSource.ScriptTypeCounts(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ScriptTypeCounts: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("ScriptTypeCounts(%s): %v", chartGrouping, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, counts, m.GetIndentCtx(r))
+}
+
+// getBlockPropagation serves the propagation latency of the most recent
+// mainchain blocks. The number of blocks is set with the URL query ?n=N, up to
+// 10000.
+// /chart/block-propagation
+func (c *appContext) getBlockPropagation(w http.ResponseWriter, r *http.Request) {
+	n := int64(1000)
+	if nParam := r.URL.Query().Get("n"); nParam != "" {
+		var err error
+		n, err = strconv.ParseInt(nParam, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if n > 10000 {
+			n = 10000
+		}
+	}
+
+	prop, err := c.DataSource.BlockPropagation(n)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BlockPropagation: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BlockPropagation: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, prop, m.GetIndentCtx(r))
+}
+
+// getSearch serves the ranked results of a search for the URL query ?q=. The
+// number of results is set with ?limit=N.
+// /search
+func (c *appContext) getSearch(w http.ResponseWriter, r *http.Request) {
+	if c.searcher == nil {
+		http.Error(w, "Search disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Missing search query q.", http.StatusBadRequest)
+		return
+	}
+	var limit int
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := c.searcher.Search(query, limit)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Search: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Search: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, results, m.GetIndentCtx(r))
+}
+
 // route: /market/{token}/candlestick/{bin}
 func (c *appContext) getCandlestickChart(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
@@ -1649,13 +3409,118 @@ func (c *appContext) getCandlestickChart(w http.ResponseWriter, r *http.Request)
 
 	chart, err := c.xcBot.QuickSticks(token, bin)
 	if err != nil {
-		apiLog.Infof("QuickSticks error: %v", err)
-		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		return
+		// Until the exchange reports its candlesticks, e.g. after a restart,
+		// chart the stored candles.
+		var storedErr error
+		chart, storedErr = c.storedCandlestickChart(token, bin)
+		if storedErr != nil {
+			apiLog.Infof("QuickSticks error: %v, stored candles error: %v", err, storedErr)
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
 	}
 	writeJSONBytes(w, chart)
 }
 
+// storedCandlestickChart encodes the most recent stored market candles of the
+// exchange and bin like the ExchangeBot's candlestick chart data.
+func (c *appContext) storedCandlestickChart(token, bin string) ([]byte, error) {
+	width, ok := dbtypes.MarketCandleBins[bin]
+	if !ok {
+		return nil, fmt.Errorf("no stored candles for bin %s", bin)
+	}
+	to := time.Now()
+	candles, err := c.DataSource.MarketCandles(token, bin,
+		to.Add(-maxMarketCandles*width), to)
+	if err != nil {
+		return nil, err
+	}
+	sticks := make(exchanges.Candlesticks, 0, len(candles))
+	for _, candle := range candles {
+		sticks = append(sticks, exchanges.Candlestick{
+			High:   candle.High,
+			Low:    candle.Low,
+			Open:   candle.Open,
+			Close:  candle.Close,
+			Volume: candle.Volume,
+			Start:  candle.Start,
+		})
+	}
+	return c.xcBot.EncodeSticks(bin, sticks)
+}
+
+// maxMarketCandles is the maximum number of market candles served by
+// getMarketCandles.
+const maxMarketCandles = 2000
+
+// getMarketCandles serves the stored OHLCV candles of an exchange's DCR-BTC
+// market, or of the aggregated market, for the "bin" URL query parameter (1m,
+// 5m, 1h or 1d, default is 1h), starting in the range from the "from" to the
+// "to" parameters (UNIX seconds). The default range is the maxMarketCandles
+// bins until now, and at most maxMarketCandles candles following from are
+// served.
+// /market/{token}/candles?bin=1h&from=X&to=Y
+func (c *appContext) getMarketCandles(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	token := m.RetrieveExchangeTokenCtx(r)
+	if _, found := c.xcBot.DcrBtcExchanges[token]; !found && token != dbtypes.AggregatedMarket {
+		http.Error(w, "unknown exchange "+token, http.StatusNotFound)
+		return
+	}
+
+	bin := r.URL.Query().Get("bin")
+	if bin == "" {
+		bin = "1h"
+	}
+	width, ok := dbtypes.MarketCandleBins[bin]
+	if !ok {
+		http.Error(w, "invalid bin "+bin, http.StatusUnprocessableEntity)
+		return
+	}
+	maxRange := maxMarketCandles * width
+
+	to := time.Now()
+	if toParam := r.URL.Query().Get("to"); toParam != "" {
+		toUnix, err := strconv.ParseInt(toParam, 10, 64)
+		if err != nil || toUnix < 0 {
+			http.Error(w, "invalid to time", http.StatusUnprocessableEntity)
+			return
+		}
+		to = time.Unix(toUnix, 0)
+	}
+	from := to.Add(-maxRange)
+	if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+		fromUnix, err := strconv.ParseInt(fromParam, 10, 64)
+		if err != nil || fromUnix < 0 || fromUnix > to.Unix() {
+			http.Error(w, "invalid from time", http.StatusUnprocessableEntity)
+			return
+		}
+		from = time.Unix(fromUnix, 0)
+		if to.Sub(from) > maxRange {
+			to = from.Add(maxRange)
+		}
+	}
+
+	candles, err := c.DataSource.MarketCandles(token, bin, from, to)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("MarketCandles: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("MarketCandles(%s, %s, %v, %v): %v", token, bin, from, to, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if candles == nil {
+		candles = []*dbtypes.MarketCandle{}
+	}
+	writeJSON(w, candles, m.GetIndentCtx(r))
+}
+
 // route: /market/{token}/depth
 func (c *appContext) getDepthChart(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
@@ -1677,6 +3542,22 @@ func (c *appContext) getDepthChart(w http.ResponseWriter, r *http.Request) {
 	writeJSONBytes(w, chart)
 }
 
+// route: /exchanges/depth
+func (c *appContext) getConsolidatedDepth(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	chart, err := c.xcBot.QuickConsolidatedDepth()
+	if err != nil {
+		apiLog.Infof("QuickConsolidatedDepth error: %v", err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSONBytes(w, chart)
+}
+
 func (c *appContext) getAddressTransactions(w http.ResponseWriter, r *http.Request) {
 	addresses, err := m.GetAddressCtx(r, c.Params)
 	if err != nil || len(addresses) > 1 {
@@ -1707,9 +3588,64 @@ func (c *appContext) getAddressTransactions(w http.ResponseWriter, r *http.Reque
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
+	txs.Tag = c.DataSource.AddressTag(address)
 	writeJSON(w, txs, m.GetIndentCtx(r))
 }
 
+// getAddressTag serves the label of the address, or 404 if it has none.
+// /address/{address}/tag
+func (c *appContext) getAddressTag(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	tag := c.DataSource.AddressTag(addresses[0])
+	if tag == nil {
+		http.Error(w, "No label for the address.", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, tag, m.GetIndentCtx(r))
+}
+
+// getAddressTags serves the labels of the known addresses with the category
+// given by the "category" URL query parameter, or all labels if not set.
+// /addresses/tags
+func (c *appContext) getAddressTags(w http.ResponseWriter, r *http.Request) {
+	tags := c.DataSource.AddressTags(r.URL.Query().Get("category"))
+	writeJSON(w, tags, m.GetIndentCtx(r))
+}
+
+// getAddressCluster serves the cluster of addresses linked to the address by
+// the common-input-ownership heuristic, and the cluster's balance.
+// /address/{address}/cluster
+func (c *appContext) getAddressCluster(w http.ResponseWriter, r *http.Request) {
+	if c.clusters == nil {
+		http.Error(w, "Address clustering disabled.", http.StatusServiceUnavailable)
+		return
+	}
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	address := addresses[0]
+
+	cluster, err := c.clusters.AddressCluster(address)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressCluster: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AddressCluster(%s): %v", address, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, cluster, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getAddressTransactionsRaw(w http.ResponseWriter, r *http.Request) {
 	addresses, err := m.GetAddressCtx(r, c.Params)
 	if err != nil || len(addresses) > 1 {
@@ -1776,6 +3712,33 @@ func (c *appContext) getAgendaData(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, data, "")
 }
 
+// getAgendaVoteTimeSeries serves the agenda's vote counts in each interval of
+// its voting period.
+// /agenda/{agendaId}/votes/timeseries
+func (c *appContext) getAgendaVoteTimeSeries(w http.ResponseWriter, r *http.Request) {
+	agendaId := m.GetAgendaIdCtx(r)
+	if agendaId == "" {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	series, err := c.DataSource.AgendaVoteTimeSeries(agendaId)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AgendaVoteTimeSeries: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AgendaVoteTimeSeries(%s): %v", agendaId, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, series, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getExchanges(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
 		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
@@ -1802,6 +3765,27 @@ func (c *appContext) getExchanges(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, state, m.GetIndentCtx(r))
 }
 
+// getExchangeRate serves the weighted median DCR exchange rate in the currency
+// given by the "currency" URL query parameter, the default index if not set.
+// /exchangerate
+func (c *appContext) getExchangeRate(w http.ResponseWriter, r *http.Request) {
+	if c.xcBot == nil {
+		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	code := strings.ToUpper(r.URL.Query().Get("currency"))
+	if code == "" {
+		code = c.xcBot.BtcIndex
+	}
+	rate, err := c.xcBot.ExchangeRate(code)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("No exchange rate for currency %s", code), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, rate, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getCurrencyCodes(w http.ResponseWriter, r *http.Request) {
 	if c.xcBot == nil {
 		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
@@ -0,0 +1,1705 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	m "github.com/decred/dcrdata/middleware/v3"
+	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
+	"github.com/go-chi/chi"
+)
+
+// A valid mainnet address for the handlers that decode the address.
+const stubMainnetAddress = "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx"
+
+func (ds *dataSourceStub) AddressRowsCompact(address string) ([]*dbtypes.AddressRowCompact, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	return ds.addrRows, nil
+}
+
+// AddressBalanceAtHeight returns the stub's address balance, or sql.ErrNoRows
+// for a height above the stub's best block.
+func (ds *dataSourceStub) AddressBalanceAtHeight(address string, height int64) (int64, error) {
+	if ds.addrErr != nil {
+		return 0, ds.addrErr
+	}
+	if height > ds.Height() {
+		return 0, sql.ErrNoRows
+	}
+	return ds.addrBal, nil
+}
+
+// ChainReorgs returns a page of the stub's reorgs.
+func (ds *dataSourceStub) ChainReorgs(N, offset int64) (*apitypes.ChainReorgs, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	page := &apitypes.ChainReorgs{
+		Total:  int64(len(ds.reorgs)),
+		Reorgs: []*dbtypes.Reorg{},
+	}
+	for i := offset; i < int64(len(ds.reorgs)) && i < offset+N; i++ {
+		page.Reorgs = append(page.Reorgs, ds.reorgs[i])
+	}
+	return page, nil
+}
+
+func (ds *dataSourceStub) RichList(N int) (*apitypes.RichList, error) {
+	ds.richN = N
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	return ds.richList, nil
+}
+
+func (ds *dataSourceStub) SideChainBlock(hash string) (*apitypes.SideChainBlock, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	block, found := ds.sideBlks[hash]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return block, nil
+}
+
+func (ds *dataSourceStub) AgendaVoteTimeSeries(agendaID string) (*apitypes.AgendaVoteTimeSeries, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	series, found := ds.agendaTS[agendaID]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return series, nil
+}
+
+func (ds *dataSourceStub) TicketLifecycle(txid string) (*apitypes.TicketLifecycle, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	lifecycle, found := ds.tickets[txid]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return lifecycle, nil
+}
+
+// UTXOSetStats returns sql.ErrNoRows for the pruned outputs at height 0.
+func (ds *dataSourceStub) UTXOSetStats(height int64) (*apitypes.UTXOSetStats, error) {
+	ds.utxoAt = height
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	if height == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if height < 0 {
+		height = ds.Height()
+	}
+	return &apitypes.UTXOSetStats{
+		Height:     height,
+		Count:      3,
+		TotalValue: 12.5,
+		ScriptTypes: []apitypes.UTXOScriptTypeStats{
+			{ScriptType: "pubkeyhash", Count: 2, Value: 12},
+			{ScriptType: "stakesubmission-pubkeyhash", Count: 1, Value: 0.5},
+		},
+	}, nil
+}
+
+// GetStakeVersionsLatest returns stake version 8 as the latest.
+func (ds *dataSourceStub) GetStakeVersionsLatest() (*chainjson.StakeVersions, error) {
+	return &chainjson.StakeVersions{StakeVersion: 8}, nil
+}
+
+func (ds *dataSourceStub) VoteVersionHistory(grouping dbtypes.TimeBasedGrouping, version uint32) (*apitypes.VoteVersionHistory, error) {
+	ds.voteVer = version
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	return &apitypes.VoteVersionHistory{
+		Version:    version,
+		Counts:     map[uint32][]int64{7: {3}, 8: {2}},
+		Total:      []int64{5},
+		OldPercent: []float64{60},
+	}, nil
+}
+
+func (ds *dataSourceStub) VoteBitsStats(version uint32, startHeight, endHeight int64) (*apitypes.VoteBitsStats, error) {
+	ds.voteVer, ds.voteFrom, ds.voteTo = version, startHeight, endHeight
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	return &apitypes.VoteBitsStats{
+		Version:     version,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		VoteBits:    []apitypes.VoteBitsCount{},
+		Agendas:     []apitypes.VoteAgendaChoices{},
+	}, nil
+}
+
+func (ds *dataSourceStub) AddressTag(address string) *dbtypes.AddressTag {
+	for _, tag := range ds.tags {
+		if tag.Address == address {
+			return tag
+		}
+	}
+	return nil
+}
+
+func (ds *dataSourceStub) AddressTags(category string) []*dbtypes.AddressTag {
+	tags := make([]*dbtypes.AddressTag, 0, len(ds.tags))
+	for _, tag := range ds.tags {
+		if category == "" || tag.Category == category {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (ds *dataSourceStub) RecentSwaps(N, offset int64) ([]*apitypes.AtomicSwap, error) {
+	ds.count, ds.skip = N, offset
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	swaps := []*apitypes.AtomicSwap{}
+	for i := offset; i < int64(len(ds.swaps)) && i < offset+N; i++ {
+		swaps = append(swaps, ds.swaps[i])
+	}
+	return swaps, nil
+}
+
+func (ds *dataSourceStub) TxSwaps(txid string) ([]*apitypes.AtomicSwap, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	swaps := []*apitypes.AtomicSwap{}
+	for _, swap := range ds.swaps {
+		if swap.ContractTx == txid || swap.SpendTx == txid {
+			swaps = append(swaps, swap)
+		}
+	}
+	return swaps, nil
+}
+
+func (ds *dataSourceStub) TxGraph(txid string, depth, maxEdges int) (*apitypes.TxGraph, error) {
+	ds.depth = depth
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	if ds.txGraph == nil || ds.txGraph.TxID != txid {
+		return nil, sql.ErrNoRows
+	}
+	return ds.txGraph, nil
+}
+
+func (ds *dataSourceStub) StakeROI(amount dcrutil.Amount, start int64) (*apitypes.StakeROI, error) {
+	ds.roiAmt, ds.roiStart = amount, start
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	return ds.roi, nil
+}
+
+func (ds *dataSourceStub) ProposalVoteSnapshots(token string) (*apitypes.ProposalVoteSnapshots, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	snaps, found := ds.snaps[token]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return snaps, nil
+}
+
+// addressIORows are the rows of an address history out of order, including a
+// spend in the same block as a funding entry, and a funding row that is not in
+// a valid mainchain block.
+func addressIORows() []*dbtypes.AddressRowCompact {
+	return []*dbtypes.AddressRowCompact{{
+		TxBlockTime:    100,
+		TxHash:         chainhash.Hash{1},
+		ValidMainChain: true,
+		IsFunding:      true,
+		Value:          5e8,
+	}, {
+		TxBlockTime:    200,
+		TxHash:         chainhash.Hash{2},
+		TxVinVoutIndex: 1,
+		MatchingTxHash: chainhash.Hash{1},
+		ValidMainChain: true,
+		Value:          2e8,
+	}, {
+		TxBlockTime: 150,
+		TxHash:      chainhash.Hash{4},
+		IsFunding:   true,
+		Value:       7e8,
+	}, {
+		TxBlockTime:    200,
+		TxHash:         chainhash.Hash{3},
+		TxType:         2, // vote
+		ValidMainChain: true,
+		IsFunding:      true,
+		Value:          1e8,
+	}}
+}
+
+func TestAddressIOHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []*dbtypes.AddressRowCompact
+		want []*apitypes.AddressTxIO
+	}{{
+		name: "no rows",
+		want: []*apitypes.AddressTxIO{},
+	}, {
+		name: "history",
+		rows: addressIORows(),
+		want: []*apitypes.AddressTxIO{{
+			Time:    100,
+			TxHash:  chainhash.Hash{1}.String(),
+			TxType:  "Regular",
+			Amount:  5,
+			Balance: 5,
+		}, {
+			Time:    200,
+			TxHash:  chainhash.Hash{3}.String(),
+			TxType:  "Vote",
+			Amount:  1,
+			Balance: 6,
+		}, {
+			Time:           200,
+			TxHash:         chainhash.Hash{2}.String(),
+			IOIndex:        1,
+			TxType:         "Regular",
+			Amount:         -2,
+			Balance:        4,
+			MatchingTxHash: chainhash.Hash{1}.String(),
+		}},
+	}}
+
+	for _, test := range tests {
+		got := addressIOHistory(test.rows)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected entries", test.name)
+			for _, e := range test.want {
+				t.Logf("\t%+v", e)
+			}
+			t.Logf("got")
+			for _, e := range got {
+				t.Logf("\t%+v", e)
+			}
+		}
+	}
+}
+
+func TestAddressIOExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		address    string
+		query      string
+		csv        bool
+		addrErr    error
+		wantStatus int
+		wantHashes []byte // first bytes of the hashes of the entries
+		wantSkip   int
+	}{{
+		name:       "all entries",
+		address:    stubMainnetAddress,
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{1, 3, 2},
+	}, {
+		name:       "page",
+		address:    stubMainnetAddress,
+		query:      "?count=1&skip=1",
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{3},
+		wantSkip:   1,
+	}, {
+		name:       "count beyond end",
+		address:    stubMainnetAddress,
+		query:      "?count=10&skip=2",
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{2},
+		wantSkip:   2,
+	}, {
+		name:       "skip beyond end",
+		address:    stubMainnetAddress,
+		query:      "?skip=5",
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{},
+		wantSkip:   5,
+	}, {
+		name:       "csv",
+		address:    stubMainnetAddress,
+		query:      "?count=2",
+		csv:        true,
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{1, 3},
+	}, {
+		name:       "csv with CRLF",
+		address:    stubMainnetAddress,
+		query:      "?cr=true",
+		csv:        true,
+		wantStatus: http.StatusOK,
+		wantHashes: []byte{1, 3, 2},
+	}, {
+		name:       "invalid cr",
+		address:    stubMainnetAddress,
+		query:      "?cr=maybe",
+		csv:        true,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid count",
+		address:    stubMainnetAddress,
+		query:      "?count=-1",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid skip",
+		address:    stubMainnetAddress,
+		query:      "?skip=x",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid address",
+		address:    "Dsxyz",
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "timeout",
+		address:    stubMainnetAddress,
+		addrErr:    errors.New(dbtypes.TimeoutPrefix),
+		wantStatus: http.StatusServiceUnavailable,
+	}, {
+		name:       "database error",
+		address:    stubMainnetAddress,
+		addrErr:    errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.addrRows = addressIORows()
+		ds.addrErr = test.addrErr
+		app := &appContext{
+			Params:     chaincfg.MainNetParams(),
+			DataSource: ds,
+			Status:     apitypes.NewStatus(1000, 1, 1, "test", "mainnet"),
+		}
+
+		handler, path := app.addressIOHistoryJSON, "/io/json"
+		if test.csv {
+			handler, path = app.addressIOHistoryCSV, "/io/csv"
+		}
+		req := httptest.NewRequest(http.MethodGet, "/address/"+test.address+path+test.query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), m.CtxAddress,
+			[]string{test.address}))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var hashes []string
+		if test.csv {
+			if total := rr.Header().Get("X-Total-Count"); total != "3" {
+				t.Errorf("%s: expected X-Total-Count 3, got %q", test.name, total)
+			}
+			records, err := csv.NewReader(rr.Body).ReadAll()
+			if err != nil {
+				t.Errorf("%s: invalid CSV: %v", test.name, err)
+				continue
+			}
+			if len(records) == 0 || records[0][0] != "time_stamp" {
+				t.Errorf("%s: missing CSV header", test.name)
+				continue
+			}
+			for _, record := range records[1:] {
+				hashes = append(hashes, record[1])
+			}
+		} else {
+			var page apitypes.AddressTxIOPage
+			if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+				t.Errorf("%s: invalid JSON: %v", test.name, err)
+				continue
+			}
+			if page.Address != test.address || page.Total != 3 || page.Skip != test.wantSkip {
+				t.Errorf("%s: wrong page of %s, total %d, skip %d", test.name,
+					page.Address, page.Total, page.Skip)
+			}
+			for _, e := range page.Entries {
+				hashes = append(hashes, e.TxHash)
+			}
+		}
+
+		if len(hashes) != len(test.wantHashes) {
+			t.Errorf("%s: expected %d entries, got %d", test.name, len(test.wantHashes), len(hashes))
+			continue
+		}
+		for i, b := range test.wantHashes {
+			if hashes[i] != (chainhash.Hash{b}).String() {
+				t.Errorf("%s: expected entry %d to be %v, got %s", test.name, i,
+					chainhash.Hash{b}, hashes[i])
+			}
+		}
+	}
+}
+
+func TestAddressBalanceAtHeight(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		addrErr    error
+		wantStatus int
+		wantHeight int64
+	}{{
+		name:       "best block",
+		path:       "/address/" + stubMainnetAddress + "/balance/1",
+		wantStatus: http.StatusOK,
+		wantHeight: 1,
+	}, {
+		name:       "genesis block",
+		path:       "/address/" + stubMainnetAddress + "/balance/0",
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "height above best",
+		path:       "/address/" + stubMainnetAddress + "/balance/2",
+		wantStatus: http.StatusNotFound,
+	}, {
+		name:       "negative height",
+		path:       "/address/" + stubMainnetAddress + "/balance/-1",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid height",
+		path:       "/address/" + stubMainnetAddress + "/balance/best",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid address",
+		path:       "/address/" + stubAddress + "/balance/1",
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "timeout",
+		path:       "/address/" + stubMainnetAddress + "/balance/1",
+		addrErr:    errors.New(dbtypes.TimeoutPrefix),
+		wantStatus: http.StatusServiceUnavailable,
+	}, {
+		name:       "database error",
+		path:       "/address/" + stubMainnetAddress + "/balance/1",
+		addrErr:    errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.addrBal = 150000000
+		ds.addrErr = test.addrErr
+		app := &appContext{
+			Params:     chaincfg.MainNetParams(),
+			DataSource: ds,
+		}
+		router := chi.NewRouter()
+		router.With(m.AddressPathCtxN(1), m.BlockIndexPathCtx).
+			Get("/address/{address}/balance/{idx}", app.addressBalanceAtHeight)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var balance apitypes.AddressBalanceAtHeight
+		if err := json.Unmarshal(rr.Body.Bytes(), &balance); err != nil {
+			t.Errorf("%s: invalid JSON: %v", test.name, err)
+			continue
+		}
+		want := apitypes.AddressBalanceAtHeight{
+			Address: stubMainnetAddress,
+			Height:  test.wantHeight,
+			Balance: 1.5,
+			Atoms:   150000000,
+		}
+		if balance != want {
+			t.Errorf("%s: expected balance %+v, got %+v", test.name, want, balance)
+		}
+	}
+}
+
+func TestChainReorgs(t *testing.T) {
+	reorgs := make([]*dbtypes.Reorg, 2500)
+	for i := range reorgs {
+		reorgs[i] = &dbtypes.Reorg{Depth: int64(i)}
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantDepths []int64 // first and last
+		wantCount  int
+	}{{
+		name:       "default",
+		path:       "/chain/reorgs",
+		wantStatus: http.StatusOK,
+		wantDepths: []int64{0, 99},
+		wantCount:  100,
+	}, {
+		name:       "count",
+		path:       "/chain/reorgs/count/5",
+		wantStatus: http.StatusOK,
+		wantDepths: []int64{0, 4},
+		wantCount:  5,
+	}, {
+		name:       "count and skip",
+		path:       "/chain/reorgs/count/2/skip/10",
+		wantStatus: http.StatusOK,
+		wantDepths: []int64{10, 11},
+		wantCount:  2,
+	}, {
+		name:       "count above limit",
+		path:       "/chain/reorgs/count/5000",
+		wantStatus: http.StatusOK,
+		wantDepths: []int64{0, 1999},
+		wantCount:  2000,
+	}, {
+		name:       "skip past the end",
+		path:       "/chain/reorgs/count/10/skip/3000",
+		wantStatus: http.StatusOK,
+	}, {
+		name:       "timeout",
+		path:       "/chain/reorgs",
+		err:        errors.New(dbtypes.TimeoutPrefix),
+		wantStatus: http.StatusServiceUnavailable,
+	}, {
+		name:       "database error",
+		path:       "/chain/reorgs",
+		err:        errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.reorgs = reorgs
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Route("/chain/reorgs", func(rr chi.Router) {
+			rr.Get("/", app.getChainReorgs)
+			rr.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getChainReorgs)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getChainReorgs)
+			})
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var page apitypes.ChainReorgs
+		if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if page.Total != int64(len(reorgs)) {
+			t.Errorf("%s: expected total %d, got %d", test.name, len(reorgs), page.Total)
+		}
+		if len(page.Reorgs) != test.wantCount {
+			t.Errorf("%s: expected %d reorgs, got %d", test.name, test.wantCount, len(page.Reorgs))
+			continue
+		}
+		if test.wantCount == 0 {
+			continue
+		}
+		depths := []int64{page.Reorgs[0].Depth, page.Reorgs[len(page.Reorgs)-1].Depth}
+		if !reflect.DeepEqual(depths, test.wantDepths) {
+			t.Errorf("%s: expected first and last depths %v, got %v", test.name,
+				test.wantDepths, depths)
+		}
+	}
+}
+
+func TestRichList(t *testing.T) {
+	richList := &apitypes.RichList{
+		Height: 1,
+		Addresses: []apitypes.RichListAddress{{
+			Rank:    1,
+			Address: stubMainnetAddress,
+			Balance: 1500,
+		}},
+		Distribution: []apitypes.BalanceRange{{
+			MinBalance:   1000,
+			NumAddresses: 1,
+			TotalBalance: 1500,
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantN      int
+	}{
+		{"default", "/addresses/rich", nil, http.StatusOK, 100},
+		{"n", "/addresses/rich?n=10", nil, http.StatusOK, 10},
+		{"zero n", "/addresses/rich?n=0", nil, http.StatusBadRequest, 0},
+		{"invalid n", "/addresses/rich?n=ten", nil, http.StatusBadRequest, 0},
+		{"timeout", "/addresses/rich", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable, 100},
+		{"database error", "/addresses/rich", errors.New("connection refused"),
+			http.StatusInternalServerError, 100},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.richList = richList
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Get("/addresses/rich", app.getRichList)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.richN != test.wantN {
+			t.Errorf("%s: expected n %d, got %d", test.name, test.wantN, ds.richN)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.RichList
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Addresses, richList.Addresses) ||
+			!reflect.DeepEqual(got.Distribution, richList.Distribution) {
+			t.Errorf("%s: expected rich list %+v, got %+v", test.name, richList, got)
+		}
+	}
+}
+
+// feeRatesStub is a FeeRateEstimator that records the targets of the last
+// FeeRates call.
+type feeRatesStub struct {
+	targets []int
+}
+
+func (fr *feeRatesStub) FeeRates(targets []int) *apitypes.MempoolFeeRates {
+	fr.targets = targets
+	rates := &apitypes.MempoolFeeRates{Estimates: []apitypes.FeeRateEstimate{}}
+	for _, n := range targets {
+		rates.Estimates = append(rates.Estimates, apitypes.FeeRateEstimate{
+			Blocks:  n,
+			FeeRate: 0.0001,
+		})
+	}
+	return rates
+}
+
+func TestMempoolFeeRates(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		noEstimator bool
+		wantStatus  int
+		wantTargets []int
+	}{
+		{"default", "/mempool/feerates", false, http.StatusOK, defaultFeeRateTargets},
+		{"blocks", "/mempool/feerates?blocks=4", false, http.StatusOK, []int{4}},
+		{"max blocks", "/mempool/feerates?blocks=100", false, http.StatusOK, []int{100}},
+		{"zero blocks", "/mempool/feerates?blocks=0", false, http.StatusBadRequest, nil},
+		{"too many blocks", "/mempool/feerates?blocks=101", false, http.StatusBadRequest, nil},
+		{"invalid blocks", "/mempool/feerates?blocks=x", false, http.StatusBadRequest, nil},
+		{"no estimator", "/mempool/feerates", true, http.StatusServiceUnavailable, nil},
+	}
+
+	for _, test := range tests {
+		feeRates := new(feeRatesStub)
+		app := &appContext{DataSource: newDataSourceStub(), feeRates: feeRates}
+		if test.noEstimator {
+			app.feeRates = nil
+		}
+		router := chi.NewRouter()
+		router.Get("/mempool/feerates", app.getMempoolFeeRates)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if !reflect.DeepEqual(feeRates.targets, test.wantTargets) {
+			t.Errorf("%s: expected targets %v, got %v", test.name, test.wantTargets,
+				feeRates.targets)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.MempoolFeeRates
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if len(got.Estimates) != len(test.wantTargets) {
+			t.Errorf("%s: expected %d estimates, got %d", test.name,
+				len(test.wantTargets), len(got.Estimates))
+		}
+	}
+}
+
+func TestSideChainBlock(t *testing.T) {
+	block := &apitypes.SideChainBlock{
+		Hash:         stubBlockHash1,
+		Height:       1,
+		PreviousHash: stubBlockHash0,
+		IsValid:      true,
+		Size:         39663,
+		Tx: []apitypes.SideChainTx{{
+			TxID: stubTxID,
+			Type: "Regular",
+			Size: 250,
+		}},
+		STx: []apitypes.SideChainTx{},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+	}{
+		{"stored", "/block/side/" + stubBlockHash1, nil, http.StatusOK},
+		{"not stored", "/block/side/" + stubBlockHash0, nil, http.StatusNotFound},
+		{"invalid hash", "/block/side/1234", nil, http.StatusUnprocessableEntity},
+		{"timeout", "/block/side/" + stubBlockHash1, errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable},
+		{"database error", "/block/side/" + stubBlockHash1, errors.New("connection refused"),
+			http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.sideBlks = map[string]*apitypes.SideChainBlock{stubBlockHash1: block}
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.With(m.BlockHashPathCtx).Get("/block/side/{blockhash}", app.getSideChainBlock)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.SideChainBlock
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if got.Hash != block.Hash || got.PreviousHash != block.PreviousHash ||
+			!reflect.DeepEqual(got.Tx, block.Tx) || len(got.STx) != 0 {
+			t.Errorf("%s: expected block %+v, got %+v", test.name, block, got)
+		}
+	}
+}
+
+func TestAgendaVoteTimeSeries(t *testing.T) {
+	series := &apitypes.AgendaVoteTimeSeries{
+		AgendaID:      "treasury",
+		VotingStarted: 8064,
+		VotingDone:    16127,
+		IntervalSize:  144,
+		Intervals: []apitypes.AgendaVoteInterval{{
+			StartHeight: 8064,
+			EndHeight:   8207,
+			Votes:       apitypes.AgendaVoteCounts{Yes: 500, Abstain: 100, No: 100, Total: 700},
+			Cumulative:  apitypes.AgendaVoteCounts{Yes: 500, Abstain: 100, No: 100, Total: 700},
+		}, {
+			StartHeight: 8208,
+			EndHeight:   8250,
+			Votes:       apitypes.AgendaVoteCounts{Yes: 200, No: 10, Total: 210},
+			Cumulative:  apitypes.AgendaVoteCounts{Yes: 700, Abstain: 100, No: 110, Total: 910},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+	}{
+		{"agenda", "/agenda/treasury/votes/timeseries", nil, http.StatusOK},
+		{"unknown agenda", "/agenda/unknown/votes/timeseries", nil, http.StatusNotFound},
+		{"timeout", "/agenda/treasury/votes/timeseries", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable},
+		{"database error", "/agenda/treasury/votes/timeseries", errors.New("connection refused"),
+			http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.agendaTS = map[string]*apitypes.AgendaVoteTimeSeries{"treasury": series}
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.With(m.AgendaIdCtx).Get("/agenda/{agendaId}/votes/timeseries",
+			app.getAgendaVoteTimeSeries)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.AgendaVoteTimeSeries
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if got.AgendaID != series.AgendaID || got.IntervalSize != series.IntervalSize ||
+			len(got.Intervals) != len(series.Intervals) {
+			t.Fatalf("%s: expected series %+v, got %+v", test.name, series, got)
+		}
+		for i, iv := range got.Intervals {
+			want := series.Intervals[i]
+			if iv.StartHeight != want.StartHeight || iv.EndHeight != want.EndHeight ||
+				iv.Votes != want.Votes || iv.Cumulative != want.Cumulative {
+				t.Errorf("%s: expected interval %+v, got %+v", test.name, want, iv)
+			}
+		}
+	}
+}
+
+func TestTicketLifecycle(t *testing.T) {
+	const unknownTicket = "0000000000000000000000000000000000000000000000000000000000000001"
+	lifecycle := &apitypes.TicketLifecycle{
+		TicketHash:       stubTxID,
+		Status:           "voted",
+		PurchaseBlock:    &apitypes.TinyBlock{Hash: stubBlockHash1, Height: 1},
+		PurchaseTime:     1454954535,
+		Price:            2,
+		MaturityHeight:   257,
+		ExpirationHeight: 41217,
+		Spend: &apitypes.TicketSpend{
+			TxID:  stubTxID,
+			Block: apitypes.TinyBlock{Hash: stubBlockHash0, Height: 300},
+			Time:  1454954535 + 2*86400,
+		},
+		SpendType: "Voted",
+		Reward:    1.6,
+		DaysHeld:  2,
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+	}{
+		{"ticket", "/ticket/" + stubTxID + "/lifecycle", nil, http.StatusOK},
+		{"unknown ticket", "/ticket/" + unknownTicket + "/lifecycle", nil, http.StatusNotFound},
+		{"invalid txid", "/ticket/1234/lifecycle", nil, http.StatusUnprocessableEntity},
+		{"timeout", "/ticket/" + stubTxID + "/lifecycle", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable},
+		{"database error", "/ticket/" + stubTxID + "/lifecycle", errors.New("connection refused"),
+			http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.tickets = map[string]*apitypes.TicketLifecycle{stubTxID: lifecycle}
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Route("/ticket/{txid}", func(r chi.Router) {
+			r.Use(m.TransactionHashCtx)
+			r.Get("/lifecycle", app.getTicketLifecycle)
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.TicketLifecycle
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(&got, lifecycle) {
+			t.Errorf("%s: expected lifecycle %+v, got %+v", test.name, lifecycle, got)
+		}
+	}
+}
+
+// stakeDiffProjStub is a StakeDiffProjector with a fixed projection.
+type stakeDiffProjStub struct {
+	proj *pstypes.StakeDiffProjection
+}
+
+func (sp *stakeDiffProjStub) StakeDiffProjection() *pstypes.StakeDiffProjection {
+	return sp.proj
+}
+
+func TestStakeDiffPrediction(t *testing.T) {
+	tests := []struct {
+		name      string
+		proj      *pstypes.StakeDiffProjection
+		wantBand  apitypes.StakeDiffBand
+		remaining int64
+	}{
+		{"projected below the estimate", &pstypes.StakeDiffProjection{
+			IdxBlockInWindow: 0, WindowSize: 144, Min: 100, Max: 200, Expected: 150,
+			Projected: 90,
+		}, apitypes.StakeDiffBand{Min: 100, Max: 200, Expected: 150}, 143},
+		{"projected within the estimate", &pstypes.StakeDiffProjection{
+			IdxBlockInWindow: 100, WindowSize: 144, Min: 100, Max: 200, Expected: 150,
+			Projected: 170,
+		}, apitypes.StakeDiffBand{Min: 170, Max: 200, Expected: 170}, 43},
+		{"projected above the estimate", &pstypes.StakeDiffProjection{
+			IdxBlockInWindow: 143, WindowSize: 144, Min: 100, Max: 200, Expected: 150,
+			Projected: 250,
+		}, apitypes.StakeDiffBand{Min: 250, Max: 250, Expected: 250}, 0},
+	}
+
+	for _, test := range tests {
+		pred := stakeDiffPrediction(test.proj)
+		wantEstimate := apitypes.StakeDiffBand{Min: test.proj.Min,
+			Max: test.proj.Max, Expected: test.proj.Expected}
+		if pred.Estimate != wantEstimate {
+			t.Errorf("%s: expected estimate %+v, got %+v", test.name, wantEstimate,
+				pred.Estimate)
+		}
+		if pred.WithMempool != test.wantBand {
+			t.Errorf("%s: expected estimate with mempool %+v, got %+v", test.name,
+				test.wantBand, pred.WithMempool)
+		}
+		if pred.BlocksRemaining != test.remaining {
+			t.Errorf("%s: expected %d blocks remaining, got %d", test.name,
+				test.remaining, pred.BlocksRemaining)
+		}
+	}
+}
+
+func TestStakeDiffPredictionEndpoint(t *testing.T) {
+	proj := &pstypes.StakeDiffProjection{
+		Height: 1000, IdxBlockInWindow: 135, WindowSize: 144, Current: 120,
+		Min: 100, Max: 200, Expected: 150, MempoolTickets: 20, Projected: 160,
+	}
+
+	tests := []struct {
+		name       string
+		projector  StakeDiffProjector
+		wantStatus int
+	}{
+		{"projection", &stakeDiffProjStub{proj}, http.StatusOK},
+		{"no projection yet", &stakeDiffProjStub{}, http.StatusServiceUnavailable},
+		{"no projector", nil, http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		app := &appContext{DataSource: newDataSourceStub(), sdiffProj: test.projector}
+		router := chi.NewRouter()
+		router.Get("/stake/diff/prediction", app.getStakeDiffPrediction)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stake/diff/prediction", nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.StakeDiffPrediction
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(&got, stakeDiffPrediction(proj)) {
+			t.Errorf("%s: expected prediction %+v, got %+v", test.name,
+				stakeDiffPrediction(proj), got)
+		}
+	}
+}
+
+func TestChainInfo(t *testing.T) {
+	for _, params := range []*chaincfg.Params{chaincfg.MainNetParams(),
+		chaincfg.TestNet3Params(), chaincfg.SimNetParams()} {
+		info := newChainInfo(params)
+		if info.Network != params.Name || info.GenesisHash != params.GenesisHash.String() {
+			t.Errorf("%s: expected genesis block %s, got %s of %s", params.Name,
+				params.GenesisHash, info.GenesisHash, info.Network)
+		}
+		if info.MaxBlockSize != params.MaximumBlockSizes[0] {
+			t.Errorf("%s: expected max block size %d, got %d", params.Name,
+				params.MaximumBlockSizes[0], info.MaxBlockSize)
+		}
+		if info.Stake.TicketPoolSize != params.TicketPoolSize ||
+			info.Stake.StakeValidationHeight != params.StakeValidationHeight {
+			t.Errorf("%s: unexpected stake parameters %+v", params.Name, info.Stake)
+		}
+		if info.Subsidy.Base != params.BaseSubsidy ||
+			info.Subsidy.ReductionInterval != params.SubsidyReductionInterval {
+			t.Errorf("%s: unexpected subsidy parameters %+v", params.Name, info.Subsidy)
+		}
+
+		// Every deployment is listed in order of stake version.
+		var numDeployments int
+		for _, deployments := range params.Deployments {
+			numDeployments += len(deployments)
+		}
+		if len(info.Deployments) != numDeployments {
+			t.Errorf("%s: expected %d deployments, got %d", params.Name,
+				numDeployments, len(info.Deployments))
+		}
+		for i, d := range info.Deployments {
+			if i > 0 && d.Version < info.Deployments[i-1].Version {
+				t.Errorf("%s: deployment %s of version %d after version %d", params.Name,
+					d.ID, d.Version, info.Deployments[i-1].Version)
+			}
+			if len(d.Choices) == 0 {
+				t.Errorf("%s: deployment %s has no choices", params.Name, d.ID)
+			}
+		}
+
+		if len(info.Checkpoints) != len(params.Checkpoints) {
+			t.Errorf("%s: expected %d checkpoints, got %d", params.Name,
+				len(params.Checkpoints), len(info.Checkpoints))
+		}
+	}
+
+	// The endpoint serves the parameters of the network.
+	app := &appContext{DataSource: newDataSourceStub(),
+		chainInfo: newChainInfo(chaincfg.MainNetParams())}
+	router := chi.NewRouter()
+	router.Get("/chaininfo", app.getChainInfo)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/chaininfo", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var got apitypes.ChainInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", rr.Body.String(), err)
+	}
+	if !reflect.DeepEqual(&got, app.chainInfo) {
+		t.Errorf("expected chain info %+v, got %+v", app.chainInfo, got)
+	}
+}
+
+func TestUTXOSetStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantHeight int64
+	}{
+		{"best block", "/utxoset/stats", nil, http.StatusOK, -1},
+		{"height", "/utxoset/stats?height=1", nil, http.StatusOK, 1},
+		{"pruned height", "/utxoset/stats?height=0", nil, http.StatusNotFound, 0},
+		{"future height", "/utxoset/stats?height=2", nil, http.StatusBadRequest, 0},
+		{"negative height", "/utxoset/stats?height=-1", nil, http.StatusBadRequest, 0},
+		{"invalid height", "/utxoset/stats?height=x", nil, http.StatusBadRequest, 0},
+		{"timeout", "/utxoset/stats", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable, -1},
+		{"database error", "/utxoset/stats", errors.New("connection refused"),
+			http.StatusInternalServerError, -1},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Get("/utxoset/stats", app.getUTXOSetStats)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.utxoAt != test.wantHeight {
+			t.Errorf("%s: expected stats at height %d, got %d", test.name,
+				test.wantHeight, ds.utxoAt)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.UTXOSetStats
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if got.Height != 1 || got.Count != 3 || len(got.ScriptTypes) != 2 {
+			t.Errorf("%s: unexpected stats %+v", test.name, got)
+		}
+	}
+}
+
+func TestVoteVersionHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		err         error
+		wantStatus  int
+		wantVersion uint32
+	}{
+		{"latest version", "/stake/vote/versions/day", nil, http.StatusOK, 8},
+		{"version", "/stake/vote/versions/week?version=7", nil, http.StatusOK, 7},
+		{"future version", "/stake/vote/versions/day?version=9", nil, http.StatusOK, 8},
+		{"invalid version", "/stake/vote/versions/day?version=x", nil, 422, 0},
+		{"negative version", "/stake/vote/versions/day?version=-1", nil, 422, 0},
+		{"invalid grouping", "/stake/vote/versions/decade", nil, 422, 0},
+		{"timeout", "/stake/vote/versions/day", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable, 8},
+		{"database error", "/stake/vote/versions/day", errors.New("connection refused"),
+			http.StatusInternalServerError, 8},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Route("/stake/vote", func(r chi.Router) {
+			r.Use(app.StakeVersionLatestCtx)
+			r.With(m.ChartGroupingCtx).Get("/versions/{chartgrouping}", app.getVoteVersionHistory)
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.voteVer != test.wantVersion {
+			t.Errorf("%s: expected version %d, got %d", test.name, test.wantVersion, ds.voteVer)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.VoteVersionHistory
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if got.Version != test.wantVersion || len(got.Counts[7]) != 1 ||
+			!reflect.DeepEqual(got.OldPercent, []float64{60}) {
+			t.Errorf("%s: unexpected history %+v", test.name, got)
+		}
+	}
+}
+
+func TestVoteBitsStats(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	interval := int64(params.RuleChangeActivationInterval)
+
+	tests := []struct {
+		name             string
+		path             string
+		height           int64
+		wantStatus       int
+		wantVersion      uint32
+		wantFrom, wantTo int64
+	}{
+		{"defaults", "/stake/vote/bits", interval + 10, http.StatusOK, 8, interval, interval + 10},
+		{"interval start", "/stake/vote/bits", interval, http.StatusOK, 8, interval, interval},
+		{"to", "/stake/vote/bits?to=20", interval + 10, http.StatusOK, 8, 0, 20},
+		{"from and to", "/stake/vote/bits?version=7&from=5&to=20", interval + 10,
+			http.StatusOK, 7, 5, 20},
+		{"from after to", "/stake/vote/bits?from=21&to=20", interval + 10,
+			http.StatusBadRequest, 0, 0, 0},
+		{"negative to", "/stake/vote/bits?to=-1", interval + 10, http.StatusBadRequest, 0, 0, 0},
+		{"invalid from", "/stake/vote/bits?from=x", interval + 10, http.StatusBadRequest, 0, 0, 0},
+		{"invalid version", "/stake/vote/bits?version=x", interval + 10, 422, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		// The stub's best block height is the index of its last block.
+		ds.blocks = make([]*apitypes.BlockDataBasic, test.height+1)
+		app := &appContext{DataSource: ds, Params: params}
+		router := chi.NewRouter()
+		router.Route("/stake/vote", func(r chi.Router) {
+			r.Use(app.StakeVersionLatestCtx)
+			r.Get("/bits", app.getVoteBitsStats)
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.voteVer != test.wantVersion || ds.voteFrom != test.wantFrom ||
+			ds.voteTo != test.wantTo {
+			t.Errorf("%s: expected version %d from %d to %d, got version %d from %d to %d",
+				test.name, test.wantVersion, test.wantFrom, test.wantTo, ds.voteVer,
+				ds.voteFrom, ds.voteTo)
+		}
+	}
+}
+
+func TestAddressTagEndpoints(t *testing.T) {
+	ds := newDataSourceStub()
+	ds.tags = []*dbtypes.AddressTag{{
+		Address:  stubMainnetAddress,
+		Label:    "Exchange A",
+		Category: "exchange",
+	}, {
+		Address:  "Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk",
+		Label:    "VSP fees",
+		Category: "vsp",
+	}}
+	app := &appContext{
+		Params:     chaincfg.MainNetParams(),
+		DataSource: ds,
+	}
+	router := chi.NewRouter()
+	router.With(m.AddressPathCtxN(1)).Get("/address/{address}/tag", app.getAddressTag)
+	router.Get("/addresses/tags", app.getAddressTags)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantLabels []string
+	}{
+		{"tag", "/address/" + stubMainnetAddress + "/tag", http.StatusOK, []string{"Exchange A"}},
+		{"no tag", "/address/DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87/tag", http.StatusNotFound, nil},
+		{"invalid address", "/address/" + stubAddress + "/tag", http.StatusUnprocessableEntity, nil},
+		{"all tags", "/addresses/tags", http.StatusOK, []string{"Exchange A", "VSP fees"}},
+		{"category", "/addresses/tags?category=vsp", http.StatusOK, []string{"VSP fees"}},
+		{"unknown category", "/addresses/tags?category=treasury", http.StatusOK, []string{}},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		// A single label is served as an object, and a list as an array. Only
+		// the labels are decoded, since dbtypes.TimeDef is not unmarshalable.
+		type label struct {
+			Label string `json:"label"`
+		}
+		var tags []*label
+		body := rr.Body.Bytes()
+		if len(body) > 0 && body[0] == '{' {
+			tags = append(tags, new(label))
+			if err := json.Unmarshal(body, tags[0]); err != nil {
+				t.Errorf("%s: invalid JSON: %v", test.name, err)
+				continue
+			}
+		} else if err := json.Unmarshal(body, &tags); err != nil || tags == nil {
+			t.Errorf("%s: invalid JSON array %s: %v", test.name, body, err)
+			continue
+		}
+
+		labels := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			labels = append(labels, tag.Label)
+		}
+		if !reflect.DeepEqual(labels, test.wantLabels) {
+			t.Errorf("%s: expected labels %v, got %v", test.name, test.wantLabels, labels)
+		}
+	}
+}
+
+func TestSwapEndpoints(t *testing.T) {
+	// A redemption and a refund of contracts created by the same transaction.
+	contractTx, redeemTx, refundTx := chainhash.Hash{1}.String(),
+		chainhash.Hash{2}.String(), chainhash.Hash{3}.String()
+	swaps := []*apitypes.AtomicSwap{{
+		ContractTx: contractTx,
+		SpendTx:    redeemTx,
+		Secret:     "07",
+		Value:      5,
+	}, {
+		ContractTx:   contractTx,
+		ContractVout: 1,
+		SpendTx:      refundTx,
+		IsRefund:     true,
+		Value:        3,
+	}}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantSpends []string
+		wantN      int64
+		wantSkip   int64
+	}{{
+		name:       "recent",
+		path:       "/swaps/recent",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{redeemTx, refundTx},
+		wantN:      20,
+	}, {
+		name:       "recent page",
+		path:       "/swaps/recent?n=1&skip=1",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{refundTx},
+		wantN:      1,
+		wantSkip:   1,
+	}, {
+		name:       "recent above limit",
+		path:       "/swaps/recent?n=100000",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{redeemTx, refundTx},
+		wantN:      maxRecentSwaps,
+	}, {
+		name:       "recent invalid n",
+		path:       "/swaps/recent?n=-1",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "recent invalid skip",
+		path:       "/swaps/recent?skip=x",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "recent timeout",
+		path:       "/swaps/recent",
+		err:        errors.New(dbtypes.TimeoutPrefix),
+		wantStatus: http.StatusServiceUnavailable,
+		wantN:      20,
+	}, {
+		name:       "contract tx",
+		path:       "/tx/" + contractTx + "/swaps",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{redeemTx, refundTx},
+	}, {
+		name:       "spending tx",
+		path:       "/tx/" + refundTx + "/swaps",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{refundTx},
+	}, {
+		name:       "no swaps",
+		path:       "/tx/" + stubTxID + "/swaps",
+		wantStatus: http.StatusOK,
+		wantSpends: []string{},
+	}, {
+		name:       "invalid txid",
+		path:       "/tx/xyz/swaps",
+		wantStatus: http.StatusUnprocessableEntity,
+	}, {
+		name:       "tx database error",
+		path:       "/tx/" + contractTx + "/swaps",
+		err:        errors.New("connection refused"),
+		wantStatus: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.swaps = swaps
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Get("/swaps/recent", app.getRecentSwaps)
+		router.With(m.TransactionHashCtx).Get("/tx/{txid}/swaps", app.getTxSwaps)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.count != test.wantN || ds.skip != test.wantSkip {
+			t.Errorf("%s: expected n %d and skip %d, got %d and %d", test.name,
+				test.wantN, test.wantSkip, ds.count, ds.skip)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got []*apitypes.AtomicSwap
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil || got == nil {
+			t.Errorf("%s: invalid JSON array %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		spends := make([]string, 0, len(got))
+		for _, swap := range got {
+			spends = append(spends, swap.SpendTx)
+		}
+		if !reflect.DeepEqual(spends, test.wantSpends) {
+			t.Errorf("%s: expected spends %v, got %v", test.name, test.wantSpends, spends)
+		}
+	}
+}
+
+func TestTxGraph(t *testing.T) {
+	fundingTx, spendingTx := chainhash.Hash{1}.String(), chainhash.Hash{2}.String()
+	graph := &apitypes.TxGraph{
+		TxID:  stubTxID,
+		Depth: 1,
+		Nodes: []*apitypes.TxGraphNode{
+			{TxID: fundingTx, Depth: -1, Type: "Regular", Value: 10},
+			{TxID: stubTxID, Type: "Regular", Value: 9.9, Fees: 0.1},
+			{TxID: spendingTx, Depth: 1, Type: "Regular", Value: 4},
+		},
+		Edges: []*apitypes.TxGraphEdge{
+			{From: fundingTx, To: stubTxID, Value: 10, Depth: -1},
+			{From: stubTxID, Vout: 1, To: spendingTx, Value: 4, Depth: 1},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantDepth  int
+	}{
+		{"default depth", "/tx/" + stubTxID + "/graph", nil, http.StatusOK, 1},
+		{"max depth", "/tx/" + stubTxID + "/graph?depth=6", nil, http.StatusOK, maxTxGraphDepth},
+		{"zero depth", "/tx/" + stubTxID + "/graph?depth=0", nil, 422, 0},
+		{"deep", "/tx/" + stubTxID + "/graph?depth=7", nil, 422, 0},
+		{"invalid depth", "/tx/" + stubTxID + "/graph?depth=x", nil, 422, 0},
+		{"invalid txid", "/tx/xyz/graph", nil, 422, 0},
+		{"unknown tx", "/tx/" + fundingTx + "/graph", nil, http.StatusNotFound, 1},
+		{"pruned", "/tx/" + stubTxID + "/graph", dbtypes.ErrPruned, http.StatusNotFound, 1},
+		{"timeout", "/tx/" + stubTxID + "/graph", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable, 1},
+		{"database error", "/tx/" + stubTxID + "/graph", errors.New("connection refused"),
+			http.StatusInternalServerError, 1},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.txGraph = graph
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds}
+		router := chi.NewRouter()
+		router.Route("/tx/{txid}", func(r chi.Router) {
+			r.Use(m.TransactionHashCtx)
+			r.Get("/graph", app.getTxGraph)
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.depth != test.wantDepth {
+			t.Errorf("%s: expected depth %d, got %d", test.name, test.wantDepth, ds.depth)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.TxGraph
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if len(got.Nodes) != len(graph.Nodes) || !reflect.DeepEqual(got.Edges, graph.Edges) {
+			t.Errorf("%s: expected graph %+v, got %+v", test.name, graph, got)
+		}
+	}
+}
+
+func TestStakeROI(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	roi := &apitypes.StakeROI{
+		Amount:      10,
+		StartHeight: params.StakeEnabledHeight,
+		StartTime:   apitypes.NewTimeAPIFromUNIX(1455511962),
+		EndHeight:   params.StakeEnabledHeight + 8448,
+		EndTime:     apitypes.NewTimeAPIFromUNIX(1458052730),
+		FinalAmount: 10.1,
+		Reward:      0.1,
+		ROI:         1,
+		Cycles: []*apitypes.StakeROICycle{{
+			PurchaseHeight: params.StakeEnabledHeight,
+			TicketPrice:    2,
+			PoolSize:       40960,
+			Tickets:        5,
+			VoteHeight:     params.StakeEnabledHeight + 8448,
+			VoteReward:     0.02,
+			Balance:        10.1,
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantAmount float64
+		wantStart  int64
+	}{
+		{"default start", "/stake/roi?amount=10", nil, http.StatusOK, 10, params.StakeEnabledHeight},
+		{"start", "/stake/roi?amount=0.5&start=1", nil, http.StatusOK, 0.5, 1},
+		{"no amount", "/stake/roi", nil, 422, 0, 0},
+		{"zero amount", "/stake/roi?amount=0", nil, 422, 0, 0},
+		{"negative amount", "/stake/roi?amount=-1", nil, 422, 0, 0},
+		{"invalid amount", "/stake/roi?amount=x", nil, 422, 0, 0},
+		{"amount above supply", "/stake/roi?amount=3e7", nil, 422, 0, 0},
+		{"negative start", "/stake/roi?amount=10&start=-1", nil, 422, 0, 0},
+		{"invalid start", "/stake/roi?amount=10&start=x", nil, 422, 0, 0},
+		{"start above best block", "/stake/roi?amount=10&start=1000000", sql.ErrNoRows,
+			422, 10, 1000000},
+		{"timeout", "/stake/roi?amount=10", errors.New(dbtypes.TimeoutPrefix),
+			http.StatusServiceUnavailable, 10, params.StakeEnabledHeight},
+		{"database error", "/stake/roi?amount=10", errors.New("connection refused"),
+			http.StatusInternalServerError, 10, params.StakeEnabledHeight},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.roi = roi
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds, Params: params}
+		router := chi.NewRouter()
+		router.Get("/stake/roi", app.getStakeROI)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if ds.roiAmt.ToCoin() != test.wantAmount || ds.roiStart != test.wantStart {
+			t.Errorf("%s: expected amount %v from %d, got %v from %d", test.name,
+				test.wantAmount, test.wantStart, ds.roiAmt.ToCoin(), ds.roiStart)
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.StakeROI
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(&got, roi) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, roi, got)
+		}
+	}
+}
+
+func TestProposalVoteSnapshots(t *testing.T) {
+	const token = "27f87171d98b7923a1bd2bee6affed929fa2d2a6e178b5c80a9971a92a5c7f50"
+	snaps := &apitypes.ProposalVoteSnapshots{
+		Token:            token,
+		EligibleTickets:  40000,
+		QuorumPercentage: 20,
+		PassPercentage:   60,
+		Height:           []int64{418000, 418288},
+		Yes:              []int64{900, 4500},
+		No:               []int64{100, 1500},
+		Turnout:          []float64{2.5, 15},
+		QuorumProgress:   []float64{12.5, 75},
+		Approval:         []float64{90, 75},
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		err          error
+		isPiDisabled bool
+		wantStatus   int
+	}{
+		{"snapshots", "/proposal/" + token + "/snapshots", nil, false, http.StatusOK},
+		{"no snapshots", "/proposal/abcd/snapshots", nil, false, http.StatusNotFound},
+		{"piparser disabled", "/proposal/" + token + "/snapshots", nil, true,
+			http.StatusServiceUnavailable},
+		{"timeout", "/proposal/" + token + "/snapshots", errors.New(dbtypes.TimeoutPrefix),
+			false, http.StatusServiceUnavailable},
+		{"database error", "/proposal/" + token + "/snapshots", errors.New("connection refused"),
+			false, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.snaps = map[string]*apitypes.ProposalVoteSnapshots{token: snaps}
+		ds.addrErr = test.err
+		app := &appContext{DataSource: ds, isPiDisabled: test.isPiDisabled}
+		router := chi.NewRouter()
+		router.Route("/proposal", func(r chi.Router) {
+			r.With(m.ProposalTokenCtx).Get("/{token}/snapshots", app.getProposalVoteSnapshots)
+		})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+
+		var got apitypes.ProposalVoteSnapshots
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(&got, snaps) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, snaps, got)
+		}
+	}
+}
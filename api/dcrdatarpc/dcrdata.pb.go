@@ -0,0 +1,1573 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: dcrdata.proto
+
+package dcrdatarpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type BestBlockRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BestBlockRequest) Reset()         { *m = BestBlockRequest{} }
+func (m *BestBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*BestBlockRequest) ProtoMessage()    {}
+func (*BestBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{0}
+}
+func (m *BestBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BestBlockRequest.Unmarshal(m, b)
+}
+func (m *BestBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BestBlockRequest.Marshal(b, m, deterministic)
+}
+func (dst *BestBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BestBlockRequest.Merge(dst, src)
+}
+func (m *BestBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_BestBlockRequest.Size(m)
+}
+func (m *BestBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BestBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BestBlockRequest proto.InternalMessageInfo
+
+// BlockRequest identifies a block by hash, or by height if no hash is set.
+type BlockRequest struct {
+	Height               int64    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockRequest) Reset()         { *m = BlockRequest{} }
+func (m *BlockRequest) String() string { return proto.CompactTextString(m) }
+func (*BlockRequest) ProtoMessage()    {}
+func (*BlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{1}
+}
+func (m *BlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockRequest.Unmarshal(m, b)
+}
+func (m *BlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockRequest.Marshal(b, m, deterministic)
+}
+func (dst *BlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockRequest.Merge(dst, src)
+}
+func (m *BlockRequest) XXX_Size() int {
+	return xxx_messageInfo_BlockRequest.Size(m)
+}
+func (m *BlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockRequest proto.InternalMessageInfo
+
+func (m *BlockRequest) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockRequest) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+type TicketPool struct {
+	Size                 uint32   `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Value                float64  `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	ValueAvg             float64  `protobuf:"fixed64,3,opt,name=value_avg,json=valueAvg,proto3" json:"value_avg,omitempty"`
+	Winners              []string `protobuf:"bytes,4,rep,name=winners,proto3" json:"winners,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TicketPool) Reset()         { *m = TicketPool{} }
+func (m *TicketPool) String() string { return proto.CompactTextString(m) }
+func (*TicketPool) ProtoMessage()    {}
+func (*TicketPool) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{2}
+}
+func (m *TicketPool) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TicketPool.Unmarshal(m, b)
+}
+func (m *TicketPool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TicketPool.Marshal(b, m, deterministic)
+}
+func (dst *TicketPool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TicketPool.Merge(dst, src)
+}
+func (m *TicketPool) XXX_Size() int {
+	return xxx_messageInfo_TicketPool.Size(m)
+}
+func (m *TicketPool) XXX_DiscardUnknown() {
+	xxx_messageInfo_TicketPool.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TicketPool proto.InternalMessageInfo
+
+func (m *TicketPool) GetSize() uint32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *TicketPool) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *TicketPool) GetValueAvg() float64 {
+	if m != nil {
+		return m.ValueAvg
+	}
+	return 0
+}
+
+func (m *TicketPool) GetWinners() []string {
+	if m != nil {
+		return m.Winners
+	}
+	return nil
+}
+
+type Block struct {
+	Height          uint32  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Hash            string  `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Size            uint32  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Difficulty      float64 `protobuf:"fixed64,4,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	StakeDifficulty float64 `protobuf:"fixed64,5,opt,name=stake_difficulty,json=stakeDifficulty,proto3" json:"stake_difficulty,omitempty"`
+	Time            int64   `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	NumTx           uint32  `protobuf:"varint,7,opt,name=num_tx,json=numTx,proto3" json:"num_tx,omitempty"`
+	// Fees and total_sent are in atoms.
+	Fees                 int64       `protobuf:"varint,8,opt,name=fees,proto3" json:"fees,omitempty"`
+	TotalSent            int64       `protobuf:"varint,9,opt,name=total_sent,json=totalSent,proto3" json:"total_sent,omitempty"`
+	TicketPool           *TicketPool `protobuf:"bytes,10,opt,name=ticket_pool,json=ticketPool,proto3" json:"ticket_pool,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{3}
+}
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (dst *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(dst, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Block) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Block) GetSize() uint32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Block) GetDifficulty() float64 {
+	if m != nil {
+		return m.Difficulty
+	}
+	return 0
+}
+
+func (m *Block) GetStakeDifficulty() float64 {
+	if m != nil {
+		return m.StakeDifficulty
+	}
+	return 0
+}
+
+func (m *Block) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Block) GetNumTx() uint32 {
+	if m != nil {
+		return m.NumTx
+	}
+	return 0
+}
+
+func (m *Block) GetFees() int64 {
+	if m != nil {
+		return m.Fees
+	}
+	return 0
+}
+
+func (m *Block) GetTotalSent() int64 {
+	if m != nil {
+		return m.TotalSent
+	}
+	return 0
+}
+
+func (m *Block) GetTicketPool() *TicketPool {
+	if m != nil {
+		return m.TicketPool
+	}
+	return nil
+}
+
+type TransactionRequest struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionRequest) Reset()         { *m = TransactionRequest{} }
+func (m *TransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*TransactionRequest) ProtoMessage()    {}
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{4}
+}
+func (m *TransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionRequest.Unmarshal(m, b)
+}
+func (m *TransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionRequest.Marshal(b, m, deterministic)
+}
+func (dst *TransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionRequest.Merge(dst, src)
+}
+func (m *TransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_TransactionRequest.Size(m)
+}
+func (m *TransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionRequest proto.InternalMessageInfo
+
+func (m *TransactionRequest) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+type TxInput struct {
+	Coinbase             string   `protobuf:"bytes,1,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
+	Stakebase            string   `protobuf:"bytes,2,opt,name=stakebase,proto3" json:"stakebase,omitempty"`
+	Txid                 string   `protobuf:"bytes,3,opt,name=txid,proto3" json:"txid,omitempty"`
+	Vout                 uint32   `protobuf:"varint,4,opt,name=vout,proto3" json:"vout,omitempty"`
+	Tree                 int32    `protobuf:"varint,5,opt,name=tree,proto3" json:"tree,omitempty"`
+	Sequence             uint32   `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	AmountIn             float64  `protobuf:"fixed64,7,opt,name=amount_in,json=amountIn,proto3" json:"amount_in,omitempty"`
+	BlockHeight          uint32   `protobuf:"varint,8,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockIndex           uint32   `protobuf:"varint,9,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"`
+	ScriptSigHex         string   `protobuf:"bytes,10,opt,name=script_sig_hex,json=scriptSigHex,proto3" json:"script_sig_hex,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxInput) Reset()         { *m = TxInput{} }
+func (m *TxInput) String() string { return proto.CompactTextString(m) }
+func (*TxInput) ProtoMessage()    {}
+func (*TxInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{5}
+}
+func (m *TxInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxInput.Unmarshal(m, b)
+}
+func (m *TxInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxInput.Marshal(b, m, deterministic)
+}
+func (dst *TxInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxInput.Merge(dst, src)
+}
+func (m *TxInput) XXX_Size() int {
+	return xxx_messageInfo_TxInput.Size(m)
+}
+func (m *TxInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxInput proto.InternalMessageInfo
+
+func (m *TxInput) GetCoinbase() string {
+	if m != nil {
+		return m.Coinbase
+	}
+	return ""
+}
+
+func (m *TxInput) GetStakebase() string {
+	if m != nil {
+		return m.Stakebase
+	}
+	return ""
+}
+
+func (m *TxInput) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *TxInput) GetVout() uint32 {
+	if m != nil {
+		return m.Vout
+	}
+	return 0
+}
+
+func (m *TxInput) GetTree() int32 {
+	if m != nil {
+		return m.Tree
+	}
+	return 0
+}
+
+func (m *TxInput) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *TxInput) GetAmountIn() float64 {
+	if m != nil {
+		return m.AmountIn
+	}
+	return 0
+}
+
+func (m *TxInput) GetBlockHeight() uint32 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *TxInput) GetBlockIndex() uint32 {
+	if m != nil {
+		return m.BlockIndex
+	}
+	return 0
+}
+
+func (m *TxInput) GetScriptSigHex() string {
+	if m != nil {
+		return m.ScriptSigHex
+	}
+	return ""
+}
+
+type TxOutput struct {
+	Value                float64  `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	N                    uint32   `protobuf:"varint,2,opt,name=n,proto3" json:"n,omitempty"`
+	Version              uint32   `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	ScriptType           string   `protobuf:"bytes,4,opt,name=script_type,json=scriptType,proto3" json:"script_type,omitempty"`
+	ScriptHex            string   `protobuf:"bytes,5,opt,name=script_hex,json=scriptHex,proto3" json:"script_hex,omitempty"`
+	Addresses            []string `protobuf:"bytes,6,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxOutput) Reset()         { *m = TxOutput{} }
+func (m *TxOutput) String() string { return proto.CompactTextString(m) }
+func (*TxOutput) ProtoMessage()    {}
+func (*TxOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{6}
+}
+func (m *TxOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxOutput.Unmarshal(m, b)
+}
+func (m *TxOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxOutput.Marshal(b, m, deterministic)
+}
+func (dst *TxOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxOutput.Merge(dst, src)
+}
+func (m *TxOutput) XXX_Size() int {
+	return xxx_messageInfo_TxOutput.Size(m)
+}
+func (m *TxOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxOutput proto.InternalMessageInfo
+
+func (m *TxOutput) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *TxOutput) GetN() uint32 {
+	if m != nil {
+		return m.N
+	}
+	return 0
+}
+
+func (m *TxOutput) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *TxOutput) GetScriptType() string {
+	if m != nil {
+		return m.ScriptType
+	}
+	return ""
+}
+
+func (m *TxOutput) GetScriptHex() string {
+	if m != nil {
+		return m.ScriptHex
+	}
+	return ""
+}
+
+func (m *TxOutput) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+type Transaction struct {
+	Txid          string      `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Size          int32       `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Version       int32       `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Locktime      uint32      `protobuf:"varint,4,opt,name=locktime,proto3" json:"locktime,omitempty"`
+	Expiry        uint32      `protobuf:"varint,5,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	Inputs        []*TxInput  `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs       []*TxOutput `protobuf:"bytes,7,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Confirmations int64       `protobuf:"varint,8,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	// The block fields are empty for unconfirmed transactions.
+	BlockHash            string   `protobuf:"bytes,9,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockHeight          int64    `protobuf:"varint,10,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockIndex           uint32   `protobuf:"varint,11,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"`
+	BlockTime            int64    `protobuf:"varint,12,opt,name=block_time,json=blockTime,proto3" json:"block_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Transaction) Reset()         { *m = Transaction{} }
+func (m *Transaction) String() string { return proto.CompactTextString(m) }
+func (*Transaction) ProtoMessage()    {}
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{7}
+}
+func (m *Transaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transaction.Unmarshal(m, b)
+}
+func (m *Transaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transaction.Marshal(b, m, deterministic)
+}
+func (dst *Transaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transaction.Merge(dst, src)
+}
+func (m *Transaction) XXX_Size() int {
+	return xxx_messageInfo_Transaction.Size(m)
+}
+func (m *Transaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transaction proto.InternalMessageInfo
+
+func (m *Transaction) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *Transaction) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Transaction) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Transaction) GetLocktime() uint32 {
+	if m != nil {
+		return m.Locktime
+	}
+	return 0
+}
+
+func (m *Transaction) GetExpiry() uint32 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
+func (m *Transaction) GetInputs() []*TxInput {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *Transaction) GetOutputs() []*TxOutput {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *Transaction) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *Transaction) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *Transaction) GetBlockHeight() int64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *Transaction) GetBlockIndex() uint32 {
+	if m != nil {
+		return m.BlockIndex
+	}
+	return 0
+}
+
+func (m *Transaction) GetBlockTime() int64 {
+	if m != nil {
+		return m.BlockTime
+	}
+	return 0
+}
+
+type AddressTransactionsRequest struct {
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Count is the maximum number of transactions, with a default of 10.
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Skip                 int64    `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressTransactionsRequest) Reset()         { *m = AddressTransactionsRequest{} }
+func (m *AddressTransactionsRequest) String() string { return proto.CompactTextString(m) }
+func (*AddressTransactionsRequest) ProtoMessage()    {}
+func (*AddressTransactionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{8}
+}
+func (m *AddressTransactionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressTransactionsRequest.Unmarshal(m, b)
+}
+func (m *AddressTransactionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressTransactionsRequest.Marshal(b, m, deterministic)
+}
+func (dst *AddressTransactionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressTransactionsRequest.Merge(dst, src)
+}
+func (m *AddressTransactionsRequest) XXX_Size() int {
+	return xxx_messageInfo_AddressTransactionsRequest.Size(m)
+}
+func (m *AddressTransactionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressTransactionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressTransactionsRequest proto.InternalMessageInfo
+
+func (m *AddressTransactionsRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *AddressTransactionsRequest) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *AddressTransactionsRequest) GetSkip() int64 {
+	if m != nil {
+		return m.Skip
+	}
+	return 0
+}
+
+type AddressTransaction struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Size                 int32    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Time                 int64    `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	Value                float64  `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Confirmations        int64    `protobuf:"varint,5,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddressTransaction) Reset()         { *m = AddressTransaction{} }
+func (m *AddressTransaction) String() string { return proto.CompactTextString(m) }
+func (*AddressTransaction) ProtoMessage()    {}
+func (*AddressTransaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{9}
+}
+func (m *AddressTransaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressTransaction.Unmarshal(m, b)
+}
+func (m *AddressTransaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressTransaction.Marshal(b, m, deterministic)
+}
+func (dst *AddressTransaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressTransaction.Merge(dst, src)
+}
+func (m *AddressTransaction) XXX_Size() int {
+	return xxx_messageInfo_AddressTransaction.Size(m)
+}
+func (m *AddressTransaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressTransaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressTransaction proto.InternalMessageInfo
+
+func (m *AddressTransaction) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *AddressTransaction) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *AddressTransaction) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+type AddressTransactions struct {
+	Address              string                `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Transactions         []*AddressTransaction `protobuf:"bytes,2,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *AddressTransactions) Reset()         { *m = AddressTransactions{} }
+func (m *AddressTransactions) String() string { return proto.CompactTextString(m) }
+func (*AddressTransactions) ProtoMessage()    {}
+func (*AddressTransactions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{10}
+}
+func (m *AddressTransactions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressTransactions.Unmarshal(m, b)
+}
+func (m *AddressTransactions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressTransactions.Marshal(b, m, deterministic)
+}
+func (dst *AddressTransactions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressTransactions.Merge(dst, src)
+}
+func (m *AddressTransactions) XXX_Size() int {
+	return xxx_messageInfo_AddressTransactions.Size(m)
+}
+func (m *AddressTransactions) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressTransactions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddressTransactions proto.InternalMessageInfo
+
+func (m *AddressTransactions) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *AddressTransactions) GetTransactions() []*AddressTransaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+type TicketInfoRequest struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TicketInfoRequest) Reset()         { *m = TicketInfoRequest{} }
+func (m *TicketInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*TicketInfoRequest) ProtoMessage()    {}
+func (*TicketInfoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{11}
+}
+func (m *TicketInfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TicketInfoRequest.Unmarshal(m, b)
+}
+func (m *TicketInfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TicketInfoRequest.Marshal(b, m, deterministic)
+}
+func (dst *TicketInfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TicketInfoRequest.Merge(dst, src)
+}
+func (m *TicketInfoRequest) XXX_Size() int {
+	return xxx_messageInfo_TicketInfoRequest.Size(m)
+}
+func (m *TicketInfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TicketInfoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TicketInfoRequest proto.InternalMessageInfo
+
+func (m *TicketInfoRequest) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+type TicketInfo struct {
+	Status              string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	PurchaseBlockHash   string `protobuf:"bytes,2,opt,name=purchase_block_hash,json=purchaseBlockHash,proto3" json:"purchase_block_hash,omitempty"`
+	PurchaseBlockHeight uint32 `protobuf:"varint,3,opt,name=purchase_block_height,json=purchaseBlockHeight,proto3" json:"purchase_block_height,omitempty"`
+	MaturityHeight      uint32 `protobuf:"varint,4,opt,name=maturity_height,json=maturityHeight,proto3" json:"maturity_height,omitempty"`
+	ExpirationHeight    uint32 `protobuf:"varint,5,opt,name=expiration_height,json=expirationHeight,proto3" json:"expiration_height,omitempty"`
+	LotteryBlockHash    string `protobuf:"bytes,6,opt,name=lottery_block_hash,json=lotteryBlockHash,proto3" json:"lottery_block_hash,omitempty"`
+	LotteryBlockHeight  uint32 `protobuf:"varint,7,opt,name=lottery_block_height,json=lotteryBlockHeight,proto3" json:"lottery_block_height,omitempty"`
+	// Vote and revocation are the spending transaction IDs, if any.
+	Vote                 string   `protobuf:"bytes,8,opt,name=vote,proto3" json:"vote,omitempty"`
+	Revocation           string   `protobuf:"bytes,9,opt,name=revocation,proto3" json:"revocation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TicketInfo) Reset()         { *m = TicketInfo{} }
+func (m *TicketInfo) String() string { return proto.CompactTextString(m) }
+func (*TicketInfo) ProtoMessage()    {}
+func (*TicketInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{12}
+}
+func (m *TicketInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TicketInfo.Unmarshal(m, b)
+}
+func (m *TicketInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TicketInfo.Marshal(b, m, deterministic)
+}
+func (dst *TicketInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TicketInfo.Merge(dst, src)
+}
+func (m *TicketInfo) XXX_Size() int {
+	return xxx_messageInfo_TicketInfo.Size(m)
+}
+func (m *TicketInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_TicketInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TicketInfo proto.InternalMessageInfo
+
+func (m *TicketInfo) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *TicketInfo) GetPurchaseBlockHash() string {
+	if m != nil {
+		return m.PurchaseBlockHash
+	}
+	return ""
+}
+
+func (m *TicketInfo) GetPurchaseBlockHeight() uint32 {
+	if m != nil {
+		return m.PurchaseBlockHeight
+	}
+	return 0
+}
+
+func (m *TicketInfo) GetMaturityHeight() uint32 {
+	if m != nil {
+		return m.MaturityHeight
+	}
+	return 0
+}
+
+func (m *TicketInfo) GetExpirationHeight() uint32 {
+	if m != nil {
+		return m.ExpirationHeight
+	}
+	return 0
+}
+
+func (m *TicketInfo) GetLotteryBlockHash() string {
+	if m != nil {
+		return m.LotteryBlockHash
+	}
+	return ""
+}
+
+func (m *TicketInfo) GetLotteryBlockHeight() uint32 {
+	if m != nil {
+		return m.LotteryBlockHeight
+	}
+	return 0
+}
+
+func (m *TicketInfo) GetVote() string {
+	if m != nil {
+		return m.Vote
+	}
+	return ""
+}
+
+func (m *TicketInfo) GetRevocation() string {
+	if m != nil {
+		return m.Revocation
+	}
+	return ""
+}
+
+type AgendasRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AgendasRequest) Reset()         { *m = AgendasRequest{} }
+func (m *AgendasRequest) String() string { return proto.CompactTextString(m) }
+func (*AgendasRequest) ProtoMessage()    {}
+func (*AgendasRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{13}
+}
+func (m *AgendasRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AgendasRequest.Unmarshal(m, b)
+}
+func (m *AgendasRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AgendasRequest.Marshal(b, m, deterministic)
+}
+func (dst *AgendasRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AgendasRequest.Merge(dst, src)
+}
+func (m *AgendasRequest) XXX_Size() int {
+	return xxx_messageInfo_AgendasRequest.Size(m)
+}
+func (m *AgendasRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AgendasRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AgendasRequest proto.InternalMessageInfo
+
+type Agenda struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description          string   `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Status               string   `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	VoteVersion          uint32   `protobuf:"varint,4,opt,name=vote_version,json=voteVersion,proto3" json:"vote_version,omitempty"`
+	Mask                 uint32   `protobuf:"varint,5,opt,name=mask,proto3" json:"mask,omitempty"`
+	VotingStarted        int64    `protobuf:"varint,6,opt,name=voting_started,json=votingStarted,proto3" json:"voting_started,omitempty"`
+	VotingDone           int64    `protobuf:"varint,7,opt,name=voting_done,json=votingDone,proto3" json:"voting_done,omitempty"`
+	Activated            int64    `protobuf:"varint,8,opt,name=activated,proto3" json:"activated,omitempty"`
+	HardForked           int64    `protobuf:"varint,9,opt,name=hard_forked,json=hardForked,proto3" json:"hard_forked,omitempty"`
+	StartTime            int64    `protobuf:"varint,10,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	ExpireTime           int64    `protobuf:"varint,11,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Agenda) Reset()         { *m = Agenda{} }
+func (m *Agenda) String() string { return proto.CompactTextString(m) }
+func (*Agenda) ProtoMessage()    {}
+func (*Agenda) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{14}
+}
+func (m *Agenda) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Agenda.Unmarshal(m, b)
+}
+func (m *Agenda) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Agenda.Marshal(b, m, deterministic)
+}
+func (dst *Agenda) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Agenda.Merge(dst, src)
+}
+func (m *Agenda) XXX_Size() int {
+	return xxx_messageInfo_Agenda.Size(m)
+}
+func (m *Agenda) XXX_DiscardUnknown() {
+	xxx_messageInfo_Agenda.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Agenda proto.InternalMessageInfo
+
+func (m *Agenda) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Agenda) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Agenda) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Agenda) GetVoteVersion() uint32 {
+	if m != nil {
+		return m.VoteVersion
+	}
+	return 0
+}
+
+func (m *Agenda) GetMask() uint32 {
+	if m != nil {
+		return m.Mask
+	}
+	return 0
+}
+
+func (m *Agenda) GetVotingStarted() int64 {
+	if m != nil {
+		return m.VotingStarted
+	}
+	return 0
+}
+
+func (m *Agenda) GetVotingDone() int64 {
+	if m != nil {
+		return m.VotingDone
+	}
+	return 0
+}
+
+func (m *Agenda) GetActivated() int64 {
+	if m != nil {
+		return m.Activated
+	}
+	return 0
+}
+
+func (m *Agenda) GetHardForked() int64 {
+	if m != nil {
+		return m.HardForked
+	}
+	return 0
+}
+
+func (m *Agenda) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *Agenda) GetExpireTime() int64 {
+	if m != nil {
+		return m.ExpireTime
+	}
+	return 0
+}
+
+type Agendas struct {
+	Agendas              []*Agenda `protobuf:"bytes,1,rep,name=agendas,proto3" json:"agendas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Agendas) Reset()         { *m = Agendas{} }
+func (m *Agendas) String() string { return proto.CompactTextString(m) }
+func (*Agendas) ProtoMessage()    {}
+func (*Agendas) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{15}
+}
+func (m *Agendas) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Agendas.Unmarshal(m, b)
+}
+func (m *Agendas) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Agendas.Marshal(b, m, deterministic)
+}
+func (dst *Agendas) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Agendas.Merge(dst, src)
+}
+func (m *Agendas) XXX_Size() int {
+	return xxx_messageInfo_Agendas.Size(m)
+}
+func (m *Agendas) XXX_DiscardUnknown() {
+	xxx_messageInfo_Agendas.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Agendas proto.InternalMessageInfo
+
+func (m *Agendas) GetAgendas() []*Agenda {
+	if m != nil {
+		return m.Agendas
+	}
+	return nil
+}
+
+type BlockSubscription struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockSubscription) Reset()         { *m = BlockSubscription{} }
+func (m *BlockSubscription) String() string { return proto.CompactTextString(m) }
+func (*BlockSubscription) ProtoMessage()    {}
+func (*BlockSubscription) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dcrdata_512167c41b7acc98, []int{16}
+}
+func (m *BlockSubscription) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockSubscription.Unmarshal(m, b)
+}
+func (m *BlockSubscription) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockSubscription.Marshal(b, m, deterministic)
+}
+func (dst *BlockSubscription) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockSubscription.Merge(dst, src)
+}
+func (m *BlockSubscription) XXX_Size() int {
+	return xxx_messageInfo_BlockSubscription.Size(m)
+}
+func (m *BlockSubscription) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockSubscription.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockSubscription proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*BestBlockRequest)(nil), "dcrdatarpc.BestBlockRequest")
+	proto.RegisterType((*BlockRequest)(nil), "dcrdatarpc.BlockRequest")
+	proto.RegisterType((*TicketPool)(nil), "dcrdatarpc.TicketPool")
+	proto.RegisterType((*Block)(nil), "dcrdatarpc.Block")
+	proto.RegisterType((*TransactionRequest)(nil), "dcrdatarpc.TransactionRequest")
+	proto.RegisterType((*TxInput)(nil), "dcrdatarpc.TxInput")
+	proto.RegisterType((*TxOutput)(nil), "dcrdatarpc.TxOutput")
+	proto.RegisterType((*Transaction)(nil), "dcrdatarpc.Transaction")
+	proto.RegisterType((*AddressTransactionsRequest)(nil), "dcrdatarpc.AddressTransactionsRequest")
+	proto.RegisterType((*AddressTransaction)(nil), "dcrdatarpc.AddressTransaction")
+	proto.RegisterType((*AddressTransactions)(nil), "dcrdatarpc.AddressTransactions")
+	proto.RegisterType((*TicketInfoRequest)(nil), "dcrdatarpc.TicketInfoRequest")
+	proto.RegisterType((*TicketInfo)(nil), "dcrdatarpc.TicketInfo")
+	proto.RegisterType((*AgendasRequest)(nil), "dcrdatarpc.AgendasRequest")
+	proto.RegisterType((*Agenda)(nil), "dcrdatarpc.Agenda")
+	proto.RegisterType((*Agendas)(nil), "dcrdatarpc.Agendas")
+	proto.RegisterType((*BlockSubscription)(nil), "dcrdatarpc.BlockSubscription")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DCRDataClient is the client API for DCRData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DCRDataClient interface {
+	// GetBestBlock returns the best block in the database.
+	GetBestBlock(ctx context.Context, in *BestBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// GetBlock returns the mainchain block with the requested hash or height.
+	GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// GetTransaction returns the transaction with the requested ID.
+	GetTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
+	// GetAddressTransactions returns a page of an address's transactions, most
+	// recent first.
+	GetAddressTransactions(ctx context.Context, in *AddressTransactionsRequest, opts ...grpc.CallOption) (*AddressTransactions, error)
+	// GetTicketInfo returns the status and lifecycle of a ticket.
+	GetTicketInfo(ctx context.Context, in *TicketInfoRequest, opts ...grpc.CallOption) (*TicketInfo, error)
+	// GetAgendas returns the consensus agendas and their voting milestones.
+	GetAgendas(ctx context.Context, in *AgendasRequest, opts ...grpc.CallOption) (*Agendas, error)
+	// SubscribeBlocks streams each new mainchain block as it is connected.
+	SubscribeBlocks(ctx context.Context, in *BlockSubscription, opts ...grpc.CallOption) (DCRData_SubscribeBlocksClient, error)
+}
+
+type dCRDataClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDCRDataClient(cc *grpc.ClientConn) DCRDataClient {
+	return &dCRDataClient{cc}
+}
+
+func (c *dCRDataClient) GetBestBlock(ctx context.Context, in *BestBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetBestBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) GetTransaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) GetAddressTransactions(ctx context.Context, in *AddressTransactionsRequest, opts ...grpc.CallOption) (*AddressTransactions, error) {
+	out := new(AddressTransactions)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetAddressTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) GetTicketInfo(ctx context.Context, in *TicketInfoRequest, opts ...grpc.CallOption) (*TicketInfo, error) {
+	out := new(TicketInfo)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetTicketInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) GetAgendas(ctx context.Context, in *AgendasRequest, opts ...grpc.CallOption) (*Agendas, error) {
+	out := new(Agendas)
+	err := c.cc.Invoke(ctx, "/dcrdatarpc.DCRData/GetAgendas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dCRDataClient) SubscribeBlocks(ctx context.Context, in *BlockSubscription, opts ...grpc.CallOption) (DCRData_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DCRData_serviceDesc.Streams[0], "/dcrdatarpc.DCRData/SubscribeBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dCRDataSubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DCRData_SubscribeBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type dCRDataSubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *dCRDataSubscribeBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DCRDataServer is the server API for DCRData service.
+type DCRDataServer interface {
+	// GetBestBlock returns the best block in the database.
+	GetBestBlock(context.Context, *BestBlockRequest) (*Block, error)
+	// GetBlock returns the mainchain block with the requested hash or height.
+	GetBlock(context.Context, *BlockRequest) (*Block, error)
+	// GetTransaction returns the transaction with the requested ID.
+	GetTransaction(context.Context, *TransactionRequest) (*Transaction, error)
+	// GetAddressTransactions returns a page of an address's transactions, most
+	// recent first.
+	GetAddressTransactions(context.Context, *AddressTransactionsRequest) (*AddressTransactions, error)
+	// GetTicketInfo returns the status and lifecycle of a ticket.
+	GetTicketInfo(context.Context, *TicketInfoRequest) (*TicketInfo, error)
+	// GetAgendas returns the consensus agendas and their voting milestones.
+	GetAgendas(context.Context, *AgendasRequest) (*Agendas, error)
+	// SubscribeBlocks streams each new mainchain block as it is connected.
+	SubscribeBlocks(*BlockSubscription, DCRData_SubscribeBlocksServer) error
+}
+
+func RegisterDCRDataServer(s *grpc.Server, srv DCRDataServer) {
+	s.RegisterService(&_DCRData_serviceDesc, srv)
+}
+
+func _DCRData_GetBestBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BestBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetBestBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetBestBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetBestBlock(ctx, req.(*BestBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetBlock(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetTransaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_GetAddressTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetAddressTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetAddressTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetAddressTransactions(ctx, req.(*AddressTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_GetTicketInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TicketInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetTicketInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetTicketInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetTicketInfo(ctx, req.(*TicketInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_GetAgendas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgendasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DCRDataServer).GetAgendas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dcrdatarpc.DCRData/GetAgendas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DCRDataServer).GetAgendas(ctx, req.(*AgendasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DCRData_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlockSubscription)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DCRDataServer).SubscribeBlocks(m, &dCRDataSubscribeBlocksServer{stream})
+}
+
+type DCRData_SubscribeBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type dCRDataSubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *dCRDataSubscribeBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DCRData_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dcrdatarpc.DCRData",
+	HandlerType: (*DCRDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBestBlock",
+			Handler:    _DCRData_GetBestBlock_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _DCRData_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _DCRData_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetAddressTransactions",
+			Handler:    _DCRData_GetAddressTransactions_Handler,
+		},
+		{
+			MethodName: "GetTicketInfo",
+			Handler:    _DCRData_GetTicketInfo_Handler,
+		},
+		{
+			MethodName: "GetAgendas",
+			Handler:    _DCRData_GetAgendas_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _DCRData_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dcrdata.proto",
+}
+
+func init() { proto.RegisterFile("dcrdata.proto", fileDescriptor_dcrdata_512167c41b7acc98) }
+
+var fileDescriptor_dcrdata_512167c41b7acc98 = []byte{
+	// 1246 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x8d, 0x57, 0xdd, 0x6e, 0xeb, 0x44,
+	0x10, 0x56, 0x92, 0xe6, 0x6f, 0x92, 0xb4, 0xe9, 0xa6, 0xa7, 0x44, 0xe1, 0xfc, 0x14, 0x0b, 0x38,
+	0x07, 0x1d, 0x54, 0x1d, 0x95, 0x8b, 0x4a, 0x48, 0x08, 0xb5, 0x94, 0xc3, 0xe9, 0x15, 0xc8, 0x8d,
+	0xb8, 0x35, 0xae, 0xbd, 0x49, 0xad, 0x24, 0x76, 0xb0, 0xd7, 0x21, 0xe5, 0x09, 0xb8, 0xe0, 0x29,
+	0xb8, 0x40, 0xe2, 0x7d, 0x78, 0x12, 0x5e, 0x00, 0x76, 0x66, 0xd6, 0x8e, 0xf3, 0x43, 0xe0, 0x6e,
+	0xe6, 0xdb, 0xd9, 0xdd, 0xc9, 0x37, 0xdf, 0xcc, 0x3a, 0xd0, 0xf1, 0xbd, 0xd8, 0x77, 0x95, 0x7b,
+	0x3e, 0x8f, 0x23, 0x15, 0x09, 0x30, 0x6e, 0x3c, 0xf7, 0x2c, 0x01, 0xdd, 0x6b, 0x99, 0xa8, 0xeb,
+	0x69, 0xe4, 0x4d, 0x6c, 0xf9, 0x63, 0xaa, 0x6d, 0xeb, 0x73, 0x68, 0x17, 0x7d, 0x71, 0x0a, 0xb5,
+	0x07, 0x19, 0x8c, 0x1f, 0x54, 0xbf, 0x74, 0x56, 0x7a, 0x55, 0xb1, 0x8d, 0x27, 0x04, 0x1c, 0x3c,
+	0xb8, 0xc9, 0x43, 0xbf, 0xac, 0xd1, 0xa6, 0x4d, 0xb6, 0x35, 0x03, 0x18, 0x06, 0xde, 0x44, 0xaa,
+	0xef, 0xa2, 0x68, 0x8a, 0x11, 0x49, 0xf0, 0xb3, 0xa4, 0x7d, 0x1d, 0x9b, 0x6c, 0x71, 0x02, 0xd5,
+	0x85, 0x3b, 0x4d, 0x25, 0x6d, 0x2b, 0xd9, 0xec, 0x88, 0xf7, 0xa1, 0x49, 0x86, 0xe3, 0x2e, 0xc6,
+	0xfd, 0x0a, 0xad, 0x34, 0x08, 0xb8, 0x5a, 0x8c, 0x45, 0x1f, 0xea, 0x3f, 0x05, 0x61, 0x28, 0xe3,
+	0xa4, 0x7f, 0x70, 0x56, 0xd1, 0x77, 0x65, 0xae, 0xf5, 0x47, 0x19, 0xaa, 0x94, 0xeb, 0x46, 0x92,
+	0x9d, 0x7d, 0x49, 0xe6, 0x69, 0x55, 0x0a, 0x69, 0x3d, 0x07, 0xf0, 0x83, 0xd1, 0x28, 0xf0, 0xd2,
+	0xa9, 0x7a, 0xd4, 0xd7, 0x60, 0x06, 0x05, 0x44, 0x7c, 0x02, 0xdd, 0x44, 0xb9, 0x13, 0xe9, 0x14,
+	0xa2, 0xaa, 0x14, 0x75, 0x44, 0xf8, 0xcd, 0x2a, 0x54, 0x1f, 0xaf, 0x82, 0x99, 0xec, 0xd7, 0x88,
+	0x2d, 0xb2, 0xc5, 0x13, 0xa8, 0x85, 0xe9, 0xcc, 0x51, 0xcb, 0x7e, 0x9d, 0x2e, 0xad, 0x6a, 0x6f,
+	0xb8, 0xc4, 0xd0, 0x91, 0x94, 0x49, 0xbf, 0xc1, 0xa1, 0x68, 0x8b, 0x67, 0x00, 0x2a, 0x52, 0xee,
+	0xd4, 0x49, 0x64, 0xa8, 0xfa, 0x4d, 0x5a, 0x69, 0x12, 0x72, 0xa7, 0x01, 0x71, 0x09, 0x2d, 0x45,
+	0x0c, 0x3b, 0x73, 0x4d, 0x71, 0x1f, 0xf4, 0x7a, 0xeb, 0xe2, 0xf4, 0x7c, 0x55, 0xd3, 0xf3, 0x55,
+	0x01, 0x6c, 0x50, 0xb9, 0x6d, 0xbd, 0x02, 0x31, 0x8c, 0xdd, 0x30, 0x71, 0x3d, 0x15, 0x44, 0x61,
+	0x56, 0x5c, 0x4c, 0x76, 0x19, 0xf8, 0xc4, 0x9a, 0xe6, 0x07, 0x6d, 0xeb, 0xb7, 0x32, 0xd4, 0x87,
+	0xcb, 0xdb, 0x70, 0x9e, 0x2a, 0x31, 0x80, 0x86, 0x17, 0x05, 0xe1, 0xbd, 0x9b, 0x48, 0x13, 0x93,
+	0xfb, 0xe2, 0x29, 0x34, 0xe9, 0xb7, 0xd3, 0x22, 0x13, 0xbc, 0x02, 0xf2, 0x93, 0x2b, 0xab, 0x93,
+	0x11, 0x5b, 0x44, 0xa9, 0x22, 0x7e, 0x35, 0xf3, 0x68, 0x53, 0x5c, 0x2c, 0x25, 0xb1, 0x59, 0xb5,
+	0xc9, 0xc6, 0x5b, 0x13, 0x4c, 0x30, 0xf4, 0x98, 0xc6, 0x8e, 0x9d, 0xfb, 0x28, 0x15, 0x77, 0x16,
+	0xa5, 0xa1, 0x72, 0x82, 0x90, 0xd8, 0xd4, 0x52, 0x61, 0xe0, 0x36, 0x14, 0x1f, 0x40, 0xfb, 0x1e,
+	0xf5, 0xe0, 0x18, 0x31, 0x34, 0x68, 0x73, 0x8b, 0xb0, 0x77, 0xac, 0x88, 0x17, 0xc0, 0xae, 0xde,
+	0xee, 0xcb, 0x25, 0x11, 0xdc, 0xb1, 0x81, 0xa0, 0x5b, 0x44, 0xc4, 0x87, 0x70, 0x98, 0x78, 0x71,
+	0x30, 0x57, 0x4e, 0x12, 0x8c, 0xf5, 0x41, 0x4b, 0x22, 0xb9, 0x69, 0xb7, 0x19, 0xbd, 0x0b, 0xc6,
+	0xef, 0xe4, 0xd2, 0xfa, 0xbd, 0x04, 0x8d, 0xe1, 0xf2, 0xdb, 0x54, 0x21, 0x4b, 0xb9, 0xa8, 0x4b,
+	0x45, 0x51, 0xb7, 0xa1, 0x14, 0x12, 0x2f, 0x1d, 0xbb, 0x14, 0xa2, 0x8a, 0x17, 0x5a, 0xb3, 0x9a,
+	0x7b, 0x23, 0xbc, 0xcc, 0xc5, 0x8c, 0xcc, 0x85, 0xea, 0x71, 0x2e, 0x89, 0x9c, 0xa6, 0x0d, 0x0c,
+	0x0d, 0x35, 0x82, 0x92, 0x30, 0x01, 0x98, 0x4d, 0xd5, 0x30, 0x4d, 0x88, 0x4e, 0x05, 0xeb, 0xe0,
+	0xfa, 0x7e, 0x2c, 0x93, 0x44, 0x4b, 0xa9, 0x46, 0x1d, 0xb2, 0x02, 0xac, 0x5f, 0x2a, 0xd0, 0x2a,
+	0x14, 0x7e, 0x57, 0xc5, 0xf3, 0x8e, 0x28, 0x73, 0x0d, 0xa8, 0x23, 0x36, 0xf2, 0xad, 0xae, 0xf2,
+	0xd5, 0xd5, 0x41, 0xb6, 0x48, 0xe4, 0x5c, 0xc9, 0xdc, 0xc7, 0x3e, 0x94, 0xcb, 0x79, 0x10, 0x73,
+	0x77, 0xe8, 0x3e, 0x64, 0x4f, 0xbc, 0x86, 0x5a, 0x80, 0x82, 0xe2, 0x04, 0x5b, 0x17, 0xbd, 0x35,
+	0xc5, 0xb2, 0xd8, 0x6c, 0x13, 0x22, 0xce, 0xa1, 0x1e, 0x11, 0xb1, 0x89, 0x2e, 0x30, 0x46, 0x9f,
+	0xac, 0x47, 0x33, 0xeb, 0x76, 0x16, 0xa4, 0x2b, 0xd6, 0xf1, 0xa2, 0x70, 0x14, 0xc4, 0x33, 0x17,
+	0x7f, 0x62, 0xd6, 0x4f, 0xeb, 0x20, 0xb2, 0x68, 0xb4, 0x81, 0x03, 0xa1, 0xc9, 0x2c, 0xb2, 0x32,
+	0x70, 0x2a, 0x6c, 0x4a, 0x07, 0xe8, 0x8c, 0x7d, 0xd2, 0x69, 0x6d, 0x49, 0x27, 0xbf, 0x82, 0xb8,
+	0x69, 0x73, 0xef, 0x12, 0x32, 0xd4, 0x80, 0xf5, 0x03, 0x0c, 0xae, 0xb8, 0x2e, 0x85, 0x82, 0x24,
+	0x59, 0x2b, 0x6a, 0xc2, 0x4d, 0xd5, 0x4c, 0x6d, 0x32, 0x17, 0xe5, 0xe5, 0xa1, 0xc0, 0xa9, 0x3e,
+	0x15, 0x9b, 0x1d, 0x2a, 0xda, 0x24, 0x98, 0x53, 0x75, 0xf4, 0xf0, 0x40, 0xdb, 0xfa, 0xb5, 0x04,
+	0x62, 0xfb, 0x8a, 0xff, 0x5d, 0xf3, 0x6c, 0x74, 0x55, 0x0a, 0xa3, 0x2b, 0xd7, 0xf6, 0x41, 0x51,
+	0xdb, 0x5b, 0x94, 0x57, 0x77, 0x50, 0x6e, 0x25, 0xd0, 0xdb, 0xf1, 0x83, 0xf7, 0xfc, 0xd2, 0x6b,
+	0x68, 0xab, 0x42, 0xa4, 0x4e, 0x0e, 0xcb, 0xff, 0xbc, 0x58, 0xfe, 0xed, 0x03, 0xed, 0xb5, 0x3d,
+	0xd6, 0x4b, 0x38, 0xe6, 0x11, 0x78, 0x1b, 0x8e, 0xa2, 0x7d, 0x73, 0xee, 0xaf, 0x72, 0xf6, 0x5a,
+	0x61, 0x24, 0x4a, 0x57, 0x4f, 0x2f, 0x95, 0x66, 0x49, 0x19, 0x4f, 0xab, 0xb1, 0x37, 0x4f, 0x63,
+	0x4f, 0xab, 0x46, 0x3a, 0x05, 0x01, 0xf1, 0xc0, 0x3b, 0xce, 0x96, 0xae, 0x73, 0x21, 0x5d, 0xc0,
+	0x93, 0xcd, 0x78, 0x56, 0x14, 0xb7, 0x7d, 0x6f, 0x7d, 0x07, 0x2b, 0xeb, 0x25, 0x1c, 0x69, 0xce,
+	0xd2, 0x38, 0x50, 0x8f, 0x59, 0x34, 0x77, 0xd6, 0x61, 0x06, 0x9b, 0xc0, 0xd7, 0x70, 0x4c, 0x1d,
+	0x45, 0x04, 0x67, 0xa1, 0xdc, 0x6a, 0xdd, 0xd5, 0x82, 0x09, 0xfe, 0x14, 0xc4, 0x34, 0x52, 0x4a,
+	0xc6, 0x8f, 0xc5, 0xc4, 0x6b, 0x94, 0x78, 0xd7, 0xac, 0xac, 0xf2, 0x7e, 0x03, 0x27, 0x1b, 0xd1,
+	0x7c, 0x3a, 0xbf, 0x58, 0x62, 0x2d, 0x3e, 0x7f, 0x5c, 0x17, 0x91, 0x92, 0xd4, 0x6e, 0x4d, 0x9b,
+	0x6c, 0x7c, 0x48, 0x63, 0xb9, 0x88, 0x3c, 0xca, 0xc3, 0x74, 0x59, 0x01, 0xb1, 0xba, 0x70, 0x78,
+	0x35, 0x96, 0xa1, 0xef, 0x66, 0xba, 0xb7, 0xfe, 0x2c, 0x43, 0x8d, 0x21, 0x3c, 0x30, 0x74, 0x67,
+	0xd9, 0x4b, 0x43, 0xb6, 0x38, 0x83, 0x96, 0x2f, 0x79, 0xd8, 0xe1, 0x89, 0x4c, 0x7b, 0x11, 0x2a,
+	0x14, 0xae, 0xb2, 0x56, 0x38, 0xdd, 0xd1, 0x98, 0x92, 0x93, 0x8d, 0x31, 0x66, 0xb4, 0x85, 0xd8,
+	0xf7, 0x66, 0x94, 0xe9, 0x0b, 0x67, 0x6e, 0x32, 0x31, 0x0c, 0x92, 0x2d, 0x3e, 0x82, 0x43, 0x1d,
+	0x12, 0x84, 0x63, 0x47, 0x9f, 0x13, 0x2b, 0xe9, 0x9b, 0x97, 0xbc, 0xc3, 0xe8, 0x1d, 0x83, 0x38,
+	0x0c, 0x4c, 0x98, 0x1f, 0x85, 0x92, 0x58, 0xaa, 0xd8, 0xc0, 0xd0, 0x8d, 0x46, 0x68, 0x2c, 0x6b,
+	0x49, 0x2e, 0x5c, 0x3c, 0x82, 0x27, 0xd2, 0x0a, 0xc0, 0xed, 0x0f, 0x6e, 0xec, 0x3b, 0xa3, 0x28,
+	0x9e, 0xe8, 0x75, 0x7e, 0xe7, 0x01, 0xa1, 0xb7, 0x84, 0xd0, 0xd0, 0xc7, 0xab, 0x78, 0x96, 0xf0,
+	0x34, 0x6a, 0x12, 0x82, 0xb3, 0x04, 0xf7, 0x53, 0xbd, 0x25, 0xaf, 0xb7, 0x78, 0x3f, 0x43, 0x34,
+	0x6c, 0x2e, 0xa1, 0x6e, 0x88, 0xd6, 0x3a, 0xa8, 0xbb, 0x6c, 0x6a, 0x66, 0xb1, 0xa1, 0xc4, 0x5a,
+	0x43, 0xd1, 0x92, 0x9d, 0x85, 0x58, 0x3d, 0x38, 0xa6, 0x22, 0xdf, 0xa5, 0xf7, 0x39, 0xc7, 0x17,
+	0x7f, 0x57, 0xa0, 0x7e, 0xf3, 0x95, 0x7d, 0xa3, 0xf7, 0x88, 0x2f, 0xa1, 0xfd, 0x8d, 0x54, 0xf9,
+	0x77, 0xa3, 0x78, 0x5a, 0x3c, 0x6d, 0xf3, 0x73, 0x72, 0x70, 0xbc, 0xb6, 0x4a, 0x1b, 0x2e, 0xa1,
+	0x81, 0x07, 0x90, 0xdd, 0xdf, 0x5a, 0xde, 0xb3, 0xf1, 0x16, 0x0e, 0xf5, 0xc6, 0xe2, 0x64, 0x5b,
+	0x1b, 0x0d, 0xdb, 0xdf, 0x37, 0x83, 0xf7, 0xfe, 0x65, 0x5d, 0xb8, 0x70, 0xaa, 0x8f, 0xda, 0x35,
+	0x9d, 0x3e, 0xde, 0x3f, 0x6d, 0x32, 0xdd, 0x0e, 0x5e, 0xfc, 0x47, 0x9c, 0x78, 0x0b, 0x1d, 0xcc,
+	0x76, 0x35, 0x61, 0x9e, 0x6d, 0x7f, 0xa6, 0x15, 0x66, 0xd4, 0xe0, 0x74, 0xf7, 0xb2, 0xf8, 0x02,
+	0x00, 0x53, 0x35, 0xc5, 0x1c, 0x6c, 0xd7, 0x2e, 0x4f, 0xa9, 0xb7, 0x63, 0x4d, 0x7c, 0x0d, 0x47,
+	0xa6, 0x94, 0xf7, 0x3c, 0x73, 0x92, 0xf5, 0x44, 0xb6, 0x8a, 0xbd, 0x83, 0xf9, 0x37, 0xa5, 0xfb,
+	0x1a, 0xfd, 0x7b, 0xf8, 0xec, 0x1f, 0xe3, 0x30, 0x52, 0xd1, 0x4e, 0x0c, 0x00, 0x00,
+}
@@ -0,0 +1,4 @@
+
+# Requires grpc and protoc-gen-go
+# https://grpc.io/docs/quickstart/go.html#install-grpc
+protoc dcrdata.proto --go_out=plugins=grpc:.
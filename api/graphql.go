@@ -0,0 +1,490 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Limits on the shape of GraphQL queries. Since the schema's types link to
+// each other (e.g. block -> transactions -> block), the depth limit prevents
+// a single request from walking large parts of the chain.
+const (
+	graphQLMaxDepth       = 8
+	graphQLMaxParallelism = 10
+)
+
+// graphQLSchema is the schema for the /graphql endpoint. Amounts are in DCR,
+// and times are UNIX timestamps in seconds.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# The best block in the database.
+	bestBlock: Block
+	# A mainchain block by hash, or by height if no hash is given.
+	block(height: Int, hash: String): Block
+	transaction(txid: String!): Transaction
+	address(address: String!): Address
+}
+
+type Block {
+	height: Int!
+	hash: String!
+	size: Int!
+	time: Int!
+	difficulty: Float!
+	stakeDifficulty: Float!
+	numTx: Int!
+	# The first regular transaction.
+	coinbase: Transaction
+	transactions: [Transaction!]!
+	stakeTransactions: [Transaction!]!
+}
+
+type Transaction {
+	txid: String!
+	size: Int!
+	version: Int!
+	locktime: Int!
+	expiry: Int!
+	confirmations: Int!
+	# The containing block, or null for unconfirmed transactions.
+	block: Block
+	vin: [Vin!]!
+	vout: [Vout!]!
+}
+
+type Vin {
+	coinbase: String
+	stakebase: String
+	txid: String
+	vout: Int
+	tree: Int
+	sequence: Float!
+	amountIn: Float!
+}
+
+type Vout {
+	n: Int!
+	value: Float!
+	version: Int!
+	scriptType: String!
+	scriptHex: String!
+	addresses: [String!]!
+	# The spending transaction input, or null if the output is unspent.
+	spend: Spend
+}
+
+type Spend {
+	txid: String!
+	vin: Int!
+}
+
+type Address {
+	address: String!
+	numSpent: Float!
+	numUnspent: Float!
+	spent: Float!
+	unspent: Float!
+	transactions(count: Int = 10, skip: Int = 0): [AddressTransaction!]!
+}
+
+type AddressTransaction {
+	txid: String!
+	size: Int!
+	time: Int!
+	value: Float!
+	confirmations: Int!
+	transaction: Transaction
+}
+`
+
+// NewGraphQLHandler creates an http.Handler for GraphQL queries of the blocks,
+// transactions, and addresses in the DataSource.
+func NewGraphQLHandler(app *appContext) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(graphQLSchema, &graphQLResolver{app},
+		graphql.MaxDepth(graphQLMaxDepth), graphql.MaxParallelism(graphQLMaxParallelism))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL schema: %v", err)
+	}
+	return &relay.Handler{Schema: schema}, nil
+}
+
+// graphQLResolver resolves the root Query type.
+type graphQLResolver struct {
+	app *appContext
+}
+
+func (r *graphQLResolver) BestBlock() *gqlBlock {
+	summary := r.app.DataSource.GetBestBlockSummary()
+	if summary == nil {
+		return nil
+	}
+	return &gqlBlock{r.app, summary}
+}
+
+func (r *graphQLResolver) Block(args struct {
+	Height *int32
+	Hash   *string
+}) (*gqlBlock, error) {
+	var summary *apitypes.BlockDataBasic
+	switch {
+	case args.Hash != nil:
+		if _, err := chainhash.NewHashFromStr(*args.Hash); err != nil {
+			return nil, fmt.Errorf("invalid block hash: %v", err)
+		}
+		summary = r.app.DataSource.GetSummaryByHash(*args.Hash, true)
+	case args.Height != nil:
+		if *args.Height < 0 || int64(*args.Height) > r.app.DataSource.Height() {
+			return nil, nil
+		}
+		summary = r.app.DataSource.GetSummary(int(*args.Height))
+	default:
+		return nil, fmt.Errorf("either height or hash is required")
+	}
+	if summary == nil {
+		return nil, nil
+	}
+	return &gqlBlock{r.app, summary}, nil
+}
+
+func (r *graphQLResolver) Transaction(args struct{ Txid string }) (*gqlTransaction, error) {
+	txid, err := chainhash.NewHashFromStr(args.Txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction ID: %v", err)
+	}
+	tx := r.app.DataSource.GetRawAPITransaction(txid)
+	if tx == nil {
+		return nil, nil
+	}
+	return &gqlTransaction{app: r.app, txid: tx.TxID, tx: tx}, nil
+}
+
+func (r *graphQLResolver) Address(args struct{ Address string }) (*gqlAddress, error) {
+	totals, err := r.app.DataSource.AddressTotals(args.Address)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressTotals: %v", err)
+		return nil, fmt.Errorf("database timeout")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get totals for address %s", args.Address)
+	}
+	return &gqlAddress{r.app, totals}, nil
+}
+
+type gqlBlock struct {
+	app     *appContext
+	summary *apitypes.BlockDataBasic
+}
+
+func (b *gqlBlock) Height() int32            { return int32(b.summary.Height) }
+func (b *gqlBlock) Hash() string             { return b.summary.Hash }
+func (b *gqlBlock) Size() int32              { return int32(b.summary.Size) }
+func (b *gqlBlock) Time() int32              { return int32(b.summary.Time.UNIX()) }
+func (b *gqlBlock) Difficulty() float64      { return b.summary.Difficulty }
+func (b *gqlBlock) StakeDifficulty() float64 { return b.summary.StakeDiff }
+func (b *gqlBlock) NumTx() int32             { return int32(b.summary.NumTx) }
+
+func (b *gqlBlock) blockTransactions() (*apitypes.BlockTransactions, error) {
+	txs := b.app.DataSource.GetTransactionsForBlockByHash(b.summary.Hash)
+	if txs == nil {
+		return nil, fmt.Errorf("unable to get transactions for block %s", b.summary.Hash)
+	}
+	return txs, nil
+}
+
+func (b *gqlBlock) Coinbase() (*gqlTransaction, error) {
+	txs, err := b.blockTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if len(txs.Tx) == 0 {
+		return nil, nil
+	}
+	return &gqlTransaction{app: b.app, txid: txs.Tx[0]}, nil
+}
+
+func (b *gqlBlock) Transactions() ([]*gqlTransaction, error) {
+	txs, err := b.blockTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return b.lazyTransactions(txs.Tx), nil
+}
+
+func (b *gqlBlock) StakeTransactions() ([]*gqlTransaction, error) {
+	txs, err := b.blockTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return b.lazyTransactions(txs.STx), nil
+}
+
+func (b *gqlBlock) lazyTransactions(txids []string) []*gqlTransaction {
+	txs := make([]*gqlTransaction, 0, len(txids))
+	for _, txid := range txids {
+		txs = append(txs, &gqlTransaction{app: b.app, txid: txid})
+	}
+	return txs
+}
+
+// gqlTransaction resolves a Transaction. When only the txid is known, the
+// transaction is loaded on first use so that queries for just the txids of a
+// block's transactions do not retrieve every transaction.
+type gqlTransaction struct {
+	app  *appContext
+	txid string
+
+	once sync.Once
+	tx   *apitypes.Tx
+	err  error
+}
+
+func (t *gqlTransaction) load() (*apitypes.Tx, error) {
+	t.once.Do(func() {
+		if t.tx != nil {
+			return
+		}
+		txid, err := chainhash.NewHashFromStr(t.txid)
+		if err != nil {
+			t.err = fmt.Errorf("invalid transaction ID: %v", err)
+			return
+		}
+		if t.tx = t.app.DataSource.GetRawAPITransaction(txid); t.tx == nil {
+			t.err = fmt.Errorf("unable to get transaction %s", t.txid)
+		}
+	})
+	return t.tx, t.err
+}
+
+func (t *gqlTransaction) Txid() string { return t.txid }
+
+func (t *gqlTransaction) Size() (int32, error) {
+	tx, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	return tx.Size, nil
+}
+
+func (t *gqlTransaction) Version() (int32, error) {
+	tx, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	return tx.Version, nil
+}
+
+func (t *gqlTransaction) Locktime() (int32, error) {
+	tx, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	return int32(tx.Locktime), nil
+}
+
+func (t *gqlTransaction) Expiry() (int32, error) {
+	tx, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	return int32(tx.Expiry), nil
+}
+
+func (t *gqlTransaction) Confirmations() (int32, error) {
+	tx, err := t.load()
+	if err != nil {
+		return 0, err
+	}
+	return int32(tx.Confirmations), nil
+}
+
+func (t *gqlTransaction) Block() (*gqlBlock, error) {
+	tx, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	if tx.Block == nil || tx.Block.BlockHash == "" {
+		return nil, nil
+	}
+	summary := t.app.DataSource.GetSummaryByHash(tx.Block.BlockHash, true)
+	if summary == nil {
+		return nil, fmt.Errorf("unable to get block %s", tx.Block.BlockHash)
+	}
+	return &gqlBlock{t.app, summary}, nil
+}
+
+func (t *gqlTransaction) Vin() ([]*gqlVin, error) {
+	tx, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	vins := make([]*gqlVin, 0, len(tx.Vin))
+	for i := range tx.Vin {
+		vins = append(vins, &gqlVin{&tx.Vin[i]})
+	}
+	return vins, nil
+}
+
+func (t *gqlTransaction) Vout() ([]*gqlVout, error) {
+	tx, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	// Spending info is only looked up if a vout's spend field is requested.
+	spends := &gqlSpends{app: t.app, txid: tx.TxID, vouts: tx.Vout}
+	vouts := make([]*gqlVout, 0, len(tx.Vout))
+	for i := range tx.Vout {
+		vouts = append(vouts, &gqlVout{&tx.Vout[i], spends})
+	}
+	return vouts, nil
+}
+
+type gqlVin struct {
+	vin *chainjson.Vin
+}
+
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (v *gqlVin) Coinbase() *string  { return optString(v.vin.Coinbase) }
+func (v *gqlVin) Stakebase() *string { return optString(v.vin.Stakebase) }
+func (v *gqlVin) Txid() *string      { return optString(v.vin.Txid) }
+func (v *gqlVin) Sequence() float64  { return float64(v.vin.Sequence) }
+func (v *gqlVin) AmountIn() float64  { return v.vin.AmountIn }
+
+func (v *gqlVin) Vout() *int32 {
+	if v.vin.Txid == "" {
+		return nil
+	}
+	n := int32(v.vin.Vout)
+	return &n
+}
+
+func (v *gqlVin) Tree() *int32 {
+	if v.vin.Txid == "" {
+		return nil
+	}
+	tree := int32(v.vin.Tree)
+	return &tree
+}
+
+// gqlSpends looks up the spending transactions of all of a transaction's
+// outputs at most once.
+type gqlSpends struct {
+	app   *appContext
+	txid  string
+	vouts []apitypes.Vout
+
+	once sync.Once
+	err  error
+}
+
+func (s *gqlSpends) load() error {
+	s.once.Do(func() {
+		s.err = s.app.setOutputSpends(s.txid, s.vouts)
+	})
+	return s.err
+}
+
+type gqlVout struct {
+	vout   *apitypes.Vout
+	spends *gqlSpends
+}
+
+func (v *gqlVout) N() int32            { return int32(v.vout.N) }
+func (v *gqlVout) Value() float64      { return v.vout.Value }
+func (v *gqlVout) Version() int32      { return int32(v.vout.Version) }
+func (v *gqlVout) ScriptType() string  { return v.vout.ScriptPubKeyDecoded.Type }
+func (v *gqlVout) ScriptHex() string   { return v.vout.ScriptPubKeyDecoded.Hex }
+func (v *gqlVout) Addresses() []string { return v.vout.ScriptPubKeyDecoded.Addresses }
+
+func (v *gqlVout) Spend() (*gqlSpend, error) {
+	if err := v.spends.load(); err != nil {
+		return nil, err
+	}
+	if v.vout.Spend == nil {
+		return nil, nil
+	}
+	return &gqlSpend{v.vout.Spend}, nil
+}
+
+type gqlSpend struct {
+	spend *apitypes.TxInputID
+}
+
+func (s *gqlSpend) Txid() string { return s.spend.Hash }
+func (s *gqlSpend) Vin() int32   { return int32(s.spend.Index) }
+
+type gqlAddress struct {
+	app    *appContext
+	totals *apitypes.AddressTotals
+}
+
+func (a *gqlAddress) Address() string     { return a.totals.Address }
+func (a *gqlAddress) NumSpent() float64   { return float64(a.totals.NumSpent) }
+func (a *gqlAddress) NumUnspent() float64 { return float64(a.totals.NumUnspent) }
+func (a *gqlAddress) Spent() float64      { return a.totals.CoinsSpent }
+func (a *gqlAddress) Unspent() float64    { return a.totals.CoinsUnspent }
+
+func (a *gqlAddress) Transactions(args struct {
+	Count int32
+	Skip  int32
+}) ([]*gqlAddressTx, error) {
+	count, skip := int64(args.Count), int64(args.Skip)
+	if count <= 0 {
+		count = 10
+	} else if count > maxAddressTxCount {
+		count = maxAddressTxCount
+	}
+	if skip < 0 {
+		skip = 0
+	}
+
+	txs, err := a.app.DataSource.AddressTransactionDetails(a.totals.Address, count, skip, dbtypes.AddrTxnAll)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressTransactionDetails: %v", err)
+		return nil, fmt.Errorf("database timeout")
+	}
+	if err != nil || txs == nil {
+		return nil, fmt.Errorf("unable to get transactions for address %s", a.totals.Address)
+	}
+
+	addrTxs := make([]*gqlAddressTx, 0, len(txs.Transactions))
+	for _, tx := range txs.Transactions {
+		addrTxs = append(addrTxs, &gqlAddressTx{a.app, tx})
+	}
+	return addrTxs, nil
+}
+
+type gqlAddressTx struct {
+	app *appContext
+	tx  *apitypes.AddressTxShort
+}
+
+func (a *gqlAddressTx) Txid() string         { return a.tx.TxID }
+func (a *gqlAddressTx) Size() int32          { return a.tx.Size }
+func (a *gqlAddressTx) Time() int32          { return int32(a.tx.Time.UNIX()) }
+func (a *gqlAddressTx) Value() float64       { return a.tx.Value }
+func (a *gqlAddressTx) Confirmations() int32 { return int32(a.tx.Confirmations) }
+
+func (a *gqlAddressTx) Transaction() *gqlTransaction {
+	return &gqlTransaction{app: a.app, txid: a.tx.TxID}
+}
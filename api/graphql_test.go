@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+)
+
+// GetTransactionsForBlockByHash returns the IDs of the stub's transactions in
+// the block.
+func (ds *dataSourceStub) GetTransactionsForBlockByHash(hash string) *apitypes.BlockTransactions {
+	if ds.GetSummaryByHash(hash, false) == nil {
+		return nil
+	}
+	txs := &apitypes.BlockTransactions{Tx: []string{}, STx: []string{}}
+	for txid, tx := range ds.txs {
+		if tx.Block != nil && tx.Block.BlockHash == hash {
+			txs.Tx = append(txs.Tx, txid)
+		}
+	}
+	return txs
+}
+
+// AddressTotals returns the totals of the stub's address.
+func (ds *dataSourceStub) AddressTotals(address string) (*apitypes.AddressTotals, error) {
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	if ds.addrTxs == nil || ds.addrTxs.Address != address {
+		return nil, fmt.Errorf("no totals for %s", address)
+	}
+	return &apitypes.AddressTotals{
+		Address:      address,
+		NumUnspent:   int64(len(ds.addrTxs.Transactions)),
+		CoinsUnspent: 1.5,
+	}, nil
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantData  string // JSON
+		wantErr   bool
+		wantCount int64 // AddressTransactionDetails count, if called
+	}{{
+		name:     "best block",
+		query:    `{ bestBlock { height hash numTx } }`,
+		wantData: `{"bestBlock": {"height": 1, "hash": "` + stubBlockHash1 + `", "numTx": 2}}`,
+	}, {
+		name:     "block by height",
+		query:    `{ block(height: 0) { hash time } }`,
+		wantData: `{"block": {"hash": "` + stubBlockHash0 + `", "time": 1454954400}}`,
+	}, {
+		name:     "block by hash",
+		query:    `{ block(hash: "` + stubBlockHash1 + `") { height } }`,
+		wantData: `{"block": {"height": 1}}`,
+	}, {
+		name:     "block above best",
+		query:    `{ block(height: 5) { hash } }`,
+		wantData: `{"block": null}`,
+	}, {
+		name:    "invalid block hash",
+		query:   `{ block(hash: "xyz") { height } }`,
+		wantErr: true,
+	}, {
+		name:    "block without height or hash",
+		query:   `{ block { height } }`,
+		wantErr: true,
+	}, {
+		name:     "block transactions",
+		query:    `{ block(height: 1) { transactions { txid size } stakeTransactions { txid } } }`,
+		wantData: `{"block": {"transactions": [{"txid": "` + stubTxID + `", "size": 250}], "stakeTransactions": []}}`,
+	}, {
+		name: "transaction",
+		query: `{ transaction(txid: "` + stubTxID + `") {
+			confirmations block { height } vout { n value scriptType addresses } } }`,
+		wantData: `{"transaction": {"confirmations": 1, "block": {"height": 1},
+			"vout": [{"n": 0, "value": 1.5, "scriptType": "pubkeyhash", "addresses": ["` + stubAddress + `"]}]}}`,
+	}, {
+		name:     "unknown transaction",
+		query:    `{ transaction(txid: "` + stubBlockHash0 + `") { txid } }`,
+		wantData: `{"transaction": null}`,
+	}, {
+		name:      "address",
+		query:     `{ address(address: "` + stubAddress + `") { numUnspent unspent transactions(count: 5) { txid time } } }`,
+		wantData:  `{"address": {"numUnspent": 1, "unspent": 1.5, "transactions": [{"txid": "` + stubTxID + `", "time": 1454954535}]}}`,
+		wantCount: 5,
+	}, {
+		name:      "address default count",
+		query:     `{ address(address: "` + stubAddress + `") { transactions { txid } } }`,
+		wantData:  `{"address": {"transactions": [{"txid": "` + stubTxID + `"}]}}`,
+		wantCount: 10,
+	}, {
+		name:    "unknown address",
+		query:   `{ address(address: "Dsxyz") { address } }`,
+		wantErr: true,
+	}, {
+		name: "too deep",
+		query: `{ block(height: 1) { transactions { block { transactions { block {
+			transactions { block { transactions { block { hash } } } } } } } } } }`,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		handler, err := NewGraphQLHandler(&appContext{DataSource: ds})
+		if err != nil {
+			t.Fatalf("NewGraphQLHandler failed: %v", err)
+		}
+
+		body, _ := json.Marshal(map[string]string{"query": test.query})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var resp struct {
+			Data   json.RawMessage   `json:"data"`
+			Errors []json.RawMessage `json:"errors"`
+		}
+		if err = json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s: invalid response %q: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if test.wantErr {
+			if len(resp.Errors) == 0 {
+				t.Errorf("%s: expected an error, got data %s", test.name, resp.Data)
+			}
+			continue
+		}
+		if len(resp.Errors) > 0 {
+			t.Errorf("%s: unexpected errors %s", test.name, resp.Errors)
+			continue
+		}
+
+		var data, wantData interface{}
+		if err = json.Unmarshal(resp.Data, &data); err != nil {
+			t.Errorf("%s: invalid data %s: %v", test.name, resp.Data, err)
+			continue
+		}
+		if err = json.NewDecoder(strings.NewReader(test.wantData)).Decode(&wantData); err != nil {
+			t.Fatalf("%s: invalid expected data: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(data, wantData) {
+			t.Errorf("%s: expected data %s, got %s", test.name, test.wantData, resp.Data)
+		}
+		if ds.count != test.wantCount {
+			t.Errorf("%s: expected count %d, got %d", test.name, test.wantCount, ds.count)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"sync"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/gov/v3/agendas"
+	"github.com/decred/dcrdata/v5/api/dcrdatarpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blockSubscriberBuffer is the number of new blocks buffered for each
+// SubscribeBlocks stream. A subscriber that falls further behind misses
+// blocks rather than holding up the other block savers.
+const blockSubscriberBuffer = 16
+
+// maxAddressTxCount is the maximum number of transactions returned by
+// GetAddressTransactions, matching the REST API's limit.
+const maxAddressTxCount = 8000
+
+// GRPCServer implements dcrdatarpc.DCRDataServer using the same DataSource
+// as the REST API. It also satisfies blockdata.BlockDataSaver so that new
+// blocks can be streamed to SubscribeBlocks clients.
+type GRPCServer struct {
+	DataSource DataSource
+	AgendaDB   *agendas.AgendaDB
+
+	mtx         sync.Mutex
+	subscribers map[chan *dcrdatarpc.Block]struct{}
+	quit        chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewGRPCServer creates a new GRPCServer. Register it with a grpc.Server using
+// dcrdatarpc.RegisterDCRDataServer.
+func NewGRPCServer(dataSource DataSource, agendaDB *agendas.AgendaDB) *GRPCServer {
+	return &GRPCServer{
+		DataSource:  dataSource,
+		AgendaDB:    agendaDB,
+		subscribers: make(map[chan *dcrdatarpc.Block]struct{}),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Close ends all SubscribeBlocks streams. Since grpc.Server's GracefulStop
+// waits for streams to return, Close should be called first.
+func (s *GRPCServer) Close() {
+	s.closeOnce.Do(func() { close(s.quit) })
+}
+
+// GetBestBlock returns the best block in the database.
+func (s *GRPCServer) GetBestBlock(_ context.Context, _ *dcrdatarpc.BestBlockRequest) (*dcrdatarpc.Block, error) {
+	summary := s.DataSource.GetBestBlockSummary()
+	if summary == nil {
+		return nil, status.Error(codes.Unavailable, "best block not available")
+	}
+	return blockToRPC(summary), nil
+}
+
+// GetBlock returns the mainchain block with the requested hash, or height if
+// no hash is set.
+func (s *GRPCServer) GetBlock(_ context.Context, req *dcrdatarpc.BlockRequest) (*dcrdatarpc.Block, error) {
+	var summary *apitypes.BlockDataBasic
+	if req.Hash != "" {
+		if _, err := chainhash.NewHashFromStr(req.Hash); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block hash: %v", err)
+		}
+		summary = s.DataSource.GetSummaryByHash(req.Hash, true)
+	} else {
+		if req.Height < 0 || req.Height > s.DataSource.Height() {
+			return nil, status.Errorf(codes.NotFound, "no block at height %d", req.Height)
+		}
+		summary = s.DataSource.GetSummary(int(req.Height))
+	}
+	if summary == nil {
+		return nil, status.Error(codes.NotFound, "block not found")
+	}
+	return blockToRPC(summary), nil
+}
+
+// GetTransaction returns the transaction with the requested ID.
+func (s *GRPCServer) GetTransaction(_ context.Context, req *dcrdatarpc.TransactionRequest) (*dcrdatarpc.Transaction, error) {
+	txid, err := chainhash.NewHashFromStr(req.Txid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID: %v", err)
+	}
+	tx := s.DataSource.GetRawAPITransaction(txid)
+	if tx == nil {
+		return nil, status.Errorf(codes.NotFound, "transaction %s not found", txid)
+	}
+	return txToRPC(tx), nil
+}
+
+// GetAddressTransactions returns a page of the address's transactions, most
+// recent first.
+func (s *GRPCServer) GetAddressTransactions(_ context.Context, req *dcrdatarpc.AddressTransactionsRequest) (*dcrdatarpc.AddressTransactions, error) {
+	count, skip := req.Count, req.Skip
+	if count <= 0 {
+		count = 10
+	} else if count > maxAddressTxCount {
+		count = maxAddressTxCount
+	}
+	if skip < 0 {
+		skip = 0
+	}
+
+	txs, err := s.DataSource.AddressTransactionDetails(req.Address, count, skip, dbtypes.AddrTxnAll)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressTransactionDetails: %v", err)
+		return nil, status.Error(codes.Unavailable, "database timeout")
+	}
+	if err != nil || txs == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address %q", req.Address)
+	}
+
+	addrTxs := &dcrdatarpc.AddressTransactions{
+		Address:      txs.Address,
+		Transactions: make([]*dcrdatarpc.AddressTransaction, 0, len(txs.Transactions)),
+	}
+	for _, tx := range txs.Transactions {
+		addrTxs.Transactions = append(addrTxs.Transactions, &dcrdatarpc.AddressTransaction{
+			Txid:          tx.TxID,
+			Size:          tx.Size,
+			Time:          tx.Time.UNIX(),
+			Value:         tx.Value,
+			Confirmations: tx.Confirmations,
+		})
+	}
+	return addrTxs, nil
+}
+
+// GetTicketInfo returns the status and lifecycle of a ticket.
+func (s *GRPCServer) GetTicketInfo(_ context.Context, req *dcrdatarpc.TicketInfoRequest) (*dcrdatarpc.TicketInfo, error) {
+	txid, err := chainhash.NewHashFromStr(req.Txid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ticket hash: %v", err)
+	}
+	tinfo, err := s.DataSource.GetTicketInfo(txid.String())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "unable to get ticket info for tx %v: %v", txid, err)
+	}
+
+	ticket := &dcrdatarpc.TicketInfo{
+		Status:           tinfo.Status,
+		MaturityHeight:   tinfo.MaturityHeight,
+		ExpirationHeight: tinfo.ExpirationHeight,
+	}
+	if tinfo.PurchaseBlock != nil {
+		ticket.PurchaseBlockHash = tinfo.PurchaseBlock.Hash
+		ticket.PurchaseBlockHeight = tinfo.PurchaseBlock.Height
+	}
+	if tinfo.LotteryBlock != nil {
+		ticket.LotteryBlockHash = tinfo.LotteryBlock.Hash
+		ticket.LotteryBlockHeight = tinfo.LotteryBlock.Height
+	}
+	if tinfo.Vote != nil {
+		ticket.Vote = *tinfo.Vote
+	}
+	if tinfo.Revocation != nil {
+		ticket.Revocation = *tinfo.Revocation
+	}
+	return ticket, nil
+}
+
+// GetAgendas returns the consensus agendas and their voting milestones.
+func (s *GRPCServer) GetAgendas(_ context.Context, _ *dcrdatarpc.AgendasRequest) (*dcrdatarpc.Agendas, error) {
+	if s.AgendaDB == nil {
+		return nil, status.Error(codes.Unavailable, "agendas not available")
+	}
+	agendas, err := s.AgendaDB.AllAgendas()
+	if err != nil {
+		apiLog.Errorf("agendadb AllAgendas error: %v", err)
+		return nil, status.Error(codes.Unavailable, "agendadb.AllAgendas failed")
+	}
+
+	voteMilestones, err := s.DataSource.AllAgendas()
+	if err != nil {
+		apiLog.Errorf("AllAgendas timeout error: %v", err)
+		return nil, status.Error(codes.Unavailable, "database timeout")
+	}
+
+	resp := &dcrdatarpc.Agendas{
+		Agendas: make([]*dcrdatarpc.Agenda, 0, len(agendas)),
+	}
+	for _, agenda := range agendas {
+		milestone := voteMilestones[agenda.ID]
+		resp.Agendas = append(resp.Agendas, &dcrdatarpc.Agenda{
+			Name:          agenda.ID,
+			Description:   agenda.Description,
+			Status:        milestone.Status.String(),
+			VoteVersion:   agenda.VoteVersion,
+			Mask:          uint32(agenda.Mask),
+			VotingStarted: milestone.VotingStarted,
+			VotingDone:    milestone.VotingDone,
+			Activated:     milestone.Activated,
+			HardForked:    milestone.HardForked,
+			StartTime:     int64(agenda.StartTime),
+			ExpireTime:    int64(agenda.ExpireTime),
+		})
+	}
+	return resp, nil
+}
+
+// SubscribeBlocks streams each new mainchain block to the client until the
+// client disconnects or the server is closed.
+func (s *GRPCServer) SubscribeBlocks(_ *dcrdatarpc.BlockSubscription, stream dcrdatarpc.DCRData_SubscribeBlocksServer) error {
+	blocks := make(chan *dcrdatarpc.Block, blockSubscriberBuffer)
+	s.mtx.Lock()
+	s.subscribers[blocks] = struct{}{}
+	s.mtx.Unlock()
+
+	defer func() {
+		s.mtx.Lock()
+		delete(s.subscribers, blocks)
+		s.mtx.Unlock()
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.quit:
+			return nil
+		case block := <-blocks:
+			if err := stream.Send(block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Store sends the new block to the SubscribeBlocks streams. This method
+// satisfies blockdata.BlockDataSaver.
+func (s *GRPCServer) Store(blockData *blockdata.BlockData, _ *wire.MsgBlock) error {
+	summary := blockData.ToBlockSummary()
+	block := blockToRPC(&summary)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for blocks := range s.subscribers {
+		select {
+		case blocks <- block:
+		default:
+			apiLog.Warnf("gRPC block subscriber is not keeping up, "+
+				"dropping block %d.", block.Height)
+		}
+	}
+	return nil
+}
+
+func blockToRPC(summary *apitypes.BlockDataBasic) *dcrdatarpc.Block {
+	block := &dcrdatarpc.Block{
+		Height:          summary.Height,
+		Hash:            summary.Hash,
+		Size:            summary.Size,
+		Difficulty:      summary.Difficulty,
+		StakeDifficulty: summary.StakeDiff,
+		Time:            summary.Time.UNIX(),
+		NumTx:           summary.NumTx,
+	}
+	if summary.MiningFee != nil {
+		block.Fees = *summary.MiningFee
+	}
+	if summary.TotalSent != nil {
+		block.TotalSent = *summary.TotalSent
+	}
+	if pool := summary.PoolInfo; pool != nil {
+		block.TicketPool = &dcrdatarpc.TicketPool{
+			Size:     pool.Size,
+			Value:    pool.Value,
+			ValueAvg: pool.ValAvg,
+			Winners:  pool.Winners,
+		}
+	}
+	return block
+}
+
+func txToRPC(tx *apitypes.Tx) *dcrdatarpc.Transaction {
+	rpcTx := &dcrdatarpc.Transaction{
+		Txid:          tx.TxID,
+		Size:          tx.Size,
+		Version:       tx.Version,
+		Locktime:      tx.Locktime,
+		Expiry:        tx.Expiry,
+		Inputs:        make([]*dcrdatarpc.TxInput, 0, len(tx.Vin)),
+		Outputs:       make([]*dcrdatarpc.TxOutput, 0, len(tx.Vout)),
+		Confirmations: tx.Confirmations,
+	}
+	if tx.Block != nil {
+		rpcTx.BlockHash = tx.Block.BlockHash
+		rpcTx.BlockHeight = tx.Block.BlockHeight
+		rpcTx.BlockIndex = tx.Block.BlockIndex
+		rpcTx.BlockTime = tx.Block.BlockTime
+	}
+
+	for i := range tx.Vin {
+		vin := &tx.Vin[i]
+		in := &dcrdatarpc.TxInput{
+			Coinbase:    vin.Coinbase,
+			Stakebase:   vin.Stakebase,
+			Txid:        vin.Txid,
+			Vout:        vin.Vout,
+			Tree:        int32(vin.Tree),
+			Sequence:    vin.Sequence,
+			AmountIn:    vin.AmountIn,
+			BlockHeight: vin.BlockHeight,
+			BlockIndex:  vin.BlockIndex,
+		}
+		if vin.ScriptSig != nil {
+			in.ScriptSigHex = vin.ScriptSig.Hex
+		}
+		rpcTx.Inputs = append(rpcTx.Inputs, in)
+	}
+
+	for i := range tx.Vout {
+		vout := &tx.Vout[i]
+		rpcTx.Outputs = append(rpcTx.Outputs, &dcrdatarpc.TxOutput{
+			Value:      vout.Value,
+			N:          vout.N,
+			Version:    uint32(vout.Version),
+			ScriptType: vout.ScriptPubKeyDecoded.Type,
+			ScriptHex:  vout.ScriptPubKeyDecoded.Hex,
+			Addresses:  vout.ScriptPubKeyDecoded.Addresses,
+		})
+	}
+	return rpcTx
+}
+
+// Ensure GRPCServer satisfies the interfaces it is used as.
+var (
+	_ dcrdatarpc.DCRDataServer = (*GRPCServer)(nil)
+	_ blockdata.BlockDataSaver = (*GRPCServer)(nil)
+)
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/v5/api/dcrdatarpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	stubBlockHash0 = "298e5cc3d985bfe7f81dc135f360abe089edd4396b86d2de66b0cef42b21d980"
+	stubBlockHash1 = "000000000000437482b6d47f82f374cde539440ddb108b0a76886f0d87d126b9"
+	stubTxID       = "8f3a1b2c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+	stubAddress    = "DsUBCQWJsW8raht1i4gXTv7xPu3ySpUxxxx"
+)
+
+// dataSourceStub satisfies DataSource, but will panic with a nil pointer
+// dereference for the methods not explicitly defined here.
+type dataSourceStub struct {
+	// Embedding the DataSource interface promotes all of its methods, so that
+	// only the methods required by the tests are implemented.
+	DataSource
+
+	blocks   []*apitypes.BlockDataBasic // by height
+	txs      map[string]*apitypes.Tx
+	addrTxs  *apitypes.Address
+	addrRows []*dbtypes.AddressRowCompact
+	addrBal  int64 // atoms, at every height
+	addrErr  error
+	reorgs   []*dbtypes.Reorg
+	richList *apitypes.RichList
+	richN    int // N of the last RichList call
+	sideBlks map[string]*apitypes.SideChainBlock
+	agendaTS map[string]*apitypes.AgendaVoteTimeSeries
+	tickets  map[string]*apitypes.TicketLifecycle
+	utxoAt   int64  // height of the last UTXOSetStats call
+	voteVer  uint32 // version of the last VoteVersionHistory or VoteBitsStats call
+	voteFrom int64
+	voteTo   int64
+	tags     []*dbtypes.AddressTag
+	swaps    []*apitypes.AtomicSwap
+	txGraph  *apitypes.TxGraph
+	depth    int // depth of the last TxGraph call
+	roi      *apitypes.StakeROI
+	roiAmt   dcrutil.Amount // amount of the last StakeROI call
+	roiStart int64          // start height of the last StakeROI call
+	snaps    map[string]*apitypes.ProposalVoteSnapshots
+
+	// count and skip of the last AddressTransactionDetails or RecentSwaps call
+	count, skip int64
+}
+
+func newDataSourceStub() *dataSourceStub {
+	fees := int64(12000)
+	return &dataSourceStub{
+		blocks: []*apitypes.BlockDataBasic{{
+			Height: 0,
+			Hash:   stubBlockHash0,
+			Time:   apitypes.NewTimeAPIFromUNIX(1454954400),
+			NumTx:  1,
+		}, {
+			Height:    1,
+			Hash:      stubBlockHash1,
+			Size:      39663,
+			Time:      apitypes.NewTimeAPIFromUNIX(1454954535),
+			NumTx:     2,
+			MiningFee: &fees,
+			PoolInfo: &apitypes.TicketPoolInfo{
+				Height:  1,
+				Size:    5,
+				Value:   10,
+				ValAvg:  2,
+				Winners: []string{stubTxID},
+			},
+		}},
+		txs: map[string]*apitypes.Tx{
+			stubTxID: {
+				TxShort: apitypes.TxShort{
+					TxID: stubTxID,
+					Size: 250,
+					Vout: []apitypes.Vout{{
+						Value: 1.5,
+						N:     0,
+						ScriptPubKeyDecoded: apitypes.ScriptPubKey{
+							Type:      "pubkeyhash",
+							Addresses: []string{stubAddress},
+						},
+					}},
+				},
+				Confirmations: 1,
+				Block: &apitypes.BlockID{
+					BlockHash:   stubBlockHash1,
+					BlockHeight: 1,
+				},
+			},
+		},
+		addrTxs: &apitypes.Address{
+			Address: stubAddress,
+			Transactions: []*apitypes.AddressTxShort{{
+				TxID:          stubTxID,
+				Size:          250,
+				Time:          apitypes.NewTimeAPIFromUNIX(1454954535),
+				Value:         1.5,
+				Confirmations: 1,
+			}},
+		},
+	}
+}
+
+func (ds *dataSourceStub) Height() int64 {
+	return int64(len(ds.blocks) - 1)
+}
+
+func (ds *dataSourceStub) GetBestBlockSummary() *apitypes.BlockDataBasic {
+	if len(ds.blocks) == 0 {
+		return nil
+	}
+	return ds.blocks[len(ds.blocks)-1]
+}
+
+func (ds *dataSourceStub) GetSummary(idx int) *apitypes.BlockDataBasic {
+	if idx < 0 || idx >= len(ds.blocks) {
+		return nil
+	}
+	return ds.blocks[idx]
+}
+
+func (ds *dataSourceStub) GetSummaryByHash(hash string, _ bool) *apitypes.BlockDataBasic {
+	for _, block := range ds.blocks {
+		if block.Hash == hash {
+			return block
+		}
+	}
+	return nil
+}
+
+func (ds *dataSourceStub) GetRawAPITransaction(txid *chainhash.Hash) *apitypes.Tx {
+	return ds.txs[txid.String()]
+}
+
+func (ds *dataSourceStub) AddressTransactionDetails(addr string, count, skip int64,
+	_ dbtypes.AddrTxnViewType) (*apitypes.Address, error) {
+	ds.count, ds.skip = count, skip
+	if ds.addrErr != nil {
+		return nil, ds.addrErr
+	}
+	if ds.addrTxs == nil || ds.addrTxs.Address != addr {
+		return nil, nil
+	}
+	return ds.addrTxs, nil
+}
+
+func TestGRPCServerGetBestBlock(t *testing.T) {
+	s := NewGRPCServer(newDataSourceStub(), nil)
+	block, err := s.GetBestBlock(context.Background(), &dcrdatarpc.BestBlockRequest{})
+	if err != nil {
+		t.Fatalf("GetBestBlock failed: %v", err)
+	}
+	if block.Height != 1 || block.Hash != stubBlockHash1 {
+		t.Errorf("wrong best block %d (%s)", block.Height, block.Hash)
+	}
+
+	s = NewGRPCServer(&dataSourceStub{}, nil)
+	_, err = s.GetBestBlock(context.Background(), &dcrdatarpc.BestBlockRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected code %v without a best block, got %v", codes.Unavailable, err)
+	}
+}
+
+func TestGRPCServerGetBlock(t *testing.T) {
+	s := NewGRPCServer(newDataSourceStub(), nil)
+
+	tests := []struct {
+		name     string
+		req      *dcrdatarpc.BlockRequest
+		wantCode codes.Code
+		wantHash string
+	}{
+		{"by hash", &dcrdatarpc.BlockRequest{Hash: stubBlockHash1}, codes.OK, stubBlockHash1},
+		{"by height", &dcrdatarpc.BlockRequest{Height: 0}, codes.OK, stubBlockHash0},
+		{"invalid hash", &dcrdatarpc.BlockRequest{Hash: "xyz"}, codes.InvalidArgument, ""},
+		{"unknown hash", &dcrdatarpc.BlockRequest{Hash: stubTxID}, codes.NotFound, ""},
+		{"negative height", &dcrdatarpc.BlockRequest{Height: -1}, codes.NotFound, ""},
+		{"height above best", &dcrdatarpc.BlockRequest{Height: 2}, codes.NotFound, ""},
+	}
+
+	for _, test := range tests {
+		block, err := s.GetBlock(context.Background(), test.req)
+		if code := status.Code(err); code != test.wantCode {
+			t.Errorf("%s: expected code %v, got %v (%v)", test.name, test.wantCode, code, err)
+			continue
+		}
+		if err == nil && block.Hash != test.wantHash {
+			t.Errorf("%s: expected block %s, got %s", test.name, test.wantHash, block.Hash)
+		}
+	}
+}
+
+func TestGRPCServerGetTransaction(t *testing.T) {
+	s := NewGRPCServer(newDataSourceStub(), nil)
+
+	tests := []struct {
+		name     string
+		txid     string
+		wantCode codes.Code
+	}{
+		{"found", stubTxID, codes.OK},
+		{"invalid ID", "xyz", codes.InvalidArgument},
+		{"unknown ID", stubBlockHash1, codes.NotFound},
+	}
+
+	for _, test := range tests {
+		tx, err := s.GetTransaction(context.Background(),
+			&dcrdatarpc.TransactionRequest{Txid: test.txid})
+		if code := status.Code(err); code != test.wantCode {
+			t.Errorf("%s: expected code %v, got %v (%v)", test.name, test.wantCode, code, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if tx.Txid != test.txid || tx.BlockHash != stubBlockHash1 || tx.BlockHeight != 1 {
+			t.Errorf("%s: wrong transaction %s in block %d (%s)", test.name,
+				tx.Txid, tx.BlockHeight, tx.BlockHash)
+		}
+		if len(tx.Outputs) != 1 || tx.Outputs[0].ScriptType != "pubkeyhash" ||
+			len(tx.Outputs[0].Addresses) != 1 {
+			t.Errorf("%s: wrong outputs %v", test.name, tx.Outputs)
+		}
+	}
+}
+
+func TestGRPCServerGetAddressTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *dcrdatarpc.AddressTransactionsRequest
+		addrErr   error
+		wantCode  codes.Code
+		wantCount int64
+		wantSkip  int64
+	}{{
+		name:      "default count",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: stubAddress},
+		wantCode:  codes.OK,
+		wantCount: 10,
+	}, {
+		name:      "count and skip",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: stubAddress, Count: 20, Skip: 5},
+		wantCode:  codes.OK,
+		wantCount: 20,
+		wantSkip:  5,
+	}, {
+		name:      "count above limit",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: stubAddress, Count: 2 * maxAddressTxCount},
+		wantCode:  codes.OK,
+		wantCount: maxAddressTxCount,
+	}, {
+		name:      "negative skip",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: stubAddress, Skip: -5},
+		wantCode:  codes.OK,
+		wantCount: 10,
+	}, {
+		name:      "timeout",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: stubAddress},
+		addrErr:   errors.New(dbtypes.TimeoutPrefix),
+		wantCode:  codes.Unavailable,
+		wantCount: 10,
+	}, {
+		name:      "invalid address",
+		req:       &dcrdatarpc.AddressTransactionsRequest{Address: "Dsxyz"},
+		wantCode:  codes.InvalidArgument,
+		wantCount: 10,
+	}}
+
+	for _, test := range tests {
+		ds := newDataSourceStub()
+		ds.addrErr = test.addrErr
+		s := NewGRPCServer(ds, nil)
+
+		txs, err := s.GetAddressTransactions(context.Background(), test.req)
+		if code := status.Code(err); code != test.wantCode {
+			t.Errorf("%s: expected code %v, got %v (%v)", test.name, test.wantCode, code, err)
+			continue
+		}
+		if ds.count != test.wantCount || ds.skip != test.wantSkip {
+			t.Errorf("%s: expected count %d and skip %d, got %d and %d", test.name,
+				test.wantCount, test.wantSkip, ds.count, ds.skip)
+		}
+		if err != nil {
+			continue
+		}
+		if txs.Address != stubAddress || len(txs.Transactions) != 1 ||
+			txs.Transactions[0].Time != 1454954535 {
+			t.Errorf("%s: wrong address transactions %v", test.name, txs)
+		}
+	}
+}
+
+func TestGRPCServerGetAgendasUnavailable(t *testing.T) {
+	s := NewGRPCServer(newDataSourceStub(), nil)
+	_, err := s.GetAgendas(context.Background(), &dcrdatarpc.AgendasRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected code %v without an agendas DB, got %v", codes.Unavailable, err)
+	}
+}
+
+func TestBlockToRPC(t *testing.T) {
+	ds := newDataSourceStub()
+
+	// The genesis block stub has no fees or ticket pool.
+	block := blockToRPC(ds.blocks[0])
+	if block.Fees != 0 || block.TicketPool != nil {
+		t.Errorf("expected no fees or ticket pool, got %d and %v", block.Fees, block.TicketPool)
+	}
+
+	block = blockToRPC(ds.blocks[1])
+	if block.Fees != 12000 || block.Time != 1454954535 || block.NumTx != 2 {
+		t.Errorf("wrong block %v", block)
+	}
+	if block.TicketPool == nil || block.TicketPool.Size != 5 ||
+		len(block.TicketPool.Winners) != 1 {
+		t.Errorf("wrong ticket pool %v", block.TicketPool)
+	}
+}
@@ -220,10 +220,11 @@ func PostAddrsTxsCtx(next http.Handler) http.Handler {
 }
 
 // PostAddrsUtxoCtx middleware processes parameters given in the POST request
-// body for an addrs utxo endpoint.
+// body for an addrs utxo endpoint. The "addrs" field may be a comma-separated
+// string of addresses or an array of address strings. At most
+// maxInsightPostAddrsUTXO addresses are accepted.
 func PostAddrsUtxoCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		req := apitypes.InsightAddr{}
 		body, err := ioutil.ReadAll(r.Body)
 		r.Body.Close()
 		if err != nil {
@@ -231,20 +232,56 @@ func PostAddrsUtxoCtx(next http.Handler) http.Handler {
 			return
 		}
 
-		err = json.Unmarshal(body, &req)
+		addrs, err := parsePostAddrs(body, maxInsightPostAddrsUTXO)
 		if err != nil {
-			writeInsightError(w, fmt.Sprintf("Failed to parse request: %v", err))
+			writeInsightError(w, err.Error())
 			return
 		}
 
 		// Successful extraction of Body JSON
-		addrs := strings.Split(req.Addrs, ",")
 		ctx := context.WithValue(r.Context(), m.CtxAddress, addrs)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// parsePostAddrs extracts the addresses from the "addrs" field of a JSON
+// request body. The field may be a comma-separated string or an array of
+// strings. Whitespace around each address is removed, and empty entries are
+// skipped. An error is returned if there are no addresses or more than max.
+func parsePostAddrs(body []byte, max int) ([]string, error) {
+	var req struct {
+		Addrs json.RawMessage `json:"addrs"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("Failed to parse request: %v", err)
+	}
+
+	var list []string
+	var csv string
+	if err := json.Unmarshal(req.Addrs, &csv); err == nil {
+		list = strings.Split(csv, ",")
+	} else if err = json.Unmarshal(req.Addrs, &list); err != nil {
+		return nil, fmt.Errorf("Failed to parse request: addrs must be a " +
+			"comma-separated string or an array of strings")
+	}
+
+	addrs := make([]string, 0, len(list))
+	for _, addr := range list {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("No addresses specified")
+	}
+	if len(addrs) > max {
+		return nil, fmt.Errorf("Too many addresses (%d). At most %d addresses "+
+			"may be requested.", len(addrs), max)
+	}
+	return addrs, nil
+}
+
 // AddressCommandCtx returns a http.HandlerFunc that embeds the value at the url
 // part {command} into the request context.
 func AddressCommandCtx(next http.Handler) http.Handler {
@@ -0,0 +1,45 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package insight
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parsePostAddrs(t *testing.T) {
+	a := "Dsbb8DHHwWMkxSSgfAj9czC44VVKXZPWAmg"
+	b := "DsoHKy2eWYPSS1SmVQCoQCLWzSPNgnJNN5p"
+	tooMany := `{"addrs":"` + strings.Repeat(a+",", 3) + a + `"}`
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{"csv", fmt.Sprintf(`{"addrs":"%s,%s"}`, a, b), []string{a, b}, false},
+		{"csv spaces", fmt.Sprintf(`{"addrs":" %s , %s,"}`, a, b), []string{a, b}, false},
+		{"array", fmt.Sprintf(`{"addrs":["%s","%s"]}`, a, b), []string{a, b}, false},
+		{"max", `{"addrs":"` + strings.Repeat(a+",", 2) + a + `"}`, []string{a, a, a}, false},
+		{"too many", tooMany, nil, true},
+		{"empty", `{"addrs":""}`, nil, true},
+		{"missing", `{}`, nil, true},
+		{"number", `{"addrs":1}`, nil, true},
+		{"bad json", `{"addrs":`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePostAddrs([]byte(tt.body), 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePostAddrs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePostAddrs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -54,6 +54,10 @@ const (
 	// height 342553, and this is a ~75MB JSON payload.
 	maxInsightAddrsUTXOs = 500000
 
+	// maxInsightPostAddrsUTXO limits the number of addresses that may be given
+	// in the body of a POST request to the addrs/utxo endpoint.
+	maxInsightPostAddrsUTXO = 200
+
 	// maxInsightAddrsTxns limits the number of transactions that may be
 	// returned by the addrs[/{addresses}]/txs endpoints when the {addresses}
 	// list has more than one address. This limit is applied to the "to" and
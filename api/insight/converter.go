@@ -9,6 +9,7 @@ import (
 	"github.com/decred/dcrd/dcrutil/v2"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
 	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
 	"github.com/decred/dcrdata/txhelpers/v4"
 )
 
@@ -128,9 +129,10 @@ func (iapi *InsightApi) DcrToInsightTxns(txs []*chainjson.TxRawResult, noAsm, no
 		if !noSpent {
 			// Populate the spending status of all vouts. Note: this only
 			// gathers information from the database, which does not include
-			// mempool transactions.
+			// mempool transactions, nor the spends of the outputs of
+			// transactions in pruned blocks.
 			addrFull, err := iapi.BlockData.SpendDetailsForFundingTx(txNew.Txid)
-			if err != nil {
+			if err != nil && err != dbtypes.ErrPruned {
 				return nil, err
 			}
 			for _, dbAddr := range addrFull {
@@ -82,6 +82,29 @@ type BlockTransactions struct {
 // vout
 // vin
 
+// SideChainBlock is a block that is not on the main chain, such as a block
+// orphaned by a reorg, with its transactions.
+type SideChainBlock struct {
+	Hash         string        `json:"hash"`
+	Height       uint32        `json:"height"`
+	PreviousHash string        `json:"previous_hash"`
+	Time         TimeAPI       `json:"time"`
+	IsValid      bool          `json:"is_valid"`
+	Size         int           `json:"size"`
+	Tx           []SideChainTx `json:"tx"`
+	STx          []SideChainTx `json:"stx"`
+}
+
+// SideChainTx is a transaction in a side chain block.
+type SideChainTx struct {
+	TxID     string  `json:"txid"`
+	Type     string  `json:"type"`
+	Size     int     `json:"size"`
+	Fee      float64 `json:"fee"`
+	TotalOut float64 `json:"total_out"`
+	Hex      string  `json:"hex"`
+}
+
 // Tx models TxShort with the number of confirmations and block info Block
 type Tx struct {
 	TxShort
@@ -115,6 +138,36 @@ type AgendaAPIResponse struct {
 	ByTime   *dbtypes.AgendaVoteChoices `json:"by_time"`
 }
 
+// AgendaVoteCounts are the vote choice counts of an agenda.
+type AgendaVoteCounts struct {
+	Yes     uint64 `json:"yes"`
+	Abstain uint64 `json:"abstain"`
+	No      uint64 `json:"no"`
+	Total   uint64 `json:"total"`
+}
+
+// AgendaVoteInterval holds the agenda votes cast in an interval of the voting
+// period, and the cumulative votes cast in the voting period up to the end of
+// the interval. EndHeight is the last block counted, which precedes the end of
+// the interval while the interval is in progress.
+type AgendaVoteInterval struct {
+	StartHeight int64            `json:"start_height"`
+	EndHeight   int64            `json:"end_height"`
+	EndTime     TimeAPI          `json:"end_time"`
+	Votes       AgendaVoteCounts `json:"votes"`
+	Cumulative  AgendaVoteCounts `json:"cumulative"`
+}
+
+// AgendaVoteTimeSeries is the time-series of an agenda's votes over its rule
+// change interval, in intervals of IntervalSize blocks.
+type AgendaVoteTimeSeries struct {
+	AgendaID      string               `json:"agenda_id"`
+	VotingStarted int64                `json:"voting_started"`
+	VotingDone    int64                `json:"voting_done"`
+	IntervalSize  int64                `json:"interval_size"`
+	Intervals     []AgendaVoteInterval `json:"intervals"`
+}
+
 // TrimmedTx models data to resemble to result of the decoderawtransaction RPC.
 type TrimmedTx struct {
 	TxID     string          `json:"txid"`
@@ -125,6 +178,63 @@ type TrimmedTx struct {
 	Vout     []Vout          `json:"vout"`
 }
 
+// DecodedTxIn is an input of a transaction decoded from its serialized form.
+// AmountIn is the value of the previous output in DCR, which is nil if it is not
+// in the input's witness data and the previous output is not known. Generated
+// inputs, such as the input of a coinbase, do not spend a previous output.
+type DecodedTxIn struct {
+	PrevTxID  string   `json:"prev_txid,omitempty"`
+	PrevVout  uint32   `json:"prev_vout"`
+	PrevTree  int8     `json:"prev_tree"`
+	Generated bool     `json:"generated,omitempty"`
+	Sequence  uint32   `json:"sequence"`
+	AmountIn  *float64 `json:"amountin,omitempty"`
+	ScriptSig string   `json:"scriptSig"`
+}
+
+// DecodedTx is a transaction decoded from its serialized form. TotalIn, Fee and
+// FeeRate (DCR/kB) are only set if the amounts of all inputs are known.
+type DecodedTx struct {
+	TxID     string        `json:"txid"`
+	Type     string        `json:"type"`
+	Size     int           `json:"size"`
+	Version  uint16        `json:"version"`
+	Locktime uint32        `json:"locktime"`
+	Expiry   uint32        `json:"expiry"`
+	Vin      []DecodedTxIn `json:"vin"`
+	Vout     []Vout        `json:"vout"`
+	TotalIn  *float64      `json:"total_in,omitempty"`
+	TotalOut float64       `json:"total_out"`
+	Fee      *float64      `json:"fee,omitempty"`
+	FeeRate  *float64      `json:"fee_rate,omitempty"`
+}
+
+// DecodeTxResult is the result of decoding one of the transactions posted to
+// the bulk decode endpoint. Error is set instead of Tx if the transaction could
+// not be decoded.
+type DecodeTxResult struct {
+	Tx    *DecodedTx `json:"tx,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// AddressTagRequest is the PUT body of an address label set with the admin
+// API.
+type AddressTagRequest struct {
+	Label    string `json:"label"`
+	Category string `json:"category"`
+}
+
+// AddressWatchRequest is the POST body of an address watch registration.
+type AddressWatchRequest struct {
+	Address     string `json:"address"`
+	CallbackURL string `json:"callback_url"`
+}
+
+// FaucetRequest is the POST body of a request for coins from the faucet.
+type FaucetRequest struct {
+	Address string `json:"address"`
+}
+
 // Txns models the multi transaction post data structure
 type Txns struct {
 	Transactions []string `json:"transactions"`
@@ -314,8 +424,9 @@ type OutPoint struct {
 
 // Address models the address string with the transactions as AddressTxShort
 type Address struct {
-	Address      string            `json:"address"`
-	Transactions []*AddressTxShort `json:"address_transactions"`
+	Address      string              `json:"address"`
+	Tag          *dbtypes.AddressTag `json:"tag,omitempty"`
+	Transactions []*AddressTxShort   `json:"address_transactions"`
 }
 
 // AddressTxRaw is modeled from SearchRawTransactionsResult but with size in
@@ -343,16 +454,46 @@ type AddressTxShort struct {
 	Confirmations int64   `json:"confirmations"`
 }
 
+// AddressBalanceAtHeight is the balance of an address as of a past block.
+type AddressBalanceAtHeight struct {
+	Address string  `json:"address"`
+	Height  int64   `json:"height"`
+	Balance float64 `json:"balance"`
+	Atoms   int64   `json:"atoms"`
+}
+
+// AddressTxIO is a funding or spending entry in the history of an address,
+// with the address balance after the entry. Amount is negative for spending.
+type AddressTxIO struct {
+	Time           int64   `json:"time"`
+	TxHash         string  `json:"tx_hash"`
+	IOIndex        uint32  `json:"io_index"`
+	TxType         string  `json:"tx_type"`
+	Amount         float64 `json:"amount"`
+	Balance        float64 `json:"balance"`
+	MatchingTxHash string  `json:"matching_tx_hash,omitempty"`
+}
+
+// AddressTxIOPage is a page of the oldest-first funding and spending history of
+// an address. Total is the number of entries in the full history.
+type AddressTxIOPage struct {
+	Address string         `json:"address"`
+	Total   int            `json:"total"`
+	Skip    int            `json:"skip"`
+	Entries []*AddressTxIO `json:"entries"`
+}
+
 // AddressTotals represents the number and value of spent and unspent outputs
 // for an address.
 type AddressTotals struct {
-	Address      string  `json:"address"`
-	BlockHash    string  `json:"blockhash"`
-	BlockHeight  uint64  `json:"blockheight"`
-	NumSpent     int64   `json:"num_stxos"`
-	NumUnspent   int64   `json:"num_utxos"`
-	CoinsSpent   float64 `json:"dcr_spent"`
-	CoinsUnspent float64 `json:"dcr_unspent"`
+	Address      string              `json:"address"`
+	Tag          *dbtypes.AddressTag `json:"tag,omitempty"`
+	BlockHash    string              `json:"blockhash"`
+	BlockHeight  uint64              `json:"blockheight"`
+	NumSpent     int64               `json:"num_stxos"`
+	NumUnspent   int64               `json:"num_utxos"`
+	CoinsSpent   float64             `json:"dcr_spent"`
+	CoinsUnspent float64             `json:"dcr_unspent"`
 }
 
 // BlockDataWithTxType adds an array of TxRawWithTxType to
@@ -419,6 +560,25 @@ type Status struct {
 	api             APIStatus
 }
 
+// AdminJob is a maintenance task started from the admin endpoints. Progress
+// is the last progress message of a running task, or the result of a finished
+// task. Finished is zero while the task is running.
+type AdminJob struct {
+	ID       int64  `json:"id"`
+	Task     string `json:"task"`
+	Started  int64  `json:"started"`
+	Finished int64  `json:"finished,omitempty"`
+	Progress string `json:"progress"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AdminSyncStatus is the sync status of the node and database, and the recent
+// admin jobs, most recent first.
+type AdminSyncStatus struct {
+	Status APIStatus   `json:"status"`
+	Jobs   []*AdminJob `json:"jobs"`
+}
+
 // APIStatus is for the JSON-formatted response at /status.
 type APIStatus struct {
 	Ready           bool   `json:"ready"`
@@ -564,6 +724,122 @@ type CoinSupply struct {
 	Ultimate int64  `json:"supply_ultimate"`
 }
 
+// SupplySchedule is the block subsidy schedule over a range of heights and
+// the supply projected from the circulating supply at the best block. The
+// amounts are in atoms.
+type SupplySchedule struct {
+	Height      int64               `json:"block_height"`
+	Hash        string              `json:"block_hash"`
+	Circulating int64               `json:"supply_circulating"`
+	Ultimate    int64               `json:"supply_ultimate"`
+	Ranges      []SubsidyRangeSplit `json:"ranges"`
+}
+
+// SubsidyRangeSplit is the PoW, PoS, and treasury subsidy of each block in a
+// range of heights with constant subsidy, assuming that every block has the
+// maximum number of votes. MaxSupply is the coin supply once the block at
+// EndHeight is mined if every block had the maximum number of votes, while
+// ProjectedSupply is projected from the circulating supply, and is only set
+// for ranges ending after the best block.
+type SubsidyRangeSplit struct {
+	StartHeight     int64 `json:"start_height"`
+	EndHeight       int64 `json:"end_height"`
+	PoW             int64 `json:"pow"`
+	PoS             int64 `json:"pos"`
+	Treasury        int64 `json:"treasury"`
+	MaxSupply       int64 `json:"max_supply"`
+	ProjectedSupply int64 `json:"projected_supply,omitempty"`
+}
+
+// ChainInfo is the consensus parameters of the active network, so that clients
+// need not embed them. Durations are in seconds, and amounts in atoms.
+type ChainInfo struct {
+	Network            string             `json:"network"`
+	Net                uint32             `json:"net"`
+	GenesisHash        string             `json:"genesis_hash"`
+	DefaultPort        string             `json:"default_port"`
+	AddressPrefix      string             `json:"address_prefix"`
+	TargetBlockTime    int64              `json:"target_block_time"`
+	WorkDiffWindowSize int64              `json:"work_diff_window_size"`
+	WorkDiffWindows    int64              `json:"work_diff_windows"`
+	MaxBlockSize       int                `json:"max_block_size"`
+	MaxTxSize          int                `json:"max_tx_size"`
+	CoinbaseMaturity   uint16             `json:"coinbase_maturity"`
+	Stake              ChainStakeParams   `json:"stake"`
+	Subsidy            ChainSubsidyParams `json:"subsidy"`
+	RuleChange         ChainRuleChange    `json:"rule_change"`
+	Deployments        []ChainDeployment  `json:"deployments"`
+	Checkpoints        []ChainCheckpoint  `json:"checkpoints"`
+}
+
+// ChainStakeParams is the ticket and stake difficulty parameters of a network.
+type ChainStakeParams struct {
+	TicketPoolSize        uint16 `json:"ticket_pool_size"`
+	TicketsPerBlock       uint16 `json:"tickets_per_block"`
+	TicketMaturity        uint16 `json:"ticket_maturity"`
+	TicketExpiry          uint32 `json:"ticket_expiry"`
+	SStxChangeMaturity    uint16 `json:"sstx_change_maturity"`
+	MinimumStakeDiff      int64  `json:"min_stake_diff"`
+	StakeDiffWindowSize   int64  `json:"stake_diff_window_size"`
+	StakeDiffWindows      int64  `json:"stake_diff_windows"`
+	StakeVersionInterval  int64  `json:"stake_version_interval"`
+	MaxFreshStakePerBlock uint8  `json:"max_fresh_stake_per_block"`
+	StakeEnabledHeight    int64  `json:"stake_enabled_height"`
+	StakeValidationHeight int64  `json:"stake_validation_height"`
+}
+
+// ChainSubsidyParams is the block subsidy parameters of a network. The subsidy
+// is multiplied by Multiplier/Divisor every ReductionInterval blocks, and split
+// among PoW, PoS and the treasury by the proportions, which are out of their
+// sum.
+type ChainSubsidyParams struct {
+	BlockOne           int64  `json:"block_one"`
+	Base               int64  `json:"base"`
+	Multiplier         int64  `json:"multiplier"`
+	Divisor            int64  `json:"divisor"`
+	ReductionInterval  int64  `json:"reduction_interval"`
+	WorkProportion     uint16 `json:"work_proportion"`
+	StakeProportion    uint16 `json:"stake_proportion"`
+	TreasuryProportion uint16 `json:"treasury_proportion"`
+}
+
+// ChainRuleChange is the parameters of the voting on consensus rule changes. An
+// agenda vote passes when Multiplier/Divisor of the non-abstaining votes in an
+// Interval are for it, with at least Quorum non-abstaining votes.
+type ChainRuleChange struct {
+	Quorum     uint32 `json:"quorum"`
+	Multiplier uint32 `json:"multiplier"`
+	Divisor    uint32 `json:"divisor"`
+	Interval   uint32 `json:"interval"`
+}
+
+// ChainDeployment is an agenda deployment of a stake version. StartTime and
+// ExpireTime are UNIX times.
+type ChainDeployment struct {
+	Version     uint32                  `json:"version"`
+	ID          string                  `json:"id"`
+	Description string                  `json:"description"`
+	Mask        uint16                  `json:"mask"`
+	Choices     []ChainDeploymentChoice `json:"choices"`
+	StartTime   uint64                  `json:"start_time"`
+	ExpireTime  uint64                  `json:"expire_time"`
+}
+
+// ChainDeploymentChoice is a choice of an agenda, with its vote bits.
+type ChainDeploymentChoice struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Bits        uint16 `json:"bits"`
+	IsAbstain   bool   `json:"is_abstain"`
+	IsNo        bool   `json:"is_no"`
+}
+
+// ChainCheckpoint is a hard-coded checkpoint block.
+type ChainCheckpoint struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
 // TicketPoolInfo models data about ticket pool
 type TicketPoolInfo struct {
 	Height  uint32   `json:"height"`
@@ -654,6 +930,28 @@ type StakeDiff struct {
 	PriceWindowNum   int                               `json:"window_number"`
 }
 
+// StakeDiffBand is the range and expected value of a ticket price estimate.
+type StakeDiffBand struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Expected float64 `json:"expected"`
+}
+
+// StakeDiffPrediction is the predicted ticket price of the next stake
+// difficulty window as of the block at Height. Estimate is the node's
+// estimate from the mined tickets, and WithMempool includes the ticket
+// purchases in mempool as if they are mined in the current window.
+type StakeDiffPrediction struct {
+	Height           int64         `json:"height"`
+	IdxBlockInWindow int           `json:"window_block_index"`
+	WindowSize       int64         `json:"window_size"`
+	BlocksRemaining  int64         `json:"blocks_remaining"`
+	Current          float64       `json:"current"`
+	MempoolTickets   uint32        `json:"mempool_tickets"`
+	Estimate         StakeDiffBand `json:"estimate"`
+	WithMempool      StakeDiffBand `json:"with_mempool"`
+}
+
 // StakeInfoExtended models data about the fee, pool and stake difficulty
 type StakeInfoExtended struct {
 	Hash             string                 `json:"hash"`
@@ -701,6 +999,31 @@ type MempoolTicketFees struct {
 	FeeRates []float64 `json:"top_fees"`
 }
 
+// MempoolFeeRates models the fee rates in DCR/kB of the regular and ticket
+// purchase transactions in mempool at block height Height, with the fee rates
+// estimated to be required for confirmation within a number of blocks.
+type MempoolFeeRates struct {
+	Height      uint32              `json:"height"`
+	Time        int64               `json:"time"`
+	Count       int                 `json:"count"`
+	Size        int64               `json:"size"`
+	Percentiles []FeeRatePercentile `json:"percentiles"`
+	Estimates   []FeeRateEstimate   `json:"estimates"`
+}
+
+// FeeRatePercentile is a percentile of the mempool fee rates.
+type FeeRatePercentile struct {
+	Percentile int     `json:"percentile"`
+	FeeRate    float64 `json:"fee_rate"`
+}
+
+// FeeRateEstimate is the fee rate estimated to be required for confirmation
+// within Blocks blocks.
+type FeeRateEstimate struct {
+	Blocks  int     `json:"blocks"`
+	FeeRate float64 `json:"fee_rate"`
+}
+
 // TicketDetails models details about ticket Hash received at height Height
 type TicketDetails struct {
 	Hash    string  `json:"hash"`
@@ -741,6 +1064,34 @@ type TinyBlock struct {
 	Height uint32 `json:"height"`
 }
 
+// TicketLifecycle is the life of a ticket from its purchase to its vote or
+// revocation. The maturity block is the first block at which the ticket is
+// live. Reward is the stakebase of the ticket's vote, and is zero if the ticket
+// did not vote. DaysHeld is the time from the purchase to the spend, or to now
+// if the ticket is unspent.
+type TicketLifecycle struct {
+	TicketHash       string       `json:"ticket_hash"`
+	Status           string       `json:"status"`
+	PurchaseBlock    *TinyBlock   `json:"purchase_block"`
+	PurchaseTime     int64        `json:"purchase_time"`
+	Price            float64      `json:"price"`
+	Fee              float64      `json:"fee"`
+	MaturityHeight   uint32       `json:"maturity_height"`
+	LiveTime         int64        `json:"live_time,omitempty"`
+	ExpirationHeight uint32       `json:"expiration_height"`
+	Spend            *TicketSpend `json:"spend,omitempty"`
+	SpendType        string       `json:"spend_type"`
+	Reward           float64      `json:"reward"`
+	DaysHeld         float64      `json:"days_held"`
+}
+
+// TicketSpend is the vote or revocation spending a ticket.
+type TicketSpend struct {
+	TxID  string    `json:"txid"`
+	Block TinyBlock `json:"block"`
+	Time  int64     `json:"time"`
+}
+
 // TicketPoolChartsData is for data used to display ticket pool statistics at
 // /ticketpool.
 type TicketPoolChartsData struct {
@@ -771,3 +1122,440 @@ type PriceCountTime struct {
 	Count int             `json:"count"`
 	Time  dbtypes.TimeDef `json:"time"`
 }
+
+// TreasuryBalance is the treasury balance from the mainchain treasury
+// transactions, and the number of additions (TAdds and treasurybases) and
+// spends (TSpends).
+type TreasuryBalance struct {
+	Height    int64   `json:"height"`
+	Balance   float64 `json:"balance"`
+	NumAdds   int64   `json:"num_adds"`
+	NumSpends int64   `json:"num_spends"`
+}
+
+// TreasuryBalanceHistory is the amount added to and spent from the treasury in
+// each time period, and the balance at the end of the period. FiatBalance is
+// the balance valued in FiatCurrency with the DCR price at the end of the
+// period, and is null for the periods without a price. The fiat fields are
+// omitted if no historical prices have been imported.
+type TreasuryBalanceHistory struct {
+	Time         []dbtypes.TimeDef `json:"time"`
+	Added        []float64         `json:"added"`
+	Spent        []float64         `json:"spent"`
+	Balance      []float64         `json:"balance"`
+	FiatCurrency string            `json:"fiat_currency,omitempty"`
+	FiatBalance  []*float64        `json:"fiat_balance,omitempty"`
+}
+
+// CoinDaysDestroyed is the value spent, in DCR, and the coin days destroyed in
+// each time period. Spending an output destroys its value multiplied by the
+// number of days since it was created.
+type CoinDaysDestroyed struct {
+	Time              []dbtypes.TimeDef `json:"time"`
+	Spent             []float64         `json:"spent"`
+	CoinDaysDestroyed []float64         `json:"coin_days_destroyed"`
+}
+
+// CoinAgeBands is the distribution of the unspent value by the age of the
+// outputs at daily snapshots. Values[i][j] is the unspent value, in DCR, in
+// age band Bands[j] as of the block at Height[i].
+type CoinAgeBands struct {
+	Bands  []string          `json:"bands"`
+	Height []int64           `json:"height"`
+	Time   []dbtypes.TimeDef `json:"time"`
+	Values [][]float64       `json:"values"`
+}
+
+// AddressCluster is the cluster of addresses linked to an address by the
+// common-input-ownership heuristic, as of the block at Height. ClusterID is
+// zero if the address has not been linked to another address, in which case
+// the cluster is just the address. Balance is the unspent value of the
+// cluster's addresses, in DCR.
+type AddressCluster struct {
+	Address      string  `json:"address"`
+	ClusterID    int64   `json:"cluster_id"`
+	NumAddresses int64   `json:"num_addresses"`
+	Balance      float64 `json:"balance"`
+	Height       int64   `json:"height"`
+}
+
+// Fates of a transaction in the mempool history.
+const (
+	MempoolTxPending = "mempool"
+	MempoolTxMined   = "mined"
+	MempoolTxEvicted = "evicted"
+)
+
+// MempoolTxHistory is the mempool history of a transaction: when it was first
+// and last seen in mempool, its fee rate, and its fate. MinedHeight is set for
+// a mined transaction, with the seconds from first seen to the time of the
+// block in ConfirmSeconds. Fees are in DCR, and FeeRate is in DCR/kB.
+type MempoolTxHistory struct {
+	TxID           string  `json:"txid"`
+	Type           string  `json:"type"`
+	FirstSeen      int64   `json:"first_seen"`
+	LastSeen       int64   `json:"last_seen"`
+	Size           int32   `json:"size"`
+	Fees           float64 `json:"fees"`
+	FeeRate        float64 `json:"fee_rate"`
+	Fate           string  `json:"fate"`
+	MinedHeight    *int64  `json:"mined_height,omitempty"`
+	ConfirmSeconds *int64  `json:"confirm_seconds,omitempty"`
+}
+
+// MempoolCongestion is the mempool history aggregated by the period in which
+// the transactions were first seen: the number of transactions, their median
+// fee rate in DCR/kB, the number that were mined and the mean seconds to their
+// confirmation, and the number evicted from mempool without being mined.
+type MempoolCongestion struct {
+	Time               []dbtypes.TimeDef `json:"time"`
+	Count              []int64           `json:"count"`
+	MedianFeeRate      []float64         `json:"median_fee_rate"`
+	Mined              []int64           `json:"mined"`
+	MeanConfirmSeconds []float64         `json:"mean_confirm_seconds"`
+	Evicted            []int64           `json:"evicted"`
+}
+
+// BlockPropagation is the propagation latency of recent mainchain blocks,
+// oldest first. Receive is the seconds from the block's timestamp to the
+// receipt of dcrd's block connected notification, and Store is the seconds
+// from the receipt to the completion of StoreBlock. Both are null for blocks
+// stored without a notification, such as during the initial sync. Total is
+// the seconds from the block's timestamp to the completion of StoreBlock.
+type BlockPropagation struct {
+	Height  []int64           `json:"height"`
+	Time    []dbtypes.TimeDef `json:"time"`
+	Receive []*float64        `json:"receive"`
+	Store   []*float64        `json:"store"`
+	Total   []float64         `json:"total"`
+}
+
+// ProposalVoteSnapshots is the vote tally of a Politeia proposal through its
+// vote, oldest first. Turnout is the percentage of the eligible tickets that
+// voted, QuorumProgress is the votes cast as a percentage of the quorum, and
+// Approval is the percentage of the votes cast that were yes votes. The
+// eligible tickets and percentages required are those of the last snapshot.
+type ProposalVoteSnapshots struct {
+	Token            string            `json:"token"`
+	EligibleTickets  int64             `json:"eligible_tickets"`
+	QuorumPercentage uint32            `json:"quorum_percentage"`
+	PassPercentage   uint32            `json:"pass_percentage"`
+	Height           []int64           `json:"height"`
+	Time             []dbtypes.TimeDef `json:"time"`
+	Yes              []int64           `json:"yes"`
+	No               []int64           `json:"no"`
+	Turnout          []float64         `json:"turnout"`
+	QuorumProgress   []float64         `json:"quorum_progress"`
+	Approval         []float64         `json:"approval"`
+}
+
+// The types of search results.
+const (
+	SearchResultBlock    = "block"
+	SearchResultTx       = "tx"
+	SearchResultAddress  = "address"
+	SearchResultProposal = "proposal"
+)
+
+// SearchResult is a block, transaction, address or proposal matching a search
+// query. ID is the block hash, txid, address or proposal token. Rank is 1 for
+// an exact match, and lower for partial matches.
+type SearchResult struct {
+	Type   string  `json:"type"`
+	ID     string  `json:"id"`
+	Height *int64  `json:"height,omitempty"`
+	Title  string  `json:"title,omitempty"`
+	Rank   float64 `json:"rank"`
+}
+
+// SearchResults are the results of a search query in descending order of
+// rank.
+type SearchResults struct {
+	Query   string          `json:"query"`
+	Results []*SearchResult `json:"results"`
+}
+
+// TxInclusionProof is a merkle proof that a transaction is committed to by the
+// header of the mainchain block containing it. The proof verifies the
+// transaction hash at LeafIndex against Root with
+// standalone.VerifyInclusionProof. Root is the header's stake root rather than
+// its merkle root for stake tree transactions in blocks that predate the
+// combined transaction tree commitment (DCP0005).
+type TxInclusionProof struct {
+	TxID        string   `json:"txid"`
+	BlockHash   string   `json:"block_hash"`
+	BlockHeight int64    `json:"block_height"`
+	Root        string   `json:"root"`
+	Tree        int8     `json:"tree"`
+	LeafIndex   uint32   `json:"leaf_index"`
+	Proof       []string `json:"proof"`
+}
+
+// ChainReorgs is a page of the chain reorganizations recorded by dcrdata, most
+// recent first, and the total number recorded.
+type ChainReorgs struct {
+	Total  int64            `json:"total"`
+	Reorgs []*dbtypes.Reorg `json:"reorgs"`
+}
+
+// ScriptTypeCounts is the number of mainchain outputs of each script type, and
+// their value in DCR, in each time period. Counts[t][i] and Values[t][i] are
+// for the outputs of script type t in the period starting at Time[i].
+type ScriptTypeCounts struct {
+	Time   []dbtypes.TimeDef    `json:"time"`
+	Counts map[string][]int64   `json:"counts"`
+	Values map[string][]float64 `json:"values"`
+}
+
+// NonstandardOutputs is a page of the mainchain outputs with nonstandard
+// scripts, most recent first, and the total number of them.
+type NonstandardOutputs struct {
+	Total   int64                        `json:"total"`
+	Outputs []*dbtypes.NonstandardOutput `json:"outputs"`
+}
+
+// RichListAddress is an address of the rich list and its balance in DCR.
+type RichListAddress struct {
+	Rank    int     `json:"rank"`
+	Address string  `json:"address"`
+	Balance float64 `json:"balance"`
+}
+
+// BalanceRange is the number of addresses with a balance of at least
+// MinBalance DCR, but less than the MinBalance of the next range, and their
+// total balance.
+type BalanceRange struct {
+	MinBalance   float64 `json:"min_balance"`
+	NumAddresses int64   `json:"num_addresses"`
+	TotalBalance float64 `json:"total_balance"`
+}
+
+// RichList is the top addresses by balance and the distribution of address
+// balances, as of the block at Height. The rich list is materialized
+// periodically, at the time Updated.
+type RichList struct {
+	Height       int64             `json:"height"`
+	Updated      TimeAPI           `json:"updated"`
+	Addresses    []RichListAddress `json:"addresses"`
+	Distribution []BalanceRange    `json:"distribution"`
+}
+
+// UTXOScriptTypeStats is the number and total value, in DCR, of the unspent
+// outputs with a script type.
+type UTXOScriptTypeStats struct {
+	ScriptType string  `json:"script_type"`
+	Count      int64   `json:"count"`
+	Value      float64 `json:"value"`
+}
+
+// UTXOSetStats summarizes the UTXO set after the block at Height, in total
+// and for each script type, ordered by value.
+type UTXOSetStats struct {
+	Height      int64                 `json:"height"`
+	Count       int64                 `json:"count"`
+	TotalValue  float64               `json:"total_value"`
+	ScriptTypes []UTXOScriptTypeStats `json:"script_types"`
+}
+
+// VoteVersionHistory is the number of mainchain votes with each vote version in
+// each time period, and the percentage of the votes in each period with a vote
+// version older than Version.
+type VoteVersionHistory struct {
+	Version    uint32             `json:"version"`
+	Time       []dbtypes.TimeDef  `json:"time"`
+	Counts     map[uint32][]int64 `json:"counts"`
+	Total      []int64            `json:"total"`
+	OldPercent []float64          `json:"old_percent"`
+}
+
+// VoteBitsCount is the number of votes with the vote bits.
+type VoteBitsCount struct {
+	VoteBits uint16 `json:"vote_bits"`
+	Count    int64  `json:"count"`
+}
+
+// VoteChoiceCount is the number of votes for an agenda choice.
+type VoteChoiceCount struct {
+	Choice string `json:"choice"`
+	Count  int64  `json:"count"`
+}
+
+// VoteAgendaChoices is the number of votes for each choice of an agenda.
+type VoteAgendaChoices struct {
+	ID      string            `json:"id"`
+	Choices []VoteChoiceCount `json:"choices"`
+}
+
+// VoteBitsStats is the number of mainchain votes with vote version Version in
+// the blocks with heights in [StartHeight, EndHeight] by their vote bits, and
+// the resulting tally of the choices of the version's agendas. Votes with bits
+// that match none of an agenda's choices are not counted for the agenda.
+type VoteBitsStats struct {
+	Version     uint32              `json:"version"`
+	StartHeight int64               `json:"start_height"`
+	EndHeight   int64               `json:"end_height"`
+	Votes       int64               `json:"votes"`
+	VoteBits    []VoteBitsCount     `json:"vote_bits"`
+	Agendas     []VoteAgendaChoices `json:"agendas"`
+}
+
+// TSpendVoteTally is the number of stakeholder votes for and against a TSpend.
+type TSpendVoteTally struct {
+	Yes int64 `json:"yes"`
+	No  int64 `json:"no"`
+}
+
+// TSpendPayout is an output of a TSpend.
+type TSpendPayout struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+}
+
+// StakeROI is the result of staking Amount DCR from StartHeight with the
+// historical ticket prices, pool sizes, and vote rewards. The rewards of each
+// cycle's votes are restaked. EndHeight is the height of the last cycle's
+// votes, and Pending is the number of tickets purchased by the final cycle that
+// had not voted by the best block. ROI and AnnualizedROI are percentages.
+type StakeROI struct {
+	Amount        float64          `json:"amount"`
+	StartHeight   int64            `json:"start_height"`
+	StartTime     TimeAPI          `json:"start_time"`
+	EndHeight     int64            `json:"end_height"`
+	EndTime       TimeAPI          `json:"end_time"`
+	FinalAmount   float64          `json:"final_amount"`
+	Reward        float64          `json:"reward"`
+	ROI           float64          `json:"roi"`
+	AnnualizedROI float64          `json:"annualized_roi"`
+	Pending       int64            `json:"pending_tickets"`
+	Cycles        []*StakeROICycle `json:"cycles"`
+}
+
+// StakeROICycle is the purchase of tickets at a height and their votes.
+// VoteReward is the reward of each vote, and Balance is the staked amount
+// after the votes, both in DCR.
+type StakeROICycle struct {
+	PurchaseHeight int64   `json:"purchase_height"`
+	TicketPrice    float64 `json:"ticket_price"`
+	PoolSize       int64   `json:"pool_size"`
+	Tickets        int64   `json:"tickets"`
+	VoteHeight     int64   `json:"vote_height"`
+	VoteReward     float64 `json:"vote_reward"`
+	Balance        float64 `json:"balance"`
+}
+
+// TxGraph is the graph of the ancestors and descendants of a transaction up
+// to Depth hops. An ancestor's depth is negative, and a descendant's depth is
+// positive. Truncated is set if the graph exceeded the maximum number of edges.
+type TxGraph struct {
+	TxID      string         `json:"txid"`
+	Depth     int            `json:"depth"`
+	Nodes     []*TxGraphNode `json:"nodes"`
+	Edges     []*TxGraphEdge `json:"edges"`
+	Truncated bool           `json:"truncated"`
+}
+
+// TxGraphNode is a transaction in a TxGraph. Value is the total output value,
+// in DCR.
+type TxGraphNode struct {
+	TxID        string  `json:"txid"`
+	Depth       int     `json:"depth"`
+	Type        string  `json:"type"`
+	BlockHeight int64   `json:"block_height"`
+	Time        TimeAPI `json:"time"`
+	Value       float64 `json:"value"`
+	Fees        float64 `json:"fees"`
+}
+
+// TxGraphEdge is the spending of output Vout of transaction From by input Vin
+// of transaction To. Depth is the depth of the edge's farther transaction.
+type TxGraphEdge struct {
+	From  string  `json:"from"`
+	Vout  uint32  `json:"vout"`
+	To    string  `json:"to"`
+	Vin   uint32  `json:"vin"`
+	Value float64 `json:"value"`
+	Depth int     `json:"depth"`
+}
+
+// AtomicSwap is the redemption or refund of an atomic swap contract output by
+// a transaction input. Value is the amount of the contract output, and Secret
+// is empty for a refund. LockTime is the time or block height after which the
+// contract may be refunded.
+type AtomicSwap struct {
+	ContractTx       string  `json:"contract_txid"`
+	ContractVout     uint32  `json:"contract_vout"`
+	ContractAddress  string  `json:"contract_address"`
+	RecipientAddress string  `json:"recipient_address"`
+	RefundAddress    string  `json:"refund_address"`
+	Value            float64 `json:"value"`
+	SecretHash       string  `json:"secret_hash"`
+	Secret           string  `json:"secret,omitempty"`
+	LockTime         int64   `json:"lock_time"`
+	IsRefund         bool    `json:"refund"`
+	SpendTx          string  `json:"spend_txid"`
+	SpendVin         uint32  `json:"spend_vin"`
+	BlockHeight      int64   `json:"block_height"`
+	Time             TimeAPI `json:"time"`
+}
+
+// ProposalSpending is the billing of an approved Politeia proposal, with the
+// payments of its invoices. The billed amounts are in US dollars, and
+// TotalBilledDCR is the sum of the invoices' BilledDCR.
+type ProposalSpending struct {
+	Token          string            `json:"token"`
+	Title          string            `json:"title"`
+	TotalBilled    float64           `json:"total_billed_usd"`
+	TotalBilledDCR float64           `json:"total_billed_dcr"`
+	Invoices       []ProposalInvoice `json:"invoices"`
+	UpdatedAt      int64             `json:"updated_at"`
+}
+
+// ProposalInvoice is a contractor invoice billed in part to a proposal.
+// BilledDCR is the amount billed to the proposal at the invoice's exchange
+// rate. Paid is the total of the payments, which are for the whole invoice,
+// including any work billed to other proposals.
+type ProposalInvoice struct {
+	Token          string            `json:"token"`
+	Month          uint              `json:"month"`
+	Year           uint              `json:"year"`
+	Contractor     string            `json:"contractor"`
+	Billed         float64           `json:"billed_usd"`
+	ExchangeRate   float64           `json:"exchange_rate"`
+	BilledDCR      float64           `json:"billed_dcr"`
+	PaymentAddress string            `json:"payment_address"`
+	Paid           float64           `json:"paid"`
+	Payments       []ProposalPayment `json:"payments"`
+}
+
+// The sources of the proposal invoice payments.
+const (
+	PaymentSourceTSpend         = "tspend"
+	PaymentSourceLegacyTreasury = "legacy"
+	PaymentSourceOther          = "other"
+)
+
+// ProposalPayment is a payment to an invoice's payment address. The Source is
+// PaymentSourceTSpend for a TSpend, PaymentSourceLegacyTreasury for a
+// transaction spending from the legacy treasury address, or
+// PaymentSourceOther.
+type ProposalPayment struct {
+	TxID        string  `json:"txid"`
+	BlockHeight int64   `json:"block_height"`
+	Time        TimeAPI `json:"time"`
+	Amount      float64 `json:"amount"`
+	Source      string  `json:"source"`
+}
+
+// TSpend describes a mined treasury spend. Amount is the total spent from the
+// treasury, including the fee.
+type TSpend struct {
+	TxID        string          `json:"txid"`
+	BlockHash   string          `json:"block_hash"`
+	BlockHeight int64           `json:"block_height"`
+	Time        TimeAPI         `json:"time"`
+	Amount      float64         `json:"amount"`
+	Fee         float64         `json:"fee"`
+	Expiry      uint32          `json:"expiry"`
+	Payouts     []TSpendPayout  `json:"payouts"`
+	Votes       TSpendVoteTally `json:"votes"`
+}
@@ -0,0 +1,1076 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	m "github.com/decred/dcrdata/middleware/v3"
+	"github.com/go-chi/chi"
+)
+
+// The /api/v2 routes are a translation layer over the v1 handlers, so that
+// both versions share the same query code. A v2 request is rewritten to the
+// corresponding v1 path and served by the v1 router, and the v1 JSON response
+// is then translated:
+//
+//   - Amount fields are renamed with an explicit unit suffix: _atoms or _dcr,
+//     _dcr_per_kb for fee rates, and _btc for DCR-BTC prices.
+//   - List responses are wrapped in a pagination envelope, with the offset and
+//     limit given by the ?offset=M&limit=N URL queries instead of the
+//     /count/{N}/skip/{M} path elements or the differently named URL queries
+//     of v1.
+//   - Bare arrays of amounts are wrapped in an object with a field named for
+//     their unit.
+//
+// Every v1 GET route with a JSON response, other than the /list of the v1
+// routes, has a v2 route. The routes that respond with plain text, CSV, binary
+// data or the pre-encoded chart data shared with the explorer, and the POST and
+// DELETE routes, are only in v1.
+
+// v2Route describes a v2 API route and its translation to a v1 route.
+type v2Route struct {
+	// pattern is the chi route pattern of the v2 route.
+	pattern string
+	// v1 is the path of the v1 route. URL parameters of pattern are given as
+	// {name}, and for paged routes the page is given as {limit} and {offset}.
+	v1 string
+	// renames maps the dot-separated paths of the v1 JSON fields to their v2
+	// names. Array elements share the path of the array.
+	renames map[string]string
+	// list is the field of the v1 response with the list of results of a
+	// paged route, which becomes the data field of the pagination envelope,
+	// or v2RootList if the response is the list. list is empty for routes
+	// that are not paged.
+	list string
+	// total is the field of the v1 response with the total number of results
+	// of a paged route, if any.
+	total string
+	// limitQuery and offsetQuery are the URL queries with the page of a v1
+	// route that is paged by URL queries rather than by v2PageSuffix.
+	limitQuery, offsetQuery string
+	// defLimit and maxLimit are the default and maximum page size.
+	defLimit, maxLimit int
+	// wrap is the field of the v2 response that holds the v1 response, for
+	// v1 responses that are a bare array of amounts, the unit of which
+	// would otherwise not be named.
+	wrap string
+}
+
+// v2PageSuffix is the v1 path element with the page of routes paged by path,
+// which is optional in the v1 paths matched for the successor-version Link.
+const v2PageSuffix = "/count/{limit}/skip/{offset}"
+
+// v2RootList is the list field of the paged routes whose v1 response is the
+// list itself rather than an object.
+const v2RootList = "."
+
+var (
+	blockRenames = map[string]string{
+		"sdiff":              "sdiff_dcr",
+		"fees":               "fees_atoms",
+		"total_sent":         "total_sent_atoms",
+		"ticket_pool.value":  "value_dcr",
+		"ticket_pool.valavg": "valavg_dcr",
+	}
+
+	headerRenames = map[string]string{
+		"sbits": "sbits_dcr",
+	}
+
+	verboseBlockRenames = map[string]string{
+		"sbits":               "sbits_dcr",
+		"rawtx.vin.amountin":  "amountin_dcr",
+		"rawtx.vout.value":    "value_dcr",
+		"rawstx.vin.amountin": "amountin_dcr",
+		"rawstx.vout.value":   "value_dcr",
+	}
+
+	subsidyRenames = map[string]string{
+		"work_reward":        "work_reward_atoms",
+		"stake_reward":       "stake_reward_atoms",
+		"stake_reward_total": "stake_reward_total_atoms",
+		"project_subsidy":    "project_subsidy_atoms",
+		"total":              "total_atoms",
+	}
+
+	stakeInfoRenames = map[string]string{
+		"stakediff":          "stakediff_dcr",
+		"feeinfo.min":        "min_dcr_per_kb",
+		"feeinfo.max":        "max_dcr_per_kb",
+		"feeinfo.mean":       "mean_dcr_per_kb",
+		"feeinfo.median":     "median_dcr_per_kb",
+		"feeinfo.stddev":     "stddev_dcr_per_kb",
+		"ticket_pool.value":  "value_dcr",
+		"ticket_pool.valavg": "valavg_dcr",
+	}
+
+	poolInfoRenames = map[string]string{
+		"value":  "value_dcr",
+		"valavg": "valavg_dcr",
+	}
+
+	txRenames = map[string]string{
+		"vin.amountin": "amountin_dcr",
+		"vout.value":   "value_dcr",
+	}
+
+	// valueRenames are the renames of transaction inputs and outputs, and of
+	// atomic swaps, the value of which is in DCR.
+	valueRenames = map[string]string{
+		"value": "value_dcr",
+	}
+
+	ticketFeesRenames = map[string]string{
+		"top_fees": "top_fees_dcr_per_kb",
+	}
+
+	ticketDetailsRenames = map[string]string{
+		"tickets.abs_fee": "abs_fee_dcr",
+		"tickets.fee":     "fee_rate_dcr_per_kb",
+	}
+
+	ticketPoolTimeRenames = map[string]string{
+		"time_chart.price": "price_dcr",
+	}
+
+	v2Routes = append(v2BlockRoutes("/block/best", "/block/hash/{blockhash}", "/block/{idx}"), []*v2Route{
+		{
+			pattern: "/status",
+			v1:      "/status",
+		},
+		{
+			pattern: "/status/happy",
+			v1:      "/status/happy",
+		},
+		{
+			pattern: "/sync",
+			v1:      "/sync",
+		},
+		{
+			pattern: "/supply",
+			v1:      "/supply",
+			renames: map[string]string{
+				"supply_mined":    "supply_mined_atoms",
+				"supply_ultimate": "supply_ultimate_atoms",
+			},
+		},
+		{
+			pattern: "/supply/schedule",
+			v1:      "/supply/schedule",
+			renames: map[string]string{
+				"supply_circulating":      "supply_circulating_atoms",
+				"supply_ultimate":         "supply_ultimate_atoms",
+				"ranges.pow":              "pow_atoms",
+				"ranges.pos":              "pos_atoms",
+				"ranges.treasury":         "treasury_atoms",
+				"ranges.max_supply":       "max_supply_atoms",
+				"ranges.projected_supply": "projected_supply_atoms",
+			},
+		},
+		{
+			pattern: "/utxoset/stats",
+			v1:      "/utxoset/stats",
+			renames: map[string]string{
+				"total_value":        "total_value_dcr",
+				"script_types.value": "value_dcr",
+			},
+		},
+		{
+			pattern: "/chaininfo",
+			v1:      "/chaininfo",
+			renames: map[string]string{
+				"stake.min_stake_diff": "min_stake_diff_atoms",
+				"subsidy.base":         "base_atoms",
+			},
+		},
+		{
+			pattern: "/block/side/{blockhash}",
+			v1:      "/block/side/{blockhash}",
+			renames: map[string]string{
+				"tx.fee":        "fee_dcr",
+				"tx.total_out":  "total_out_dcr",
+				"stx.fee":       "fee_dcr",
+				"stx.total_out": "total_out_dcr",
+			},
+		},
+		{
+			pattern: "/block/timestamp-anomalies",
+			v1:      "/block/timestamp-anomalies",
+		},
+		{
+			pattern: "/block/range/{idx0}/{idx}",
+			v1:      "/block/range/{idx0}/{idx}",
+			renames: blockRenames,
+		},
+		{
+			pattern: "/block/range/{idx0}/{idx}/size",
+			v1:      "/block/range/{idx0}/{idx}/size",
+		},
+		{
+			pattern: "/block/range/{idx0}/{idx}/{step}",
+			v1:      "/block/range/{idx0}/{idx}/{step}",
+			renames: blockRenames,
+		},
+		{
+			pattern: "/block/range/{idx0}/{idx}/{step}/size",
+			v1:      "/block/range/{idx0}/{idx}/{step}/size",
+		},
+		{
+			pattern: "/stake/vote/info",
+			v1:      "/stake/vote/info",
+		},
+		{
+			pattern: "/stake/vote/versions/{chartgrouping}",
+			v1:      "/stake/vote/versions/{chartgrouping}",
+		},
+		{
+			pattern: "/stake/vote/bits",
+			v1:      "/stake/vote/bits",
+		},
+		{
+			pattern: "/stake/pool",
+			v1:      "/stake/pool",
+			renames: poolInfoRenames,
+		},
+		{
+			pattern: "/stake/pool/full",
+			v1:      "/stake/pool/full",
+		},
+		{
+			pattern: "/stake/pool/b/{idx}",
+			v1:      "/stake/pool/b/{idx}",
+			renames: poolInfoRenames,
+		},
+		{
+			pattern: "/stake/pool/b/{idxorhash}/full",
+			v1:      "/stake/pool/b/{idxorhash}/full",
+		},
+		{
+			pattern: "/stake/pool/r/{idx0}/{idx}",
+			v1:      "/stake/pool/r/{idx0}/{idx}",
+			renames: poolInfoRenames,
+		},
+		{
+			pattern: "/stake/diff",
+			v1:      "/stake/diff",
+			renames: map[string]string{
+				"current":            "current_dcr",
+				"next":               "next_dcr",
+				"estimates.min":      "min_dcr",
+				"estimates.max":      "max_dcr",
+				"estimates.expected": "expected_dcr",
+				"estimates.user":     "user_dcr",
+			},
+		},
+		{
+			pattern: "/stake/diff/current",
+			v1:      "/stake/diff/current",
+			renames: map[string]string{
+				"current": "current_dcr",
+				"next":    "next_dcr",
+			},
+		},
+		{
+			pattern: "/stake/diff/estimates",
+			v1:      "/stake/diff/estimates",
+			renames: map[string]string{
+				"min":      "min_dcr",
+				"max":      "max_dcr",
+				"expected": "expected_dcr",
+				"user":     "user_dcr",
+			},
+		},
+		{
+			pattern: "/stake/diff/prediction",
+			v1:      "/stake/diff/prediction",
+			renames: map[string]string{
+				"current":               "current_dcr",
+				"estimate.min":          "min_dcr",
+				"estimate.max":          "max_dcr",
+				"estimate.expected":     "expected_dcr",
+				"with_mempool.min":      "min_dcr",
+				"with_mempool.max":      "max_dcr",
+				"with_mempool.expected": "expected_dcr",
+			},
+		},
+		{
+			pattern: "/stake/diff/b/{idx}",
+			v1:      "/stake/diff/b/{idx}",
+			wrap:    "sdiff_dcr",
+		},
+		{
+			pattern: "/stake/diff/r/{idx0}/{idx}",
+			v1:      "/stake/diff/r/{idx0}/{idx}",
+			wrap:    "sdiff_dcr",
+		},
+		{
+			pattern: "/stake/powerless",
+			v1:      "/stake/powerless",
+			renames: map[string]string{
+				"unspent.p": "price_dcr",
+				"revoked.p": "price_dcr",
+			},
+		},
+		{
+			pattern: "/stake/roi",
+			v1:      "/stake/roi",
+			renames: map[string]string{
+				"amount":              "amount_dcr",
+				"final_amount":        "final_amount_dcr",
+				"reward":              "reward_dcr",
+				"cycles.ticket_price": "ticket_price_dcr",
+				"cycles.vote_reward":  "vote_reward_dcr",
+				"cycles.balance":      "balance_dcr",
+			},
+		},
+		{
+			pattern: "/tx/decoded/{txid}",
+			v1:      "/tx/decoded/{txid}",
+			renames: txRenames,
+		},
+		{
+			pattern: "/tx/{txid}",
+			v1:      "/tx/{txid}",
+			renames: txRenames,
+		},
+		{
+			pattern: "/tx/{txid}/trimmed",
+			v1:      "/tx/{txid}/trimmed",
+			renames: txRenames,
+		},
+		{
+			pattern: "/tx/{txid}/out",
+			v1:      "/tx/{txid}/out",
+			renames: valueRenames,
+		},
+		{
+			pattern: "/tx/{txid}/out/{txinoutindex}",
+			v1:      "/tx/{txid}/out/{txinoutindex}",
+			renames: valueRenames,
+		},
+		{
+			pattern: "/tx/{txid}/in",
+			v1:      "/tx/{txid}/in",
+			renames: valueRenames,
+		},
+		{
+			pattern: "/tx/{txid}/in/{txinoutindex}",
+			v1:      "/tx/{txid}/in/{txinoutindex}",
+			renames: valueRenames,
+		},
+		{
+			pattern: "/tx/{txid}/vinfo",
+			v1:      "/tx/{txid}/vinfo",
+		},
+		{
+			pattern: "/tx/{txid}/tinfo",
+			v1:      "/tx/{txid}/tinfo",
+		},
+		{
+			pattern: "/tx/{txid}/proof",
+			v1:      "/tx/{txid}/proof",
+		},
+		{
+			pattern: "/tx/{txid}/firstseen",
+			v1:      "/tx/{txid}/firstseen",
+			renames: map[string]string{
+				"fees":     "fees_dcr",
+				"fee_rate": "fee_rate_dcr_per_kb",
+			},
+		},
+		{
+			pattern: "/tx/{txid}/swaps",
+			v1:      "/tx/{txid}/swaps",
+			renames: valueRenames,
+		},
+		{
+			pattern: "/tx/{txid}/graph",
+			v1:      "/tx/{txid}/graph",
+			renames: map[string]string{
+				"nodes.value": "value_dcr",
+				"nodes.fees":  "fees_dcr",
+				"edges.value": "value_dcr",
+			},
+		},
+		{
+			pattern:  "/chain/reorgs",
+			v1:       "/chain/reorgs" + v2PageSuffix,
+			list:     "reorgs",
+			total:    "total",
+			defLimit: 100,
+			maxLimit: 2000,
+		},
+		{
+			pattern: "/outputs/nonstandard",
+			v1:      "/outputs/nonstandard" + v2PageSuffix,
+			renames: map[string]string{
+				"outputs.value": "value_atoms",
+			},
+			list:     "outputs",
+			total:    "total",
+			defLimit: 100,
+			maxLimit: 2000,
+		},
+		{
+			pattern: "/treasury/balance",
+			v1:      "/treasury/balance",
+			renames: map[string]string{
+				"balance": "balance_dcr",
+			},
+		},
+		{
+			pattern: "/treasury/balance/{chartgrouping}",
+			v1:      "/treasury/balance/{chartgrouping}",
+			renames: map[string]string{
+				"added":   "added_dcr",
+				"spent":   "spent_dcr",
+				"balance": "balance_dcr",
+			},
+		},
+		{
+			pattern: "/treasury/tspend/{txid}",
+			v1:      "/treasury/tspend/{txid}",
+			renames: map[string]string{
+				"amount":         "amount_dcr",
+				"fee":            "fee_dcr",
+				"payouts.amount": "amount_dcr",
+			},
+		},
+		{
+			pattern: "/treasury/tspend/{txid}/votes",
+			v1:      "/treasury/tspend/{txid}/votes",
+		},
+		{
+			pattern:     "/swaps/recent",
+			v1:          "/swaps/recent",
+			renames:     valueRenames,
+			list:        v2RootList,
+			limitQuery:  "n",
+			offsetQuery: "skip",
+			defLimit:    20,
+			maxLimit:    maxRecentSwaps,
+		},
+		{
+			pattern: "/address/{address}/exists",
+			v1:      "/address/{address}/exists",
+		},
+		{
+			pattern: "/address/{address}/totals",
+			v1:      "/address/{address}/totals",
+			renames: map[string]string{
+				"dcr_spent":   "spent_dcr",
+				"dcr_unspent": "unspent_dcr",
+			},
+		},
+		{
+			pattern: "/address/{address}/cluster",
+			v1:      "/address/{address}/cluster",
+			renames: map[string]string{
+				"balance": "balance_dcr",
+			},
+		},
+		{
+			pattern: "/address/{address}/tag",
+			v1:      "/address/{address}/tag",
+		},
+		{
+			pattern: "/address/{address}/balance/{idx}",
+			v1:      "/address/{address}/balance/{idx}",
+			renames: map[string]string{
+				"balance": "balance_dcr",
+				"atoms":   "balance_atoms",
+			},
+		},
+		{
+			pattern: "/address/{address}/txs",
+			v1:      "/address/{address}" + v2PageSuffix,
+			renames: map[string]string{
+				"address_transactions.value": "value_dcr",
+			},
+			list:     "address_transactions",
+			defLimit: 10,
+			maxLimit: 8000,
+		},
+		{
+			pattern: "/address/{address}/txs/raw",
+			v1:      "/address/{address}" + v2PageSuffix + "/raw",
+			renames: map[string]string{
+				"vin.amountin":      "amountin_dcr",
+				"vin.prevOut.value": "value_dcr",
+				"vout.value":        "value_dcr",
+			},
+			list:     v2RootList,
+			defLimit: 10,
+			maxLimit: 8000,
+		},
+		{
+			pattern: "/address/{address}/types/{chartgrouping}",
+			v1:      "/address/{address}/types/{chartgrouping}",
+		},
+		{
+			pattern: "/address/{address}/amountflow/{chartgrouping}",
+			v1:      "/address/{address}/amountflow/{chartgrouping}",
+			renames: map[string]string{
+				"received": "received_dcr",
+				"sent":     "sent_dcr",
+				"net":      "net_dcr",
+			},
+		},
+		{
+			pattern: "/address/{address}/io/json",
+			v1:      "/address/{address}/io/json",
+			renames: map[string]string{
+				"entries.amount":  "amount_dcr",
+				"entries.balance": "balance_dcr",
+			},
+			list:        "entries",
+			total:       "total",
+			limitQuery:  "count",
+			offsetQuery: "skip",
+			defLimit:    100,
+			maxLimit:    8000,
+		},
+		{
+			pattern: "/addresses/rich",
+			v1:      "/addresses/rich",
+			renames: map[string]string{
+				"addresses.balance":          "balance_dcr",
+				"distribution.min_balance":   "min_balance_dcr",
+				"distribution.total_balance": "total_balance_dcr",
+			},
+		},
+		{
+			pattern: "/addresses/tags",
+			v1:      "/addresses/tags",
+		},
+		{
+			pattern: "/vsps",
+			v1:      "/vsps",
+		},
+		{
+			pattern: "/search",
+			v1:      "/search",
+		},
+		{
+			pattern: "/agendas",
+			v1:      "/agendas",
+		},
+		{
+			pattern: "/agenda/{agendaId}",
+			v1:      "/agenda/{agendaId}",
+		},
+		{
+			pattern: "/agenda/{agendaId}/votes/timeseries",
+			v1:      "/agenda/{agendaId}/votes/timeseries",
+		},
+		{
+			pattern: "/mempool/feerates",
+			v1:      "/mempool/feerates",
+			renames: map[string]string{
+				"percentiles.fee_rate": "fee_rate_dcr_per_kb",
+				"estimates.fee_rate":   "fee_rate_dcr_per_kb",
+			},
+		},
+		{
+			pattern: "/mempool/history/{chartgrouping}",
+			v1:      "/mempool/history/{chartgrouping}",
+			renames: map[string]string{
+				"median_fee_rate": "median_fee_rate_dcr_per_kb",
+			},
+		},
+		{
+			pattern: "/mempool/sstx",
+			v1:      "/mempool/sstx",
+			renames: map[string]string{
+				"min":             "min_dcr_per_kb",
+				"max":             "max_dcr_per_kb",
+				"mean":            "mean_dcr_per_kb",
+				"median":          "median_dcr_per_kb",
+				"stddev":          "stddev_dcr_per_kb",
+				"lowest_mineable": "lowest_mineable_dcr_per_kb",
+			},
+		},
+		{
+			pattern: "/mempool/sstx/fees",
+			v1:      "/mempool/sstx/fees",
+			renames: ticketFeesRenames,
+		},
+		{
+			pattern: "/mempool/sstx/fees/{N}",
+			v1:      "/mempool/sstx/fees/{N}",
+			renames: ticketFeesRenames,
+		},
+		{
+			pattern: "/mempool/sstx/details",
+			v1:      "/mempool/sstx/details",
+			renames: ticketDetailsRenames,
+		},
+		{
+			pattern: "/mempool/sstx/details/{N}",
+			v1:      "/mempool/sstx/details/{N}",
+			renames: ticketDetailsRenames,
+		},
+		{
+			pattern: "/chart/coin-age/cdd/{chartgrouping}",
+			v1:      "/chart/coin-age/cdd/{chartgrouping}",
+			renames: map[string]string{
+				"spent": "spent_dcr",
+			},
+		},
+		{
+			pattern: "/chart/coin-age/bands",
+			v1:      "/chart/coin-age/bands",
+			renames: map[string]string{
+				"values": "values_dcr",
+			},
+		},
+		{
+			pattern: "/chart/block-propagation",
+			v1:      "/chart/block-propagation",
+		},
+		{
+			pattern: "/chart/script-types",
+			v1:      "/chart/script-types",
+			renames: map[string]string{
+				"values": "values_dcr",
+			},
+		},
+		{
+			pattern: "/chart/script-types/{chartgrouping}",
+			v1:      "/chart/script-types/{chartgrouping}",
+			renames: map[string]string{
+				"values": "values_dcr",
+			},
+		},
+		{
+			pattern: "/ticket/{txid}/lifecycle",
+			v1:      "/ticket/{txid}/lifecycle",
+			renames: map[string]string{
+				"price":  "price_dcr",
+				"fee":    "fee_dcr",
+				"reward": "reward_dcr",
+			},
+		},
+		{
+			pattern: "/ticketpool",
+			v1:      "/ticketpool",
+			renames: ticketPoolTimeRenames,
+		},
+		{
+			pattern: "/ticketpool/bydate/{tp}",
+			v1:      "/ticketpool/bydate/{tp}",
+			renames: ticketPoolTimeRenames,
+		},
+		{
+			pattern: "/ticketpool/charts",
+			v1:      "/ticketpool/charts",
+			renames: map[string]string{
+				"time_chart.price":  "price_dcr",
+				"price_chart.price": "price_dcr",
+				"mempool.price":     "price_dcr",
+			},
+		},
+		{
+			pattern: "/proposal/{token}",
+			v1:      "/proposal/{token}",
+		},
+		{
+			pattern: "/proposal/{token}/snapshots",
+			v1:      "/proposal/{token}/snapshots",
+		},
+		{
+			pattern: "/proposal/{token}/spending",
+			v1:      "/proposal/{token}/spending",
+			renames: map[string]string{
+				"invoices.paid":            "paid_dcr",
+				"invoices.payments.amount": "amount_dcr",
+			},
+		},
+		{
+			pattern: "/exchanges",
+			v1:      "/exchanges",
+		},
+		{
+			pattern: "/exchanges/codes",
+			v1:      "/exchanges/codes",
+		},
+		{
+			pattern: "/exchangerate",
+			v1:      "/exchangerate",
+		},
+		{
+			pattern: "/market/{token}/candles",
+			v1:      "/market/{token}/candles",
+			renames: map[string]string{
+				"open":   "open_btc",
+				"high":   "high_btc",
+				"low":    "low_btc",
+				"close":  "close_btc",
+				"volume": "volume_dcr",
+			},
+		},
+	}...)
+)
+
+// v2BlockRoutes returns the v2 routes of a block for each of the v1 path
+// prefixes that identify the block, such as /block/best or /block/{idx}.
+func v2BlockRoutes(prefixes ...string) []*v2Route {
+	var routes []*v2Route
+	for _, prefix := range prefixes {
+		for _, rt := range []v2Route{
+			{pattern: prefix, renames: blockRenames},
+			{pattern: prefix + "/header", renames: headerRenames},
+			{pattern: prefix + "/header/raw"},
+			{pattern: prefix + "/raw"},
+			{pattern: prefix + "/size"},
+			{pattern: prefix + "/subsidy", renames: subsidyRenames},
+			{pattern: prefix + "/verbose", renames: verboseBlockRenames},
+			{pattern: prefix + "/pos", renames: stakeInfoRenames},
+			{pattern: prefix + "/tx"},
+			{pattern: prefix + "/tx/count"},
+		} {
+			rt := rt
+			rt.v1 = rt.pattern
+			routes = append(routes, &rt)
+		}
+	}
+	return routes
+}
+
+// routeParam matches the {name} URL parameters of route patterns.
+var routeParam = regexp.MustCompile(`{[a-zA-Z0-9]+}`)
+
+// v1Regexp returns a regular expression matching the v1 paths of the route,
+// with a named group for each URL parameter. The page of routes paged by path
+// is optional.
+func (rt *v2Route) v1Regexp() *regexp.Regexp {
+	parts := strings.SplitN(rt.v1, v2PageSuffix, 2)
+	for i, part := range parts {
+		segments := strings.Split(part, "/")
+		for j, seg := range segments {
+			if routeParam.MatchString(seg) {
+				segments[j] = "(?P<" + strings.Trim(seg, "{}") + ">[^/]+)"
+				continue
+			}
+			segments[j] = regexp.QuoteMeta(seg)
+		}
+		parts[i] = strings.Join(segments, "/")
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "(/count/[^/]+(/skip/[^/]+)?)?") + "/?$")
+}
+
+// v2Page is the pagination envelope of the v2 list responses.
+type v2Page struct {
+	Data       interface{}            `json:"data"`
+	Pagination v2Pagination           `json:"pagination"`
+	Extra      map[string]interface{} `json:"-"`
+}
+
+// v2Pagination describes the page of a v2 list response. Total is omitted if
+// the v1 route does not count the results.
+type v2Pagination struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Count  int    `json:"count"`
+	Total  *int64 `json:"total,omitempty"`
+}
+
+// MarshalJSON adds the other fields of the v1 response, such as the address of
+// the address transactions list, alongside data and pagination.
+func (p *v2Page) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]interface{}, len(p.Extra)+2)
+	for k, v := range p.Extra {
+		obj[k] = v
+	}
+	obj["data"] = p.Data
+	obj["pagination"] = p.Pagination
+	return json.Marshal(obj)
+}
+
+// NewAPIV2Router creates the router for the /api/v2 routes, which are served
+// by translating the requests to and the responses from the v1 router. Rate
+// limiting and request logging are done by the v1 router.
+func NewAPIV2Router(v1 apiMux, JSONIndent string) *chi.Mux {
+	mux := chi.NewRouter()
+	mux.Use(m.Indent(JSONIndent))
+	for _, rt := range v2Routes {
+		mux.Get(rt.pattern, v1.v2Handler(rt))
+	}
+	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, r.URL.RequestURI()+" ain't no country I've ever heard of! (404)", http.StatusNotFound)
+	})
+	return mux
+}
+
+// v2Handler serves the v2 route rt with the v1 router.
+func (mux apiMux) v2Handler(rt *v2Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path, query := rt.v1, r.URL.Query()
+		var offset, limit int
+		if rt.list != "" {
+			var err error
+			offset, limit, err = v2PageQuery(query, rt.defLimit, rt.maxLimit)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			query.Del("offset")
+			query.Del("limit")
+			if rt.limitQuery != "" {
+				query.Set(rt.limitQuery, strconv.Itoa(limit))
+				query.Set(rt.offsetQuery, strconv.Itoa(offset))
+			} else {
+				path = strings.NewReplacer("{limit}", strconv.Itoa(limit),
+					"{offset}", strconv.Itoa(offset)).Replace(path)
+			}
+		}
+		rctx := chi.RouteContext(r.Context())
+		path = routeParam.ReplaceAllStringFunc(path, func(p string) string {
+			return url.PathEscape(rctx.URLParam(strings.Trim(p, "{}")))
+		})
+
+		rec := newV2Recorder()
+		mux.ServeHTTP(rec, v1Request(r, path, query))
+		if rec.status != http.StatusOK {
+			rec.copyTo(w)
+			return
+		}
+
+		dec := json.NewDecoder(&rec.body)
+		dec.UseNumber() // keep the precision of atoms amounts
+		var resp interface{}
+		if err := dec.Decode(&resp); err != nil {
+			apiLog.Errorf("Failed to decode v1 response for %s: %v", path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		resp = renameFields(resp, "", rt.renames)
+		if rt.wrap != "" {
+			resp = map[string]interface{}{rt.wrap: resp}
+		}
+
+		if rt.list != "" {
+			page, err := v2Envelope(resp, rt, offset, limit)
+			if err != nil {
+				apiLog.Errorf("Failed to paginate v1 response for %s: %v", path, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			resp = page
+		}
+		writeJSON(w, resp, m.GetIndentCtx(r))
+	}
+}
+
+// v2PageQuery parses the ?offset=M&limit=N URL queries. The limit is set to
+// defLimit if not specified, and capped at maxLimit.
+func v2PageQuery(q url.Values, defLimit, maxLimit int) (offset, limit int, err error) {
+	limit = defLimit
+	if l := q.Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit %q", l)
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q", o)
+		}
+	}
+	return offset, limit, nil
+}
+
+// v1Request creates the request for the v1 path and URL queries from the v2
+// request. The request has a new chi route context so that the v1 router
+// routes the path rather than the v2 route. The Accept-Encoding header is
+// dropped since the response is decoded.
+func v1Request(r *http.Request, path string, query url.Values) *http.Request {
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, chi.NewRouteContext())
+	r1 := r.WithContext(ctx)
+	r1.URL = &url.URL{Path: path, RawQuery: query.Encode()}
+	r1.RequestURI = r1.URL.RequestURI()
+	r1.Header = make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		r1.Header[k] = v
+	}
+	r1.Header.Del("Accept-Encoding")
+	return r1
+}
+
+// renameFields renames the fields of the decoded JSON value v whose paths are
+// keys of renames. path is the path of v.
+func renameFields(v interface{}, path string, renames map[string]string) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(vt))
+		for k, val := range vt {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if name, ok := renames[p]; ok {
+				k = name
+			}
+			out[k] = renameFields(val, p, renames)
+		}
+		return out
+	case []interface{}:
+		for i := range vt {
+			vt[i] = renameFields(vt[i], path, renames)
+		}
+		return vt
+	}
+	return v
+}
+
+// v2Envelope wraps the list of the decoded v1 response of a paged route in a
+// pagination envelope. The other fields of the v1 response are kept alongside
+// the data and pagination fields.
+func v2Envelope(resp interface{}, rt *v2Route, offset, limit int) (*v2Page, error) {
+	var obj map[string]interface{}
+	var data []interface{}
+	var ok bool
+	if rt.list == v2RootList {
+		if data, ok = resp.([]interface{}); !ok && resp != nil {
+			return nil, fmt.Errorf("response is not an array")
+		}
+	} else {
+		if obj, ok = resp.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("response is not an object")
+		}
+		data, _ = obj[rt.list].([]interface{})
+	}
+	if data == nil {
+		data = []interface{}{}
+	}
+	page := &v2Page{
+		Data: data,
+		Pagination: v2Pagination{
+			Offset: offset,
+			Limit:  limit,
+			Count:  len(data),
+		},
+		Extra: make(map[string]interface{}, len(obj)),
+	}
+	for k, v := range obj {
+		if k == rt.list {
+			continue
+		}
+		if k == rt.total && rt.total != "" {
+			if n, ok := v.(json.Number); ok {
+				if total, err := n.Int64(); err == nil {
+					page.Pagination.Total = &total
+					continue
+				}
+			}
+		}
+		page.Extra[k] = v
+	}
+	return page, nil
+}
+
+// v2Recorder is a http.ResponseWriter that records the v1 response.
+type v2Recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newV2Recorder() *v2Recorder {
+	return &v2Recorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (rec *v2Recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *v2Recorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
+
+func (rec *v2Recorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// copyTo writes the recorded response to w.
+func (rec *v2Recorder) copyTo(w http.ResponseWriter) {
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	w.Write(rec.body.Bytes())
+}
+
+// v2Successor returns the v2 path that succeeds the v1 path, or "" if there is
+// no v2 route for the path.
+func v2Successor(path string) string {
+	for i, re := range v2Regexps {
+		match := re.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+		names := re.SubexpNames()
+		return routeParam.ReplaceAllStringFunc(v2Routes[i].pattern, func(p string) string {
+			name := strings.Trim(p, "{}")
+			for j := range names {
+				if names[j] == name {
+					return match[j]
+				}
+			}
+			return p
+		})
+	}
+	return ""
+}
+
+// v2Regexps are the regular expressions matching the v1 paths of the
+// v2Routes, in the same order.
+var v2Regexps = func() []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(v2Routes))
+	for i, rt := range v2Routes {
+		res[i] = rt.v1Regexp()
+	}
+	return res
+}()
+
+// DeprecationHeaders is middleware that marks the v1 API responses as
+// deprecated with the Deprecation and, if sunset is not the zero time, the
+// Sunset (RFC 8594) response headers. If a v2 route succeeds the requested
+// route, a Link header with the successor-version relation is also set.
+// prefix is the path prefix at which the v1 router is mounted, such as /api.
+func DeprecationHeaders(prefix string, sunset time.Time) func(http.Handler) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			path := strings.TrimPrefix(r.URL.Path, prefix)
+			if successor := v2Successor(path); successor != "" {
+				h.Add("Link", fmt.Sprintf(`<%s/v2%s>; rel="successor-version"`,
+					prefix, successor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,624 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/go-chi/chi"
+)
+
+// decodeV1 encodes the v1 response thing, and decodes it as the v2 handler
+// does.
+func decodeV1(t *testing.T, thing interface{}) interface{} {
+	b, err := json.Marshal(thing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err = dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// fieldAt finds the field at the dot-separated path of the decoded JSON value,
+// looking in the first element of arrays.
+func fieldAt(v interface{}, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		for {
+			arr, ok := v.([]interface{})
+			if !ok {
+				break
+			}
+			if len(arr) == 0 {
+				return false
+			}
+			v = arr[0]
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if v, ok = obj[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func findV2Route(pattern string) *v2Route {
+	for _, rt := range v2Routes {
+		if rt.pattern == pattern {
+			return rt
+		}
+	}
+	return nil
+}
+
+func TestV2RouteRenames(t *testing.T) {
+	one, fees := 1.0, int64(12000)
+	rawTx := chainjson.TxRawResult{
+		Vin:  []chainjson.Vin{{AmountIn: 1}},
+		Vout: []chainjson.Vout{{Value: 1}},
+	}
+	poolChart := &dbtypes.PoolTicketsData{Price: []float64{1}}
+
+	// The v1 responses are of the types written by the v1 handlers, and want
+	// are the paths of the renamed fields in the v2 responses.
+	tests := []struct {
+		pattern string
+		v1      interface{}
+		want    []string
+	}{{
+		pattern: "/block/best",
+		v1: &apitypes.BlockDataBasic{
+			MiningFee: &fees,
+			TotalSent: &fees,
+			PoolInfo:  &apitypes.TicketPoolInfo{},
+		},
+		want: []string{"sdiff_dcr", "fees_atoms", "total_sent_atoms",
+			"ticket_pool.value_dcr", "ticket_pool.valavg_dcr"},
+	}, {
+		pattern: "/block/best/header",
+		v1:      &chainjson.GetBlockHeaderVerboseResult{},
+		want:    []string{"sbits_dcr"},
+	}, {
+		pattern: "/block/best/verbose",
+		v1: &chainjson.GetBlockVerboseResult{
+			RawTx:  []chainjson.TxRawResult{rawTx},
+			RawSTx: []chainjson.TxRawResult{rawTx},
+		},
+		want: []string{"sbits_dcr", "rawtx.vin.amountin_dcr", "rawtx.vout.value_dcr",
+			"rawstx.vin.amountin_dcr", "rawstx.vout.value_dcr"},
+	}, {
+		pattern: "/block/best/subsidy",
+		v1:      &apitypes.BlockSubsidies{NumVotes: 5, TotalStake: 5, Total: 7},
+		want: []string{"work_reward_atoms", "stake_reward_atoms",
+			"stake_reward_total_atoms", "project_subsidy_atoms", "total_atoms"},
+	}, {
+		pattern: "/block/best/pos",
+		v1:      apitypes.NewStakeInfoExtended(),
+		want: []string{"stakediff_dcr", "feeinfo.min_dcr_per_kb",
+			"feeinfo.max_dcr_per_kb", "feeinfo.mean_dcr_per_kb",
+			"feeinfo.median_dcr_per_kb", "feeinfo.stddev_dcr_per_kb",
+			"ticket_pool.value_dcr", "ticket_pool.valavg_dcr"},
+	}, {
+		pattern: "/supply",
+		v1:      &apitypes.CoinSupply{},
+		want:    []string{"supply_mined_atoms", "supply_ultimate_atoms"},
+	}, {
+		pattern: "/supply/schedule",
+		v1: &apitypes.SupplySchedule{
+			Ranges: []apitypes.SubsidyRangeSplit{{ProjectedSupply: 1}},
+		},
+		want: []string{"supply_circulating_atoms", "supply_ultimate_atoms",
+			"ranges.pow_atoms", "ranges.pos_atoms", "ranges.treasury_atoms",
+			"ranges.max_supply_atoms", "ranges.projected_supply_atoms"},
+	}, {
+		pattern: "/utxoset/stats",
+		v1: &apitypes.UTXOSetStats{
+			ScriptTypes: []apitypes.UTXOScriptTypeStats{{}},
+		},
+		want: []string{"total_value_dcr", "script_types.value_dcr"},
+	}, {
+		pattern: "/chaininfo",
+		v1:      &apitypes.ChainInfo{},
+		want:    []string{"stake.min_stake_diff_atoms", "subsidy.base_atoms"},
+	}, {
+		pattern: "/block/side/{blockhash}",
+		v1: &apitypes.SideChainBlock{
+			Tx:  []apitypes.SideChainTx{{}},
+			STx: []apitypes.SideChainTx{{}},
+		},
+		want: []string{"tx.fee_dcr", "tx.total_out_dcr", "stx.fee_dcr",
+			"stx.total_out_dcr"},
+	}, {
+		pattern: "/stake/pool",
+		v1:      &apitypes.TicketPoolInfo{},
+		want:    []string{"value_dcr", "valavg_dcr"},
+	}, {
+		pattern: "/stake/diff",
+		v1: &apitypes.StakeDiff{
+			Estimates: chainjson.EstimateStakeDiffResult{User: &one},
+		},
+		want: []string{"current_dcr", "next_dcr", "estimates.min_dcr",
+			"estimates.max_dcr", "estimates.expected_dcr", "estimates.user_dcr"},
+	}, {
+		pattern: "/stake/diff/current",
+		v1:      &chainjson.GetStakeDifficultyResult{},
+		want:    []string{"current_dcr", "next_dcr"},
+	}, {
+		pattern: "/stake/diff/estimates",
+		v1:      &chainjson.EstimateStakeDiffResult{User: &one},
+		want:    []string{"min_dcr", "max_dcr", "expected_dcr", "user_dcr"},
+	}, {
+		pattern: "/stake/diff/prediction",
+		v1:      &apitypes.StakeDiffPrediction{},
+		want: []string{"current_dcr", "estimate.min_dcr", "estimate.max_dcr",
+			"estimate.expected_dcr", "with_mempool.min_dcr",
+			"with_mempool.max_dcr", "with_mempool.expected_dcr"},
+	}, {
+		pattern: "/stake/powerless",
+		v1: &apitypes.PowerlessTickets{
+			Unspent: []apitypes.PowerlessTicket{{}},
+			Revoked: []apitypes.PowerlessTicket{{}},
+		},
+		want: []string{"unspent.price_dcr", "revoked.price_dcr"},
+	}, {
+		pattern: "/stake/roi",
+		v1:      &apitypes.StakeROI{Cycles: []*apitypes.StakeROICycle{{}}},
+		want: []string{"amount_dcr", "final_amount_dcr", "reward_dcr",
+			"cycles.ticket_price_dcr", "cycles.vote_reward_dcr", "cycles.balance_dcr"},
+	}, {
+		pattern: "/tx/{txid}",
+		v1: &apitypes.Tx{
+			TxShort: apitypes.TxShort{
+				Vin:  []chainjson.Vin{{}},
+				Vout: []apitypes.Vout{{}},
+			},
+		},
+		want: []string{"vin.amountin_dcr", "vout.value_dcr"},
+	}, {
+		pattern: "/tx/{txid}/out",
+		v1:      []*apitypes.TxOut{{}},
+		want:    []string{"value_dcr"},
+	}, {
+		pattern: "/tx/{txid}/in",
+		v1:      []*apitypes.TxIn{{}},
+		want:    []string{"value_dcr"},
+	}, {
+		pattern: "/tx/{txid}/swaps",
+		v1:      []*apitypes.AtomicSwap{{}},
+		want:    []string{"value_dcr"},
+	}, {
+		pattern: "/tx/{txid}/firstseen",
+		v1:      &apitypes.MempoolTxHistory{},
+		want:    []string{"fees_dcr", "fee_rate_dcr_per_kb"},
+	}, {
+		pattern: "/tx/{txid}/graph",
+		v1: &apitypes.TxGraph{
+			Nodes: []*apitypes.TxGraphNode{{}},
+			Edges: []*apitypes.TxGraphEdge{{}},
+		},
+		want: []string{"nodes.value_dcr", "nodes.fees_dcr", "edges.value_dcr"},
+	}, {
+		pattern: "/outputs/nonstandard",
+		v1: &apitypes.NonstandardOutputs{
+			Outputs: []*dbtypes.NonstandardOutput{{}},
+		},
+		want: []string{"outputs.value_atoms"},
+	}, {
+		pattern: "/treasury/balance",
+		v1:      &apitypes.TreasuryBalance{},
+		want:    []string{"balance_dcr"},
+	}, {
+		pattern: "/treasury/balance/{chartgrouping}",
+		v1:      &apitypes.TreasuryBalanceHistory{},
+		want:    []string{"added_dcr", "spent_dcr", "balance_dcr"},
+	}, {
+		pattern: "/treasury/tspend/{txid}",
+		v1:      &apitypes.TSpend{Payouts: []apitypes.TSpendPayout{{}}},
+		want:    []string{"amount_dcr", "fee_dcr", "payouts.amount_dcr"},
+	}, {
+		pattern: "/address/{address}/totals",
+		v1:      &apitypes.AddressTotals{},
+		want:    []string{"spent_dcr", "unspent_dcr"},
+	}, {
+		pattern: "/address/{address}/cluster",
+		v1:      &apitypes.AddressCluster{},
+		want:    []string{"balance_dcr"},
+	}, {
+		pattern: "/address/{address}/balance/{idx}",
+		v1:      &apitypes.AddressBalanceAtHeight{},
+		want:    []string{"balance_dcr", "balance_atoms"},
+	}, {
+		pattern: "/address/{address}/txs",
+		v1: &apitypes.Address{
+			Transactions: []*apitypes.AddressTxShort{{}},
+		},
+		want: []string{"address_transactions.value_dcr"},
+	}, {
+		pattern: "/address/{address}/txs/raw",
+		v1: []*apitypes.AddressTxRaw{{
+			Vin: []chainjson.VinPrevOut{{
+				AmountIn: &one,
+				PrevOut:  &chainjson.PrevOut{},
+			}},
+			Vout: []apitypes.Vout{{}},
+		}},
+		want: []string{"vin.amountin_dcr", "vin.prevOut.value_dcr", "vout.value_dcr"},
+	}, {
+		pattern: "/address/{address}/amountflow/{chartgrouping}",
+		v1: &dbtypes.ChartsData{
+			Received: []float64{1},
+			Sent:     []float64{1},
+			Net:      []float64{0},
+		},
+		want: []string{"received_dcr", "sent_dcr", "net_dcr"},
+	}, {
+		pattern: "/address/{address}/io/json",
+		v1:      &apitypes.AddressTxIOPage{Entries: []*apitypes.AddressTxIO{{}}},
+		want:    []string{"entries.amount_dcr", "entries.balance_dcr"},
+	}, {
+		pattern: "/addresses/rich",
+		v1: &apitypes.RichList{
+			Addresses:    []apitypes.RichListAddress{{}},
+			Distribution: []apitypes.BalanceRange{{}},
+		},
+		want: []string{"addresses.balance_dcr", "distribution.min_balance_dcr",
+			"distribution.total_balance_dcr"},
+	}, {
+		pattern: "/mempool/feerates",
+		v1: &apitypes.MempoolFeeRates{
+			Percentiles: []apitypes.FeeRatePercentile{{}},
+			Estimates:   []apitypes.FeeRateEstimate{{}},
+		},
+		want: []string{"percentiles.fee_rate_dcr_per_kb", "estimates.fee_rate_dcr_per_kb"},
+	}, {
+		pattern: "/mempool/history/{chartgrouping}",
+		v1:      &apitypes.MempoolCongestion{},
+		want:    []string{"median_fee_rate_dcr_per_kb"},
+	}, {
+		pattern: "/mempool/sstx",
+		v1:      &apitypes.MempoolTicketFeeInfo{},
+		want: []string{"min_dcr_per_kb", "max_dcr_per_kb", "mean_dcr_per_kb",
+			"median_dcr_per_kb", "stddev_dcr_per_kb", "lowest_mineable_dcr_per_kb"},
+	}, {
+		pattern: "/mempool/sstx/fees",
+		v1:      &apitypes.MempoolTicketFees{},
+		want:    []string{"top_fees_dcr_per_kb"},
+	}, {
+		pattern: "/mempool/sstx/details",
+		v1: &apitypes.MempoolTicketDetails{
+			Tickets: apitypes.TicketsDetails{{}},
+		},
+		want: []string{"tickets.abs_fee_dcr", "tickets.fee_rate_dcr_per_kb"},
+	}, {
+		pattern: "/chart/coin-age/cdd/{chartgrouping}",
+		v1:      &apitypes.CoinDaysDestroyed{},
+		want:    []string{"spent_dcr"},
+	}, {
+		pattern: "/chart/coin-age/bands",
+		v1:      &apitypes.CoinAgeBands{},
+		want:    []string{"values_dcr"},
+	}, {
+		pattern: "/chart/script-types",
+		v1:      &apitypes.ScriptTypeCounts{},
+		want:    []string{"values_dcr"},
+	}, {
+		pattern: "/chart/script-types/{chartgrouping}",
+		v1:      &apitypes.ScriptTypeCounts{},
+		want:    []string{"values_dcr"},
+	}, {
+		pattern: "/ticket/{txid}/lifecycle",
+		v1:      &apitypes.TicketLifecycle{},
+		want:    []string{"price_dcr", "fee_dcr", "reward_dcr"},
+	}, {
+		pattern: "/ticketpool",
+		v1: struct {
+			Height    int64                    `json:"height"`
+			TimeChart *dbtypes.PoolTicketsData `json:"time_chart"`
+		}{0, poolChart},
+		want: []string{"time_chart.price_dcr"},
+	}, {
+		pattern: "/ticketpool/charts",
+		v1: &apitypes.TicketPoolChartsData{
+			TimeChart:  poolChart,
+			PriceChart: poolChart,
+			Mempool:    &apitypes.PriceCountTime{},
+		},
+		want: []string{"time_chart.price_dcr", "price_chart.price_dcr",
+			"mempool.price_dcr"},
+	}, {
+		pattern: "/proposal/{token}/spending",
+		v1: &apitypes.ProposalSpending{
+			Invoices: []apitypes.ProposalInvoice{{
+				Payments: []apitypes.ProposalPayment{{}},
+			}},
+		},
+		want: []string{"invoices.paid_dcr", "invoices.payments.amount_dcr"},
+	}, {
+		pattern: "/market/{token}/candles",
+		v1:      []*dbtypes.MarketCandle{{}},
+		want: []string{"open_btc", "high_btc", "low_btc", "close_btc",
+			"volume_dcr"},
+	}}
+
+	tested := make(map[uintptr]bool)
+	for _, test := range tests {
+		rt := findV2Route(test.pattern)
+		if rt == nil {
+			t.Errorf("%s: no v2 route", test.pattern)
+			continue
+		}
+		tested[reflect.ValueOf(rt.renames).Pointer()] = true
+
+		v1 := decodeV1(t, test.v1)
+		for path := range rt.renames {
+			if !fieldAt(v1, path) {
+				t.Errorf("%s: v1 response has no field %s", test.pattern, path)
+			}
+		}
+
+		v2 := renameFields(decodeV1(t, test.v1), "", rt.renames)
+		if len(test.want) != len(rt.renames) {
+			t.Errorf("%s: %d renames, expected %d", test.pattern, len(rt.renames), len(test.want))
+		}
+		for _, path := range test.want {
+			if !fieldAt(v2, path) {
+				t.Errorf("%s: v2 response has no field %s", test.pattern, path)
+			}
+		}
+		for path := range rt.renames {
+			if fieldAt(v2, path) {
+				t.Errorf("%s: v1 field %s was not renamed", test.pattern, path)
+			}
+		}
+	}
+
+	// Every route that renames fields shares its renames with a tested route.
+	for _, rt := range v2Routes {
+		if rt.renames != nil && !tested[reflect.ValueOf(rt.renames).Pointer()] {
+			t.Errorf("%s: renames not tested", rt.pattern)
+		}
+	}
+}
+
+func TestV2RenameUnits(t *testing.T) {
+	units := []string{"_atoms", "_dcr", "_dcr_per_kb", "_btc"}
+	hasUnit := func(name string) bool {
+		for _, unit := range units {
+			if strings.HasSuffix(name, unit) {
+				return true
+			}
+		}
+		return false
+	}
+
+	patterns := make(map[string]bool, len(v2Routes))
+	for _, rt := range v2Routes {
+		if patterns[rt.pattern] {
+			t.Errorf("duplicate v2 route %s", rt.pattern)
+		}
+		patterns[rt.pattern] = true
+
+		for path, name := range rt.renames {
+			if !hasUnit(name) {
+				t.Errorf("%s: %s renamed to %s, which has no unit", rt.pattern, path, name)
+			}
+		}
+		if rt.wrap != "" && !hasUnit(rt.wrap) {
+			t.Errorf("%s: wrapped in %s, which has no unit", rt.pattern, rt.wrap)
+		}
+		if rt.list != "" && (rt.defLimit <= 0 || rt.maxLimit < rt.defLimit) {
+			t.Errorf("%s: invalid page limits %d and %d", rt.pattern, rt.defLimit, rt.maxLimit)
+		}
+	}
+}
+
+func TestV2PageQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantOffset int
+		wantLimit  int
+		wantErr    bool
+	}{
+		{"", 0, 10, false},
+		{"offset=20&limit=5", 20, 5, false},
+		{"limit=500", 0, 100, false},
+		{"offset=-1", 0, 0, true},
+		{"offset=x", 0, 0, true},
+		{"limit=0", 0, 0, true},
+		{"limit=ten", 0, 0, true},
+	}
+	for _, test := range tests {
+		q, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset, limit, err := v2PageQuery(q, 10, 100)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%q: expected error %v, got %v", test.query, test.wantErr, err)
+			continue
+		}
+		if offset != test.wantOffset || limit != test.wantLimit {
+			t.Errorf("%q: expected offset %d and limit %d, got %d and %d", test.query,
+				test.wantOffset, test.wantLimit, offset, limit)
+		}
+	}
+}
+
+func TestV2Successor(t *testing.T) {
+	tests := []struct {
+		v1, want string
+	}{
+		{"/block/best", "/block/best"},
+		{"/block/best/", "/block/best"},
+		{"/block/123/verbose", "/block/123/verbose"},
+		{"/block/hash/abc/pos", "/block/hash/abc/pos"},
+		{"/block/range/1/9/2/size", "/block/range/1/9/2/size"},
+		{"/tx/decoded/abc", "/tx/decoded/abc"},
+		{"/tx/abc/out/1", "/tx/abc/out/1"},
+		{"/address/Dsaddr", "/address/Dsaddr/txs"},
+		{"/address/Dsaddr/count/5", "/address/Dsaddr/txs"},
+		{"/address/Dsaddr/count/5/skip/2", "/address/Dsaddr/txs"},
+		{"/address/Dsaddr/raw", "/address/Dsaddr/txs/raw"},
+		{"/address/Dsaddr/count/5/skip/2/raw", "/address/Dsaddr/txs/raw"},
+		{"/address/Dsaddr/io/json", "/address/Dsaddr/io/json"},
+		{"/chain/reorgs/count/5", "/chain/reorgs"},
+		{"/outputs/nonstandard/count/5/skip/10", "/outputs/nonstandard"},
+		{"/swaps/recent", "/swaps/recent"},
+		{"/stake/diff/b/5", "/stake/diff/b/5"},
+		// The routes only in v1.
+		{"/", ""},
+		{"/supply/circulating", ""},
+		{"/block/best/height", ""},
+		{"/block/hash/abc/height", ""},
+		{"/block/123/hash", ""},
+		{"/tx/abc/raw", ""},
+		{"/tx/hex/abc", ""},
+		{"/address/Dsaddr/io/csv", ""},
+		{"/address/io/Dsaddr", ""},
+		{"/chart/ticket-price", ""},
+		{"/chart/market/binance/depth", ""},
+		{"/exchanges/depth", ""},
+		{"/mempool", ""},
+		{"/txs/decode", ""},
+		{"/watch/5", ""},
+		{"/faucet", ""},
+		{"/list", ""},
+	}
+	for _, test := range tests {
+		if got := v2Successor(test.v1); got != test.want {
+			t.Errorf("%s: expected successor %q, got %q", test.v1, test.want, got)
+		}
+	}
+}
+
+func TestV2Router(t *testing.T) {
+	// The v1 router responds with fixed v1 responses, and records the path
+	// and queries of the v1 requests.
+	var v1URI string
+	v1 := chi.NewRouter()
+	v1.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v1URI = r.URL.RequestURI()
+			next.ServeHTTP(w, r)
+		})
+	})
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}
+	}
+	v1.Get("/block/best", respond(`{"height":5,"sdiff":1.5,"fees":12000,`+
+		`"ticket_pool":{"size":3,"value":30,"valavg":10}}`))
+	v1.Get("/address/{address}/count/{N}/skip/{M}", respond(`{"address":"Dsaddr",`+
+		`"address_transactions":[{"txid":"aa","value":1.5}]}`))
+	v1.Get("/chain/reorgs/count/{N}/skip/{M}", respond(`{"total":12,"reorgs":[{"depth":1}]}`))
+	v1.Get("/swaps/recent", respond(`[{"spend_txid":"bb","value":5}]`))
+	v1.Get("/stake/diff/b/{idx}", respond(`[2.5]`))
+	v1.Get("/search", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"query": r.URL.Query().Get("q")}, "")
+	})
+	v1.Get("/tx/{txid}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unable to get transaction", http.StatusNotFound)
+	})
+	v2 := NewAPIV2Router(apiMux{v1}, "")
+
+	tests := []struct {
+		name       string
+		path       string
+		wantV1     string
+		wantStatus int
+		wantBody   string
+	}{{
+		name:       "renames",
+		path:       "/block/best",
+		wantV1:     "/block/best",
+		wantStatus: http.StatusOK,
+		wantBody: `{"height":5,"sdiff_dcr":1.5,"fees_atoms":12000,` +
+			`"ticket_pool":{"size":3,"value_dcr":30,"valavg_dcr":10}}`,
+	}, {
+		name:       "page by path",
+		path:       "/address/Dsaddr/txs?offset=4&limit=2",
+		wantV1:     "/address/Dsaddr/count/2/skip/4",
+		wantStatus: http.StatusOK,
+		wantBody: `{"address":"Dsaddr","data":[{"txid":"aa","value_dcr":1.5}],` +
+			`"pagination":{"offset":4,"limit":2,"count":1}}`,
+	}, {
+		name:       "default page with total",
+		path:       "/chain/reorgs",
+		wantV1:     "/chain/reorgs/count/100/skip/0",
+		wantStatus: http.StatusOK,
+		wantBody: `{"data":[{"depth":1}],` +
+			`"pagination":{"offset":0,"limit":100,"count":1,"total":12}}`,
+	}, {
+		name:       "page by queries",
+		path:       "/swaps/recent?offset=1&limit=5000",
+		wantV1:     "/swaps/recent?n=500&skip=1",
+		wantStatus: http.StatusOK,
+		wantBody: `{"data":[{"spend_txid":"bb","value_dcr":5}],` +
+			`"pagination":{"offset":1,"limit":500,"count":1}}`,
+	}, {
+		name:       "invalid page",
+		path:       "/chain/reorgs?limit=x",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "wrapped array",
+		path:       "/stake/diff/b/7",
+		wantV1:     "/stake/diff/b/7",
+		wantStatus: http.StatusOK,
+		wantBody:   `{"sdiff_dcr":[2.5]}`,
+	}, {
+		name:       "queries",
+		path:       "/search?q=abc",
+		wantV1:     "/search?q=abc",
+		wantStatus: http.StatusOK,
+		wantBody:   `{"query":"abc"}`,
+	}, {
+		name:       "v1 error",
+		path:       "/tx/aa",
+		wantV1:     "/tx/aa",
+		wantStatus: http.StatusNotFound,
+	}}
+
+	for _, test := range tests {
+		v1URI = ""
+		rr := httptest.NewRecorder()
+		v2.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if v1URI != test.wantV1 {
+			t.Errorf("%s: expected v1 request %q, got %q", test.name, test.wantV1, v1URI)
+		}
+		if rr.Code != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, rr.Code)
+			continue
+		}
+		if test.wantBody == "" {
+			continue
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid JSON %s: %v", test.name, rr.Body.String(), err)
+			continue
+		}
+		if err := json.Unmarshal([]byte(test.wantBody), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %s, got %s", test.name, test.wantBody, rr.Body.String())
+		}
+	}
+}
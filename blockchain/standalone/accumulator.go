@@ -0,0 +1,119 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"math"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// Accumulator incrementally calculates a merkle root as leaves are added
+// without retaining all of the leaves.  It only stores the roots of the
+// perfect subtrees (peaks) formed by the leaves added so far, so the memory
+// required is logarithmic in the number of leaves, and adding a leaf requires
+// at most log2(n) hashes.
+//
+// The merkle root it produces is identical to that of CalcMerkleRoot for the
+// same leaves, including the convention of duplicating the final node of a
+// level with an odd number of nodes.
+//
+// An Accumulator is append-only.  It is not safe for concurrent writes, so
+// callers must synchronize calls to Add with calls to any other methods.
+//
+// The zero value is an empty Accumulator that is ready to use.
+type Accumulator struct {
+	// numLeaves is the number of leaves added so far.  The set bits indicate
+	// which of the peaks are populated, where peaks[i] is the root of a
+	// perfect subtree with 2^i leaves.
+	numLeaves uint32
+	peaks     [32]chainhash.Hash
+}
+
+// NewAccumulator returns a new empty Accumulator.
+func NewAccumulator() *Accumulator {
+	return &Accumulator{}
+}
+
+// hashBranches returns the hash of the concatenation of the left and right
+// branches.
+func hashBranches(left, right *chainhash.Hash) chainhash.Hash {
+	var buf [2 * chainhash.HashSize]byte
+	copy(buf[:chainhash.HashSize], left[:])
+	copy(buf[chainhash.HashSize:], right[:])
+	return chainhash.HashH(buf[:])
+}
+
+// Add adds the provided leaf to the accumulator.
+//
+// This works in the same manner as incrementing a binary counter.  The new
+// leaf is merged with the peak at each level for which a perfect subtree
+// already exists, carrying the combined hash upward until it reaches a level
+// with no peak, where it is stored.
+//
+// Since leaf indices are uint32, at most math.MaxUint32 leaves may be added.
+// Attempting to add more will panic.
+func (a *Accumulator) Add(leaf chainhash.Hash) {
+	if a.numLeaves == math.MaxUint32 {
+		panic("accumulator leaf count overflow")
+	}
+
+	node := leaf
+	level := uint8(0)
+	for a.numLeaves&(1<<level) != 0 {
+		node = hashBranches(&a.peaks[level], &node)
+		level++
+	}
+	a.peaks[level] = node
+	a.numLeaves++
+}
+
+// LeafCount returns the number of leaves added to the accumulator.
+func (a *Accumulator) LeafCount() uint32 {
+	return a.numLeaves
+}
+
+// Root returns the merkle root of all of the leaves added so far.  An all zero
+// hash is returned when no leaves have been added, consistent with
+// CalcMerkleRoot.
+//
+// The root is calculated by sweeping up the right edge of the tree, starting
+// from the smallest peak.  At each level where the right edge has no sibling,
+// the node is concatenated with itself per the odd node duplication
+// convention, and otherwise it is combined with the peak to its left.
+func (a *Accumulator) Root() chainhash.Hash {
+	n := a.numLeaves
+	if n == 0 {
+		// All zero.
+		return chainhash.Hash{}
+	}
+
+	// Start with the smallest peak.
+	level := uint8(0)
+	for n&(1<<level) == 0 {
+		level++
+	}
+	root := a.peaks[level]
+
+	// Continue until the count is a power of two at the current level, which
+	// means the node is the root.  The count is widened to avoid overflow when
+	// padding a full uint32 count.
+	count := uint64(n)
+	for count != uint64(1)<<level {
+		// There is no right sibling at this level, so duplicate the node and
+		// account for the padding in the count.
+		root = hashBranches(&root, &root)
+		count += uint64(1) << level
+		level++
+
+		// Combine with the peaks to the left until the next level that
+		// requires duplication.
+		for count&(uint64(1)<<level) == 0 {
+			root = hashBranches(&a.peaks[level], &root)
+			level++
+		}
+	}
+	return root
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// randomLeaves returns the specified number of pseudo-random leaves using the
+// provided source.
+func randomLeaves(rng *rand.Rand, numLeaves int) []chainhash.Hash {
+	leaves := make([]chainhash.Hash, numLeaves)
+	for i := range leaves {
+		rng.Read(leaves[i][:])
+	}
+	return leaves
+}
+
+// TestAccumulator ensures the merkle root calculated by the accumulator matches
+// the one calculated by CalcMerkleRoot after each leaf is added.
+func TestAccumulator(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	leaves := randomLeaves(rng, 300)
+
+	acc := NewAccumulator()
+	if root := acc.Root(); root != (chainhash.Hash{}) {
+		t.Fatalf("unexpected root for empty accumulator -- got %v, want "+
+			"all zero", root)
+	}
+
+	for i := range leaves {
+		acc.Add(leaves[i])
+		if acc.LeafCount() != uint32(i+1) {
+			t.Fatalf("unexpected leaf count -- got %d, want %d",
+				acc.LeafCount(), i+1)
+		}
+
+		want := CalcMerkleRoot(leaves[:i+1])
+		if root := acc.Root(); root != want {
+			t.Fatalf("%d leaves: unexpected root -- got %v, want %v", i+1,
+				root, want)
+		}
+	}
+}
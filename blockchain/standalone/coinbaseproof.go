@@ -0,0 +1,39 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// coinbaseLeafIndex is the leaf index of the coinbase transaction, which is
+// always the first transaction of the regular transaction tree.
+const coinbaseLeafIndex = 0
+
+// GenerateCoinbaseProof treats the provided slice of hashes as the leaves of
+// the regular transaction tree of a block, where the first leaf is the
+// coinbase transaction, and generates and returns a merkle tree inclusion proof
+// for the coinbase transaction.  This is a convenience for SPV clients that
+// commonly need to prove the coinbase in order to extract the data it embeds,
+// since it fixes the leaf index so that it can't accidentally be mistaken.  See
+// GenerateInclusionProof for details about the proof.
+//
+// Nil is returned when there are no leaves.  Note that a block with only a
+// coinbase transaction also results in an empty proof, since the coinbase
+// hash is the merkle root.
+func GenerateCoinbaseProof(leaves []chainhash.Hash) []chainhash.Hash {
+	if len(leaves) == 0 {
+		return nil
+	}
+	return GenerateInclusionProof(leaves, coinbaseLeafIndex)
+}
+
+// VerifyCoinbaseProof returns whether or not the given coinbase transaction
+// hash and inclusion proof result in recalculating a merkle root that matches
+// the provided merkle root of the regular transaction tree.  See
+// GenerateCoinbaseProof for details.
+func VerifyCoinbaseProof(root, coinbaseHash *chainhash.Hash, proof []chainhash.Hash) bool {
+	return VerifyInclusionProof(root, coinbaseHash, coinbaseLeafIndex, proof)
+}
@@ -27,6 +27,7 @@ The provided functions fall into the following categories:
  - Merkle root calculation
    - Calculation from individual leaf hashes
    - Calculation from a slice of transactions
+   - Incremental calculation as leaves are added via an Accumulator
  - Subsidy calculation
    - Proof-of-work subsidy for a given height and number of votes
    - Stake vote subsidy for a given height
@@ -34,7 +35,12 @@ The provided functions fall into the following categories:
  - Coinbase transaction identification
  - Merkle tree inclusion proofs
    - Generate an inclusion proof for a given tree and leaf index
+   - Distinguish an empty tree and out of range index from an empty proof
    - Verify a leaf is a member of the tree at a given index via the proof
+   - Calculate the root implied by a proof and match it against candidate roots
+   - Generate and verify a proof for a contiguous range of leaves
+   - Generate and verify proofs for the regular or stake transaction tree
+   - Generate and verify a proof for the coinbase transaction
 
 Errors
 
@@ -43,5 +49,10 @@ the caller to differentiate between errors further up the call stack through
 type assertions.  In addition, callers can programmatically determine the
 specific rule violation by examining the ErrorCode field of the type asserted
 standalone.RuleError.
+
+The exceptions are the inclusion proof functions, which are not subject to
+consensus rules.  GenerateInclusionProofErr returns the ErrNoLeaves and
+ErrIndexOutOfRange sentinel errors, and GenerateTxTreeInclusionProof
+additionally returns ErrUnknownTxTree, all of which may be compared directly.
 */
 package standalone
@@ -5,9 +5,21 @@
 package standalone
 
 import (
+	"errors"
+
 	"github.com/decred/dcrd/chaincfg/chainhash"
 )
 
+var (
+	// ErrNoLeaves is returned by GenerateInclusionProofErr when no leaves are
+	// provided, and thus there is no tree to prove inclusion in.
+	ErrNoLeaves = errors.New("no leaves provided")
+
+	// ErrIndexOutOfRange is returned by GenerateInclusionProofErr when the
+	// leaf index is not less than the number of leaves.
+	ErrIndexOutOfRange = errors.New("leaf index out of range")
+)
+
 // log2FloorMasks defines the masks to use when quickly calculating
 // floor(log2(x)) in a constant log2(32) = 5 steps, where x is a uint32, using
 // shifts.  They are derived from (2^(2^x) - 1) * (2^(2^x)), for x in 4..0.
@@ -57,14 +69,33 @@ func fastLog2Ceil(n uint32) uint8 {
 // other hand, if the goal were to prove inclusion of h2 at the 0-based leaf
 // index of 1, the proof would consist of the sibling hashes h1 and h34.
 //
-// Specifying a leaf index that is out of range will return nil.
+// Specifying a leaf index that is out of range will return nil.  Use
+// GenerateInclusionProofErr to distinguish that case from the empty proof of a
+// tree with a single leaf.
 func GenerateInclusionProof(leaves []chainhash.Hash, leafIndex uint32) []chainhash.Hash {
+	proof, _ := GenerateInclusionProofErr(leaves, leafIndex)
+	return proof
+}
+
+// GenerateInclusionProofErr is identical to GenerateInclusionProof except that
+// it returns ErrNoLeaves when there are no leaves and ErrIndexOutOfRange when
+// the leaf index is out of range, rather than a nil proof.
+//
+// A tree with a single leaf results in an empty, non-nil proof, since the
+// single leaf is the merkle root and no sibling hashes are needed to prove its
+// inclusion.
+func GenerateInclusionProofErr(leaves []chainhash.Hash, leafIndex uint32) ([]chainhash.Hash, error) {
 	if len(leaves) == 0 {
-		return nil
+		return nil, ErrNoLeaves
 	}
 
 	if leafIndex >= uint32(len(leaves)) {
-		return nil
+		return nil, ErrIndexOutOfRange
+	}
+
+	// The single leaf is the root, so the proof is empty.
+	if len(leaves) == 1 {
+		return []chainhash.Hash{}, nil
 	}
 
 	// Copy the leaves so they can be safely mutated by the in-place merkle root
@@ -135,7 +166,7 @@ func GenerateInclusionProof(leaves []chainhash.Hash, leafIndex uint32) []chainha
 		leafIndex = halfLeafIndex
 	}
 
-	return proof
+	return proof, nil
 }
 
 // VerifyInclusionProof returns whether or not the given leaf hash, original
@@ -160,9 +191,28 @@ func GenerateInclusionProof(leaves []chainhash.Hash, leafIndex uint32) []chainha
 // The verification will succeed if the root of the new partial merkle tree,
 // "h1234", matches the provided root hash "h1234o".
 func VerifyInclusionProof(root, leaf *chainhash.Hash, leafIndex uint32, proof []chainhash.Hash) bool {
-	// Create a buffer to reuse for hashing the branches and some long lived
-	// slices into it to avoid reslicing.
+	calcRoot, ok := CalcInclusionProofRoot(leaf, leafIndex, proof)
+	return ok && *root == calcRoot
+}
+
+// CalcInclusionProofRoot returns the merkle root implied by the given leaf
+// hash, original leaf index, and inclusion proof.  See GenerateInclusionProof
+// for details about the proof.
+//
+// The returned boolean is false when the leaf index is not possible for the
+// length of the proof, in which case no root is implied.  This is the basis of
+// VerifyInclusionProof and MatchInclusionProofRoot, and is useful for callers
+// that need to compare the implied root against a root obtained separately.
+func CalcInclusionProofRoot(leaf *chainhash.Hash, leafIndex uint32, proof []chainhash.Hash) (chainhash.Hash, bool) {
 	var buf [2 * chainhash.HashSize]byte
+	return calcInclusionProofRoot(&buf, leaf, leafIndex, proof)
+}
+
+// calcInclusionProofRoot is CalcInclusionProofRoot with a caller-provided
+// buffer for hashing the branches, which allows the buffer to be shared when
+// calculating the roots of many proofs.
+func calcInclusionProofRoot(buf *[2 * chainhash.HashSize]byte, leaf *chainhash.Hash, leafIndex uint32, proof []chainhash.Hash) (chainhash.Hash, bool) {
+	// Create some long lived slices into the buffer to avoid reslicing.
 	var left = buf[:chainhash.HashSize]
 	var right = buf[chainhash.HashSize:]
 	var both = buf[:]
@@ -174,18 +224,17 @@ func VerifyInclusionProof(root, leaf *chainhash.Hash, leafIndex uint32, proof []
 	// it follows that the max possible 0-based leaf index is 2^len(proof) - 1.
 	proofLen := len(proof)
 	if proofLen > 32 {
-		return false
+		return chainhash.Hash{}, false
 	}
 	maxLeafIndex := uint32(uint64(1)<<uint8(proofLen) - 1)
 	if leafIndex > maxLeafIndex {
-		return false
+		return chainhash.Hash{}, false
 	}
 
 	// The following algorithm works by treating each entry in the proof as the
 	// sibling value opposite the known value for each level of the merkle tree
 	// and hashing it along with the known value while accounting for whether
-	// the known value is in the left or right branch at that level and finally
-	// comparing the calculated root to the provided root.
+	// the known value is in the left or right branch at that level.
 	intermediate := *leaf
 	for _, proof := range proof {
 		// The sibling hash needed to prove inclusion for the given leaf is on
@@ -203,5 +252,57 @@ func VerifyInclusionProof(root, leaf *chainhash.Hash, leafIndex uint32, proof []
 		leafIndex >>= 1
 	}
 
-	return *root == intermediate
+	return intermediate, true
+}
+
+// MatchInclusionProofRoot returns the index of the first of the provided
+// candidate merkle roots that the given leaf hash, original leaf index, and
+// inclusion proof result in, along with true.  False is returned when none of
+// the roots match.
+//
+// This is useful when there are multiple plausible roots, such as those of the
+// competing block headers at a chain reorganization boundary, since the root
+// implied by the proof is only calculated once rather than once per candidate
+// as would be the case when calling VerifyInclusionProof for each root.
+func MatchInclusionProofRoot(leaf *chainhash.Hash, leafIndex uint32, proof []chainhash.Hash, roots []chainhash.Hash) (int, bool) {
+	calcRoot, ok := CalcInclusionProofRoot(leaf, leafIndex, proof)
+	if !ok {
+		return -1, false
+	}
+	for i := range roots {
+		if roots[i] == calcRoot {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// InclusionProof is a leaf hash, its original leaf index, and the merkle tree
+// inclusion proof for it.  See GenerateInclusionProof for details about the
+// proof.
+type InclusionProof struct {
+	Leaf      chainhash.Hash
+	LeafIndex uint32
+	Proof     []chainhash.Hash
+}
+
+// VerifyInclusionProofs returns whether or not all of the given inclusion
+// proofs result in recalculating the provided merkle root, as would be
+// determined by calling VerifyInclusionProof for each of them.  When a proof
+// fails to verify, its index is returned along with false, and the remaining
+// proofs are not checked.  Otherwise, -1 and true are returned.
+//
+// This is useful for validating many proofs against the same tree, such as
+// client-submitted proofs for the transactions of a whole block, since a
+// single hashing buffer is shared by all of the proofs.
+func VerifyInclusionProofs(root *chainhash.Hash, proofs []InclusionProof) (int, bool) {
+	var buf [2 * chainhash.HashSize]byte
+	for i := range proofs {
+		p := &proofs[i]
+		calcRoot, ok := calcInclusionProofRoot(&buf, &p.Leaf, p.LeafIndex, p.Proof)
+		if !ok || calcRoot != *root {
+			return i, false
+		}
+	}
+	return -1, true
 }
@@ -5,6 +5,8 @@
 package standalone
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
@@ -390,3 +392,243 @@ nextTest:
 		}
 	}
 }
+
+// TestGenerateInclusionProofErr ensures GenerateInclusionProofErr returns the
+// expected errors for trees without leaves and leaf indices that are out of
+// range, and an empty, non-nil proof without error for a single leaf.
+func TestGenerateInclusionProofErr(t *testing.T) {
+	leaves := randomLeaves(rand.New(rand.NewSource(1)), 3)
+	tests := []struct {
+		name      string           // test description
+		leaves    []chainhash.Hash // leaves to test
+		leafIndex uint32           // leaf index to test
+		wantLen   int              // expected proof length
+		err       error            // expected error
+	}{{
+		name:      "no leaves",
+		leaves:    nil,
+		leafIndex: 0,
+		err:       ErrNoLeaves,
+	}, {
+		name:      "no leaves, nonzero leaf index",
+		leaves:    []chainhash.Hash{},
+		leafIndex: 5,
+		err:       ErrNoLeaves,
+	}, {
+		name:      "single leaf, leaf index 1 -- out of range",
+		leaves:    leaves[:1],
+		leafIndex: 1,
+		err:       ErrIndexOutOfRange,
+	}, {
+		name:      "3 leaves, max leaf index -- out of range",
+		leaves:    leaves,
+		leafIndex: ^uint32(0),
+		err:       ErrIndexOutOfRange,
+	}, {
+		name:      "single leaf, leaf index 0",
+		leaves:    leaves[:1],
+		leafIndex: 0,
+		wantLen:   0,
+	}, {
+		name:      "3 leaves, leaf index 2",
+		leaves:    leaves,
+		leafIndex: 2,
+		wantLen:   2,
+	}}
+
+	for _, test := range tests {
+		proof, err := GenerateInclusionProofErr(test.leaves, test.leafIndex)
+		if err != test.err {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+			continue
+		}
+		if err != nil {
+			if proof != nil {
+				t.Errorf("%q: unexpected non-nil proof %v", test.name, proof)
+			}
+			continue
+		}
+		if proof == nil {
+			t.Errorf("%q: unexpected nil proof", test.name)
+			continue
+		}
+		if len(proof) != test.wantLen {
+			t.Errorf("%q: unexpected proof length -- got %d, want %d",
+				test.name, len(proof), test.wantLen)
+		}
+	}
+}
+
+// TestInclusionProofRandomized generates inclusion proofs for random leaf
+// counts and indices, both in and out of range, and ensures the proofs for
+// valid indices verify against the merkle root while invalid indices return
+// the expected error.
+func TestInclusionProofRandomized(t *testing.T) {
+	const iterations = 1000
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < iterations; i++ {
+		numLeaves := rng.Intn(300)
+		leaves := randomLeaves(rng, numLeaves)
+
+		// Choose indices past the end of the leaves about a quarter of the
+		// time.
+		leafIndex := uint32(rng.Intn(numLeaves + numLeaves/3 + 1))
+		if rng.Intn(20) == 0 {
+			leafIndex = rng.Uint32()
+		}
+
+		proof, err := GenerateInclusionProofErr(leaves, leafIndex)
+		switch {
+		case numLeaves == 0:
+			if err != ErrNoLeaves {
+				t.Fatalf("%d leaves, index %d: unexpected error -- got %v, "+
+					"want %v", numLeaves, leafIndex, err, ErrNoLeaves)
+			}
+			continue
+		case leafIndex >= uint32(numLeaves):
+			if err != ErrIndexOutOfRange {
+				t.Fatalf("%d leaves, index %d: unexpected error -- got %v, "+
+					"want %v", numLeaves, leafIndex, err, ErrIndexOutOfRange)
+			}
+			continue
+		case err != nil:
+			t.Fatalf("%d leaves, index %d: unexpected error: %v", numLeaves,
+				leafIndex, err)
+		}
+
+		// The compatibility wrapper must return the same proof.
+		if compat := GenerateInclusionProof(leaves, leafIndex); !reflect.DeepEqual(compat, proof) {
+			t.Fatalf("%d leaves, index %d: GenerateInclusionProof mismatch",
+				numLeaves, leafIndex)
+		}
+
+		root := CalcMerkleRoot(leaves)
+		if !VerifyInclusionProof(&root, &leaves[leafIndex], leafIndex, proof) {
+			t.Fatalf("%d leaves, index %d: proof did not verify", numLeaves,
+				leafIndex)
+		}
+
+		// The proof must not verify for a different leaf.
+		bogusLeaf := leaves[leafIndex]
+		bogusLeaf[0] ^= 0x01
+		if VerifyInclusionProof(&root, &bogusLeaf, leafIndex, proof) {
+			t.Fatalf("%d leaves, index %d: proof verified for modified leaf",
+				numLeaves, leafIndex)
+		}
+	}
+}
+
+// TestMatchInclusionProofRoot ensures MatchInclusionProofRoot identifies which
+// of several candidate roots a proof belongs to, and that it agrees with
+// CalcInclusionProofRoot and VerifyInclusionProof.
+func TestMatchInclusionProofRoot(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	leavesA := randomLeaves(rng, 7)
+	leavesB := randomLeaves(rng, 7)
+	rootA, rootB := CalcMerkleRoot(leavesA), CalcMerkleRoot(leavesB)
+	unrelated := randomLeaves(rng, 1)[0]
+
+	for leafIndex := uint32(0); leafIndex < uint32(len(leavesB)); leafIndex++ {
+		leaf := &leavesB[leafIndex]
+		proof := GenerateInclusionProof(leavesB, leafIndex)
+
+		calcRoot, ok := CalcInclusionProofRoot(leaf, leafIndex, proof)
+		if !ok || calcRoot != rootB {
+			t.Fatalf("index %d: unexpected implied root %v (ok %v), want %v",
+				leafIndex, calcRoot, ok, rootB)
+		}
+
+		roots := []chainhash.Hash{unrelated, rootA, rootB}
+		idx, ok := MatchInclusionProofRoot(leaf, leafIndex, proof, roots)
+		if !ok || idx != 2 {
+			t.Fatalf("index %d: unexpected match -- got %d (ok %v), want 2",
+				leafIndex, idx, ok)
+		}
+		for i := range roots {
+			want := i == idx
+			if got := VerifyInclusionProof(&roots[i], leaf, leafIndex, proof); got != want {
+				t.Fatalf("index %d, root %d: VerifyInclusionProof got %v, "+
+					"want %v", leafIndex, i, got, want)
+			}
+		}
+
+		// No match when the proof does not belong to any of the candidates.
+		if idx, ok := MatchInclusionProofRoot(leaf, leafIndex, proof, roots[:2]); ok {
+			t.Fatalf("index %d: unexpected match at index %d", leafIndex, idx)
+		}
+		if _, ok := MatchInclusionProofRoot(leaf, leafIndex, proof, nil); ok {
+			t.Fatalf("index %d: unexpected match without roots", leafIndex)
+		}
+	}
+
+	// A leaf index that is impossible for the proof length implies no root.
+	proof := GenerateInclusionProof(leavesB, 0)
+	if _, ok := CalcInclusionProofRoot(&leavesB[0], 8, proof); ok {
+		t.Fatal("unexpected implied root for out of range leaf index")
+	}
+	if _, ok := MatchInclusionProofRoot(&leavesB[0], 8, proof, []chainhash.Hash{rootB}); ok {
+		t.Fatal("unexpected match for out of range leaf index")
+	}
+}
+
+// TestVerifyInclusionProofs ensures that verifying a batch of inclusion proofs
+// agrees with verifying each of them, and stops at the first invalid proof.
+func TestVerifyInclusionProofs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	leaves := randomLeaves(rng, 13)
+	root := CalcMerkleRoot(leaves)
+
+	proofs := make([]InclusionProof, len(leaves))
+	for i := range leaves {
+		leafIndex := uint32(i)
+		proofs[i] = InclusionProof{
+			Leaf:      leaves[i],
+			LeafIndex: leafIndex,
+			Proof:     GenerateInclusionProof(leaves, leafIndex),
+		}
+		if !VerifyInclusionProof(&root, &leaves[i], leafIndex, proofs[i].Proof) {
+			t.Fatalf("index %d: proof does not verify", i)
+		}
+	}
+
+	if idx, ok := VerifyInclusionProofs(&root, proofs); !ok || idx != -1 {
+		t.Fatalf("unexpected failure at index %d", idx)
+	}
+	if idx, ok := VerifyInclusionProofs(&root, nil); !ok || idx != -1 {
+		t.Fatalf("unexpected failure at index %d without proofs", idx)
+	}
+
+	// The first invalid proof is reported, whether the leaf index is wrong or
+	// impossible for the proof length, or the leaf is wrong.
+	tests := []struct {
+		name   string
+		modify func(p []InclusionProof)
+		want   int
+	}{{
+		name:   "wrong leaf index",
+		modify: func(p []InclusionProof) { p[4].LeafIndex = 5 },
+		want:   4,
+	}, {
+		name:   "out of range leaf index",
+		modify: func(p []InclusionProof) { p[7].LeafIndex = 1 << uint(len(p[7].Proof)) },
+		want:   7,
+	}, {
+		name: "wrong leaf before another invalid proof",
+		modify: func(p []InclusionProof) {
+			p[2].Leaf = randomLeaves(rng, 1)[0]
+			p[9].LeafIndex = 0
+		},
+		want: 2,
+	}}
+	for _, test := range tests {
+		modified := make([]InclusionProof, len(proofs))
+		copy(modified, proofs)
+		test.modify(modified)
+		idx, ok := VerifyInclusionProofs(&root, modified)
+		if ok || idx != test.want {
+			t.Fatalf("%s: got index %d (ok %v), want %d", test.name, idx, ok,
+				test.want)
+		}
+	}
+}
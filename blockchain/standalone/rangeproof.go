@@ -0,0 +1,190 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// GenerateRangeProof treats the provided slice of hashes as leaves of a merkle
+// tree and generates and returns a merkle tree inclusion proof for all of the
+// leaves in the contiguous range [startIndex, endIndex].  The proof can be used
+// to efficiently prove that all of the leaves in the range are members of the
+// tree at their respective positions.
+//
+// Rather than the sibling hashes along the path of a single leaf as described
+// by GenerateInclusionProof, a range proof consists of only the sibling hashes
+// along the left and right boundaries of the subtree covered by the range.
+// At each level of the tree, the proof contains the sibling to the left of the
+// range when the first covered node is a right child, followed by the sibling
+// to the right of the range when the last covered node is a left child.  All
+// other nodes in the range are calculated from the range leaves themselves.
+//
+// For example, consider the following merkle tree:
+//
+//	                root = h(h1234 + h5678)
+//	             /                          \
+//	       h1234                              h5678
+//	      /      \                           /      \
+//	  h12          h34                   h56          h78
+//	 /   \        /   \                 /   \        /   \
+//	h1    h2     h3    h4              h5    h6     h7    h8
+//
+// Further, consider the goal is to prove inclusion of the leaves h2 through h5
+// at 0-based leaf indices 1 through 4.  The proof will consist of the sibling
+// hashes h1 and h6 for the first level, and h78 for the second level.  The
+// remaining nodes, h12, h34, h56, h1234, h5678, and the root, are all
+// calculated from the range leaves and those boundary hashes.
+//
+// An error is returned when there are no leaves, the start index is after the
+// end index, or either index is out of range.
+func GenerateRangeProof(leaves []chainhash.Hash, startIndex, endIndex uint32) ([]chainhash.Hash, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("no leaves provided")
+	}
+	if startIndex > endIndex {
+		return nil, fmt.Errorf("start index %d is after end index %d",
+			startIndex, endIndex)
+	}
+	if endIndex >= uint32(len(leaves)) {
+		return nil, fmt.Errorf("end index %d is out of range for %d leaves",
+			endIndex, len(leaves))
+	}
+
+	// Copy the leaves so they can be safely mutated by the in-place merkle root
+	// calculation.  Note that the backing array is provided with space for one
+	// additional item when the number of leaves is odd as an optimization for
+	// the in-place calculation to avoid the need grow the backing array.
+	allocLen := len(leaves) + len(leaves)&1
+	dupLeaves := make([]chainhash.Hash, len(leaves), allocLen)
+	copy(dupLeaves, leaves)
+	leaves = dupLeaves
+
+	// Create a buffer to reuse for hashing the branches and some long lived
+	// slices into it to avoid reslicing.
+	var buf [2 * chainhash.HashSize]byte
+	var left = buf[:chainhash.HashSize]
+	var right = buf[chainhash.HashSize:]
+	var both = buf[:]
+
+	// This works in the same manner as GenerateInclusionProof, except that the
+	// sibling hashes are stored for the boundaries of the covered range at each
+	// level rather than for the path of a single leaf.  Note that when the
+	// final node of a level is duplicated, the duplicate is stored as the right
+	// boundary sibling when needed, just as with single leaf proofs.
+	var proof []chainhash.Hash
+	for len(leaves) > 1 {
+		// When there is no right child, the parent is generated by hashing the
+		// concatenation of the left child with itself.
+		if len(leaves)&1 != 0 {
+			leaves = append(leaves, leaves[len(leaves)-1])
+		}
+
+		// Store the sibling hashes on the boundaries of the covered range.
+		if startIndex&1 != 0 {
+			proof = append(proof, leaves[startIndex-1])
+		}
+		if endIndex&1 == 0 {
+			proof = append(proof, leaves[endIndex+1])
+		}
+
+		// Set the parent node to the hash of the concatenation of the left and
+		// right children.
+		for i := 0; i < len(leaves)>>1; i++ {
+			copy(left, leaves[i<<1][:])
+			copy(right, leaves[(i<<1)+1][:])
+			leaves[i] = chainhash.HashH(both)
+		}
+		leaves = leaves[:len(leaves)>>1]
+		startIndex >>= 1
+		endIndex >>= 1
+	}
+
+	return proof, nil
+}
+
+// VerifyRangeProof returns whether or not the given contiguous range of leaf
+// hashes, the original leaf index of the first leaf in the range, and range
+// proof result in recalculating a merkle root that matches the provided merkle
+// root.  See GenerateRangeProof for details about the proof.
+//
+// The verification recalculates the subtree covered by the range from the
+// provided range leaves, using the boundary sibling hashes from the proof as
+// needed at each level, and compares the final result to the provided root.
+func VerifyRangeProof(root *chainhash.Hash, rangeLeaves []chainhash.Hash, startIndex uint32, proof []chainhash.Hash) bool {
+	if len(rangeLeaves) == 0 {
+		return false
+	}
+
+	// Ensure the end of the range does not overflow the maximum possible leaf
+	// index.
+	endIndex64 := uint64(startIndex) + uint64(len(rangeLeaves)) - 1
+	if endIndex64 > uint64(^uint32(0)) {
+		return false
+	}
+	endIndex := uint32(endIndex64)
+
+	// Copy the range leaves so they can be safely mutated by the in-place
+	// calculation.  Space is provided for the boundary siblings.
+	nodes := make([]chainhash.Hash, 0, len(rangeLeaves)+2)
+	nodes = append(nodes, rangeLeaves...)
+
+	// Create a buffer to reuse for hashing the branches and some long lived
+	// slices into it to avoid reslicing.
+	var buf [2 * chainhash.HashSize]byte
+	var left = buf[:chainhash.HashSize]
+	var right = buf[chainhash.HashSize:]
+	var both = buf[:]
+
+	// The following algorithm works by extending the known nodes at each level
+	// with the boundary sibling hashes from the proof as needed such that the
+	// nodes consist of complete pairs, hashing each pair to form the known
+	// nodes of the next level.  The root is reached when a single node remains
+	// and the proof is exhausted.  Since the leaf index is a uint32, there can
+	// be at most 32 levels.
+	for level := 0; len(nodes) > 1 || len(proof) > 0; level++ {
+		if level == 32 {
+			return false
+		}
+
+		// Prepend the left boundary sibling when the first node is a right
+		// child.
+		if startIndex&1 != 0 {
+			if len(proof) == 0 {
+				return false
+			}
+			nodes = append(nodes, chainhash.Hash{})
+			copy(nodes[1:], nodes)
+			nodes[0] = proof[0]
+			proof = proof[1:]
+		}
+
+		// Append the right boundary sibling when the last node is a left
+		// child.
+		if endIndex&1 == 0 {
+			if len(proof) == 0 {
+				return false
+			}
+			nodes = append(nodes, proof[0])
+			proof = proof[1:]
+		}
+
+		// Set the parent node to the hash of the concatenation of the left and
+		// right children.
+		for i := 0; i < len(nodes)>>1; i++ {
+			copy(left, nodes[i<<1][:])
+			copy(right, nodes[(i<<1)+1][:])
+			nodes[i] = chainhash.HashH(both)
+		}
+		nodes = nodes[:len(nodes)>>1]
+		startIndex >>= 1
+		endIndex >>= 1
+	}
+
+	// The final node must be the root at index 0.
+	return startIndex == 0 && *root == nodes[0]
+}
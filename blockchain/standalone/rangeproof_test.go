@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestRangeProof ensures range proofs generated for every possible range of
+// trees of various sizes verify against the merkle root, and that they do not
+// verify when the leaves or starting index are modified.
+func TestRangeProof(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for numLeaves := 1; numLeaves <= 23; numLeaves++ {
+		leaves := randomLeaves(rng, numLeaves)
+		root := CalcMerkleRoot(leaves)
+		for start := uint32(0); start < uint32(numLeaves); start++ {
+			for end := start; end < uint32(numLeaves); end++ {
+				proof, err := GenerateRangeProof(leaves, start, end)
+				if err != nil {
+					t.Fatalf("%d leaves, range [%d,%d]: unexpected error: %v",
+						numLeaves, start, end, err)
+				}
+
+				// A range of a single leaf must produce the same proof as
+				// the single leaf inclusion proof.  Note that an empty proof
+				// may be nil or zero length.
+				if start == end {
+					want := GenerateInclusionProof(leaves, start)
+					if len(want)+len(proof) > 0 && !reflect.DeepEqual(proof, want) {
+						t.Fatalf("%d leaves, range [%d,%d]: proof does not "+
+							"match inclusion proof", numLeaves, start, end)
+					}
+				}
+
+				rangeLeaves := leaves[start : end+1]
+				if !VerifyRangeProof(&root, rangeLeaves, start, proof) {
+					t.Fatalf("%d leaves, range [%d,%d]: proof did not verify",
+						numLeaves, start, end)
+				}
+
+				// Modify the final leaf in the range.
+				modified := append(rangeLeaves[:0:0], rangeLeaves...)
+				modified[len(modified)-1][0] ^= 0x01
+				if VerifyRangeProof(&root, modified, start, proof) {
+					t.Fatalf("%d leaves, range [%d,%d]: proof verified with "+
+						"modified leaf", numLeaves, start, end)
+				}
+
+				// Shift the starting index.
+				if start > 0 && VerifyRangeProof(&root, rangeLeaves, start-1, proof) {
+					t.Fatalf("%d leaves, range [%d,%d]: proof verified with "+
+						"wrong start index", numLeaves, start, end)
+				}
+			}
+		}
+	}
+}
+
+// TestGenerateRangeProofErrors ensures invalid ranges are rejected.
+func TestGenerateRangeProofErrors(t *testing.T) {
+	leaves := randomLeaves(rand.New(rand.NewSource(1)), 5)
+	tests := []struct {
+		name       string
+		numLeaves  int
+		start, end uint32
+	}{
+		{"no leaves", 0, 0, 0},
+		{"start after end", 5, 3, 2},
+		{"end out of range", 5, 2, 5},
+		{"start and end out of range", 5, 5, 6},
+	}
+
+	for _, test := range tests {
+		_, err := GenerateRangeProof(leaves[:test.numLeaves], test.start, test.end)
+		if err == nil {
+			t.Errorf("%q: did not receive expected error", test.name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// TxTreeType identifies one of the transaction trees of a block.  Decred blocks
+// commit to the regular and stake transaction trees separately, via the merkle
+// root and stake root fields of the block header, respectively.
+type TxTreeType uint8
+
+const (
+	// TxTreeRegular identifies the regular transaction tree, whose merkle root
+	// is the merkle root field of the block header.
+	TxTreeRegular TxTreeType = iota
+
+	// TxTreeStake identifies the stake transaction tree, whose merkle root is
+	// the stake root field of the block header.
+	TxTreeStake
+)
+
+// String returns the TxTreeType as a human-readable name.
+func (t TxTreeType) String() string {
+	switch t {
+	case TxTreeRegular:
+		return "regular"
+	case TxTreeStake:
+		return "stake"
+	}
+	return fmt.Sprintf("unknown tree (%d)", uint8(t))
+}
+
+// ErrUnknownTxTree is returned by GenerateTxTreeInclusionProof when the
+// transaction tree type is not recognized.
+var ErrUnknownTxTree = errors.New("unknown transaction tree type")
+
+// GenerateTxTreeInclusionProof generates and returns a merkle tree inclusion
+// proof for the given leaf index of the transaction tree identified by tree,
+// where the leaves of the regular and stake transaction trees are provided
+// separately.  This avoids accidentally proving a transaction against the
+// wrong tree of the block.  See GenerateInclusionProof for details about the
+// proof.
+//
+// ErrUnknownTxTree is returned when the tree type is not recognized, and
+// ErrNoLeaves is returned when the selected tree has no leaves.  Otherwise, the
+// result is that of GenerateInclusionProofErr for the selected tree.
+func GenerateTxTreeInclusionProof(regularLeaves, stakeLeaves []chainhash.Hash, tree TxTreeType, leafIndex uint32) ([]chainhash.Hash, error) {
+	var leaves []chainhash.Hash
+	switch tree {
+	case TxTreeRegular:
+		leaves = regularLeaves
+	case TxTreeStake:
+		leaves = stakeLeaves
+	default:
+		return nil, ErrUnknownTxTree
+	}
+	return GenerateInclusionProofErr(leaves, leafIndex)
+}
+
+// VerifyTxTreeInclusionProof returns whether or not the given leaf hash,
+// original leaf index, and inclusion proof result in recalculating the merkle
+// root of the transaction tree identified by tree, where merkleRoot and
+// stakeRoot are the merkle root and stake root fields of the block header,
+// respectively.  False is returned when the tree type is not recognized.  See
+// GenerateTxTreeInclusionProof for details.
+func VerifyTxTreeInclusionProof(merkleRoot, stakeRoot, leaf *chainhash.Hash, tree TxTreeType, leafIndex uint32, proof []chainhash.Hash) bool {
+	var root *chainhash.Hash
+	switch tree {
+	case TxTreeRegular:
+		root = merkleRoot
+	case TxTreeStake:
+		root = stakeRoot
+	default:
+		return false
+	}
+	return VerifyInclusionProof(root, leaf, leafIndex, proof)
+}
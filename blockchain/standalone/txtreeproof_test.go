@@ -0,0 +1,75 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package standalone
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// TestTxTreeInclusionProof ensures proofs for each transaction tree only verify
+// against the root of the selected tree, and that the expected errors are
+// returned for unknown and empty trees.
+func TestTxTreeInclusionProof(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	regularLeaves := randomLeaves(rng, 5)
+	stakeLeaves := randomLeaves(rng, 3)
+	merkleRoot := CalcMerkleRoot(regularLeaves)
+	stakeRoot := CalcMerkleRoot(stakeLeaves)
+
+	tests := []struct {
+		tree   TxTreeType
+		leaves []chainhash.Hash
+		other  TxTreeType
+	}{
+		{TxTreeRegular, regularLeaves, TxTreeStake},
+		{TxTreeStake, stakeLeaves, TxTreeRegular},
+	}
+	for _, test := range tests {
+		for i := range test.leaves {
+			leafIndex := uint32(i)
+			proof, err := GenerateTxTreeInclusionProof(regularLeaves,
+				stakeLeaves, test.tree, leafIndex)
+			if err != nil {
+				t.Fatalf("%v tree, index %d: unexpected error: %v", test.tree,
+					leafIndex, err)
+			}
+			leaf := &test.leaves[i]
+			if !VerifyTxTreeInclusionProof(&merkleRoot, &stakeRoot, leaf,
+				test.tree, leafIndex, proof) {
+				t.Fatalf("%v tree, index %d: proof did not verify", test.tree,
+					leafIndex)
+			}
+			if VerifyTxTreeInclusionProof(&merkleRoot, &stakeRoot, leaf,
+				test.other, leafIndex, proof) {
+				t.Fatalf("%v tree, index %d: proof verified against the %v "+
+					"tree", test.tree, leafIndex, test.other)
+			}
+		}
+	}
+
+	// Unknown tree types.
+	const unknownTree = TxTreeType(2)
+	_, err := GenerateTxTreeInclusionProof(regularLeaves, stakeLeaves,
+		unknownTree, 0)
+	if err != ErrUnknownTxTree {
+		t.Fatalf("unexpected error for unknown tree -- got %v, want %v", err,
+			ErrUnknownTxTree)
+	}
+	proof := GenerateInclusionProof(regularLeaves, 0)
+	if VerifyTxTreeInclusionProof(&merkleRoot, &merkleRoot, &regularLeaves[0],
+		unknownTree, 0, proof) {
+		t.Fatal("proof verified for unknown tree")
+	}
+
+	// Empty stake tree.
+	_, err = GenerateTxTreeInclusionProof(regularLeaves, nil, TxTreeStake, 0)
+	if err != ErrNoLeaves {
+		t.Fatalf("unexpected error for empty tree -- got %v, want %v", err,
+			ErrNoLeaves)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package candles records the OHLCV candles of the DCR-BTC markets monitored by
+// an ExchangeBot. The price of each exchange update, the volume-weighted price
+// of all the exchanges, and the mid price of each live orderbook update are
+// sampled into the candles of every bin width, and the candles reported by the
+// exchanges replace the sampled candles of the same bins with their volumes.
+package candles
+
+import (
+	"context"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/exchanges/v2"
+)
+
+// Store is the storage for the market candles of a Recorder.
+type Store interface {
+	StoreMarketPrices(prices []*dbtypes.MarketPrice) error
+	StoreMarketCandles(candles []*dbtypes.MarketCandle) error
+}
+
+// Recorder stores the market data of the ExchangeBot updates.
+type Recorder struct {
+	bot   *exchanges.ExchangeBot
+	store Store
+	// stored is the start of the last stored candle reported by each
+	// exchange, by exchange and bin. The last candle of an exchange is in
+	// progress, so it is stored again with the next update.
+	stored map[string]time.Time
+}
+
+// NewRecorder creates a Recorder for the ExchangeBot's updates.
+func NewRecorder(bot *exchanges.ExchangeBot, store Store) *Recorder {
+	return &Recorder{
+		bot:    bot,
+		store:  store,
+		stored: make(map[string]time.Time),
+	}
+}
+
+// Run records the exchange and orderbook updates until the ExchangeBot quits or
+// the context is canceled.
+func (rec *Recorder) Run(ctx context.Context) {
+	chans := rec.bot.UpdateChannels()
+	for {
+		select {
+		case update := <-chans.Exchange:
+			rec.recordExchange(update)
+		case update := <-chans.Depth:
+			rec.recordDepths(update)
+		case <-chans.Quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recordExchange samples the prices of the exchange and of the aggregated
+// market, and stores the new candles reported by the exchange.
+func (rec *Recorder) recordExchange(update *exchanges.ExchangeUpdate) {
+	now := time.Now()
+	var prices []*dbtypes.MarketPrice
+	if update.State.Price > 0 {
+		prices = append(prices, &dbtypes.MarketPrice{
+			Exchange: update.Token,
+			Time:     now,
+			Price:    update.State.Price,
+		})
+	}
+	if price := aggregatedPrice(rec.bot.State()); price > 0 {
+		prices = append(prices, &dbtypes.MarketPrice{
+			Exchange: dbtypes.AggregatedMarket,
+			Time:     now,
+			Price:    price,
+		})
+	}
+	if len(prices) > 0 {
+		if err := rec.store.StoreMarketPrices(prices); err != nil {
+			log.Errorf("Failed to store the market prices of %s: %v", update.Token, err)
+		}
+	}
+
+	var candles []*dbtypes.MarketCandle
+	for key, sticks := range update.State.Candlesticks {
+		bin := string(key)
+		if _, ok := dbtypes.MarketCandleBins[bin]; !ok || len(sticks) == 0 {
+			continue
+		}
+		storedKey := update.Token + "-" + bin
+		candles = append(candles, reportedCandles(update.Token, bin, sticks,
+			rec.stored[storedKey])...)
+		rec.stored[storedKey] = sticks[len(sticks)-1].Start
+	}
+	if len(candles) == 0 {
+		return
+	}
+	if err := rec.store.StoreMarketCandles(candles); err != nil {
+		log.Errorf("Failed to store the market candles of %s: %v", update.Token, err)
+		// Store all the candles again with the next update.
+		for key := range update.State.Candlesticks {
+			delete(rec.stored, update.Token+"-"+string(key))
+		}
+	}
+}
+
+// recordDepths samples the mid prices of the updated orderbooks.
+func (rec *Recorder) recordDepths(update *exchanges.DepthUpdate) {
+	state := rec.bot.State()
+	if state == nil {
+		return
+	}
+	t := time.Unix(update.Time, 0)
+	var prices []*dbtypes.MarketPrice
+	for _, token := range update.Tokens {
+		xcState, found := state.DcrBtc[token]
+		if !found {
+			continue
+		}
+		if price := midPrice(xcState.Depth); price > 0 {
+			prices = append(prices, &dbtypes.MarketPrice{
+				Exchange: token,
+				Time:     t,
+				Price:    price,
+			})
+		}
+	}
+	if len(prices) == 0 {
+		return
+	}
+	if err := rec.store.StoreMarketPrices(prices); err != nil {
+		log.Errorf("Failed to store the orderbook mid prices: %v", err)
+	}
+}
+
+// reportedCandles converts the candlesticks reported by an exchange for the bin
+// that start at or after since.
+func reportedCandles(token, bin string, sticks exchanges.Candlesticks, since time.Time) []*dbtypes.MarketCandle {
+	var candles []*dbtypes.MarketCandle
+	for _, stick := range sticks {
+		if stick.Start.Before(since) {
+			continue
+		}
+		candles = append(candles, &dbtypes.MarketCandle{
+			Exchange: token,
+			Bin:      bin,
+			Start:    stick.Start.UTC(),
+			Open:     stick.Open,
+			High:     stick.High,
+			Low:      stick.Low,
+			Close:    stick.Close,
+			Volume:   stick.Volume,
+		})
+	}
+	return candles
+}
+
+// aggregatedPrice is the DCR-BTC price of the exchanges weighted by their DCR
+// volumes, or 0 if there is no volume.
+func aggregatedPrice(state *exchanges.ExchangeBotState) float64 {
+	if state == nil {
+		return 0
+	}
+	var priceAccumulator, volSum float64
+	for _, xcState := range state.DcrBtc {
+		if xcState.Price <= 0 {
+			continue
+		}
+		volSum += xcState.Volume
+		priceAccumulator += xcState.Volume * xcState.Price
+	}
+	if volSum == 0 {
+		return 0
+	}
+	return priceAccumulator / volSum
+}
+
+// midPrice is the mean of the best bid and ask of the orderbook, or 0 if either
+// side is empty. The bids are ordered by descending price, and the asks by
+// ascending price.
+func midPrice(depth *exchanges.DepthData) float64 {
+	if depth == nil || len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return 0
+	}
+	return (depth.Bids[0].Price + depth.Asks[0].Price) / 2
+}
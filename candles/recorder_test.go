@@ -0,0 +1,66 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package candles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/exchanges/v2"
+)
+
+func TestReportedCandles(t *testing.T) {
+	start := time.Unix(1600000000, 0).Truncate(time.Hour)
+	sticks := exchanges.Candlesticks{
+		{Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10, Start: start},
+		{Open: 1.5, High: 3, Low: 1, Close: 2, Volume: 20, Start: start.Add(time.Hour)},
+		{Open: 2, High: 2.5, Low: 2, Close: 2.2, Volume: 5, Start: start.Add(2 * time.Hour)},
+	}
+
+	candles := reportedCandles("binance", "1h", sticks, time.Time{})
+	if len(candles) != 3 {
+		t.Fatalf("got %d candles, want 3", len(candles))
+	}
+	c := candles[1]
+	if c.Exchange != "binance" || c.Bin != "1h" || !c.Start.Equal(sticks[1].Start) ||
+		c.Open != 1.5 || c.High != 3 || c.Low != 1 || c.Close != 2 || c.Volume != 20 {
+		t.Errorf("unexpected candle %+v", c)
+	}
+
+	// The candle in progress at the last update is converted again.
+	candles = reportedCandles("binance", "1h", sticks, start.Add(time.Hour))
+	if len(candles) != 2 || !candles[0].Start.Equal(sticks[1].Start) {
+		t.Errorf("got %d candles since the second, want 2", len(candles))
+	}
+}
+
+func TestAggregatedPrice(t *testing.T) {
+	state := &exchanges.ExchangeBotState{
+		DcrBtc: map[string]*exchanges.ExchangeState{
+			"binance": {Price: 0.01, Volume: 300},
+			"bittrex": {Price: 0.02, Volume: 100},
+			"failing": {Price: 0, Volume: 1000},
+		},
+	}
+	if price := aggregatedPrice(state); price != 0.0125 {
+		t.Errorf("got aggregated price %v, want 0.0125", price)
+	}
+	if price := aggregatedPrice(&exchanges.ExchangeBotState{}); price != 0 {
+		t.Errorf("got aggregated price %v without exchanges, want 0", price)
+	}
+}
+
+func TestMidPrice(t *testing.T) {
+	depth := &exchanges.DepthData{
+		Bids: []exchanges.DepthPoint{{Price: 0.010, Quantity: 1}, {Price: 0.009, Quantity: 2}},
+		Asks: []exchanges.DepthPoint{{Price: 0.012, Quantity: 1}, {Price: 0.013, Quantity: 2}},
+	}
+	if price := midPrice(depth); price != 0.011 {
+		t.Errorf("got mid price %v, want 0.011", price)
+	}
+	depth.Asks = nil
+	if price := midPrice(depth); price != 0 {
+		t.Errorf("got mid price %v of a one-sided orderbook, want 0", price)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+	"github.com/decred/dcrdata/rpcutils/v3"
+)
+
+// chainWorkMismatch describes a stored block whose chainwork differs from the
+// node's.
+type chainWorkMismatch struct {
+	height int64
+	hash   string
+	stored string
+	node   string
+}
+
+// normalizeChainWork returns the hex-encoded chainwork in a canonical form for
+// comparison, without leading zeros.
+func normalizeChainWork(work string) string {
+	work = strings.TrimLeft(strings.ToLower(work), "0")
+	if work == "" {
+		return "0"
+	}
+	return work
+}
+
+// verifyChainWork compares the stored chainwork of the mainchain blocks from
+// genesis through height with the chainwork reported by the node. Every step'th
+// block is checked, with step 1 checking all blocks, and the block at height is
+// always checked. The RPC calls are retried with backoff, and the verification
+// stops early if quit is closed, returning cancelled. The number of blocks
+// checked and any mismatches are returned.
+func verifyChainWork(db *dcrpg.ChainDB, client *rpcclient.Client, height, step int64,
+	quit <-chan struct{}) (checked int64, mismatches []chainWorkMismatch, cancelled bool, err error) {
+	if step < 1 {
+		step = 1
+	}
+
+	check := func(h int64) error {
+		hashStr, stored, err := db.BlockChainWork(h)
+		if err != nil {
+			return fmt.Errorf("BlockChainWork(%d) failed: %v", h, err)
+		}
+		hash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return fmt.Errorf("invalid block hash %q at height %d: %v", hashStr, h, err)
+		}
+
+		var nodeWork string
+		cancelled, err = withRetry(quit, fmt.Sprintf("GetChainWork(%d)", h), func() (err error) {
+			nodeWork, err = rpcutils.GetChainWork(client, hash)
+			return err
+		})
+		if cancelled {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("GetChainWork failed (%s): %v", hash, err)
+		}
+
+		checked++
+		if normalizeChainWork(stored) != normalizeChainWork(nodeWork) {
+			m := chainWorkMismatch{h, hashStr, stored, nodeWork}
+			log.Errorf("Chainwork mismatch at height %d (%s): stored %s, node %s",
+				m.height, m.hash, m.stored, m.node)
+			mismatches = append(mismatches, m)
+		}
+		return nil
+	}
+
+	for h := int64(0); h <= height; h += step {
+		// check for quit signal
+		select {
+		case <-quit:
+			return checked, mismatches, true, nil
+		default:
+		}
+
+		if err = check(h); err != nil || cancelled {
+			return
+		}
+		if checked%5000 == 0 {
+			log.Infof("Verified chainwork of %d blocks, through height %d.", checked, h)
+		}
+	}
+
+	// Always check the tip, which the step may have skipped.
+	if height%step != 0 {
+		err = check(height)
+	}
+	return
+}
@@ -11,9 +11,11 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
 	"github.com/decred/dcrdata/v5/netparams"
 	flags "github.com/jessevdk/go-flags"
 )
@@ -40,6 +42,13 @@ var (
 	defaultDBUser     = "dcrdata"
 	defaultDBPass     = ""
 	defaultDBName     = "dcrdata"
+
+	defaultStakeDBRecoverWindow = 288
+	defaultMaxHeightGap         = 1000
+	defaultVerifyChainWorkStep  = 100
+	defaultFetchWorkers         = 4
+	defaultHealthStaleness      = 2 * time.Minute
+	defaultJSONProgressFD       = 1 // stdout
 )
 
 type config struct {
@@ -53,19 +62,51 @@ type config struct {
 	LogDir       string `long:"logdir" description:"Directory to log output"`
 	HTTPProfile  bool   `long:"httpprof" short:"p" description:"Start HTTP profiler."`
 	CPUProfile   string `long:"cpuprofile" description:"File for CPU profiling."`
-	MemProfile   string `long:"memprofile" description:"File for memory profiling."`
+	MemProfile   string `long:"memprofile" description:"Path prefix for heap profiles, which are written each time SIGUSR2 is received."`
 	HidePGConfig bool   `long:"hidepgconfig" description:"Blocks logging of the PostgreSQL db configuration on system start up."`
+	Report       string `long:"report" description:"File to which a JSON report of the sync is written on completion or clean shutdown."`
+
+	// JSON progress stream
+	JSONProgress   bool `long:"jsonprogress" description:"Write sync progress as newline-delimited JSON to the file descriptor set by jsonprogressfd (stdout by default) each time progress is logged."`
+	JSONProgressFD int  `long:"jsonprogressfd" description:"File descriptor to which jsonprogress output is written."`
+
+	// Health endpoint
+	HealthListen    string        `long:"healthlisten" description:"Address (host:port) on which to serve the /healthz liveness endpoint. Disabled if empty."`
+	HealthStaleness time.Duration `long:"healthstale" description:"Time without storing a block after which /healthz reports the sync as stalled."`
 
 	// DB
-	DBHostPort             string `long:"dbhost" description:"DB host"`
-	DBUser                 string `long:"dbuser" description:"DB user"`
-	DBPass                 string `long:"dbpass" description:"DB pass"`
-	DBName                 string `long:"dbname" description:"DB name"`
-	DuplicateEntryRecovery bool   `short:"r" long:"recoverfromdups" description:"Remove duplicate entries from all tables which would be prevented by the unique indexes. May be necessary to recover from an ill-timed crash."`
-	DropDBTables           bool   `short:"D" long:"droptables" description:"Drop/delete DB tables."`
-	ForceReindex           bool   `long:"reindex" short:"R" description:"Drop indexes prior to sync and recreate after sync, with insertion conflict checks disabled in absence of constraints."`
-	AddrSpendInfoOnline    bool   `short:"a" long:"addrspends-no-batch" description:"Continually update the address table spending transaction info during rebuild (instead of full table update at end).  SLOW if doing full rebuild!"`
-	TicketSpendInfoBatch   bool   `short:"T" long:"ticketspends-batch" description:"Batch update the tickets table spending transaction info after rebuild (instead of during the rebuild)."`
+	DBHostPort             string   `long:"dbhost" description:"DB host"`
+	DBUser                 string   `long:"dbuser" description:"DB user"`
+	DBPass                 string   `long:"dbpass" description:"DB pass"`
+	DBName                 string   `long:"dbname" description:"DB name"`
+	DuplicateEntryRecovery bool     `short:"r" long:"recoverfromdups" description:"Remove duplicate entries from all tables which would be prevented by the unique indexes. May be necessary to recover from an ill-timed crash."`
+	DropDBTables           bool     `short:"D" long:"droptables" description:"Drop/delete DB tables. Requires --yes."`
+	DropTable              []string `long:"droptable" description:"Drop/delete only the named DB table (e.g. addresses, tickets, votes), then exit. May be specified multiple times. Requires --yes."`
+	ConfirmDrop            bool     `long:"yes" description:"Confirm that DB tables should be dropped by --droptables or --droptable."`
+	ForceReindex           bool     `long:"reindex" short:"R" description:"Drop indexes prior to sync and recreate after sync, with insertion conflict checks disabled in absence of constraints."`
+	OnlySpendInfo          string   `long:"onlyspendinfo" optional:"yes" optional-value:"all" description:"Skip the block sync and only populate the spending tx info of an already synced DB. Select the tables with addresses, tickets, or all (default when no value is given)."`
+	Estimate               bool     `long:"estimate" description:"Report the DB and node heights, the number of blocks to process, whether a bulk reindex would be used, and a time estimate from a short throughput probe, then exit without storing anything."`
+	DumpUTXO               string   `long:"dumputxo" description:"Write the UTXO set to the CSV file, then exit without syncing. The UTXO set is at the best block, or at dumputxo-height."`
+	DumpUTXOHeight         int64    `long:"dumputxo-height" description:"Height of the UTXO set written by dumputxo. The default (-1) is the best block."`
+	MigrateOnly            bool     `long:"migrate-only" description:"Apply any pending database schema migrations, then exit without syncing."`
+	MigrateRevert          bool     `long:"migrate-revert" description:"Revert the most recently applied database schema migration, then exit."`
+	MigrateDryRun          bool     `long:"migrate-dryrun" description:"With migrate-only or migrate-revert, only log the migration steps and the migration history without modifying the database."`
+	MigrateBackup          bool     `long:"migrate-backup" description:"With migrate-only, copy the tables modified by the migrations first, so that they may be reverted. Each copy needs as much free disk space as the table."`
+	AddrSpendInfoOnline    bool     `short:"a" long:"addrspends-no-batch" description:"Continually update the address table spending transaction info during rebuild (instead of full table update at end).  SLOW if doing full rebuild!"`
+	AddrSpendIncremental   bool     `long:"addrspends-incremental" description:"When populating the address table spending tx info after the sync (without addrspends-no-batch), only process outputs spent in the blocks added since the last address spending info update, keeping the address table indexes. Use with onlyspendinfo=addresses for periodic catch-up runs."`
+	TicketSpendInfoBatch   bool     `short:"T" long:"ticketspends-batch" description:"Batch update the tickets table spending transaction info after rebuild (instead of during the rebuild)."`
+	StakeDBRecoverWindow   int64    `long:"stakedbrecoverwindow" description:"Number of blocks to rewind the stake DB when attempting to recover it from corruption."`
+	StakeDBSnapshotExport  string   `long:"stakedb-snapshot-export" description:"Write a snapshot of the stake DB to the file, then exit. The snapshot is at the stake DB height, or at stakedb-snapshot-height."`
+	StakeDBSnapshotHeight  int64    `long:"stakedb-snapshot-height" description:"Height of the exported stake DB snapshot. The stake DB is rewound to this height. The default (-1) is the current stake DB height."`
+	StakeDBSnapshotImport  string   `long:"stakedb-snapshot-import" description:"Create the stake DB from a snapshot file written with stakedb-snapshot-export before syncing. The stake DB must not already exist."`
+	MaxHeightGap           int64    `long:"maxheightgap" description:"Maximum difference between the chain DB and stake DB heights before syncing is refused. A negative value disables the check."`
+	Force                  bool     `long:"force" description:"Proceed with the sync even if the chain DB and stake DB heights differ by more than maxheightgap."`
+	VerifyChainWork        bool     `long:"verifychainwork" description:"After the sync, verify that the stored chainwork of the mainchain blocks matches the node's, and exit with an error on any mismatch."`
+	VerifyChainWorkStep    int64    `long:"verifychainworkstep" description:"Check the chainwork of every Nth block with verifychainwork. The best block is always checked. Use 1 to check every block."`
+	FetchWorkers           int      `long:"fetchworkers" description:"Number of concurrent RPC workers fetching blocks from dcrd. Fetched blocks are still stored in height order. Use 1 to fetch serially."`
+	VinBatchSize           int      `long:"vinbatch" description:"Maximum number of vins written in each COPY when copythreshold is set."`
+	VoutBatchSize          int      `long:"voutbatch" description:"Maximum number of vouts written in each COPY when copythreshold is set."`
+	CopyThreshold          int      `long:"copythreshold" description:"Number of vins or vouts in a block's transaction tree at or above which they are written with COPY instead of an INSERT for each row. Only used with the indexes dropped (reindex). 0 disables COPY."`
 
 	// RPC client options
 	DcrdUser         string `long:"dcrduser" description:"Daemon RPC user name"`
@@ -85,6 +126,17 @@ var (
 		DBPass:     defaultDBPass,
 		DBName:     defaultDBName,
 		DcrdCert:   defaultDaemonRPCCertFile,
+
+		StakeDBRecoverWindow:  defaultStakeDBRecoverWindow,
+		StakeDBSnapshotHeight: -1,
+		DumpUTXOHeight:        -1,
+		MaxHeightGap:          defaultMaxHeightGap,
+		VerifyChainWorkStep:   defaultVerifyChainWorkStep,
+		FetchWorkers:          defaultFetchWorkers,
+		VinBatchSize:          dcrpg.DefaultCopyBatchSize,
+		VoutBatchSize:         dcrpg.DefaultCopyBatchSize,
+		HealthStaleness:       defaultHealthStaleness,
+		JSONProgressFD:        defaultJSONProgressFD,
 	}
 )
 
@@ -202,6 +254,75 @@ func loadConfig() (*config, error) {
 		return loadConfigError(err)
 	}
 
+	if cfg.StakeDBRecoverWindow < 0 {
+		err := fmt.Errorf("%s: stakedbrecoverwindow may not be negative (%d)",
+			"loadConfig", cfg.StakeDBRecoverWindow)
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.StakeDBSnapshotExport != "" && cfg.StakeDBSnapshotImport != "" {
+		err := fmt.Errorf("%s: stakedb-snapshot-export and stakedb-snapshot-import "+
+			"may not be used together", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.JSONProgress && cfg.JSONProgressFD < 1 {
+		err := fmt.Errorf("%s: jsonprogressfd must be an open output file "+
+			"descriptor (got %d)", "loadConfig", cfg.JSONProgressFD)
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if (cfg.DropDBTables || len(cfg.DropTable) > 0) && !cfg.ConfirmDrop {
+		err := fmt.Errorf("%s: dropping tables deletes data and requires "+
+			"confirmation with --yes", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+	if cfg.DropDBTables && len(cfg.DropTable) > 0 {
+		err := fmt.Errorf("%s: droptables and droptable may not be used "+
+			"together", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.MigrateOnly && cfg.MigrateRevert {
+		err := fmt.Errorf("%s: migrate-only and migrate-revert may not be "+
+			"used together", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+	if (cfg.MigrateDryRun || cfg.MigrateBackup) && !cfg.MigrateOnly && !cfg.MigrateRevert {
+		err := fmt.Errorf("%s: migrate-dryrun and migrate-backup require "+
+			"migrate-only or migrate-revert", "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.OnlySpendInfo != "" && !validSpendInfoSelection(cfg.OnlySpendInfo) {
+		err := fmt.Errorf("%s: onlyspendinfo must be one of %s, %s, or %s (got %q)",
+			"loadConfig", spendInfoAddresses, spendInfoTickets, spendInfoAll,
+			cfg.OnlySpendInfo)
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.VerifyChainWorkStep < 1 {
+		err := fmt.Errorf("%s: verifychainworkstep must be at least 1 (got %d)",
+			"loadConfig", cfg.VerifyChainWorkStep)
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
+	if cfg.FetchWorkers < 1 {
+		err := fmt.Errorf("%s: fetchworkers must be at least 1 (got %d)",
+			"loadConfig", cfg.FetchWorkers)
+		fmt.Fprintln(os.Stderr, err)
+		return loadConfigError(err)
+	}
+
 	// Set the host names and ports to the default if the
 	// user does not specify them.
 	if cfg.DcrdServ == "" {
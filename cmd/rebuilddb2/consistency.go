@@ -0,0 +1,35 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+)
+
+// checkHeightAgreement ensures the chain DB and stake DB heights are within
+// maxGap blocks of each other. A larger gap, such as after a manual table
+// truncation, would otherwise cause the stake DB to be silently rewound or
+// advanced over a large number of blocks before the sync begins. An empty chain
+// DB is not checked since a fresh rebuild with an existing stake DB is the
+// normal result of dropping the tables. A negative maxGap disables the check.
+func checkHeightAgreement(dbHeight, stakeDBHeight, maxGap int64) error {
+	if maxGap < 0 || dbHeight < 0 {
+		return nil
+	}
+	gap := stakeDBHeight - dbHeight
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap <= maxGap {
+		return nil
+	}
+	direction := "ahead of"
+	if stakeDBHeight < dbHeight {
+		direction = "behind"
+	}
+	return fmt.Errorf("the stake DB height (%d) is %d blocks %s the chain DB "+
+		"height (%d), exceeding the limit of %d blocks set by --maxheightgap. "+
+		"Use --force to proceed anyway", stakeDBHeight, gap, direction,
+		dbHeight, maxGap)
+}
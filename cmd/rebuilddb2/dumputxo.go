@@ -0,0 +1,83 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// utxoCSVHeader are the columns of the UTXO set CSV file. The addresses of a
+// multisig output are separated by spaces.
+var utxoCSVHeader = []string{"tx_hash", "tx_index", "tx_tree", "height",
+	"value", "script_type", "addresses"}
+
+// dumpUTXOSet writes the UTXO set after the mainchain block at the height, or
+// at the best block if height is negative, to a CSV file. The file is written
+// to a temporary file that is renamed when complete.
+func dumpUTXOSet(db *dcrpg.ChainDB, fileName string, height int64) error {
+	if height < 0 {
+		_, height = db.BestBlockStr()
+	}
+
+	tmpName := fileName + ".tmp"
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(f, 1<<20)
+	w := csv.NewWriter(bw)
+
+	log.Infof("Writing the UTXO set at height %d to %s...", height, fileName)
+	var count, total int64
+	err = w.Write(utxoCSVHeader)
+	if err == nil {
+		record := make([]string, len(utxoCSVHeader))
+		err = db.ForEachUTXO(height, func(utxo *dbtypes.UTXOSetEntry) error {
+			record[0] = utxo.TxHash
+			record[1] = strconv.FormatUint(uint64(utxo.TxIndex), 10)
+			record[2] = strconv.Itoa(int(utxo.TxTree))
+			record[3] = strconv.FormatInt(utxo.Height, 10)
+			record[4] = strconv.FormatInt(utxo.Value, 10)
+			record[5] = utxo.ScriptType
+			record[6] = strings.Join(utxo.Addresses, " ")
+			count++
+			total += utxo.Value
+			return w.Write(record)
+		})
+	}
+	if err == nil {
+		w.Flush()
+		if err = w.Error(); err == nil {
+			err = bw.Flush()
+		}
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmpName)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("the outputs at height %d have been pruned", height)
+		}
+		return fmt.Errorf("failed to write the UTXO set: %v", err)
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err = os.Rename(tmpName, fileName); err != nil {
+		return err
+	}
+
+	log.Infof("Wrote %d unspent outputs with a total value of %d atoms at height %d to %s.",
+		count, total, height, fileName)
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+	"github.com/decred/dcrdata/rpcutils/v3"
+)
+
+// estimateProbeBlocks is the number of blocks fetched from the node to probe
+// the throughput for the --estimate mode.
+const estimateProbeBlocks = 48
+
+// useBulkReindex indicates if the sync from lastBlock to the node's best block
+// height is large enough to use the bulk load path, which drops the indexes
+// before the sync and recreates them after.
+func useBulkReindex(lastBlock, height int64) bool {
+	blocksToSync := height - lastBlock
+	return blocksToSync > height/2
+}
+
+// syncEstimate describes the scope of the work a sync would perform.
+type syncEstimate struct {
+	DBHeight     int64
+	NodeHeight   int64
+	BlocksToSync int64
+	BulkReindex  bool
+	ProbeBlocks  int64
+	BlocksPerSec float64
+}
+
+// ETA returns the estimated duration of the block store loop based on the
+// probed throughput.
+func (e *syncEstimate) ETA() time.Duration {
+	return estimateETA(e.BlocksToSync, e.BlocksPerSec)
+}
+
+// estimateSync determines the scope of a sync without storing any blocks or
+// modifying the indexes. The throughput is probed by fetching up to
+// estimateProbeBlocks of the blocks that would be synced, and their chainwork,
+// in the same manner as the block store loop.
+func estimateSync(db *dcrpg.ChainDB, client *rpcclient.Client, forceReindex bool) (*syncEstimate, error) {
+	lastBlock, err := db.HeightDB()
+	if err != nil {
+		return nil, fmt.Errorf("HeightDB failed: %v", err)
+	}
+	_, height, err := client.GetBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("GetBestBlock failed: %v", err)
+	}
+
+	e := &syncEstimate{
+		DBHeight:     lastBlock,
+		NodeHeight:   height,
+		BlocksToSync: height - lastBlock,
+		BulkReindex:  useBulkReindex(lastBlock, height) || forceReindex,
+	}
+	if e.BlocksToSync <= 0 {
+		e.BlocksToSync = 0
+		return e, nil
+	}
+
+	probeEnd := lastBlock + estimateProbeBlocks
+	if probeEnd > height {
+		probeEnd = height
+	}
+	start := time.Now()
+	for ib := lastBlock + 1; ib <= probeEnd; ib++ {
+		_, blockHash, err := rpcutils.GetBlock(ib, client)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlock failed (%s): %v", blockHash, err)
+		}
+		if _, err = rpcutils.GetChainWork(client, blockHash); err != nil {
+			return nil, fmt.Errorf("GetChainWork failed (%s): %v", blockHash, err)
+		}
+		e.ProbeBlocks++
+	}
+	if secs := time.Since(start).Seconds(); secs > 0 {
+		e.BlocksPerSec = float64(e.ProbeBlocks) / secs
+	}
+	return e, nil
+}
+
+// logSummary writes the estimate to the log.
+func (e *syncEstimate) logSummary() {
+	log.Infof("DB height: %d, node best block height: %d", e.DBHeight, e.NodeHeight)
+	log.Infof("Blocks to process: %d", e.BlocksToSync)
+	if e.BulkReindex {
+		log.Infof("Bulk load: indexes would be dropped before the sync and " +
+			"recreated after.")
+	} else {
+		log.Infof("Incremental sync: indexes and duplicate checks would remain enabled.")
+	}
+	if e.BlocksToSync == 0 {
+		return
+	}
+	log.Infof("Fetched %d blocks from the node at %.1f blk/s. Estimated time to "+
+		"process the remaining blocks: %s", e.ProbeBlocks, e.BlocksPerSec,
+		etaString(e.ETA()))
+	log.Infof("The estimate reflects node fetch throughput only. Database " +
+		"inserts, index creation, and the spend-info updates add to it.")
+}
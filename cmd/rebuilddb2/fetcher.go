@@ -0,0 +1,162 @@
+// Copyright (c) 2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrdata/rpcutils/v3"
+)
+
+// fetchWindowPerWorker is the number of fetched blocks per worker that may be
+// waiting to be consumed in height order. The window bounds memory use when a
+// slow fetch holds up the blocks fetched after it by the other workers.
+const fetchWindowPerWorker = 4
+
+// fetchResult is a block and its chainwork fetched from the node by a
+// blockFetcher worker.
+type fetchResult struct {
+	height    int64
+	block     *dcrutil.Block
+	hash      *chainhash.Hash
+	chainWork string
+	err       error
+}
+
+// fetchJob is a height to be fetched by a worker, and the slot to which the
+// result is delivered.
+type fetchJob struct {
+	height int64
+	slot   chan *fetchResult
+}
+
+// blockFetcher fetches a range of blocks from the node with a pool of
+// concurrent RPC workers, and delivers them in height order via Next. A slot
+// for each height is queued in order before the height is handed to a worker,
+// so the consumer simply waits on the slots in turn, regardless of the order
+// in which the workers complete.
+type blockFetcher struct {
+	client rpcutils.BlockFetcher
+	end    int64
+	slots  chan chan *fetchResult
+	jobs   chan fetchJob
+	quit   chan struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// newBlockFetcher starts the specified number of workers fetching the blocks
+// from start to end, inclusive. Stop must be called when the fetcher is no
+// longer needed, such as when the fetched blocks are abandoned after a reorg.
+func newBlockFetcher(client rpcutils.BlockFetcher, start, end int64, workers int,
+	quit chan struct{}) *blockFetcher {
+	if workers < 1 {
+		workers = 1
+	}
+	f := &blockFetcher{
+		client: client,
+		end:    end,
+		slots:  make(chan chan *fetchResult, workers*fetchWindowPerWorker),
+		jobs:   make(chan fetchJob),
+		quit:   quit,
+		stop:   make(chan struct{}),
+	}
+
+	f.wg.Add(1 + workers)
+	go f.dispatch(start)
+	for i := 0; i < workers; i++ {
+		go f.work()
+	}
+	return f
+}
+
+// dispatch queues a result slot for each height in order, and hands the height
+// to the next available worker. The slots channel is closed once the end of
+// the range is reached, or when the fetcher is stopped.
+func (f *blockFetcher) dispatch(start int64) {
+	defer f.wg.Done()
+	defer close(f.slots)
+	defer close(f.jobs)
+	for height := start; height <= f.end; height++ {
+		slot := make(chan *fetchResult, 1)
+		select {
+		case f.slots <- slot:
+		case <-f.stop:
+			return
+		case <-f.quit:
+			return
+		}
+		select {
+		case f.jobs <- fetchJob{height, slot}:
+		case <-f.stop:
+			return
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// work fetches the blocks for the dispatched heights until the job channel is
+// closed. The slots are buffered, so delivering a result never blocks.
+func (f *blockFetcher) work() {
+	defer f.wg.Done()
+	for job := range f.jobs {
+		job.slot <- f.fetch(job.height)
+	}
+}
+
+// fetch gets the block at the given height and its chainwork from the node.
+func (f *blockFetcher) fetch(height int64) *fetchResult {
+	res := &fetchResult{height: height}
+	res.block, res.hash, res.err = rpcutils.GetBlock(height, f.client)
+	if res.err != nil {
+		res.err = fmt.Errorf("GetBlock failed (%s): %v", res.hash, res.err)
+		return res
+	}
+	res.chainWork, res.err = rpcutils.GetChainWork(f.client, res.hash)
+	if res.err != nil {
+		res.err = fmt.Errorf("GetChainWork failed (%s): %v", res.hash, res.err)
+	}
+	return res
+}
+
+// Next returns the result for the next height in the range, waiting for it to
+// be fetched if necessary. A nil result is returned once the range has been
+// exhausted, or if the fetcher was stopped or quit was signaled.
+func (f *blockFetcher) Next() *fetchResult {
+	var slot chan *fetchResult
+	select {
+	case s, ok := <-f.slots:
+		if !ok {
+			return nil
+		}
+		slot = s
+	case <-f.quit:
+		return nil
+	}
+	select {
+	case res := <-slot:
+		return res
+	case <-f.quit:
+		return nil
+	}
+}
+
+// Covers reports whether the given height is within the fetcher's range.
+func (f *blockFetcher) Covers(height int64) bool {
+	return height <= f.end
+}
+
+// Stop signals the dispatcher to stop handing out heights and waits for the
+// workers to finish their in-progress fetches. It is safe to call Stop more
+// than once.
+func (f *blockFetcher) Stop() {
+	f.stopOnce.Do(func() { close(f.stop) })
+	f.wg.Wait()
+}
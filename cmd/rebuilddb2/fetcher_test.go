@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// fetcherClientStub is a BlockFetcher for a chain of empty blocks whose
+// hashes encode their heights. Blocks take longer to fetch at some heights,
+// so that the workers complete out of order.
+type fetcherClientStub struct {
+	best   int64
+	failAt int64 // GetBlock fails at this height
+}
+
+func stubBlockHash(height int64) *chainhash.Hash {
+	var hash chainhash.Hash
+	binary.LittleEndian.PutUint64(hash[:], uint64(height)+1)
+	return &hash
+}
+
+func stubBlockHeight(hash *chainhash.Hash) int64 {
+	return int64(binary.LittleEndian.Uint64(hash[:])) - 1
+}
+
+func stubChainWork(height int64) string {
+	return fmt.Sprintf("%064x", height)
+}
+
+func (c *fetcherClientStub) GetBestBlock() (*chainhash.Hash, int64, error) {
+	return stubBlockHash(c.best), c.best, nil
+}
+
+func (c *fetcherClientStub) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	if height < 0 || height > c.best {
+		return nil, fmt.Errorf("no block at height %d", height)
+	}
+	return stubBlockHash(height), nil
+}
+
+func (c *fetcherClientStub) GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	height := stubBlockHeight(hash)
+	if height == c.failAt {
+		return nil, fmt.Errorf("block %d unavailable", height)
+	}
+	time.Sleep(time.Duration(height%3) * time.Millisecond)
+	return &wire.MsgBlock{Header: wire.BlockHeader{Height: uint32(height)}}, nil
+}
+
+func (c *fetcherClientStub) GetBlockHeaderVerbose(hash *chainhash.Hash) (*chainjson.GetBlockHeaderVerboseResult, error) {
+	return &chainjson.GetBlockHeaderVerboseResult{
+		ChainWork: stubChainWork(stubBlockHeight(hash)),
+	}, nil
+}
+
+func TestBlockFetcherOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int64
+		workers    int
+		failAt     int64
+	}{
+		{"one worker", 0, 20, 1, -1},
+		{"many workers", 5, 100, 8, -1},
+		{"more workers than blocks", 10, 12, 16, -1},
+		{"no workers", 0, 5, 0, -1},
+		{"single block", 7, 7, 4, -1},
+		{"fetch error", 0, 30, 4, 17},
+	}
+
+	for _, test := range tests {
+		client := &fetcherClientStub{best: 100, failAt: test.failAt}
+		f := newBlockFetcher(client, test.start, test.end, test.workers, make(chan struct{}))
+
+		next := test.start
+		for res := f.Next(); res != nil; res = f.Next() {
+			if res.height != next {
+				t.Fatalf("%s: expected height %d, got %d", test.name, next, res.height)
+			}
+			next++
+			if res.height == test.failAt {
+				if res.err == nil {
+					t.Errorf("%s: expected an error at height %d", test.name, res.height)
+				}
+				continue
+			}
+			if res.err != nil {
+				t.Errorf("%s: unexpected error at height %d: %v", test.name, res.height, res.err)
+				continue
+			}
+			if res.block.Height() != res.height || *res.hash != *stubBlockHash(res.height) {
+				t.Errorf("%s: wrong block %d (%v) at height %d", test.name,
+					res.block.Height(), res.hash, res.height)
+			}
+			if res.chainWork != stubChainWork(res.height) {
+				t.Errorf("%s: wrong chainwork %s at height %d", test.name, res.chainWork, res.height)
+			}
+		}
+		f.Stop()
+
+		if next != test.end+1 {
+			t.Errorf("%s: expected blocks up to %d, got up to %d", test.name, test.end, next-1)
+		}
+		if f.Covers(test.end+1) || !f.Covers(test.end) {
+			t.Errorf("%s: wrong coverage of the end of the range", test.name)
+		}
+	}
+}
+
+func TestBlockFetcherStop(t *testing.T) {
+	tests := []struct {
+		name  string
+		quit  bool // close quit instead of calling Stop
+		reads int
+	}{
+		{"stop before reading", false, 0},
+		{"stop after reading", false, 10},
+		{"quit", true, 10},
+	}
+
+	for _, test := range tests {
+		quit := make(chan struct{})
+		f := newBlockFetcher(&fetcherClientStub{best: 1000, failAt: -1}, 0, 1000, 4, quit)
+		for i := 0; i < test.reads; i++ {
+			if res := f.Next(); res == nil || res.height != int64(i) {
+				t.Fatalf("%s: expected height %d, got %v", test.name, i, res)
+			}
+		}
+
+		// After quit, Next may still return the results already queued in the
+		// window, but no more than that.
+		if test.quit {
+			close(quit)
+			window := 4*fetchWindowPerWorker + 1
+			var i int
+			for ; i <= window && f.Next() != nil; i++ {
+			}
+			if i > window {
+				t.Errorf("%s: expected no results beyond the window after quit", test.name)
+			}
+		}
+
+		// Stop must not wait for the rest of the range to be fetched.
+		stopped := make(chan struct{})
+		go func() {
+			f.Stop()
+			f.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: Stop did not return", test.name)
+		}
+	}
+}
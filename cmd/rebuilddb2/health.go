@@ -0,0 +1,135 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Sync phases reported by the health endpoint.
+const (
+	phaseStarting  = "starting"
+	phaseStakeDB   = "stakedb"
+	phaseStoring   = "storing"
+	phasePaused    = "paused"
+	phaseIndexing  = "indexing"
+	phaseSpendInfo = "spendinfo"
+	phaseDone      = "done"
+)
+
+// syncHealth tracks the progress of the sync for the health endpoint. The sync
+// is considered stalled if no block has been stored within the staleness
+// window while in the block storing phase.
+type syncHealth struct {
+	mtx          sync.RWMutex
+	phase        string
+	height       int64
+	target       int64
+	lastProgress time.Time
+	shuttingDown bool
+	staleAfter   time.Duration
+}
+
+// healthStatus is the JSON response of the health endpoint.
+type healthStatus struct {
+	Healthy              bool    `json:"healthy"`
+	Status               string  `json:"status"`
+	Phase                string  `json:"phase"`
+	Height               int64   `json:"height"`
+	Target               int64   `json:"target"`
+	SecondsSinceProgress float64 `json:"seconds_since_progress"`
+}
+
+// newSyncHealth creates a new syncHealth in the starting phase.
+func newSyncHealth(staleAfter time.Duration) *syncHealth {
+	return &syncHealth{
+		phase:        phaseStarting,
+		height:       -1,
+		lastProgress: time.Now(),
+		staleAfter:   staleAfter,
+	}
+}
+
+// SetPhase sets the current sync phase, and resets the staleness timer.
+func (h *syncHealth) SetPhase(phase string) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.phase = phase
+	h.lastProgress = time.Now()
+}
+
+// BlockStored records that the block at the given height was stored, and the
+// current target height.
+func (h *syncHealth) BlockStored(height, target int64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.height, h.target = height, target
+	h.lastProgress = time.Now()
+}
+
+// SetHeight sets the current and target heights without indicating progress.
+func (h *syncHealth) SetHeight(height, target int64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.height, h.target = height, target
+}
+
+// SetShuttingDown marks the sync as shutting down, after which the health
+// endpoint always reports unhealthy.
+func (h *syncHealth) SetShuttingDown() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.shuttingDown = true
+}
+
+// status returns the current health status.
+func (h *syncHealth) status() *healthStatus {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	sinceProgress := time.Since(h.lastProgress)
+	status := &healthStatus{
+		Healthy:              true,
+		Status:               "ok",
+		Phase:                h.phase,
+		Height:               h.height,
+		Target:               h.target,
+		SecondsSinceProgress: sinceProgress.Seconds(),
+	}
+	switch {
+	case h.shuttingDown:
+		status.Healthy, status.Status = false, "shutting down"
+	case h.phase == phaseStoring && h.staleAfter > 0 && sinceProgress > h.staleAfter:
+		status.Healthy, status.Status = false, "stalled"
+	}
+	return status
+}
+
+// ServeHTTP responds with the health status as JSON, with a 200 status code
+// when healthy and 503 otherwise.
+func (h *syncHealth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := h.status()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Warnf("Failed to write health status: %v", err)
+	}
+}
+
+// serveHealth starts an HTTP server for the /healthz endpoint on the given
+// address.
+func serveHealth(addr string, h *syncHealth) {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", h)
+	go func() {
+		log.Infof("Serving health endpoint on http://%s/healthz", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Errorf("Health endpoint server failed: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"time"
+)
+
+// writeHeapProfile writes a heap profile to a new file named with the given
+// path prefix and the current time, returning the name of the file.
+func writeHeapProfile(prefix string) (string, error) {
+	fileName := fmt.Sprintf("%s.%s.heap", prefix,
+		time.Now().Format("20060102-150405.000"))
+	f, err := os.Create(fileName)
+	if err != nil {
+		return "", err
+	}
+	// Close the file even if writing the profile fails so that repeated
+	// requests do not leak descriptors.
+	if err = pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return fileName, f.Close()
+}
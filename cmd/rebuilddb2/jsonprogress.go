@@ -0,0 +1,49 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// progressRecord is a single line of the --jsonprogress output.
+type progressRecord struct {
+	Height         int64   `json:"height"`
+	Target         int64   `json:"target"`
+	Percent        float64 `json:"percent"`
+	BlocksPerSec   float64 `json:"blk_per_sec"`
+	TxPerSec       float64 `json:"tx_per_sec"`
+	VinsPerSec     float64 `json:"vin_per_sec"`
+	VoutsPerSec    float64 `json:"vout_per_sec"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// progressWriter writes progress records as newline-delimited JSON, with each
+// record written by a single call to the underlying writer so that a
+// supervising process can consume it line by line.
+type progressWriter struct {
+	mtx sync.Mutex
+	w   io.Writer
+}
+
+// newProgressWriter creates a new progressWriter for w.
+func newProgressWriter(w io.Writer) *progressWriter {
+	return &progressWriter{w: w}
+}
+
+// Write writes the record as a single line of JSON.
+func (p *progressWriter) Write(rec *progressRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	_, err = p.w.Write(b)
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// runMigrations applies the pending schema migrations of the database, or with
+// --migrate-revert reverts the most recent one, and logs the migration history.
+// With --migrate-dryrun, the database is not modified.
+func runMigrations(cfg *config, dbi *dcrpg.DBInfo, client *rpcclient.Client) error {
+	db, err := dcrpg.Connect(dbi.Host, dbi.Port, dbi.User, dbi.Pass, dbi.DBName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ver, err := dcrpg.DBVersion(db)
+	if err != nil {
+		return fmt.Errorf("unable to read the DB version (are the tables created?): %v", err)
+	}
+	log.Infof("DB version %v", ver)
+
+	// Without a stake DB, the ancient migration to 1.2.0 that evolves the
+	// ticket pool is refused. dcrdata itself can perform it.
+	upgrader := dcrpg.NewUpgrader(context.Background(), db, client, nil)
+	upgrader.EnableDryRun(cfg.MigrateDryRun)
+	upgrader.EnableBackups(cfg.MigrateBackup)
+
+	if cfg.MigrateRevert {
+		prev, err := upgrader.RevertMigration()
+		if err != nil {
+			return err
+		}
+		if !cfg.MigrateDryRun {
+			log.Infof("Reverted DB version %v to %v.", ver, prev)
+		}
+	} else {
+		pending, err := upgrader.PendingMigrations()
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			log.Infof("No pending migrations.")
+		} else if _, err = upgrader.UpgradeDatabase(); err != nil {
+			return err
+		} else if !cfg.MigrateDryRun {
+			log.Infof("Applied %d migrations.", len(pending))
+		}
+	}
+
+	history, err := dcrpg.MigrationHistory(db)
+	if err != nil {
+		// The schema_migrations table is only created with the first migration.
+		log.Debugf("No migration history: %v", err)
+		return nil
+	}
+	log.Infof("Migration history:")
+	for _, rec := range history {
+		status := "applied"
+		if rec.RevertedAt != nil {
+			status = "reverted " + rec.RevertedAt.Format("2006-01-02 15:04:05")
+		}
+		var backups string
+		if len(rec.BackupTables) > 0 {
+			backups = ", backups: " + strings.Join(rec.BackupTables, ", ")
+		}
+		log.Infof("    %s %s (%v%s), %s: %s", rec.AppliedAt.Format("2006-01-02 15:04:05"),
+			rec.Version, rec.Duration, backups, status, rec.Description)
+	}
+	return nil
+}
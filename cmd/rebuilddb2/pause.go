@@ -0,0 +1,63 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"sync"
+)
+
+// syncPauser is used to temporarily halt the block store loop without
+// shutting down. The loop checks Paused before storing each block, and Wait
+// blocks until the sync is resumed or quit is closed.
+type syncPauser struct {
+	mtx    sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// newSyncPauser creates a new syncPauser in the running (unpaused) state.
+func newSyncPauser() *syncPauser {
+	return &syncPauser{}
+}
+
+// Toggle switches between the paused and running states, and returns true if
+// the sync is now paused.
+func (p *syncPauser) Toggle() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.paused {
+		p.paused = false
+		close(p.resume)
+		return false
+	}
+	p.paused = true
+	p.resume = make(chan struct{})
+	return true
+}
+
+// Paused indicates if the sync is presently paused.
+func (p *syncPauser) Paused() bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.paused
+}
+
+// Wait blocks while the sync is paused. It returns false if quit was closed
+// before the sync was resumed.
+func (p *syncPauser) Wait(quit <-chan struct{}) bool {
+	p.mtx.Lock()
+	if !p.paused {
+		p.mtx.Unlock()
+		return true
+	}
+	resume := p.resume
+	p.mtx.Unlock()
+
+	select {
+	case <-resume:
+		return true
+	case <-quit:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"time"
+)
+
+// Names of the timed phases of a rebuild, in the order they run.
+const (
+	timedStakeDBRewind   = "stakedb_rewind"
+	timedStakeDBAdvance  = "stakedb_advance"
+	timedBlockStore      = "block_store"
+	timedDedup           = "dedup"
+	timedIndexing        = "indexing"
+	timedAddrSpendInfo   = "address_spendinfo"
+	timedTicketSpendInfo = "ticket_spendinfo"
+	timedChainWorkVerify = "chainwork_verify"
+)
+
+// phaseTiming is the wall-clock duration of one phase of a rebuild.
+type phaseTiming struct {
+	Phase   string  `json:"phase"`
+	Seconds float64 `json:"seconds"`
+	// Completed is false if the phase was still running when the timings
+	// were collected, such as when the rebuild was interrupted.
+	Completed bool `json:"completed"`
+}
+
+// phaseTimer times the sequential phases of a rebuild. It is not safe for
+// concurrent use.
+type phaseTimer struct {
+	current string
+	start   time.Time
+	done    []phaseTiming
+}
+
+// Start stops the current phase, if any, and starts timing the named phase.
+func (p *phaseTimer) Start(phase string) {
+	p.Stop()
+	p.current, p.start = phase, time.Now()
+}
+
+// Stop stops timing the current phase, if any, recording it as completed.
+func (p *phaseTimer) Stop() {
+	if p.current == "" {
+		return
+	}
+	p.done = append(p.done, phaseTiming{
+		Phase:     p.current,
+		Seconds:   time.Since(p.start).Seconds(),
+		Completed: true,
+	})
+	p.current = ""
+}
+
+// Timings returns the timings of the completed phases, followed by the elapsed
+// time of the current phase, if any, which is marked as not completed.
+func (p *phaseTimer) Timings() []phaseTiming {
+	timings := make([]phaseTiming, len(p.done), len(p.done)+1)
+	copy(timings, p.done)
+	if p.current != "" {
+		timings = append(timings, phaseTiming{
+			Phase:   p.current,
+			Seconds: time.Since(p.start).Seconds(),
+		})
+	}
+	return timings
+}
+
+// logSummary writes the phase timings to the log.
+func (p *phaseTimer) logSummary() {
+	timings := p.Timings()
+	if len(timings) == 0 {
+		return
+	}
+	log.Infof("Phase timing breakdown:")
+	for _, t := range timings {
+		status := ""
+		if !t.Completed {
+			status = " (interrupted)"
+		}
+		log.Infof("  %-18s %10.1f s%s", t.Phase, t.Seconds, status)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"time"
+)
+
+// emaSmoothing is the weight given to the newest sample by rateEMA. Lower
+// values produce a steadier estimate when throughput swings between the
+// deindexed bulk load and the slower indexed inserts.
+const emaSmoothing = 0.2
+
+// rateEMA is an exponential moving average of a rate, such as blocks per
+// second.
+type rateEMA struct {
+	alpha  float64
+	value  float64
+	primed bool
+}
+
+// newRateEMA creates a new rateEMA with the given smoothing factor, which
+// should be in (0,1].
+func newRateEMA(alpha float64) *rateEMA {
+	return &rateEMA{alpha: alpha}
+}
+
+// Update incorporates a new sample and returns the smoothed rate. The first
+// sample initializes the average.
+func (r *rateEMA) Update(sample float64) float64 {
+	if !r.primed {
+		r.value = sample
+		r.primed = true
+		return r.value
+	}
+	r.value = r.alpha*sample + (1-r.alpha)*r.value
+	return r.value
+}
+
+// Rate returns the current smoothed rate.
+func (r *rateEMA) Rate() float64 {
+	return r.value
+}
+
+// estimateETA computes the time required to process remaining blocks at the
+// given rate. A negative duration is returned if the rate is not positive.
+func estimateETA(remaining int64, blocksPerSec float64) time.Duration {
+	if blocksPerSec <= 0 {
+		return -1
+	}
+	if remaining <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) / blocksPerSec * float64(time.Second))
+}
+
+// percentComplete returns the percentage of the chain, up to the target
+// height, that has been processed.
+func percentComplete(height, target int64) float64 {
+	if target <= 0 {
+		return 100
+	}
+	pct := 100 * float64(height) / float64(target)
+	if pct > 100 {
+		pct = 100
+	}
+	return pct
+}
+
+// etaString formats an ETA for the log, rounding to the nearest second.
+func etaString(eta time.Duration) string {
+	if eta < 0 {
+		return "unknown"
+	}
+	return eta.Round(time.Second).String()
+}
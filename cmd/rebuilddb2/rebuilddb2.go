@@ -18,10 +18,10 @@ import (
 
 	"github.com/decred/dcrd/rpcclient/v5"
 	"github.com/decred/dcrdata/db/dcrpg/v5"
+	"github.com/decred/dcrdata/gov/v3/politeia/pisync"
 	"github.com/decred/dcrdata/rpcutils/v3"
 	"github.com/decred/dcrdata/stakedb/v3"
 	"github.com/decred/slog"
-	"github.com/dmigwi/go-piparser/proposals"
 )
 
 var (
@@ -58,6 +58,12 @@ func mainCore() error {
 		return err
 	}
 
+	// The health endpoint reports progress to orchestrators.
+	health := newSyncHealth(cfg.HealthStaleness)
+	if cfg.HealthListen != "" {
+		serveHealth(cfg.HealthListen, health)
+	}
+
 	if cfg.HTTPProfile {
 		go func() {
 			log.Infoln(http.ListenAndServe("localhost:6060", nil))
@@ -75,19 +81,9 @@ func mainCore() error {
 		defer pprof.StopCPUProfile()
 	}
 
+	// Heap profiles are written on demand via SIGUSR2.
 	if cfg.MemProfile != "" {
-		var f *os.File
-		f, err = os.Create(cfg.MemProfile)
-		if err != nil {
-			log.Fatal(err)
-			return err
-		}
-		timer := time.NewTimer(time.Second * 15)
-		go func() {
-			<-timer.C
-			pprof.WriteHeapProfile(f)
-			f.Close()
-		}()
+		listenHeapProfileSignal(cfg.MemProfile)
 	}
 
 	// Connect to node RPC server
@@ -123,17 +119,17 @@ func mainCore() error {
 		DBName: cfg.DBName,
 	}
 
-	log.Infof("Setting up the Politeia's proposals clone repository. Please wait...")
-
-	// repoName and repoOwner are set to empty string so that the defaults can be used.
-	parser, err := proposals.NewParser("", "", cfg.LogDir)
+	// The proposals fetcher is not run, but it is needed to sync the proposal
+	// votes if the legacy upgrades are performed.
+	piFetcher, err := pisync.NewFetcher(pisync.DefaultURL, 0)
 	if err != nil {
 		return err
 	}
 
-	var piParser dcrpg.ProposalsFetcher
-	if parser != nil {
-		piParser = parser
+	// Apply or revert schema migrations without the automatic upgrade done by
+	// NewChainDB, so they may be dry run.
+	if cfg.MigrateOnly || cfg.MigrateRevert {
+		return runMigrations(cfg, &dbi, client)
 	}
 
 	// Construct a ChainDB without a stakeDB to allow quick dropping of tables.
@@ -145,9 +141,12 @@ func mainCore() error {
 		AddrCacheAddrCap:     2,
 		AddrCacheRowCap:      2,
 		AddrCacheUTXOByteCap: 1 << 5,
+		VinBatchSize:         cfg.VinBatchSize,
+		VoutBatchSize:        cfg.VoutBatchSize,
+		CopyThreshold:        cfg.CopyThreshold,
 	}
 	mpChecker := rpcutils.NewMempoolAddressChecker(client, activeChain)
-	db, err := dcrpg.NewChainDB(dbCfg, nil, mpChecker, piParser, client, func() {})
+	db, err := dcrpg.NewChainDB(dbCfg, nil, mpChecker, piFetcher, client, func() {})
 	if db != nil {
 		defer db.Close()
 	}
@@ -160,14 +159,58 @@ func mainCore() error {
 		return nil
 	}
 
+	// Drop only the selected tables.
+	if len(cfg.DropTable) > 0 {
+		dropped, err := db.DropTablesByName(cfg.DropTable)
+		if len(dropped) > 0 {
+			log.Infof("Dropped tables: %s", strings.Join(dropped, ", "))
+		}
+		return err
+	}
+
+	// Populate the spending info of an already synced DB, skipping the sync.
+	if cfg.OnlySpendInfo != "" {
+		return runSpendInfoOnly(db, cfg.OnlySpendInfo, cfg.AddrSpendIncremental, health)
+	}
+
+	// Report the scope of a sync and exit without storing anything.
+	if cfg.Estimate {
+		est, err := estimateSync(db, client, cfg.ForceReindex)
+		if err != nil {
+			return err
+		}
+		est.logSummary()
+		return nil
+	}
+
+	// Export the UTXO set and exit without syncing.
+	if cfg.DumpUTXO != "" {
+		return dumpUTXOSet(db, cfg.DumpUTXO, cfg.DumpUTXOHeight)
+	}
+
 	// Create/load stake database (which includes the separate ticket pool DB).
 	sdbDir := "rebuild_data"
+	if cfg.StakeDBSnapshotImport != "" {
+		if err = importStakeDBSnapshot(cfg.StakeDBSnapshotImport, sdbDir); err != nil {
+			return err
+		}
+	}
 	stakeDB, stakeDBHeight, err := stakedb.NewStakeDatabase(client, activeChain, sdbDir)
 	if err != nil {
 		log.Errorf("Unable to create stake DB: %v", err)
 		if stakeDBHeight >= 0 {
-			log.Infof("Attempting to recover stake DB...")
-			stakeDB, err = stakedb.LoadAndRecover(client, activeChain, sdbDir, stakeDBHeight-288)
+			recoverTo := stakeDBHeight - cfg.StakeDBRecoverWindow
+			log.Infof("Attempting to recover stake DB, rewinding %d blocks from %d to %d...",
+				cfg.StakeDBRecoverWindow, stakeDBHeight, recoverTo)
+			stakeDB, err = stakedb.LoadAndRecover(client, activeChain, sdbDir, recoverTo)
+			if err != nil {
+				if stakeDB != nil {
+					_ = stakeDB.Close()
+				}
+				return fmt.Errorf("StakeDatabase recovery failed after rewinding %d "+
+					"blocks from %d to %d (try a larger --stakedbrecoverwindow): %v",
+					cfg.StakeDBRecoverWindow, stakeDBHeight, recoverTo, err)
+			}
 			stakeDBHeight = int64(stakeDB.Height())
 		}
 		if err != nil {
@@ -181,6 +224,11 @@ func mainCore() error {
 
 	log.Infof("Loaded StakeDatabase at height %d", stakeDBHeight)
 
+	if cfg.StakeDBSnapshotExport != "" {
+		return exportStakeDBSnapshot(stakeDB, cfg.StakeDBSnapshotExport,
+			cfg.StakeDBSnapshotHeight)
+	}
+
 	// Provide the stake database to the ChainDB for all of it's ticket tracking
 	// needs.
 	db.UseStakeDB(stakeDB)
@@ -206,16 +254,47 @@ func mainCore() error {
 		log.Info("tables are empty, starting fresh.")
 	}
 
+	// An interrupted rebuild records the phase it reached.
+	syncState, err := db.SyncState()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve sync state: %v", err)
+	}
+	if syncState != nil {
+		log.Infof("Resuming an interrupted rebuild from phase %q at height %d "+
+			"(last updated %v).", syncState.Phase, syncState.Height,
+			syncState.UpdatedAt.Format(time.RFC3339))
+	}
+
+	// Refuse to sync when the chain DB and stake DB heights disagree by more
+	// than expected, unless forced.
+	if err = checkHeightAgreement(lastBlock, stakeDBHeight, cfg.MaxHeightGap); err != nil {
+		if !cfg.Force {
+			return err
+		}
+		log.Warnf("Proceeding due to --force: %v", err)
+	}
+
 	// Start waiting for the interrupt signal
 	go func() {
 		<-c
 		signal.Stop(c)
 		// Close the channel so multiple goroutines can get the message
 		log.Infof("CTRL+C hit.  Closing goroutines. Please wait.")
+		health.SetShuttingDown()
 		close(quit)
 	}()
 
+	// SIGUSR1 toggles pausing of the block store loop.
+	pauser := newSyncPauser()
+	listenPauseSignal(pauser, quit)
+
+	// Time each phase, logging the breakdown even if interrupted.
+	phases := new(phaseTimer)
+	defer phases.logSummary()
+
 	// Get stakedb at PG DB height
+	health.SetPhase(phaseStakeDB)
+	phases.Start(timedStakeDBRewind)
 	var rewindTo int64
 	if lastBlock > 0 {
 		// Rewind one extra block to ensure previous winning tickets (validators
@@ -240,6 +319,7 @@ func mainCore() error {
 	}
 
 	// Advance to last block, but don't log if it's just one block to connect
+	phases.Start(timedStakeDBAdvance)
 	if stakeDBHeight+1 < lastBlock {
 		log.Infof("Advancing stake db from %d to %d...", stakeDBHeight, lastBlock)
 	}
@@ -270,21 +350,37 @@ func mainCore() error {
 	db.InBatchSync = true
 	defer func() { db.InBatchSync = false }()
 
-	var totalTxs, totalVins, totalVouts int64
+	// The running totals are updated by the storage goroutine.
+	totals := &syncTotals{height: lastBlock}
 	var lastTxs, lastVins, lastVouts int64
 	tickTime := 10 * time.Second
 	ticker := time.NewTicker(tickTime)
+	blockRate := newRateEMA(emaSmoothing)
 	startTime := time.Now()
 	o := sync.Once{}
+	report := &syncReport{
+		StartHeight: lastBlock + 1,
+		EndHeight:   lastBlock,
+	}
+	if cfg.Report != "" {
+		defer func() {
+			report.Phases = phases.Timings()
+			if err := report.writeFile(cfg.Report); err != nil {
+				log.Errorf("Failed to write sync report: %v", err)
+				return
+			}
+			log.Infof("Wrote sync report to %s.", cfg.Report)
+		}()
+	}
 	speedReporter := func() {
 		ticker.Stop()
-		totalElapsed := time.Since(startTime).Seconds()
-		if int64(totalElapsed) == 0 {
+		var totalTxs, totalVins, totalVouts int64
+		report.EndHeight, totalTxs, totalVins, totalVouts = totals.load()
+		report.setTotals(time.Since(startTime), totalTxs, totalVins, totalVouts)
+		if int64(report.DurationSeconds) == 0 {
 			return
 		}
-		totalVoutPerSec := totalVouts / int64(totalElapsed)
-		totalTxPerSec := totalTxs / int64(totalElapsed)
-		log.Infof("Avg. speed: %d tx/s, %d vout/s", totalTxPerSec, totalVoutPerSec)
+		log.Infof("Avg. speed: %d tx/s, %d vout/s", report.AvgTxPerSec, report.AvgVoutPerSec)
 	}
 	speedReport := func() { o.Do(speedReporter) }
 	defer speedReport()
@@ -295,21 +391,104 @@ func mainCore() error {
 		return fmt.Errorf("GetBestBlock failed: %v", err)
 	}
 
-	// Remove indexes/constraints before bulk import
-	blocksToSync := height - lastBlock
-	reindexing := blocksToSync > height/2
+	// Remove indexes/constraints before bulk import. A resumed bulk import
+	// must finish without indexes and recreate them, no matter how many blocks
+	// remain.
+	reindexing := useBulkReindex(lastBlock, height) || resumeBulkReindex(syncState)
+	report.BulkReindex = reindexing || cfg.ForceReindex
+	checkpoint := &syncCheckpointer{db: db, bulkReindex: report.BulkReindex}
 	if reindexing || cfg.ForceReindex {
-		log.Info("Large bulk load: Removing indexes and disabling duplicate checks.")
-		err = db.DeindexAll()
-		if err != nil && !strings.Contains(err.Error(), "does not exist") {
-			return err
+		if indexesDropped(syncState) {
+			log.Info("Resuming bulk load: indexes were already removed. Disabling duplicate checks.")
+		} else {
+			log.Info("Large bulk load: Removing indexes and disabling duplicate checks.")
+			err = db.DeindexAll()
+			if err != nil && !strings.Contains(err.Error(), "does not exist") {
+				return err
+			}
+			if err = checkpoint.Set(syncPhaseDeindexed, lastBlock); err != nil {
+				return err
+			}
 		}
 		db.EnableDuplicateCheckOnInsert(false)
 	} else {
 		db.EnableDuplicateCheckOnInsert(true)
 	}
 
+	// The hash of the last fetched block is used to detect a reorg on the node
+	// while syncing.
+	var prevHash string
+	if lastBlock >= 0 {
+		if prevHash, err = db.HashDB(); err != nil {
+			return fmt.Errorf("HashDB failed: %v", err)
+		}
+	}
+
+	// Fetched blocks are stored sequentially by a separate goroutine so that
+	// fetching the next blocks overlaps with storing them. The deferred Finish
+	// ensures the storage goroutine has returned before the totals are reported.
+	storer := newBlockStorer(db, cfg, totals, health, pauser, quit)
+	defer func() { _ = storer.Finish() }()
+
+	// A block's validity is set by the stakeholder votes in the following
+	// block, so each fetched block is held as pending until the next block is
+	// fetched and its header vote bits are known.
+	var pending *fetchedBlock
+	// queuePending queues the pending block for storage. It returns false if
+	// the storage goroutine has stopped, along with any storage error.
+	queuePending := func() (bool, error) {
+		if pending == nil {
+			return true, nil
+		}
+		fb := pending
+		pending = nil
+		if storer.Queue(fb) {
+			return true, nil
+		}
+		// The storage goroutine stopped due to an error or quit.
+		return false, storer.Finish()
+	}
+
+	// Progress is streamed as newline-delimited JSON if requested, with the
+	// rates from the most recent ticker tick.
+	var progress *progressWriter
+	if cfg.JSONProgress {
+		progress = newProgressWriter(os.NewFile(uintptr(cfg.JSONProgressFD), "jsonprogress"))
+	}
+	var lastRates progressRecord
+	emitProgress := func() {
+		if progress == nil {
+			return
+		}
+		rec := lastRates
+		rec.Height, _, _, _ = totals.load()
+		rec.Target = height
+		rec.Percent = percentComplete(rec.Height, height)
+		rec.ElapsedSeconds = time.Since(startTime).Seconds()
+		if err := progress.Write(&rec); err != nil {
+			log.Warnf("Failed to write JSON progress: %v", err)
+		}
+	}
+
+	if report.BulkReindex {
+		if err = checkpoint.Set(syncPhaseBulkLoading, lastBlock); err != nil {
+			return err
+		}
+	}
+
+	// Blocks are fetched concurrently by a pool of RPC workers, and consumed
+	// here in height order. The deferred Stop waits for in-progress fetches.
+	var fetcher *blockFetcher
+	defer func() {
+		if fetcher != nil {
+			fetcher.Stop()
+		}
+	}()
+
 	startHeight := lastBlock + 1
+	health.SetHeight(lastBlock, height)
+	health.SetPhase(phaseStoring)
+	phases.Start(timedBlockStore)
 	for ib := startHeight; ib <= height; ib++ {
 		// check for quit signal
 		select {
@@ -329,47 +508,123 @@ func mainCore() error {
 				}
 				log.Infof("Processing blocks %d to %d...", ib, endRangeBlock)
 			}
+			emitProgress()
 		}
 		select {
 		case <-ticker.C:
-			blocksPerSec := float64(ib-lastBlock) / tickTime.Seconds()
+			storedHeight, totalTxs, totalVins, totalVouts := totals.load()
+			blocksPerSec := float64(storedHeight-lastBlock) / tickTime.Seconds()
 			txPerSec := float64(totalTxs-lastTxs) / tickTime.Seconds()
 			vinsPerSec := float64(totalVins-lastVins) / tickTime.Seconds()
 			voutPerSec := float64(totalVouts-lastVouts) / tickTime.Seconds()
-			log.Infof("(%3d blk/s,%5d tx/s,%5d vin/sec,%5d vout/s)", int64(blocksPerSec),
-				int64(txPerSec), int64(vinsPerSec), int64(voutPerSec))
-			lastBlock, lastTxs = ib, totalTxs
+			eta := estimateETA(height-storedHeight, blockRate.Update(blocksPerSec))
+			log.Infof("(%3d blk/s,%5d tx/s,%5d vin/sec,%5d vout/s) %.2f%% complete, ETA %s",
+				int64(blocksPerSec), int64(txPerSec), int64(vinsPerSec),
+				int64(voutPerSec), percentComplete(storedHeight, height), etaString(eta))
+			lastBlock, lastTxs = storedHeight, totalTxs
 			lastVins, lastVouts = totalVins, totalVouts
+			lastRates = progressRecord{
+				BlocksPerSec: blocksPerSec,
+				TxPerSec:     txPerSec,
+				VinsPerSec:   vinsPerSec,
+				VoutsPerSec:  voutPerSec,
+			}
+			emitProgress()
 		default:
 		}
 
-		block, blockHash, err := rpcutils.GetBlock(ib, client)
-		if err != nil {
-			return fmt.Errorf("GetBlock failed (%s): %v", blockHash, err)
+		// Blocks are fetched ahead by the fetcher's workers up to the node
+		// height when it was started. Start a new fetcher for any blocks
+		// mined since, or after a reorg abandoned the previous fetcher.
+		if fetcher == nil || !fetcher.Covers(ib) {
+			if fetcher != nil {
+				fetcher.Stop()
+			}
+			fetcher = newBlockFetcher(client, ib, height, cfg.FetchWorkers, quit)
+		}
+		res := fetcher.Next()
+		if res == nil {
+			log.Infof("Rescan cancelled at height %d.", ib)
+			return nil
 		}
+		if res.err != nil {
+			return res.err
+		}
+		block, blockHash := res.block, res.hash
+
+		// Ensure this block extends the last fetched block. If not, the node's
+		// chain was reorganized after that block was fetched, so store the
+		// queued blocks, rewind to the common ancestor, and resume from there.
+		if ib > 0 && block.MsgBlock().Header.PrevBlock.String() != prevHash {
+			// The pending block is on the abandoned chain and will be purged,
+			// but it must be stored so the rewind starts from the fetch height.
+			if pending != nil {
+				pending.isValid = true
+			}
+			if ok, err := queuePending(); !ok {
+				if err != nil {
+					return err
+				}
+				log.Infof("Rescan cancelled at height %d.", ib)
+				return nil
+			}
+			if err = storer.Finish(); err != nil {
+				return err
+			}
+			select {
+			case <-quit:
+				log.Infof("Rescan cancelled at height %d.", ib)
+				return nil
+			default:
+			}
 
-		// Grab the chainwork.
-		chainWork, err := rpcutils.GetChainWork(client, blockHash)
-		if err != nil {
-			return fmt.Errorf("GetChainWork failed (%s): %v", blockHash, err)
+			ancestor, cancelled, err := findCommonAncestor(db, client, ib-1, quit)
+			if err != nil {
+				return fmt.Errorf("unable to find common ancestor after reorg: %v", err)
+			}
+			if cancelled {
+				log.Infof("Reorg rewind cancelled at height %d.", ib)
+				return nil
+			}
+			depth := ib - 1 - ancestor
+			log.Warnf("Chain reorganization of depth %d detected at height %d. "+
+				"Rewinding to common ancestor at height %d.", depth, ib, ancestor)
+			if _, _, err = db.PurgeBestBlocks(depth); err != nil {
+				return fmt.Errorf("PurgeBestBlocks failed: %v", err)
+			}
+			if prevHash, err = db.HashDB(); err != nil {
+				return fmt.Errorf("HashDB failed: %v", err)
+			}
+			totals.setHeight(ancestor)
+			lastBlock = ancestor
+			storer = newBlockStorer(db, cfg, totals, health, pauser, quit)
+			// The fetched blocks after this one are also from the abandoned
+			// chain. The loop increment resumes at the block after the ancestor.
+			fetcher.Stop()
+			fetcher = nil
+			ib = ancestor
+			continue
 		}
 
-		var numVins, numVouts int64
-		isValid, isMainchain, updateExistingRecords := true, true, true
-		numVins, numVouts, _, err = db.StoreBlock(block.MsgBlock(), isValid,
-			isMainchain, updateExistingRecords, cfg.AddrSpendInfoOnline,
-			!cfg.TicketSpendInfoBatch, chainWork)
-		if err != nil {
-			return fmt.Errorf("StoreBlock failed: %v", err)
+		// This block's vote bits indicate if the pending previous block was
+		// approved by stakeholders, so it may now be queued for storage.
+		if pending != nil {
+			pending.isValid = parentApproved(block)
+		}
+		if ok, err := queuePending(); !ok {
+			if err != nil {
+				return err
+			}
+			log.Infof("Rescan cancelled at height %d.", ib)
+			return nil
 		}
-		totalVins += numVins
-		totalVouts += numVouts
 
-		numSTx := int64(len(block.STransactions()))
-		numRTx := int64(len(block.Transactions()))
-		totalTxs += numRTx + numSTx
-		// totalRTxs += numRTx
-		// totalSTxs += numSTx
+		pending = &fetchedBlock{
+			block:     block,
+			chainWork: res.chainWork,
+			target:    height,
+		}
+		prevHash = blockHash.String()
 
 		// update height, the end condition for the loop
 		if _, height, err = client.GetBestBlock(); err != nil {
@@ -377,58 +632,120 @@ func mainCore() error {
 		}
 	}
 
+	// The validity of the block at the tip is not known until the node has the
+	// next block. Store it as valid, which is corrected by StoreBlock's update
+	// of the previous block when the next block is stored by dcrndata.
+	if pending != nil {
+		pending.isValid, pending.provisional = true, true
+		report.TipValidityPending = true
+		if ok, err := queuePending(); !ok {
+			if err != nil {
+				return err
+			}
+			log.Infof("Rescan cancelled at height %d.", height)
+			return nil
+		}
+	}
+
+	// Wait for the queued blocks to be stored.
+	if err = storer.Finish(); err != nil {
+		return err
+	}
+	select {
+	case <-quit:
+		storedHeight, _, _, _ := totals.load()
+		log.Infof("Rescan cancelled after height %d.", storedHeight)
+		return nil
+	default:
+	}
+
+	phases.Stop()
 	speedReport()
 
+	storedHeight, _, _, _ := totals.load()
+	health.SetPhase(phaseIndexing)
 	if reindexing || cfg.ForceReindex {
+		if err = checkpoint.Set(syncPhaseReindexing, storedHeight); err != nil {
+			return err
+		}
+		// Indexes partially recreated by an interrupted rebuild are dropped
+		// so that they may all be created again.
+		if syncState != nil && syncState.Phase == syncPhaseReindexing {
+			err = db.DeindexAll()
+			if err != nil && !strings.Contains(err.Error(), "does not exist") {
+				return err
+			}
+		}
+
+		phases.Start(timedDedup)
 		if err = db.DeleteDuplicates(nil); err != nil {
 			return err
 		}
 
 		// Create indexes
+		phases.Start(timedIndexing)
 		if err = db.IndexAll(nil); err != nil {
 			return fmt.Errorf("IndexAll failed: %v", err)
 		}
 		// Only reindex address table here if we do not do it below
-		if cfg.AddrSpendInfoOnline {
-			err = db.IndexAddressTable(nil)
+		if cfg.AddrSpendInfoOnline || cfg.AddrSpendIncremental {
+			if err = db.IndexAddressTable(nil); err != nil {
+				return fmt.Errorf("IndexAddressTable failed: %v", err)
+			}
 		}
 		if !cfg.TicketSpendInfoBatch {
-			err = db.IndexTicketsTable(nil)
+			if err = db.IndexTicketsTable(nil); err != nil {
+				return fmt.Errorf("IndexTicketsTable failed: %v", err)
+			}
 		}
 	}
 
+	health.SetPhase(phaseSpendInfo)
+	if err = checkpoint.Set(syncPhaseSpendUpdate, storedHeight); err != nil {
+		return err
+	}
 	if !cfg.AddrSpendInfoOnline {
-		// Remove indexes not on funding txns (remove on address table indexes)
-		_ = db.DeindexAddressTable() // ignore errors for non-existent indexes
-		db.EnableDuplicateCheckOnInsert(false)
-		log.Infof("Populating spending tx info in address table...")
-		numAddresses, err := db.UpdateSpendingInfoInAllAddresses(nil)
-		if err != nil {
-			log.Errorf("UpdateSpendingInfoInAllAddresses FAILED: %v", err)
-		}
-		// Index address table
-		log.Infof("Updated %d rows of address table", numAddresses)
-		if err = db.IndexAddressTable(nil); err != nil {
-			log.Errorf("IndexAddressTable FAILED: %v", err)
+		phases.Start(timedAddrSpendInfo)
+		if err = updateAddressSpendInfo(db, cfg.AddrSpendIncremental); err != nil {
+			return err
 		}
 	}
 
 	if cfg.TicketSpendInfoBatch {
-		// Remove indexes not on funding txns (remove on address table indexes)
-		_ = db.DeindexTicketsTable() // ignore errors for non-existent indexes
-		db.EnableDuplicateCheckOnInsert(false)
-		log.Infof("Populating spending tx info in tickets table...")
-		numTicketsUpdated, err := db.UpdateSpendingInfoInAllTickets()
+		phases.Start(timedTicketSpendInfo)
+		if err = updateTicketSpendInfo(db); err != nil {
+			return err
+		}
+	}
+
+	if cfg.VerifyChainWork {
+		phases.Start(timedChainWorkVerify)
+		log.Infof("Verifying stored chainwork through height %d (every %d blocks)...",
+			height, cfg.VerifyChainWorkStep)
+		checked, mismatches, cancelled, err := verifyChainWork(db, client,
+			height, cfg.VerifyChainWorkStep, quit)
 		if err != nil {
-			log.Errorf("UpdateSpendingInfoInAllTickets FAILED: %v", err)
+			return fmt.Errorf("chainwork verification failed: %v", err)
+		}
+		if cancelled {
+			log.Infof("Chainwork verification cancelled after %d blocks.", checked)
+			return nil
 		}
-		// Index tickets table
-		log.Infof("Updated %d rows of address table", numTicketsUpdated)
-		if err = db.IndexTicketsTable(nil); err != nil {
-			log.Errorf("IndexTicketsTable FAILED: %v", err)
+		if len(mismatches) > 0 {
+			return fmt.Errorf("stored chainwork differs from the node's for %d "+
+				"of %d checked blocks", len(mismatches), checked)
 		}
+		log.Infof("Verified chainwork of %d blocks.", checked)
+	}
+	phases.Stop()
+
+	if err = checkpoint.Clear(); err != nil {
+		return err
 	}
 
+	report.Completed = true
+	health.SetPhase(phaseDone)
+	_, totalTxs, totalVins, totalVouts := totals.load()
 	log.Infof("Rebuild finished at height %d. Delta: %d blocks, %d transactions, %d ins, %d outs",
 		height, height-startHeight+1, totalTxs, totalVins, totalVouts)
 
@@ -0,0 +1,40 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// findCommonAncestor walks back from fromHeight, comparing the block hashes
+// stored in the DB with those of the node's main chain, and returns the height
+// of the most recent block on which they agree. If quit is closed before the
+// common ancestor is found, cancelled is true.
+func findCommonAncestor(db *dcrpg.ChainDB, client *rpcclient.Client,
+	fromHeight int64, quit <-chan struct{}) (height int64, cancelled bool, err error) {
+	for height = fromHeight; height >= 0; height-- {
+		select {
+		case <-quit:
+			return height, true, nil
+		default:
+		}
+
+		var dbHash string
+		dbHash, err = db.BlockHash(height)
+		if err != nil {
+			return height, false, fmt.Errorf("BlockHash(%d) failed: %v", height, err)
+		}
+		nodeHash, err := client.GetBlockHash(height)
+		if err != nil {
+			return height, false, fmt.Errorf("GetBlockHash(%d) failed: %v", height, err)
+		}
+		if nodeHash.String() == dbHash {
+			return height, false, nil
+		}
+	}
+	return -1, false, fmt.Errorf("no common ancestor found")
+}
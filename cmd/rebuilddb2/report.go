@@ -0,0 +1,52 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// syncReport is the machine-readable summary of a rebuild, written to the file
+// specified by the --report option.
+type syncReport struct {
+	StartHeight     int64   `json:"start_height"`
+	EndHeight       int64   `json:"end_height"`
+	BlocksProcessed int64   `json:"blocks_processed"`
+	Transactions    int64   `json:"transactions"`
+	Vins            int64   `json:"vins"`
+	Vouts           int64   `json:"vouts"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	AvgTxPerSec     int64   `json:"avg_tx_per_sec"`
+	AvgVoutPerSec   int64   `json:"avg_vout_per_sec"`
+	BulkReindex     bool    `json:"bulk_reindex"`
+	// TipValidityPending indicates that the final block was stored as valid
+	// before the block voting on its validity was available.
+	TipValidityPending bool `json:"tip_validity_pending"`
+	// Phases is the wall-clock time spent in each phase of the rebuild.
+	Phases    []phaseTiming `json:"phases,omitempty"`
+	Completed bool          `json:"completed"`
+}
+
+// setTotals records the totals and elapsed time of the block store loop, and
+// computes the average rates in the same way they are logged.
+func (r *syncReport) setTotals(elapsed time.Duration, txs, vins, vouts int64) {
+	r.Transactions, r.Vins, r.Vouts = txs, vins, vouts
+	r.DurationSeconds = elapsed.Seconds()
+	if secs := int64(elapsed.Seconds()); secs > 0 {
+		r.AvgTxPerSec = txs / secs
+		r.AvgVoutPerSec = vouts / secs
+	}
+	r.BlocksProcessed = r.EndHeight - r.StartHeight + 1
+}
+
+// writeFile writes the report as JSON to the specified file.
+func (r *syncReport) writeFile(path string) error {
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"time"
+)
+
+// Parameters of the exponential backoff used by withRetry.
+const (
+	rpcRetryAttempts = 5
+	rpcRetryBaseWait = 500 * time.Millisecond
+	rpcRetryMaxWait  = 10 * time.Second
+)
+
+// withRetry calls fn until it succeeds or rpcRetryAttempts calls have failed,
+// waiting with exponential backoff between attempts. The error from the final
+// attempt is returned. If quit is closed while waiting, withRetry returns
+// immediately with cancelled set and the most recent error.
+func withRetry(quit <-chan struct{}, what string, fn func() error) (cancelled bool, err error) {
+	wait := rpcRetryBaseWait
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt == rpcRetryAttempts {
+			return false, err
+		}
+		log.Warnf("%s failed (attempt %d of %d), retrying in %v: %v", what,
+			attempt, rpcRetryAttempts, wait, err)
+
+		select {
+		case <-quit:
+			return true, err
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+		if wait > rpcRetryMaxWait {
+			wait = rpcRetryMaxWait
+		}
+	}
+}
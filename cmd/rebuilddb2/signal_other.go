@@ -0,0 +1,24 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package main
+
+import "time"
+
+// listenHeapProfileSignal writes a single heap profile after 15 seconds on
+// platforms without SIGUSR2.
+func listenHeapProfileSignal(prefix string) {
+	time.AfterFunc(15*time.Second, func() {
+		fileName, err := writeHeapProfile(prefix)
+		if err != nil {
+			log.Errorf("Failed to write heap profile: %v", err)
+			return
+		}
+		log.Infof("Wrote heap profile to %s.", fileName)
+	})
+}
+
+// listenPauseSignal is a no-op on platforms without SIGUSR1.
+func listenPauseSignal(p *syncPauser, quit <-chan struct{}) {}
@@ -0,0 +1,51 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// listenHeapProfileSignal writes a timestamped heap profile, with file names
+// beginning with prefix, each time SIGUSR2 is received.
+func listenHeapProfileSignal(prefix string) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR2)
+	go func() {
+		for range c {
+			fileName, err := writeHeapProfile(prefix)
+			if err != nil {
+				log.Errorf("Failed to write heap profile: %v", err)
+				continue
+			}
+			log.Infof("SIGUSR2 received. Wrote heap profile to %s.", fileName)
+		}
+	}()
+}
+
+// listenPauseSignal toggles the paused state of the sync each time SIGUSR1 is
+// received, until quit is closed.
+func listenPauseSignal(p *syncPauser, quit <-chan struct{}) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-c:
+				if p.Toggle() {
+					log.Infof("SIGUSR1 received. Pausing sync after the current block.")
+				} else {
+					log.Infof("SIGUSR1 received. Resuming sync.")
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/decred/dcrdata/stakedb/v3"
+)
+
+// exportStakeDBSnapshot writes a snapshot of the stake DB at the height, or at
+// the stake DB height if height is negative, to the file. The snapshot is
+// written to a temporary file that is renamed when complete.
+func exportStakeDBSnapshot(stakeDB *stakedb.StakeDatabase, fileName string, height int64) error {
+	if height < 0 {
+		height = int64(stakeDB.Height())
+	}
+
+	tmpName := fileName + ".tmp"
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return err
+	}
+	if err = stakeDB.ExportSnapshot(f, height); err != nil {
+		f.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to export stake DB snapshot: %v", err)
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err = os.Rename(tmpName, fileName); err != nil {
+		return err
+	}
+
+	log.Infof("Wrote stake DB snapshot at height %d to %s.", height, fileName)
+	return nil
+}
+
+// importStakeDBSnapshot creates the stake DB in dataDir from the snapshot
+// file.
+func importStakeDBSnapshot(fileName, dataDir string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	height, hash, err := stakedb.ImportSnapshot(f, activeChain, dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to import stake DB snapshot: %v", err)
+	}
+
+	log.Infof("Imported stake DB snapshot at height %d (%v) from %s.",
+		height, hash, fileName)
+	return nil
+}
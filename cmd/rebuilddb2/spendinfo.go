@@ -0,0 +1,110 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// Values of the --onlyspendinfo option selecting the tables for which the
+// spending transaction info is populated.
+const (
+	spendInfoAll       = "all"
+	spendInfoAddresses = "addresses"
+	spendInfoTickets   = "tickets"
+)
+
+// validSpendInfoSelection indicates if sel is a valid --onlyspendinfo value.
+func validSpendInfoSelection(sel string) bool {
+	switch sel {
+	case spendInfoAll, spendInfoAddresses, spendInfoTickets:
+		return true
+	}
+	return false
+}
+
+// updateAddressSpendInfo populates the spending transaction info for all rows
+// of the address table. The address table indexes are removed for the bulk
+// update and recreated afterward. The first error encountered is returned. If
+// incremental is true, only the rows for outputs spent in blocks added since
+// the last update are populated, and the indexes are kept.
+func updateAddressSpendInfo(db *dcrpg.ChainDB, incremental bool) error {
+	if incremental {
+		log.Infof("Populating spending tx info in address table for new blocks...")
+		numAddresses, err := db.UpdateSpendingInfoInNewAddresses(nil)
+		if err != nil {
+			log.Errorf("UpdateSpendingInfoInNewAddresses FAILED: %v", err)
+			return err
+		}
+		log.Infof("Updated %d rows of address table", numAddresses)
+		return nil
+	}
+
+	// Remove indexes not on funding txns (remove on address table indexes)
+	_ = db.DeindexAddressTable() // ignore errors for non-existent indexes
+	db.EnableDuplicateCheckOnInsert(false)
+	log.Infof("Populating spending tx info in address table...")
+	numAddresses, err := db.UpdateSpendingInfoInAllAddresses(nil)
+	if err != nil {
+		log.Errorf("UpdateSpendingInfoInAllAddresses FAILED: %v", err)
+	}
+	// Index address table
+	log.Infof("Updated %d rows of address table", numAddresses)
+	if errIdx := db.IndexAddressTable(nil); errIdx != nil {
+		log.Errorf("IndexAddressTable FAILED: %v", errIdx)
+		if err == nil {
+			err = errIdx
+		}
+	}
+	return err
+}
+
+// updateTicketSpendInfo populates the spending transaction info for all rows of
+// the tickets table. The tickets table indexes are removed for the bulk update
+// and recreated afterward. The first error encountered is returned.
+func updateTicketSpendInfo(db *dcrpg.ChainDB) error {
+	// Remove indexes not on funding txns (remove on tickets table indexes)
+	_ = db.DeindexTicketsTable() // ignore errors for non-existent indexes
+	db.EnableDuplicateCheckOnInsert(false)
+	log.Infof("Populating spending tx info in tickets table...")
+	numTicketsUpdated, err := db.UpdateSpendingInfoInAllTickets()
+	if err != nil {
+		log.Errorf("UpdateSpendingInfoInAllTickets FAILED: %v", err)
+	}
+	// Index tickets table
+	log.Infof("Updated %d rows of tickets table", numTicketsUpdated)
+	if errIdx := db.IndexTicketsTable(nil); errIdx != nil {
+		log.Errorf("IndexTicketsTable FAILED: %v", errIdx)
+		if err == nil {
+			err = errIdx
+		}
+	}
+	return err
+}
+
+// runSpendInfoOnly runs only the spend-info population passes selected by sel,
+// without syncing any blocks. This completes the final phase of a rebuild that
+// stored the blocks but was interrupted before populating the spend info.
+func runSpendInfoOnly(db *dcrpg.ChainDB, sel string, incremental bool, health *syncHealth) error {
+	health.SetPhase(phaseSpendInfo)
+	var errAddr, errTickets error
+	if sel == spendInfoAll || sel == spendInfoAddresses {
+		errAddr = updateAddressSpendInfo(db, incremental)
+	}
+	if sel == spendInfoAll || sel == spendInfoTickets {
+		errTickets = updateTicketSpendInfo(db)
+	}
+	// Restore duplicate checks for any subsequent use of the DB.
+	db.EnableDuplicateCheckOnInsert(true)
+
+	if errAddr != nil || errTickets != nil {
+		return fmt.Errorf("spend info population failed (addresses: %v, "+
+			"tickets: %v)", errAddr, errTickets)
+	}
+	health.SetPhase(phaseDone)
+	log.Infof("Spend info population (%s) finished.", sel)
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// blockQueueSize is the number of fetched blocks that may be queued for
+// storage, allowing the fetch loop to race ahead of the storage goroutine.
+const blockQueueSize = 32
+
+// fetchedBlock is a block retrieved from the node that is ready to be stored.
+type fetchedBlock struct {
+	block     *dcrutil.Block
+	chainWork string
+	// target is the node's best block height when the block was fetched.
+	target int64
+	// isValid indicates if the block was approved by the votes in the next
+	// block. provisional is set when the next block was not yet available, in
+	// which case isValid is assumed to be true.
+	isValid     bool
+	provisional bool
+}
+
+// parentApproved reports whether the provided block's header vote bits approve
+// the previous block, i.e. whether the previous block is valid.
+func parentApproved(block *dcrutil.Block) bool {
+	return dcrutil.IsFlagSet16(block.MsgBlock().Header.VoteBits, dcrutil.BlockValid)
+}
+
+// syncTotals are the running totals of the block store loop. They are updated
+// by the storage goroutine and read concurrently by the speed reporter, so the
+// fields are only accessed atomically.
+type syncTotals struct {
+	height int64
+	txs    int64
+	vins   int64
+	vouts  int64
+}
+
+// blockStored adds the counts for a newly stored block at the given height.
+func (t *syncTotals) blockStored(height, txs, vins, vouts int64) {
+	atomic.AddInt64(&t.txs, txs)
+	atomic.AddInt64(&t.vins, vins)
+	atomic.AddInt64(&t.vouts, vouts)
+	atomic.StoreInt64(&t.height, height)
+}
+
+// setHeight sets the height of the last stored block, such as after a rewind.
+func (t *syncTotals) setHeight(height int64) {
+	atomic.StoreInt64(&t.height, height)
+}
+
+// load returns the height of the last stored block and the total number of
+// transactions, vins, and vouts stored.
+func (t *syncTotals) load() (height, txs, vins, vouts int64) {
+	return atomic.LoadInt64(&t.height), atomic.LoadInt64(&t.txs),
+		atomic.LoadInt64(&t.vins), atomic.LoadInt64(&t.vouts)
+}
+
+// blockStorer stores queued blocks sequentially, in the order they were
+// queued, in a dedicated goroutine.
+type blockStorer struct {
+	db     *dcrpg.ChainDB
+	cfg    *config
+	totals *syncTotals
+	health *syncHealth
+	pauser *syncPauser
+	quit   <-chan struct{}
+
+	queue     chan *fetchedBlock
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// newBlockStorer creates a new blockStorer and starts its storage goroutine.
+func newBlockStorer(db *dcrpg.ChainDB, cfg *config, totals *syncTotals,
+	health *syncHealth, pauser *syncPauser, quit <-chan struct{}) *blockStorer {
+	s := &blockStorer{
+		db:     db,
+		cfg:    cfg,
+		totals: totals,
+		health: health,
+		pauser: pauser,
+		quit:   quit,
+		queue:  make(chan *fetchedBlock, blockQueueSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// run stores the queued blocks until the queue is closed, an error is
+// encountered, or quit is closed. When quit is closed, the block being stored
+// is completed, but no further queued blocks are stored.
+func (s *blockStorer) run() {
+	defer close(s.done)
+	for fb := range s.queue {
+		// check for quit signal
+		select {
+		case <-s.quit:
+			log.Infof("Block storage cancelled at height %d.", fb.block.Height())
+			return
+		default:
+		}
+
+		// Block here while paused, but still respond to the quit signal.
+		if s.pauser.Paused() {
+			height := fb.block.Height()
+			log.Infof("Sync paused at height %d. Send SIGUSR1 to resume.", height)
+			s.health.SetPhase(phasePaused)
+			if !s.pauser.Wait(s.quit) {
+				log.Infof("Block storage cancelled at height %d.", height)
+				return
+			}
+			s.health.SetPhase(phaseStoring)
+			log.Infof("Sync resumed at height %d.", height)
+		}
+
+		if err := s.store(fb); err != nil {
+			s.err = err
+			return
+		}
+	}
+}
+
+// store stores a single block and updates the totals.
+func (s *blockStorer) store(fb *fetchedBlock) error {
+	// Since updateExistingRecords is set, storing a block that was previously
+	// stored with a different validity (e.g. re-storing after an interrupted
+	// run) overwrites the is_valid flags of its existing transaction, vin, and
+	// address rows rather than leaving the stale flags in place. Storing the
+	// following block also updates this block's flags according to its vote
+	// bits, which corrects a provisionally valid block at the tip.
+	isMainchain, updateExistingRecords := true, true
+	numVins, numVouts, _, err := s.db.StoreBlock(fb.block.MsgBlock(), fb.isValid,
+		isMainchain, updateExistingRecords, s.cfg.AddrSpendInfoOnline,
+		!s.cfg.TicketSpendInfoBatch, fb.chainWork)
+	if err != nil {
+		return fmt.Errorf("StoreBlock failed: %v", err)
+	}
+
+	height := fb.block.Height()
+	if fb.provisional {
+		log.Infof("Block %d stored as valid pending the votes in the next block. "+
+			"It will be updated when the next block is stored.", height)
+	} else if !fb.isValid {
+		log.Infof("Block %d stored as invalid (disapproved by stakeholders).", height)
+	}
+	numTxs := int64(len(fb.block.Transactions()) + len(fb.block.STransactions()))
+	s.totals.blockStored(height, numTxs, numVins, numVouts)
+	s.health.BlockStored(height, fb.target)
+	return nil
+}
+
+// Queue queues a block for storage. It returns false if the block could not be
+// queued because the storage goroutine has stopped, or quit was closed.
+func (s *blockStorer) Queue(fb *fetchedBlock) bool {
+	select {
+	case s.queue <- fb:
+		return true
+	case <-s.done:
+		return false
+	case <-s.quit:
+		return false
+	}
+}
+
+// Finish stops accepting blocks and waits for the storage goroutine to store
+// the queued blocks and return. Any error encountered while storing is
+// returned. Finish may be called more than once.
+func (s *blockStorer) Finish() error {
+	s.closeOnce.Do(func() { close(s.queue) })
+	<-s.done
+	return s.err
+}
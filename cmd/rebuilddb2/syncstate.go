@@ -0,0 +1,69 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+// Phases of a rebuild recorded in the sync_state table. A bulk import passes
+// through all of them in order, while an import that keeps the indexes starts
+// at syncPhaseSpendUpdate.
+const (
+	// The indexes have been dropped, but no blocks have been stored yet.
+	syncPhaseDeindexed = "deindexed"
+	// Blocks are being stored without indexes.
+	syncPhaseBulkLoading = "bulk_loading"
+	// Duplicates are being removed and the indexes recreated.
+	syncPhaseReindexing = "reindexing"
+	// The spending transaction info is being updated.
+	syncPhaseSpendUpdate = "spend_update"
+)
+
+// syncCheckpointer records the phase of a rebuild so that an interrupted
+// rebuild can resume at the same phase.
+type syncCheckpointer struct {
+	db          *dcrpg.ChainDB
+	bulkReindex bool
+}
+
+// Set records the phase and the height of the best stored block.
+func (c *syncCheckpointer) Set(phase string, height int64) error {
+	if err := c.db.SetSyncState(phase, height, c.bulkReindex); err != nil {
+		return fmt.Errorf("unable to record sync phase %q: %v", phase, err)
+	}
+	log.Debugf("Recorded sync phase %q at height %d.", phase, height)
+	return nil
+}
+
+// Clear removes the recorded phase once the rebuild is complete.
+func (c *syncCheckpointer) Clear() error {
+	if err := c.db.ClearSyncState(); err != nil {
+		return fmt.Errorf("unable to clear sync state: %v", err)
+	}
+	return nil
+}
+
+// resumeBulkReindex reports whether the recorded sync state is from a bulk
+// import that was interrupted before its indexes were recreated, in which case
+// the resumed rebuild must recreate them regardless of how many blocks remain.
+func resumeBulkReindex(state *dcrpg.SyncState) bool {
+	if state == nil || !state.BulkReindex {
+		return false
+	}
+	switch state.Phase {
+	case syncPhaseDeindexed, syncPhaseBulkLoading, syncPhaseReindexing:
+		return true
+	}
+	return false
+}
+
+// indexesDropped reports whether the recorded sync state indicates that the
+// indexes were completely dropped by the interrupted rebuild. During
+// syncPhaseReindexing, some of the indexes may have been recreated.
+func indexesDropped(state *dcrpg.SyncState) bool {
+	return resumeBulkReindex(state) && state.Phase != syncPhaseReindexing
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5"
+)
+
+func TestResumeBulkReindex(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         *dcrpg.SyncState
+		wantReindex   bool
+		wantNoIndexes bool
+	}{
+		{"no state", nil, false, false},
+		{"deindexed", &dcrpg.SyncState{Phase: syncPhaseDeindexed, BulkReindex: true}, true, true},
+		{"bulk loading", &dcrpg.SyncState{Phase: syncPhaseBulkLoading, BulkReindex: true}, true, true},
+		{"reindexing", &dcrpg.SyncState{Phase: syncPhaseReindexing, BulkReindex: true}, true, false},
+		{"spend update", &dcrpg.SyncState{Phase: syncPhaseSpendUpdate, BulkReindex: true}, false, false},
+		{"indexes kept", &dcrpg.SyncState{Phase: syncPhaseBulkLoading}, false, false},
+		{"unknown phase", &dcrpg.SyncState{Phase: "other", BulkReindex: true}, false, false},
+	}
+
+	for _, test := range tests {
+		if got := resumeBulkReindex(test.state); got != test.wantReindex {
+			t.Errorf("%s: resumeBulkReindex = %v, expected %v", test.name, got, test.wantReindex)
+		}
+		if got := indexesDropped(test.state); got != test.wantNoIndexes {
+			t.Errorf("%s: indexesDropped = %v, expected %v", test.name, got, test.wantNoIndexes)
+		}
+	}
+}
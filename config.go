@@ -23,6 +23,7 @@ import (
 	"github.com/decred/dcrd/dcrutil/v2"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/db/dcrpg/v5"
 	"github.com/decred/dcrdata/v5/netparams"
 	"github.com/decred/dcrdata/v5/version"
 	"github.com/decred/slog"
@@ -35,6 +36,9 @@ const (
 	defaultDataDirname    = "data"
 	defaultLogLevel       = "info"
 	defaultLogDirname     = "logs"
+
+	// apiSunsetLayout is the date format of the api-v1-sunset setting.
+	apiSunsetLayout = "2006-01-02"
 )
 
 var activeNet = &netparams.MainNetParams
@@ -48,16 +52,21 @@ var (
 	dcrdHomeDir              = dcrutil.AppDataDir("dcrd", false)
 	defaultDaemonRPCCertFile = filepath.Join(dcrdHomeDir, "rpc.cert")
 	defaultMaxLogZips        = 16
+	defaultForceExitSignals  = 2
 
 	defaultHost                = "localhost"
 	defaultHTTPProfPath        = "/p"
 	defaultAPIProto            = "http"
+	defaultTLSCertFile         = "dcrdata.cert"
+	defaultTLSKeyFile          = "dcrdata.key"
 	defaultMainnetPort         = "7777"
 	defaultTestnetPort         = "17778"
 	defaultSimnetPort          = "17779"
+	defaultGRPCPort            = "7787"
 	defaultIndentJSON          = "   "
 	defaultCacheControlMaxAge  = 86400
 	defaultInsightReqRateLimit = 20.0
+	defaultAPIReqRateBurst     = 20
 	defaultMaxCSVAddrs         = 25
 	defaultServerHeader        = "dcrdata"
 
@@ -79,9 +88,30 @@ var (
 	defaultAddrCacheLimit   = 4096
 	defaultAddrCacheUXTOCap = 1 << 29
 
+	defaultRichListSize     = 1000
+	defaultRichListInterval = time.Hour
+
+	defaultPruneInterval = time.Hour
+
+	defaultPiPollInterval = 10 * time.Minute
+
+	defaultPGMaintenanceInterval = 30 * time.Minute
+
+	defaultVSPInterval = 10 * time.Minute
+
+	defaultPriceHistoryInterval = 6 * time.Hour
+
+	defaultFaucetAmount   = 10
+	defaultFaucetInterval = 24 * time.Hour
+
+	defaultRawPubZMQAddr  = "tcp://127.0.0.1:28900"
+	defaultRawPubNATSAddr = "nats://127.0.0.1:4222"
+
 	defaultExchangeIndex     = "USD"
 	defaultDisabledExchanges = "dragonex,poloniex"
+	defaultExchangeFiats     = "USD,EUR,JPY,GBP"
 	defaultRateCertFile      = filepath.Join(defaultHomeDir, "rpc.cert")
+	defaultAutocertDir       = filepath.Join(defaultHomeDir, "autocert")
 
 	defaultMainnetLink  = "https://explorer.dcrdata.org/"
 	defaultTestnetLink  = "https://testnet.dcrdata.org/"
@@ -109,16 +139,33 @@ type config struct {
 	UseGops      bool   `short:"g" long:"gops" description:"Run with gops diagnostics agent listening. See github.com/google/gops for more information." env:"DCRDATA_USE_GOPS"`
 	ReloadHTML   bool   `long:"reload-html" description:"Reload HTML templates on every request" env:"DCRDATA_RELOAD_HTML"`
 
+	ForceExitSignals int `long:"force-exit-signals" description:"Number of interrupt signals or shutdown requests, received within 5 seconds, that force an immediate exit when the clean shutdown stalls. The first always begins a clean shutdown. Values less than 2 disable the forced exit." env:"DCRDATA_FORCE_EXIT_SIGNALS"`
+
 	// API/server
-	APIProto            string  `long:"apiproto" description:"Protocol for API (http or https)" env:"DCRDATA_ENABLE_HTTPS"`
-	APIListen           string  `long:"apilisten" description:"Listen address for API. default localhost:7777, :17778 testnet, :17779 simnet" env:"DCRDATA_LISTEN_URL"`
-	IndentJSON          string  `long:"indentjson" description:"String for JSON indentation (default is \"   \"), when indentation is requested via URL query."`
-	UseRealIP           bool    `long:"userealip" description:"Use the RealIP middleware from the pressly/chi/middleware package to get the client's real IP from the X-Forwarded-For or X-Real-IP headers, in that order." env:"DCRDATA_USE_REAL_IP"`
-	CacheControlMaxAge  int     `long:"cachecontrol-maxage" description:"Set CacheControl in the HTTP response header to a value in seconds for clients to cache the response. This applies only to FileServer routes." env:"DCRDATA_MAX_CACHE_AGE"`
-	InsightReqRateLimit float64 `long:"insight-limit-rps" description:"Requests/second per client IP for the Insight API's rate limiter." env:"DCRDATA_INSIGHT_RATE_LIMIT"`
-	MaxCSVAddrs         int     `long:"max-api-addrs" description:"Maximum allowed comma-separated addresses for endpoints that accept multiple addresses."`
-	CompressAPI         bool    `long:"compress-api" description:"Use compression for a number of endpoints with commonly large responses."`
-	ServerHeader        string  `long:"server-http-header" description:"Set the HTTP response header Server key value. Valid values are \"off\", \"version\", or a custom string."`
+	APIProto            string   `long:"apiproto" description:"Protocol for API (http or https)" env:"DCRDATA_ENABLE_HTTPS"`
+	APIListen           string   `long:"apilisten" description:"Listen address for API. default localhost:7777, :17778 testnet, :17779 simnet" env:"DCRDATA_LISTEN_URL"`
+	TLSCert             string   `long:"tlscert" description:"File containing the TLS certificate of the web server when apiproto is https." env:"DCRDATA_TLS_CERT"`
+	TLSKey              string   `long:"tlskey" description:"File containing the TLS key of the web server when apiproto is https." env:"DCRDATA_TLS_KEY"`
+	AutocertHosts       []string `long:"autocert-host" description:"Host name for which a TLS certificate is obtained automatically from Let's Encrypt, instead of using tlscert and tlskey. Implies apiproto=https. May be specified multiple times."`
+	AutocertDir         string   `long:"autocert-dir" description:"Directory in which the automatically obtained TLS certificates are cached." env:"DCRDATA_AUTOCERT_DIR"`
+	AutocertEmail       string   `long:"autocert-email" description:"Contact email address for the Let's Encrypt account, for notices about certificate problems." env:"DCRDATA_AUTOCERT_EMAIL"`
+	AutocertHTTPListen  string   `long:"autocert-httplisten" description:"Listen address, e.g. :80, for the Let's Encrypt HTTP challenge, which also redirects other HTTP requests to HTTPS. If not set, only the TLS challenge on the apilisten address, which must be port 443, is used." env:"DCRDATA_AUTOCERT_HTTP_LISTEN"`
+	TrustedProxies      []string `long:"trusted-proxy" description:"IP address or CIDR network of a trusted reverse proxy, from which the client's real IP is taken from the X-Forwarded-For or X-Real-IP headers. Headers from other peers are ignored. Overrides userealip. May be specified multiple times."`
+	CORSOrigins         []string `long:"cors-origin" description:"Origin allowed to make cross-origin requests, e.g. https://example.com, with at most one * wildcard, e.g. https://*.example.com. All origins are allowed if not set. May be specified multiple times."`
+	IndentJSON          string   `long:"indentjson" description:"String for JSON indentation (default is \"   \"), when indentation is requested via URL query."`
+	UseRealIP           bool     `long:"userealip" description:"Use the RealIP middleware from the pressly/chi/middleware package to get the client's real IP from the X-Forwarded-For or X-Real-IP headers, in that order." env:"DCRDATA_USE_REAL_IP"`
+	CacheControlMaxAge  int      `long:"cachecontrol-maxage" description:"Set CacheControl in the HTTP response header to a value in seconds for clients to cache the response. This applies only to FileServer routes." env:"DCRDATA_MAX_CACHE_AGE"`
+	InsightReqRateLimit float64  `long:"insight-limit-rps" description:"Requests/second per client IP for the Insight API's rate limiter." env:"DCRDATA_INSIGHT_RATE_LIMIT"`
+	APIReqRateLimit     float64  `long:"api-limit-rps" description:"Requests/second per client IP for the API's rate limiter. Requests with an API key in the X-API-Key header are limited by the key's quota in the api_keys table instead. Set to 0 to disable rate limiting." env:"DCRDATA_API_RATE_LIMIT"`
+	APIReqRateBurst     int      `long:"api-limit-burst" description:"Maximum burst of requests per client IP for the API's rate limiter."`
+	MaxCSVAddrs         int      `long:"max-api-addrs" description:"Maximum allowed comma-separated addresses for endpoints that accept multiple addresses."`
+	CompressAPI         bool     `long:"compress-api" description:"Use compression for a number of endpoints with commonly large responses."`
+	APIV1Sunset         string   `long:"api-v1-sunset" description:"Date (YYYY-MM-DD) after which the v1 API may be removed, sent in the Sunset response header of the deprecated /api routes."`
+	APISigningKey       string   `long:"api-signing-key" description:"File with the hex encoded seed of the Ed25519 key with which API responses are signed. A new key is generated if the file does not exist. Responses are not signed if not set." env:"DCRDATA_API_SIGNING_KEY"`
+	AdminToken          string   `long:"admin-token" description:"Bearer token of the /admin endpoints for monitoring the sync and starting maintenance tasks. The admin endpoints are disabled if not set." env:"DCRDATA_ADMIN_TOKEN"`
+	ServerHeader        string   `long:"server-http-header" description:"Set the HTTP response header Server key value. Valid values are \"off\", \"version\", or a custom string."`
+	Headless            bool     `long:"headless" description:"Serve the data used to render each explorer page as JSON under /api/page, e.g. /api/page/block/{hash}, for custom frontends." env:"DCRDATA_HEADLESS"`
+	GRPCListen          string   `long:"grpclisten" description:"Listen address for the gRPC API, e.g. localhost:7787. The gRPC API is disabled if not set." env:"DCRDATA_GRPC_LISTEN_URL"`
 
 	// Mempool
 	MempoolMinInterval int `long:"mp-min-interval" description:"The minimum time in seconds between mempool reports, regardless of number of new tickets seen." env:"DCRDATA_MEMPOOL_MIN_INTERVAL"`
@@ -126,12 +173,12 @@ type config struct {
 	MPTriggerTickets   int `long:"mp-ticket-trigger" description:"The minimum number of new tickets that must be seen to trigger a new mempool report." env:"DCRDATA_MP_TRIGGER_TICKETS"`
 
 	// Politeia/proposals and consensus agendas
-	AgendasDBFileName string `long:"agendadbfile" description:"Agendas DB file name (default is agendas.db)." env:"DCRDATA_AGENDAS_DB_FILE_NAME"`
-	ProposalsFileName string `long:"proposalsdbfile" description:"Proposals DB file name (default is proposals.db)." env:"DCRDATA_PROPOSALS_DB_FILE_NAME"`
-	PoliteiaAPIURL    string `long:"politeiaurl" description:"Defines the root API politeia URL (defaults to https://proposals.decred.org)."`
-	PiPropRepoOwner   string `long:"piproposalsowner" description:"Defines the owner to the github repo where Politeia's proposals are pushed."`
-	PiPropRepoName    string `long:"piproposalsrepo" description:"Defines the name of the github repo where Politeia's proposals are pushed."`
-	DisablePiParser   bool   `long:"disable-piparser" description:"Disables the piparser tool from running."`
+	AgendasDBFileName string        `long:"agendadbfile" description:"Agendas DB file name (default is agendas.db)." env:"DCRDATA_AGENDAS_DB_FILE_NAME"`
+	ProposalsFileName string        `long:"proposalsdbfile" description:"Proposals DB file name (default is proposals.db)." env:"DCRDATA_PROPOSALS_DB_FILE_NAME"`
+	PoliteiaAPIURL    string        `long:"politeiaurl" description:"Defines the root API politeia URL (defaults to https://proposals.decred.org)."`
+	PoliteiaCMSURL    string        `long:"politeiacmsurl" description:"Defines the root API URL of the Politeia contractor management system, e.g. https://cms.decred.org, from which the billing of the approved proposals is retrieved. Proposal billing is disabled if not set."`
+	PiPollInterval    time.Duration `long:"pi-poll-interval" description:"Interval (a time.Duration string) between the retrievals of the proposals and their votes from the Politeia API for the auxiliary DB."`
+	DisablePiParser   bool          `long:"disable-piparser" description:"Disables the retrieval of Politeia's proposals and their votes."`
 
 	// Caching and optimization.
 	AddrCacheCap     int    `long:"addr-cache-cap" description:"Address cache capacity in bytes."`
@@ -140,13 +187,65 @@ type config struct {
 	NoDevPrefetch    bool   `long:"no-dev-prefetch" description:"Disable automatic dev fund balance query on new blocks. When true, the query will still be run on demand, but not automatically after new blocks are connected." env:"DCRDATA_DISABLE_DEV_PREFETCH"`
 	ChartsCacheDump  string `long:"chartscache" description:"Defines the file name that holds the charts cache data on system exit."`
 
+	// Rich list
+	RichListSize     int           `long:"richlist-size" description:"Number of top addresses by balance materialized in the rich list."`
+	RichListInterval time.Duration `long:"richlist-interval" description:"Interval (a time.Duration string) between rich list and address balance distribution updates. Set to 0 to disable the updates."`
+
+	// Pruning mode
+	PruneDepth    int64         `long:"prune-depth" description:"Enable pruning mode, deleting the vins and spent vouts of the blocks more than this many blocks below the best block. Block summaries, address balances and the coin supply are kept. Must be at least 4096. Set to 0 to disable pruning."`
+	PruneInterval time.Duration `long:"prune-interval" description:"Interval (a time.Duration string) between pruning runs in pruning mode."`
+
+	// VSP statistics
+	VSPs        []string      `long:"vsp" description:"Base URL of a VSP's vspd instance (e.g. https://vsp.example.com) from which to collect VSP statistics. May be specified multiple times."`
+	VSPInterval time.Duration `long:"vsp-interval" description:"Interval (a time.Duration string) between polls of the VSPs' statistics."`
+
+	// Historical prices
+	PriceSource   string        `long:"price-history-source" description:"Historical price source from which to import the daily DCR/USD prices for the fiat-valued chart series, coingecko or cryptocompare. The import is disabled if not set. Mainnet only."`
+	PriceInterval time.Duration `long:"price-history-interval" description:"Interval (a time.Duration string) between imports of the prices of the completed days."`
+
+	// Address watches
+	Webhooks bool `long:"webhooks" description:"Enable the address watch API, with which clients register callback URLs that are sent signed notifications of the mempool and confirmed transactions involving an address."`
+
+	// Testnet and simnet faucet
+	FaucetWalletRPC  string        `long:"faucet-wallet-rpc" description:"URL of the dcrwallet JSON-RPC server (e.g. https://127.0.0.1:19110) from which the faucet sends coins. Enables the /api/faucet endpoint on testnet and simnet."`
+	FaucetWalletUser string        `long:"faucet-wallet-user" description:"Username of the faucet's wallet RPC server." env:"DCRDATA_FAUCET_WALLET_USER"`
+	FaucetWalletPass string        `long:"faucet-wallet-pass" description:"Password of the faucet's wallet RPC server." env:"DCRDATA_FAUCET_WALLET_PASS"`
+	FaucetWalletCert string        `long:"faucet-wallet-cert" description:"File containing the TLS certificate of the faucet's wallet RPC server."`
+	FaucetAmount     float64       `long:"faucet-amount" description:"Amount of DCR sent by each faucet request."`
+	FaucetInterval   time.Duration `long:"faucet-interval" description:"Interval (a time.Duration string) that must elapse before an address may be granted faucet coins again."`
+	FaucetToken      string        `long:"faucet-token" description:"Bearer token of the /api/faucet endpoint. Required to enable the faucet." env:"DCRDATA_FAUCET_TOKEN"`
+
+	// Address labels
+	AddressTagsFile string `long:"addresstags" description:"JSON file of labels of known addresses (an array of objects with address, label and category fields) to import on startup. Labels set with the admin API are not replaced."`
+
+	// Coin age analytics
+	CoinAge bool `long:"coinage" description:"Compute the coin days destroyed by each block and the coin age distribution of the unspent value, served by the /api/chart/coin-age endpoints. The first run processes the whole chain in the background."`
+
+	// Address clustering
+	AddrClusters bool `long:"addrclusters" description:"Link addresses into clusters with the common-input-ownership heuristic, served by the /api/address/{address}/cluster endpoint. This is costly in database size and time, and the first run processes the whole chain in the background."`
+
+	// Mempool history
+	MempoolHistory bool `long:"mempool-history" description:"Record the first seen time, fee rate, and fate (mined or evicted) of each transaction seen in mempool, served by the /api/tx/{txid}/firstseen and /api/mempool/history/{chartgrouping} endpoints."`
+
+	// Raw event publisher
+	RawPub     string `long:"rawpub" description:"Transport with which to publish the rawblock, rawtx, and stakeevent messages: zmq or nats. Empty disables publishing."`
+	RawPubAddr string `long:"rawpubaddr" description:"For zmq, the endpoint on which the PUB socket listens (default tcp://127.0.0.1:28900). For nats, the URL of the NATS server (default nats://127.0.0.1:4222)."`
+
 	// DB backend
 	PGDBName         string        `long:"pgdbname" description:"PostgreSQL DB name." env:"DCRDATA_PG_DB_NAME"`
 	PGUser           string        `long:"pguser" description:"PostgreSQL DB user." env:"DCRDATA_POSTGRES_USER"`
 	PGPass           string        `long:"pgpass" description:"PostgreSQL DB password." env:"DCRDATA_POSTGRES_PASS"`
 	PGHost           string        `long:"pghost" description:"PostgreSQL server host:port or UNIX socket (e.g. /run/postgresql)." env:"DCRDATA_POSTGRES_HOST_URL"`
+	PGDSN            string        `long:"pgdsn" description:"PostgreSQL data source name (a postgres:// URL or key=value settings) for the primary database. Overrides pghost, pguser, and pgpass." env:"DCRDATA_POSTGRES_DSN"`
+	PGReplicas       []string      `long:"pgreplica" description:"PostgreSQL data source name of a read replica of the primary database, used for read-only queries such as address history and charts. May be specified multiple times."`
 	PGQueryTimeout   time.Duration `short:"T" long:"pgtimeout" description:"Timeout (a time.Duration string) for most PostgreSQL queries used for user initiated queries."`
 	HidePGConfig     bool          `long:"hidepgconfig" description:"Blocks logging of the PostgreSQL db configuration on system start up."`
+	PGMaintInterval  time.Duration `long:"pg-maintenance-interval" description:"Interval (a time.Duration string) between checks of the high-churn tables (e.g. addresses, vouts, tickets) for scheduled VACUUM ANALYZE and ANALYZE. Set to 0 to disable the scheduler and rely on autovacuum."`
+	PGMaintRatio     float64       `long:"pg-maintenance-ratio" description:"Fraction of a table's live tuples that may be dead (or modified since the last ANALYZE) before the maintenance scheduler vacuums (or analyzes) it."`
+	PGVinBatch       int           `long:"pg-vin-batch" description:"Maximum number of vins written in each COPY when pg-copy-threshold is set."`
+	PGVoutBatch      int           `long:"pg-vout-batch" description:"Maximum number of vouts written in each COPY when pg-copy-threshold is set."`
+	PGCopyThreshold  int           `long:"pg-copy-threshold" description:"Number of vins or vouts in a block's transaction tree at or above which they are written with COPY instead of an INSERT for each row during a bulk sync without indexes. 0 disables COPY."`
+	PGPartition      bool          `long:"pg-partition" description:"Partition the vins and vouts tables by block height, converting the tables of an existing database. This may take a while, and cannot be undone. Not supported with CockroachDB."`
 	DropIndexes      bool          `long:"drop-inds" short:"D" description:"Drop all table indexes and exit."`
 	PurgeNBestBlocks int           `long:"purge-n-blocks" description:"Purge all data for the N best blocks, using the best block across all DBs if they are out of sync."`
 	SyncAndQuit      bool          `long:"sync-and-quit" description:"Sync to the best block and exit. Do not start the explorer or API." env:"DCRDATA_ENABLE_SYNC_N_QUIT"`
@@ -154,20 +253,26 @@ type config struct {
 	SyncStatusLimit  int           `long:"sync-status-limit" description:"Sets the number of blocks behind the current best height past which only the syncing status page can be served on the running web server. Value should be greater than 2 but less than 5000."`
 
 	// RPC client options
-	DcrdUser         string `long:"dcrduser" description:"Daemon RPC user name" env:"DCRDATA_DCRD_USER"`
-	DcrdPass         string `long:"dcrdpass" description:"Daemon RPC password" env:"DCRDATA_DCRD_PASS"`
-	DcrdServ         string `long:"dcrdserv" description:"Hostname/IP and port of dcrd RPC server to connect to (default localhost:9109, testnet: localhost:19109, simnet: localhost:19556)" env:"DCRDATA_DCRD_URL"`
-	DcrdCert         string `long:"dcrdcert" description:"File containing the dcrd certificate file" env:"DCRDATA_DCRD_CERT"`
-	DisableDaemonTLS bool   `long:"nodaemontls" description:"Disable TLS for the daemon RPC client -- NOTE: This is only allowed if the RPC client is connecting to localhost" env:"DCRDATA_DCRD_DISABLE_TLS"`
-	NoBlockPrefetch  bool   `long:"no-dcrd-block-prefetch" description:"Disable block pre-fetch from dcrd during startup sync."`
+	DcrdUser         string   `long:"dcrduser" description:"Daemon RPC user name" env:"DCRDATA_DCRD_USER"`
+	DcrdPass         string   `long:"dcrdpass" description:"Daemon RPC password" env:"DCRDATA_DCRD_PASS"`
+	DcrdServ         string   `long:"dcrdserv" description:"Hostname/IP and port of dcrd RPC server to connect to (default localhost:9109, testnet: localhost:19109, simnet: localhost:19556)" env:"DCRDATA_DCRD_URL"`
+	DcrdCert         string   `long:"dcrdcert" description:"File containing the dcrd certificate file" env:"DCRDATA_DCRD_CERT"`
+	DcrdFallbackServ []string `long:"dcrdfallbackserv" description:"Hostname/IP and port of a fallback dcrd RPC server to fail over to when dcrdserv is unreachable. May be specified multiple times, in order of preference. The fallback nodes must accept the same RPC credentials."`
+	DcrdFallbackCert []string `long:"dcrdfallbackcert" description:"File containing the certificate of the corresponding dcrdfallbackserv, if different from dcrdcert. May be specified multiple times."`
+	DisableDaemonTLS bool     `long:"nodaemontls" description:"Disable TLS for the daemon RPC client -- NOTE: This is only allowed if the RPC client is connecting to localhost" env:"DCRDATA_DCRD_DISABLE_TLS"`
+	NoBlockPrefetch  bool     `long:"no-dcrd-block-prefetch" description:"Disable block pre-fetch from dcrd during startup sync."`
 
 	// ExchangeBot settings
 	EnableExchangeBot bool   `long:"exchange-monitor" description:"Enable the exchange monitor" env:"DCRDATA_MONITOR_EXCHANGES"`
 	DisabledExchanges string `long:"disable-exchange" description:"Exchanges to disable. See /exchanges/exchanges.go for available exchanges. Use a comma to separate multiple exchanges" env:"DCRDATA_DISABLE_EXCHANGES"`
 	ExchangeCurrency  string `long:"exchange-currency" description:"The default bitcoin price index. A 3-letter currency code" env:"DCRDATA_EXCHANGE_INDEX"`
+	ExchangeFiats     string `long:"exchange-fiats" description:"Comma-separated fiat currency codes to request from the bitcoin price index sources that do not provide every currency by default." env:"DCRDATA_EXCHANGE_FIATS"`
 	RateMaster        string `long:"ratemaster" description:"The address of a DCRRates instance. Exchange monitoring will get all data from a DCRRates subscription." env:"DCRDATA_RATE_MASTER"`
 	RateCertificate   string `long:"ratecert" description:"File containing DCRRates TLS certificate file." env:"DCRDATA_RATE_MASTER"`
 
+	// Per-source ExchangeBot settings
+	ExchangeSources []string `long:"exchange-source" description:"Settings of an exchange or bitcoin price index source, as token:key=value,..., with keys enabled (true/false), weight (in the weighted median exchange rate, default 1) and interval (between API calls, default 5m), e.g. coinbase:weight=2,interval=10m. May be repeated for several sources."`
+
 	// Links
 	MainnetLink  string `long:"mainnet-link" description:"When dcrdata is on testnet, this address will be used to direct a user to a dcrdata on mainnet when appropriate." env:"DCRDATA_MAINNET_LINK"`
 	TestnetLink  string `long:"testnet-link" description:"When dcrdata is on mainnet, this address will be used to direct a user to a dcrdata on testnet when appropriate." env:"DCRDATA_TESTNET_LINK"`
@@ -180,6 +285,7 @@ var (
 		DataDir:             defaultDataDir,
 		LogDir:              defaultLogDir,
 		MaxLogZips:          defaultMaxLogZips,
+		ForceExitSignals:    defaultForceExitSignals,
 		ConfigFile:          defaultConfigFile,
 		AgendasDBFileName:   defaultAgendasDBFileName,
 		ProposalsFileName:   defaultProposalsFileName,
@@ -188,9 +294,13 @@ var (
 		DebugLevel:          defaultLogLevel,
 		HTTPProfPath:        defaultHTTPProfPath,
 		APIProto:            defaultAPIProto,
+		TLSCert:             defaultTLSCertFile,
+		TLSKey:              defaultTLSKeyFile,
+		AutocertDir:         defaultAutocertDir,
 		IndentJSON:          defaultIndentJSON,
 		CacheControlMaxAge:  defaultCacheControlMaxAge,
 		InsightReqRateLimit: defaultInsightReqRateLimit,
+		APIReqRateBurst:     defaultAPIReqRateBurst,
 		MaxCSVAddrs:         defaultMaxCSVAddrs,
 		ServerHeader:        defaultServerHeader,
 		DcrdCert:            defaultDaemonRPCCertFile,
@@ -202,11 +312,24 @@ var (
 		PGPass:              defaultPGPass,
 		PGHost:              defaultPGHost,
 		PGQueryTimeout:      defaultPGQueryTimeout,
+		PGMaintInterval:     defaultPGMaintenanceInterval,
+		PGMaintRatio:        dcrpg.DefaultMaintenanceRatio,
+		PGVinBatch:          dcrpg.DefaultCopyBatchSize,
+		PGVoutBatch:         dcrpg.DefaultCopyBatchSize,
 		AddrCacheCap:        defaultAddrCacheCap,
 		AddrCacheLimit:      defaultAddrCacheLimit,
 		AddrCacheUXTOCap:    defaultAddrCacheUXTOCap,
+		RichListSize:        defaultRichListSize,
+		RichListInterval:    defaultRichListInterval,
+		PruneInterval:       defaultPruneInterval,
+		PiPollInterval:      defaultPiPollInterval,
+		VSPInterval:         defaultVSPInterval,
+		PriceInterval:       defaultPriceHistoryInterval,
+		FaucetAmount:        defaultFaucetAmount,
+		FaucetInterval:      defaultFaucetInterval,
 		ExchangeCurrency:    defaultExchangeIndex,
 		DisabledExchanges:   defaultDisabledExchanges,
+		ExchangeFiats:       defaultExchangeFiats,
 		RateCertificate:     defaultRateCertFile,
 		MainnetLink:         defaultMainnetLink,
 		TestnetLink:         defaultTestnetLink,
@@ -603,6 +726,17 @@ func loadConfig() (*config, error) {
 		return loadConfigError(err)
 	}
 
+	for i := range cfg.DcrdFallbackServ {
+		cfg.DcrdFallbackServ[i], err = normalizeNetworkAddress(cfg.DcrdFallbackServ[i],
+			defaultHost, activeNet.JSONRPCClientPort)
+		if err != nil {
+			return loadConfigError(err)
+		}
+	}
+	if len(cfg.DcrdFallbackCert) > len(cfg.DcrdFallbackServ) {
+		return loadConfigError(fmt.Errorf("more dcrdfallbackcert than dcrdfallbackserv"))
+	}
+
 	// Output folder
 	cfg.OutFolder = cleanAndExpandPath(cfg.OutFolder)
 	cfg.OutFolder = filepath.Join(cfg.OutFolder, activeNet.Name)
@@ -618,17 +752,106 @@ func loadConfig() (*config, error) {
 		return loadConfigError(fmt.Errorf("httpprofprefix must not be \"\" or \"/\""))
 	}
 
+	if cfg.APISigningKey != "" {
+		cfg.APISigningKey = cleanAndExpandPath(cfg.APISigningKey)
+	}
+
+	// Automatic TLS certificates are only for HTTPS.
+	if len(cfg.AutocertHosts) > 0 {
+		cfg.APIProto = "https"
+		cfg.AutocertDir = cleanAndExpandPath(cfg.AutocertDir)
+	} else if cfg.AutocertHTTPListen != "" {
+		return loadConfigError(fmt.Errorf("autocert-httplisten requires autocert-host"))
+	}
+	cfg.TLSCert = cleanAndExpandPath(cfg.TLSCert)
+	cfg.TLSKey = cleanAndExpandPath(cfg.TLSKey)
+
+	// Only the headers from the trusted proxies are used when they are set.
+	if len(cfg.TrustedProxies) > 0 && cfg.UseRealIP {
+		log.Warnf("Using the client IP headers only from the trusted proxies (--trusted-proxy), ignoring --userealip.")
+		cfg.UseRealIP = false
+	}
+
 	// Parse, validate, and set debug log level(s).
 	if cfg.Quiet {
 		cfg.DebugLevel = "error"
 	}
 
+	if cfg.RichListSize <= 0 {
+		cfg.RichListSize = defaultRichListSize
+	}
+
+	if cfg.PruneDepth < 0 || (cfg.PruneDepth > 0 && cfg.PruneDepth < dcrpg.MinPruneDepth) {
+		return loadConfigError(fmt.Errorf("invalid prune-depth %d, expected 0 "+
+			"or at least %d", cfg.PruneDepth, dcrpg.MinPruneDepth))
+	}
+	if cfg.PruneInterval <= 0 {
+		cfg.PruneInterval = defaultPruneInterval
+	}
+	if cfg.PiPollInterval <= 0 {
+		cfg.PiPollInterval = defaultPiPollInterval
+	}
+
+	if cfg.VSPInterval <= 0 {
+		cfg.VSPInterval = defaultVSPInterval
+	}
+	if cfg.PriceInterval <= 0 {
+		cfg.PriceInterval = defaultPriceHistoryInterval
+	}
+
+	if cfg.FaucetWalletRPC != "" {
+		if !cfg.TestNet && !cfg.SimNet {
+			return loadConfigError(fmt.Errorf("the faucet may not be " +
+				"enabled on mainnet"))
+		}
+		if cfg.FaucetToken == "" {
+			return loadConfigError(fmt.Errorf("faucet-token is required " +
+				"to enable the faucet"))
+		}
+		if cfg.FaucetAmount <= 0 {
+			return loadConfigError(fmt.Errorf("invalid faucet-amount %v",
+				cfg.FaucetAmount))
+		}
+		if cfg.FaucetInterval <= 0 {
+			cfg.FaucetInterval = defaultFaucetInterval
+		}
+		if cfg.FaucetWalletCert != "" {
+			cfg.FaucetWalletCert = cleanAndExpandPath(cfg.FaucetWalletCert)
+		}
+	}
+
+	switch cfg.RawPub {
+	case "":
+	case "zmq":
+		if cfg.RawPubAddr == "" {
+			cfg.RawPubAddr = defaultRawPubZMQAddr
+		}
+	case "nats":
+		if cfg.RawPubAddr == "" {
+			cfg.RawPubAddr = defaultRawPubNATSAddr
+		}
+	default:
+		return loadConfigError(fmt.Errorf("invalid rawpub transport %q, "+
+			"expected zmq or nats", cfg.RawPub))
+	}
+
 	// Validate DB timeout. Zero or negative should be set to the large default
 	// timeout to effectively disable timeouts.
 	if cfg.PGQueryTimeout <= 0 {
 		cfg.PGQueryTimeout = defaultPGQueryTimeout
 	}
 
+	if cfg.PGMaintRatio <= 0 {
+		cfg.PGMaintRatio = dcrpg.DefaultMaintenanceRatio
+	}
+
+	if cfg.APIV1Sunset != "" {
+		if _, err := time.Parse(apiSunsetLayout, cfg.APIV1Sunset); err != nil {
+			return loadConfigError(fmt.Errorf("invalid api-v1-sunset %q, "+
+				"expected YYYY-MM-DD", cfg.APIV1Sunset))
+		}
+	}
+
 	// Parse, validate, and set debug log level(s).
 	if err := parseAndSetDebugLevels(cfg.DebugLevel); err != nil {
 		err = fmt.Errorf("%s: %v", funcName, err.Error())
@@ -645,6 +868,13 @@ func loadConfig() (*config, error) {
 	}
 	cfg.PoliteiaAPIURL = urlPath
 
+	if cfg.PoliteiaCMSURL != "" {
+		cfg.PoliteiaCMSURL, err = retrieveRootPath(cfg.PoliteiaCMSURL)
+		if err != nil {
+			return loadConfigError(err)
+		}
+	}
+
 	// Check the supplied APIListen address
 	if cfg.APIListen == "" {
 		cfg.APIListen = defaultHost + ":" + defaultPort
@@ -655,6 +885,14 @@ func loadConfig() (*config, error) {
 		}
 	}
 
+	// Check the supplied GRPCListen address, if any.
+	if cfg.GRPCListen != "" {
+		cfg.GRPCListen, err = normalizeNetworkAddress(cfg.GRPCListen, defaultHost, defaultGRPCPort)
+		if err != nil {
+			return loadConfigError(err)
+		}
+	}
+
 	switch cfg.ServerHeader {
 	case "off":
 		cfg.ServerHeader = ""
@@ -664,10 +902,16 @@ func loadConfig() (*config, error) {
 
 	// Expand some additional paths.
 	cfg.DcrdCert = cleanAndExpandPath(cfg.DcrdCert)
+	for i := range cfg.DcrdFallbackCert {
+		cfg.DcrdFallbackCert[i] = cleanAndExpandPath(cfg.DcrdFallbackCert[i])
+	}
 	cfg.AgendasDBFileName = cleanAndExpandPath(cfg.AgendasDBFileName)
 	cfg.ProposalsFileName = cleanAndExpandPath(cfg.ProposalsFileName)
 	cfg.RateCertificate = cleanAndExpandPath(cfg.RateCertificate)
 	cfg.ChartsCacheDump = cleanAndExpandPath(cfg.ChartsCacheDump)
+	if cfg.AddressTagsFile != "" {
+		cfg.AddressTagsFile = cleanAndExpandPath(cfg.AddressTagsFile)
+	}
 
 	// Clean up the provided mainnet and testnet links, ensuring there is a single
 	// trailing slash.
@@ -782,6 +782,54 @@ func (ac *AddressCache) Clear(addrs []string) (numCleared int) {
 	return
 }
 
+// ConnectBlock moves the cache to a new best block that extends the previous
+// best block, prevHash. The data for the addresses in the new block, addrs, are
+// purged. The data for the other addresses that was valid at prevHash is still
+// valid, and is retagged with the new block. Any data computed at another block
+// is stale, and is purged.
+func (ac *AddressCache) ConnectBlock(block BlockID, prevHash chainhash.Hash, addrs []string) (numCleared int) {
+	return ac.moveTip(prevHash, block, addrs)
+}
+
+// DisconnectBlock moves the cache from an orphaned best block, hash, back to
+// its parent block, such as during a chain reorganization. The data for the
+// addresses in the orphaned block, addrs, are purged. The data for the other
+// addresses that was valid at the orphaned block is retagged with the parent
+// block, and any data computed at another block is purged.
+func (ac *AddressCache) DisconnectBlock(hash chainhash.Hash, parent BlockID, addrs []string) (numCleared int) {
+	return ac.moveTip(hash, parent, addrs)
+}
+
+// moveTip purges the data for the addresses affected by a change of the best
+// block from oldTip to newTip, and retags the data for the unaffected addresses
+// that was valid at oldTip.
+func (ac *AddressCache) moveTip(oldTip chainhash.Hash, newTip BlockID, addrs []string) (numCleared int) {
+	ac.mtx.Lock()
+	defer ac.mtx.Unlock()
+	for i := range addrs {
+		if _, found := ac.a[addrs[i]]; found {
+			delete(ac.a, addrs[i])
+			numCleared++
+		}
+	}
+
+	for addr, aci := range ac.a {
+		aci.mtx.Lock()
+		switch aci.hash {
+		case oldTip:
+			aci.hash = newTip.Hash
+			aci.height = newTip.Height
+		case newTip.Hash:
+			// Computed after the best block was updated.
+		default:
+			delete(ac.a, addr)
+			numCleared++
+		}
+		aci.mtx.Unlock()
+	}
+	return
+}
+
 // Balance attempts to retrieve an AddressBalance for the given address. The
 // BlockID for the block at which the cached data is valid is also returned. In
 // the event of a cache miss, both returned pointers will be nil.
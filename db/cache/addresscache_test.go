@@ -143,3 +143,51 @@ func TestAddressCacheItem_Transactions(t *testing.T) {
 		}
 	}
 }
+
+func TestAddressCache_ConnectDisconnectBlock(t *testing.T) {
+	ac := NewAddressCache(100, 10, 1000)
+	b0 := BlockID{Hash: chainhash.Hash{0}, Height: 100}
+	b1 := BlockID{Hash: chainhash.Hash{1}, Height: 101}
+	stale := BlockID{Hash: chainhash.Hash{9}, Height: 99}
+
+	store := func(addr string, block BlockID) {
+		if !ac.StoreBalance(addr, &dbtypes.AddressBalance{Address: addr}, &block) {
+			t.Fatalf("failed to store balance of %s", addr)
+		}
+	}
+	store("touched", b0)
+	store("untouched", b0)
+	store("stale", stale)
+
+	// Only the data for the addresses in the new block and the stale data are
+	// purged.
+	if numCleared := ac.ConnectBlock(b1, b0.Hash, []string{"touched", "uncached"}); numCleared != 2 {
+		t.Errorf("ConnectBlock cleared %d addresses, want 2", numCleared)
+	}
+	if bal, _ := ac.Balance("touched"); bal != nil {
+		t.Error("data for an address in the block was not purged")
+	}
+	if bal, _ := ac.Balance("stale"); bal != nil {
+		t.Error("stale data was not purged")
+	}
+	bal, blockID := ac.Balance("untouched")
+	if bal == nil {
+		t.Fatal("data for an address not in the block was purged")
+	}
+	if *blockID != b1 {
+		t.Errorf("data for an address not in the block at %v, want %v", *blockID, b1)
+	}
+
+	// Disconnecting the block purges the data for the addresses in the
+	// orphaned block, and moves the others back to its parent.
+	store("touched", b1)
+	if numCleared := ac.DisconnectBlock(b1.Hash, b0, []string{"touched"}); numCleared != 1 {
+		t.Errorf("DisconnectBlock cleared %d addresses, want 1", numCleared)
+	}
+	if bal, _ := ac.Balance("touched"); bal != nil {
+		t.Error("data for an address in the orphaned block was not purged")
+	}
+	if _, blockID = ac.Balance("untouched"); blockID == nil || *blockID != b0 {
+		t.Errorf("data for an address not in the orphaned block at %v, want %v", blockID, b0)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -33,8 +35,10 @@ const (
 	AnonymitySet    = "privacy-participation"
 	TicketPoolSize  = "ticket-pool-size"
 	TicketPoolValue = "ticket-pool-value"
+	TicketPool      = "ticket-pool"
 	WindMissedVotes = "missed-votes"
 	PercentStaked   = "stake-participation"
+	SolveTime       = "solvetime"
 
 	// Some chartResponse keys
 	heightKey       = "h"
@@ -56,6 +60,8 @@ const (
 	durationKey     = "duration"
 	workKey         = "work"
 	rateKey         = "rate"
+	solveTimeKey    = "solvetime"
+	avgKey          = "avg"
 )
 
 // binLevel specifies the granularity of data.
@@ -68,6 +74,7 @@ type axisType string
 // These are the recognized binLevel and axisType values.
 const (
 	DayBin     binLevel = "day"
+	HourBin    binLevel = "hour"
 	BlockBin   binLevel = "block"
 	WindowBin  binLevel = "window"
 	HeightAxis axisType = "height"
@@ -93,12 +100,43 @@ func ParseBin(bin string) binLevel {
 	switch binLevel(bin) {
 	case BlockBin:
 		return BlockBin
+	case HourBin:
+		return HourBin
 	case WindowBin:
 		return WindowBin
 	}
 	return DefaultBinLevel
 }
 
+// ParseRange parses a chart time range, such as 12h, 30d, 2w, 6m or 1y, to a
+// number of seconds. Months are 30 days and years are 365 days. An empty range
+// or "all" is the entire chart, and parses to zero.
+func ParseRange(r string) (uint64, error) {
+	if r == "" || r == "all" {
+		return 0, nil
+	}
+	var unit uint64
+	switch r[len(r)-1] {
+	case 'h':
+		unit = anHour
+	case 'd':
+		unit = aDay
+	case 'w':
+		unit = 7 * aDay
+	case 'm':
+		unit = 30 * aDay
+	case 'y':
+		unit = 365 * aDay
+	default:
+		return 0, InvalidRangeErr
+	}
+	n, err := strconv.ParseUint(r[:len(r)-1], 10, 32)
+	if err != nil || n == 0 {
+		return 0, InvalidRangeErr
+	}
+	return n * unit, nil
+}
+
 // ParseAxis returns the matching axis type, else the default of time axis.
 func ParseAxis(aType string) axisType {
 	switch axisType(aType) {
@@ -112,9 +150,14 @@ func ParseAxis(aType string) axisType {
 const (
 	// aDay defines the number of seconds in a day.
 	aDay = 86400
+	// anHour defines the number of seconds in an hour.
+	anHour = 3600
 	// HashrateAvgLength is the number of blocks used the rolling average for
 	// the network hashrate calculation.
 	HashrateAvgLength = 120
+	// SolveTimeAvgLength is the number of blocks in the rolling average of the
+	// block solve times.
+	SolveTimeAvgLength = 144
 )
 
 // cacheVersion helps detect when the cache data stored has changed its
@@ -145,6 +188,9 @@ const UnknownChartErr = ChartError("unknown chart")
 // ignore the bin flag.
 const InvalidBinErr = ChartError("invalid bin")
 
+// InvalidRangeErr is returned when a chart time range cannot be parsed.
+const InvalidRangeErr = ChartError("invalid range")
+
 // An interface for reading and setting the length of datasets.
 type lengther interface {
 	Length() int
@@ -225,6 +271,15 @@ func (data ChartUints) snip(max int) ChartUints {
 	return data[:max]
 }
 
+// from is the dataset starting at index i, or an empty dataset if it is not
+// longer than i.
+func (data ChartUints) from(i int) ChartUints {
+	if i > len(data) {
+		i = len(data)
+	}
+	return data[i:]
+}
+
 // Avg is the average value of a segment of the dataset.
 func (data ChartUints) Avg(s, e int) uint64 {
 	if e <= s {
@@ -316,6 +371,17 @@ func newDaySet(size int) *zoomSet {
 	return set
 }
 
+// Constructor for a sized zoomSet for hour-binned data. Only the ticket pool
+// data is binned by the hour.
+func newHourSet(size int) *zoomSet {
+	return &zoomSet{
+		Height:    newChartUints(size),
+		Time:      newChartUints(size),
+		PoolSize:  newChartUints(size),
+		PoolValue: newChartUints(size),
+	}
+}
+
 // windowSet is for data that only changes at the difficulty change interval,
 // 144 blocks on mainnet. stakeValid defines the number windows before the
 // stake validation height.
@@ -411,7 +477,8 @@ type ChartUpdater struct {
 // managing data validation and update concurrency, but does not perform any
 // data retrieval and must be used with care to keep the data valid. The Blocks
 // and Windows fields must be updated by (presumably) a database package. The
-// Days data is auto-generated from the Blocks data during Lengthen-ing.
+// Days and Hours data are auto-generated from the Blocks data during
+// Lengthen-ing.
 type ChartData struct {
 	mtx          sync.RWMutex
 	ctx          context.Context
@@ -420,6 +487,7 @@ type ChartData struct {
 	Blocks       *zoomSet
 	Windows      *windowSet
 	Days         *zoomSet
+	Hours        *zoomSet
 	cacheMtx     sync.RWMutex
 	cache        map[string]*cachedChart
 	updateMtx    sync.Mutex
@@ -459,8 +527,59 @@ func midnight(t uint64) (mid uint64) {
 	return
 }
 
-// Lengthen performs data validation and populates the Days zoomSet. If there is
-// an update to a zoomSet or windowSet, the cacheID will be incremented.
+// Reduce the timestamp to the start of its hour.
+func hourStart(t uint64) uint64 {
+	return t - t%anHour
+}
+
+// lengthenHours appends the hours completed since the last entry in the Hours
+// zoomSet, averaging the ticket pool data of the blocks in each hour. An hour
+// is complete once a block from a later hour is found. Hours without blocks
+// have no entry. It returns true if entries were added.
+func (charts *ChartData) lengthenHours() bool {
+	blocks, hours := charts.Blocks, charts.Hours
+
+	// Drop any hours that end with blocks that are no longer in the Blocks
+	// data, e.g. after a reorg.
+	hoursLen := len(hours.Height)
+	for hoursLen > 0 && hours.Height[hoursLen-1] >= uint64(len(blocks.Time)) {
+		hoursLen--
+	}
+	hours.Snip(hoursLen)
+
+	startIdx := 0
+	if hoursLen > 0 {
+		startIdx = int(hours.Height[hoursLen-1]) + 1
+	}
+	if startIdx >= len(blocks.Time) {
+		return false
+	}
+
+	end := hourStart(blocks.Time[len(blocks.Time)-1])
+	start := hourStart(blocks.Time[startIdx])
+	var added bool
+	for start < end && startIdx < len(blocks.Time) {
+		next := start + anHour
+		endIdx := startIdx
+		for endIdx < len(blocks.Time) && blocks.Time[endIdx] < next {
+			endIdx++
+		}
+		if endIdx > startIdx {
+			hours.Height = append(hours.Height, uint64(endIdx-1))
+			hours.Time = append(hours.Time, start)
+			hours.PoolSize = append(hours.PoolSize, blocks.PoolSize.Avg(startIdx, endIdx))
+			hours.PoolValue = append(hours.PoolValue, blocks.PoolValue.Avg(startIdx, endIdx))
+			added = true
+		}
+		startIdx = endIdx
+		start = next
+	}
+	return added
+}
+
+// Lengthen performs data validation and populates the Days and Hours zoomSets.
+// If there is an update to a zoomSet or windowSet, the cacheID will be
+// incremented.
 func (charts *ChartData) Lengthen() error {
 	charts.mtx.Lock()
 	defer charts.mtx.Unlock()
@@ -565,6 +684,8 @@ func (charts *ChartData) Lengthen() error {
 		log.Warnf("(*ChartData).Lengthen: Zero-length day-binned data!")
 	}
 
+	hoursAdded := charts.lengthenHours()
+
 	charts.cacheMtx.Lock()
 	defer charts.cacheMtx.Unlock()
 	// The cacheID for day-binned data, only increment the cacheID when entries
@@ -572,6 +693,9 @@ func (charts *ChartData) Lengthen() error {
 	if len(intervals) > 0 {
 		days.cacheID++
 	}
+	if hoursAdded {
+		charts.Hours.cacheID++
+	}
 	// For blocks and windows, the cacheID is the last timestamp.
 	charts.Blocks.cacheID = blocks.Time[len(blocks.Time)-1]
 	charts.Windows.cacheID = windows.Time[len(windows.Time)-1]
@@ -592,6 +716,13 @@ func (charts *ChartData) ReorgHandler(reorg *txhelpers.ReorgData) error {
 	daysLen -= 2
 	log.Debugf("ChartData.ReorgHandler snipping days height to %d", daysLen)
 	charts.Days.Snip(daysLen)
+	// Drop the hours ending at or after the new height.
+	hoursLen := len(charts.Hours.Height)
+	for hoursLen > 0 && charts.Hours.Height[hoursLen-1] >= uint64(newHeight) {
+		hoursLen--
+	}
+	log.Debugf("ChartData.ReorgHandler snipping hours to %d", hoursLen)
+	charts.Hours.Snip(hoursLen)
 	// Drop the last window
 	windowsLen := len(charts.Windows.Time)
 	windowsLen--
@@ -684,6 +815,7 @@ func (charts *ChartData) readCacheFile(filePath string) error {
 		charts.Blocks.Snip(0)
 		charts.Windows.Snip(0)
 		charts.Days.Snip(0)
+		charts.Hours.Snip(0)
 	}
 
 	return nil
@@ -900,6 +1032,7 @@ func NewChartData(ctx context.Context, height uint32, chainParams *chaincfg.Para
 	// https://github.com/golang/go/blob/87e48c5afdcf5e01bb2b7f51b7643e8901f4b7f9/src/runtime/slice.go#L100-L112
 	size := int(height * 5 / 4)
 	days := int(time.Since(genesis)/time.Hour/24)*5/4 + 1 // at least one day
+	hours := days * 24
 	windows := int(base64Height/chainParams.StakeDiffWindowSize+1) * 5 / 4
 
 	return &ChartData{
@@ -909,6 +1042,7 @@ func NewChartData(ctx context.Context, height uint32, chainParams *chaincfg.Para
 		Blocks:       newBlockSet(size),
 		Windows:      newWindowSet(windows),
 		Days:         newDaySet(days),
+		Hours:        newHourSet(hours),
 		cache:        make(map[string]*cachedChart),
 		updaters:     make([]ChartUpdater, 0),
 	}
@@ -928,6 +1062,8 @@ func (charts *ChartData) cacheID(bin binLevel) uint64 {
 		return charts.Blocks.cacheID
 	case DayBin:
 		return charts.Days.cacheID
+	case HourBin:
+		return charts.Hours.cacheID
 	case WindowBin:
 		return charts.Windows.cacheID
 	}
@@ -979,6 +1115,15 @@ var chartMakers = map[string]ChartMaker{
 	TicketPoolValue: poolValueChart,
 	WindMissedVotes: missedVotesChart,
 	PercentStaked:   stakedCoinsChart,
+	SolveTime:       solveTimeChart,
+}
+
+// RangedChartMaker is a ChartMaker for a chart that can be limited to a time
+// range, in seconds, ending at the last entry. A zero span is the entire chart.
+type RangedChartMaker func(charts *ChartData, bin binLevel, axis axisType, span uint64) ([]byte, error)
+
+var rangedChartMakers = map[string]RangedChartMaker{
+	TicketPool: ticketPoolChart,
 }
 
 // Chart will return a JSON-encoded chartResponse of the provided chart,
@@ -994,6 +1139,9 @@ func (charts *ChartData) Chart(chartID, binString, axisString string) ([]byte, e
 	if found && cache.cacheID == cacheID {
 		return cache.data, nil
 	}
+	if _, ranged := rangedChartMakers[chartID]; ranged {
+		return charts.RangedChart(chartID, binString, axisString, "")
+	}
 	maker, hasMaker := chartMakers[chartID]
 	if !hasMaker {
 		return nil, UnknownChartErr
@@ -1010,6 +1158,55 @@ func (charts *ChartData) Chart(chartID, binString, axisString string) ([]byte, e
 	return data, nil
 }
 
+// RangedChart is like Chart, but limits the chart to the time range, such as
+// 30d or 1y, ending at the last entry of the binned data. See ParseRange for
+// the range format. Only charts with a RangedChartMaker support a range.
+func (charts *ChartData) RangedChart(chartID, binString, axisString, rangeString string) ([]byte, error) {
+	maker, hasMaker := rangedChartMakers[chartID]
+	if !hasMaker {
+		if _, known := chartMakers[chartID]; known {
+			return nil, InvalidRangeErr
+		}
+		return nil, UnknownChartErr
+	}
+	span, err := ParseRange(rangeString)
+	if err != nil {
+		return nil, err
+	}
+	bin := ParseBin(binString)
+	axis := ParseAxis(axisString)
+	// The range is part of the cached chart's ID.
+	rangedID := chartID + "-" + strconv.FormatUint(span, 10)
+	cache, found, cacheID := charts.getCache(rangedID, bin, axis)
+	if found && cache.cacheID == cacheID {
+		return cache.data, nil
+	}
+	charts.mtx.RLock()
+	data, err := maker(charts, bin, axis, span)
+	charts.mtx.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	charts.cacheChart(rangedID, bin, axis, data)
+	return data, nil
+}
+
+// rangeStart is the index of the first entry in the time set that is within
+// span seconds of the last entry. A zero span selects the entire set.
+func rangeStart(times ChartUints, span uint64) int {
+	if span == 0 || len(times) == 0 {
+		return 0
+	}
+	last := times[len(times)-1]
+	if span >= last {
+		return 0
+	}
+	since := last - span
+	return sort.Search(len(times), func(i int) bool {
+		return times[i] >= since
+	})
+}
+
 // Encode the data sets. Optionally add arbitrary additional data as part of the
 // chartResponse seed. A nil seed is allowed.
 func encode(sets lengtherMap, seed chartResponse) ([]byte, error) {
@@ -1268,6 +1465,78 @@ func durationBTWChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, e
 	return nil, InvalidBinErr
 }
 
+// solveTimes computes the solve time of each block after the first from the
+// block times, which is negative for a block with a timestamp earlier than its
+// parent's, and the rolling average of the solve times of up to
+// SolveTimeAvgLength blocks. Unlike blockTimes, negative solve times are kept.
+func solveTimes(times ChartUints) (solve, avg ChartFloats) {
+	if len(times) < 2 {
+		return ChartFloats{}, ChartFloats{}
+	}
+	solve = make(ChartFloats, 0, len(times)-1)
+	avg = make(ChartFloats, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		solve = append(solve, float64(int64(times[i]-times[i-1])))
+		// The sum of the solve times in the window is the time between its
+		// first parent and its last block.
+		start := i - SolveTimeAvgLength
+		if start < 0 {
+			start = 0
+		}
+		avg = append(avg, float64(int64(times[i]-times[start]))/float64(i-start))
+	}
+	return
+}
+
+// solveTimeChart is the solve time of every block after the genesis block,
+// with the rolling average. Only the block bin is supported.
+func solveTimeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, error) {
+	if bin != BlockBin {
+		return nil, InvalidBinErr
+	}
+	seed := binAxisSeed(bin, axis)
+	solve, avg := solveTimes(charts.Blocks.Time)
+	sets := lengtherMap{
+		solveTimeKey: solve,
+		avgKey:       avg,
+	}
+	if axis == HeightAxis {
+		seed[offsetKey] = 1
+	} else if len(charts.Blocks.Time) > 0 {
+		sets[timeKey] = charts.Blocks.Time[1:]
+	} else {
+		sets[timeKey] = ChartUints{}
+	}
+	return encode(sets, seed)
+}
+
+// TimestampAnomaly is a block with a timestamp earlier than its parent's.
+type TimestampAnomaly struct {
+	Height     int64 `json:"height"`
+	Time       int64 `json:"time"`
+	ParentTime int64 `json:"parent_time"`
+}
+
+// TimestampAnomalies finds the blocks with timestamps earlier than their
+// parents', most recent first. The block data starts at the genesis block, so
+// the index of a block is its height.
+func (charts *ChartData) TimestampAnomalies() []TimestampAnomaly {
+	charts.mtx.RLock()
+	defer charts.mtx.RUnlock()
+	anomalies := []TimestampAnomaly{}
+	times := charts.Blocks.Time
+	for i := len(times) - 1; i > 0; i-- {
+		if times[i] < times[i-1] {
+			anomalies = append(anomalies, TimestampAnomaly{
+				Height:     int64(i),
+				Time:       int64(times[i]),
+				ParentTime: int64(times[i-1]),
+			})
+		}
+	}
+	return anomalies
+}
+
 // hashrate converts the provided chainwork data to hashrate data. Since
 // hashrates are averaged over HashrateAvgLength blocks, the returned slice
 // is HashrateAvgLength shorter than the provided chainwork. A time slice is
@@ -1511,17 +1780,21 @@ func ticketPoolSizeChart(charts *ChartData, bin binLevel, axis axisType) ([]byte
 				countKey: charts.Blocks.PoolSize,
 			}, seed)
 		}
-	case DayBin:
+	case DayBin, HourBin:
+		set := charts.Days
+		if bin == HourBin {
+			set = charts.Hours
+		}
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				heightKey: charts.Days.Height,
-				countKey:  charts.Days.PoolSize,
+				heightKey: set.Height,
+				countKey:  set.PoolSize,
 			}, seed)
 		default:
 			return encode(lengtherMap{
-				timeKey:  charts.Days.Time,
-				countKey: charts.Days.PoolSize,
+				timeKey:  set.Time,
+				countKey: set.PoolSize,
 			}, seed)
 		}
 	}
@@ -1543,23 +1816,59 @@ func poolValueChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, err
 				poolValKey: charts.Blocks.PoolValue,
 			}, seed)
 		}
-	case DayBin:
+	case DayBin, HourBin:
+		set := charts.Days
+		if bin == HourBin {
+			set = charts.Hours
+		}
 		switch axis {
 		case HeightAxis:
 			return encode(lengtherMap{
-				heightKey:  charts.Days.Height,
-				poolValKey: charts.Days.PoolValue,
+				heightKey:  set.Height,
+				poolValKey: set.PoolValue,
 			}, seed)
 		default:
 			return encode(lengtherMap{
-				timeKey:    charts.Days.Time,
-				poolValKey: charts.Days.PoolValue,
+				timeKey:    set.Time,
+				poolValKey: set.PoolValue,
 			}, seed)
 		}
 	}
 	return nil, InvalidBinErr
 }
 
+// ticketPoolChart is the ticket pool size and value, binned by block, hour or
+// day. Block-binned data on the height axis has an offset, the height of the
+// first block, instead of a height set.
+func ticketPoolChart(charts *ChartData, bin binLevel, axis axisType, span uint64) ([]byte, error) {
+	var set *zoomSet
+	switch bin {
+	case BlockBin:
+		set = charts.Blocks
+	case HourBin:
+		set = charts.Hours
+	case DayBin:
+		set = charts.Days
+	default:
+		return nil, InvalidBinErr
+	}
+	start := rangeStart(set.Time, span)
+	seed := binAxisSeed(bin, axis)
+	sets := lengtherMap{
+		countKey:   set.PoolSize.from(start),
+		poolValKey: set.PoolValue.from(start),
+	}
+	switch {
+	case axis != HeightAxis:
+		sets[timeKey] = set.Time.from(start)
+	case bin == BlockBin:
+		seed[offsetKey] = start
+	default:
+		sets[heightKey] = set.Height.from(start)
+	}
+	return encode(sets, seed)
+}
+
 func missedVotesChart(charts *ChartData, _ binLevel, axis axisType) ([]byte, error) {
 	prestakeWindows := int(charts.StartPOS / charts.DiffInterval)
 	if prestakeWindows >= len(charts.Windows.MissedVotes) ||
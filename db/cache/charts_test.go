@@ -266,6 +266,85 @@ func TestChartsCache(t *testing.T) {
 	})
 }
 
+// TestTicketPoolChart tests the hour-binned ticket pool data and the ranged
+// ticket pool chart.
+func TestTicketPoolChart(t *testing.T) {
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	charts := NewChartData(ctx, 0, chaincfg.MainNetParams())
+
+	start := uint64(10 * aDay)
+	times := ChartUints{start + 10, start + 20, start + anHour + 5,
+		start + 3*anHour + 1, start + 3*anHour + 2}
+	for i, blockTime := range times {
+		v := uint64(2*i + 1)
+		charts.Blocks.Height = append(charts.Blocks.Height, uint64(i))
+		charts.Blocks.Time = append(charts.Blocks.Time, blockTime)
+		charts.Blocks.PoolSize = append(charts.Blocks.PoolSize, v)
+		charts.Blocks.PoolValue = append(charts.Blocks.PoolValue, 10*v)
+		charts.Blocks.BlockSize = append(charts.Blocks.BlockSize, v)
+		charts.Blocks.TxCount = append(charts.Blocks.TxCount, v)
+		charts.Blocks.NewAtoms = append(charts.Blocks.NewAtoms, v)
+		charts.Blocks.Chainwork = append(charts.Blocks.Chainwork, v)
+		charts.Blocks.Fees = append(charts.Blocks.Fees, v)
+		charts.Blocks.TotalMixed = append(charts.Blocks.TotalMixed, v)
+		charts.Blocks.AnonymitySet = append(charts.Blocks.AnonymitySet, v)
+	}
+	charts.Windows.Time = ChartUints{0}
+	charts.Windows.PowDiff = ChartFloats{0}
+	charts.Windows.TicketPrice = ChartUints{0}
+	charts.Windows.StakeCount = ChartUints{0}
+	charts.Windows.MissedVotes = ChartUints{0}
+
+	if err := charts.Lengthen(); err != nil {
+		t.Fatalf("Lengthen error: %v", err)
+	}
+	// The last hour is incomplete, and the hour without blocks is skipped.
+	if !reflect.DeepEqual(charts.Hours.Time, ChartUints{start, start + anHour}) {
+		t.Fatalf("unexpected hour times %v", charts.Hours.Time)
+	}
+	if !reflect.DeepEqual(charts.Hours.Height, ChartUints{1, 2}) {
+		t.Fatalf("unexpected hour heights %v", charts.Hours.Height)
+	}
+	if !reflect.DeepEqual(charts.Hours.PoolSize, ChartUints{2, 5}) {
+		t.Fatalf("unexpected hour pool sizes %v", charts.Hours.PoolSize)
+	}
+
+	chart, err := charts.RangedChart(TicketPool, string(HourBin), string(TimeAxis), "all")
+	if err != nil {
+		t.Fatalf("RangedChart error: %v", err)
+	}
+	expected := fmt.Sprintf(`{"axis":"time","bin":"hour","count":[2,5],"poolval":[20,50],"t":[%d,%d]}`,
+		start, start+anHour)
+	if string(chart) != expected {
+		t.Fatalf("unexpected chart json %s", chart)
+	}
+
+	chart, err = charts.RangedChart(TicketPool, string(BlockBin), string(HeightAxis), "1h")
+	if err != nil {
+		t.Fatalf("RangedChart error: %v", err)
+	}
+	if string(chart) != `{"axis":"height","bin":"block","count":[7,9],"offset":3,"poolval":[70,90]}` {
+		t.Fatalf("unexpected chart json %s", chart)
+	}
+
+	if _, err = charts.RangedChart(TicketPool, string(BlockBin), "", "1x"); err != InvalidRangeErr {
+		t.Fatalf("expected InvalidRangeErr, got %v", err)
+	}
+	if _, err = charts.RangedChart(BlockSize, string(BlockBin), "", "1y"); err != InvalidRangeErr {
+		t.Fatalf("expected InvalidRangeErr, got %v", err)
+	}
+
+	// Hours ending with removed blocks are dropped.
+	charts.Blocks.Snip(2)
+	if err = charts.Lengthen(); err != nil {
+		t.Fatalf("Lengthen error: %v", err)
+	}
+	if !reflect.DeepEqual(charts.Hours.Height, ChartUints{1}) {
+		t.Fatalf("unexpected hour heights after snip %v", charts.Hours.Height)
+	}
+}
+
 func TestChartReorg(t *testing.T) {
 	ctx, shutdown := context.WithCancel(context.Background())
 	defer shutdown()
@@ -307,6 +386,13 @@ func TestChartReorg(t *testing.T) {
 			Fees:       newUints(),
 			TotalMixed: newUints(),
 		}
+		charts.Hours = &zoomSet{
+			cacheID:   0,
+			Height:    newUints(),
+			Time:      newUints(),
+			PoolSize:  newUints(),
+			PoolValue: newUints(),
+		}
 	}
 	// this test reorg will replace the entire chain.
 
@@ -348,3 +434,36 @@ func TestChartReorg(t *testing.T) {
 	resetCharts()
 	testReorg(2, 2, 1, 1, 2)
 }
+
+func TestSolveTimeChart(t *testing.T) {
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	charts := NewChartData(ctx, 0, chaincfg.MainNetParams())
+	charts.Blocks.Height = ChartUints{0, 1, 2, 3}
+	charts.Blocks.Time = ChartUints{1000, 1300, 1200, 1500}
+
+	solve, avg := solveTimes(charts.Blocks.Time)
+	if !reflect.DeepEqual(solve, ChartFloats{300, -100, 300}) {
+		t.Fatalf("unexpected solve times %v", solve)
+	}
+	if !reflect.DeepEqual(avg, ChartFloats{300, 100, 500.0 / 3}) {
+		t.Fatalf("unexpected average solve times %v", avg)
+	}
+
+	chart, err := charts.Chart(SolveTime, string(BlockBin), string(TimeAxis))
+	if err != nil {
+		t.Fatalf("Chart error: %v", err)
+	}
+	if string(chart) != `{"avg":[300,100,166.66666666666666],"axis":"time","bin":"block","solvetime":[300,-100,300],"t":[1300,1200,1500]}` {
+		t.Fatalf("unexpected chart json %s", chart)
+	}
+	if _, err = charts.Chart(SolveTime, string(DayBin), string(TimeAxis)); err != InvalidBinErr {
+		t.Fatalf("expected InvalidBinErr, got %v", err)
+	}
+
+	anomalies := charts.TimestampAnomalies()
+	expected := []TimestampAnomaly{{Height: 2, Time: 1200, ParentTime: 1300}}
+	if !reflect.DeepEqual(anomalies, expected) {
+		t.Fatalf("unexpected timestamp anomalies %v", anomalies)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// RawCache is a cache of serialized blocks and transactions, keyed by hash,
+// holding up to a maximum number of bytes. The least recently used entries are
+// evicted to make room for new entries. The cached data must not be modified.
+// Use NewRawCache to create a RawCache.
+type RawCache struct {
+	mtx      sync.Mutex
+	maxBytes int
+	size     int
+	entries  map[string]*list.Element
+	lru      *list.List // front is the most recently used
+}
+
+type rawEntry struct {
+	key  string
+	data []byte
+}
+
+// NewRawCache creates a RawCache holding up to maxBytes bytes of data.
+func NewRawCache(maxBytes int) *RawCache {
+	return &RawCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Get retrieves the data cached for the key, if any.
+func (c *RawCache) Get(key string) ([]byte, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*rawEntry).data, true
+}
+
+// Set caches the data for the key, evicting the least recently used entries
+// as needed. Data larger than the cache is not cached.
+func (c *RawCache) Set(key string, data []byte) {
+	if len(data) > c.maxBytes {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*rawEntry)
+		c.size += len(data) - len(entry.data)
+		entry.data = data
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.lru.PushFront(&rawEntry{key, data})
+		c.size += len(data)
+	}
+
+	for c.size > c.maxBytes {
+		oldest := c.lru.Back()
+		entry := c.lru.Remove(oldest).(*rawEntry)
+		delete(c.entries, entry.key)
+		c.size -= len(entry.data)
+	}
+}
+
+// Size returns the number of bytes of data cached.
+func (c *RawCache) Size() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.size
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRawCache(t *testing.T) {
+	c := NewRawCache(10)
+	c.Set("a", []byte("aaaa"))
+	c.Set("b", []byte("bbbb"))
+	if size := c.Size(); size != 8 {
+		t.Fatalf("size %d, want 8", size)
+	}
+
+	// Using a makes b the least recently used entry, which is evicted.
+	if data, ok := c.Get("a"); !ok || !bytes.Equal(data, []byte("aaaa")) {
+		t.Fatalf("Get(a) = %q, %v", data, ok)
+	}
+	c.Set("c", []byte("cccc"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a was evicted")
+	}
+	if size := c.Size(); size != 8 {
+		t.Errorf("size %d, want 8", size)
+	}
+
+	// Replacing an entry updates the size.
+	c.Set("c", []byte("cc"))
+	if size := c.Size(); size != 6 {
+		t.Errorf("size %d, want 6", size)
+	}
+
+	// Data larger than the cache is not cached.
+	c.Set("d", make([]byte, 11))
+	if _, ok := c.Get("d"); ok {
+		t.Error("oversized data was cached")
+	}
+	if size := c.Size(); size != 6 {
+		t.Errorf("size %d, want 6", size)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"github.com/decred/dcrd/blockchain/stake/v2"
 	"github.com/decred/dcrd/chaincfg/v2"
@@ -137,6 +138,8 @@ func processTransactions(msgBlock *wire.MsgBlock, tree int8, chainParams *chainc
 				ScriptHex:   txin.SignatureScript,
 				IsValid:     dbTx.IsValid,
 				IsMainchain: isMainchain,
+
+				TxBlockHeight: dbTx.BlockHeight,
 			})
 		}
 
@@ -155,6 +158,7 @@ func processTransactions(msgBlock *wire.MsgBlock, tree int8, chainParams *chainc
 				Version:      txout.Version,
 				ScriptPubKey: txout.PkScript,
 				Mixed:        mixDenom == txout.Value, // later, check ticket and vote outputs against the spent outputs' mixed status
+				BlockHeight:  dbTx.BlockHeight,
 			}
 			scriptClass, scriptAddrs, reqSigs, err := txscript.ExtractPkScriptAddrs(
 				vout.Version, vout.ScriptPubKey, chainParams)
@@ -179,3 +183,61 @@ func processTransactions(msgBlock *wire.MsgBlock, tree int8, chainParams *chainc
 
 	return dbTransactions, dbTxVouts, dbTxVins
 }
+
+// ExtractScriptTypes classifies the output scripts of the block's regular and
+// stake transactions, returning the number and value of the outputs of each
+// script type, ordered by type, and the outputs with nonstandard scripts.
+func ExtractScriptTypes(msgBlock *wire.MsgBlock) ([]*ScriptTypeCount, []*NonstandardOutput) {
+	blockHash := msgBlock.BlockHash().String()
+	blockTime := NewTimeDef(msgBlock.Header.Timestamp)
+	countsByType := make(map[string]*ScriptTypeCount)
+	var nonstandard []*NonstandardOutput
+	extract := func(txs []*wire.MsgTx, tree int8) {
+		for _, tx := range txs {
+			var txHash string
+			for io, txout := range tx.TxOut {
+				class := txscript.GetScriptClass(txout.Version, txout.PkScript)
+				scriptType := class.String()
+				stc, found := countsByType[scriptType]
+				if !found {
+					stc = &ScriptTypeCount{ScriptType: scriptType}
+					countsByType[scriptType] = stc
+				}
+				stc.Count++
+				stc.Value += txout.Value
+
+				if class != txscript.NonStandardTy {
+					continue
+				}
+				if txHash == "" {
+					txHash = tx.TxHash().String()
+				}
+				// The disassembly of an unparsable script ends with [error].
+				disasm, _ := txscript.DisasmString(txout.PkScript)
+				nonstandard = append(nonstandard, &NonstandardOutput{
+					TxHash:        txHash,
+					Vout:          uint32(io),
+					Tree:          tree,
+					BlockHash:     blockHash,
+					BlockHeight:   int64(msgBlock.Header.Height),
+					BlockTime:     blockTime,
+					Value:         txout.Value,
+					ScriptVersion: txout.Version,
+					PkScript:      hex.EncodeToString(txout.PkScript),
+					Disasm:        disasm,
+				})
+			}
+		}
+	}
+	extract(msgBlock.Transactions, wire.TxTreeRegular)
+	extract(msgBlock.STransactions, wire.TxTreeStake)
+
+	counts := make([]*ScriptTypeCount, 0, len(countsByType))
+	for _, stc := range countsByType {
+		counts = append(counts, stc)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].ScriptType < counts[j].ScriptType
+	})
+	return counts, nonstandard
+}
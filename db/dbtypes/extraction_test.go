@@ -0,0 +1,64 @@
+package dbtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestExtractScriptTypes(t *testing.T) {
+	pkHash := bytes.Repeat([]byte{0x11}, 20)
+	p2pkh := append(append([]byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20},
+		pkHash...), txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+	nullData := []byte{txscript.OP_RETURN, txscript.OP_DATA_4, 1, 2, 3, 4}
+
+	tx := wire.NewMsgTx()
+	tx.AddTxOut(&wire.TxOut{Value: 100, PkScript: p2pkh})
+	// The same script is nonstandard with a nonzero script version.
+	tx.AddTxOut(&wire.TxOut{Value: 200, Version: 1, PkScript: p2pkh})
+	stx := wire.NewMsgTx()
+	stx.AddTxOut(&wire.TxOut{Value: 300, PkScript: p2pkh})
+	stx.AddTxOut(&wire.TxOut{Value: 0, PkScript: nullData})
+
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Height:    1234,
+			Timestamp: time.Unix(1600000000, 0),
+		},
+		Transactions:  []*wire.MsgTx{tx},
+		STransactions: []*wire.MsgTx{stx},
+	}
+
+	counts, nonstandard := ExtractScriptTypes(block)
+	want := []ScriptTypeCount{
+		{ScriptType: "nonstandard", Count: 1, Value: 200},
+		{ScriptType: "nulldata", Count: 1, Value: 0},
+		{ScriptType: "pubkeyhash", Count: 2, Value: 400},
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("got %d script types, want %d", len(counts), len(want))
+	}
+	for i := range want {
+		if *counts[i] != want[i] {
+			t.Errorf("script type %d: got %+v, want %+v", i, *counts[i], want[i])
+		}
+	}
+
+	if len(nonstandard) != 1 {
+		t.Fatalf("got %d nonstandard outputs, want 1", len(nonstandard))
+	}
+	out := nonstandard[0]
+	if out.TxHash != tx.TxHash().String() || out.Vout != 1 || out.Tree != wire.TxTreeRegular ||
+		out.BlockHash != block.BlockHash().String() || out.BlockHeight != 1234 ||
+		out.Value != 200 || out.ScriptVersion != 1 || out.PkScript != hex.EncodeToString(p2pkh) {
+		t.Errorf("unexpected nonstandard output %+v", out)
+	}
+	wantDisasm := "OP_DUP OP_HASH160 " + hex.EncodeToString(pkHash) + " OP_EQUALVERIFY OP_CHECKSIG"
+	if out.Disasm != wantDisasm {
+		t.Errorf("got disassembly %q, want %q", out.Disasm, wantDisasm)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -27,6 +28,10 @@ var (
 	TimeoutPrefix       = "TIMEOUT of PostgreSQL query"
 )
 
+// ErrPruned is returned by queries of the inputs and outputs of transactions
+// in the blocks deleted by the pruning mode of the DB.
+var ErrPruned = errors.New("the inputs and outputs of the transaction have been pruned")
+
 // IsTimeout checks if the message is prefixed with the expected DB timeout
 // message prefix.
 func IsTimeout(msg string) bool {
@@ -761,6 +766,8 @@ type Vout struct {
 	ScriptPubKey     []byte           `json:"pkScriptHex"`
 	ScriptPubKeyData ScriptPubKeyData `json:"pkScript"`
 	Mixed            bool             `json:"mixed"`
+	// BlockHeight is the height of the block containing the transaction.
+	BlockHeight int64 `json:"block_height"`
 }
 
 // UTXOData stores an address and value associated with a transaction output.
@@ -779,6 +786,18 @@ type UTXO struct {
 	UTXOData
 }
 
+// UTXOSetEntry is an output in the UTXO set, funded by a transaction in the
+// mainchain block at Height.
+type UTXOSetEntry struct {
+	TxHash     string
+	TxIndex    uint32
+	TxTree     int8
+	Height     int64
+	Value      int64
+	ScriptType string
+	Addresses  []string
+}
+
 // AddressRow represents a row in the addresses table
 type AddressRow struct {
 	Address        string
@@ -1537,6 +1556,33 @@ type ChartsData struct {
 	NetHash     []uint64  `json:"nethash,omitempty"`
 }
 
+// PoliteiaProposal is the metadata and vote summary of a Politeia proposal.
+// Status and VoteStatus are the descriptions of the proposal and vote statuses,
+// e.g. "Public" and "Started". Yes and No are the vote counts.
+type PoliteiaProposal struct {
+	Token            string
+	Name             string
+	Username         string
+	Status           string
+	VoteStatus       string
+	NumComments      int32
+	PublishedAt      TimeDef
+	EndHeight        int64
+	EligibleTickets  int64
+	QuorumPercentage uint32
+	PassPercentage   uint32
+	Yes              int64
+	No               int64
+	TotalVotes       int64
+}
+
+// ProposalCastVote is a ticket's vote on a Politeia proposal. Choice is the
+// capitalized vote option, e.g. "Yes" or "No".
+type ProposalCastVote struct {
+	Ticket string
+	Choice string
+}
+
 // ProposalChartsData defines the data used to plot proposal votes charts.
 type ProposalChartsData struct {
 	Yes  []uint64  `json:"yes,omitempty"`
@@ -1544,6 +1590,21 @@ type ProposalChartsData struct {
 	Time []TimeDef `json:"time,omitempty"`
 }
 
+// ProposalVoteSnapshot is the vote tally of a Politeia proposal at a block
+// height while its vote is live. QuorumPercentage and PassPercentage are the
+// percentages of the eligible tickets and of the votes cast required for a
+// quorum and for approval.
+type ProposalVoteSnapshot struct {
+	Token            string
+	Height           int64
+	Time             TimeDef
+	Yes              int64
+	No               int64
+	EligibleTickets  int64
+	QuorumPercentage uint32
+	PassPercentage   uint32
+}
+
 // ScriptPubKeyData is part of the result of decodescript(ScriptPubKeyHex)
 type ScriptPubKeyData struct {
 	ReqSigs   uint32   `json:"reqSigs"`
@@ -1569,6 +1630,9 @@ type VinTxProperty struct {
 	IsValid     bool    `json:"is_valid"`
 	IsMainchain bool    `json:"is_mainchain"`
 	Time        TimeDef `json:"time"`
+	// TxBlockHeight is the height of the block containing the spending
+	// transaction, while BlockHeight is that of the previous outpoint.
+	TxBlockHeight int64 `json:"tx_block_height"`
 }
 
 // PoolTicketsData defines the real time data
@@ -1698,6 +1762,220 @@ type BlockStatus struct {
 	NextHash    string `json:"next_hash"`
 }
 
+// Reorg describes a chain reorganization. Depth is the number of blocks
+// orphaned from the old main chain, and OrphanedTxns is the number of regular
+// and stake transactions in those blocks.
+type Reorg struct {
+	OldTipHash     string  `json:"old_tip_hash"`
+	OldTipHeight   int64   `json:"old_tip_height"`
+	NewTipHash     string  `json:"new_tip_hash"`
+	NewTipHeight   int64   `json:"new_tip_height"`
+	CommonAncestor string  `json:"common_ancestor"`
+	Depth          int64   `json:"depth"`
+	OrphanedTxns   int64   `json:"orphaned_txns"`
+	Time           TimeDef `json:"time"`
+}
+
+// VSPStats is a snapshot of the statistics reported by a voting service
+// provider (VSP), identified by the URL of its vspd instance. Live, Voted and
+// Missed are the VSP's ticket counts. When the VSP is not Reachable, the
+// other fields are those of the last successful poll.
+type VSPStats struct {
+	VSP           string  `json:"vsp"`
+	Time          TimeDef `json:"time"`
+	Reachable     bool    `json:"reachable"`
+	Closed        bool    `json:"closed"`
+	VspdVersion   string  `json:"vspd_version"`
+	FeePercentage float64 `json:"fee_percentage"`
+	Live          int64   `json:"live"`
+	Voted         int64   `json:"voted"`
+	Missed        int64   `json:"missed"`
+}
+
+// DCRPriceCurrency is the fiat currency of the imported historical DCR prices,
+// and of the fiat-valued chart series.
+const DCRPriceCurrency = "USD"
+
+// DCRPrice is the closing price of DCR in a fiat currency on a day (UTC), as
+// reported by the named historical price source.
+type DCRPrice struct {
+	Day      time.Time `json:"day"`
+	Currency string    `json:"currency"`
+	Price    float64   `json:"price"`
+	Source   string    `json:"source"`
+}
+
+// AggregatedMarket is the exchange name of the market candles of the
+// volume-weighted DCR-BTC price of all the monitored exchanges.
+const AggregatedMarket = "aggregated"
+
+// MarketCandleBins are the widths of the stored market candles, by bin name.
+var MarketCandleBins = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// MarketPrice is a sample of the DCR-BTC price of an exchange, or of the
+// AggregatedMarket, at a time.
+type MarketPrice struct {
+	Exchange string
+	Time     time.Time
+	Price    float64
+}
+
+// MarketCandle is an OHLCV candle of the DCR-BTC market of an exchange, or of
+// the AggregatedMarket, starting at Start and as wide as its bin. The prices
+// are in BTC and the volume in DCR.
+type MarketCandle struct {
+	Exchange string    `json:"exchange"`
+	Bin      string    `json:"bin"`
+	Start    time.Time `json:"start"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	Volume   float64   `json:"volume"`
+}
+
+// ScriptTypeCount is the number and total value, in atoms, of the outputs of a
+// block with a script type (e.g. pubkeyhash or nonstandard).
+type ScriptTypeCount struct {
+	ScriptType string
+	Count      int64
+	Value      int64
+}
+
+// NonstandardOutput is a transaction output whose script is not of a
+// recognized form, including the scripts of nonzero versions, with the
+// disassembly of the script. Value is in atoms.
+type NonstandardOutput struct {
+	TxHash        string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	BlockHash     string  `json:"block_hash"`
+	BlockHeight   int64   `json:"block_height"`
+	BlockTime     TimeDef `json:"block_time"`
+	Value         int64   `json:"value"`
+	ScriptVersion uint16  `json:"script_version"`
+	PkScript      string  `json:"pkscript"`
+	Disasm        string  `json:"disasm"`
+}
+
+// AddressWatch is the registration of a callback URL to be notified of the
+// transactions involving an address. Secret is the key of the HMAC-SHA256
+// signature of each notification, and is only revealed at registration.
+type AddressWatch struct {
+	ID          int64   `json:"id"`
+	Address     string  `json:"address"`
+	CallbackURL string  `json:"callback_url"`
+	Secret      string  `json:"secret,omitempty"`
+	Created     TimeDef `json:"created"`
+}
+
+// FaucetGrant is a payment of testnet or simnet coins by the faucet to an
+// address. Amount is in atoms.
+type FaucetGrant struct {
+	ID      int64   `json:"id"`
+	Address string  `json:"address"`
+	Amount  int64   `json:"amount"`
+	TxHash  string  `json:"txid"`
+	Time    TimeDef `json:"time"`
+}
+
+// Sources of the address labels.
+const (
+	AddressTagSourceAdmin  = "admin"
+	AddressTagSourceImport = "import"
+)
+
+// AddressTag is a public label of a known address, such as an exchange, the
+// treasury or a VSP fee address. Source is AddressTagSourceAdmin for a label
+// set with the admin API, or AddressTagSourceImport for an imported label.
+type AddressTag struct {
+	Address  string  `json:"address"`
+	Label    string  `json:"label"`
+	Category string  `json:"category"`
+	Source   string  `json:"source"`
+	Updated  TimeDef `json:"updated"`
+}
+
+// CoinAgeInput is the value, in atoms, of an output spent by a block, and the
+// time of the block that created the output.
+type CoinAgeInput struct {
+	Value       int64
+	FundingTime TimeDef
+}
+
+// CoinAgeFlows is the value, in atoms, created by the valid transactions of a
+// mainchain block, and the outputs they spend.
+type CoinAgeFlows struct {
+	Height  int64
+	Hash    string
+	Time    TimeDef
+	Created int64
+	Spent   []CoinAgeInput
+}
+
+// CoinAgeBlock is the value spent and the coin days destroyed by a mainchain
+// block. DayDeltas is the block's change to the unspent value created on each
+// day, keyed by the UNIX time of the start of the day (UTC).
+type CoinAgeBlock struct {
+	Height            int64
+	Hash              string
+	Time              TimeDef
+	Spent             int64
+	CoinDaysDestroyed float64
+	DayDeltas         map[int64]int64
+}
+
+// CoinAgeBands is the unspent value, in atoms, in each coin age band as of a
+// mainchain block.
+type CoinAgeBands struct {
+	Height int64
+	Time   TimeDef
+	Bands  []int64
+}
+
+// ClusterMerge is a change to the address clusters made by a block: the
+// clusters with ids in Merged are merged into the cluster with id Target, and
+// the Addresses not yet clustered are added to it. Target is zero for a new
+// cluster.
+type ClusterMerge struct {
+	Target    int64
+	Merged    []int64
+	Addresses []string
+}
+
+// ClusterBlock is the address cluster changes made by a mainchain block.
+type ClusterBlock struct {
+	Height int64
+	Hash   string
+	Merges []ClusterMerge
+}
+
+// MempoolHistoryTx is a transaction seen in mempool, as recorded in the
+// mempool history. FirstSeen is the time, in seconds since the epoch, that the
+// transaction entered dcrd's mempool. Fees are in atoms, and FeeRate is in
+// atoms/kB.
+type MempoolHistoryTx struct {
+	TxHash    string
+	TxType    string
+	FirstSeen int64
+	Size      int32
+	Fees      int64
+	FeeRate   int64
+}
+
+// ProposalTitle is the title of a Politeia proposal, indexed for full-text
+// search.
+type ProposalTitle struct {
+	Token string
+	RefID string
+	Title string
+}
+
 // SideChain represents blocks of a side chain, in ascending height order.
 type SideChain struct {
 	Hashes  []string
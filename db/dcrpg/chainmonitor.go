@@ -11,6 +11,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
 	"github.com/decred/dcrdata/txhelpers/v4"
 )
 
@@ -63,7 +64,7 @@ func (p *ChainMonitor) switchToSideChain(reorgData *txhelpers.ReorgData) (int32,
 	startTime := time.Now()
 	mainRoot := reorgData.CommonAncestor.String()
 	log.Infof("Moving %d blocks to side chain...", mainTip-commonAncestorHeight)
-	newMainRoot, numBlocksmoved, err := p.db.TipToSideChain(mainRoot)
+	newMainRoot, numBlocksmoved, numTxnsMoved, err := p.db.TipToSideChain(mainRoot)
 	if err != nil || mainRoot != newMainRoot {
 		return 0, nil, fmt.Errorf("failed to flag blocks as side chain")
 	}
@@ -135,6 +136,26 @@ func (p *ChainMonitor) switchToSideChain(reorgData *txhelpers.ReorgData) (int32,
 			endHeight, mainTip))
 	}
 
+	// Recount the agenda votes of the intervals with blocks from the new chain.
+	if err = p.db.UpdateAgendaVoteIntervals(commonAncestorHeight + 1); err != nil {
+		log.Errorf("Failed to update agenda vote intervals: %v", err)
+	}
+
+	// Record the reorg for the reorg history.
+	err = InsertReorg(p.db.db, &dbtypes.Reorg{
+		OldTipHash:     reorgData.OldChainHead.String(),
+		OldTipHeight:   int64(reorgData.OldChainHeight),
+		NewTipHash:     endHash.String(),
+		NewTipHeight:   int64(endHeight),
+		CommonAncestor: mainRoot,
+		Depth:          numBlocksmoved,
+		OrphanedTxns:   numTxnsMoved,
+		Time:           dbtypes.NewTimeDef(time.Now()),
+	})
+	if err != nil {
+		log.Errorf("Failed to record reorg: %v", err)
+	}
+
 	return endHeight, &endHash, nil
 }
 
@@ -173,8 +194,13 @@ func (p *ChainMonitor) ReorgHandler(reorg *txhelpers.ReorgData) (err error) {
 	}
 
 	p.db.InReorg = false
-	// Freshen project fund balance and clear ALL address cache data.
-	_ = p.db.FreshenAddressCaches(true, nil) // async update
+	// The address cache was updated precisely as blocks were disconnected and
+	// connected. Clear ALL address cache data only if the reorg failed.
+	if err != nil {
+		_ = p.db.FreshenAddressCaches(true, nil) // async update
+	} else {
+		_ = p.db.prefetchProjectFund(true) // async update
+	}
 
 	return err
 }
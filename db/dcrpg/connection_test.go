@@ -0,0 +1,52 @@
+package dcrpg
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestReadDB(t *testing.T) {
+	primary := new(sql.DB)
+	replicas := []*sql.DB{new(sql.DB), new(sql.DB)}
+
+	// Without replicas, the reads go to the primary.
+	pgb := &ChainDB{db: primary}
+	for i := 0; i < 3; i++ {
+		if pgb.readDB() != primary {
+			t.Fatalf("read %d not on the primary", i)
+		}
+	}
+
+	// With replicas, the reads take turns on each replica, and never go to
+	// the primary.
+	pgb = &ChainDB{db: primary, replicas: replicas}
+	counts := make(map[*sql.DB]int)
+	for i := 0; i < 10; i++ {
+		counts[pgb.readDB()]++
+	}
+	if counts[primary] != 0 {
+		t.Errorf("%d reads on the primary", counts[primary])
+	}
+	for i, r := range replicas {
+		if counts[r] != 5 {
+			t.Errorf("expected 5 reads on replica %d, got %d", i, counts[r])
+		}
+	}
+}
+
+func TestConnectReplicas(t *testing.T) {
+	replicas, err := connectReplicas(nil)
+	if err != nil || len(replicas) != 0 {
+		t.Fatalf("expected no replicas, got %d (%v)", len(replicas), err)
+	}
+
+	// An unreachable replica fails the connection.
+	replicas, err = connectReplicas([]string{
+		"host=/nonexistent user=dcrdata dbname=dcrdata sslmode=disable"})
+	if err == nil {
+		t.Fatalf("expected an error connecting to an unreachable replica")
+	}
+	if replicas != nil {
+		t.Errorf("expected no replicas, got %d", len(replicas))
+	}
+}
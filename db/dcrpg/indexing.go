@@ -5,6 +5,7 @@ package dcrpg
 
 import (
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/decred/dcrdata/db/dbtypes/v2"
@@ -26,8 +27,19 @@ type deIndexingInfo struct {
 
 // Vins table indexes
 
+// IndexVinTableOnVins creates the unique index for the vins table over
+// transaction hash, index, and tree, which also includes the block height if
+// the table is partitioned.
 func IndexVinTableOnVins(db *sql.DB) (err error) {
-	_, err = db.Exec(internal.IndexVinTableOnVins)
+	stmt := internal.IndexVinTableOnVins
+	partitioned, err := TableIsPartitioned(db, "vins")
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		stmt = internal.IndexVinTablePartitionedOnVins
+	}
+	_, err = db.Exec(stmt)
 	return
 }
 
@@ -113,9 +125,18 @@ func DeindexBlockTableOnTime(db *sql.DB) (err error) {
 // vouts table indexes
 
 // IndexVoutTableOnTxHashIdx creates the index for the addresses table over
-// transaction hash and index.
+// transaction hash and index, which also includes the block height if the
+// table is partitioned.
 func IndexVoutTableOnTxHashIdx(db *sql.DB) (err error) {
-	_, err = db.Exec(internal.IndexVoutTableOnTxHashIdx)
+	stmt := internal.IndexVoutTableOnTxHashIdx
+	partitioned, err := TableIsPartitioned(db, "vouts")
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		stmt = internal.IndexVoutTablePartitionedOnTxHashIdx
+	}
+	_, err = db.Exec(stmt)
 	return
 }
 
@@ -172,6 +193,18 @@ func DeindexAddressTableOnAddress(db *sql.DB) (err error) {
 	return
 }
 
+// IndexAddressTableOnPrefix creates the index for the addresses table used by
+// address prefix searches.
+func IndexAddressTableOnPrefix(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexAddressTableOnPrefix)
+	return
+}
+
+func DeindexAddressTableOnPrefix(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexAddressTableOnPrefix)
+	return
+}
+
 // IndexAddressTableOnVoutID creates the index for the addresses table over
 // vout row ID.
 func IndexAddressTableOnVoutID(db *sql.DB) (err error) {
@@ -465,6 +498,7 @@ func (pgb *ChainDB) DeindexAll() error {
 		{DeindexBlockTimeOnTableAddress},
 		{DeindexAddressTableOnMatchingTxHash},
 		{DeindexAddressTableOnAddress},
+		{DeindexAddressTableOnPrefix},
 		{DeindexAddressTableOnVoutID},
 		{DeindexAddressTableOnTxHash},
 
@@ -557,6 +591,7 @@ func (pgb *ChainDB) IndexAll(barLoad chan *dbtypes.ProgressBarLoad) error {
 		{Msg: "addresses table on tx hash", IndexFunc: IndexAddressTableOnTxHash},
 		{Msg: "addresses table on block time", IndexFunc: IndexBlockTimeOnTableAddress},
 		{Msg: "addresses table on address", IndexFunc: IndexAddressTableOnAddress},
+		{Msg: "addresses table on address prefix", IndexFunc: IndexAddressTableOnPrefix},
 		{Msg: "addresses table on vout DB ID", IndexFunc: IndexAddressTableOnVoutID},
 		//{Msg: "addresses table on matching tx hash", IndexFunc: IndexAddressTableOnMatchingTxHash},
 
@@ -660,6 +695,7 @@ func (pgb *ChainDB) ReindexAddressesBlockTime() error {
 func (pgb *ChainDB) IndexAddressTable(barLoad chan *dbtypes.ProgressBarLoad) error {
 	addressesTableIndexes := []indexingInfo{
 		{Msg: "address", IndexFunc: IndexAddressTableOnAddress},
+		{Msg: "address prefix", IndexFunc: IndexAddressTableOnPrefix},
 		{Msg: "matching tx hash", IndexFunc: IndexAddressTableOnMatchingTxHash},
 		{Msg: "block time", IndexFunc: IndexBlockTimeOnTableAddress},
 		{Msg: "vout Db ID", IndexFunc: IndexAddressTableOnVoutID},
@@ -705,6 +741,33 @@ func (pgb *ChainDB) DeindexAddressTable() error {
 	return err
 }
 
+// ReindexAddressTable drops and recreates the indexes of the addresses table,
+// sending progress updates on barLoad if it is not nil. The address queries
+// are slow until the indexes are recreated. ReindexAddressTable fails during
+// the initial batch sync, which manages the indexes itself.
+func (pgb *ChainDB) ReindexAddressTable(barLoad chan *dbtypes.ProgressBarLoad) error {
+	if pgb.InBatchSync {
+		return fmt.Errorf("cannot reindex the addresses table during the batch sync")
+	}
+	log.Infof("Reindexing the addresses table...")
+	if barLoad != nil {
+		barLoad <- &dbtypes.ProgressBarLoad{BarID: dbtypes.AddressesTableSync,
+			Subtitle: "Dropping addresses table indexes..."}
+	}
+	if err := pgb.DeindexAddressTable(); err != nil {
+		return err
+	}
+	return pgb.IndexAddressTable(barLoad)
+}
+
+// PurgeAddressCache clears the address cache, returning the number of
+// addresses cleared.
+func (pgb *ChainDB) PurgeAddressCache() int {
+	numCleared := pgb.AddressCache.ClearAll()
+	log.Infof("Purged %d addresses from the address cache.", numCleared)
+	return numCleared
+}
+
 // IndexStatsTableOnHeight creates the index for the stats table over height.
 func IndexStatsTableOnHeight(db *sql.DB) (err error) {
 	_, err = db.Exec(internal.IndexStatsOnHeight)
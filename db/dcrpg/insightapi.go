@@ -270,10 +270,18 @@ func (pgb *ChainDB) AddressUTXO(address string) ([]*dbtypes.AddressTxnOutput, bo
 }
 
 // SpendDetailsForFundingTx will return the details of any spending transactions
-// (tx, index, block height) for a given funding transaction.
+// (tx, index, block height) for a given funding transaction. dbtypes.ErrPruned
+// is returned if the funding transaction is in a pruned block.
 func (pgb *ChainDB) SpendDetailsForFundingTx(fundHash string) ([]*apitypes.SpendByFundingHash, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
+	pruned, err := pgb.txPruned(ctx, fundHash)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	if pruned {
+		return nil, dbtypes.ErrPruned
+	}
 	addrRow, err := RetrieveSpendingTxsByFundingTxWithBlockHeight(ctx, pgb.db, fundHash)
 	if err != nil {
 		return nil, pgb.replaceCancelError(err)
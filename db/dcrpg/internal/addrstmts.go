@@ -15,7 +15,8 @@ const (
 		is_funding BOOLEAN,
 		tx_vin_vout_index INT4,
 		tx_vin_vout_row_id INT8,
-		tx_type INT4
+		tx_type INT4,
+		balance INT8 -- NULL until materialized by MaterializeAddressBalances
 	);`
 
 	// insertAddressRow is the basis for several address insert/upsert
@@ -33,7 +34,7 @@ const (
 	// the inserted/updated address row id.
 	UpsertAddressRow = insertAddressRow + `ON CONFLICT (tx_vin_vout_row_id, address, is_funding) DO UPDATE
 		SET matching_tx_hash = $2, tx_hash = $3, tx_vin_vout_index = $4,
-		block_time = $7, valid_mainchain = $9 RETURNING id;`
+		block_time = $7, valid_mainchain = $9, balance = NULL RETURNING id;`
 
 	// InsertAddressRowOnConflictDoNothing allows an INSERT with a DO NOTHING on
 	// conflict with addresses' unique tx index, while returning the row id of
@@ -71,10 +72,68 @@ const (
 		` ON addresses(address);`
 	DeindexAddressTableOnAddress = `DROP INDEX IF EXISTS ` + IndexOfAddressTableOnAddress + ` CASCADE;`
 
+	// IndexAddressTableOnPrefix creates an index with the text_pattern_ops
+	// operator class, which supports address LIKE 'prefix%' queries regardless
+	// of the database's collation.
+	IndexAddressTableOnPrefix = `CREATE INDEX IF NOT EXISTS ` + IndexOfAddressTableOnPrefix +
+		` ON addresses(address text_pattern_ops);`
+	DeindexAddressTableOnPrefix = `DROP INDEX IF EXISTS ` + IndexOfAddressTableOnPrefix + ` CASCADE;`
+
 	IndexAddressTableOnTxHash = `CREATE INDEX IF NOT EXISTS ` + IndexOfAddressTableOnTx +
 		` ON addresses(tx_hash);`
 	DeindexAddressTableOnTxHash = `DROP INDEX IF EXISTS ` + IndexOfAddressTableOnTx + ` CASCADE;`
 
+	// AddAddressesBalanceColumn adds the balance column to an existing
+	// addresses table.
+	AddAddressesBalanceColumn = `ALTER TABLE addresses ADD COLUMN IF NOT EXISTS balance INT8;`
+
+	// MaterializeAddressBalances sets the balance column, the running total of
+	// the address's valid mainchain funding less spending ordered by
+	// (block_time, id), for the rows of an address starting from the earliest
+	// row with a NULL balance. The rows before that row are all materialized,
+	// so the running total continues from the balance of the last of them.
+	MaterializeAddressBalances = `WITH pending AS (
+			SELECT MIN(block_time) AS block_time FROM addresses
+			WHERE address = $1 AND balance IS NULL
+		), base AS (
+			SELECT COALESCE((
+				SELECT balance FROM addresses, pending
+				WHERE address = $1 AND addresses.block_time < pending.block_time
+				ORDER BY addresses.block_time DESC, id DESC
+				LIMIT 1
+			), 0) AS balance
+		), running AS (
+			SELECT id, SUM(CASE WHEN NOT valid_mainchain THEN 0
+					WHEN is_funding THEN value ELSE -value END)
+				OVER (ORDER BY addresses.block_time, id) AS total
+			FROM addresses, pending
+			WHERE address = $1 AND addresses.block_time >= pending.block_time
+		)
+		UPDATE addresses SET balance = base.balance + running.total
+		FROM running, base
+		WHERE addresses.id = running.id;`
+
+	// InvalidateAddressBalancesFromBlock clears the materialized balances of
+	// the rows of all addresses at or after the time of the given block, such
+	// as when the validity of the block's transactions changes.
+	InvalidateAddressBalancesFromBlock = `UPDATE addresses SET balance = NULL
+		WHERE balance IS NOT NULL
+			AND block_time >= (SELECT time FROM blocks WHERE hash = $1);`
+
+	// SelectAddressesInBlock selects the distinct addresses funded or spent by
+	// the transactions in the given block.
+	SelectAddressesInBlock = `SELECT DISTINCT addresses.address
+		FROM addresses
+		JOIN transactions ON transactions.tx_hash = addresses.tx_hash
+		WHERE transactions.block_hash = $1;`
+
+	// SelectAddressBalanceAtTime selects the materialized balance of the last
+	// row of an address at or before the given block time.
+	SelectAddressBalanceAtTime = `SELECT COALESCE(balance, 0) FROM addresses
+		WHERE address = $1 AND block_time <= $2
+		ORDER BY block_time DESC, id DESC
+		LIMIT 1;`
+
 	// SelectSpendingTxsByPrevTx = `SELECT id, tx_hash, tx_index, prev_tx_index FROM vins WHERE prev_tx_hash=$1;`
 	// SelectSpendingTxByPrevOut = `SELECT id, tx_hash, tx_index FROM vins WHERE prev_tx_hash=$1 AND prev_tx_index=$2;`
 	// SelectFundingTxsByTx      = `SELECT id, prev_tx_hash FROM vins WHERE tx_hash=$1;`
@@ -0,0 +1,40 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "address_tags" table of the public labels of
+// known addresses, such as exchanges, the treasury and VSP fee addresses.
+const (
+	// CreateAddressTagsTable creates the address_tags table. The source is
+	// "admin" for labels set with the admin API, or "import" for labels
+	// imported from a file.
+	CreateAddressTagsTable = `CREATE TABLE IF NOT EXISTS address_tags (
+		address TEXT PRIMARY KEY,
+		label TEXT NOT NULL,
+		category TEXT NOT NULL,
+		source TEXT NOT NULL,
+		updated TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertAddressTagRow = `INSERT INTO address_tags (address, label, category,
+		source, updated)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address) DO UPDATE
+		SET label = $2, category = $3, source = $4, updated = $5;`
+
+	// UpsertImportedAddressTagRow is like UpsertAddressTagRow, but does not
+	// replace a label set with the admin API.
+	UpsertImportedAddressTagRow = `INSERT INTO address_tags (address, label,
+		category, source, updated)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address) DO UPDATE
+		SET label = $2, category = $3, source = $4, updated = $5
+		WHERE address_tags.source = $4;`
+
+	SelectAddressTags = `SELECT address, label, category, source, updated
+		FROM address_tags
+		ORDER BY category, label, address;`
+
+	DeleteAddressTagRow = `DELETE FROM address_tags WHERE address = $1;`
+)
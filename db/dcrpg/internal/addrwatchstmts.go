@@ -0,0 +1,31 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "address_watches" table of the callback URLs
+// registered for notifications of the transactions involving an address.
+const (
+	// CreateAddressWatchesTable creates the address_watches table. The secret
+	// is the key of the HMAC signature of each notification.
+	CreateAddressWatchesTable = `CREATE TABLE IF NOT EXISTS address_watches (
+		id SERIAL8 PRIMARY KEY,
+		address TEXT NOT NULL,
+		callback_url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		created TIMESTAMPTZ NOT NULL
+	);`
+
+	InsertAddressWatchRow = `INSERT INTO address_watches (address, callback_url,
+		secret, created)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`
+
+	SelectAddressWatches = `SELECT id, address, callback_url, secret, created
+		FROM address_watches
+		ORDER BY id;`
+
+	// DeleteAddressWatchRow deletes a registration, provided its secret.
+	DeleteAddressWatchRow = `DELETE FROM address_watches
+		WHERE id = $1 AND secret = $2;`
+)
@@ -0,0 +1,50 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "agenda_vote_intervals" table of the agenda vote
+// choice counts cast in each interval of an agenda's rule change interval.
+const (
+	// CreateAgendaVoteIntervalsTable creates the agenda_vote_intervals table.
+	// interval_end is the last block height counted, which is before the end of
+	// the interval while the interval is in progress.
+	CreateAgendaVoteIntervalsTable = `CREATE TABLE IF NOT EXISTS agenda_vote_intervals (
+		agenda_id TEXT NOT NULL,
+		interval_start INT8 NOT NULL,
+		interval_end INT8 NOT NULL,
+		end_time TIMESTAMPTZ NOT NULL,
+		yes INT8 NOT NULL,
+		abstain INT8 NOT NULL,
+		no INT8 NOT NULL,
+		total INT8 NOT NULL,
+		PRIMARY KEY (agenda_id, interval_start)
+	);`
+
+	// UpsertAgendaVoteInterval counts the votes for the agenda ($4) cast in the
+	// main chain blocks from height $5 to $6, and stores the counts for the
+	// interval starting at $5.
+	UpsertAgendaVoteInterval = `INSERT INTO agenda_vote_intervals (agenda_id,
+			interval_start, interval_end, end_time, yes, abstain, no, total)
+		SELECT $4, $5, $6,
+			(SELECT time FROM blocks WHERE height = $6 AND is_mainchain = TRUE),` +
+		selectAgendaVotesQuery + `
+		ON CONFLICT (agenda_id, interval_start) DO UPDATE
+		SET interval_end = EXCLUDED.interval_end, end_time = EXCLUDED.end_time,
+			yes = EXCLUDED.yes, abstain = EXCLUDED.abstain, no = EXCLUDED.no,
+			total = EXCLUDED.total;`
+
+	// SelectAgendaVoteIntervalsLastStart selects the start height of the last
+	// interval stored for an agenda's voting period.
+	SelectAgendaVoteIntervalsLastStart = `SELECT MAX(interval_start)
+		FROM agenda_vote_intervals
+		WHERE agenda_id = $1 AND interval_start >= $2 AND interval_start <= $3;`
+
+	// SelectAgendaVoteIntervals selects the intervals of an agenda's voting
+	// period in ascending order.
+	SelectAgendaVoteIntervals = `SELECT interval_start, interval_end, end_time,
+			yes, abstain, no, total
+		FROM agenda_vote_intervals
+		WHERE agenda_id = $1 AND interval_start >= $2 AND interval_start <= $3
+		ORDER BY interval_start;`
+)
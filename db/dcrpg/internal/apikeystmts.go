@@ -0,0 +1,24 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "api_keys" table of the API keys with their own
+// request rate limits.
+const (
+	// CreateAPIKeysTable creates the api_keys table. Keys are identified by
+	// the hex-encoded SHA-256 hash of the key, and rate_limit is in requests
+	// per second, with bursts of up to burst requests.
+	CreateAPIKeysTable = `CREATE TABLE IF NOT EXISTS api_keys (
+		key_hash TEXT PRIMARY KEY,
+		label TEXT NOT NULL DEFAULT '',
+		rate_limit FLOAT8 NOT NULL,
+		burst INT4 NOT NULL,
+		disabled BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+
+	SelectAPIKeyQuota = `SELECT rate_limit, burst
+		FROM api_keys
+		WHERE key_hash = $1 AND NOT disabled;`
+)
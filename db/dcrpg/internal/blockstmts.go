@@ -102,6 +102,9 @@ const (
 	SelectBlockHashByHeight = `SELECT hash FROM blocks WHERE height = $1 AND is_mainchain = true;`
 	SelectBlockHeightByHash = `SELECT height FROM blocks WHERE hash = $1;`
 
+	SelectBlockChainWorkByHeight = `SELECT hash, chainwork FROM blocks
+		WHERE height = $1 AND is_mainchain = true;`
+
 	SelectBlockTimeByHeight = `SELECT time FROM blocks
 		WHERE height = $1 AND is_mainchain = true;`
 
@@ -0,0 +1,56 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "market_candles" table of the OHLCV candles of
+// the DCR-BTC markets monitored by the exchange bot. The candles are built from
+// price samples as the exchange updates arrive, and replaced by the candles
+// reported by an exchange, which include the traded volume.
+const (
+	// CreateMarketCandlesTable creates the market_candles table. Each row is
+	// the candle of an exchange's market for the bin (e.g. 1h) starting at
+	// start.
+	CreateMarketCandlesTable = `CREATE TABLE IF NOT EXISTS market_candles (
+		exchange TEXT NOT NULL,
+		bin TEXT NOT NULL,
+		start TIMESTAMPTZ NOT NULL,
+		open FLOAT8 NOT NULL,
+		high FLOAT8 NOT NULL,
+		low FLOAT8 NOT NULL,
+		close FLOAT8 NOT NULL,
+		volume FLOAT8 NOT NULL DEFAULT 0,
+		PRIMARY KEY (exchange, bin, start)
+	);`
+
+	// UpsertMarketCandle stores a candle reported by an exchange, replacing
+	// a candle built from price samples.
+	UpsertMarketCandle = `INSERT INTO market_candles (exchange, bin, start, open, high, low, close, volume)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (exchange, bin, start) DO UPDATE
+		SET open = $4, high = $5, low = $6, close = $7, volume = $8;`
+
+	// UpsertMarketPriceSample adds the price sample $4 to the candle, which
+	// opens with its first sample. The samples must be added in time order.
+	UpsertMarketPriceSample = `INSERT INTO market_candles AS c (exchange, bin, start, open, high, low, close)
+		VALUES ($1, $2, $3, $4, $4, $4, $4)
+		ON CONFLICT (exchange, bin, start) DO UPDATE
+		SET high = GREATEST(c.high, $4), low = LEAST(c.low, $4), close = $4;`
+
+	// SelectMarketCandles selects the candles of the exchange and bin starting
+	// in [$3, $4), ordered by start.
+	SelectMarketCandles = `SELECT start, open, high, low, close, volume
+		FROM market_candles
+		WHERE exchange = $1 AND bin = $2 AND start >= $3 AND start < $4
+		ORDER BY start;`
+
+	// SelectAggregatedMarketCandles is like SelectMarketCandles for the
+	// aggregated market ($1), whose volume is the total volume of the
+	// exchanges' candles with the same start.
+	SelectAggregatedMarketCandles = `SELECT c.start, c.open, c.high, c.low, c.close,
+			COALESCE((SELECT SUM(x.volume) FROM market_candles x
+				WHERE x.bin = c.bin AND x.start = c.start AND x.exchange != $1), 0)
+		FROM market_candles c
+		WHERE c.exchange = $1 AND c.bin = $2 AND c.start >= $3 AND c.start < $4
+		ORDER BY c.start;`
+)
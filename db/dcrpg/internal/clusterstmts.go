@@ -0,0 +1,103 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the address cluster tables, which are materialized
+// block by block by the analytics package with the common-input-ownership
+// heuristic. The "address_clusters" table has a row for each cluster, the
+// "cluster_addresses" table maps each clustered address to its cluster, and
+// the "address_cluster_state" table records the last block processed, and
+// holds at most one row.
+const (
+	CreateAddressClustersTable = `CREATE TABLE IF NOT EXISTS address_clusters (
+		id SERIAL8 PRIMARY KEY,
+		num_addresses INT8 NOT NULL
+	);`
+
+	// CreateClusterAddressesTable creates the cluster_addresses table, and the
+	// index on cluster_id used to merge clusters and sum their balances.
+	CreateClusterAddressesTable = `CREATE TABLE IF NOT EXISTS cluster_addresses (
+		address TEXT PRIMARY KEY,
+		cluster_id INT8 NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS ix_cluster_addresses_cluster_id
+		ON cluster_addresses(cluster_id);`
+
+	CreateAddressClusterStateTable = `CREATE TABLE IF NOT EXISTS address_cluster_state (
+		id INT4 PRIMARY KEY CHECK (id = 1),
+		height INT8 NOT NULL,
+		hash TEXT NOT NULL
+	);`
+
+	// SelectClusterInputs selects the input addresses of the valid regular
+	// transactions of the mainchain block at height $1, excluding the CSPP
+	// mix transactions, whose inputs are owned by the mix participants. The
+	// rows are ordered by transaction.
+	SelectClusterInputs = `SELECT DISTINCT addresses.tx_hash, addresses.address
+		FROM transactions
+		JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+			AND NOT addresses.is_funding AND addresses.valid_mainchain
+		WHERE transactions.block_height = $1 AND transactions.is_mainchain
+			AND transactions.is_valid AND transactions.tree = 0
+			AND COALESCE(transactions.mix_count, 0) = 0
+		ORDER BY addresses.tx_hash;`
+
+	SelectAddressClusterIDs = `SELECT address, cluster_id
+		FROM cluster_addresses
+		WHERE address = ANY($1);`
+
+	InsertAddressCluster = `INSERT INTO address_clusters (num_addresses)
+		VALUES (0)
+		RETURNING id;`
+
+	// MergeAddressClusters moves the addresses of the clusters with ids in $2
+	// to the cluster with id $1.
+	MergeAddressClusters = `UPDATE cluster_addresses
+		SET cluster_id = $1
+		WHERE cluster_id = ANY($2);`
+
+	DeleteAddressClusters = `DELETE FROM address_clusters
+		WHERE id = ANY($1);`
+
+	InsertClusterAddresses = `INSERT INTO cluster_addresses (address, cluster_id)
+		SELECT UNNEST($2::TEXT[]), $1
+		ON CONFLICT (address) DO UPDATE
+		SET cluster_id = EXCLUDED.cluster_id;`
+
+	UpdateAddressClusterSize = `UPDATE address_clusters
+		SET num_addresses = (SELECT COUNT(*) FROM cluster_addresses
+			WHERE cluster_id = $1)
+		WHERE id = $1;`
+
+	UpsertAddressClusterState = `INSERT INTO address_cluster_state (id, height, hash)
+		VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE
+		SET height = $1, hash = $2;`
+
+	SelectAddressClusterState = `SELECT height, hash
+		FROM address_cluster_state
+		WHERE id = 1;`
+
+	// SelectAddressCluster selects the cluster id and size of the cluster of
+	// address $1.
+	SelectAddressCluster = `SELECT address_clusters.id, address_clusters.num_addresses
+		FROM cluster_addresses
+		JOIN address_clusters ON address_clusters.id = cluster_addresses.cluster_id
+		WHERE cluster_addresses.address = $1;`
+
+	// SelectAddressClusterBalance selects the unspent value of the addresses
+	// of the cluster with id $1.
+	SelectAddressClusterBalance = `SELECT COALESCE(SUM(addresses.value), 0)
+		FROM cluster_addresses
+		JOIN addresses ON addresses.address = cluster_addresses.address
+		WHERE cluster_addresses.cluster_id = $1 AND addresses.is_funding
+			AND addresses.matching_tx_hash = '' AND addresses.valid_mainchain;`
+
+	// SelectAddressUnspentValue selects the unspent value of address $1, for
+	// addresses that are not clustered.
+	SelectAddressUnspentValue = `SELECT COALESCE(SUM(value), 0)
+		FROM addresses
+		WHERE address = $1 AND is_funding AND matching_tx_hash = ''
+			AND valid_mainchain;`
+)
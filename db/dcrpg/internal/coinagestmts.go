@@ -0,0 +1,111 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the coin age tables, which are materialized block by
+// block by the analytics package. The "coin_age_blocks" table has the coin
+// days destroyed by each mainchain block, the "coin_age_deltas" table has each
+// block's change to the unspent value created on each (UTC) day, and the
+// "coin_age_bands" table has daily snapshots of the unspent value in each coin
+// age band.
+const (
+	// CreateCoinAgeBlocksTable creates the coin_age_blocks table. spent is the
+	// value of the outputs spent by the block, in atoms.
+	CreateCoinAgeBlocksTable = `CREATE TABLE IF NOT EXISTS coin_age_blocks (
+		height INT8 PRIMARY KEY,
+		hash TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		spent INT8 NOT NULL,
+		coin_days_destroyed FLOAT8 NOT NULL
+	);`
+
+	// CreateCoinAgeDeltasTable creates the coin_age_deltas table. Summing delta
+	// by day over the blocks up to a height gives the unspent value created on
+	// each day as of that height.
+	CreateCoinAgeDeltasTable = `CREATE TABLE IF NOT EXISTS coin_age_deltas (
+		height INT8 NOT NULL,
+		day TIMESTAMPTZ NOT NULL,
+		delta INT8 NOT NULL,
+		PRIMARY KEY (height, day)
+	);`
+
+	// CreateCoinAgeBandsTable creates the coin_age_bands table. bands is the
+	// unspent value in each age band, in atoms.
+	CreateCoinAgeBandsTable = `CREATE TABLE IF NOT EXISTS coin_age_bands (
+		height INT8 PRIMARY KEY,
+		time TIMESTAMPTZ NOT NULL,
+		bands INT8[] NOT NULL
+	);`
+
+	// SelectCoinAgeBlockCreated selects the hash and time of the mainchain
+	// block at height $1, and the value of the outputs of its valid
+	// transactions.
+	SelectCoinAgeBlockCreated = `SELECT blocks.hash, blocks.time,
+			(SELECT COALESCE(SUM(sent), 0)
+			FROM transactions
+			WHERE block_hash = blocks.hash AND is_valid)
+		FROM blocks
+		WHERE blocks.height = $1 AND blocks.is_mainchain;`
+
+	// SelectCoinAgeBlockSpent selects the value and the funding block time of
+	// the outputs spent by the valid transactions of the mainchain block at
+	// height $1. Stakebase and coinbase inputs have no funding transaction.
+	SelectCoinAgeBlockSpent = `SELECT vins.value_in, prev.block_time
+		FROM transactions AS spend
+		JOIN vins ON vins.tx_hash = spend.tx_hash
+			AND vins.is_mainchain AND vins.is_valid
+		JOIN transactions AS prev ON prev.tx_hash = vins.prev_tx_hash
+			AND prev.is_mainchain AND prev.is_valid
+		WHERE spend.block_height = $1 AND spend.is_mainchain AND spend.is_valid;`
+
+	InsertCoinAgeBlock = `INSERT INTO coin_age_blocks (height, hash, time,
+			spent, coin_days_destroyed)
+		VALUES ($1, $2, $3, $4, $5);`
+
+	InsertCoinAgeDelta = `INSERT INTO coin_age_deltas (height, day, delta)
+		VALUES ($1, $2, $3);`
+
+	UpsertCoinAgeBands = `INSERT INTO coin_age_bands (height, time, bands)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (height) DO UPDATE
+		SET time = EXCLUDED.time, bands = EXCLUDED.bands;`
+
+	// SelectCoinAgeTip selects the height, hash and time of the last block
+	// processed.
+	SelectCoinAgeTip = `SELECT height, hash, time
+		FROM coin_age_blocks
+		ORDER BY height DESC
+		LIMIT 1;`
+
+	// SelectCoinAgeDays selects the unspent value created on each day as of
+	// the block at height $1.
+	SelectCoinAgeDays = `SELECT day, SUM(delta)
+		FROM coin_age_deltas
+		WHERE height <= $1
+		GROUP BY day
+		HAVING SUM(delta) <> 0
+		ORDER BY day;`
+
+	DeleteCoinAgeBlocksAbove = `DELETE FROM coin_age_blocks WHERE height > $1;`
+	DeleteCoinAgeDeltasAbove = `DELETE FROM coin_age_deltas WHERE height > $1;`
+	DeleteCoinAgeBandsAbove  = `DELETE FROM coin_age_bands WHERE height > $1;`
+
+	// selectCoinDaysDestroyed is formatted with the time grouping by
+	// MakeSelectCoinDaysDestroyed.
+	selectCoinDaysDestroyed = `SELECT %s AS period, SUM(spent),
+			SUM(coin_days_destroyed)
+		FROM coin_age_blocks
+		GROUP BY period
+		ORDER BY period;`
+
+	SelectCoinAgeBands = `SELECT height, time, bands
+		FROM coin_age_bands
+		ORDER BY height;`
+)
+
+// MakeSelectCoinDaysDestroyed returns the selectCoinDaysDestroyed query for
+// the given time grouping (e.g. "day", or "all" for each block).
+func MakeSelectCoinDaysDestroyed(group string) string {
+	return formatGroupingQuery(selectCoinDaysDestroyed, group, "time")
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "faucet_grants" table of the coins sent to
+// addresses by the testnet or simnet faucet.
+const (
+	// CreateFaucetGrantsTable creates the faucet_grants table. The index on
+	// (address, time) serves the rate limiting of each address.
+	CreateFaucetGrantsTable = `CREATE TABLE IF NOT EXISTS faucet_grants (
+		id SERIAL8 PRIMARY KEY,
+		address TEXT NOT NULL,
+		amount INT8 NOT NULL,
+		tx_hash TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS ix_faucet_grants_address_time
+		ON faucet_grants(address, time DESC);`
+
+	InsertFaucetGrantRow = `INSERT INTO faucet_grants (address, amount,
+		tx_hash, time)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`
+
+	SelectLastFaucetGrant = `SELECT id, address, amount, tx_hash, time
+		FROM faucet_grants
+		WHERE address = $1
+		ORDER BY time DESC
+		LIMIT 1;`
+)
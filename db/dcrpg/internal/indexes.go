@@ -31,6 +31,7 @@ const (
 	IndexOfAddressTableOnBlockTime  = "block_time_index"
 	IndexOfAddressTableOnTx         = "uix_addresses_funding_tx"
 	IndexOfAddressTableOnMatchingTx = "matching_tx_hash_index"
+	IndexOfAddressTableOnPrefix     = "ix_addresses_address_prefix"
 
 	// tickets table
 
@@ -75,7 +76,8 @@ const (
 // AddressesIndexNames are the names of the indexes on the addresses table.
 var AddressesIndexNames = []string{IndexOfAddressTableOnAddress,
 	IndexOfAddressTableOnVoutID, IndexOfAddressTableOnBlockTime,
-	IndexOfAddressTableOnTx, IndexOfAddressTableOnMatchingTx}
+	IndexOfAddressTableOnTx, IndexOfAddressTableOnMatchingTx,
+	IndexOfAddressTableOnPrefix}
 
 // IndexDescriptions relate table index names to descriptions of the indexes.
 var IndexDescriptions = map[string]string{
@@ -93,6 +95,7 @@ var IndexDescriptions = map[string]string{
 	IndexOfAddressTableOnBlockTime:         "addresses table on block time",
 	IndexOfAddressTableOnTx:                "addresses table on transaction hash",
 	IndexOfAddressTableOnMatchingTx:        "addresses table on matching tx hash",
+	IndexOfAddressTableOnPrefix:            "addresses table on address prefix",
 	IndexOfTicketsTableOnHashes:            "tickets table on block hash and transaction hash",
 	IndexOfTicketsTableOnTxRowID:           "tickets table on transactions table row ID",
 	IndexOfTicketsTableOnPoolStatus:        "tickets table on pool status",
@@ -0,0 +1,20 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the scheduled VACUUM and ANALYZE of the high-churn
+// tables.
+const (
+	// SelectTableChurn selects the live and dead tuple counts, and the number
+	// of tuples modified since the last ANALYZE, of the named tables.
+	SelectTableChurn = `SELECT relname, n_live_tup, n_dead_tup, n_mod_since_analyze
+		FROM pg_stat_user_tables
+		WHERE schemaname = 'public' AND relname = ANY($1);`
+
+	// SelectVacuumProgress selects the phase and the heap blocks scanned and
+	// total of a running VACUUM of the named table.
+	SelectVacuumProgress = `SELECT phase, heap_blks_scanned, heap_blks_total
+		FROM pg_stat_progress_vacuum
+		WHERE relid = $1::regclass;`
+)
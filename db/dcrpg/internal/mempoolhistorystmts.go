@@ -0,0 +1,88 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "mempool_history" table, which records each
+// transaction seen in mempool, when it was first and last seen, its fee rate,
+// and its fate: mined at mined_height, or evicted from mempool without being
+// mined, in which case evicted_at is the time it was last seen. A transaction
+// with neither is still pending.
+const (
+	// CreateMempoolHistoryTable creates the mempool_history table, the index
+	// on first_seen used to aggregate the history by time, and the index of
+	// the pending transactions whose fate is resolved after each block.
+	CreateMempoolHistoryTable = `CREATE TABLE IF NOT EXISTS mempool_history (
+		tx_hash TEXT PRIMARY KEY,
+		tx_type TEXT NOT NULL,
+		first_seen TIMESTAMPTZ NOT NULL,
+		last_seen TIMESTAMPTZ NOT NULL,
+		size INT4 NOT NULL,
+		fees INT8 NOT NULL,
+		fee_rate INT8 NOT NULL,
+		mined_height INT8,
+		evicted_at TIMESTAMPTZ
+	);
+	CREATE INDEX IF NOT EXISTS ix_mempool_history_first_seen
+		ON mempool_history(first_seen);
+	CREATE INDEX IF NOT EXISTS ix_mempool_history_pending
+		ON mempool_history(last_seen)
+		WHERE mined_height IS NULL AND evicted_at IS NULL;`
+
+	// UpsertMempoolHistory inserts a transaction seen in mempool at time $4,
+	// or updates its last_seen time. A transaction seen again is pending,
+	// such as when the block that mined it was orphaned.
+	UpsertMempoolHistory = `INSERT INTO mempool_history (tx_hash, tx_type,
+			first_seen, last_seen, size, fees, fee_rate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tx_hash) DO UPDATE
+		SET last_seen = $4, mined_height = NULL, evicted_at = NULL;`
+
+	// SetMempoolHistoryMined sets the mined_height of the pending transactions
+	// not seen in mempool since $1 that are in a mainchain block.
+	SetMempoolHistoryMined = `UPDATE mempool_history
+		SET mined_height = transactions.block_height
+		FROM transactions
+		WHERE mempool_history.mined_height IS NULL
+			AND mempool_history.evicted_at IS NULL
+			AND mempool_history.last_seen < $1
+			AND transactions.tx_hash = mempool_history.tx_hash
+			AND transactions.is_mainchain;`
+
+	// SetMempoolHistoryEvicted marks the pending transactions not seen in
+	// mempool since $1 as evicted.
+	SetMempoolHistoryEvicted = `UPDATE mempool_history
+		SET evicted_at = last_seen
+		WHERE mined_height IS NULL AND evicted_at IS NULL
+			AND last_seen < $1;`
+
+	// SelectMempoolHistoryTx selects the mempool history of transaction $1,
+	// with the time of the mainchain block that mined it.
+	SelectMempoolHistoryTx = `SELECT tx_type, first_seen, last_seen, size,
+			fees, fee_rate, mined_height, evicted_at IS NOT NULL, blocks.time
+		FROM mempool_history
+		LEFT JOIN blocks ON blocks.height = mempool_history.mined_height
+			AND blocks.is_mainchain
+		WHERE tx_hash = $1;`
+
+	// selectMempoolCongestion is formatted with the time grouping by
+	// MakeSelectMempoolCongestion.
+	selectMempoolCongestion = `SELECT %s AS period,
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY fee_rate),
+			COUNT(*) FILTER (WHERE mined_height IS NOT NULL),
+			COALESCE(AVG(EXTRACT(EPOCH FROM blocks.time - first_seen))
+				FILTER (WHERE blocks.time IS NOT NULL), 0),
+			COUNT(*) FILTER (WHERE evicted_at IS NOT NULL)
+		FROM mempool_history
+		LEFT JOIN blocks ON blocks.height = mempool_history.mined_height
+			AND blocks.is_mainchain
+		GROUP BY period
+		ORDER BY period;`
+)
+
+// MakeSelectMempoolCongestion returns the selectMempoolCongestion query for the
+// given time grouping of the first seen times (e.g. "day").
+func MakeSelectMempoolCongestion(group string) string {
+	return formatGroupingQuery(selectMempoolCongestion, group, "first_seen")
+}
@@ -46,4 +46,7 @@ const (
 
 	SetDBMaintenanceVersion = `UPDATE meta
 		SET maintenance_version = $1;`
+
+	SetDBSchemaAndMaintenanceVersions = `UPDATE meta
+		SET schema_version = $1, maintenance_version = $2;`
 )
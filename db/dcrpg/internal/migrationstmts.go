@@ -0,0 +1,40 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "schema_migrations" table, the audit log of the
+// database migrations applied and reverted. backup_tables lists the copies of
+// the tables made before the migration, which are used to revert it.
+const (
+	CreateSchemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		id SERIAL8 PRIMARY KEY,
+		version TEXT NOT NULL,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL,
+		duration_ms INT8 NOT NULL,
+		backup_tables TEXT[],
+		reverted_at TIMESTAMPTZ
+	);`
+
+	InsertSchemaMigration = `INSERT INTO schema_migrations (version,
+			description, applied_at, duration_ms, backup_tables)
+		VALUES ($1, $2, $3, $4, $5);`
+
+	// SelectAppliedSchemaMigration selects the most recent application of the
+	// migration to version $1 that has not been reverted.
+	SelectAppliedSchemaMigration = `SELECT id, backup_tables
+		FROM schema_migrations
+		WHERE version = $1 AND reverted_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1;`
+
+	SetSchemaMigrationReverted = `UPDATE schema_migrations
+		SET reverted_at = $2
+		WHERE id = $1;`
+
+	SelectSchemaMigrations = `SELECT version, description, applied_at,
+			duration_ms, backup_tables, reverted_at
+		FROM schema_migrations
+		ORDER BY id;`
+)
@@ -0,0 +1,111 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the declarative partitioning of the vins and vouts
+// tables by block_height. Each partition holds the rows of a fixed range of
+// block heights, and is named like vins_p<first height>. A partitioned table's
+// primary key and unique indexes must include the partition key, so the
+// block_height columns are NOT NULL and the unique index columns are extended
+// with block_height.
+const (
+	CreateVinTablePartitioned = `CREATE TABLE IF NOT EXISTS vins (
+		id SERIAL8,
+		tx_hash TEXT,
+		tx_index INT4,
+		tx_tree INT2,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN,
+		block_time TIMESTAMPTZ,
+		prev_tx_hash TEXT,
+		prev_tx_index INT8,
+		prev_tx_tree INT2,
+		value_in INT8,
+		tx_type INT4,
+		block_height INT8 NOT NULL,
+		PRIMARY KEY (id, block_height)
+	) PARTITION BY RANGE (block_height);`
+
+	CreateVoutTablePartitioned = `CREATE TABLE IF NOT EXISTS vouts (
+		id SERIAL8,
+		tx_hash TEXT,
+		tx_index INT4,
+		tx_tree INT2,
+		value INT8,
+		version INT2,
+		pkscript BYTEA,
+		script_req_sigs INT4,
+		script_type TEXT,
+		script_addresses TEXT[],
+		mixed BOOLEAN DEFAULT FALSE,
+		spend_tx_row_id INT8,
+		block_height INT8 NOT NULL,
+		PRIMARY KEY (id, block_height)
+	) PARTITION BY RANGE (block_height);`
+
+	// CreatePartition creates the partition of a table (%[1]s) for the block
+	// heights in [%[2]d, %[3]d).
+	CreatePartition = `CREATE TABLE IF NOT EXISTS %[1]s_p%[2]d PARTITION OF %[1]s
+		FOR VALUES FROM (%[2]d) TO (%[3]d);`
+
+	// SelectTableIsPartitioned checks if the table $1 is partitioned.
+	SelectTableIsPartitioned = `SELECT EXISTS (
+			SELECT 1 FROM pg_partitioned_table
+			WHERE partrelid = to_regclass($1)
+		);`
+
+	// SelectPartitionNames lists the partitions of the table $1.
+	SelectPartitionNames = `SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass($1)
+		ORDER BY c.relname;`
+
+	// SetVinsBlockHeight sets the block_height of the vins from the
+	// transactions table, preferring the height of the mainchain block when a
+	// transaction is in several blocks.
+	SetVinsBlockHeight = `UPDATE vins SET block_height = txns.block_height
+		FROM (
+			SELECT DISTINCT ON (tx_hash) tx_hash, block_height
+			FROM transactions
+			ORDER BY tx_hash, is_mainchain DESC, block_height
+		) AS txns
+		WHERE vins.tx_hash = txns.tx_hash AND vins.block_height IS NULL;`
+
+	// SetVoutsBlockHeight is like SetVinsBlockHeight for the vouts.
+	SetVoutsBlockHeight = `UPDATE vouts SET block_height = txns.block_height
+		FROM (
+			SELECT DISTINCT ON (tx_hash) tx_hash, block_height
+			FROM transactions
+			ORDER BY tx_hash, is_mainchain DESC, block_height
+		) AS txns
+		WHERE vouts.tx_hash = txns.tx_hash AND vouts.block_height IS NULL;`
+
+	// VinsColumns and VoutsColumns are the columns of the vins and vouts
+	// tables, which are copied when the tables are partitioned.
+	VinsColumns = `id, tx_hash, tx_index, tx_tree, is_valid, is_mainchain,
+		block_time, prev_tx_hash, prev_tx_index, prev_tx_tree, value_in, tx_type,
+		block_height`
+	VoutsColumns = `id, tx_hash, tx_index, tx_tree, value, version, pkscript,
+		script_req_sigs, script_type, script_addresses, mixed, spend_tx_row_id,
+		block_height`
+
+	// CountNullBlockHeights counts the rows of a table (%[1]s) without a
+	// block_height, e.g. of transactions no longer in the transactions table,
+	// which cannot be moved into a partition.
+	CountNullBlockHeights = `SELECT COUNT(*) FROM %[1]s WHERE block_height IS NULL;`
+
+	// The conversion of an unpartitioned table (%[1]s) moves its rows into a
+	// new partitioned table, preserving the row ids. The columns (%[2]s) are
+	// listed since the column order of the old table depends on when its
+	// columns were added.
+	RenameUnpartitionedTable = `ALTER TABLE %[1]s RENAME TO %[1]s_unpartitioned;
+		ALTER INDEX %[1]s_pkey RENAME TO %[1]s_unpartitioned_pkey;
+		ALTER SEQUENCE %[1]s_id_seq RENAME TO %[1]s_unpartitioned_id_seq;`
+	CopyUnpartitionedRows = `INSERT INTO %[1]s (%[2]s)
+		SELECT %[2]s FROM %[1]s_unpartitioned;`
+	SetPartitionedSequence = `SELECT setval('%[1]s_id_seq',
+		(SELECT COALESCE(MAX(id), 0) + 1 FROM %[1]s), false);`
+	DropUnpartitionedTable = `DROP TABLE %[1]s_unpartitioned;`
+)
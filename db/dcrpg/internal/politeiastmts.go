@@ -0,0 +1,58 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the proposals retrieved from the Politeia API. The
+// "politeia_proposals" table holds the latest metadata and vote summary of each
+// proposal, while the votes cast on the proposals are stored in the proposals
+// and proposal_votes tables, with a proposals row for each batch of votes
+// retrieved.
+const (
+	CreatePoliteiaProposalsTable = `CREATE TABLE IF NOT EXISTS politeia_proposals (
+		token TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		username TEXT NOT NULL,
+		status TEXT NOT NULL,
+		vote_status TEXT NOT NULL,
+		num_comments INT4 NOT NULL,
+		published_at TIMESTAMPTZ NOT NULL,
+		end_height INT8 NOT NULL,
+		eligible_tickets INT8 NOT NULL,
+		quorum_percentage INT4 NOT NULL,
+		pass_percentage INT4 NOT NULL,
+		yes INT8 NOT NULL,
+		no INT8 NOT NULL,
+		total_votes INT8 NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertPoliteiaProposal = `INSERT INTO politeia_proposals (token, name,
+			username, status, vote_status, num_comments, published_at,
+			end_height, eligible_tickets, quorum_percentage, pass_percentage,
+			yes, no, total_votes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (token)
+		DO UPDATE SET
+		name = $2,
+		username = $3,
+		status = $4,
+		vote_status = $5,
+		num_comments = $6,
+		published_at = $7,
+		end_height = $8,
+		eligible_tickets = $9,
+		quorum_percentage = $10,
+		pass_percentage = $11,
+		yes = $12,
+		no = $13,
+		total_votes = $14,
+		updated_at = $15;`
+
+	// SelectProposalVotedTickets selects the tickets whose votes on the
+	// proposal $1 are stored.
+	SelectProposalVotedTickets = `SELECT proposal_votes.ticket
+		FROM proposal_votes
+		JOIN proposals ON proposals.id = proposal_votes.proposals_row_id
+		WHERE proposals.token = $1;`
+)
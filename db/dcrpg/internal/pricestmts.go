@@ -0,0 +1,32 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "dcr_prices" table of the daily closing prices
+// of DCR imported from a historical price source.
+const (
+	// CreateDCRPricesTable creates the dcr_prices table. Each row is the
+	// closing price of DCR in the currency on the day (UTC).
+	CreateDCRPricesTable = `CREATE TABLE IF NOT EXISTS dcr_prices (
+		day DATE NOT NULL,
+		currency TEXT NOT NULL,
+		price FLOAT8 NOT NULL,
+		source TEXT NOT NULL,
+		PRIMARY KEY (currency, day)
+	);`
+
+	UpsertDCRPrice = `INSERT INTO dcr_prices (day, currency, price, source)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (currency, day) DO UPDATE
+		SET price = $3, source = $4;`
+
+	// SelectLastDCRPriceDay selects the last day with a price in the currency.
+	SelectLastDCRPriceDay = `SELECT MAX(day) FROM dcr_prices WHERE currency = $1;`
+
+	// SelectDCRPrices selects the prices in the currency, ordered by day.
+	SelectDCRPrices = `SELECT day, currency, price, source
+		FROM dcr_prices
+		WHERE currency = $1
+		ORDER BY day;`
+)
@@ -0,0 +1,38 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "block_propagation" table, which records for
+// each new block the time it was received from dcrd and the time StoreBlock
+// completed.
+const (
+	// CreateBlockPropagationTable creates the block_propagation table.
+	// received_at is NULL if the block was not received in a dcrd block
+	// connected notification.
+	CreateBlockPropagationTable = `CREATE TABLE IF NOT EXISTS block_propagation (
+		hash TEXT PRIMARY KEY,
+		height INT8 NOT NULL,
+		block_time TIMESTAMPTZ NOT NULL,
+		received_at TIMESTAMPTZ,
+		stored_at TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertBlockPropagation = `INSERT INTO block_propagation (hash, height,
+			block_time, received_at, stored_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (hash)
+		DO UPDATE SET
+		received_at = $4,
+		stored_at = $5;`
+
+	// SelectBlockPropagation selects the propagation times of the N most
+	// recent mainchain blocks.
+	SelectBlockPropagation = `SELECT block_propagation.height,
+			block_propagation.block_time, received_at, stored_at
+		FROM block_propagation
+		JOIN blocks ON blocks.hash = block_propagation.hash
+		WHERE blocks.is_mainchain
+		ORDER BY block_propagation.height DESC
+		LIMIT $1;`
+)
@@ -0,0 +1,42 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "proposal_vote_snapshots" table, which records
+// the vote tally of each Politeia proposal whose vote is live, as of the block
+// heights at which the proposals were updated.
+const (
+	CreateProposalVoteSnapshotsTable = `CREATE TABLE IF NOT EXISTS proposal_vote_snapshots (
+		token TEXT NOT NULL,
+		height INT8 NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		yes INT8 NOT NULL,
+		no INT8 NOT NULL,
+		eligible_tickets INT8 NOT NULL,
+		quorum_percentage INT4 NOT NULL,
+		pass_percentage INT4 NOT NULL,
+		PRIMARY KEY (token, height)
+	);`
+
+	UpsertProposalVoteSnapshot = `INSERT INTO proposal_vote_snapshots (token,
+			height, time, yes, no, eligible_tickets, quorum_percentage,
+			pass_percentage)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (token, height)
+		DO UPDATE SET
+		time = $3,
+		yes = $4,
+		no = $5,
+		eligible_tickets = $6,
+		quorum_percentage = $7,
+		pass_percentage = $8;`
+
+	// SelectProposalVoteSnapshots selects the snapshots of the proposal's vote
+	// tally, oldest first.
+	SelectProposalVoteSnapshots = `SELECT height, time, yes, no,
+			eligible_tickets, quorum_percentage, pass_percentage
+		FROM proposal_vote_snapshots
+		WHERE token = $1
+		ORDER BY height;`
+)
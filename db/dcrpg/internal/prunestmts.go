@@ -0,0 +1,87 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to pruning mode, in which the vins and spent vouts of
+// old blocks are deleted. The "prune_state" table records the height of the
+// last pruned block, and holds at most one row. The "pruned_supply" table
+// rolls up the atoms minted by each pruned block, which were summed from the
+// deleted vins.
+const (
+	CreatePruneStateTable = `CREATE TABLE IF NOT EXISTS prune_state (
+		id INT4 PRIMARY KEY CHECK (id = 1),
+		pruned_height INT8 NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertPrunedHeight = `INSERT INTO prune_state (id, pruned_height, updated_at)
+		VALUES (1, $1, NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET
+		pruned_height = $1,
+		updated_at = NOW();`
+
+	SelectPrunedHeight = `SELECT pruned_height
+		FROM prune_state
+		WHERE id = 1;`
+
+	// SelectTxLowestBlockHeight selects the lowest height of the blocks
+	// containing the transaction $1, which is compared with the pruned height
+	// since the vins spending the transaction's outputs are in blocks at that
+	// height or above.
+	SelectTxLowestBlockHeight = `SELECT MIN(block_height)
+		FROM transactions
+		WHERE tx_hash = $1;`
+
+	CreatePrunedSupplyTable = `CREATE TABLE IF NOT EXISTS pruned_supply (
+		height INT8 PRIMARY KEY,
+		block_time TIMESTAMPTZ NOT NULL,
+		new_atoms INT8 NOT NULL,
+		circulating INT8 NOT NULL
+	);`
+
+	// InsertPrunedSupply rolls up the atoms minted by the mainchain blocks
+	// with heights in [$1, $2) before their vins are deleted. new_atoms uses
+	// the filters of SelectCoinSupply, and circulating those of
+	// SelectCirculatingSupply, which excludes the TSpends (treasury tx_type
+	// $3).
+	InsertPrunedSupply = `INSERT INTO pruned_supply (height, block_time,
+			new_atoms, circulating)
+		SELECT transactions.block_height, vins.block_time, SUM(vins.value_in),
+			SUM(CASE WHEN vins.value_in > 0 AND NOT EXISTS (SELECT 1 FROM treasury
+					WHERE treasury.tx_hash = vins.tx_hash AND treasury.tx_type = $3)
+				THEN vins.value_in ELSE 0 END)
+		FROM vins JOIN transactions
+		ON vins.tx_hash = transactions.tx_hash
+		WHERE vins.prev_tx_hash = '0000000000000000000000000000000000000000000000000000000000000000'
+			AND transactions.block_height >= $1 AND transactions.block_height < $2
+			AND transactions.is_mainchain
+			AND NOT (vins.is_valid = false AND vins.tx_tree = 0)
+			AND vins.is_mainchain
+		GROUP BY vins.block_time, transactions.block_height
+		ON CONFLICT (height) DO NOTHING;`
+
+	// DeletePrunedVins deletes the vins of the transactions of all blocks,
+	// mainchain or not, with heights in [$1, $2).
+	DeletePrunedVins = `DELETE FROM vins
+		WHERE id IN (
+			SELECT UNNEST(vin_db_ids)
+			FROM transactions
+			WHERE block_height >= $1 AND block_height < $2
+		);`
+
+	// DeletePrunedVouts deletes the vouts of the transactions of all blocks
+	// with heights in [$1, $2) that are either zero-valued or spent by a
+	// transaction in a block below height $2. Unspent vouts, and vouts spent
+	// in the unpruned blocks, are kept for the UTXO set and for reorgs.
+	DeletePrunedVouts = `DELETE FROM vouts
+		WHERE id IN (
+			SELECT UNNEST(vout_db_ids)
+			FROM transactions
+			WHERE block_height >= $1 AND block_height < $2
+		)
+		AND (value = 0 OR spend_tx_row_id IN (
+			SELECT id FROM transactions WHERE block_height < $2
+		));`
+)
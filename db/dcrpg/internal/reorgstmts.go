@@ -0,0 +1,36 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "reorgs" table, which records each chain
+// reorganization processed by dcrdata.
+const (
+	// CreateReorgsTable creates the reorgs table. depth is the number of
+	// blocks orphaned from the old main chain, and orphaned_txns is the number
+	// of regular and stake transactions in those blocks.
+	CreateReorgsTable = `CREATE TABLE IF NOT EXISTS reorgs (
+		id SERIAL8 PRIMARY KEY,
+		old_tip_hash TEXT NOT NULL,
+		old_tip_height INT8 NOT NULL,
+		new_tip_hash TEXT NOT NULL,
+		new_tip_height INT8 NOT NULL,
+		common_ancestor_hash TEXT NOT NULL,
+		depth INT8 NOT NULL,
+		orphaned_txns INT8 NOT NULL,
+		time TIMESTAMPTZ NOT NULL
+	);`
+
+	InsertReorgRow = `INSERT INTO reorgs (old_tip_hash, old_tip_height,
+		new_tip_hash, new_tip_height, common_ancestor_hash, depth,
+		orphaned_txns, time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	SelectReorgs = `SELECT old_tip_hash, old_tip_height, new_tip_hash,
+			new_tip_height, common_ancestor_hash, depth, orphaned_txns, time
+		FROM reorgs
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2;`
+
+	SelectReorgsCount = `SELECT COUNT(*) FROM reorgs;`
+)
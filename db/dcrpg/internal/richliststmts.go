@@ -0,0 +1,68 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "rich_list" table of the top addresses by
+// balance, and the "balance_distribution" table of the number of addresses
+// and their total balance in each balance range. Both tables are periodically
+// rebuilt from the addresses table in a single transaction.
+const (
+	CreateRichListTable = `CREATE TABLE IF NOT EXISTS rich_list (
+		rank INT4 PRIMARY KEY,
+		address TEXT NOT NULL,
+		balance INT8 NOT NULL,
+		height INT8 NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`
+
+	// CreateBalanceDistributionTable creates the balance_distribution table.
+	// Each row is for the addresses with a balance of at least min_balance,
+	// but less than the next row's min_balance.
+	CreateBalanceDistributionTable = `CREATE TABLE IF NOT EXISTS balance_distribution (
+		min_balance INT8 PRIMARY KEY,
+		num_addresses INT8 NOT NULL,
+		total_balance INT8 NOT NULL
+	);`
+
+	// CreateTempAddressBalances computes the balance of every address with
+	// unspent outputs, for the rebuild of both tables.
+	CreateTempAddressBalances = `CREATE TEMP TABLE address_balances ON COMMIT DROP AS
+		SELECT address, SUM(value) AS balance
+		FROM addresses
+		WHERE is_funding AND valid_mainchain AND matching_tx_hash = ''
+		GROUP BY address;`
+
+	DeleteRichList = `DELETE FROM rich_list;`
+
+	InsertRichList = `INSERT INTO rich_list (rank, address, balance, height, updated_at)
+		SELECT ROW_NUMBER() OVER (ORDER BY balance DESC, address),
+			address, balance, $2, NOW()
+		FROM address_balances
+		ORDER BY balance DESC, address
+		LIMIT $1;`
+
+	DeleteBalanceDistribution = `DELETE FROM balance_distribution;`
+
+	// InsertBalanceDistribution puts each address in the range with the
+	// largest of the minimum balances in $1 that does not exceed its balance.
+	InsertBalanceDistribution = `INSERT INTO balance_distribution (min_balance,
+			num_addresses, total_balance)
+		SELECT bucket, COUNT(*), SUM(balance)
+		FROM (
+			SELECT balance,
+				(SELECT MAX(b) FROM UNNEST($1::INT8[]) AS b WHERE b <= balance) AS bucket
+			FROM address_balances
+		) AS bucketed
+		WHERE bucket IS NOT NULL
+		GROUP BY bucket;`
+
+	SelectRichList = `SELECT rank, address, balance, height, updated_at
+		FROM rich_list
+		ORDER BY rank
+		LIMIT $1;`
+
+	SelectBalanceDistribution = `SELECT min_balance, num_addresses, total_balance
+		FROM balance_distribution
+		ORDER BY min_balance;`
+)
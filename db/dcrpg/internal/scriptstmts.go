@@ -0,0 +1,109 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the classification of the output scripts. The
+// "script_type_blocks" table has the number and value of the outputs of each
+// script type in each block, and the "nonstandard_outputs" table has the
+// outputs with nonstandard scripts and their disassembly. The rows of both are
+// keyed by block hash, and only the rows of mainchain blocks are selected.
+const (
+	// CreateScriptTypeBlocksTable creates the script_type_blocks table. value
+	// is the total value of the outputs, in atoms.
+	CreateScriptTypeBlocksTable = `CREATE TABLE IF NOT EXISTS script_type_blocks (
+		block_hash TEXT NOT NULL,
+		block_time TIMESTAMPTZ NOT NULL,
+		script_type TEXT NOT NULL,
+		count INT8 NOT NULL,
+		value INT8 NOT NULL,
+		PRIMARY KEY (block_hash, script_type)
+	);`
+
+	// CreateNonstandardOutputsTable creates the nonstandard_outputs table.
+	CreateNonstandardOutputsTable = `CREATE TABLE IF NOT EXISTS nonstandard_outputs (
+		block_hash TEXT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		tx_index INT4 NOT NULL,
+		tx_tree INT2 NOT NULL,
+		block_height INT8 NOT NULL,
+		block_time TIMESTAMPTZ NOT NULL,
+		value INT8 NOT NULL,
+		version INT4 NOT NULL,
+		pkscript BYTEA NOT NULL,
+		disasm TEXT NOT NULL,
+		PRIMARY KEY (block_hash, tx_hash, tx_index)
+	);`
+
+	UpsertScriptTypeBlock = `INSERT INTO script_type_blocks (block_hash,
+			block_time, script_type, count, value)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (block_hash, script_type) DO UPDATE
+		SET count = $4, value = $5;`
+
+	InsertNonstandardOutput = `INSERT INTO nonstandard_outputs (block_hash,
+			tx_hash, tx_index, tx_tree, block_height, block_time, value,
+			version, pkscript, disasm)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (block_hash, tx_hash, tx_index) DO NOTHING;`
+
+	// BackfillScriptTypeBlocks counts the script types of the outputs of the
+	// blocks already in the vouts table.
+	BackfillScriptTypeBlocks = `INSERT INTO script_type_blocks (block_hash,
+			block_time, script_type, count, value)
+		SELECT transactions.block_hash, MIN(transactions.block_time),
+			vouts.script_type, COUNT(*), SUM(vouts.value)
+		FROM transactions
+		JOIN vouts ON vouts.tx_hash = transactions.tx_hash
+			AND vouts.tx_tree = transactions.tree
+		GROUP BY transactions.block_hash, vouts.script_type
+		ON CONFLICT (block_hash, script_type) DO NOTHING;`
+
+	// SelectNonstandardVouts selects the outputs with nonstandard scripts
+	// already in the vouts table, with each block containing them, for the
+	// backfill of the nonstandard_outputs table.
+	SelectNonstandardVouts = `SELECT transactions.block_hash, vouts.tx_hash,
+			vouts.tx_index, vouts.tx_tree, transactions.block_height,
+			transactions.block_time, vouts.value, vouts.version, vouts.pkscript
+		FROM vouts
+		JOIN transactions ON transactions.tx_hash = vouts.tx_hash
+			AND transactions.tree = vouts.tx_tree
+		WHERE vouts.script_type = 'nonstandard';`
+
+	// selectScriptTypeCounts is formatted with the time grouping by
+	// MakeSelectScriptTypeCounts.
+	selectScriptTypeCounts = `SELECT %s AS period, script_type_blocks.script_type,
+			SUM(script_type_blocks.count), SUM(script_type_blocks.value)
+		FROM script_type_blocks
+		JOIN blocks ON blocks.hash = script_type_blocks.block_hash
+			AND blocks.is_mainchain
+		GROUP BY period, script_type_blocks.script_type
+		ORDER BY period;`
+
+	SelectNonstandardOutputsCount = `SELECT COUNT(*)
+		FROM nonstandard_outputs
+		JOIN blocks ON blocks.hash = nonstandard_outputs.block_hash
+			AND blocks.is_mainchain;`
+
+	// SelectNonstandardOutputs selects a page of the mainchain nonstandard
+	// outputs, most recent first, with limit $1 and offset $2.
+	SelectNonstandardOutputs = `SELECT nonstandard_outputs.block_hash,
+			nonstandard_outputs.tx_hash, nonstandard_outputs.tx_index,
+			nonstandard_outputs.tx_tree, nonstandard_outputs.block_height,
+			nonstandard_outputs.block_time, nonstandard_outputs.value,
+			nonstandard_outputs.version, nonstandard_outputs.pkscript,
+			nonstandard_outputs.disasm
+		FROM nonstandard_outputs
+		JOIN blocks ON blocks.hash = nonstandard_outputs.block_hash
+			AND blocks.is_mainchain
+		ORDER BY nonstandard_outputs.block_height DESC,
+			nonstandard_outputs.tx_hash, nonstandard_outputs.tx_index
+		LIMIT $1 OFFSET $2;`
+)
+
+// MakeSelectScriptTypeCounts returns the selectScriptTypeCounts query for the
+// given time grouping (e.g. "day", or "all" for each block).
+func MakeSelectScriptTypeCounts(group string) string {
+	return formatGroupingQuery(selectScriptTypeCounts, group,
+		"script_type_blocks.block_time")
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries are used by the search package. The hash prefix searches use
+// a range predicate rather than LIKE since the hashes are lowercase hex, which
+// allows the existing btree indexes to be used with any database collation.
+const (
+	// CreateProposalTitlesTable creates the proposal_titles table, the
+	// full-text search index of the Politeia proposal titles, which are synced
+	// from the proposals DB by the search package.
+	CreateProposalTitlesTable = `CREATE TABLE IF NOT EXISTS proposal_titles (
+		token TEXT PRIMARY KEY,
+		ref_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		tsv TSVECTOR NOT NULL
+	);`
+
+	UpsertProposalTitle = `INSERT INTO proposal_titles (token, ref_id, title, tsv)
+		VALUES ($1, $2, $3, to_tsvector('english', $3))
+		ON CONFLICT (token) DO UPDATE
+		SET ref_id = EXCLUDED.ref_id, title = EXCLUDED.title, tsv = EXCLUDED.tsv;`
+
+	// SearchProposalTitles selects the proposals with titles matching the
+	// words of $1, a ref_id equal to $1, or a token with the prefix $2, ranked
+	// by ts_rank with exact token and ref_id matches first. $2 is NULL unless
+	// the query is hex. The table is small, so no index is used.
+	SearchProposalTitles = `SELECT token, title,
+			CASE WHEN token LIKE $2 || '%' OR ref_id = $1 THEN 1
+			ELSE ts_rank(tsv, plainto_tsquery('english', $1)) END AS rank
+		FROM proposal_titles
+		WHERE tsv @@ plainto_tsquery('english', $1)
+			OR token LIKE $2 || '%'
+			OR ref_id = $1
+		ORDER BY rank DESC, token
+		LIMIT $3;`
+
+	SearchBlocksByHashPrefix = `SELECT hash, height
+		FROM blocks
+		WHERE hash >= $1 AND hash < $1 || 'g' AND is_mainchain
+		ORDER BY hash
+		LIMIT $2;`
+
+	SearchBlocksByHeightRange = `SELECT hash, height
+		FROM blocks
+		WHERE height BETWEEN $1 AND $2 AND is_mainchain
+		ORDER BY height
+		LIMIT $3;`
+
+	// SearchTransactionsByHashPrefix selects the transactions with the hash
+	// prefix $1, and the height of the mainchain block including each if
+	// there is one.
+	SearchTransactionsByHashPrefix = `SELECT DISTINCT ON (tx_hash) tx_hash, block_height
+		FROM transactions
+		WHERE tx_hash >= $1 AND tx_hash < $1 || 'g'
+		ORDER BY tx_hash, is_mainchain DESC
+		LIMIT $2;`
+
+	// SearchAddressesByPrefix selects the distinct addresses with the prefix
+	// $1 using the text_pattern_ops index.
+	SearchAddressesByPrefix = `SELECT address
+		FROM (SELECT DISTINCT address FROM addresses WHERE address LIKE $1 || '%') AS matches
+		ORDER BY address
+		LIMIT $2;`
+)
@@ -0,0 +1,28 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "side_chain_blocks" table of the full serialized
+// data of side chain blocks, including the blocks orphaned by reorgs.
+const (
+	// CreateSideChainBlocksTable creates the side_chain_blocks table. The
+	// blocks table row of a block indicates if it is still a side chain block.
+	CreateSideChainBlocksTable = `CREATE TABLE IF NOT EXISTS side_chain_blocks (
+		hash TEXT PRIMARY KEY,
+		height INT8 NOT NULL,
+		raw BYTEA NOT NULL,
+		stored_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);`
+
+	InsertSideChainBlockRow = `INSERT INTO side_chain_blocks (hash, height, raw)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (hash) DO NOTHING;`
+
+	// SelectSideChainBlockRaw selects the serialized block and the validity of
+	// a block that is not on the main chain.
+	SelectSideChainBlockRaw = `SELECT side_chain_blocks.raw, blocks.is_valid
+		FROM side_chain_blocks
+		JOIN blocks ON blocks.hash = side_chain_blocks.hash
+		WHERE side_chain_blocks.hash = $1 AND blocks.is_mainchain = FALSE;`
+)
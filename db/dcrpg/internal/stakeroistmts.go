@@ -0,0 +1,22 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries retrieve the historical ticket prices, pool sizes, and vote
+// rewards of the staking returns simulation.
+const (
+	// SelectStakeROIBlock selects the ticket price and pool size of the
+	// mainchain block at a height.
+	SelectStakeROIBlock = `SELECT sbits, pool_size, time
+		FROM blocks
+		WHERE height = $1 AND is_mainchain;`
+
+	// SelectStakeROIVote selects the first mainchain vote at or above a
+	// height, with its reward in DCR.
+	SelectStakeROIVote = `SELECT height, vote_reward, block_time
+		FROM votes
+		WHERE height >= $1 AND is_mainchain
+		ORDER BY height
+		LIMIT 1;`
+)
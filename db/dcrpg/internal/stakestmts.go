@@ -106,6 +106,25 @@ const (
 	SelectTicketStatusByHash   = `SELECT id, spend_type, pool_status FROM tickets` + forTxHashMainchainFirst
 	SelectTicketInfoByHash     = `SELECT block_hash, block_height, spend_type, pool_status, spend_tx_db_id FROM tickets` + forTxHashMainchainFirst
 
+	// SelectTicketLifecycle selects the purchase, the mainchain block at which
+	// the ticket matures (the block_height offset $2 is the ticket maturity),
+	// and the spending vote or revocation of the ticket with hash $1.
+	SelectTicketLifecycle = `SELECT tickets.block_hash, tickets.block_height,
+			purchase.block_time, tickets.price, tickets.fee, tickets.spend_type,
+			tickets.pool_status, live.hash, live.time, spend.tx_hash,
+			spend.block_hash, spend.block_height, spend.block_time,
+			votes.vote_reward
+		FROM tickets
+		JOIN transactions AS purchase ON purchase.id = tickets.purchase_tx_db_id
+		LEFT JOIN blocks AS live
+			ON live.height = tickets.block_height + $2 AND live.is_mainchain
+		LEFT JOIN transactions AS spend ON spend.id = tickets.spend_tx_db_id
+		LEFT JOIN votes
+			ON votes.tx_hash = spend.tx_hash AND votes.block_hash = spend.block_hash
+		WHERE tickets.tx_hash = $1
+		ORDER BY tickets.is_mainchain DESC
+		LIMIT 1;`
+
 	SelectUnspentTickets = `SELECT id, tx_hash FROM tickets
 		WHERE spend_type = 0 AND is_mainchain = true;`
 
@@ -274,6 +293,22 @@ const (
 		SET is_mainchain=$1
 		WHERE block_hash=$2;`
 
+	// selectVoteVersionHistory is formatted with the time grouping by
+	// MakeSelectVoteVersionHistory.
+	selectVoteVersionHistory = `SELECT %s AS period, version, COUNT(*)
+		FROM votes
+		WHERE is_mainchain
+		GROUP BY period, version
+		ORDER BY period, version;`
+
+	// SelectVoteBitsCounts counts the mainchain votes with version $1 in the
+	// blocks with heights in [$2, $3] by their vote bits.
+	SelectVoteBitsCounts = `SELECT vote_bits, COUNT(*)
+		FROM votes
+		WHERE is_mainchain AND version = $1 AND height BETWEEN $2 AND $3
+		GROUP BY vote_bits
+		ORDER BY COUNT(*) DESC;`
+
 	// misses table
 
 	CreateMissesTable = `CREATE TABLE IF NOT EXISTS misses (
@@ -460,11 +495,14 @@ const (
 
 	// Proposals Table
 
+	// CreateProposalsTable creates the proposals table, with a row for each
+	// batch of votes on a proposal. commit_sha is only set for the votes that
+	// were parsed from Politeia's git repository.
 	CreateProposalsTable = `CREATE TABLE IF NOT EXISTS proposals (
 		id SERIAL PRIMARY KEY,
 		token TEXT NOT NULL,
 		author TEXT,
-		commit_sha TEXT NOT NULL,
+		commit_sha TEXT,
 		time TIMESTAMPTZ
 	);`
 
@@ -475,9 +513,6 @@ const (
 
 	InsertProposalsRow = insertProposalsRow + `RETURNING id;`
 
-	UpsertProposalsRow = insertProposalsRow + `ON CONFLICT (token, time)
-		DO UPDATE SET commit_sha = $3, time = $4  RETURNING id;`
-
 	// Index
 
 	IndexProposalsTableOnToken = `CREATE UNIQUE INDEX ` + IndexOfProposalsTableOnToken +
@@ -485,13 +520,6 @@ const (
 
 	DeindexProposalsTableOnToken = `DROP INDEX ` + IndexOfProposalsTableOnToken + ` CASCADE;`
 
-	// Select
-
-	SelectProposalsLastCommitTime = `Select time
-		FROM proposals
-		ORDER BY time DESC
-		LIMIT 1;`
-
 	// Proposal Votes table
 
 	CreateProposalVotesTable = `CREATE TABLE IF NOT EXISTS proposal_votes (
@@ -592,17 +620,13 @@ func MakeAgendaVotesInsertStatement(checked bool) string {
 	return InsertAgendaVotesRow
 }
 
-// MakeProposalsInsertStatement returns the appropriate proposals insert statement for
-// the desired conflict checking and handling behavior. See the description of
-// MakeTicketInsertStatement for details.
-func MakeProposalsInsertStatement(checked bool) string {
-	if checked {
-		return UpsertProposalsRow
-	}
-	return InsertProposalsRow
-}
-
 // MakeSelectTicketsByPurchaseDate returns the selectTicketsByPurchaseDate query
 func MakeSelectTicketsByPurchaseDate(group string) string {
 	return formatGroupingQuery(selectTicketsByPurchaseDate, group, "transactions.block_time")
 }
+
+// MakeSelectVoteVersionHistory returns the selectVoteVersionHistory query for
+// the given time grouping (e.g. "day", or "all" for each block).
+func MakeSelectVoteVersionHistory(group string) string {
+	return formatGroupingQuery(selectVoteVersionHistory, group, "block_time")
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "swaps" table of the atomic swap contracts
+// redeemed or refunded by the inputs of the regular transactions. A contract is
+// only revealed when it is spent, so there is one row per spend.
+const (
+	// CreateSwapsTable creates the swaps table, and the indexes on the contract
+	// and spending transactions and the block time. The secret is NULL for a
+	// refund.
+	CreateSwapsTable = `CREATE TABLE IF NOT EXISTS swaps (
+		id SERIAL8 PRIMARY KEY,
+		contract_tx TEXT NOT NULL,
+		contract_vout INT4 NOT NULL,
+		contract_address TEXT NOT NULL,
+		recipient_address TEXT NOT NULL,
+		refund_address TEXT NOT NULL,
+		value INT8 NOT NULL,
+		secret_hash BYTEA NOT NULL,
+		secret BYTEA,
+		lock_time INT8 NOT NULL,
+		spend_tx TEXT NOT NULL,
+		spend_vin INT4 NOT NULL,
+		block_hash TEXT NOT NULL,
+		block_height INT8 NOT NULL,
+		block_time TIMESTAMPTZ NOT NULL,
+		is_mainchain BOOLEAN NOT NULL,
+		UNIQUE (spend_tx, spend_vin, block_hash)
+	);
+	CREATE INDEX IF NOT EXISTS ix_swaps_contract_tx ON swaps(contract_tx);
+	CREATE INDEX IF NOT EXISTS ix_swaps_spend_tx ON swaps(spend_tx);
+	CREATE INDEX IF NOT EXISTS ix_swaps_block_time ON swaps(block_time);`
+
+	UpsertSwapRow = `INSERT INTO swaps (contract_tx, contract_vout,
+		contract_address, recipient_address, refund_address, value,
+		secret_hash, secret, lock_time, spend_tx, spend_vin, block_hash,
+		block_height, block_time, is_mainchain)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (spend_tx, spend_vin, block_hash)
+		DO UPDATE SET is_mainchain = $15;`
+
+	UpdateSwapsMainchainByBlock = `UPDATE swaps SET is_mainchain = $1
+		WHERE block_hash = $2;`
+
+	DeleteSwapsForBlock = `DELETE FROM swaps WHERE block_hash = $1;`
+
+	selectSwapColumns = `SELECT contract_tx, contract_vout, contract_address,
+			recipient_address, refund_address, value, secret_hash, secret,
+			lock_time, spend_tx, spend_vin, block_height, block_time
+		FROM swaps`
+
+	// SelectRecentSwaps selects the $1 most recent mainchain swap spends,
+	// skipping $2.
+	SelectRecentSwaps = selectSwapColumns + `
+		WHERE is_mainchain
+		ORDER BY block_height DESC, spend_tx, spend_vin
+		LIMIT $1 OFFSET $2;`
+
+	// SelectSwapsForTx selects the mainchain swap spends of the contracts
+	// created or spent by the transaction.
+	SelectSwapsForTx = selectSwapColumns + `
+		WHERE (contract_tx = $1 OR spend_tx = $1) AND is_mainchain
+		ORDER BY spend_tx, spend_vin;`
+)
@@ -0,0 +1,61 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "sync_state" table, which records the phase of
+// an in-progress bulk import so that an interrupted import can be resumed.
+// The table holds at most one row.
+const (
+	CreateSyncStateTable = `CREATE TABLE IF NOT EXISTS sync_state (
+		id INT4 PRIMARY KEY CHECK (id = 1),
+		phase TEXT NOT NULL,
+		height INT8 NOT NULL,
+		bulk_reindex BOOLEAN NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertSyncState = `INSERT INTO sync_state (id, phase, height, bulk_reindex, updated_at)
+		VALUES (1, $1, $2, $3, NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET
+		phase = $1,
+		height = $2,
+		bulk_reindex = $3,
+		updated_at = NOW();`
+
+	SelectSyncState = `SELECT phase, height, bulk_reindex, updated_at
+		FROM sync_state
+		WHERE id = 1;`
+
+	DeleteSyncState = `DELETE FROM sync_state;`
+)
+
+// These queries relate to the "address_spend_info" table, which records the
+// height of the last block whose spending transactions have been used to
+// populate the spending info of the funding rows of the addresses table. The
+// table holds at most one row.
+const (
+	CreateAddressSpendInfoTable = `CREATE TABLE IF NOT EXISTS address_spend_info (
+		id INT4 PRIMARY KEY CHECK (id = 1),
+		last_height INT8 NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);`
+
+	UpsertAddressSpendInfoHeight = `INSERT INTO address_spend_info (id, last_height, updated_at)
+		VALUES (1, $1, NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET
+		last_height = $1,
+		updated_at = NOW();`
+
+	SelectAddressSpendInfoHeight = `SELECT last_height
+		FROM address_spend_info
+		WHERE id = 1;`
+
+	// LowerAddressSpendInfoHeight lowers the recorded height when blocks above
+	// it are removed, so that the replacement blocks are processed.
+	LowerAddressSpendInfoHeight = `UPDATE address_spend_info
+		SET last_height = $1, updated_at = NOW()
+		WHERE last_height > $1;`
+)
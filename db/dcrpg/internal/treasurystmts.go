@@ -0,0 +1,116 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "treasury" table of treasury add (TAdd), treasury
+// spend (TSpend), and treasurybase transactions, and the "treasury_votes"
+// table of the stakeholder votes on TSpends.
+const (
+	// CreateTreasuryTable creates the treasury table. The value is positive
+	// for additions to the treasury and negative for spends.
+	CreateTreasuryTable = `CREATE TABLE IF NOT EXISTS treasury (
+		id SERIAL8 PRIMARY KEY,
+		tx_hash TEXT NOT NULL,
+		tx_type INT4 NOT NULL,
+		value INT8 NOT NULL,
+		block_hash TEXT NOT NULL,
+		block_height INT8 NOT NULL,
+		block_time TIMESTAMPTZ NOT NULL,
+		is_mainchain BOOLEAN NOT NULL,
+		UNIQUE (tx_hash, block_hash)
+	);`
+
+	UpsertTreasuryRow = `INSERT INTO treasury (tx_hash, tx_type, value,
+		block_hash, block_height, block_time, is_mainchain)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tx_hash, block_hash)
+		DO UPDATE SET is_mainchain = $7;`
+
+	UpdateTreasuryMainchainByBlock = `UPDATE treasury SET is_mainchain = $1
+		WHERE block_hash = $2;`
+
+	DeleteTreasuryTxnsForBlock = `DELETE FROM treasury WHERE block_hash = $1;`
+
+	SelectTreasuryBalance = `SELECT COALESCE(SUM(value), 0),
+			COUNT(*) FILTER (WHERE value > 0),
+			COUNT(*) FILTER (WHERE value < 0)
+		FROM treasury
+		WHERE is_mainchain;`
+
+	// selectTreasuryBalanceHistory is formatted with the time grouping by
+	// MakeSelectTreasuryBalanceHistory.
+	selectTreasuryBalanceHistory = `SELECT period, added, spent,
+			SUM(added - spent) OVER (ORDER BY period) AS balance
+		FROM (
+			SELECT %s AS period,
+				COALESCE(SUM(value) FILTER (WHERE value > 0), 0) AS added,
+				COALESCE(-SUM(value) FILTER (WHERE value < 0), 0) AS spent
+			FROM treasury
+			WHERE is_mainchain
+			GROUP BY period
+		) AS grouped
+		ORDER BY period;`
+
+	SelectTSpendByHash = `SELECT value, block_hash, block_height, block_time
+		FROM treasury
+		WHERE tx_hash = $1 AND tx_type = $2 AND is_mainchain;`
+
+	// CreateTreasuryVotesTable creates the treasury_votes table. The choice
+	// is 1 for yes and 2 for no.
+	CreateTreasuryVotesTable = `CREATE TABLE IF NOT EXISTS treasury_votes (
+		id SERIAL8 PRIMARY KEY,
+		tspend_hash TEXT NOT NULL,
+		vote_hash TEXT NOT NULL,
+		choice INT2 NOT NULL,
+		block_hash TEXT NOT NULL,
+		block_height INT8 NOT NULL,
+		is_mainchain BOOLEAN NOT NULL,
+		UNIQUE (tspend_hash, vote_hash, block_hash)
+	);`
+
+	UpsertTreasuryVoteRow = `INSERT INTO treasury_votes (tspend_hash, vote_hash,
+		choice, block_hash, block_height, is_mainchain)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tspend_hash, vote_hash, block_hash)
+		DO UPDATE SET is_mainchain = $6;`
+
+	UpdateTreasuryVotesMainchainByBlock = `UPDATE treasury_votes SET is_mainchain = $1
+		WHERE block_hash = $2;`
+
+	DeleteTreasuryVotesForBlock = `DELETE FROM treasury_votes WHERE block_hash = $1;`
+
+	SelectTSpendVoteTally = `SELECT
+			COUNT(*) FILTER (WHERE choice = 1),
+			COUNT(*) FILTER (WHERE choice = 2)
+		FROM treasury_votes
+		WHERE tspend_hash = $1 AND is_mainchain;`
+
+	// SelectInvoicePayments selects the amounts paid to the address $2 by the
+	// valid mainchain transactions $1, identifying the TSpends (treasury
+	// tx_type $3) and the transactions spending outputs of the legacy treasury
+	// address $4.
+	SelectInvoicePayments = `SELECT vouts.tx_hash, transactions.block_height,
+			transactions.block_time, SUM(vouts.value),
+			EXISTS (SELECT 1 FROM treasury
+				WHERE treasury.tx_hash = vouts.tx_hash AND treasury.tx_type = $3
+					AND treasury.is_mainchain) AS tspend,
+			EXISTS (SELECT 1 FROM vins
+				JOIN vouts AS prevouts ON prevouts.tx_hash = vins.prev_tx_hash
+					AND prevouts.tx_index = vins.prev_tx_index
+				WHERE vins.tx_hash = vouts.tx_hash
+					AND $4 = ANY(prevouts.script_addresses)) AS legacy
+		FROM vouts
+		JOIN transactions ON transactions.tx_hash = vouts.tx_hash
+		WHERE vouts.tx_hash = ANY($1)
+			AND $2 = ANY(vouts.script_addresses)
+			AND transactions.is_mainchain AND transactions.is_valid
+		GROUP BY vouts.tx_hash, transactions.block_height, transactions.block_time
+		ORDER BY transactions.block_height;`
+)
+
+// MakeSelectTreasuryBalanceHistory returns the selectTreasuryBalanceHistory
+// query for the given time grouping (e.g. "day", or "all" for each block).
+func MakeSelectTreasuryBalanceHistory(group string) string {
+	return formatGroupingQuery(selectTreasuryBalanceHistory, group, "block_time")
+}
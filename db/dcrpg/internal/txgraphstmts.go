@@ -0,0 +1,59 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries traverse the transaction graph, in which each mainchain vin
+// is an edge from the transaction of the previous outpoint to the spending
+// transaction. Each recursive query returns the edges up to $2 hops from the
+// transaction $1, limited to $3 rows. Since the recursion is evaluated lazily,
+// the limit also bounds the traversal. Coinbase and stakebase inputs, which
+// have no previous outpoint, are excluded.
+const (
+	// SelectTxGraphAncestors selects the edges to the transaction and its
+	// ancestors, with the depth of the funding transaction.
+	SelectTxGraphAncestors = `WITH RECURSIVE edges AS (
+			SELECT prev_tx_hash, prev_tx_index, tx_hash, tx_index, value_in, 1 AS depth
+			FROM vins
+			WHERE tx_hash = $1
+				AND is_mainchain
+				AND prev_tx_hash != '0000000000000000000000000000000000000000000000000000000000000000'
+		UNION
+			SELECT vins.prev_tx_hash, vins.prev_tx_index, vins.tx_hash,
+				vins.tx_index, vins.value_in, edges.depth + 1
+			FROM edges
+			JOIN vins ON vins.tx_hash = edges.prev_tx_hash
+			WHERE edges.depth < $2
+				AND vins.is_mainchain
+				AND vins.prev_tx_hash != '0000000000000000000000000000000000000000000000000000000000000000'
+		)
+		SELECT prev_tx_hash, prev_tx_index, tx_hash, tx_index, value_in, depth
+		FROM edges
+		LIMIT $3;`
+
+	// SelectTxGraphDescendants selects the edges from the transaction and its
+	// descendants, with the depth of the spending transaction.
+	SelectTxGraphDescendants = `WITH RECURSIVE edges AS (
+			SELECT prev_tx_hash, prev_tx_index, tx_hash, tx_index, value_in, 1 AS depth
+			FROM vins
+			WHERE prev_tx_hash = $1
+				AND is_mainchain
+		UNION
+			SELECT vins.prev_tx_hash, vins.prev_tx_index, vins.tx_hash,
+				vins.tx_index, vins.value_in, edges.depth + 1
+			FROM edges
+			JOIN vins ON vins.prev_tx_hash = edges.tx_hash
+			WHERE edges.depth < $2
+				AND vins.is_mainchain
+		)
+		SELECT prev_tx_hash, prev_tx_index, tx_hash, tx_index, value_in, depth
+		FROM edges
+		LIMIT $3;`
+
+	// SelectTxGraphNodes selects the mainchain transactions of the graph.
+	SelectTxGraphNodes = `SELECT tx_hash, block_height, block_time, tx_type,
+			sent, fees
+		FROM transactions
+		WHERE tx_hash = ANY($1)
+			AND is_mainchain;`
+)
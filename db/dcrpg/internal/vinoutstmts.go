@@ -20,12 +20,14 @@ const (
 		prev_tx_index INT8,
 		prev_tx_tree INT2,
 		value_in INT8,
-		tx_type INT4
+		tx_type INT4,
+		block_height INT8
 	);`
 
 	// insertVinRow is the basis for several vinvs insert/upsert statements.
 	insertVinRow = `INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
-		value_in, is_valid, is_mainchain, block_time, tx_type) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) `
+		value_in, is_valid, is_mainchain, block_time, tx_type, block_height)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) `
 
 	// InsertVinRow inserts a new vin row without checking for unique index
 	// conflicts. This should only be used before the unique indexes are created
@@ -55,6 +57,74 @@ const (
 		WHERE  tx_hash = $1 AND tx_index = $2 AND tx_tree = $3 -- only executed if no INSERT
 		LIMIT  1;`
 
+	// insertVinRowPartitioned is like insertVinRow, but inserts the row with a
+	// SELECT that the upserts of a partitioned vins table make conditional.
+	// The parameter types are not inferred from a SELECT, so they are cast.
+	insertVinRowPartitioned = `INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash,
+			prev_tx_index, prev_tx_tree, value_in, is_valid, is_mainchain, block_time,
+			tx_type, block_height)
+		SELECT $1::TEXT, $2::INT4, $3::INT2, $4::TEXT, $5::INT8, $6::INT2, $7::INT8,
+			$8::BOOLEAN, $9::BOOLEAN, $10::TIMESTAMPTZ, $11::INT4, $12::INT8 `
+
+	// UpsertVinRowPartitioned is like UpsertVinRow, but for a vins table
+	// partitioned by block_height. The unique index of a partitioned table
+	// must include the partition key, so the uniqueness of (tx_hash, tx_index,
+	// tx_tree) is checked here instead, updating an existing row of the
+	// transaction at any block height, e.g. of a side chain block.
+	UpsertVinRowPartitioned = `WITH updated AS (
+			UPDATE vins SET is_valid = $8, is_mainchain = $9, block_time = $10,
+				prev_tx_hash = $4, prev_tx_index = $5, prev_tx_tree = $6
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			RETURNING id
+		), inserting AS (` +
+		insertVinRowPartitioned +
+		`	WHERE NOT EXISTS (SELECT 1 FROM updated)
+			RETURNING id
+		)
+		SELECT id FROM updated
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// InsertVinRowPartitionedOnConflictDoNothing is like
+	// InsertVinRowOnConflictDoNothing, but for a vins table partitioned by
+	// block_height. Like UpsertVinRowPartitioned, an existing row of the
+	// transaction at any block height is a conflict.
+	InsertVinRowPartitionedOnConflictDoNothing = `WITH existing AS (
+			SELECT id FROM vins
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			LIMIT 1
+		), inserting AS (` +
+		insertVinRowPartitioned +
+		`	WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		SELECT id FROM existing
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// CreateVinsCopyTable creates the temporary table into which vins are
+	// written with the COPY protocol before they are inserted into the vins
+	// table. The table is dropped when the transaction is committed.
+	CreateVinsCopyTable = `CREATE TEMP TABLE vins_copy ON COMMIT DROP AS
+		SELECT tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
+			value_in, is_valid, is_mainchain, block_time, tx_type, block_height
+		FROM vins WITH NO DATA;`
+
+	// InsertVinsFromCopy inserts the rows of the vins_copy table without
+	// checking for unique index conflicts, returning the new row ids with the
+	// unique index columns so they may be matched to the inserted vins.
+	InsertVinsFromCopy = `INSERT INTO vins (tx_hash, tx_index, tx_tree, prev_tx_hash,
+			prev_tx_index, prev_tx_tree, value_in, is_valid, is_mainchain, block_time,
+			tx_type, block_height)
+		SELECT tx_hash, tx_index, tx_tree, prev_tx_hash, prev_tx_index, prev_tx_tree,
+			value_in, is_valid, is_mainchain, block_time, tx_type, block_height
+		FROM vins_copy
+		RETURNING id, tx_hash, tx_index, tx_tree;`
+
+	TruncateVinsCopy = `TRUNCATE vins_copy;`
+
 	// DeleteVinsDuplicateRows removes rows that would violate the unique index
 	// uix_vin. This should be run prior to creating the index.
 	DeleteVinsDuplicateRows = `DELETE FROM vins
@@ -87,6 +157,12 @@ const (
 
 	IndexVinTableOnVins = `CREATE UNIQUE INDEX ` + IndexOfVinsTableOnVin +
 		` ON vins(tx_hash, tx_index, tx_tree);`
+	// IndexVinTablePartitionedOnVins creates the unique index uix_vin of a
+	// vins table partitioned by block_height. The index must include the
+	// partition key, so UpsertVinRowPartitioned checks the uniqueness of
+	// (tx_hash, tx_index, tx_tree).
+	IndexVinTablePartitionedOnVins = `CREATE UNIQUE INDEX ` + IndexOfVinsTableOnVin +
+		` ON vins(tx_hash, tx_index, tx_tree, block_height);`
 	DeindexVinTableOnVins = `DROP INDEX ` + IndexOfVinsTableOnVin + ` CASCADE;`
 
 	IndexVinTableOnPrevOuts = `CREATE INDEX ` + IndexOfVinsTableOnPrevOut +
@@ -137,6 +213,33 @@ const (
 		WHERE vouts.spend_tx_row_id IS NULL AND vouts.value>0
 			AND transactions.is_mainchain AND transactions.is_valid;`
 
+	// utxoSetAtHeight is the basis for the queries of the UTXO set after the
+	// mainchain block at height $1: the nonzero outputs of the valid mainchain
+	// transactions at or below the height that are not spent by a transaction
+	// at or below the height.
+	utxoSetAtHeight = `FROM vouts
+		JOIN transactions ON transactions.tx_hash=vouts.tx_hash
+		LEFT JOIN transactions AS spending ON spending.id=vouts.spend_tx_row_id
+		WHERE vouts.value>0
+			AND transactions.is_mainchain AND transactions.is_valid
+			AND transactions.block_height<=$1
+			AND (vouts.spend_tx_row_id IS NULL OR spending.block_height>$1)`
+
+	// SelectUTXOSetAtHeight selects the UTXO set after the mainchain block at
+	// height $1, ordered by the height and hash of the funding transaction.
+	SelectUTXOSetAtHeight = `SELECT vouts.tx_hash, vouts.tx_index, vouts.tx_tree,
+			transactions.block_height, vouts.value, vouts.script_type,
+			vouts.script_addresses
+		` + utxoSetAtHeight + `
+		ORDER BY transactions.block_height, vouts.tx_hash, vouts.tx_index;`
+
+	// SelectUTXOSetStatsAtHeight counts and sums the UTXO set after the
+	// mainchain block at height $1 for each script type.
+	SelectUTXOSetStatsAtHeight = `SELECT vouts.script_type, COUNT(*), SUM(vouts.value)
+		` + utxoSetAtHeight + `
+		GROUP BY vouts.script_type
+		ORDER BY SUM(vouts.value) DESC;`
+
 	SetIsValidIsMainchainByTxHash = `UPDATE vins SET is_valid = $1, is_mainchain = $2
 		WHERE tx_hash = $3 AND block_time = $4;`
 	SetIsValidIsMainchainByVinID = `UPDATE vins SET is_valid = $2, is_mainchain = $3
@@ -155,16 +258,39 @@ const (
 		WHERE tx_hash = $5 AND tx_index = $6 AND tx_tree = $7;`
 
 	// SelectCoinSupply fetches the newly minted atoms per block by filtering
-	// for stakebase and stake-validated coinbase transactions.
-	SelectCoinSupply = `SELECT vins.block_time, sum(vins.value_in)
-		FROM vins JOIN transactions
-		ON vins.tx_hash = transactions.tx_hash
-		WHERE vins.prev_tx_hash = '0000000000000000000000000000000000000000000000000000000000000000'
-		AND transactions.block_height > $1
-		AND NOT (vins.is_valid = false AND vins.tx_tree = 0)
-		AND vins.is_mainchain
-		GROUP BY vins.block_time, transactions.block_height
-		ORDER BY transactions.block_height;`
+	// for stakebase and stake-validated coinbase transactions. The atoms
+	// minted by pruned blocks are taken from the pruned_supply table.
+	SelectCoinSupply = `SELECT block_time, atoms FROM (
+			SELECT height AS block_height, block_time, new_atoms AS atoms
+			FROM pruned_supply
+			WHERE height > $1
+			UNION ALL
+			SELECT transactions.block_height, vins.block_time, sum(vins.value_in)
+			FROM vins JOIN transactions
+			ON vins.tx_hash = transactions.tx_hash
+			WHERE vins.prev_tx_hash = '0000000000000000000000000000000000000000000000000000000000000000'
+			AND transactions.block_height > $1
+			AND NOT (vins.is_valid = false AND vins.tx_tree = 0)
+			AND vins.is_mainchain
+			GROUP BY vins.block_time, transactions.block_height
+		) AS supply
+		ORDER BY block_height;`
+
+	// SelectCirculatingSupply sums the atoms minted by the stakebase and
+	// stake-validated coinbase and treasurybase transactions of the main
+	// chain. TSpends, which also have a null input, are excluded by their
+	// treasury tx_type ($1) as they spend from the treasury. The genesis block
+	// coinbase is stored with a value of -1. The atoms minted by pruned blocks
+	// are taken from the pruned_supply table.
+	SelectCirculatingSupply = `SELECT COALESCE(SUM(value_in), 0)
+			+ (SELECT COALESCE(SUM(circulating), 0) FROM pruned_supply)
+		FROM vins
+		WHERE prev_tx_hash = '0000000000000000000000000000000000000000000000000000000000000000'
+		AND NOT (is_valid = false AND tx_tree = 0)
+		AND is_mainchain
+		AND value_in > 0
+		AND NOT EXISTS (SELECT 1 FROM treasury
+			WHERE treasury.tx_hash = vins.tx_hash AND treasury.tx_type = $1);`
 
 	// vouts
 
@@ -180,13 +306,14 @@ const (
 		script_type TEXT,
 		script_addresses TEXT[],
 		mixed BOOLEAN DEFAULT FALSE,
-		spend_tx_row_id INT8
+		spend_tx_row_id INT8,
+		block_height INT8
 	);`
 
 	// insertVinRow is the basis for several vout insert/upsert statements.
 	insertVoutRow = `INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
-		version, pkscript, script_req_sigs, script_type, script_addresses, mixed)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) `  // not with spend_tx_row_id
+		version, pkscript, script_req_sigs, script_type, script_addresses, mixed, block_height)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ` // not with spend_tx_row_id
 
 	// InsertVoutRow inserts a new vout row without checking for unique index
 	// conflicts. This should only be used before the unique indexes are created
@@ -218,6 +345,70 @@ const (
 		WHERE  tx_hash = $1 AND tx_index = $2 AND tx_tree = $3 -- only executed if no INSERT
 		LIMIT  1;`
 
+	// insertVoutRowPartitioned is like insertVoutRow, but inserts the row with
+	// a SELECT, like insertVinRowPartitioned.
+	insertVoutRowPartitioned = `INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+			version, pkscript, script_req_sigs, script_type, script_addresses, mixed,
+			block_height)
+		SELECT $1::TEXT, $2::INT4, $3::INT2, $4::INT8, $5::INT2, $6::BYTEA, $7::INT4,
+			$8::TEXT, $9::TEXT[], $10::BOOLEAN, $11::INT8 `
+
+	// UpsertVoutRowPartitioned is like UpsertVoutRow, but for a vouts table
+	// partitioned by block_height. As for UpsertVinRowPartitioned, the
+	// uniqueness of (tx_hash, tx_index, tx_tree) is checked here.
+	UpsertVoutRowPartitioned = `WITH updated AS (
+			UPDATE vouts SET version = $5
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			RETURNING id
+		), inserting AS (` +
+		insertVoutRowPartitioned +
+		`	WHERE NOT EXISTS (SELECT 1 FROM updated)
+			RETURNING id
+		)
+		SELECT id FROM updated
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// InsertVoutRowPartitionedOnConflictDoNothing is like
+	// InsertVoutRowOnConflictDoNothing, but for a vouts table partitioned by
+	// block_height. An existing row of the transaction at any block height is
+	// a conflict.
+	InsertVoutRowPartitionedOnConflictDoNothing = `WITH existing AS (
+			SELECT id FROM vouts
+			WHERE tx_hash = $1 AND tx_index = $2 AND tx_tree = $3
+			LIMIT 1
+		), inserting AS (` +
+		insertVoutRowPartitioned +
+		`	WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		SELECT id FROM existing
+		UNION  ALL
+		SELECT id FROM inserting
+		LIMIT  1;`
+
+	// CreateVoutsCopyTable creates the temporary table into which vouts are
+	// written with the COPY protocol before they are inserted into the vouts
+	// table. The table is dropped when the transaction is committed.
+	CreateVoutsCopyTable = `CREATE TEMP TABLE vouts_copy ON COMMIT DROP AS
+		SELECT tx_hash, tx_index, tx_tree, value, version, pkscript,
+			script_req_sigs, script_type, script_addresses, mixed, block_height
+		FROM vouts WITH NO DATA;`
+
+	// InsertVoutsFromCopy inserts the rows of the vouts_copy table without
+	// checking for unique index conflicts, returning the new row ids with the
+	// unique index columns so they may be matched to the inserted vouts.
+	InsertVoutsFromCopy = `INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+			version, pkscript, script_req_sigs, script_type, script_addresses, mixed,
+			block_height)
+		SELECT tx_hash, tx_index, tx_tree, value, version, pkscript,
+			script_req_sigs, script_type, script_addresses, mixed, block_height
+		FROM vouts_copy
+		RETURNING id, tx_hash, tx_index, tx_tree;`
+
+	TruncateVoutsCopy = `TRUNCATE vouts_copy;`
+
 	// DeleteVoutDuplicateRows removes rows that would violate the unique index
 	// uix_vout_txhash_ind. This should be run prior to creating the index.
 	DeleteVoutDuplicateRows = `DELETE FROM vouts
@@ -252,6 +443,12 @@ const (
 	// (tx_hash, tx_index, tx_tree).
 	IndexVoutTableOnTxHashIdx = `CREATE UNIQUE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnTxHashInd +
 		` ON vouts(tx_hash, tx_index, tx_tree);`
+	// IndexVoutTablePartitionedOnTxHashIdx creates the unique index
+	// uix_vout_txhash_ind of a vouts table partitioned by block_height. The
+	// uniqueness of (tx_hash, tx_index, tx_tree) is checked by
+	// UpsertVoutRowPartitioned.
+	IndexVoutTablePartitionedOnTxHashIdx = `CREATE UNIQUE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnTxHashInd +
+		` ON vouts(tx_hash, tx_index, tx_tree, block_height);`
 	DeindexVoutTableOnTxHashIdx = `DROP INDEX IF EXISTS ` + IndexOfVoutsTableOnTxHashInd + ` CASCADE;`
 
 	IndexVoutTableOnSpendTxID = `CREATE INDEX IF NOT EXISTS ` + IndexOfVoutsTableOnSpendTxID +
@@ -282,13 +479,19 @@ const (
 // constraint. For updateOnConflict=true, an upsert statement will be provided
 // that UPDATEs the conflicting row. For updateOnConflict=false, the statement
 // will either insert or do nothing, and return the inserted (new) or
-// conflicting (unmodified) row id.
-func MakeVinInsertStatement(checked, updateOnConflict bool) string {
+// conflicting (unmodified) row id. For partitioned=true, the statements are
+// for a vins table partitioned by block_height.
+func MakeVinInsertStatement(checked, updateOnConflict, partitioned bool) string {
 	if !checked {
 		return InsertVinRow
 	}
-	if updateOnConflict {
+	switch {
+	case updateOnConflict && partitioned:
+		return UpsertVinRowPartitioned
+	case updateOnConflict:
 		return UpsertVinRow
+	case partitioned:
+		return InsertVinRowPartitionedOnConflictDoNothing
 	}
 	return InsertVinRowOnConflictDoNothing
 }
@@ -301,13 +504,19 @@ func MakeVinInsertStatement(checked, updateOnConflict bool) string {
 // constraint. For updateOnConflict=true, an upsert statement will be provided
 // that UPDATEs the conflicting row. For updateOnConflict=false, the statement
 // will either insert or do nothing, and return the inserted (new) or
-// conflicting (unmodified) row id.
-func MakeVoutInsertStatement(checked, updateOnConflict bool) string {
+// conflicting (unmodified) row id. For partitioned=true, the statements are
+// for a vouts table partitioned by block_height.
+func MakeVoutInsertStatement(checked, updateOnConflict, partitioned bool) string {
 	if !checked {
 		return InsertVoutRow
 	}
-	if updateOnConflict {
+	switch {
+	case updateOnConflict && partitioned:
+		return UpsertVoutRowPartitioned
+	case updateOnConflict:
 		return UpsertVoutRow
+	case partitioned:
+		return InsertVoutRowPartitionedOnConflictDoNothing
 	}
 	return InsertVoutRowOnConflictDoNothing
 }
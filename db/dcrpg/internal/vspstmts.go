@@ -0,0 +1,35 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate to the "vsp_stats" table of the statistics polled from
+// the vspinfo API of voting service providers (VSPs).
+const (
+	// CreateVSPStatsTable creates the vsp_stats table. Each row is a snapshot
+	// of a VSP's statistics, identified by the URL of its vspd instance.
+	CreateVSPStatsTable = `CREATE TABLE IF NOT EXISTS vsp_stats (
+		id SERIAL8 PRIMARY KEY,
+		vsp TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		reachable BOOLEAN NOT NULL,
+		closed BOOLEAN NOT NULL,
+		vspd_version TEXT NOT NULL,
+		fee_percentage FLOAT8 NOT NULL,
+		live INT8 NOT NULL,
+		voted INT8 NOT NULL,
+		missed INT8 NOT NULL,
+		UNIQUE (vsp, time)
+	);`
+
+	InsertVSPStatsRow = `INSERT INTO vsp_stats (vsp, time, reachable, closed,
+		vspd_version, fee_percentage, live, voted, missed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (vsp, time) DO NOTHING;`
+
+	// SelectLatestVSPStats selects the most recent statistics of each VSP.
+	SelectLatestVSPStats = `SELECT DISTINCT ON (vsp) vsp, time, reachable,
+			closed, vspd_version, fee_percentage, live, voted, missed
+		FROM vsp_stats
+		ORDER BY vsp, time DESC;`
+)
@@ -0,0 +1,226 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5/internal"
+	"github.com/lib/pq"
+)
+
+// MaintenanceTables are the high-churn tables checked by the maintenance
+// scheduler. The spending info updates of the addresses and vouts tables, the
+// ticket pool status updates of the tickets table, and the periodic rebuilds
+// of the rich list tables leave many dead tuples that autovacuum with the
+// default settings is slow to remove.
+var MaintenanceTables = []string{"addresses", "vouts", "vins", "transactions",
+	"tickets", "votes", "rich_list", "balance_distribution"}
+
+const (
+	// DefaultMaintenanceRatio is the default fraction of a table's live tuples
+	// that may be dead, or modified since the last ANALYZE, before the table
+	// is vacuumed or analyzed.
+	DefaultMaintenanceRatio = 0.05
+
+	// minMaintenanceTuples is the minimum number of dead or modified tuples
+	// for a table to be vacuumed or analyzed, so that small tables are left to
+	// autovacuum.
+	minMaintenanceTuples = 1000
+
+	// vacuumProgressInterval is the interval between progress log messages
+	// of a running VACUUM.
+	vacuumProgressInterval = 30 * time.Second
+)
+
+// tableChurn is the tuple statistics of a table from pg_stat_user_tables.
+type tableChurn struct {
+	table    string
+	live     int64
+	dead     int64
+	modified int64
+}
+
+// needsVacuum checks if the dead tuples are at least ratio of the live tuples.
+func (c *tableChurn) needsVacuum(ratio float64) bool {
+	return c.dead >= minMaintenanceTuples && float64(c.dead) >= ratio*float64(c.live)
+}
+
+// needsAnalyze checks if the tuples modified since the last ANALYZE are at
+// least ratio of the live tuples.
+func (c *tableChurn) needsAnalyze(ratio float64) bool {
+	return c.modified >= minMaintenanceTuples && float64(c.modified) >= ratio*float64(c.live)
+}
+
+// retrieveTableChurn retrieves the tuple statistics of the tables.
+func retrieveTableChurn(ctx context.Context, db *sql.DB, tables []string) ([]*tableChurn, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTableChurn, pq.Array(tables))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var churn []*tableChurn
+	for rows.Next() {
+		var c tableChurn
+		if err = rows.Scan(&c.table, &c.live, &c.dead, &c.modified); err != nil {
+			return nil, err
+		}
+		churn = append(churn, &c)
+	}
+	return churn, rows.Err()
+}
+
+// RequestMaintenance schedules a VACUUM ANALYZE of the tables, regardless of
+// their dead tuple counts, such as after a bulk update of every row. The
+// request is dropped if RunMaintenance is not running or too many requests
+// are pending.
+func (pgb *ChainDB) RequestMaintenance(tables ...string) {
+	for _, table := range tables {
+		select {
+		case pgb.maintenanceReqs <- table:
+		default:
+			log.Debugf("Dropped maintenance request for table %s.", table)
+		}
+	}
+}
+
+// RunMaintenance checks the MaintenanceTables at the given interval, running
+// VACUUM ANALYZE on those with dead tuples of at least ratio of their live
+// tuples, and ANALYZE on those with modified tuples of at least ratio of their
+// live tuples. Requests from RequestMaintenance are handled as they arrive.
+// RunMaintenance returns when the context is cancelled.
+func (pgb *ChainDB) RunMaintenance(ctx context.Context, interval time.Duration, ratio float64) {
+	if pgb.cockroach {
+		log.Infof("Table maintenance is not supported with CockroachDB.")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pgb.InBatchSync {
+				continue
+			}
+			if err := pgb.maintainTables(ctx, MaintenanceTables, ratio); err != nil {
+				log.Errorf("Table maintenance failed: %v", err)
+			}
+		case table := <-pgb.maintenanceReqs:
+			// Collect the other pending requests.
+			tables := []string{table}
+		pending:
+			for {
+				select {
+				case table = <-pgb.maintenanceReqs:
+					tables = appendUnique(tables, table)
+				default:
+					break pending
+				}
+			}
+			for _, table := range tables {
+				if err := pgb.vacuumTable(ctx, table); err != nil {
+					log.Errorf("Failed to VACUUM table %s: %v", table, err)
+				}
+			}
+		}
+	}
+}
+
+// maintainTables runs VACUUM ANALYZE or ANALYZE on the tables whose dead or
+// modified tuples are at least ratio of their live tuples.
+func (pgb *ChainDB) maintainTables(ctx context.Context, tables []string, ratio float64) error {
+	churn, err := retrieveTableChurn(ctx, pgb.db, tables)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve table statistics: %v", err)
+	}
+
+	for _, c := range churn {
+		if ctx.Err() != nil {
+			return nil
+		}
+		switch {
+		case c.needsVacuum(ratio):
+			log.Infof("Table %s has %d dead of %d live tuples.", c.table, c.dead, c.live)
+			err = pgb.vacuumTable(ctx, c.table)
+		case c.needsAnalyze(ratio):
+			log.Infof("Performing an ANALYZE(%d) on %s table with %d of %d tuples modified...",
+				quickStatsTarget, c.table, c.modified, c.live)
+			err = AnalyzeTable(pgb.db, c.table, quickStatsTarget)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Errorf("Maintenance of table %s failed: %v", c.table, err)
+		}
+	}
+	return nil
+}
+
+// vacuumTable runs VACUUM ANALYZE on the table, logging the progress of the
+// VACUUM until it completes.
+func (pgb *ChainDB) vacuumTable(ctx context.Context, table string) error {
+	log.Infof("Performing a VACUUM ANALYZE on %s table...", table)
+	start := time.Now()
+
+	done := make(chan struct{})
+	defer close(done)
+	go pgb.logVacuumProgress(ctx, table, done)
+
+	_, err := pgb.db.ExecContext(ctx, fmt.Sprintf(`VACUUM (ANALYZE) %s;`,
+		pq.QuoteIdentifier(table)))
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	log.Infof("VACUUM ANALYZE of %s table completed in %v.", table,
+		time.Since(start).Round(time.Second))
+	return nil
+}
+
+// logVacuumProgress logs the phase and scanned heap blocks of the running
+// VACUUM of the table every vacuumProgressInterval until done is closed.
+func (pgb *ChainDB) logVacuumProgress(ctx context.Context, table string, done <-chan struct{}) {
+	ticker := time.NewTicker(vacuumProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		var phase string
+		var scanned, total int64
+		err := pgb.db.QueryRowContext(ctx, internal.SelectVacuumProgress, table).
+			Scan(&phase, &scanned, &total)
+		if err != nil {
+			// The VACUUM may have just completed.
+			continue
+		}
+		var pct float64
+		if total > 0 {
+			pct = 100 * float64(scanned) / float64(total)
+		}
+		log.Infof("VACUUM of %s table: %s, %.1f%% of %d heap blocks scanned.",
+			table, phase, pct, total)
+	}
+}
+
+// appendUnique appends s to the slice if it is not already present.
+func appendUnique(sl []string, s string) []string {
+	for i := range sl {
+		if sl[i] == s {
+			return sl
+		}
+	}
+	return append(sl, s)
+}
@@ -0,0 +1,60 @@
+package dcrpg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableChurn(t *testing.T) {
+	tests := []struct {
+		name                    string
+		churn                   tableChurn
+		ratio                   float64
+		wantVacuum, wantAnalyze bool
+	}{
+		{"quiet", tableChurn{"vouts", 1e6, 10, 10}, 0.05, false, false},
+		{"dead tuples", tableChurn{"vouts", 1e6, 5e4, 0}, 0.05, true, false},
+		{"modified tuples", tableChurn{"vouts", 1e6, 0, 5e4}, 0.05, false, true},
+		{"both", tableChurn{"vouts", 1e6, 6e4, 1e5}, 0.05, true, true},
+		{"below ratio", tableChurn{"vouts", 1e6, 4e4, 4e4}, 0.05, false, false},
+		{"higher ratio", tableChurn{"vouts", 1e6, 5e4, 5e4}, 0.1, false, false},
+		{"small table", tableChurn{"rich_list", 100, 999, 999}, 0.05, false, false},
+		{"minimum tuples", tableChurn{"rich_list", 100, 1000, 1000}, 0.05, true, true},
+		{"empty table", tableChurn{"rich_list", 0, 1000, 0}, 0.05, true, false},
+	}
+
+	for _, test := range tests {
+		if got := test.churn.needsVacuum(test.ratio); got != test.wantVacuum {
+			t.Errorf("%s: expected needsVacuum %v, got %v", test.name, test.wantVacuum, got)
+		}
+		if got := test.churn.needsAnalyze(test.ratio); got != test.wantAnalyze {
+			t.Errorf("%s: expected needsAnalyze %v, got %v", test.name, test.wantAnalyze, got)
+		}
+	}
+}
+
+func TestRequestMaintenance(t *testing.T) {
+	pgb := &ChainDB{maintenanceReqs: make(chan string, 2)}
+
+	// Requests beyond the capacity of the channel are dropped.
+	pgb.RequestMaintenance("addresses", "vouts", "tickets")
+	if len(pgb.maintenanceReqs) != 2 {
+		t.Fatalf("expected 2 pending requests, got %d", len(pgb.maintenanceReqs))
+	}
+	for _, want := range []string{"addresses", "vouts"} {
+		if got := <-pgb.maintenanceReqs; got != want {
+			t.Errorf("expected request for table %s, got %s", want, got)
+		}
+	}
+}
+
+func TestAppendUnique(t *testing.T) {
+	var tables []string
+	for _, table := range []string{"vouts", "addresses", "vouts", "tickets", "addresses"} {
+		tables = appendUnique(tables, table)
+	}
+	want := []string{"vouts", "addresses", "tickets"}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("expected %v, got %v", want, tables)
+	}
+}
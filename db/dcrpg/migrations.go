@@ -0,0 +1,585 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5/internal"
+	"github.com/lib/pq"
+)
+
+// Migration is a versioned upgrade of the database. The SQL statements, if
+// any, are executed first, followed by the Run function, if any. A migration
+// may be reverted if it has Down statements to undo its schema changes, or
+// Backup tables whose rows it modifies, or both.
+type Migration struct {
+	// Version is the database version after the migration is applied.
+	Version DatabaseVersion
+	// Description is logged and recorded in the schema_migrations table.
+	Description string
+	// SQL is executed in the same DB transaction as the version update, unless
+	// the migration also has a Run function.
+	SQL string
+	// Run performs the parts of the migration that cannot be expressed in SQL.
+	Run func(u *Upgrader) error
+	// Down reverts the schema changes of the migration.
+	Down string
+	// Backup lists the tables whose rows are modified by the migration. When
+	// backups are enabled, they are copied before the migration is applied,
+	// and restored when it is reverted.
+	Backup []string
+}
+
+// Reversible indicates if the migration can be reverted.
+func (mg *Migration) Reversible() bool {
+	return mg.Down != "" || len(mg.Backup) > 0
+}
+
+// String implements Stringer for Migration.
+func (mg Migration) String() string {
+	return fmt.Sprintf("%v (%s)", mg.Version, mg.Description)
+}
+
+// migrations are the upgrades of the compatVersion 1 database, in order. The
+// last migration must be to targetDatabaseVersion.
+var migrations = []Migration{
+	{
+		Version:     NewDatabaseVersion(1, 1, 0),
+		Description: "remove the legacy table version comments",
+		Run:         func(u *Upgrader) error { removeTableComments(u.db); return nil },
+	},
+	{
+		Version:     NewDatabaseVersion(1, 2, 0),
+		Description: "create the stats table and blocks.winners column",
+		Run:         (*Upgrader).upgrade110to120,
+	},
+	{
+		Version:     NewDatabaseVersion(1, 3, 0),
+		Description: "index the blocks table on time",
+		Run:         (*Upgrader).upgrade120to130,
+		Down:        internal.DeindexBlocksTableOnTime,
+	},
+	{
+		Version:     NewDatabaseVersion(1, 4, 0),
+		Description: "change votes.version from INT2 to INT4",
+		SQL:         `ALTER TABLE votes ALTER COLUMN version TYPE INT4;`,
+		Down:        `ALTER TABLE votes ALTER COLUMN version TYPE INT2;`,
+	},
+	{
+		Version:     NewDatabaseVersion(1, 5, 0),
+		Description: "add the transactions.mix_count and mix_denom columns",
+		SQL: `ALTER TABLE transactions
+			ADD COLUMN IF NOT EXISTS mix_count INT4 DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS mix_denom INT8 DEFAULT 0;`,
+		Down: makeDeleteColumnsStmt("transactions", []string{"mix_count", "mix_denom"}),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 5, 1),
+		Description: "set the mix data of the mixing and split transactions",
+		Run:         (*Upgrader).setTxMixData,
+		Backup:      []string{"transactions"},
+	},
+	{
+		Version:     NewDatabaseVersion(1, 6, 0),
+		Description: "add and set the vouts.mixed and spend_tx_row_id columns",
+		Run:         (*Upgrader).upgrade151to160,
+		Down:        makeDeleteColumnsStmt("vouts", []string{"mixed", "spend_tx_row_id"}),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 7, 0),
+		Description: "index the vouts table on spend_tx_row_id",
+		SQL:         internal.IndexVoutTableOnSpendTxID,
+		Down:        internal.DeindexVoutTableOnSpendTxID,
+	},
+	{
+		// This drastically accelerates several queries including those for
+		// the fees, coin supply, privacy participation, and anonymity set
+		// charts.
+		Version:     NewDatabaseVersion(1, 8, 0),
+		Description: "index the transactions table on block_height",
+		SQL:         internal.IndexTransactionTableOnBlockHeight,
+		Down:        internal.DeindexTransactionTableOnBlockHeight,
+	},
+	{
+		// The balances are computed for each address when first requested.
+		Version:     NewDatabaseVersion(1, 9, 0),
+		Description: "add the addresses.balance column for running balances",
+		SQL:         internal.AddAddressesBalanceColumn,
+		Down:        makeDeleteColumnsStmt("addresses", []string{"balance"}),
+	},
+	{
+		// This may take a while on mainnet.
+		Version:     NewDatabaseVersion(1, 10, 0),
+		Description: "index the addresses table on address prefix",
+		SQL:         internal.IndexAddressTableOnPrefix,
+		Down:        internal.DeindexAddressTableOnPrefix,
+	},
+	{
+		// The block_height columns are the partition keys of the vins and
+		// vouts tables when they are partitioned. Setting them may take a
+		// while on mainnet.
+		Version:     NewDatabaseVersion(1, 11, 0),
+		Description: "add and set the vins.block_height and vouts.block_height columns",
+		SQL: `ALTER TABLE vins ADD COLUMN IF NOT EXISTS block_height INT8;
+			ALTER TABLE vouts ADD COLUMN IF NOT EXISTS block_height INT8;`,
+		Run: (*Upgrader).setVinsVoutsBlockHeight,
+		Down: makeDeleteColumnsStmt("vins", []string{"block_height"}) +
+			makeDeleteColumnsStmt("vouts", []string{"block_height"}),
+	},
+	{
+		// The votes retrieved from the Politeia API have no git commit.
+		Version:     NewDatabaseVersion(1, 12, 0),
+		Description: "allow proposals rows without a commit_sha",
+		SQL:         `ALTER TABLE proposals ALTER COLUMN commit_sha DROP NOT NULL;`,
+		Down: `UPDATE proposals SET commit_sha = '' WHERE commit_sha IS NULL;
+			ALTER TABLE proposals ALTER COLUMN commit_sha SET NOT NULL;`,
+	},
+	{
+		// Each of the following migrations creates the tables of a feature.
+		// The tables are only created if they do not exist, since earlier
+		// versions of dcrdata created them without a version bump.
+		Version:     NewDatabaseVersion(1, 13, 0),
+		Description: "create the sync_state table",
+		SQL:         internal.CreateSyncStateTable,
+		Down:        makeDropTablesStmt("sync_state"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 14, 0),
+		Description: "create the treasury and treasury_votes tables",
+		SQL: internal.CreateTreasuryTable +
+			internal.CreateTreasuryVotesTable,
+		Down: makeDropTablesStmt("treasury_votes", "treasury"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 15, 0),
+		Description: "create the address_spend_info table",
+		SQL:         internal.CreateAddressSpendInfoTable,
+		Down:        makeDropTablesStmt("address_spend_info"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 16, 0),
+		Description: "create the reorgs table",
+		SQL:         internal.CreateReorgsTable,
+		Down:        makeDropTablesStmt("reorgs"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 17, 0),
+		Description: "create the rich_list and balance_distribution tables",
+		SQL: internal.CreateRichListTable +
+			internal.CreateBalanceDistributionTable,
+		Down: makeDropTablesStmt("balance_distribution", "rich_list"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 18, 0),
+		Description: "create the vsp_stats table",
+		SQL:         internal.CreateVSPStatsTable,
+		Down:        makeDropTablesStmt("vsp_stats"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 19, 0),
+		Description: "create the api_keys table",
+		SQL:         internal.CreateAPIKeysTable,
+		Down:        makeDropTablesStmt("api_keys"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 20, 0),
+		Description: "create the side_chain_blocks table",
+		SQL:         internal.CreateSideChainBlocksTable,
+		Down:        makeDropTablesStmt("side_chain_blocks"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 21, 0),
+		Description: "create the agenda_vote_intervals table",
+		SQL:         internal.CreateAgendaVoteIntervalsTable,
+		Down:        makeDropTablesStmt("agenda_vote_intervals"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 22, 0),
+		Description: "create the address_watches table",
+		SQL:         internal.CreateAddressWatchesTable,
+		Down:        makeDropTablesStmt("address_watches"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 23, 0),
+		Description: "create the coin_age_blocks, coin_age_deltas and coin_age_bands tables",
+		SQL: internal.CreateCoinAgeBlocksTable +
+			internal.CreateCoinAgeDeltasTable +
+			internal.CreateCoinAgeBandsTable,
+		Down: makeDropTablesStmt("coin_age_bands", "coin_age_deltas", "coin_age_blocks"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 24, 0),
+		Description: "create the proposal_titles table",
+		SQL:         internal.CreateProposalTitlesTable,
+		Down:        makeDropTablesStmt("proposal_titles"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 25, 0),
+		Description: "create the prune_state and pruned_supply tables",
+		SQL: internal.CreatePruneStateTable +
+			internal.CreatePrunedSupplyTable,
+		Down: makeDropTablesStmt("pruned_supply", "prune_state"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 26, 0),
+		Description: "create the block_propagation table",
+		SQL:         internal.CreateBlockPropagationTable,
+		Down:        makeDropTablesStmt("block_propagation"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 27, 0),
+		Description: "create the address_clusters, cluster_addresses and address_cluster_state tables",
+		SQL: internal.CreateAddressClustersTable +
+			internal.CreateClusterAddressesTable +
+			internal.CreateAddressClusterStateTable,
+		Down: makeDropTablesStmt("address_cluster_state", "cluster_addresses", "address_clusters"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 28, 0),
+		Description: "create the mempool_history table",
+		SQL:         internal.CreateMempoolHistoryTable,
+		Down:        makeDropTablesStmt("mempool_history"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 29, 0),
+		Description: "create the address_tags table",
+		SQL:         internal.CreateAddressTagsTable,
+		Down:        makeDropTablesStmt("address_tags"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 30, 0),
+		Description: "create the swaps table",
+		SQL:         internal.CreateSwapsTable,
+		Down:        makeDropTablesStmt("swaps"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 31, 0),
+		Description: "create the faucet_grants table",
+		SQL:         internal.CreateFaucetGrantsTable,
+		Down:        makeDropTablesStmt("faucet_grants"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 32, 0),
+		Description: "create the proposal_vote_snapshots table",
+		SQL:         internal.CreateProposalVoteSnapshotsTable,
+		Down:        makeDropTablesStmt("proposal_vote_snapshots"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 33, 0),
+		Description: "create the politeia_proposals table",
+		SQL:         internal.CreatePoliteiaProposalsTable,
+		Down:        makeDropTablesStmt("politeia_proposals"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 34, 0),
+		Description: "create the dcr_prices table",
+		SQL:         internal.CreateDCRPricesTable,
+		Down:        makeDropTablesStmt("dcr_prices"),
+	},
+	{
+		Version:     NewDatabaseVersion(1, 35, 0),
+		Description: "create the market_candles table",
+		SQL:         internal.CreateMarketCandlesTable,
+		Down:        makeDropTablesStmt("market_candles"),
+	},
+	{
+		// The outputs of the blocks already stored are classified by Run.
+		Version:     NewDatabaseVersion(1, 36, 0),
+		Description: "create the script_type_blocks and nonstandard_outputs tables",
+		SQL: internal.CreateScriptTypeBlocksTable +
+			internal.CreateNonstandardOutputsTable,
+		Run:  (*Upgrader).backfillScriptTypes,
+		Down: makeDropTablesStmt("nonstandard_outputs", "script_type_blocks"),
+	},
+}
+
+// makeDropTablesStmt makes the statement that drops the tables, if they exist.
+func makeDropTablesStmt(tables ...string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", strings.Join(tables, ", "))
+}
+
+// migrationIndex returns the index in migrations of the migration to the
+// version, or -1 if there is none.
+func migrationIndex(ver DatabaseVersion) int {
+	for i := range migrations {
+		if migrations[i].Version == ver {
+			return i
+		}
+	}
+	return -1
+}
+
+// pendingMigrations returns the migrations required to upgrade the database
+// from the current version to the target version.
+func pendingMigrations(current, target DatabaseVersion) ([]Migration, error) {
+	var pending []Migration
+	for _, mg := range migrations {
+		if mg.Version.compat != current.compat {
+			continue
+		}
+		switch current.NeededToReach(&mg.Version) {
+		case Upgrade, Maintenance:
+			pending = append(pending, mg)
+		}
+		if mg.Version == target {
+			return pending, nil
+		}
+	}
+	return nil, fmt.Errorf("no migrations to version %v", target)
+}
+
+// PendingMigrations returns the migrations required to upgrade the database to
+// the version supported by this version of dcrdata.
+func (u *Upgrader) PendingMigrations() ([]Migration, error) {
+	current, err := DBVersion(u.db)
+	if err != nil {
+		return nil, err
+	}
+	return pendingMigrations(current, *targetDatabaseVersion)
+}
+
+// migrate applies the migrations from the current to the target version. In
+// dry run mode, the pending migrations are only logged and false is returned.
+func (u *Upgrader) migrate(current, target DatabaseVersion) (bool, error) {
+	pending, err := pendingMigrations(current, target)
+	if err != nil {
+		return false, err
+	}
+
+	if u.dryRun {
+		log.Infof("Dry run: %d migrations from %v to %v.", len(pending), current, target)
+		for i := range pending {
+			u.logMigration(current, &pending[i])
+			current = pending[i].Version
+		}
+		return false, nil
+	}
+
+	if err = createTable(u.db, "schema_migrations", internal.CreateSchemaMigrationsTable); err != nil {
+		return false, fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	for i := range pending {
+		mg := &pending[i]
+		if err = u.applyMigration(current, mg); err != nil {
+			return false, fmt.Errorf("failed to upgrade %v to %v: %v", current, mg.Version, err)
+		}
+		current = mg.Version
+	}
+
+	return true, nil
+}
+
+// logMigration logs what applying the migration involves.
+func (u *Upgrader) logMigration(from DatabaseVersion, mg *Migration) {
+	log.Infof("Migration %v -> %v: %s", from, mg.Version, mg.Description)
+	if mg.SQL != "" {
+		log.Infof("    SQL: %s", mg.SQL)
+	}
+	if mg.Run != nil {
+		log.Infof("    Performs data upgrades that are not expressed in SQL.")
+	}
+	if len(mg.Backup) > 0 {
+		if u.backups {
+			log.Infof("    Backs up tables: %s", strings.Join(mg.Backup, ", "))
+		} else {
+			log.Infof("    Modifies tables, without backups: %s", strings.Join(mg.Backup, ", "))
+		}
+	}
+	if !mg.Reversible() {
+		log.Infof("    Cannot be reverted.")
+	}
+}
+
+// backupTableName is the name of the copy of the table made before the
+// migration to the version.
+func backupTableName(table string, ver DatabaseVersion) string {
+	return fmt.Sprintf("%s_backup_%d_%d_%d", table, ver.compat, ver.schema, ver.maint)
+}
+
+// applyMigration backs up the tables modified by the migration, applies it,
+// updates the database version, and records it in the schema_migrations table.
+func (u *Upgrader) applyMigration(from DatabaseVersion, mg *Migration) error {
+	log.Infof("Performing database upgrade %v -> %v: %s", from, mg.Version, mg.Description)
+	start := time.Now()
+
+	var backups []string
+	if u.backups {
+		for _, table := range mg.Backup {
+			backup := backupTableName(table, mg.Version)
+			log.Infof("Backing up the %s table to %s...", table, backup)
+			_, err := u.db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s;
+				CREATE TABLE %s AS TABLE %s;`, backup, backup, table))
+			if err != nil {
+				return fmt.Errorf("failed to back up table %s: %v", table, err)
+			}
+			backups = append(backups, backup)
+		}
+	}
+
+	if mg.Run != nil {
+		// The SQL statements precede Run, outside of the version update's
+		// transaction.
+		if mg.SQL != "" {
+			if _, err := u.db.Exec(mg.SQL); err != nil {
+				return err
+			}
+		}
+		if err := mg.Run(u); err != nil {
+			return err
+		}
+	}
+
+	dbTx, err := u.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin DB transaction: %v", err)
+	}
+	if mg.Run == nil && mg.SQL != "" {
+		if _, err = dbTx.Exec(mg.SQL); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	if err = updateDBVersion(dbTx, mg.Version); err != nil {
+		_ = dbTx.Rollback()
+		return fmt.Errorf("failed to update DB version: %v", err)
+	}
+	_, err = dbTx.Exec(internal.InsertSchemaMigration, mg.Version.String(),
+		mg.Description, start, int64(time.Since(start)/time.Millisecond), pq.Array(backups))
+	if err != nil {
+		_ = dbTx.Rollback()
+		return fmt.Errorf("failed to record migration: %v", err)
+	}
+	return dbTx.Commit()
+}
+
+// RevertMigration reverts the migration to the current database version,
+// returning the version of the database before the migration. The schema
+// changes are undone, and the tables backed up before the migration are
+// restored and the backups dropped. In dry run mode, the steps are only
+// logged.
+func (u *Upgrader) RevertMigration() (*DatabaseVersion, error) {
+	current, err := DBVersion(u.db)
+	if err != nil {
+		return nil, err
+	}
+	idx := migrationIndex(current)
+	if idx == -1 {
+		return nil, fmt.Errorf("no migration to version %v", current)
+	}
+	mg := &migrations[idx]
+	if !mg.Reversible() {
+		return nil, fmt.Errorf("migration %v cannot be reverted", mg)
+	}
+	prev := *legacyDatabaseVersion
+	if idx > 0 {
+		prev = migrations[idx-1].Version
+	}
+
+	// Find the application of the migration, to be marked as reverted, and
+	// the backups made then. A database created at a later version has no
+	// record of the migration.
+	var id int64
+	var backups []string
+	err = u.db.QueryRow(internal.SelectAppliedSchemaMigration, current.String()).
+		Scan(&id, pq.Array(&backups))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if len(backups) != len(mg.Backup) {
+		return nil, fmt.Errorf("migration %v cannot be reverted without "+
+			"backups of tables %s", mg, strings.Join(mg.Backup, ", "))
+	}
+
+	log.Infof("Reverting database upgrade %v -> %v: %s", prev, current, mg.Description)
+	if u.dryRun {
+		if mg.Down != "" {
+			log.Infof("    SQL: %s", mg.Down)
+		}
+		for i, backup := range backups {
+			log.Infof("    Restores table %s from %s.", mg.Backup[i], backup)
+		}
+		return &prev, nil
+	}
+
+	dbTx, err := u.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin DB transaction: %v", err)
+	}
+	makeErr := func(s string, args ...interface{}) (*DatabaseVersion, error) {
+		_ = dbTx.Rollback()
+		return nil, fmt.Errorf(s, args...)
+	}
+
+	if mg.Down != "" {
+		if _, err = dbTx.Exec(mg.Down); err != nil {
+			return makeErr("failed to revert schema changes: %v", err)
+		}
+	}
+	for i, backup := range backups {
+		table := mg.Backup[i]
+		log.Infof("Restoring the %s table from %s...", table, backup)
+		_, err = dbTx.Exec(fmt.Sprintf(`TRUNCATE %s;
+			INSERT INTO %s SELECT * FROM %s;
+			DROP TABLE %s;`, table, table, backup, backup))
+		if err != nil {
+			return makeErr("failed to restore table %s: %v", table, err)
+		}
+	}
+	if err = updateDBVersion(dbTx, prev); err != nil {
+		return makeErr("failed to update DB version: %v", err)
+	}
+	if id != 0 {
+		if _, err = dbTx.Exec(internal.SetSchemaMigrationReverted, id, time.Now()); err != nil {
+			return makeErr("failed to record migration reversion: %v", err)
+		}
+	}
+	if err = dbTx.Commit(); err != nil {
+		return nil, err
+	}
+	return &prev, nil
+}
+
+// MigrationRecord is an entry in the schema_migrations table.
+type MigrationRecord struct {
+	Version      string
+	Description  string
+	AppliedAt    time.Time
+	Duration     time.Duration
+	BackupTables []string
+	RevertedAt   *time.Time
+}
+
+// MigrationHistory retrieves the migrations applied and reverted, oldest first.
+// The migrations applied before the schema_migrations table was introduced are
+// not included.
+func MigrationHistory(db *sql.DB) ([]MigrationRecord, error) {
+	rows, err := db.Query(internal.SelectSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []MigrationRecord
+	for rows.Next() {
+		var rec MigrationRecord
+		var durationMs int64
+		var revertedAt pq.NullTime
+		err = rows.Scan(&rec.Version, &rec.Description, &rec.AppliedAt,
+			&durationMs, pq.Array(&rec.BackupTables), &revertedAt)
+		if err != nil {
+			return nil, err
+		}
+		rec.Duration = time.Duration(durationMs) * time.Millisecond
+		if revertedAt.Valid {
+			rec.RevertedAt = &revertedAt.Time
+		}
+		history = append(history, rec)
+	}
+	return history, rows.Err()
+}
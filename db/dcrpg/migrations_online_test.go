@@ -0,0 +1,87 @@
+// +build pgonline
+
+package dcrpg
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRevertMigration(t *testing.T) {
+	u := NewUpgrader(context.Background(), sqlDb, nil, nil)
+
+	// Revert the last migrations, which only have Down statements, in turn.
+	// The first reversion of each migration may not be recorded, since the
+	// test database may have been created at the target version, so it is
+	// applied again and reverted a second time.
+	tests := []struct {
+		version DatabaseVersion
+		table   string // created by the migration
+	}{
+		{migrations[len(migrations)-1].Version, "script_type_blocks"},
+		{migrations[len(migrations)-2].Version, "market_candles"},
+	}
+
+	for _, test := range tests {
+		idx := migrationIndex(test.version)
+		mg, prev := &migrations[idx], migrations[idx-1].Version
+		if len(mg.Backup) > 0 || mg.Down == "" {
+			t.Fatalf("migration %v is not a Down-only migration", mg)
+		}
+
+		reverted, err := u.RevertMigration()
+		if err != nil {
+			t.Fatalf("failed to revert migration %v: %v", mg, err)
+		}
+		if *reverted != prev {
+			t.Fatalf("reverted to %v, expected %v", reverted, prev)
+		}
+		if _, err = u.migrate(prev, test.version); err != nil {
+			t.Fatalf("failed to apply migration %v: %v", mg, err)
+		}
+		if _, err = u.RevertMigration(); err != nil {
+			t.Fatalf("failed to revert migration %v again: %v", mg, err)
+		}
+
+		ver, err := DBVersion(sqlDb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ver != prev {
+			t.Errorf("DB version %v after reverting %v, expected %v", ver, mg, prev)
+		}
+		exists, err := TableExists(sqlDb, test.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Errorf("table %s exists after reverting %v", test.table, mg)
+		}
+
+		history, err := MigrationHistory(sqlDb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var last *MigrationRecord
+		for i := range history {
+			if history[i].Version == test.version.String() {
+				last = &history[i]
+			}
+		}
+		if last == nil {
+			t.Fatalf("migration %v is not in the history", mg)
+		}
+		if last.RevertedAt == nil {
+			t.Errorf("migration %v is not recorded as reverted", mg)
+		}
+	}
+
+	// Restore the database to the target version.
+	current, err := DBVersion(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = u.migrate(current, *targetDatabaseVersion); err != nil {
+		t.Fatalf("failed to reapply the migrations: %v", err)
+	}
+}
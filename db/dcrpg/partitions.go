@@ -0,0 +1,216 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5/internal"
+)
+
+// PartitionBlocks is the range of block heights of each partition of the vins
+// and vouts tables, about a year of mainnet blocks.
+const PartitionBlocks = 100000
+
+// partitionedTables are the tables that may be partitioned by block_height,
+// with the statements that create them partitioned, and their columns.
+var partitionedTables = [][3]string{
+	{"vins", internal.CreateVinTablePartitioned, internal.VinsColumns},
+	{"vouts", internal.CreateVoutTablePartitioned, internal.VoutsColumns},
+}
+
+// TableIsPartitioned checks if the specified table is partitioned.
+func TableIsPartitioned(db *sql.DB, tableName string) (partitioned bool, err error) {
+	err = db.QueryRow(internal.SelectTableIsPartitioned, tableName).Scan(&partitioned)
+	return
+}
+
+// createPartitionedTables creates the vins and vouts tables partitioned by
+// block_height. The partitions are created as blocks are stored.
+func createPartitionedTables(db *sql.DB) error {
+	for _, pair := range partitionedTables {
+		if err := createTable(db, pair[0], pair[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionsEnd returns the end of the block height range covered by the
+// partitions of the table, which are named like <table>_p<first height>, or 0
+// if there are none.
+func partitionsEnd(db *sql.DB, table string) (int64, error) {
+	rows, err := db.Query(internal.SelectPartitionNames, table)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var end int64
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return 0, err
+		}
+		start, err := strconv.ParseInt(strings.TrimPrefix(name, table+"_p"), 10, 64)
+		if err != nil {
+			log.Warnf("Unrecognized partition %s of the %s table.", name, table)
+			continue
+		}
+		if start+PartitionBlocks > end {
+			end = start + PartitionBlocks
+		}
+	}
+	return end, rows.Err()
+}
+
+// createPartitions creates the partitions of the table covering the block
+// heights in [start, end), rounded out to whole partitions.
+func createPartitions(db SqlExecutor, table string, start, end int64) error {
+	for from := start - start%PartitionBlocks; from < end; from += PartitionBlocks {
+		_, err := db.Exec(fmt.Sprintf(internal.CreatePartition, table, from, from+PartitionBlocks))
+		if err != nil {
+			return fmt.Errorf("failed to create partition %s_p%d: %v", table, from, err)
+		}
+	}
+	return nil
+}
+
+// partitionTable converts the unpartitioned table into a table partitioned by
+// block_height, creating the partitions for all of its rows. The row ids are
+// preserved. The indexes of the table are not recreated. The table is not
+// converted if any of its rows has no block_height, since those rows would be
+// lost.
+func partitionTable(db *sql.DB, table, createStmt, columns string) error {
+	var nullHeights int64
+	err := db.QueryRow(fmt.Sprintf(internal.CountNullBlockHeights, table)).Scan(&nullHeights)
+	if err != nil {
+		return fmt.Errorf("failed to count the %s rows without a block height: %v", table, err)
+	}
+	if nullHeights > 0 {
+		return fmt.Errorf("%d rows of the %s table have no block_height since their "+
+			"transactions are not in the transactions table, and would be lost", nullHeights, table)
+	}
+
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin database transaction: %v", err)
+	}
+
+	exec := func(stmt, what string) error {
+		if _, err := dbTx.Exec(stmt); err != nil {
+			_ = dbTx.Rollback()
+			return fmt.Errorf("failed to %s: %v", what, err)
+		}
+		return nil
+	}
+
+	if err = exec(fmt.Sprintf(internal.RenameUnpartitionedTable, table),
+		"rename the "+table+" table"); err != nil {
+		return err
+	}
+	if err = exec(createStmt, "create the partitioned "+table+" table"); err != nil {
+		return err
+	}
+
+	var maxHeight sql.NullInt64
+	err = dbTx.QueryRow(fmt.Sprintf(`SELECT MAX(block_height) FROM %s_unpartitioned;`,
+		table)).Scan(&maxHeight)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	if maxHeight.Valid {
+		if err = createPartitions(dbTx, table, 0, maxHeight.Int64+1); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	log.Infof("Moving the rows of the %s table into its partitions. This may take a while...", table)
+	if err = exec(fmt.Sprintf(internal.CopyUnpartitionedRows, table, columns),
+		"copy the "+table+" rows"); err != nil {
+		return err
+	}
+	if err = exec(fmt.Sprintf(internal.SetPartitionedSequence, table),
+		"set the "+table+" id sequence"); err != nil {
+		return err
+	}
+	if err = exec(fmt.Sprintf(internal.DropUnpartitionedTable, table),
+		"drop the unpartitioned "+table+" table"); err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// partitionVinsVouts converts the unpartitioned vins and vouts tables into
+// tables partitioned by block_height, and recreates their indexes.
+func partitionVinsVouts(db *sql.DB) error {
+	for _, pair := range partitionedTables {
+		partitioned, err := TableIsPartitioned(db, pair[0])
+		if err != nil {
+			return err
+		}
+		if partitioned {
+			continue
+		}
+		log.Infof("Partitioning the %s table by block height...", pair[0])
+		if err = partitionTable(db, pair[0], pair[1], pair[2]); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Indexing the partitioned vins and vouts tables...")
+	for _, idx := range []struct {
+		name  string
+		index func(db *sql.DB) error
+	}{
+		{internal.IndexOfVinsTableOnVin, IndexVinTableOnVins},
+		{internal.IndexOfVinsTableOnPrevOut, IndexVinTableOnPrevOuts},
+		{internal.IndexOfVoutsTableOnTxHashInd, IndexVoutTableOnTxHashIdx},
+		{internal.IndexOfVoutsTableOnSpendTxID, IndexVoutTableOnSpendTxID},
+	} {
+		exists, err := ExistsIndex(db, idx.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err = idx.index(db); err != nil {
+			return fmt.Errorf("failed to create index %s: %v", idx.name, err)
+		}
+	}
+	return nil
+}
+
+// ensurePartitions creates the partitions of the vins and vouts tables for the
+// block height if they do not already exist. This is a no-op unless the tables
+// are partitioned.
+func (pgb *ChainDB) ensurePartitions(height int64) error {
+	if !pgb.partitioned {
+		return nil
+	}
+
+	pgb.partitionMtx.Lock()
+	defer pgb.partitionMtx.Unlock()
+	if height < pgb.partitionEnd {
+		return nil
+	}
+
+	end := height - height%PartitionBlocks + PartitionBlocks
+	for _, pair := range partitionedTables {
+		if err := createPartitions(pgb.db, pair[0], pgb.partitionEnd, end); err != nil {
+			return err
+		}
+	}
+	log.Infof("Created the vins and vouts partitions for blocks %d to %d.",
+		pgb.partitionEnd, end-1)
+	pgb.partitionEnd = end
+	return nil
+}
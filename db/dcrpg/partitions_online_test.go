@@ -0,0 +1,276 @@
+// +build pgonline
+
+package dcrpg
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v5/internal"
+	"github.com/decred/dcrdata/testutil/dbconfig/v2"
+	"github.com/lib/pq"
+)
+
+const partitionTestSchema = "partition_test"
+
+// connectTestSchema opens a connection to the test database that uses a
+// scratch schema, in which tables such as vins and vouts may be created and
+// modified without affecting the tables of the other tests.
+func connectTestSchema(t *testing.T, schema string) *sql.DB {
+	_, err := sqlDb.Exec(fmt.Sprintf(`DROP SCHEMA IF EXISTS %[1]s CASCADE;
+		CREATE SCHEMA %[1]s;`, schema))
+	if err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+
+	dsn := fmt.Sprintf("host=%s user=%s dbname=%s sslmode=disable search_path=%s",
+		dbconfig.PGTestsHost, dbconfig.PGTestsUser, dbconfig.PGTestsDBName, schema)
+	if dbconfig.PGTestsPass != "" {
+		dsn += " password=" + dbconfig.PGTestsPass
+	}
+	if !strings.HasPrefix(dbconfig.PGTestsHost, "/") {
+		dsn += " port=" + dbconfig.PGTestsPort
+	}
+	db, err := ConnectDSN(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	return db
+}
+
+// The unpartitioned tables of an upgraded database, the columns of which are
+// in a different order than in the partitioned tables since they were added by
+// migrations.
+const (
+	createUpgradedVinTable = `CREATE TABLE vins (
+		id SERIAL8 PRIMARY KEY,
+		tx_hash TEXT,
+		tx_index INT4,
+		tx_tree INT2,
+		block_height INT8,
+		is_valid BOOLEAN,
+		is_mainchain BOOLEAN,
+		block_time TIMESTAMPTZ,
+		prev_tx_hash TEXT,
+		prev_tx_index INT8,
+		prev_tx_tree INT2,
+		tx_type INT4,
+		value_in INT8
+	);`
+	createUpgradedVoutTable = `CREATE TABLE vouts (
+		id SERIAL8 PRIMARY KEY,
+		tx_hash TEXT,
+		tx_index INT4,
+		tx_tree INT2,
+		value INT8,
+		version INT2,
+		pkscript BYTEA,
+		script_req_sigs INT4,
+		script_type TEXT,
+		script_addresses TEXT[],
+		block_height INT8,
+		spend_tx_row_id INT8,
+		mixed BOOLEAN DEFAULT FALSE
+	);`
+)
+
+func TestPartitionTable(t *testing.T) {
+	db := connectTestSchema(t, partitionTestSchema)
+	defer db.Close()
+	defer sqlDb.Exec(`DROP SCHEMA IF EXISTS ` + partitionTestSchema + ` CASCADE;`)
+
+	tests := []struct {
+		name        string
+		table       string
+		create      string
+		insert      string // one row, with the block height $1 and value $2
+		selectRows  string // the id, block height and value, by id
+		wantErr     bool
+		wantPartEnd int64
+	}{{
+		name:   "vins",
+		table:  "vins",
+		create: createUpgradedVinTable,
+		insert: `INSERT INTO vins (tx_hash, tx_index, tx_tree, block_height,
+			is_valid, is_mainchain, block_time, tx_type, value_in)
+			VALUES ('aa', 0, 0, $1, TRUE, TRUE, NOW(), 0, $2);`,
+		selectRows:  `SELECT id, block_height, value_in FROM vins ORDER BY id;`,
+		wantPartEnd: 2 * PartitionBlocks,
+	}, {
+		name:   "vouts",
+		table:  "vouts",
+		create: createUpgradedVoutTable,
+		insert: `INSERT INTO vouts (tx_hash, tx_index, tx_tree, block_height,
+			value, script_addresses, mixed)
+			VALUES ('bb', 0, 0, $1, $2, '{"Dsaddr"}', TRUE);`,
+		selectRows:  `SELECT id, block_height, value FROM vouts ORDER BY id;`,
+		wantPartEnd: 2 * PartitionBlocks,
+	}, {
+		name:   "vins without block height",
+		table:  "vins",
+		create: createUpgradedVinTable,
+		insert: `INSERT INTO vins (tx_hash, tx_index, tx_tree, block_height, value_in)
+			VALUES ('cc', 0, 0, NULLIF($1, 7), $2);`,
+		selectRows: `SELECT id, COALESCE(block_height, -1), value_in FROM vins ORDER BY id;`,
+		wantErr:    true,
+	}}
+
+	type row struct{ id, height, value int64 }
+	for _, test := range tests {
+		var createStmt, columns string
+		for _, pt := range partitionedTables {
+			if pt[0] == test.table {
+				createStmt, columns = pt[1], pt[2]
+			}
+		}
+		_, err := db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, test.table) + test.create)
+		if err != nil {
+			t.Fatalf("%s: failed to create table: %v", test.name, err)
+		}
+		// Rows in two partitions, one of which is at a height set to NULL by
+		// the insert of the last test.
+		for i, height := range []int64{0, 7, PartitionBlocks - 1, PartitionBlocks + 3} {
+			if _, err = db.Exec(test.insert, height, 1000*(i+1)); err != nil {
+				t.Fatalf("%s: failed to insert row: %v", test.name, err)
+			}
+		}
+
+		readRows := func() []row {
+			rows, err := db.Query(test.selectRows)
+			if err != nil {
+				t.Fatalf("%s: failed to select rows: %v", test.name, err)
+			}
+			defer rows.Close()
+			var rs []row
+			for rows.Next() {
+				var r row
+				if err = rows.Scan(&r.id, &r.height, &r.value); err != nil {
+					t.Fatalf("%s: failed to scan row: %v", test.name, err)
+				}
+				rs = append(rs, r)
+			}
+			return rs
+		}
+		before := readRows()
+
+		err = partitionTable(db, test.table, createStmt, columns)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.wantErr, err)
+			continue
+		}
+		partitioned, err := TableIsPartitioned(db, test.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if partitioned == test.wantErr {
+			t.Errorf("%s: expected partitioned %v, got %v", test.name, !test.wantErr, partitioned)
+		}
+		if after := readRows(); !reflect.DeepEqual(after, before) {
+			t.Errorf("%s: expected rows %v, got %v", test.name, before, after)
+		}
+		if test.wantErr {
+			continue
+		}
+
+		end, err := partitionsEnd(db, test.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if end != test.wantPartEnd {
+			t.Errorf("%s: expected partitions up to %d, got %d", test.name, test.wantPartEnd, end)
+		}
+		// New rows get ids after those of the moved rows.
+		var id int64
+		err = db.QueryRow(strings.TrimSuffix(test.insert, ";")+` RETURNING id;`, 5, 1).Scan(&id)
+		if err != nil {
+			t.Fatalf("%s: failed to insert row after partitioning: %v", test.name, err)
+		}
+		if last := before[len(before)-1].id; id <= last {
+			t.Errorf("%s: new row id %d, expected more than %d", test.name, id, last)
+		}
+	}
+}
+
+func TestUpsertPartitioned(t *testing.T) {
+	db := connectTestSchema(t, partitionTestSchema)
+	defer db.Close()
+	defer sqlDb.Exec(`DROP SCHEMA IF EXISTS ` + partitionTestSchema + ` CASCADE;`)
+
+	// createPartitionedTables would find the tables of the public schema.
+	for _, pt := range partitionedTables {
+		if _, err := db.Exec(pt[1]); err != nil {
+			t.Fatalf("failed to create the partitioned %s table: %v", pt[0], err)
+		}
+		if err := createPartitions(db, pt[0], 0, 2*PartitionBlocks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := IndexVinTableOnVins(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexVoutTableOnTxHashIdx(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same transaction is stored in a side chain block, and then in a
+	// main chain block at a height in another partition.
+	vinArgs := func(txHash string, mainchain bool, height int64) []interface{} {
+		return []interface{}{txHash, 0, 0, "prev", 1, 0, 5000, true, mainchain,
+			time.Unix(1454954400, 0), 0, height}
+	}
+	voutArgs := func(txHash string, mainchain bool, height int64) []interface{} {
+		return []interface{}{txHash, 0, 0, 5000, 0, []byte{0x76}, 1, "pubkeyhash",
+			pq.Array([]string{"Dsaddr"}), false, height}
+	}
+	tests := []struct {
+		name  string
+		stmt  string
+		table string
+		args  func(txHash string, mainchain bool, height int64) []interface{}
+	}{
+		{"vin upsert", internal.UpsertVinRowPartitioned, "vins", vinArgs},
+		{"vin insert", internal.InsertVinRowPartitionedOnConflictDoNothing, "vins", vinArgs},
+		{"vout upsert", internal.UpsertVoutRowPartitioned, "vouts", voutArgs},
+		{"vout insert", internal.InsertVoutRowPartitionedOnConflictDoNothing, "vouts", voutArgs},
+	}
+
+	for _, test := range tests {
+		txHash := strings.Replace(test.name, " ", "_", -1)
+		var sideID, mainID int64
+		err := db.QueryRow(test.stmt, test.args(txHash, false, PartitionBlocks-1)...).Scan(&sideID)
+		if err != nil {
+			t.Fatalf("%s: failed to store side chain row: %v", test.name, err)
+		}
+		err = db.QueryRow(test.stmt, test.args(txHash, true, PartitionBlocks+1)...).Scan(&mainID)
+		if err != nil {
+			t.Fatalf("%s: failed to store main chain row: %v", test.name, err)
+		}
+		if mainID != sideID {
+			t.Errorf("%s: expected the existing row %d, got %d", test.name, sideID, mainID)
+		}
+
+		var count int64
+		err = db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE tx_hash = $1;`,
+			test.table), txHash).Scan(&count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("%s: expected 1 row of the transaction, got %d", test.name, count)
+		}
+	}
+
+	// The upsert updates the existing row.
+	var mainchain bool
+	err := db.QueryRow(`SELECT is_mainchain FROM vins WHERE tx_hash = 'vin_upsert';`).Scan(&mainchain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mainchain {
+		t.Error("the vin upsert did not update the existing row")
+	}
+}
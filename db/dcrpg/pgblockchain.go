@@ -9,9 +9,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/decred/dcrdata/blockdata/v5"
+	"io/ioutil"
 	"math"
 	"runtime"
 	"sort"
@@ -39,7 +41,6 @@ import (
 	"github.com/decred/dcrdata/stakedb/v3"
 	"github.com/decred/dcrdata/txhelpers/v4"
 	"github.com/decred/dcrwallet/wallet/v3/txrules"
-	pitypes "github.com/dmigwi/go-piparser/proposals/types"
 	humanize "github.com/dustin/go-humanize"
 	"github.com/lib/pq"
 )
@@ -70,12 +71,14 @@ func IsRetryError(err error) bool {
 // agenda_votes table.
 var storedAgendas map[string]dbtypes.MileStone
 
-// isPiparserRunning is the flag set when a Piparser instance is running.
-const isPiparserRunning = uint32(1)
+// isProposalsHandlerRunning is the flag set when the proposals update handler
+// is running.
+const isProposalsHandlerRunning = uint32(1)
 
-// piParserCounter is a counter that helps guarantee that only one instance
-// of proposalsUpdateHandler can ever be running at any one given moment.
-var piParserCounter uint32
+// proposalsHandlerCounter is a counter that helps guarantee that only one
+// instance of proposalsUpdateHandler can ever be running at any one given
+// moment.
+var proposalsHandlerCounter uint32
 
 // ticketPoolDataCache stores the most recent ticketpool graphs information
 // fetched to minimize the possibility of making multiple queries to the db
@@ -89,11 +92,14 @@ type ticketPoolDataCache struct {
 	DonutGraphCache map[dbtypes.TimeBasedGrouping]*dbtypes.PoolTicketsData
 }
 
-// ProposalsFetcher defines the interface of the proposals plug-n-play data source.
+// ProposalsFetcher defines the interface of the Politeia proposals data source.
+// UpdateSignal signals when the proposals should be updated, Proposals returns
+// the metadata and vote summaries of all the public proposals, and CastVotes
+// returns all the votes cast on a proposal.
 type ProposalsFetcher interface {
 	UpdateSignal() <-chan struct{}
-	ProposalsHistory() ([]*pitypes.History, error)
-	ProposalsHistorySince(since time.Time) ([]*pitypes.History, error)
+	Proposals() ([]*dbtypes.PoliteiaProposal, error)
+	CastVotes(token string) ([]dbtypes.ProposalCastVote, error)
 }
 
 // ticketPoolGraphsCache persists the latest ticketpool data queried from the db.
@@ -257,10 +263,18 @@ type ChainDB struct {
 	ctx                context.Context
 	queryTimeout       time.Duration
 	db                 *sql.DB
+	replicas           []*sql.DB
+	replicaIdx         uint32
 	mp                 rpcutils.MempoolAddressChecker
 	chainParams        *chaincfg.Params
 	devAddress         string
 	dupChecks          bool
+	vinBatchSize       int
+	voutBatchSize      int
+	copyThreshold      int
+	partitioned        bool
+	partitionMtx       sync.Mutex
+	partitionEnd       int64 // end of the block heights with vins and vouts partitions
 	bestBlock          *BestBlock
 	lastBlock          map[chainhash.Hash]uint64
 	stakeDB            *stakedb.StakeDatabase
@@ -275,9 +289,11 @@ type ChainDB struct {
 	mixSetDiffsMtx     sync.Mutex
 	mixSetDiffs        map[uint32]int64 // height to value diff
 	deployments        *ChainDeployments
-	piparser           ProposalsFetcher
+	piFetcher          ProposalsFetcher
 	proposalsSync      lastSync
 	cockroach          bool
+	maintenanceReqs    chan string
+	receiptTimer       BlockReceiptTimer
 	MPC                *mempool.MempoolDataCache
 	// BlockCache stores apitypes.BlockDataBasic and apitypes.StakeInfoExtended
 	// in StoreBlock for quick retrieval without a DB query.
@@ -296,6 +312,24 @@ type ChainDB struct {
 		// commonly retrieved when the explorer block is updated.
 		difficulties map[int64]float64
 	}
+	// circulatingSupply caches the circulating supply as of the best block.
+	circulatingSupply struct {
+		sync.Mutex
+		hash   string
+		supply int64
+	}
+	// utxoSetStats caches the UTXO set statistics as of the best block.
+	utxoSetStats struct {
+		sync.Mutex
+		hash  string
+		stats *apitypes.UTXOSetStats
+	}
+	// addressTags caches the labels of known addresses. The labels are not
+	// modified, only replaced.
+	addressTags struct {
+		sync.RWMutex
+		tags map[string]*dbtypes.AddressTag
+	}
 }
 
 // ChainDeployments is mutex-protected blockchain deployment data.
@@ -461,9 +495,18 @@ func NewTicketTxnIDGetter(db *sql.DB) *TicketTxnIDGetter {
 	}
 }
 
-// DBInfo holds the PostgreSQL database connection information.
+// DBInfo holds the PostgreSQL database connection information. If DSN is set,
+// it is used to connect to the primary database instead of Host, Port, User,
+// and Pass, but DBName should still be set since it is used to identify the
+// database. ReplicaDSNs are the data source names of any read replicas of the
+// primary database. When replicas are specified, read-only queries that can
+// tolerate replication lag, such as paginated address history and chart data,
+// are spread over the replicas, while all writes and the queries that feed the
+// block-keyed caches go to the primary.
 type DBInfo struct {
 	Host, Port, User, Pass, DBName string
+	DSN                            string
+	ReplicaDSNs                    []string
 	QueryTimeout                   time.Duration
 }
 
@@ -473,7 +516,24 @@ type ChainDBCfg struct {
 	DevPrefetch, HidePGConfig         bool
 	AddrCacheRowCap, AddrCacheAddrCap int
 	AddrCacheUTXOByteCap              int
-}
+	// VinBatchSize and VoutBatchSize are the maximum numbers of vins and vouts
+	// written in each COPY. The default is DefaultCopyBatchSize.
+	VinBatchSize, VoutBatchSize int
+	// CopyThreshold is the number of vins or vouts in a block's transaction
+	// tree at or above which they are written with the COPY protocol instead
+	// of a prepared INSERT for each row. COPY is only used while the unique
+	// indexes are dropped, as during a bulk sync. 0 disables COPY.
+	CopyThreshold int
+	// Partition creates the vins and vouts tables partitioned by block height
+	// in an empty database, or converts the tables of an existing database.
+	// Once partitioned, the tables remain partitioned regardless of this
+	// setting. Partitioning is not supported with CockroachDB.
+	Partition bool
+}
+
+// DefaultCopyBatchSize is the default maximum number of vins or vouts written
+// in each COPY.
+const DefaultCopyBatchSize = 10000
 
 // NewChainDB constructs a ChainDB for the given connection and Decred network
 // parameters. By default, duplicate row checks on insertion are enabled. See
@@ -482,10 +542,10 @@ type ChainDBCfg struct {
 // between the notifier and the handler method. A non-nil BlockGetter is only
 // needed if database upgrades are required.
 func NewChainDB(cfg *ChainDBCfg, stakeDB *stakedb.StakeDatabase,
-	mp rpcutils.MempoolAddressChecker, parser ProposalsFetcher, client *rpcclient.Client,
+	mp rpcutils.MempoolAddressChecker, piFetcher ProposalsFetcher, client *rpcclient.Client,
 	shutdown func()) (*ChainDB, error) {
 	ctx := context.Background()
-	chainDB, err := NewChainDBWithCancel(ctx, cfg, stakeDB, mp, parser, client, shutdown)
+	chainDB, err := NewChainDBWithCancel(ctx, cfg, stakeDB, mp, piFetcher, client, shutdown)
 	if err != nil {
 		return nil, err
 	}
@@ -501,27 +561,19 @@ func NewChainDB(cfg *ChainDBCfg, stakeDB *stakedb.StakeDatabase,
 // cancel queries with CTRL+C, for example, use NewChainDBWithCancel. A non-nil
 // BlockGetter is only needed if database upgrades are required.
 func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeDatabase,
-	mp rpcutils.MempoolAddressChecker, parser ProposalsFetcher, client *rpcclient.Client,
+	mp rpcutils.MempoolAddressChecker, piFetcher ProposalsFetcher, client *rpcclient.Client,
 	shutdown func()) (*ChainDB, error) {
 	// Connect to the PostgreSQL daemon and return the *sql.DB.
 	dbi := cfg.DBi
-	db, err := Connect(dbi.Host, dbi.Port, dbi.User, dbi.Pass, dbi.DBName)
+	db, err := connectDBInfo(dbi)
 	if err != nil {
 		return nil, err
 	}
 
 	// Put the PostgreSQL time zone in UTC.
-	var initTZ string
-	initTZ, err = CheckCurrentTimeZone(db)
-	if err != nil {
+	if err = setTimeZoneUTC(db); err != nil {
 		return nil, err
 	}
-	if initTZ != "UTC" {
-		log.Infof("Switching PostgreSQL time zone to UTC for this session.")
-		if _, err = db.Exec(`SET TIME ZONE UTC`); err != nil {
-			return nil, fmt.Errorf("Failed to set time zone to UTC: %v", err)
-		}
-	}
 
 	pgVersion, err := RetrievePGVersion(db)
 	if err != nil {
@@ -530,6 +582,11 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 	log.Info(pgVersion)
 
 	cockroach := strings.Contains(pgVersion, "CockroachDB")
+	partition := cfg.Partition
+	if partition && cockroach {
+		log.Warnf("Partitioning of the vins and vouts tables is not supported with CockroachDB.")
+		partition = false
+	}
 
 	// Optionally logs the PostgreSQL configuration.
 	if !cockroach && !cfg.HidePGConfig {
@@ -619,6 +676,11 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 	case tablesNotFoundErr:
 		// Empty database (no blocks table). Proceed to setupTables.
 		log.Infof(`Empty database "%s". Creating tables...`, dbi.DBName)
+		if partition {
+			if err = createPartitionedTables(db); err != nil {
+				return nil, fmt.Errorf("failed to create partitioned tables: %v", err)
+			}
+		}
 		if err = CreateTables(db); err != nil {
 			return nil, fmt.Errorf("failed to create tables: %v", err)
 		}
@@ -645,6 +707,47 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 		return nil, err
 	}
 
+	// Partition the vins and vouts tables of an existing database. The
+	// block_height columns are only set once the legacy upgrades are done.
+	if partition {
+		if doLegacyUpgrade {
+			log.Warnf("The vins and vouts tables will be partitioned after the legacy upgrades, on the next start.")
+		} else if err = partitionVinsVouts(db); err != nil {
+			return nil, fmt.Errorf("failed to partition the vins and vouts tables: %v", err)
+		}
+	}
+
+	var partitioned bool
+	var partitionEnd int64
+	if !cockroach {
+		vinsPartitioned, err := TableIsPartitioned(db, "vins")
+		if err != nil {
+			return nil, err
+		}
+		voutsPartitioned, err := TableIsPartitioned(db, "vouts")
+		if err != nil {
+			return nil, err
+		}
+		if vinsPartitioned != voutsPartitioned {
+			return nil, fmt.Errorf("only one of the vins and vouts tables is partitioned, " +
+				"enable partitioning to finish partitioning them")
+		}
+		partitioned = vinsPartitioned
+	}
+	if partitioned {
+		for _, pair := range partitionedTables {
+			end, err := partitionsEnd(db, pair[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve the %s partitions: %v", pair[0], err)
+			}
+			if partitionEnd == 0 || end < partitionEnd {
+				partitionEnd = end
+			}
+		}
+		log.Infof("The vins and vouts tables are partitioned by block height, "+
+			"with partitions up to block %d.", partitionEnd-1)
+	}
+
 	// Get the best block height from the blocks table.
 	bestHeight, bestHash, err := RetrieveBestBlock(ctx, db)
 	if err != nil {
@@ -768,14 +871,42 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 		cfg.AddrCacheUTXOByteCap)
 	addrCache.ProjectAddress = projectFundAddress
 
+	// Connect to the read replicas, if any.
+	replicas, err := connectReplicas(dbi.ReplicaDSNs)
+	if err != nil {
+		return nil, err
+	}
+	if len(replicas) > 0 {
+		log.Infof("Using %d PostgreSQL read replica(s).", len(replicas))
+	}
+
+	vinBatchSize, voutBatchSize := cfg.VinBatchSize, cfg.VoutBatchSize
+	if vinBatchSize <= 0 {
+		vinBatchSize = DefaultCopyBatchSize
+	}
+	if voutBatchSize <= 0 {
+		voutBatchSize = DefaultCopyBatchSize
+	}
+	copyThreshold := cfg.CopyThreshold
+	if copyThreshold > 0 && cockroach {
+		log.Warnf("COPY of vins and vouts is not supported with CockroachDB.")
+		copyThreshold = 0
+	}
+
 	chainDB := &ChainDB{
 		ctx:                ctx,
 		queryTimeout:       queryTimeout,
 		db:                 db,
+		replicas:           replicas,
 		mp:                 mp,
 		chainParams:        params,
 		devAddress:         projectFundAddress,
 		dupChecks:          true,
+		vinBatchSize:       vinBatchSize,
+		voutBatchSize:      voutBatchSize,
+		copyThreshold:      copyThreshold,
+		partitioned:        partitioned,
+		partitionEnd:       partitionEnd,
 		bestBlock:          bestBlock,
 		lastBlock:          make(map[chainhash.Hash]uint64),
 		stakeDB:            stakeDB,
@@ -787,8 +918,9 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 		utxoCache:          newUtxoStore(5e4),
 		mixSetDiffs:        make(map[uint32]int64),
 		deployments:        new(ChainDeployments),
-		piparser:           parser,
+		piFetcher:          piFetcher,
 		cockroach:          cockroach,
+		maintenanceReqs:    make(chan string, 64),
 		MPC:                new(mempool.MempoolDataCache),
 		BlockCache:         apitypes.NewAPICache(1e4),
 		heightClients:      make([]chan uint32, 0),
@@ -841,27 +973,50 @@ func NewChainDBWithCancel(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb
 		}
 	}
 
+	// Load the labels of known addresses.
+	if err = chainDB.loadAddressTags(); err != nil {
+		return chainDB, fmt.Errorf("failed to load the address labels: %v", err)
+	}
+
 	return chainDB, nil
 }
 
-// StartPiparserHandler controls how piparser update handler will be initiated.
-// This handler should to be run once only when the first sync after startup completes.
-func (pgb *ChainDB) StartPiparserHandler() {
-	if atomic.CompareAndSwapUint32(&piParserCounter, 0, isPiparserRunning) {
+// StartProposalsHandler starts the handler of the proposals fetcher's update
+// signals. This handler should to be run once only when the first sync after
+// startup completes.
+func (pgb *ChainDB) StartProposalsHandler() {
+	if atomic.CompareAndSwapUint32(&proposalsHandlerCounter, 0, isProposalsHandlerRunning) {
 		// Start the proposal updates handler async method.
 		pgb.proposalsUpdateHandler()
 
-		log.Info("Piparser instance to handle updates is now active")
+		log.Info("Proposals update handler is now active")
 	} else {
-		log.Error("piparser instance is already running, another one cannot be activated")
+		log.Error("proposals update handler is already running, another one cannot be activated")
 	}
 }
 
 // Close closes the underlying sql.DB connection to the database.
 func (pgb *ChainDB) Close() error {
+	for _, replica := range pgb.replicas {
+		if err := replica.Close(); err != nil {
+			log.Errorf("Failed to close read replica connection: %v", err)
+		}
+	}
 	return pgb.db.Close()
 }
 
+// readDB returns the connection pool to use for a read-only query, which is
+// the next of the read replicas in turn, or the primary database if there are
+// no replicas. Since the replicas may lag the primary, the results of queries
+// on them must not be stored in caches that are keyed by the best block.
+func (pgb *ChainDB) readDB() *sql.DB {
+	if len(pgb.replicas) == 0 {
+		return pgb.db
+	}
+	i := atomic.AddUint32(&pgb.replicaIdx, 1)
+	return pgb.replicas[i%uint32(len(pgb.replicas))]
+}
+
 // SqlDB returns the underlying sql.DB, which should not be used directly unless
 // you know what you are doing (if you have to ask...).
 func (pgb *ChainDB) SqlDB() *sql.DB {
@@ -886,6 +1041,18 @@ func (pgb *ChainDB) UseMempoolChecker(mp rpcutils.MempoolAddressChecker) {
 	pgb.mp = mp
 }
 
+// BlockReceiptTimer provides the times at which the block connected
+// notifications of new blocks were received from dcrd.
+type BlockReceiptTimer interface {
+	BlockReceived(hash chainhash.Hash) (time.Time, bool)
+}
+
+// UseBlockReceiptTimer assigns a BlockReceiptTimer for the block propagation
+// latencies recorded by Store.
+func (pgb *ChainDB) UseBlockReceiptTimer(t BlockReceiptTimer) {
+	pgb.receiptTimer = t
+}
+
 // EnableDuplicateCheckOnInsert specifies whether SQL insertions should check
 // for row conflicts (duplicates), and avoid adding or updating.
 func (pgb *ChainDB) EnableDuplicateCheckOnInsert(dupCheck bool) {
@@ -948,6 +1115,28 @@ func (pgb *ChainDB) DropTables() {
 	DropTables(pgb.db)
 }
 
+// DropTablesByName drops (deletes) only the specified dcrdata tables. See the
+// DropTablesByName function for details.
+func (pgb *ChainDB) DropTablesByName(tableNames []string) ([]string, error) {
+	return DropTablesByName(pgb.db, tableNames)
+}
+
+// SyncState retrieves the recorded phase of an in-progress bulk import, or nil
+// if no import is in progress.
+func (pgb *ChainDB) SyncState() (*SyncState, error) {
+	return RetrieveSyncState(pgb.db)
+}
+
+// SetSyncState records the phase of an in-progress bulk import.
+func (pgb *ChainDB) SetSyncState(phase string, height int64, bulkReindex bool) error {
+	return SetSyncState(pgb.db, phase, height, bulkReindex)
+}
+
+// ClearSyncState removes the recorded sync state when an import completes.
+func (pgb *ChainDB) ClearSyncState() error {
+	return ClearSyncState(pgb.db)
+}
+
 // SideChainBlocks retrieves all known side chain blocks.
 func (pgb *ChainDB) SideChainBlocks() ([]*dbtypes.BlockStatus, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
@@ -956,6 +1145,62 @@ func (pgb *ChainDB) SideChainBlocks() ([]*dbtypes.BlockStatus, error) {
 	return scb, pgb.replaceCancelError(err)
 }
 
+// SideChainBlock retrieves the stored side chain block with the given hash,
+// with its transactions. sql.ErrNoRows is returned if the block is on the main
+// chain or its data is not stored.
+func (pgb *ChainDB) SideChainBlock(hash string) (*apitypes.SideChainBlock, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	msgBlock, isValid, err := RetrieveSideChainBlock(ctx, pgb.db, hash)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	block := &apitypes.SideChainBlock{
+		Hash:         msgBlock.BlockHash().String(),
+		Height:       msgBlock.Header.Height,
+		PreviousHash: msgBlock.Header.PrevBlock.String(),
+		Time:         apitypes.TimeAPI{S: dbtypes.NewTimeDef(msgBlock.Header.Timestamp)},
+		IsValid:      isValid,
+		Size:         msgBlock.SerializeSize(),
+	}
+	sideChainTxns := func(txns []*wire.MsgTx) []apitypes.SideChainTx {
+		sctxs := make([]apitypes.SideChainTx, 0, len(txns))
+		for _, tx := range txns {
+			// Serializing a transaction only fails on write errors.
+			txHex, _ := txhelpers.MsgTxToHex(tx)
+			sctxs = append(sctxs, apitypes.SideChainTx{
+				TxID:     tx.TxHash().String(),
+				Type:     txhelpers.DetermineTxTypeString(tx),
+				Size:     tx.SerializeSize(),
+				Fee:      txhelpers.TxFee(tx).ToCoin(),
+				TotalOut: txhelpers.TotalOutFromMsgTx(tx).ToCoin(),
+				Hex:      txHex,
+			})
+		}
+		return sctxs
+	}
+	block.Tx = sideChainTxns(msgBlock.Transactions)
+	block.STx = sideChainTxns(msgBlock.STransactions)
+	return block, nil
+}
+
+// storeOrphanedBlock stores the full data of a block moved from the main chain
+// to a side chain, retrieving the block from dcrd.
+func (pgb *ChainDB) storeOrphanedBlock(hash string) {
+	if pgb.Client == nil {
+		return
+	}
+	msgBlock, err := pgb.GetBlockByHash(hash)
+	if err != nil {
+		log.Errorf("Failed to retrieve orphaned block %s: %v", hash, err)
+		return
+	}
+	if err = InsertSideChainBlock(pgb.db, msgBlock); err != nil {
+		log.Errorf("Failed to store orphaned block %s: %v", hash, err)
+	}
+}
+
 // SideChainTips retrieves the tip/head block for all known side chains.
 func (pgb *ChainDB) SideChainTips() ([]*dbtypes.BlockStatus, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
@@ -1225,8 +1470,876 @@ func (pgb *ChainDB) BlockHeight(hash string) (int64, error) {
 func (pgb *ChainDB) BlockHash(height int64) (string, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	hash, err := RetrieveBlockHash(ctx, pgb.db, height)
-	return hash, pgb.replaceCancelError(err)
+	hash, err := RetrieveBlockHash(ctx, pgb.db, height)
+	return hash, pgb.replaceCancelError(err)
+}
+
+// BlockChainWork queries the DB for the hash and stored chainwork of the
+// mainchain block at the given height.
+func (pgb *ChainDB) BlockChainWork(height int64) (hash, chainWork string, err error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	hash, chainWork, err = RetrieveBlockChainWork(ctx, pgb.db, height)
+	return hash, chainWork, pgb.replaceCancelError(err)
+}
+
+// AddressBalanceAtHeight queries the DB for the balance of an address as of
+// the mainchain block at the given height, in atoms.
+func (pgb *ChainDB) AddressBalanceAtHeight(address string, height int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	balance, err := RetrieveAddressBalanceAtHeight(ctx, pgb.readDB(), address, height)
+	return balance, pgb.replaceCancelError(err)
+}
+
+// TreasuryBalance queries the DB for the current treasury balance and the
+// number of mainchain treasury additions and spends.
+func (pgb *ChainDB) TreasuryBalance() (*apitypes.TreasuryBalance, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	balance, numAdds, numSpends, err := RetrieveTreasuryBalance(ctx, pgb.db)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return &apitypes.TreasuryBalance{
+		Height:    pgb.Height(),
+		Balance:   dcrutil.Amount(balance).ToCoin(),
+		NumAdds:   numAdds,
+		NumSpends: numSpends,
+	}, nil
+}
+
+// TreasuryBalanceHistory queries the DB for the amounts added to and spent
+// from the treasury, and the resulting balance, for each period of the
+// specified time grouping.
+func (pgb *ChainDB) TreasuryBalanceHistory(grouping dbtypes.TimeBasedGrouping) (*apitypes.TreasuryBalanceHistory, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	history, err := retrieveTreasuryBalanceHistory(ctx, pgb.readDB(), grouping.String())
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	prices, err := retrieveDCRPrices(ctx, pgb.readDB(), dbtypes.DCRPriceCurrency)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	if len(prices) > 0 {
+		history.FiatCurrency = dbtypes.DCRPriceCurrency
+		history.FiatBalance = prices.fiatValues(grouping, history.Time, history.Balance)
+	}
+	return history, nil
+}
+
+// RecentSwaps queries the DB for the N most recent mainchain atomic swap
+// redemptions and refunds, skipping the first offset.
+func (pgb *ChainDB) RecentSwaps(N, offset int64) ([]*apitypes.AtomicSwap, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := retrieveRecentSwaps(ctx, pgb.readDB(), N, offset)
+	return swaps, pgb.replaceCancelError(err)
+}
+
+// TxSwaps queries the DB for the mainchain atomic swap redemptions and refunds
+// of the contracts created or spent by the transaction.
+func (pgb *ChainDB) TxSwaps(txid string) ([]*apitypes.AtomicSwap, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := retrieveSwapsForTx(ctx, pgb.readDB(), txid)
+	return swaps, pgb.replaceCancelError(err)
+}
+
+// StakeROI simulates staking the amount from the start height with the
+// historical ticket prices, pool sizes, and vote rewards, restaking the
+// rewards until the best block. sql.ErrNoRows is returned if the start height
+// is above the best block.
+func (pgb *ChainDB) StakeROI(amount dcrutil.Amount, start int64) (*apitypes.StakeROI, error) {
+	bestHeight := pgb.Height()
+	if start > bestHeight {
+		return nil, sql.ErrNoRows
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	roi, err := retrieveStakeROI(ctx, pgb.readDB(), pgb.chainParams, amount, start, bestHeight)
+	return roi, pgb.replaceCancelError(err)
+}
+
+// TxGraph retrieves the graph of the mainchain ancestors and descendants of
+// the transaction up to depth hops, with up to maxEdges edges in each
+// direction. sql.ErrNoRows is returned if the transaction is not in the
+// mainchain, and dbtypes.ErrPruned if it is in a pruned block.
+func (pgb *ChainDB) TxGraph(txid string, depth, maxEdges int) (*apitypes.TxGraph, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	pruned, err := pgb.txPruned(ctx, txid)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	if pruned {
+		return nil, dbtypes.ErrPruned
+	}
+	graph, err := retrieveTxGraph(ctx, pgb.readDB(), txid, depth, maxEdges)
+	return graph, pgb.replaceCancelError(err)
+}
+
+// VoteVersionHistory queries the DB for the number of mainchain votes with each
+// vote version for each period of the specified time grouping, and the
+// percentage of the votes with a version older than the given version.
+func (pgb *ChainDB) VoteVersionHistory(grouping dbtypes.TimeBasedGrouping, version uint32) (*apitypes.VoteVersionHistory, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	history, err := retrieveVoteVersionHistory(ctx, pgb.readDB(), grouping.String(), version)
+	return history, pgb.replaceCancelError(err)
+}
+
+// VoteBitsStats queries the DB for the number of mainchain votes with the vote
+// version in the blocks with heights in [startHeight, endHeight] by their vote
+// bits, and tallies the choices of the agendas of the vote version.
+func (pgb *ChainDB) VoteBitsStats(version uint32, startHeight, endHeight int64) (*apitypes.VoteBitsStats, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	counts, err := retrieveVoteBitsCounts(ctx, pgb.readDB(), version, startHeight, endHeight)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	stats := &apitypes.VoteBitsStats{
+		Version:     version,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		VoteBits:    counts,
+		Agendas:     []apitypes.VoteAgendaChoices{},
+	}
+	for _, c := range counts {
+		stats.Votes += c.Count
+	}
+	for _, deployment := range pgb.chainParams.Deployments[version] {
+		vote := &deployment.Vote
+		agenda := apitypes.VoteAgendaChoices{
+			ID:      vote.Id,
+			Choices: make([]apitypes.VoteChoiceCount, len(vote.Choices)),
+		}
+		for i := range vote.Choices {
+			agenda.Choices[i].Choice = vote.Choices[i].Id
+		}
+		for _, c := range counts {
+			bits := c.VoteBits & vote.Mask
+			for i := range vote.Choices {
+				if bits == vote.Choices[i].Bits {
+					agenda.Choices[i].Count += c.Count
+					break
+				}
+			}
+		}
+		stats.Agendas = append(stats.Agendas, agenda)
+	}
+	return stats, nil
+}
+
+// TSpendVotes queries the DB for the mainchain vote tally of a TSpend.
+func (pgb *ChainDB) TSpendVotes(txid string) (*apitypes.TSpendVoteTally, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	yes, no, err := RetrieveTSpendVoteTally(ctx, pgb.db, txid)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return &apitypes.TSpendVoteTally{Yes: yes, No: no}, nil
+}
+
+// InvoicePayments retrieves the payments to the invoice payment address by the
+// transactions, which are only included if they are valid and mainchain.
+func (pgb *ChainDB) InvoicePayments(address string, txids []string) ([]apitypes.ProposalPayment, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	payments, err := retrieveInvoicePayments(ctx, pgb.db, address, txids, pgb.devAddress)
+	return payments, pgb.replaceCancelError(err)
+}
+
+// TSpend gets the details of a mined TSpend, including its payouts from the
+// node and its vote tally. sql.ErrNoRows is returned if the transaction is not
+// a mainchain TSpend.
+func (pgb *ChainDB) TSpend(txid string) (*apitypes.TSpend, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	amount, blockHash, blockHeight, blockTime, err := RetrieveTSpend(ctx, pgb.db, txid)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	yes, no, err := RetrieveTSpendVoteTally(ctx, pgb.db, txid)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	txHash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := pgb.Client.GetRawTransaction(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TSpend %s: %v", txid, err)
+	}
+	msgTx := tx.MsgTx()
+
+	tspend := &apitypes.TSpend{
+		TxID:        txid,
+		BlockHash:   blockHash,
+		BlockHeight: blockHeight,
+		Time:        apitypes.TimeAPI{S: blockTime},
+		Amount:      dcrutil.Amount(amount).ToCoin(),
+		Expiry:      msgTx.Expiry,
+		Votes:       apitypes.TSpendVoteTally{Yes: yes, No: no},
+	}
+	var paid int64
+	// The first output is the OP_RETURN commitment.
+	for _, txOut := range msgTx.TxOut[1:] {
+		paid += txOut.Value
+		tspend.Payouts = append(tspend.Payouts, apitypes.TSpendPayout{
+			Address: txhelpers.TGenPayoutAddress(txOut.PkScript, pgb.chainParams),
+			Amount:  dcrutil.Amount(txOut.Value).ToCoin(),
+		})
+	}
+	tspend.Fee = dcrutil.Amount(amount - paid).ToCoin()
+	return tspend, nil
+}
+
+// TxInclusionProof generates the merkle proof that the transaction is
+// committed to by the header of the mainchain block containing it.
+// sql.ErrNoRows is returned if the transaction is not in a mainchain block.
+func (pgb *ChainDB) TxInclusionProof(txid string) (*apitypes.TxInclusionProof, error) {
+	txHash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+	blocks, _, err := pgb.TransactionBlocks(txid)
+	if err != nil {
+		return nil, err
+	}
+	var block *dbtypes.BlockStatus
+	for _, b := range blocks {
+		if b.IsMainchain {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	msgBlock, err := pgb.GetBlockByHash(block.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %v", block.Hash, err)
+	}
+	ip, err := txhelpers.GenerateTxInclusionProof(msgBlock, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := make([]string, len(ip.Proof))
+	for i := range ip.Proof {
+		proof[i] = ip.Proof[i].String()
+	}
+	return &apitypes.TxInclusionProof{
+		TxID:        txid,
+		BlockHash:   block.Hash,
+		BlockHeight: int64(block.Height),
+		Root:        ip.Root.String(),
+		Tree:        ip.Tree,
+		LeafIndex:   ip.LeafIndex,
+		Proof:       proof,
+	}, nil
+}
+
+// ChainReorgs retrieves N of the recorded chain reorganizations, most recent
+// first, after skipping the first offset.
+func (pgb *ChainDB) ChainReorgs(N, offset int64) (*apitypes.ChainReorgs, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	reorgs, total, err := RetrieveReorgs(ctx, pgb.readDB(), N, offset)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return &apitypes.ChainReorgs{
+		Total:  total,
+		Reorgs: reorgs,
+	}, nil
+}
+
+// ScriptTypeCounts queries the DB for the number and value of the mainchain
+// outputs of each script type in each period of the time grouping.
+func (pgb *ChainDB) ScriptTypeCounts(grouping dbtypes.TimeBasedGrouping) (*apitypes.ScriptTypeCounts, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	counts, err := retrieveScriptTypeCounts(ctx, pgb.readDB(), grouping.String())
+	return counts, pgb.replaceCancelError(err)
+}
+
+// NonstandardOutputs retrieves a page of the mainchain outputs with
+// nonstandard scripts, most recent first.
+func (pgb *ChainDB) NonstandardOutputs(N, offset int64) (*apitypes.NonstandardOutputs, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	outputs, total, err := RetrieveNonstandardOutputs(ctx, pgb.readDB(), N, offset)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return &apitypes.NonstandardOutputs{
+		Total:   total,
+		Outputs: outputs,
+	}, nil
+}
+
+// UpdateRichList materializes the top N addresses by balance in the rich list,
+// and the balance distribution of all addresses.
+func (pgb *ChainDB) UpdateRichList(N int) error {
+	start := time.Now()
+	height := pgb.Height()
+	if err := RebuildRichList(pgb.db, N, height); err != nil {
+		return err
+	}
+	log.Debugf("Updated rich list at height %d in %v.", height, time.Since(start))
+	return nil
+}
+
+// RunRichListUpdater updates the rich list of the top N addresses immediately,
+// and then at the given interval until the context is cancelled.
+func (pgb *ChainDB) RunRichListUpdater(ctx context.Context, interval time.Duration, N int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := pgb.UpdateRichList(N); err != nil {
+			log.Errorf("Failed to update rich list: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RichList retrieves the top N addresses of the materialized rich list, and
+// the balance distribution.
+func (pgb *ChainDB) RichList(N int) (*apitypes.RichList, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	richList, err := RetrieveRichList(ctx, pgb.readDB(), N)
+	return richList, pgb.replaceCancelError(err)
+}
+
+// MinPruneDepth is the minimum number of blocks below the best block whose
+// vins and vouts are kept in pruning mode, well beyond the depth of any
+// expected reorg.
+const MinPruneDepth = 4096
+
+// pruneBatchBlocks is the number of blocks pruned in each DB transaction.
+const pruneBatchBlocks = 1000
+
+// PrunedHeight retrieves the height of the last block whose vins and spent
+// vouts have been pruned, or -1 if the DB has not been pruned.
+func (pgb *ChainDB) PrunedHeight() (int64, error) {
+	return RetrievePrunedHeight(pgb.db)
+}
+
+// heightPruned checks if the vins and spent vouts of the blocks at the height
+// have been pruned.
+func (pgb *ChainDB) heightPruned(height int64) (bool, error) {
+	prunedHeight, err := pgb.PrunedHeight()
+	return height <= prunedHeight, err
+}
+
+// txPruned checks if the vins and spent vouts of the transaction, or the vins
+// spending its outputs, may have been pruned.
+func (pgb *ChainDB) txPruned(ctx context.Context, txHash string) (bool, error) {
+	prunedHeight, err := pgb.PrunedHeight()
+	if err != nil || prunedHeight < 0 {
+		return false, err
+	}
+	return retrieveTxPruned(ctx, pgb.db, txHash, prunedHeight)
+}
+
+// Prune deletes the vins and spent vouts of the blocks more than depth blocks
+// below the best block that have not already been pruned. The unspent vouts
+// are kept for the UTXO set, and the atoms minted by the pruned blocks are
+// rolled up so that the coin supply remains accurate. The blocks,
+// transactions and addresses tables are not pruned, so the block summaries
+// and address balances are unaffected.
+func (pgb *ChainDB) Prune(depth int64) error {
+	if depth < MinPruneDepth {
+		return fmt.Errorf("prune depth %d is less than the minimum %d",
+			depth, MinPruneDepth)
+	}
+	if pgb.InBatchSync || pgb.InReorg {
+		return nil
+	}
+
+	prunedHeight, err := pgb.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	target := pgb.Height() - depth
+	if target <= prunedHeight {
+		return nil
+	}
+
+	start := time.Now()
+	var totalVins, totalVouts int64
+	for from := prunedHeight + 1; from <= target; from += pruneBatchBlocks {
+		if err = pgb.ctx.Err(); err != nil {
+			return err
+		}
+		to := from + pruneBatchBlocks
+		if to > target+1 {
+			to = target + 1
+		}
+		vins, vouts, err := PruneBlocks(pgb.db, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to prune blocks [%d, %d): %v", from, to, err)
+		}
+		totalVins += vins
+		totalVouts += vouts
+	}
+	log.Infof("Pruned %d vins and %d vouts of blocks %d to %d in %v.",
+		totalVins, totalVouts, prunedHeight+1, target, time.Since(start))
+	pgb.RequestMaintenance("vins", "vouts")
+	return nil
+}
+
+// RunPruner prunes the blocks more than depth blocks below the best block
+// immediately, and then at the given interval until the context is cancelled.
+func (pgb *ChainDB) RunPruner(ctx context.Context, interval time.Duration, depth int64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := pgb.Prune(depth); err != nil {
+			log.Errorf("Failed to prune DB: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// APIKeyQuota retrieves the rate limit, in requests per second, and the burst
+// of the API key. sql.ErrNoRows is returned for an unknown or disabled key.
+func (pgb *ChainDB) APIKeyQuota(key string) (float64, int, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rateLimit, burst, err := RetrieveAPIKeyQuota(ctx, pgb.db, key)
+	return rateLimit, burst, pgb.replaceCancelError(err)
+}
+
+// StoreVSPStats stores a snapshot of a VSP's statistics. StoreVSPStats
+// satisfies vsp.Store.
+func (pgb *ChainDB) StoreVSPStats(stats *dbtypes.VSPStats) error {
+	return InsertVSPStats(pgb.db, stats)
+}
+
+// VSPs retrieves the most recent statistics of each VSP. VSPs satisfies
+// vsp.Store.
+func (pgb *ChainDB) VSPs() ([]*dbtypes.VSPStats, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	vsps, err := RetrieveLatestVSPStats(ctx, pgb.readDB())
+	return vsps, pgb.replaceCancelError(err)
+}
+
+// StoreDCRPrices stores the daily DCR prices. StoreDCRPrices satisfies
+// pricehistory.Store.
+func (pgb *ChainDB) StoreDCRPrices(prices []*dbtypes.DCRPrice) error {
+	return InsertDCRPrices(pgb.db, prices)
+}
+
+// LastDCRPriceDay retrieves the last day with a stored DCR price in the
+// currency, or the zero time if there are none. LastDCRPriceDay satisfies
+// pricehistory.Store.
+func (pgb *ChainDB) LastDCRPriceDay(currency string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	day, err := RetrieveLastDCRPriceDay(ctx, pgb.db, currency)
+	return day, pgb.replaceCancelError(err)
+}
+
+// StoreMarketPrices adds the DCR-BTC price samples to the market candles.
+// StoreMarketPrices satisfies candles.Store.
+func (pgb *ChainDB) StoreMarketPrices(prices []*dbtypes.MarketPrice) error {
+	return InsertMarketPrices(pgb.db, prices)
+}
+
+// StoreMarketCandles stores the market candles reported by the exchanges.
+// StoreMarketCandles satisfies candles.Store.
+func (pgb *ChainDB) StoreMarketCandles(candles []*dbtypes.MarketCandle) error {
+	return InsertMarketCandles(pgb.db, candles)
+}
+
+// MarketCandles retrieves the market candles of the exchange, or of the
+// dbtypes.AggregatedMarket, for the bin, starting in [from, to).
+func (pgb *ChainDB) MarketCandles(exchange, bin string, from, to time.Time) ([]*dbtypes.MarketCandle, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	candles, err := retrieveMarketCandles(ctx, pgb.readDB(), exchange, bin, from, to)
+	return candles, pgb.replaceCancelError(err)
+}
+
+// StoreAddressWatch stores a new address watch registration, setting its ID.
+// StoreAddressWatch satisfies webhooks.Store.
+func (pgb *ChainDB) StoreAddressWatch(watch *dbtypes.AddressWatch) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	id, err := InsertAddressWatch(ctx, pgb.db, watch)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	watch.ID = id
+	return nil
+}
+
+// AddressWatches retrieves all address watch registrations. AddressWatches
+// satisfies webhooks.Store.
+func (pgb *ChainDB) AddressWatches() ([]*dbtypes.AddressWatch, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	watches, err := RetrieveAddressWatches(ctx, pgb.db)
+	return watches, pgb.replaceCancelError(err)
+}
+
+// DeleteAddressWatch deletes the address watch registration with the given ID
+// and secret. sql.ErrNoRows is returned if there is no such registration.
+// DeleteAddressWatch satisfies webhooks.Store.
+func (pgb *ChainDB) DeleteAddressWatch(id int64, secret string) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	return pgb.replaceCancelError(DeleteAddressWatch(ctx, pgb.db, id, secret))
+}
+
+// StoreFaucetGrant stores a faucet grant, setting its ID. StoreFaucetGrant
+// satisfies faucet.Store.
+func (pgb *ChainDB) StoreFaucetGrant(grant *dbtypes.FaucetGrant) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	id, err := InsertFaucetGrant(ctx, pgb.db, grant)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	grant.ID = id
+	return nil
+}
+
+// LastFaucetGrant retrieves the most recent faucet grant to the address.
+// sql.ErrNoRows is returned if there are none. LastFaucetGrant satisfies
+// faucet.Store.
+func (pgb *ChainDB) LastFaucetGrant(address string) (*dbtypes.FaucetGrant, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	grant, err := RetrieveLastFaucetGrant(ctx, pgb.db, address)
+	return grant, pgb.replaceCancelError(err)
+}
+
+// loadAddressTags loads the address labels from the DB into the cache.
+func (pgb *ChainDB) loadAddressTags() error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	tags, err := RetrieveAddressTags(ctx, pgb.db)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	tagMap := make(map[string]*dbtypes.AddressTag, len(tags))
+	for _, tag := range tags {
+		tagMap[tag.Address] = tag
+	}
+	pgb.addressTags.Lock()
+	pgb.addressTags.tags = tagMap
+	pgb.addressTags.Unlock()
+	return nil
+}
+
+// AddressTag returns the label of the address, or nil if it has none.
+func (pgb *ChainDB) AddressTag(address string) *dbtypes.AddressTag {
+	pgb.addressTags.RLock()
+	defer pgb.addressTags.RUnlock()
+	return pgb.addressTags.tags[address]
+}
+
+// AddressTags returns the labels of the known addresses with the category, or
+// all labels if the category is empty, ordered by category and label.
+func (pgb *ChainDB) AddressTags(category string) []*dbtypes.AddressTag {
+	pgb.addressTags.RLock()
+	tags := make([]*dbtypes.AddressTag, 0, len(pgb.addressTags.tags))
+	for _, tag := range pgb.addressTags.tags {
+		if category == "" || tag.Category == category {
+			tags = append(tags, tag)
+		}
+	}
+	pgb.addressTags.RUnlock()
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Category != tags[j].Category {
+			return tags[i].Category < tags[j].Category
+		}
+		if tags[i].Label != tags[j].Label {
+			return tags[i].Label < tags[j].Label
+		}
+		return tags[i].Address < tags[j].Address
+	})
+	return tags
+}
+
+// SetAddressTag stores the label of an address, replacing any existing label.
+// The label's Source and Updated fields are set.
+func (pgb *ChainDB) SetAddressTag(tag *dbtypes.AddressTag) error {
+	tag.Source = dbtypes.AddressTagSourceAdmin
+	tag.Updated = dbtypes.NewTimeDef(time.Now())
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	if err := UpsertAddressTag(ctx, pgb.db, tag); err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	tagCopy := *tag
+	pgb.addressTags.Lock()
+	pgb.addressTags.tags[tag.Address] = &tagCopy
+	pgb.addressTags.Unlock()
+	return nil
+}
+
+// DeleteAddressTag deletes the label of the address. sql.ErrNoRows is returned
+// if the address has no label.
+func (pgb *ChainDB) DeleteAddressTag(address string) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	if err := DeleteAddressTag(ctx, pgb.db, address); err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	pgb.addressTags.Lock()
+	delete(pgb.addressTags.tags, address)
+	pgb.addressTags.Unlock()
+	return nil
+}
+
+// ImportAddressTags imports the address labels from a JSON file with an array
+// of objects with "address", "label" and "category" fields. Labels of invalid
+// addresses are skipped, and labels set with the admin API are not replaced.
+// The number of labels stored is returned.
+func (pgb *ChainDB) ImportAddressTags(fileName string) (int64, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return 0, err
+	}
+	var tags []*dbtypes.AddressTag
+	if err = json.Unmarshal(b, &tags); err != nil {
+		return 0, fmt.Errorf("invalid address labels file %s: %v", fileName, err)
+	}
+
+	now := dbtypes.NewTimeDef(time.Now())
+	valid := make([]*dbtypes.AddressTag, 0, len(tags))
+	for _, tag := range tags {
+		if _, err = dcrutil.DecodeAddress(tag.Address, pgb.chainParams); err != nil {
+			log.Warnf("Skipping the label of invalid address %q: %v", tag.Address, err)
+			continue
+		}
+		if tag.Label == "" {
+			log.Warnf("Skipping the empty label of address %s.", tag.Address)
+			continue
+		}
+		tag.Source = dbtypes.AddressTagSourceImport
+		tag.Updated = now
+		valid = append(valid, tag)
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	numStored, err := ImportAddressTags(ctx, pgb.db, valid)
+	if err != nil {
+		return 0, pgb.replaceCancelError(err)
+	}
+	return numStored, pgb.loadAddressTags()
+}
+
+// CoinAgeFlows queries the DB for the value created by the valid transactions
+// of the mainchain block at the given height, and the outputs they spend.
+func (pgb *ChainDB) CoinAgeFlows(height int64) (*dbtypes.CoinAgeFlows, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	flows, err := RetrieveCoinAgeFlows(ctx, pgb.db, height)
+	return flows, pgb.replaceCancelError(err)
+}
+
+// StoreCoinAgeBlock stores the coin age records of a block.
+func (pgb *ChainDB) StoreCoinAgeBlock(block *dbtypes.CoinAgeBlock) error {
+	return InsertCoinAgeBlock(pgb.db, block)
+}
+
+// CoinAgeTip queries the DB for the height, hash and time of the last block
+// with coin age records. The height is -1 if there are none.
+func (pgb *ChainDB) CoinAgeTip() (int64, string, dbtypes.TimeDef, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	height, hash, blockTime, err := RetrieveCoinAgeTip(ctx, pgb.db)
+	return height, hash, blockTime, pgb.replaceCancelError(err)
+}
+
+// CoinAgeDays queries the DB for the unspent value created on each day as of
+// the block at the given height.
+func (pgb *ChainDB) CoinAgeDays(height int64) (map[int64]int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	days, err := RetrieveCoinAgeDays(ctx, pgb.db, height)
+	return days, pgb.replaceCancelError(err)
+}
+
+// StoreCoinAgeBands stores a coin age bands snapshot.
+func (pgb *ChainDB) StoreCoinAgeBands(bands *dbtypes.CoinAgeBands) error {
+	return UpsertCoinAgeBands(pgb.db, bands)
+}
+
+// PurgeCoinAgeAbove deletes the coin age records of the blocks above the given
+// height.
+func (pgb *ChainDB) PurgeCoinAgeAbove(height int64) error {
+	return DeleteCoinAgeAbove(pgb.db, height)
+}
+
+// CoinDaysDestroyed queries the DB for the value spent and the coin days
+// destroyed in each period of the time grouping.
+func (pgb *ChainDB) CoinDaysDestroyed(grouping dbtypes.TimeBasedGrouping) (*apitypes.CoinDaysDestroyed, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	cdd, err := retrieveCoinDaysDestroyed(ctx, pgb.readDB(), grouping.String())
+	return cdd, pgb.replaceCancelError(err)
+}
+
+// CoinAgeBands queries the DB for the coin age bands snapshots. The band
+// labels are not set.
+func (pgb *ChainDB) CoinAgeBands() (*apitypes.CoinAgeBands, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	bands, err := retrieveCoinAgeBands(ctx, pgb.readDB())
+	return bands, pgb.replaceCancelError(err)
+}
+
+// StoreProposalTitles inserts or updates the proposal titles searched by
+// SearchProposals.
+func (pgb *ChainDB) StoreProposalTitles(titles []dbtypes.ProposalTitle) error {
+	return StoreProposalTitles(pgb.db, titles)
+}
+
+// SearchProposals searches the proposal titles for the words of the query, and
+// the tokens for tokenPrefix if it is not empty.
+func (pgb *ChainDB) SearchProposals(query, tokenPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	results, err := retrieveSearchProposals(ctx, pgb.readDB(), query, tokenPrefix, limit)
+	return results, pgb.replaceCancelError(err)
+}
+
+// SearchBlocks searches the mainchain blocks for the hash prefix.
+func (pgb *ChainDB) SearchBlocks(hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	results, err := retrieveSearchBlocks(ctx, pgb.readDB(), hashPrefix, limit)
+	return results, pgb.replaceCancelError(err)
+}
+
+// SearchBlockRange retrieves the mainchain blocks in the inclusive height
+// range.
+func (pgb *ChainDB) SearchBlockRange(from, to int64, limit int) ([]*apitypes.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	results, err := retrieveSearchBlockRange(ctx, pgb.readDB(), from, to, limit)
+	return results, pgb.replaceCancelError(err)
+}
+
+// SearchTransactions searches the transactions for the hash prefix.
+func (pgb *ChainDB) SearchTransactions(hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	results, err := retrieveSearchTransactions(ctx, pgb.readDB(), hashPrefix, limit)
+	return results, pgb.replaceCancelError(err)
+}
+
+// SearchAddresses searches the addresses with any history for the prefix.
+func (pgb *ChainDB) SearchAddresses(prefix string, limit int) ([]*apitypes.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	results, err := retrieveSearchAddresses(ctx, pgb.readDB(), prefix, limit)
+	return results, pgb.replaceCancelError(err)
+}
+
+// ClusterInputs queries the DB for the distinct input addresses of each valid
+// regular transaction, other than CSPP mixes, of the mainchain block at the
+// given height.
+func (pgb *ChainDB) ClusterInputs(height int64) ([][]string, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	inputs, err := RetrieveClusterInputs(ctx, pgb.db, height)
+	return inputs, pgb.replaceCancelError(err)
+}
+
+// AddressClusterIDs queries the DB for the cluster ids of the addresses that
+// are clustered.
+func (pgb *ChainDB) AddressClusterIDs(addrs []string) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	ids, err := RetrieveAddressClusterIDs(ctx, pgb.db, addrs)
+	return ids, pgb.replaceCancelError(err)
+}
+
+// StoreClusterBlock stores the address cluster changes of a block.
+func (pgb *ChainDB) StoreClusterBlock(block *dbtypes.ClusterBlock) error {
+	return InsertClusterBlock(pgb.db, block)
+}
+
+// AddressClusterTip queries the DB for the height and hash of the last block
+// processed for address clustering. The height is -1 if there is none.
+func (pgb *ChainDB) AddressClusterTip() (int64, string, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	height, hash, err := RetrieveAddressClusterTip(ctx, pgb.db)
+	return height, hash, pgb.replaceCancelError(err)
+}
+
+// AddressCluster queries the DB for the cluster of the address and the
+// unspent value of the cluster's addresses.
+func (pgb *ChainDB) AddressCluster(address string) (*apitypes.AddressCluster, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	cluster, err := retrieveAddressCluster(ctx, pgb.readDB(), address)
+	return cluster, pgb.replaceCancelError(err)
+}
+
+// StoreMempoolTxs records the transactions seen in mempool at seenAt in the
+// mempool history.
+func (pgb *ChainDB) StoreMempoolTxs(txs []*dbtypes.MempoolHistoryTx, seenAt time.Time) error {
+	return UpsertMempoolHistory(pgb.db, txs, seenAt)
+}
+
+// ResolveMempoolTxs sets the fate of the pending transactions in the mempool
+// history that were not seen in the mempool snapshot at seenAt: mined if they
+// are in a mainchain block, or evicted if not seen since evictBefore.
+func (pgb *ChainDB) ResolveMempoolTxs(seenAt, evictBefore time.Time) (mined, evicted int64, err error) {
+	return ResolveMempoolHistory(pgb.db, seenAt, evictBefore)
+}
+
+// MempoolTxHistory queries the DB for the mempool history of the transaction.
+// sql.ErrNoRows is returned if the transaction was not seen in mempool.
+func (pgb *ChainDB) MempoolTxHistory(txid string) (*apitypes.MempoolTxHistory, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	hist, err := retrieveMempoolTxHistory(ctx, pgb.readDB(), txid)
+	return hist, pgb.replaceCancelError(err)
+}
+
+// MempoolCongestion queries the DB for the mempool history aggregated by the
+// period of the time grouping in which the transactions were first seen.
+func (pgb *ChainDB) MempoolCongestion(grouping dbtypes.TimeBasedGrouping) (*apitypes.MempoolCongestion, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	congestion, err := retrieveMempoolCongestion(ctx, pgb.readDB(), grouping.String())
+	return congestion, pgb.replaceCancelError(err)
 }
 
 // BlockTimeByHeight queries the DB for the time of the mainchain block at the
@@ -1253,18 +2366,18 @@ func (pgb *ChainDB) VotesInBlock(hash string) (int16, error) {
 }
 
 // proposalsUpdateHandler runs in the background asynchronous to retrieve the
-// politeia proposal updates that the piparser tool signaled.
+// politeia proposal updates that the proposals fetcher signaled.
 func (pgb *ChainDB) proposalsUpdateHandler() {
-	// Do not initiate the async update if invalid or disabled piparser instance was found.
-	if pgb.piparser == nil {
-		log.Error("invalid or disabled piparser instance found: proposals async update stopped")
+	// Do not initiate the async update if no proposals fetcher was set.
+	if pgb.piFetcher == nil {
+		log.Error("no proposals fetcher found: proposals async update stopped")
 		return
 	}
 
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Errorf("recovered from piparser panic in proposalsUpdateHandler: %v", r)
+				log.Errorf("recovered from panic in proposalsUpdateHandler: %v", r)
 				select {
 				case <-time.NewTimer(time.Minute).C:
 					log.Infof("attempting to restart proposalsUpdateHandler")
@@ -1273,123 +2386,156 @@ func (pgb *ChainDB) proposalsUpdateHandler() {
 				}
 			}
 		}()
-		for range pgb.piparser.UpdateSignal() {
-			count, err := pgb.PiProposalsHistory()
+		for range pgb.piFetcher.UpdateSignal() {
+			count, err := pgb.SyncProposals()
 			if err != nil {
-				log.Error("pgb.PiProposalsHistory failed : %v", err)
-			} else {
-				log.Infof("%d politeia's proposal commits were processed", count)
+				log.Errorf("pgb.SyncProposals failed: %v", err)
+			} else if count > 0 {
+				log.Infof("%d politeia proposal votes were stored", count)
 			}
 		}
 	}()
 }
 
-// LastPiParserSync returns last time value when the piparser run sync on proposals
-// and proposal_votes table.
-func (pgb *ChainDB) LastPiParserSync() time.Time {
+// LastProposalVotesSync returns the last time the proposals and their votes
+// were retrieved from the proposals fetcher.
+func (pgb *ChainDB) LastProposalVotesSync() time.Time {
 	pgb.proposalsSync.mtx.RLock()
 	defer pgb.proposalsSync.mtx.RUnlock()
 	return pgb.proposalsSync.syncTime
 }
 
-// PiProposalsHistory queries the politeia's proposal updates via the parser tool
-// and pushes them to the proposals and proposal_votes tables.
-func (pgb *ChainDB) PiProposalsHistory() (int64, error) {
-	if pgb.piparser == nil {
-		return -1, fmt.Errorf("invalid piparser instance was found")
+// SyncProposals retrieves the proposals from the proposals fetcher, storing
+// their metadata and vote summaries in the politeia_proposals table. The votes
+// cast on a proposal are only retrieved if its vote count exceeds the number of
+// votes stored, and the new votes are pushed to the proposals and
+// proposal_votes tables. The number of votes stored is returned.
+func (pgb *ChainDB) SyncProposals() (int64, error) {
+	if pgb.piFetcher == nil {
+		return -1, fmt.Errorf("no proposals fetcher was found")
 	}
 
 	pgb.proposalsSync.mtx.Lock()
-
-	// set the sync time
 	pgb.proposalsSync.syncTime = time.Now().UTC()
-
 	pgb.proposalsSync.mtx.Unlock()
 
-	var isChecked bool
-	var proposalsData []*pitypes.History
-
-	lastUpdate, err := retrieveLastCommitTime(pgb.db)
-	switch {
-	case err == sql.ErrNoRows:
-		// No records exists yet fetch all the history.
-		proposalsData, err = pgb.piparser.ProposalsHistory()
-
-	case err != nil:
-		return -1, fmt.Errorf("retrieveLastCommitTime failed :%v", err)
-
-	default:
-		// Fetch the updates since the last insert only.
-		proposalsData, err = pgb.piparser.ProposalsHistorySince(lastUpdate)
-		isChecked = true
-	}
-
+	proposals, err := pgb.piFetcher.Proposals()
 	if err != nil {
 		return -1, fmt.Errorf("politeia proposals fetch failed: %v", err)
 	}
 
-	var commitsCount int64
+	var votesCount int64
+	for _, p := range proposals {
+		now := time.Now().UTC()
+		if err = UpsertPoliteiaProposal(pgb.db, p, now); err != nil {
+			return votesCount, fmt.Errorf("UpsertPoliteiaProposal failed: %v", err)
+		}
 
-	for _, entry := range proposalsData {
-		if entry.CommitSHA == "" {
-			// If missing commit sha ignore the entry.
+		if p.TotalVotes == 0 {
+			continue
+		}
+		stored, err := retrieveProposalVotedTickets(pgb.db, p.Token)
+		if err != nil {
+			return votesCount, fmt.Errorf("retrieveProposalVotedTickets failed: %v", err)
+		}
+		if int64(len(stored)) >= p.TotalVotes {
 			continue
 		}
 
-		// Multiple tokens votes data can be packed in a single Politeia's commit.
-		for _, val := range entry.Patch {
-			if val.Token == "" {
-				// If missing token ignore it.
-				continue
+		votes, err := pgb.piFetcher.CastVotes(p.Token)
+		if err != nil {
+			return votesCount, fmt.Errorf("politeia cast votes fetch failed: %v", err)
+		}
+		newVotes := make([]dbtypes.ProposalCastVote, 0, len(votes)-len(stored))
+		for _, vote := range votes {
+			if _, found := stored[vote.Ticket]; !found {
+				newVotes = append(newVotes, vote)
 			}
+		}
+		if len(newVotes) == 0 {
+			continue
+		}
 
-			id, err := InsertProposal(pgb.db, val.Token, entry.Author,
-				entry.CommitSHA, entry.Date, isChecked)
-			if err != nil {
-				return -1, fmt.Errorf("InsertProposal failed: %v", err)
+		// The votes are charted by the time they were retrieved, except that
+		// the votes of a finished vote that were never retrieved are
+		// attributed to the end of the vote.
+		t := now
+		if len(stored) == 0 && p.VoteStatus == "Finished" {
+			if endTime, err := pgb.BlockTimeByHeight(p.EndHeight); err == nil {
+				t = time.Unix(endTime, 0).UTC()
 			}
+		}
 
-			for _, vote := range val.VotesInfo {
-				_, err = InsertProposalVote(pgb.db, id, vote.Ticket,
-					string(vote.VoteBit), isChecked)
-				if err != nil {
-					return -1, fmt.Errorf("InsertProposalVote failed: %v", err)
-				}
-			}
+		if err = InsertProposalVotes(pgb.db, p.Token, newVotes, t); err != nil {
+			return votesCount, fmt.Errorf("InsertProposalVotes failed: %v", err)
 		}
-		commitsCount++
+		votesCount += int64(len(newVotes))
 	}
 
-	return commitsCount, err
+	return votesCount, nil
 }
 
 // ProposalVotes retrieves all the votes data associated with the provided token.
 func (pgb *ChainDB) ProposalVotes(proposalToken string) (*dbtypes.ProposalChartsData, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	chartsData, err := retrieveProposalVotesData(ctx, pgb.db, proposalToken)
+	chartsData, err := retrieveProposalVotesData(ctx, pgb.readDB(), proposalToken)
 	return chartsData, pgb.replaceCancelError(err)
 }
 
+// StoreProposalVoteSnapshot stores the snapshot of a live proposal vote's
+// tally.
+func (pgb *ChainDB) StoreProposalVoteSnapshot(snap *dbtypes.ProposalVoteSnapshot) error {
+	return UpsertProposalVoteSnapshot(pgb.db, snap)
+}
+
+// ProposalVoteSnapshots retrieves the snapshots of the proposal's vote tally,
+// oldest first. sql.ErrNoRows is returned if there are none.
+func (pgb *ChainDB) ProposalVoteSnapshots(token string) (*apitypes.ProposalVoteSnapshots, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	snaps, err := RetrieveProposalVoteSnapshots(ctx, pgb.readDB(), token)
+	return snaps, pgb.replaceCancelError(err)
+}
+
 // SpendingTransactions retrieves all transactions spending outpoints from the
 // specified funding transaction. The spending transaction hashes, the spending
 // tx input indexes, and the corresponding funding tx output indexes, and an
-// error value are returned.
+// error value are returned. dbtypes.ErrPruned is returned if the funding
+// transaction is in a pruned block, since the spending transaction inputs may
+// have been deleted.
 func (pgb *ChainDB) SpendingTransactions(fundingTxID string) ([]string, []uint32, []uint32, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
+	pruned, err := pgb.txPruned(ctx, fundingTxID)
+	if err != nil {
+		return nil, nil, nil, pgb.replaceCancelError(err)
+	}
+	if pruned {
+		return nil, nil, nil, dbtypes.ErrPruned
+	}
 	_, spendingTxns, vinInds, voutInds, err := RetrieveSpendingTxsByFundingTx(ctx, pgb.db, fundingTxID)
 	return spendingTxns, vinInds, voutInds, pgb.replaceCancelError(err)
 }
 
 // SpendingTransaction returns the transaction that spends the specified
 // transaction outpoint, if it is spent. The spending transaction hash, input
-// index, tx tree, and an error value are returned.
+// index, tx tree, and an error value are returned. If no spending transaction
+// is found for an outpoint in a pruned block, dbtypes.ErrPruned is returned
+// since the spending transaction input may have been deleted.
 func (pgb *ChainDB) SpendingTransaction(fundingTxID string,
 	fundingTxVout uint32) (string, uint32, int8, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
 	_, spendingTx, vinInd, tree, err := RetrieveSpendingTxByTxOut(ctx, pgb.db, fundingTxID, fundingTxVout)
+	if err == sql.ErrNoRows {
+		pruned, errPruned := pgb.txPruned(ctx, fundingTxID)
+		if errPruned != nil {
+			err = errPruned
+		} else if pruned {
+			err = dbtypes.ErrPruned
+		}
+	}
 	return spendingTx, vinInd, tree, pgb.replaceCancelError(err)
 }
 
@@ -1498,7 +2644,7 @@ func (pgb *ChainDB) AgendaVotes(agendaID string, chartType int) (*dbtypes.Agenda
 		return nil, nil
 	}
 
-	avc, err := retrieveAgendaVoteChoices(ctx, pgb.db, agendaID, chartType,
+	avc, err := retrieveAgendaVoteChoices(ctx, pgb.readDB(), agendaID, chartType,
 		agendaInfo.VotingStarted, agendaInfo.VotingDone)
 	return avc, pgb.replaceCancelError(err)
 }
@@ -1523,7 +2669,7 @@ func (pgb *ChainDB) AgendasVotesSummary(agendaID string) (summary *dbtypes.Agend
 	}
 
 	summary.Yes, summary.Abstain, summary.No, err = retrieveTotalAgendaVotesCount(ctx,
-		pgb.db, agendaID, agendaInfo.VotingStarted, agendaInfo.VotingDone)
+		pgb.readDB(), agendaID, agendaInfo.VotingStarted, agendaInfo.VotingDone)
 	return
 }
 
@@ -1540,8 +2686,102 @@ func (pgb *ChainDB) AgendaVoteCounts(agendaID string) (yes, abstain, no uint32,
 		return
 	}
 
-	return retrieveTotalAgendaVotesCount(ctx, pgb.db, agendaID,
+	return retrieveTotalAgendaVotesCount(ctx, pgb.readDB(), agendaID,
+		agendaInfo.VotingStarted, agendaInfo.VotingDone)
+}
+
+// UpdateAgendaVoteIntervals stores the vote counts of the intervals of each
+// agenda's voting period from the interval containing fromHeight, or from the
+// last stored interval if it is earlier, up to the best block. The intervals are
+// chainParams.StakeDiffWindowSize blocks, starting at the agenda's VotingStarted
+// height. Intervals that are missing, such as after the initial sync, are
+// backfilled, and the intervals recounted from fromHeight account for a reorg.
+func (pgb *ChainDB) UpdateAgendaVoteIntervals(fromHeight int64) error {
+	chainInfo := pgb.ChainInfo()
+	if chainInfo == nil {
+		return nil
+	}
+	bestHeight := pgb.Height()
+	intervalSize := pgb.chainParams.StakeDiffWindowSize
+
+	for agendaID, agendaInfo := range chainInfo.AgendaMileStones {
+		votingStarted, votingDone := agendaInfo.VotingStarted, agendaInfo.VotingDone
+		if votingStarted == 0 || votingStarted > bestHeight {
+			continue
+		}
+		endHeight := votingDone
+		if endHeight > bestHeight {
+			endHeight = bestHeight
+		}
+
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		lastStart, found, err := retrieveAgendaVoteIntervalsLastStart(ctx, pgb.db,
+			agendaID, votingStarted, votingDone)
+		cancel()
+		if err != nil {
+			return pgb.replaceCancelError(err)
+		}
+
+		start := votingStarted
+		if found {
+			start = lastStart
+		}
+		if fromHeight < start && fromHeight >= votingStarted {
+			start = fromHeight - (fromHeight-votingStarted)%intervalSize
+		}
+
+		for ; start <= endHeight; start += intervalSize {
+			end := start + intervalSize - 1
+			if end > endHeight {
+				end = endHeight
+			}
+			ctx, cancel = context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+			err = upsertAgendaVoteInterval(ctx, pgb.db, agendaID, start, end)
+			cancel()
+			if err != nil {
+				return pgb.replaceCancelError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// AgendaVoteTimeSeries returns the agenda's vote counts in each interval of its
+// voting period up to the best block. The voting period is the current rule
+// change interval if voting has not finished.
+func (pgb *ChainDB) AgendaVoteTimeSeries(agendaID string) (*apitypes.AgendaVoteTimeSeries, error) {
+	chainInfo := pgb.ChainInfo()
+	if chainInfo == nil {
+		return nil, sql.ErrNoRows
+	}
+	agendaInfo, found := chainInfo.AgendaMileStones[agendaID]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+
+	series := &apitypes.AgendaVoteTimeSeries{
+		AgendaID:      agendaID,
+		VotingStarted: agendaInfo.VotingStarted,
+		VotingDone:    agendaInfo.VotingDone,
+		IntervalSize:  pgb.chainParams.StakeDiffWindowSize,
+		Intervals:     []apitypes.AgendaVoteInterval{},
+	}
+	// Voting has not started.
+	if agendaInfo.VotingStarted == 0 {
+		return series, nil
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	intervals, err := retrieveAgendaVoteIntervals(ctx, pgb.readDB(), agendaID,
 		agendaInfo.VotingStarted, agendaInfo.VotingDone)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	if len(intervals) > 0 {
+		series.Intervals = intervals
+	}
+	return series, nil
 }
 
 // AllAgendas returns all the agendas stored currently.
@@ -1555,7 +2795,7 @@ func (pgb *ChainDB) AllAgendas() (map[string]dbtypes.MileStone, error) {
 func (pgb *ChainDB) NumAddressIntervals(addr string, grouping dbtypes.TimeBasedGrouping) (int64, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	return retrieveAddressTxsCount(ctx, pgb.db, addr, grouping.String())
+	return retrieveAddressTxsCount(ctx, pgb.readDB(), addr, grouping.String())
 }
 
 // AddressMetrics returns the block time of the oldest transaction and the
@@ -1588,7 +2828,7 @@ func (pgb *ChainDB) AddressMetrics(addr string) (*dbtypes.AddressMetrics, error)
 	// address (oldest transaction block time).
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	blockTime, err := retrieveOldestTxBlockTime(ctx, pgb.db, addr)
+	blockTime, err := retrieveOldestTxBlockTime(ctx, pgb.readDB(), addr)
 	if err != nil {
 		return nil, fmt.Errorf("retrieveOldestTxBlockTime failed: error: %v", err)
 	}
@@ -1624,7 +2864,7 @@ func (pgb *ChainDB) AddressTransactions(address string, N, offset int64,
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
 
-	addressRows, err = addrFunc(ctx, pgb.db, address, N, offset)
+	addressRows, err = addrFunc(ctx, pgb.readDB(), address, N, offset)
 	err = pgb.replaceCancelError(err)
 	return
 }
@@ -1684,7 +2924,7 @@ func (pgb *ChainDB) TicketPoolByDateAndInterval(maturityBlock int64,
 func (pgb *ChainDB) PosIntervals(limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	bgi, err := retrieveWindowBlocks(ctx, pgb.db,
+	bgi, err := retrieveWindowBlocks(ctx, pgb.readDB(),
 		pgb.chainParams.StakeDiffWindowSize, pgb.Height(), limit, offset)
 	return bgi, pgb.replaceCancelError(err)
 }
@@ -1696,7 +2936,7 @@ func (pgb *ChainDB) TimeBasedIntervals(timeGrouping dbtypes.TimeBasedGrouping,
 	limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
-	bgi, err := retrieveTimeBasedBlockListing(ctx, pgb.db, timeGrouping.String(),
+	bgi, err := retrieveTimeBasedBlockListing(ctx, pgb.readDB(), timeGrouping.String(),
 		limit, offset)
 	return bgi, pgb.replaceCancelError(err)
 }
@@ -1857,6 +3097,36 @@ func (pgb *ChainDB) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
 	}, nil
 }
 
+// TicketLifecycle retrieves the purchase, maturity, and vote or revocation of a
+// ticket, the vote reward, and the number of days the ticket was held.
+func (pgb *ChainDB) TicketLifecycle(txid string) (*apitypes.TicketLifecycle, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	tl, spendType, poolStatus, err := retrieveTicketLifecycle(ctx, pgb.db, txid,
+		int64(pgb.chainParams.TicketMaturity))
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	tl.MaturityHeight = tl.PurchaseBlock.Height + uint32(pgb.chainParams.TicketMaturity)
+	tl.ExpirationHeight = tl.MaturityHeight + pgb.chainParams.TicketExpiry
+	tl.SpendType = spendType.String()
+	tl.Status = strings.ToLower(poolStatus.String())
+	if pgb.Height() < int64(tl.MaturityHeight) {
+		tl.Status = "immature"
+	}
+	if spendType == dbtypes.TicketRevoked {
+		tl.Status = spendType.String()
+	}
+
+	end := time.Now().Unix()
+	if tl.Spend != nil {
+		end = tl.Spend.Time
+	}
+	tl.DaysHeld = float64(end-tl.PurchaseTime) / 86400
+	return tl, nil
+}
+
 func (pgb *ChainDB) updateProjectFundCache() error {
 	_, _, err := pgb.AddressHistoryAll(pgb.devAddress, 1, 0)
 	return err
@@ -1880,6 +3150,63 @@ func (pgb *ChainDB) FreshenAddressCaches(lazyProjectFund bool, expireAddresses [
 	numCleared := pgb.AddressCache.Clear(expireAddresses)
 	log.Debugf("Cleared cache for %d addresses.", numCleared)
 
+	return pgb.prefetchProjectFund(lazyProjectFund)
+}
+
+// connectAddressCaches moves the address cache to a new mainchain block,
+// purging only the data for the addresses in the block, and for the addresses
+// in the previous block's regular transactions if the new block disapproves
+// it. The cached data for all other addresses stays valid at the new block.
+func (pgb *ChainDB) connectAddressCaches(msgBlock *wire.MsgBlock, addresses []string) {
+	if msgBlock.Header.VoteBits&1 == 0 && msgBlock.Header.PrevBlock != zeroHash {
+		ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+		defer cancel()
+		invalidated, err := retrieveAddressesInBlock(ctx, pgb.db,
+			msgBlock.Header.PrevBlock.String())
+		if err != nil {
+			log.Errorf("Failed to retrieve the addresses in disapproved block %v: %v",
+				msgBlock.Header.PrevBlock, pgb.replaceCancelError(err))
+			pgb.AddressCache.ClearAll()
+			return
+		}
+		addresses = append(addresses, invalidated...)
+	}
+
+	blockHash := msgBlock.BlockHash()
+	numCleared := pgb.AddressCache.ConnectBlock(
+		*cache.NewBlockID(&blockHash, int64(msgBlock.Header.Height)),
+		msgBlock.Header.PrevBlock, addresses)
+	log.Debugf("Cleared cache for %d addresses.", numCleared)
+}
+
+// disconnectAddressCaches moves the address cache from an orphaned block back
+// to its parent, purging only the data for the addresses in the orphaned block.
+func (pgb *ChainDB) disconnectAddressCaches(blockHash string, parent *cache.BlockID) {
+	hash, err := chainhash.NewHashFromStr(blockHash)
+	if err != nil {
+		log.Errorf("Invalid orphaned block hash %s: %v", blockHash, err)
+		pgb.AddressCache.ClearAll()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	addresses, err := retrieveAddressesInBlock(ctx, pgb.db, blockHash)
+	if err != nil {
+		log.Errorf("Failed to retrieve the addresses in orphaned block %s: %v",
+			blockHash, pgb.replaceCancelError(err))
+		pgb.AddressCache.ClearAll()
+		return
+	}
+
+	numCleared := pgb.AddressCache.DisconnectBlock(*hash, *parent, addresses)
+	log.Debugf("Cleared cache for %d addresses.", numCleared)
+}
+
+// prefetchProjectFund prefetches the project fund balance if devPrefetch is
+// enabled and not mid-reorg. The project fund update is run asynchronously if
+// lazyProjectFund is true.
+func (pgb *ChainDB) prefetchProjectFund(lazyProjectFund bool) error {
 	// Do not initiate project fund queries if a reorg is in progress, or
 	// pre-fetch is disabled.
 	if !pgb.devPrefetch || pgb.InReorg {
@@ -2511,12 +3838,19 @@ func (pgb *ChainDB) FundingOutpointIndxByVinID(id uint64) (uint32, error) {
 // FillAddressTransactions is used to fill out the transaction details in an
 // explorer.AddressInfo generated by dbtypes.ReduceAddressHistory, usually from
 // the output of AddressHistory. This function also sets the number of
-// unconfirmed transactions for the current best block in the database.
+// unconfirmed transactions for the current best block in the database. The
+// matching transaction indexes of the transactions in pruned blocks are not
+// set.
 func (pgb *ChainDB) FillAddressTransactions(addrInfo *dbtypes.AddressInfo) error {
 	if addrInfo == nil {
 		return nil
 	}
 
+	prunedHeight, err := pgb.PrunedHeight()
+	if err != nil {
+		return err
+	}
+
 	var numUnconfirmed int64
 
 	for i, txn := range addrInfo.Transactions {
@@ -2541,7 +3875,7 @@ func (pgb *ChainDB) FillAddressTransactions(addrInfo *dbtypes.AddressInfo) error
 		// matching tx hash already present.  During the next database
 		// restructuring we may want to consider including matching tx index
 		// along with matching tx hash in the addresses table.
-		if txn.MatchedTx != `` {
+		if txn.MatchedTx != `` && dbTx.BlockHeight > prunedHeight {
 			if !txn.IsFunding {
 				// Spending transaction: lookup the previous outpoint's txout
 				// index by the vins table row ID.
@@ -2927,6 +4261,14 @@ func (pgb *ChainDB) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBloc
 	_, _, _, err := pgb.StoreBlock(msgBlock, isValid, isMainChain,
 		updateExistingRecords, updateAddressesSpendingInfo,
 		updateTicketsSpendingInfo, blockData.Header.ChainWork)
+	if err == nil {
+		pgb.recordBlockPropagation(msgBlock, time.Now())
+
+		height := int64(msgBlock.Header.Height)
+		if errAgenda := pgb.UpdateAgendaVoteIntervals(height); errAgenda != nil {
+			log.Errorf("Failed to update agenda vote intervals: %v", errAgenda)
+		}
+	}
 
 	// Signal updates to any subscribed heightClients.
 	pgb.SignalHeight(msgBlock.Header.Height)
@@ -2934,6 +4276,30 @@ func (pgb *ChainDB) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBloc
 	return err
 }
 
+// recordBlockPropagation records the times the block was received from dcrd
+// and stored, if known, in the block_propagation table.
+func (pgb *ChainDB) recordBlockPropagation(msgBlock *wire.MsgBlock, storedAt time.Time) {
+	hash := msgBlock.BlockHash()
+	var receivedAt time.Time
+	if pgb.receiptTimer != nil {
+		receivedAt, _ = pgb.receiptTimer.BlockReceived(hash)
+	}
+	err := UpsertBlockPropagation(pgb.db, hash.String(), int64(msgBlock.Header.Height),
+		msgBlock.Header.Timestamp, receivedAt, storedAt)
+	if err != nil {
+		log.Errorf("Failed to record block propagation of %v: %v", hash, err)
+	}
+}
+
+// BlockPropagation retrieves the propagation latency of the N most recent
+// mainchain blocks.
+func (pgb *ChainDB) BlockPropagation(N int64) (*apitypes.BlockPropagation, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	prop, err := RetrieveBlockPropagation(ctx, pgb.readDB(), N)
+	return prop, pgb.replaceCancelError(err)
+}
+
 // PurgeBestBlocks deletes all data for the N best blocks in the DB.
 func (pgb *ChainDB) PurgeBestBlocks(N int64) (*dbtypes.DeletionSummary, int64, error) {
 	res, height, _, err := DeleteBlocks(pgb.ctx, N, pgb.db)
@@ -3098,7 +4464,7 @@ func (pgb *ChainDB) TicketsByInputCount() (*dbtypes.PoolTicketsData, error) {
 func (pgb *ChainDB) windowStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrieveWindowStats(ctx, pgb.db, charts)
+	rows, err := retrieveWindowStats(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("windowStats: %v", pgb.replaceCancelError(err))
 	}
@@ -3112,7 +4478,7 @@ func (pgb *ChainDB) windowStats(charts *cache.ChartData) (*sql.Rows, func(), err
 func (pgb *ChainDB) missedVotesStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrieveMissedVotes(ctx, pgb.db, charts)
+	rows, err := retrieveMissedVotes(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("missedVotesStats: %v", pgb.replaceCancelError(err))
 	}
@@ -3126,7 +4492,7 @@ func (pgb *ChainDB) missedVotesStats(charts *cache.ChartData) (*sql.Rows, func()
 func (pgb *ChainDB) chartBlocks(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrieveChartBlocks(ctx, pgb.db, charts)
+	rows, err := retrieveChartBlocks(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %v", pgb.replaceCancelError(err))
 	}
@@ -3139,7 +4505,7 @@ func (pgb *ChainDB) chartBlocks(charts *cache.ChartData) (*sql.Rows, func(), err
 func (pgb *ChainDB) coinSupply(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrieveCoinSupply(ctx, pgb.db, charts)
+	rows, err := retrieveCoinSupply(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("coinSupply: %v", pgb.replaceCancelError(err))
 	}
@@ -3154,7 +4520,7 @@ func (pgb *ChainDB) txPerDay(timeArr []dbtypes.TimeDef, txCountArr []uint64) (
 	defer cancel()
 
 	var err error
-	timeArr, txCountArr, err = retrieveTxPerDay(ctx, pgb.db, timeArr, txCountArr)
+	timeArr, txCountArr, err = retrieveTxPerDay(ctx, pgb.readDB(), timeArr, txCountArr)
 	if err != nil {
 		err = fmt.Errorf("txPerDay: %v", pgb.replaceCancelError(err))
 	}
@@ -3168,7 +4534,7 @@ func (pgb *ChainDB) txPerDay(timeArr []dbtypes.TimeDef, txCountArr []uint64) (
 func (pgb *ChainDB) blockFees(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrieveBlockFees(ctx, pgb.db, charts)
+	rows, err := retrieveBlockFees(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %v", pgb.replaceCancelError(err))
 	}
@@ -3181,7 +4547,7 @@ func (pgb *ChainDB) blockFees(charts *cache.ChartData) (*sql.Rows, func(), error
 func (pgb *ChainDB) privacyParticipation(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrievePrivacyParticipation(ctx, pgb.db, charts)
+	rows, err := retrievePrivacyParticipation(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("privacyParticipation: %v", pgb.replaceCancelError(err))
 	}
@@ -3282,7 +4648,7 @@ func (pgb *ChainDB) appendAnonymitySet(charts *cache.ChartData, rows *sql.Rows)
 func (pgb *ChainDB) poolStats(charts *cache.ChartData) (*sql.Rows, func(), error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 
-	rows, err := retrievePoolStats(ctx, pgb.db, charts)
+	rows, err := retrievePoolStats(ctx, pgb.readDB(), charts)
 	if err != nil {
 		return nil, cancel, fmt.Errorf("chartBlocks: %v", pgb.replaceCancelError(err))
 	}
@@ -3308,7 +4674,7 @@ func (pgb *ChainDB) ticketsByBlocks(heightArr, soloArr, pooledArr []uint64) ([]u
 
 	var err error
 	heightArr, soloArr, pooledArr, err = retrieveTicketByOutputCount(ctx,
-		pgb.db, 1, outputCountByAllBlocks, heightArr, soloArr, pooledArr)
+		pgb.readDB(), 1, outputCountByAllBlocks, heightArr, soloArr, pooledArr)
 	if err != nil {
 		err = fmt.Errorf("ticketsByBlocks: %v", pgb.replaceCancelError(err))
 	}
@@ -3326,7 +4692,7 @@ func (pgb *ChainDB) ticketsByTPWindows(heightArr, soloArr, pooledArr []uint64) (
 	defer cancel()
 
 	var err error
-	heightArr, soloArr, pooledArr, err = retrieveTicketByOutputCount(ctx, pgb.db,
+	heightArr, soloArr, pooledArr, err = retrieveTicketByOutputCount(ctx, pgb.readDB(),
 		pgb.chainParams.StakeDiffWindowSize, outputCountByTicketPoolWindow,
 		heightArr, soloArr, pooledArr)
 	if err != nil {
@@ -3429,8 +4795,17 @@ func (pgb *ChainDB) PkScriptByVoutID(id uint64) (pkScript []byte, ver uint16, er
 
 // VinsForTx returns a slice of dbtypes.VinTxProperty values for each vin
 // referenced by the transaction dbTx, along with the pkScript and script
-// version for the corresponding previous outpoints.
+// version for the corresponding previous outpoints. dbtypes.ErrPruned is
+// returned if the transaction is in a pruned block.
 func (pgb *ChainDB) VinsForTx(dbTx *dbtypes.Tx) ([]dbtypes.VinTxProperty, []string, []uint16, error) {
+	pruned, err := pgb.heightPruned(dbTx.BlockHeight)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if pruned {
+		return nil, nil, nil, dbtypes.ErrPruned
+	}
+
 	// Retrieve the pkScript and script version for the previous outpoint of
 	// each vin.
 	prevPkScripts := make([]string, 0, len(dbTx.VinDbIds))
@@ -3455,15 +4830,27 @@ func (pgb *ChainDB) VinsForTx(dbTx *dbtypes.Tx) ([]dbtypes.VinTxProperty, []stri
 }
 
 // VoutsForTx returns a slice of dbtypes.Vout values for each vout referenced by
-// the transaction dbTx.
+// the transaction dbTx. dbtypes.ErrPruned is returned if the transaction is in
+// a pruned block, since its spent outputs have been deleted.
 func (pgb *ChainDB) VoutsForTx(dbTx *dbtypes.Tx) ([]dbtypes.Vout, error) {
+	pruned, err := pgb.heightPruned(dbTx.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	if pruned {
+		return nil, dbtypes.ErrPruned
+	}
+
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
 	vouts, err := RetrieveVoutsByIDs(ctx, pgb.db, dbTx.VoutDbIds)
 	return vouts, pgb.replaceCancelError(err)
 }
 
-func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
+// TipToSideChain moves the blocks from the tip back to, but not including, the
+// block mainRoot from the main chain to a side chain. The new tip hash, and the
+// numbers of blocks and transactions moved are returned.
+func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, int64, error) {
 	tipHash := pgb.BestBlockHashStr()
 	var blocksMoved, txnsUpdated, vinsUpdated, votesUpdated, ticketsUpdated, addrsUpdated int64
 	for tipHash != mainRoot {
@@ -3520,6 +4907,10 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 		}
 		addrsUpdated += numAddrSpending + numAddrFunding
 		log.Debugf("UpdateAddressesMainchainByIDs: %v", time.Since(now))
+		if err = invalidateAddressBalances(pgb.db, tipHash); err != nil {
+			log.Errorf("Failed to invalidate address balances from block %s: %v",
+				tipHash, err)
+		}
 
 		// 6. Votes. Sets is_mainchain=false on all votes in the tip block.
 		now = time.Now()
@@ -3541,7 +4932,26 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 		ticketsUpdated += rowsUpdated
 		log.Debugf("UpdateTicketsMainchain: %v", time.Since(now))
 
+		// 8. Treasury. Sets is_mainchain=false on all treasury txns and TSpend
+		// votes in the tip block.
+		if _, err = UpdateTreasuryMainchain(pgb.db, tipHash, false); err != nil {
+			log.Errorf("Failed to set treasury txns in block %s as sidechain: %v",
+				tipHash, err)
+		}
+
+		// 9. Swaps. Sets is_mainchain=false on the atomic swap spends in the
+		// tip block.
+		if _, err = UpdateSwapsMainchain(pgb.db, tipHash, false); err != nil {
+			log.Errorf("Failed to set swaps in block %s as sidechain: %v",
+				tipHash, err)
+		}
+
+		// 10. Block data. Keep the full orphaned block so that its transactions
+		// can still be inspected.
+		pgb.storeOrphanedBlock(tipHash)
+
 		// move on to next block
+		orphanedHash := tipHash
 		tipHash = previousHash
 
 		pgb.bestBlock.mtx.Lock()
@@ -3551,12 +4961,22 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 		}
 		pgb.bestBlock.hash = tipHash
 		pgb.bestBlock.mtx.Unlock()
+
+		// 11. Address cache. Purge the data for the addresses in the orphaned
+		// block, keeping the data for the others valid at the new tip.
+		pgb.disconnectAddressCaches(orphanedHash, cache.NewBlockID(pgb.BestBlock()))
 	}
 
 	log.Debugf("Reorg orphaned: %d blocks, %d txns, %d vins, %d addresses, %d votes, %d tickets",
 		blocksMoved, txnsUpdated, vinsUpdated, addrsUpdated, votesUpdated, ticketsUpdated)
 
-	return tipHash, blocksMoved, nil
+	// The spending info for the orphaned blocks' replacements must be
+	// populated by the next incremental address spending info update.
+	if err := lowerAddressSpendInfoHeight(pgb.db, pgb.Height()); err != nil {
+		log.Errorf("Failed to lower address spending info height: %v", err)
+	}
+
+	return tipHash, blocksMoved, txnsUpdated, nil
 }
 
 // StoreBlock processes the input wire.MsgBlock, and saves to the data tables.
@@ -3593,6 +5013,22 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 	// Convert the wire.MsgBlock to a dbtypes.Block.
 	dbBlock := dbtypes.MsgBlockToDBBlock(msgBlock, pgb.chainParams, chainWork, winningTickets)
 
+	// Keep the full data of side chain blocks, which dcrd may not serve.
+	if !isMainchain {
+		if errSide := InsertSideChainBlock(pgb.db, msgBlock); errSide != nil {
+			log.Errorf("Failed to store side chain block %s: %v", dbBlock.Hash, errSide)
+		}
+	}
+
+	// Record the block's output script types and nonstandard outputs.
+	scriptTypes, nonstandard := dbtypes.ExtractScriptTypes(msgBlock)
+	errScripts := InsertScriptTypes(pgb.db, dbBlock.Hash, msgBlock.Header.Timestamp,
+		scriptTypes, nonstandard)
+	if errScripts != nil {
+		log.Errorf("Failed to store the output script types of block %s: %v",
+			dbBlock.Hash, errScripts)
+	}
+
 	// Get the previous winners (stake DB pool info cache has this info). If the
 	// previous block is side chain, stakedb will not have the
 	// winners/validators. Since Validators are only used to identify misses in
@@ -3757,9 +5193,15 @@ func (pgb *ChainDB) StoreBlock(msgBlock *wire.MsgBlock, isValid, isMainchain,
 	}
 
 	// If not in batch sync, lazy update the dev fund balance, and expire cache
-	// data for the affected addresses.
+	// data for the affected addresses. The cached data for the other addresses
+	// remains valid at a new mainchain block.
 	if !pgb.InBatchSync {
-		if err = pgb.FreshenAddressCaches(true, addresses); err != nil {
+		if isMainchain {
+			pgb.connectAddressCaches(msgBlock, addresses)
+			if err = pgb.prefetchProjectFund(true); err != nil {
+				log.Warnf("prefetchProjectFund: %v", err)
+			}
+		} else if err = pgb.FreshenAddressCaches(true, addresses); err != nil {
 			log.Warnf("FreshenAddressCaches: %v", err)
 		}
 	}
@@ -3907,6 +5349,15 @@ type MsgBlockPG struct {
 // is returned in txDbIDs []uint64.
 func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins []dbtypes.VinTxPropertyARRAY,
 	updateExistingRecords bool) (dbAddressRows [][]dbtypes.AddressRow, txDbIDs []uint64, totalAddressRows, numOuts, numIns int, err error) {
+	// The partitions for the block's vins and vouts must exist before the
+	// inserts.
+	if len(txns) > 0 {
+		if err = pgb.ensurePartitions(txns[0].BlockHeight); err != nil {
+			err = fmt.Errorf("failed to create the vins and vouts partitions: %v", err)
+			return
+		}
+	}
+
 	// vins, vouts, and transactions inserts in atomic DB transaction
 	var dbTx *sql.Tx
 	dbTx, err = pgb.db.Begin()
@@ -3918,7 +5369,7 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	checked, doUpsert := pgb.dupChecks, updateExistingRecords
 
 	var voutStmt *sql.Stmt
-	voutStmt, err = dbTx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert))
+	voutStmt, err = dbTx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert, pgb.partitioned))
 	if err != nil {
 		_ = dbTx.Rollback()
 		err = fmt.Errorf("failed to prepare vout insert statement: %v", err)
@@ -3927,7 +5378,7 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	defer voutStmt.Close()
 
 	var vinStmt *sql.Stmt
-	vinStmt, err = dbTx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert))
+	vinStmt, err = dbTx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert, pgb.partitioned))
 	if err != nil {
 		_ = dbTx.Rollback()
 		err = fmt.Errorf("failed to prepare vin insert statement: %v", err)
@@ -3939,15 +5390,46 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 	// [tx_i][addr_j], transactions paying to different numbers of addresses.
 	dbAddressRows = make([][]dbtypes.AddressRow, len(txns))
 
+	// Without the unique indexes, large numbers of vins and vouts are written
+	// with COPY rather than an INSERT for each row.
+	var copiedVoutIDs, copiedVinIDs [][]uint64
+	if !checked && pgb.copyThreshold > 0 {
+		var numVouts, numVins int
+		for it := range txns {
+			numVouts += len(vouts[it])
+			numVins += len(vins[it])
+		}
+		if numVouts >= pgb.copyThreshold {
+			copiedVoutIDs, dbAddressRows, err = CopyVoutsDbTxn(dbTx, vouts, pgb.voutBatchSize)
+			if err != nil {
+				err = fmt.Errorf("failure in CopyVoutsDbTxn: %v", err)
+				_ = dbTx.Rollback()
+				return
+			}
+		}
+		if numVins >= pgb.copyThreshold {
+			copiedVinIDs, err = CopyVinsDbTxn(dbTx, vins, pgb.vinBatchSize)
+			if err != nil {
+				err = fmt.Errorf("failure in CopyVinsDbTxn: %v", err)
+				_ = dbTx.Rollback()
+				return
+			}
+		}
+	}
+
 	for it, Tx := range txns {
 		// Insert vouts, and collect AddressRows to add to address table for
 		// each output.
-		Tx.VoutDbIds, dbAddressRows[it], err = InsertVoutsStmt(voutStmt,
-			vouts[it], pgb.dupChecks, updateExistingRecords)
-		if err != nil && err != sql.ErrNoRows {
-			err = fmt.Errorf("failure in InsertVoutsStmt: %v", err)
-			_ = dbTx.Rollback()
-			return
+		if copiedVoutIDs != nil {
+			Tx.VoutDbIds = copiedVoutIDs[it]
+		} else {
+			Tx.VoutDbIds, dbAddressRows[it], err = InsertVoutsStmt(voutStmt,
+				vouts[it], pgb.dupChecks, updateExistingRecords)
+			if err != nil && err != sql.ErrNoRows {
+				err = fmt.Errorf("failure in InsertVoutsStmt: %v", err)
+				_ = dbTx.Rollback()
+				return
+			}
 		}
 		totalAddressRows += len(dbAddressRows[it])
 		numOuts += len(Tx.VoutDbIds)
@@ -3956,12 +5438,16 @@ func (pgb *ChainDB) storeTxns(txns []*dbtypes.Tx, vouts [][]*dbtypes.Vout, vins
 		}
 
 		// Insert vins
-		Tx.VinDbIds, err = InsertVinsStmt(vinStmt, vins[it], pgb.dupChecks,
-			updateExistingRecords)
-		if err != nil && err != sql.ErrNoRows {
-			err = fmt.Errorf("failure in InsertVinsStmt: %v", err)
-			_ = dbTx.Rollback()
-			return
+		if copiedVinIDs != nil {
+			Tx.VinDbIds = copiedVinIDs[it]
+		} else {
+			Tx.VinDbIds, err = InsertVinsStmt(vinStmt, vins[it], pgb.dupChecks,
+				updateExistingRecords)
+			if err != nil && err != sql.ErrNoRows {
+				err = fmt.Errorf("failure in InsertVinsStmt: %v", err)
+				_ = dbTx.Rollback()
+				return
+			}
 		}
 		numIns += len(Tx.VinDbIds)
 
@@ -4070,6 +5556,15 @@ txns:
 		txDbIDs:  txDbIDs,
 	}
 
+	// Atomic swap redemptions and refunds in the regular transactions.
+	if txTree == wire.TxTreeRegular && isValid {
+		if err = InsertSwaps(pgb.db, msgBlock.MsgBlock, chainParams, isMainchain); err != nil {
+			log.Error("InsertSwaps:", err)
+			txRes.err = err
+			return txRes
+		}
+	}
+
 	// Flatten the address rows into a single slice, and update the utxoCache
 	// (again, now that vin DB IDs are set in dbTransactions).
 	var dbAddressRowsFlat []*dbtypes.AddressRow
@@ -4136,6 +5631,13 @@ txns:
 			return txRes
 		}
 
+		// Treasury adds, spends, and treasurybases, and the TSpend votes.
+		if err = InsertTreasuryTxns(pgb.db, msgBlock.MsgBlock, isMainchain); err != nil {
+			log.Error("InsertTreasuryTxns:", err)
+			txRes.err = err
+			return txRes
+		}
+
 		if updateTicketsSpendingInfo {
 			// Get information for transactions spending tickets (votes and
 			// revokes), and the ticket DB row IDs themselves. Also return
@@ -4511,6 +6013,31 @@ func (pgb *ChainDB) CollectTicketSpendDBInfo(dbTxns []*dbtypes.Tx, txDbIDs []uin
 // but much more slowly for a number of reasons (that are well worth
 // investigating BTW!).
 func (pgb *ChainDB) UpdateSpendingInfoInAllAddresses(barLoad chan *dbtypes.ProgressBarLoad) (int64, error) {
+	return pgb.updateSpendingInfoInAddresses(0, barLoad)
+}
+
+// UpdateSpendingInfoInNewAddresses populates the matching transaction columns
+// for the funding rows of the addresses table that were spent in the blocks
+// added since the last run of UpdateSpendingInfoInAllAddresses or
+// UpdateSpendingInfoInNewAddresses. This allows periodic catch-up runs when
+// the spending info is not updated as blocks are stored. The spending
+// transaction's block height is tracked rather than the funding row, since
+// outputs funded before the last run may be spent by the new blocks. If the
+// spending info has never been populated in bulk, all rows are updated.
+func (pgb *ChainDB) UpdateSpendingInfoInNewAddresses(barLoad chan *dbtypes.ProgressBarLoad) (int64, error) {
+	lastHeight, err := RetrieveAddressSpendInfoHeight(pgb.db)
+	if err != nil {
+		return 0, fmt.Errorf("RetrieveAddressSpendInfoHeight: %v", err)
+	}
+	return pgb.updateSpendingInfoInAddresses(lastHeight+1, barLoad)
+}
+
+// updateSpendingInfoInAddresses populates the matching transaction columns for
+// the funding rows of the addresses table that are spent in the blocks from
+// startHeight to the best block, in chunks of blocks. The height of the last
+// block of each completed chunk is recorded, so an interrupted update may be
+// resumed with UpdateSpendingInfoInNewAddresses.
+func (pgb *ChainDB) updateSpendingInfoInAddresses(startHeight int64, barLoad chan *dbtypes.ProgressBarLoad) (int64, error) {
 	heightDB, err := pgb.HeightDB()
 	if err != nil {
 		return 0, fmt.Errorf("DBBestBlock: %v", err)
@@ -4520,7 +6047,7 @@ func (pgb *ChainDB) UpdateSpendingInfoInAllAddresses(barLoad chan *dbtypes.Progr
 
 	chunk := int64(10000)
 	var rowsTouched int64
-	for i := int64(0); i <= heightDB; i += chunk {
+	for i := startHeight; i <= heightDB; i += chunk {
 		end := i + chunk
 		if end > heightDB+1 {
 			end = heightDB + 1
@@ -4536,6 +6063,10 @@ func (pgb *ChainDB) UpdateSpendingInfoInAllAddresses(barLoad chan *dbtypes.Progr
 		}
 		rowsTouched += N
 
+		if err = SetAddressSpendInfoHeight(pgb.db, end-1); err != nil {
+			return rowsTouched, err
+		}
+
 		if barLoad != nil {
 			timeTakenPerBlock := (time.Since(tStart).Seconds() / float64(end-i))
 			barLoad <- &dbtypes.ProgressBarLoad{
@@ -4843,6 +6374,133 @@ func (pgb *ChainDB) CurrentCoinSupply() (supply *apitypes.CoinSupply) {
 	}
 }
 
+// CirculatingSupply returns the atoms minted in the main chain as of the best
+// block, which is returned with the supply. The supply is cached until the
+// best block changes.
+func (pgb *ChainDB) CirculatingSupply() (supply int64, hash string, height int64, err error) {
+	hash, height = pgb.BestBlockStr()
+
+	pgb.circulatingSupply.Lock()
+	defer pgb.circulatingSupply.Unlock()
+	if pgb.circulatingSupply.hash == hash {
+		return pgb.circulatingSupply.supply, hash, height, nil
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	supply, err = RetrieveCirculatingSupply(ctx, pgb.db)
+	if err != nil {
+		return 0, "", 0, pgb.replaceCancelError(err)
+	}
+	pgb.circulatingSupply.hash = hash
+	pgb.circulatingSupply.supply = supply
+	return supply, hash, height, nil
+}
+
+// checkUTXOSetHeight verifies that the UTXO set after the mainchain block at
+// the height can be retrieved. In pruning mode, the outputs spent below the
+// pruned height are deleted, and sql.ErrNoRows is returned for such heights.
+func (pgb *ChainDB) checkUTXOSetHeight(height int64) error {
+	if _, bestHeight := pgb.BestBlockStr(); height < 0 || height > bestHeight {
+		return fmt.Errorf("invalid height %d, best block height is %d", height, bestHeight)
+	}
+	prunedHeight, err := pgb.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	if height < prunedHeight {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UTXOSetStats counts and sums the UTXO set after the mainchain block at the
+// height, in total and for each script type. A negative height selects the
+// best block, for which the statistics are cached. sql.ErrNoRows is returned
+// if the outputs at the height have been pruned.
+func (pgb *ChainDB) UTXOSetStats(height int64) (*apitypes.UTXOSetStats, error) {
+	hash, bestHeight := pgb.BestBlockStr()
+	if height < 0 || height == bestHeight {
+		pgb.utxoSetStats.Lock()
+		defer pgb.utxoSetStats.Unlock()
+		if pgb.utxoSetStats.hash == hash {
+			return pgb.utxoSetStats.stats, nil
+		}
+		height = bestHeight
+	}
+	if err := pgb.checkUTXOSetHeight(height); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	if height != bestHeight {
+		stats, err := RetrieveUTXOSetStats(ctx, pgb.readDB(), height)
+		return stats, pgb.replaceCancelError(err)
+	}
+	// Replicas may lag, so the cached best block statistics are from the
+	// primary.
+	stats, err := RetrieveUTXOSetStats(ctx, pgb.db, height)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	pgb.utxoSetStats.hash = hash
+	pgb.utxoSetStats.stats = stats
+	return stats, nil
+}
+
+// ForEachUTXO calls f with each output of the UTXO set after the mainchain
+// block at the height, in order of the funding transaction's height. The entry
+// passed to f is reused, and must not be retained. Since the UTXO set is large,
+// there is no query timeout. sql.ErrNoRows is returned if the outputs at the
+// height have been pruned.
+func (pgb *ChainDB) ForEachUTXO(height int64, f func(*dbtypes.UTXOSetEntry) error) error {
+	if err := pgb.checkUTXOSetHeight(height); err != nil {
+		return err
+	}
+	return ForEachUTXO(pgb.ctx, pgb.db, height, f)
+}
+
+// SupplySchedule computes the block subsidy schedule from startHeight to
+// endHeight, with the supply projected from the circulating supply as of the
+// best block for the ranges ending after the best block.
+func (pgb *ChainDB) SupplySchedule(startHeight, endHeight int64) (*apitypes.SupplySchedule, error) {
+	circulating, hash, height, err := pgb.CirculatingSupply()
+	if err != nil {
+		return nil, err
+	}
+
+	// The shortfall of the circulating supply from the maximum supply, due to
+	// missed votes and disapproved blocks, carries over to the projections.
+	var shortfall int64
+	if current := txhelpers.SubsidySchedule(pgb.chainParams, height, height); len(current) > 0 {
+		shortfall = current[0].Supply - circulating
+	}
+
+	schedule := &apitypes.SupplySchedule{
+		Height:      height,
+		Hash:        hash,
+		Circulating: circulating,
+		Ultimate:    txhelpers.UltimateSubsidy(pgb.chainParams),
+		Ranges:      []apitypes.SubsidyRangeSplit{},
+	}
+	for _, r := range txhelpers.SubsidySchedule(pgb.chainParams, startHeight, endHeight) {
+		split := apitypes.SubsidyRangeSplit{
+			StartHeight: r.StartHeight,
+			EndHeight:   r.EndHeight,
+			PoW:         r.Work,
+			PoS:         r.Stake,
+			Treasury:    r.Treasury,
+			MaxSupply:   r.Supply,
+		}
+		if r.EndHeight > height {
+			split.ProjectedSupply = r.Supply - shortfall
+		}
+		schedule.Ranges = append(schedule.Ranges, split)
+	}
+	return schedule, nil
+}
+
 // GetBlockByHash gets a *wire.MsgBlock for the supplied hex-encoded hash
 // string.
 func (pgb *ChainDB) GetBlockByHash(hash string) (*wire.MsgBlock, error) {
@@ -4871,6 +6529,21 @@ func (pgb *ChainDB) GetBlockHeaderByHash(hash string) (*wire.BlockHeader, error)
 	return pgb.Client.GetBlockHeader(blockHash)
 }
 
+// RawTransaction gets the serialized transaction with the given ID from dcrd,
+// and the transaction's number of confirmations, which is 0 for mempool
+// transactions.
+func (pgb *ChainDB) RawTransaction(txid *chainhash.Hash) ([]byte, int64, error) {
+	txraw, err := pgb.Client.GetRawTransactionVerbose(txid)
+	if err != nil {
+		return nil, 0, err
+	}
+	txBytes, err := hex.DecodeString(txraw.Hex)
+	if err != nil {
+		return nil, 0, err
+	}
+	return txBytes, txraw.Confirmations, nil
+}
+
 // GetRawAPITransaction gets an *apitypes.Tx for a given transaction ID.
 func (pgb *ChainDB) GetRawAPITransaction(txid *chainhash.Hash) *apitypes.Tx {
 	tx, _ := pgb.getRawAPITransaction(txid)
@@ -6149,6 +7822,13 @@ func (pgb *ChainDB) CurrentDifficulty() (float64, error) {
 	return diff, nil
 }
 
+// EstimateStakeDiff gets the node's estimates of the next window's stake
+// difficulty. If tickets is non-nil, the User field of the result is the
+// estimate with that many more tickets purchased in the current window.
+func (pgb *ChainDB) EstimateStakeDiff(tickets *uint32) (*chainjson.EstimateStakeDiffResult, error) {
+	return pgb.Client.EstimateStakeDiff(tickets)
+}
+
 // Difficulty returns the difficulty for the first block mined after the
 // provided UNIX timestamp.
 func (pgb *ChainDB) Difficulty(timestamp int64) float64 {
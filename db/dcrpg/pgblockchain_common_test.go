@@ -8,13 +8,12 @@ import (
 	"os"
 	"runtime"
 	"testing"
-	"time"
 
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrdata/db/cache/v3"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
 	"github.com/decred/dcrdata/testutil/dbconfig/v2"
 	"github.com/decred/slog"
-	pitypes "github.com/dmigwi/go-piparser/proposals/types"
 )
 
 type MemStats runtime.MemStats
@@ -56,12 +55,12 @@ func (p *dummyParser) UpdateSignal() <-chan struct{} {
 	return make(chan struct{})
 }
 
-func (p *dummyParser) ProposalsHistory() ([]*pitypes.History, error) {
-	return []*pitypes.History{}, nil
+func (p *dummyParser) Proposals() ([]*dbtypes.PoliteiaProposal, error) {
+	return []*dbtypes.PoliteiaProposal{}, nil
 }
 
-func (p *dummyParser) ProposalsHistorySince(since time.Time) ([]*pitypes.History, error) {
-	return []*pitypes.History{}, nil
+func (p *dummyParser) CastVotes(token string) ([]dbtypes.ProposalCastVote, error) {
+	return []dbtypes.ProposalCastVote{}, nil
 }
 
 func openDB() (func() error, error) {
@@ -75,7 +74,7 @@ func openDB() (func() error, error) {
 	cfg := &ChainDBCfg{
 		dbi,
 		chaincfg.MainNetParams(),
-		true, false, 24, 1024, 1 << 16,
+		true, false, 24, 1024, 1 << 16, 0, 0, 0, false,
 	}
 	var err error
 	db, err = NewChainDB(cfg, nil, nil, new(dummyParser), nil, func() {})
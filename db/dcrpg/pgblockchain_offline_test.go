@@ -2,7 +2,11 @@ package dcrpg
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
 )
 
 func TestIsRetryError(t *testing.T) {
@@ -23,3 +27,111 @@ func TestIsRetryError(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderTablesForDrop(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  []string
+		want    []string
+		wantErr bool
+	}{
+		{"reverse creation order", []string{"tickets", "addresses", "votes"},
+			[]string{"votes", "tickets", "addresses"}, false},
+		{"duplicates", []string{"misses", "misses"}, []string{"misses"}, false},
+		{"none", nil, []string{}, false},
+		{"unknown", []string{"addresses", "address"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderTablesForDrop(tt.tables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("orderTablesForDrop() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderTablesForDrop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDCRPricesFiatValues(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+	prices := dcrPrices{
+		{Day: day(1), Price: 10},
+		{Day: day(2), Price: 20},
+		{Day: day(3), Price: 30},
+		{Day: day(10), Price: 100},
+	}
+	timeDefs := func(times ...time.Time) []dbtypes.TimeDef {
+		defs := make([]dbtypes.TimeDef, 0, len(times))
+		for _, t := range times {
+			defs = append(defs, dbtypes.NewTimeDef(t))
+		}
+		return defs
+	}
+	value := func(v float64) *float64 { return &v }
+	amounts := []float64{1, 2, 3}
+
+	// Each day is valued with its closing price. There is no price within a
+	// day of the end of the 5th.
+	got := prices.fiatValues(dbtypes.DayGrouping, timeDefs(day(1), day(2), day(5)), amounts)
+	want := []*float64{value(10), value(40), nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("day grouping: got %v, want %v", got, want)
+	}
+
+	// Each block is valued with the closing price of its day.
+	got = prices.fiatValues(dbtypes.AllGrouping, timeDefs(day(1).Add(time.Hour),
+		day(3).Add(23*time.Hour), day(8)), amounts)
+	want = []*float64{value(10), value(60), nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("all grouping: got %v, want %v", got, want)
+	}
+}
+
+func TestAddressTags(t *testing.T) {
+	pgb := new(ChainDB)
+	pgb.addressTags.tags = map[string]*dbtypes.AddressTag{
+		"Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk": {Address: "Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk",
+			Label: "VSP fees", Category: "vsp"},
+		"DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87": {Address: "DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87",
+			Label: "Exchange B", Category: "exchange"},
+		"DsZQaCQES5vh3JmcyyFokJYz3aSw8Sm1dsQ": {Address: "DsZQaCQES5vh3JmcyyFokJYz3aSw8Sm1dsQ",
+			Label: "Exchange A", Category: "exchange"},
+		"Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx": {Address: "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx",
+			Label: "Exchange A", Category: "exchange"},
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		want     []string
+	}{
+		{"all", "", []string{"Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx",
+			"DsZQaCQES5vh3JmcyyFokJYz3aSw8Sm1dsQ", "DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87",
+			"Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk"}},
+		{"category", "vsp", []string{"Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk"}},
+		{"unknown category", "treasury", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0, len(tt.want))
+			for _, tag := range pgb.AddressTags(tt.category) {
+				got = append(got, tag.Address)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if tag := pgb.AddressTag("DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87"); tag == nil ||
+		tag.Label != "Exchange B" {
+		t.Errorf("AddressTag() = %v, want Exchange B", tag)
+	}
+	if tag := pgb.AddressTag("DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC"); tag != nil {
+		t.Errorf("AddressTag() = %v, want nil", tag)
+	}
+}
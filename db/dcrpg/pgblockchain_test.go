@@ -4,8 +4,12 @@ package dcrpg
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -14,6 +18,8 @@ import (
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrd/dcrutil/v2"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
 	"github.com/decred/dcrdata/db/cache/v3"
 	"github.com/decred/dcrdata/db/dbtypes/v2"
 )
@@ -348,3 +354,801 @@ func TestUpdateChainState(t *testing.T) {
 		t.Fatalf("expected both payloads to match but the did not")
 	}
 }
+
+func TestSyncState(t *testing.T) {
+	// Restore any sync state recorded before the test.
+	prevState, err := db.SyncState()
+	if err != nil {
+		t.Fatalf("SyncState failed: %v", err)
+	}
+	defer func() {
+		if prevState == nil {
+			_ = db.ClearSyncState()
+			return
+		}
+		_ = db.SetSyncState(prevState.Phase, prevState.Height, prevState.BulkReindex)
+	}()
+
+	if err = db.ClearSyncState(); err != nil {
+		t.Fatalf("ClearSyncState failed: %v", err)
+	}
+	state, err := db.SyncState()
+	if err != nil {
+		t.Fatalf("SyncState failed: %v", err)
+	}
+	if state != nil {
+		t.Fatalf("expected no sync state after clearing, got %v", state)
+	}
+
+	// Each phase replaces the previously recorded one.
+	tests := []struct {
+		phase       string
+		height      int64
+		bulkReindex bool
+	}{
+		{"deindexed", 1000, true},
+		{"bulk_loading", 25000, true},
+		{"reindexing", 40000, true},
+		{"spend_update", 40000, false},
+	}
+
+	for _, test := range tests {
+		if err = db.SetSyncState(test.phase, test.height, test.bulkReindex); err != nil {
+			t.Fatalf("SetSyncState(%q) failed: %v", test.phase, err)
+		}
+		state, err = db.SyncState()
+		if err != nil {
+			t.Fatalf("SyncState failed: %v", err)
+		}
+		if state == nil {
+			t.Fatalf("no sync state recorded for phase %q", test.phase)
+		}
+		if state.Phase != test.phase || state.Height != test.height ||
+			state.BulkReindex != test.bulkReindex {
+			t.Errorf("expected sync state %q at %d (bulk reindex %v), got %q at %d (%v)",
+				test.phase, test.height, test.bulkReindex, state.Phase, state.Height,
+				state.BulkReindex)
+		}
+		if state.UpdatedAt.IsZero() {
+			t.Errorf("phase %q has no update time", test.phase)
+		}
+	}
+
+	if err = db.ClearSyncState(); err != nil {
+		t.Fatalf("ClearSyncState failed: %v", err)
+	}
+	if state, err = db.SyncState(); err != nil || state != nil {
+		t.Errorf("expected no sync state after clearing, got %v (%v)", state, err)
+	}
+}
+
+func TestAddressBalanceAtHeight(t *testing.T) {
+	address := "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx"
+	rows, err := db.AddressRowsCompact(address)
+	if err != nil {
+		t.Fatalf("AddressRowsCompact failed: %v", err)
+	}
+	bestHeight, err := db.HeightDB()
+	if err != nil {
+		t.Fatalf("HeightDB failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		height  int64
+		wantErr error
+	}{
+		{"genesis", 0, nil},
+		{"middle", bestHeight / 2, nil},
+		{"best", bestHeight, nil},
+		{"above best", bestHeight + 1, sql.ErrNoRows},
+	}
+
+	for _, test := range tests {
+		balance, err := db.AddressBalanceAtHeight(address, test.height)
+		if err != test.wantErr {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.wantErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		// The balance is the valid mainchain funding less spending up to the
+		// block time.
+		blockTime, err := db.BlockTimeByHeight(test.height)
+		if err != nil {
+			t.Fatalf("BlockTimeByHeight failed: %v", err)
+		}
+		var want int64
+		for _, r := range rows {
+			if !r.ValidMainChain || r.TxBlockTime > blockTime {
+				continue
+			}
+			if r.IsFunding {
+				want += int64(r.Value)
+			} else {
+				want -= int64(r.Value)
+			}
+		}
+		if balance != want {
+			t.Errorf("%s: expected balance %d at height %d, got %d", test.name,
+				want, test.height, balance)
+		}
+
+		// Once materialized, the balance is unchanged.
+		again, err := db.AddressBalanceAtHeight(address, test.height)
+		if err != nil || again != balance {
+			t.Errorf("%s: expected the same balance %d, got %d (%v)", test.name,
+				balance, again, err)
+		}
+	}
+}
+
+func TestAddressSpendInfoHeight(t *testing.T) {
+	// Restore any height recorded before the test.
+	prevHeight, err := RetrieveAddressSpendInfoHeight(db.db)
+	if err != nil {
+		t.Fatalf("RetrieveAddressSpendInfoHeight failed: %v", err)
+	}
+	defer func() {
+		if prevHeight < 0 {
+			_, _ = db.db.Exec(`DELETE FROM address_spend_info;`)
+			return
+		}
+		_ = SetAddressSpendInfoHeight(db.db, prevHeight)
+	}()
+
+	if _, err = db.db.Exec(`DELETE FROM address_spend_info;`); err != nil {
+		t.Fatalf("failed to clear the address_spend_info table: %v", err)
+	}
+	height, err := RetrieveAddressSpendInfoHeight(db.db)
+	if err != nil || height != -1 {
+		t.Fatalf("expected height -1 before the first update, got %d (%v)", height, err)
+	}
+
+	// The height is only ever lowered by a removed or orphaned block.
+	tests := []struct {
+		name  string
+		set   func() error
+		wantH int64
+	}{
+		{"set", func() error { return SetAddressSpendInfoHeight(db.db, 100) }, 100},
+		{"raise", func() error { return SetAddressSpendInfoHeight(db.db, 200) }, 200},
+		{"lower above", func() error { return lowerAddressSpendInfoHeight(db.db, 250) }, 200},
+		{"lower below", func() error { return lowerAddressSpendInfoHeight(db.db, 150) }, 150},
+	}
+	for _, test := range tests {
+		if err = test.set(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		height, err = RetrieveAddressSpendInfoHeight(db.db)
+		if err != nil {
+			t.Fatalf("%s: RetrieveAddressSpendInfoHeight failed: %v", test.name, err)
+		}
+		if height != test.wantH {
+			t.Errorf("%s: expected height %d, got %d", test.name, test.wantH, height)
+		}
+	}
+
+	// With the spending info populated to the best block, the incremental
+	// update has no blocks to process.
+	bestHeight, err := db.HeightDB()
+	if err != nil {
+		t.Fatalf("HeightDB failed: %v", err)
+	}
+	if err = SetAddressSpendInfoHeight(db.db, bestHeight); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.UpdateSpendingInfoInNewAddresses(nil)
+	if err != nil || rows != 0 {
+		t.Errorf("expected no rows updated, got %d (%v)", rows, err)
+	}
+	if height, err = RetrieveAddressSpendInfoHeight(db.db); err != nil || height != bestHeight {
+		t.Errorf("expected height %d, got %d (%v)", bestHeight, height, err)
+	}
+}
+
+func TestChainReorgs(t *testing.T) {
+	defer db.db.Exec(`DELETE FROM reorgs WHERE old_tip_hash LIKE 'reorgtest%';`)
+
+	before, err := db.ChainReorgs(1, 0)
+	if err != nil {
+		t.Fatalf("ChainReorgs failed: %v", err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		err = InsertReorg(db.db, &dbtypes.Reorg{
+			OldTipHash:     fmt.Sprintf("reorgtest%d", i),
+			OldTipHeight:   100 * i,
+			NewTipHash:     fmt.Sprintf("reorgtest%d_new", i),
+			NewTipHeight:   100*i + 1,
+			CommonAncestor: fmt.Sprintf("reorgtest%d_root", i),
+			Depth:          i,
+			OrphanedTxns:   10 * i,
+			Time:           dbtypes.NewTimeDef(time.Unix(trefUNIX+i, 0)),
+		})
+		if err != nil {
+			t.Fatalf("InsertReorg failed: %v", err)
+		}
+	}
+
+	// The most recent reorgs are first.
+	page, err := db.ChainReorgs(2, 1)
+	if err != nil {
+		t.Fatalf("ChainReorgs failed: %v", err)
+	}
+	if page.Total != before.Total+3 {
+		t.Errorf("expected %d reorgs, got %d", before.Total+3, page.Total)
+	}
+	if len(page.Reorgs) != 2 {
+		t.Fatalf("expected 2 reorgs, got %d", len(page.Reorgs))
+	}
+	for i, want := range []int64{2, 1} {
+		r := page.Reorgs[i]
+		if r.Depth != want || r.OldTipHeight != 100*want || r.OrphanedTxns != 10*want ||
+			r.CommonAncestor != fmt.Sprintf("reorgtest%d_root", want) {
+			t.Errorf("expected reorg %d, got %+v", want, r)
+		}
+		if r.Time.UNIX() != trefUNIX+want {
+			t.Errorf("expected reorg time %d, got %d", trefUNIX+want, r.Time.UNIX())
+		}
+	}
+}
+
+func TestRichList(t *testing.T) {
+	if err := db.UpdateRichList(10); err != nil {
+		t.Fatalf("UpdateRichList failed: %v", err)
+	}
+	richList, err := db.RichList(5)
+	if err != nil {
+		t.Fatalf("RichList failed: %v", err)
+	}
+	if richList.Height != db.Height() {
+		t.Errorf("expected height %d, got %d", db.Height(), richList.Height)
+	}
+
+	// The addresses are ranked by decreasing balance.
+	if len(richList.Addresses) == 0 || len(richList.Addresses) > 5 {
+		t.Fatalf("expected 1 to 5 addresses, got %d", len(richList.Addresses))
+	}
+	for i, a := range richList.Addresses {
+		if a.Rank != i+1 {
+			t.Errorf("expected rank %d, got %d", i+1, a.Rank)
+		}
+		if i > 0 && a.Balance > richList.Addresses[i-1].Balance {
+			t.Errorf("address %d balance %v above the previous %v", i, a.Balance,
+				richList.Addresses[i-1].Balance)
+		}
+	}
+
+	// The distribution has a range for each minimum balance held by any
+	// address, in order, and every address of the rich list is counted.
+	mins := make(map[float64]bool, len(balanceDistributionMins))
+	for _, min := range balanceDistributionMins {
+		mins[dcrutil.Amount(min).ToCoin()] = true
+	}
+	var numAddresses int64
+	for i, r := range richList.Distribution {
+		if !mins[r.MinBalance] {
+			t.Errorf("range %d: unexpected minimum balance %v", i, r.MinBalance)
+		}
+		if i > 0 && r.MinBalance <= richList.Distribution[i-1].MinBalance {
+			t.Errorf("range %d: minimum balance %v not above the previous %v", i,
+				r.MinBalance, richList.Distribution[i-1].MinBalance)
+		}
+		numAddresses += r.NumAddresses
+	}
+	if numAddresses < int64(len(richList.Addresses)) {
+		t.Errorf("expected at least %d addresses in the distribution, got %d",
+			len(richList.Addresses), numAddresses)
+	}
+}
+
+func TestSideChainBlockStore(t *testing.T) {
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Height:    12,
+			Timestamp: time.Unix(trefUNIX, 0),
+			Nonce:     0xdcda7a,
+		},
+	}
+	hash := msgBlock.BlockHash().String()
+	defer func() {
+		if _, err := db.db.Exec(`DELETE FROM side_chain_blocks WHERE hash = $1;`,
+			hash); err != nil {
+			t.Errorf("failed to delete the side chain block: %v", err)
+		}
+	}()
+
+	// Storing a block again does not fail.
+	for i := 0; i < 2; i++ {
+		if err := InsertSideChainBlock(db.db, msgBlock); err != nil {
+			t.Fatalf("InsertSideChainBlock failed: %v", err)
+		}
+	}
+	var height int64
+	var raw []byte
+	err := db.db.QueryRow(`SELECT height, raw FROM side_chain_blocks WHERE hash = $1;`,
+		hash).Scan(&height, &raw)
+	if err != nil {
+		t.Fatalf("failed to select the side chain block: %v", err)
+	}
+	if height != int64(msgBlock.Header.Height) {
+		t.Errorf("expected height %d, got %d", msgBlock.Header.Height, height)
+	}
+	var stored wire.MsgBlock
+	if err = stored.FromBytes(raw); err != nil {
+		t.Fatalf("invalid stored block: %v", err)
+	}
+	if stored.BlockHash().String() != hash {
+		t.Errorf("expected block %s, got %s", hash, stored.BlockHash())
+	}
+
+	// The block data is only served for blocks in the blocks table that are
+	// not on the main chain.
+	_, _, err = RetrieveSideChainBlock(context.Background(), db.db, hash)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a block not in the blocks table, got %v", err)
+	}
+}
+
+func TestAgendaVoteIntervals(t *testing.T) {
+	const agendaID = "intervaltest"
+	if db.Height() < 7 {
+		t.Skipf("need at least 8 blocks, have %d", db.Height()+1)
+	}
+	defer func() {
+		if _, err := db.db.Exec(`DELETE FROM agenda_vote_intervals WHERE agenda_id = $1;`,
+			agendaID); err != nil {
+			t.Errorf("failed to delete the agenda vote intervals: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	_, found, err := retrieveAgendaVoteIntervalsLastStart(ctx, db.db, agendaID, 0, 7)
+	if err != nil || found {
+		t.Fatalf("expected no stored intervals, got found = %v (%v)", found, err)
+	}
+
+	// The interval in progress is updated when it is counted again.
+	for _, iv := range []struct{ start, end int64 }{{0, 3}, {4, 5}, {4, 7}} {
+		if err = upsertAgendaVoteInterval(ctx, db.db, agendaID, iv.start, iv.end); err != nil {
+			t.Fatalf("upsertAgendaVoteInterval(%d, %d) failed: %v", iv.start, iv.end, err)
+		}
+	}
+	lastStart, found, err := retrieveAgendaVoteIntervalsLastStart(ctx, db.db, agendaID, 0, 7)
+	if err != nil || !found || lastStart != 4 {
+		t.Fatalf("expected last interval start 4, got %d, found = %v (%v)",
+			lastStart, found, err)
+	}
+
+	// There are no votes for the agenda, so set the counts to check the
+	// cumulative counts.
+	_, err = db.db.Exec(`UPDATE agenda_vote_intervals SET yes = 2, abstain = 0,
+		no = 1, total = 3 WHERE agenda_id = $1;`, agendaID)
+	if err != nil {
+		t.Fatalf("failed to set the vote counts: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		start, end     int64
+		wantEnds       []int64
+		wantCumulative []apitypes.AgendaVoteCounts
+	}{
+		{"voting period", 0, 7, []int64{3, 7}, []apitypes.AgendaVoteCounts{
+			{Yes: 2, No: 1, Total: 3}, {Yes: 4, No: 2, Total: 6}}},
+		{"later voting period", 4, 7, []int64{7}, []apitypes.AgendaVoteCounts{
+			{Yes: 2, No: 1, Total: 3}}},
+		{"other voting period", 8, 15, nil, nil},
+	}
+
+	for _, test := range tests {
+		intervals, err := retrieveAgendaVoteIntervals(ctx, db.db, agendaID,
+			test.start, test.end)
+		if err != nil {
+			t.Fatalf("%s: retrieveAgendaVoteIntervals failed: %v", test.name, err)
+		}
+		if len(intervals) != len(test.wantEnds) {
+			t.Fatalf("%s: expected %d intervals, got %d", test.name,
+				len(test.wantEnds), len(intervals))
+		}
+		for i, iv := range intervals {
+			if iv.EndHeight != test.wantEnds[i] {
+				t.Errorf("%s: interval %d: expected end height %d, got %d", test.name,
+					i, test.wantEnds[i], iv.EndHeight)
+			}
+			if iv.Cumulative != test.wantCumulative[i] {
+				t.Errorf("%s: interval %d: expected cumulative votes %+v, got %+v",
+					test.name, i, test.wantCumulative[i], iv.Cumulative)
+			}
+		}
+	}
+}
+
+func TestTicketLifecycle(t *testing.T) {
+	var ticketHash string
+	err := db.db.QueryRow(`SELECT tx_hash FROM tickets
+		WHERE is_mainchain = TRUE ORDER BY block_height LIMIT 1;`).Scan(&ticketHash)
+	if err == sql.ErrNoRows {
+		t.Skip("no tickets in the test database")
+	}
+	if err != nil {
+		t.Fatalf("failed to select a ticket: %v", err)
+	}
+
+	lifecycle, err := db.TicketLifecycle(ticketHash)
+	if err != nil {
+		t.Fatalf("TicketLifecycle failed: %v", err)
+	}
+	params := db.chainParams
+	if lifecycle.TicketHash != ticketHash {
+		t.Errorf("expected ticket %s, got %s", ticketHash, lifecycle.TicketHash)
+	}
+	wantMaturity := lifecycle.PurchaseBlock.Height + uint32(params.TicketMaturity)
+	if lifecycle.MaturityHeight != wantMaturity {
+		t.Errorf("expected maturity height %d, got %d", wantMaturity,
+			lifecycle.MaturityHeight)
+	}
+	if lifecycle.ExpirationHeight != wantMaturity+params.TicketExpiry {
+		t.Errorf("expected expiration height %d, got %d",
+			wantMaturity+params.TicketExpiry, lifecycle.ExpirationHeight)
+	}
+	if lifecycle.Spend != nil {
+		if lifecycle.Spend.Block.Height <= lifecycle.PurchaseBlock.Height {
+			t.Errorf("spend height %d not after the purchase height %d",
+				lifecycle.Spend.Block.Height, lifecycle.PurchaseBlock.Height)
+		}
+		if lifecycle.SpendType != dbtypes.TicketVoted.String() && lifecycle.Reward != 0 {
+			t.Errorf("expected no reward for a %s ticket, got %v",
+				lifecycle.SpendType, lifecycle.Reward)
+		}
+	}
+	if lifecycle.DaysHeld < 0 {
+		t.Errorf("expected a positive number of days held, got %v", lifecycle.DaysHeld)
+	}
+
+	_, err = db.TicketLifecycle("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an unknown ticket, got %v", err)
+	}
+}
+
+func TestRetrieveTableChurn(t *testing.T) {
+	churn, err := retrieveTableChurn(context.Background(), db.db, MaintenanceTables)
+	if err != nil {
+		t.Fatalf("retrieveTableChurn failed: %v", err)
+	}
+	if len(churn) != len(MaintenanceTables) {
+		t.Fatalf("expected statistics of %d tables, got %d", len(MaintenanceTables),
+			len(churn))
+	}
+	for _, c := range churn {
+		if c.live < 0 || c.dead < 0 || c.modified < 0 {
+			t.Errorf("invalid statistics of table %s: %+v", c.table, c)
+		}
+	}
+
+	// Forced maintenance vacuums the table regardless of its dead tuples.
+	if err = db.vacuumTable(context.Background(), "vouts"); err != nil {
+		t.Errorf("vacuumTable failed: %v", err)
+	}
+}
+
+func TestUTXOSetStats(t *testing.T) {
+	_, height := db.BestBlockStr()
+	stats, err := db.UTXOSetStats(-1)
+	if err != nil {
+		t.Fatalf("UTXOSetStats failed: %v", err)
+	}
+	if stats.Height != height {
+		t.Errorf("expected height %d, got %d", height, stats.Height)
+	}
+
+	// The statistics agree with the outputs of the UTXO set.
+	var count, total int64
+	byType := make(map[string]int64)
+	err = db.ForEachUTXO(height, func(utxo *dbtypes.UTXOSetEntry) error {
+		count++
+		total += utxo.Value
+		byType[utxo.ScriptType]++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUTXO failed: %v", err)
+	}
+	if stats.Count != count || stats.TotalValue != dcrutil.Amount(total).ToCoin() {
+		t.Errorf("expected %d outputs of %v, got %d of %v DCR", count,
+			dcrutil.Amount(total), stats.Count, stats.TotalValue)
+	}
+	if len(stats.ScriptTypes) != len(byType) {
+		t.Errorf("expected %d script types, got %d", len(byType), len(stats.ScriptTypes))
+	}
+	for i, st := range stats.ScriptTypes {
+		if st.Count != byType[st.ScriptType] {
+			t.Errorf("expected %d %s outputs, got %d", byType[st.ScriptType],
+				st.ScriptType, st.Count)
+		}
+		if i > 0 && st.Value > stats.ScriptTypes[i-1].Value {
+			t.Errorf("script type %s is not ordered by value", st.ScriptType)
+		}
+	}
+
+	// The cached statistics of the best block are returned for its height.
+	if cached, err := db.UTXOSetStats(height); err != nil || cached != stats {
+		t.Errorf("expected the cached statistics, got %v (%v)", cached, err)
+	}
+	if _, err = db.UTXOSetStats(height + 1); err == nil {
+		t.Errorf("expected an error for a height above the best block")
+	}
+}
+
+func TestAddressTagsStore(t *testing.T) {
+	const vspAddr, exchangeAddr = "Dsi8hhDzr3SvcGcv4NEGvRqFkwZ2ncRhukk",
+		"DseXBL6g6GxvfYAnKqdao2f7WkXDmYTYW87"
+	cleanUp := func() {
+		_ = db.DeleteAddressTag(vspAddr)
+		_ = db.DeleteAddressTag(exchangeAddr)
+	}
+	cleanUp()
+	defer cleanUp()
+
+	dir, err := ioutil.TempDir("", "addresstags")
+	if err != nil {
+		t.Fatalf("TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "tags.json")
+
+	// Each import follows the previous one. The expected labels are given as
+	// "label (source)".
+	tests := []struct {
+		name       string
+		adminTag   *dbtypes.AddressTag // set with the admin API before the import
+		file       string
+		wantStored int64
+		wantTags   map[string]string
+	}{{
+		name: "import",
+		file: `[{"address": "` + vspAddr + `", "label": "VSP fees", "category": "vsp"},
+			{"address": "` + exchangeAddr + `", "label": "Exchange", "category": "exchange"},
+			{"address": "Dsxyz", "label": "Invalid", "category": "exchange"},
+			{"address": "DsZQaCQES5vh3JmcyyFokJYz3aSw8Sm1dsQ", "label": "", "category": "exchange"}]`,
+		wantStored: 2,
+		wantTags: map[string]string{
+			vspAddr:      "VSP fees (import)",
+			exchangeAddr: "Exchange (import)",
+		},
+	}, {
+		name:     "reimport keeps admin labels",
+		adminTag: &dbtypes.AddressTag{Address: vspAddr, Label: "Admin label", Category: "vsp"},
+		file: `[{"address": "` + vspAddr + `", "label": "VSP renamed", "category": "vsp"},
+			{"address": "` + exchangeAddr + `", "label": "Exchange renamed", "category": "exchange"}]`,
+		wantStored: 1,
+		wantTags: map[string]string{
+			vspAddr:      "Admin label (admin)",
+			exchangeAddr: "Exchange renamed (import)",
+		},
+	}}
+
+	for _, test := range tests {
+		if test.adminTag != nil {
+			if err = db.SetAddressTag(test.adminTag); err != nil {
+				t.Fatalf("%s: SetAddressTag failed: %v", test.name, err)
+			}
+		}
+		if err = ioutil.WriteFile(fileName, []byte(test.file), 0600); err != nil {
+			t.Fatalf("%s: WriteFile failed: %v", test.name, err)
+		}
+		numStored, err := db.ImportAddressTags(fileName)
+		if err != nil {
+			t.Fatalf("%s: ImportAddressTags failed: %v", test.name, err)
+		}
+		if numStored != test.wantStored {
+			t.Errorf("%s: expected %d labels stored, got %d", test.name, test.wantStored, numStored)
+		}
+
+		// The cache is reloaded from the DB after an import.
+		for address, want := range test.wantTags {
+			tag := db.AddressTag(address)
+			if tag == nil {
+				t.Errorf("%s: no label for %s", test.name, address)
+				continue
+			}
+			if got := fmt.Sprintf("%s (%s)", tag.Label, tag.Source); got != want {
+				t.Errorf("%s: expected label %s for %s, got %s", test.name, want, address, got)
+			}
+		}
+	}
+
+	if err = db.DeleteAddressTag(vspAddr); err != nil {
+		t.Errorf("DeleteAddressTag failed: %v", err)
+	}
+	if tag := db.AddressTag(vspAddr); tag != nil {
+		t.Errorf("expected no label after deletion, got %v", tag)
+	}
+	if err = db.DeleteAddressTag(vspAddr); err != sql.ErrNoRows {
+		t.Errorf("expected %v deleting a missing label, got %v", sql.ErrNoRows, err)
+	}
+}
+
+func TestTxGraph(t *testing.T) {
+	// A transaction spending a non-coinbase output has an ancestor.
+	var txid, prevTxID string
+	err := db.db.QueryRow(`SELECT tx_hash, prev_tx_hash FROM vins
+		WHERE is_mainchain AND prev_tx_hash != $1
+		ORDER BY id LIMIT 1;`, chainhash.Hash{}.String()).Scan(&txid, &prevTxID)
+	if err == sql.ErrNoRows {
+		t.Skip("no spent outputs in the test database")
+	}
+	if err != nil {
+		t.Fatalf("failed to select a transaction: %v", err)
+	}
+
+	const depth = 2
+	graph, err := db.TxGraph(txid, depth, 2000)
+	if err != nil {
+		t.Fatalf("TxGraph failed: %v", err)
+	}
+
+	nodes := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		if node.Depth < -depth || node.Depth > depth {
+			t.Errorf("node %s at depth %d beyond %d", node.TxID, node.Depth, depth)
+		}
+		if i > 0 && node.Depth < graph.Nodes[i-1].Depth {
+			t.Errorf("node %s is not ordered by depth", node.TxID)
+		}
+		nodes[node.TxID] = node.Depth
+	}
+	if d, found := nodes[txid]; !found || d != 0 {
+		t.Errorf("expected the transaction at depth 0, got %d (found = %v)", d, found)
+	}
+	if d, found := nodes[prevTxID]; !found || d != -1 {
+		t.Errorf("expected the funding transaction at depth -1, got %d (found = %v)", d, found)
+	}
+	if !graph.Truncated {
+		for _, edge := range graph.Edges {
+			_, fromFound := nodes[edge.From]
+			_, toFound := nodes[edge.To]
+			if !fromFound || !toFound {
+				t.Errorf("edge %s:%d -> %s has no node", edge.From, edge.Vout, edge.To)
+			}
+		}
+	}
+
+	// A single edge truncates the graph.
+	graph, err = db.TxGraph(txid, 1, 1)
+	if err != nil {
+		t.Fatalf("TxGraph failed: %v", err)
+	}
+	if !graph.Truncated {
+		t.Errorf("expected a truncated graph")
+	}
+
+	_, err = db.TxGraph("0000000000000000000000000000000000000000000000000000000000000001", 1, 10)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an unknown transaction, got %v", err)
+	}
+}
+
+func TestStakeROI(t *testing.T) {
+	amount := dcrutil.Amount(1000 * dcrutil.AtomsPerCoin)
+	roi, err := db.StakeROI(amount, 0)
+	if err != nil {
+		t.Fatalf("StakeROI failed: %v", err)
+	}
+
+	// The simulation starts no earlier than the stake enabled height.
+	params := db.chainParams
+	if roi.StartHeight != params.StakeEnabledHeight {
+		t.Errorf("expected start height %d, got %d", params.StakeEnabledHeight,
+			roi.StartHeight)
+	}
+	if roi.Amount != amount.ToCoin() {
+		t.Errorf("expected amount %v, got %v", amount.ToCoin(), roi.Amount)
+	}
+
+	balance := amount
+	nextPurchase := roi.StartHeight
+	for i, cycle := range roi.Cycles {
+		if cycle.PurchaseHeight < nextPurchase {
+			t.Errorf("cycle %d purchased at %d before %d", i, cycle.PurchaseHeight,
+				nextPurchase)
+		}
+		if cycle.VoteHeight < cycle.PurchaseHeight+int64(params.TicketMaturity) {
+			t.Errorf("cycle %d voted at %d before the ticket maturity", i,
+				cycle.VoteHeight)
+		}
+		price, _ := dcrutil.NewAmount(cycle.TicketPrice)
+		if cycle.Tickets != int64(balance/price) {
+			t.Errorf("cycle %d purchased %d tickets, expected %d", i, cycle.Tickets,
+				int64(balance/price))
+		}
+		reward, _ := dcrutil.NewAmount(cycle.VoteReward)
+		balance += dcrutil.Amount(cycle.Tickets) * reward
+		if cycle.Balance != balance.ToCoin() {
+			t.Errorf("cycle %d expected balance %v, got %v", i, balance.ToCoin(),
+				cycle.Balance)
+		}
+		nextPurchase = cycle.VoteHeight + int64(params.CoinbaseMaturity)
+	}
+	if roi.FinalAmount != balance.ToCoin() {
+		t.Errorf("expected final amount %v, got %v", balance.ToCoin(), roi.FinalAmount)
+	}
+	if roi.Reward != (balance - amount).ToCoin() {
+		t.Errorf("expected reward %v, got %v", (balance - amount).ToCoin(), roi.Reward)
+	}
+	if len(roi.Cycles) > 0 && roi.EndHeight != roi.Cycles[len(roi.Cycles)-1].VoteHeight {
+		t.Errorf("expected end height %d, got %d",
+			roi.Cycles[len(roi.Cycles)-1].VoteHeight, roi.EndHeight)
+	}
+
+	// An amount below the ticket price purchases no tickets.
+	roi, err = db.StakeROI(1, 0)
+	if err != nil {
+		t.Fatalf("StakeROI failed: %v", err)
+	}
+	if len(roi.Cycles) != 0 || roi.Pending != 0 || roi.ROI != 0 {
+		t.Errorf("expected no tickets, got %d cycles and %d pending",
+			len(roi.Cycles), roi.Pending)
+	}
+
+	_, err = db.StakeROI(amount, db.Height()+1)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows above the best block, got %v", err)
+	}
+}
+
+func TestProposalVoteSnapshots(t *testing.T) {
+	const token = "snapshottest"
+	defer func() {
+		_, err := db.db.Exec(`DELETE FROM proposal_vote_snapshots WHERE token = $1;`, token)
+		if err != nil {
+			t.Errorf("failed to delete the snapshots: %v", err)
+		}
+	}()
+
+	blockTime := time.Unix(trefUNIX, 0)
+	for _, snap := range []*dbtypes.ProposalVoteSnapshot{
+		{Token: token, Height: 12, Yes: 2, No: 2},
+		{Token: token, Height: 10, Yes: 1, No: 0},
+		// The snapshot at the same height replaces the earlier one.
+		{Token: token, Height: 12, Yes: 30, No: 10},
+	} {
+		snap.Time = dbtypes.NewTimeDef(blockTime)
+		snap.EligibleTickets = 200
+		snap.QuorumPercentage = 20
+		snap.PassPercentage = 60
+		if err := db.StoreProposalVoteSnapshot(snap); err != nil {
+			t.Fatalf("StoreProposalVoteSnapshot failed: %v", err)
+		}
+	}
+
+	snaps, err := db.ProposalVoteSnapshots(token)
+	if err != nil {
+		t.Fatalf("ProposalVoteSnapshots failed: %v", err)
+	}
+	want := &apitypes.ProposalVoteSnapshots{
+		Token:            token,
+		EligibleTickets:  200,
+		QuorumPercentage: 20,
+		PassPercentage:   60,
+		Height:           []int64{10, 12},
+		Time:             []dbtypes.TimeDef{dbtypes.NewTimeDef(blockTime), dbtypes.NewTimeDef(blockTime)},
+		Yes:              []int64{1, 30},
+		No:               []int64{0, 10},
+		Turnout:          []float64{0.5, 20},
+		QuorumProgress:   []float64{2.5, 100},
+		Approval:         []float64{100, 75},
+	}
+	if !reflect.DeepEqual(snaps, want) {
+		t.Errorf("expected snapshots %+v, got %+v", want, snaps)
+	}
+
+	_, err = db.ProposalVoteSnapshots("nosnapshots")
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a proposal without snapshots, got %v", err)
+	}
+}
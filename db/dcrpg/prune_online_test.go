@@ -0,0 +1,204 @@
+// +build pgonline
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const pruneTestSchema = "prune_test"
+
+// pruneTestTx is a transaction stored for the pruning tests. Each vin spends
+// the output prevIndex of the transaction prevHash, or is a coinbase input if
+// prevHash is empty.
+type pruneTestTx struct {
+	hash   string
+	height int64
+	vins   []struct {
+		prevHash  string
+		prevIndex uint32
+		value     int64
+	}
+	vouts []int64
+	dbTx  *dbtypes.Tx
+}
+
+// storePruneTestTx stores the transaction's vins, vouts and transactions
+// rows, and marks the outputs it spends as spent.
+func storePruneTestTx(t *testing.T, db *sql.DB, tx *pruneTestTx) {
+	blockTime := time.Unix(trefUNIX+tx.height*300, 0)
+	tx.dbTx = &dbtypes.Tx{TxID: tx.hash, BlockHeight: tx.height}
+	for i, vin := range tx.vins {
+		prevHash := vin.prevHash
+		if prevHash == "" {
+			prevHash = "0000000000000000000000000000000000000000000000000000000000000000"
+		}
+		var id uint64
+		err := db.QueryRow(`INSERT INTO vins (tx_hash, tx_index, tx_tree, is_valid,
+			is_mainchain, block_time, prev_tx_hash, prev_tx_index, prev_tx_tree,
+			value_in, tx_type, block_height)
+			VALUES ($1, $2, 0, TRUE, TRUE, $3, $4, $5, 0, $6, 0, $7) RETURNING id;`,
+			tx.hash, i, blockTime, prevHash, vin.prevIndex, vin.value, tx.height).Scan(&id)
+		if err != nil {
+			t.Fatalf("failed to store vin %s:%d: %v", tx.hash, i, err)
+		}
+		tx.dbTx.VinDbIds = append(tx.dbTx.VinDbIds, id)
+	}
+	for i, value := range tx.vouts {
+		var id uint64
+		err := db.QueryRow(`INSERT INTO vouts (tx_hash, tx_index, tx_tree, value,
+			version, pkscript, script_req_sigs, script_type, script_addresses,
+			block_height)
+			VALUES ($1, $2, 0, $3, 0, '\x76a914', 1, 'pubkeyhash', '{"Dsaddr"}', $4)
+			RETURNING id;`, tx.hash, i, value, tx.height).Scan(&id)
+		if err != nil {
+			t.Fatalf("failed to store vout %s:%d: %v", tx.hash, i, err)
+		}
+		tx.dbTx.VoutDbIds = append(tx.dbTx.VoutDbIds, id)
+	}
+
+	var txRowID int64
+	err := db.QueryRow(`INSERT INTO transactions (block_hash, block_height,
+		block_time, tx_hash, tree, block_index, tx_type, num_vin, vin_db_ids,
+		num_vout, vout_db_ids, is_valid, is_mainchain)
+		VALUES ($1, $2, $3, $4, 0, 0, 0, $5, $6, $7, $8, TRUE, TRUE) RETURNING id;`,
+		tx.hash+"_block", tx.height, blockTime, tx.hash,
+		len(tx.vins), dbtypes.UInt64Array(tx.dbTx.VinDbIds),
+		len(tx.vouts), dbtypes.UInt64Array(tx.dbTx.VoutDbIds)).Scan(&txRowID)
+	if err != nil {
+		t.Fatalf("failed to store transaction %s: %v", tx.hash, err)
+	}
+	for _, vin := range tx.vins {
+		_, err = db.Exec(`UPDATE vouts SET spend_tx_row_id = $1
+			WHERE tx_hash = $2 AND tx_index = $3;`, txRowID, vin.prevHash, vin.prevIndex)
+		if err != nil {
+			t.Fatalf("failed to set the spending transaction of %s:%d: %v",
+				vin.prevHash, vin.prevIndex, err)
+		}
+	}
+}
+
+func TestPrunedQueries(t *testing.T) {
+	sdb := connectTestSchema(t, pruneTestSchema)
+	defer sdb.Close()
+	defer sqlDb.Exec(`DROP SCHEMA IF EXISTS ` + pruneTestSchema + ` CASCADE;`)
+
+	tableMap := createTableMap()
+	for _, table := range []string{"transactions", "vins", "vouts", "treasury",
+		"prune_state", "pruned_supply"} {
+		if _, err := sdb.Exec(tableMap[table]); err != nil {
+			t.Fatalf("failed to create the %s table: %v", table, err)
+		}
+	}
+
+	pgb := &ChainDB{
+		ctx:          context.Background(),
+		queryTimeout: time.Minute,
+		db:           sdb,
+	}
+
+	// The coinbase "aa" is spent by "bb", and both are in the pruned blocks.
+	// The output of "bb" is spent by "cc" above the pruned blocks.
+	type vin = struct {
+		prevHash  string
+		prevIndex uint32
+		value     int64
+	}
+	aa := &pruneTestTx{hash: "aa", height: 10, vins: []vin{{"", 0, 1500}},
+		vouts: []int64{1000, 500}}
+	bb := &pruneTestTx{hash: "bb", height: 20, vins: []vin{{"aa", 0, 1000}},
+		vouts: []int64{900}}
+	cc := &pruneTestTx{hash: "cc", height: 5000, vins: []vin{{"bb", 0, 900}},
+		vouts: []int64{800}}
+	for _, tx := range []*pruneTestTx{aa, bb, cc} {
+		storePruneTestTx(t, sdb, tx)
+	}
+
+	spendingTx, _, _, err := pgb.SpendingTransaction("aa", 0)
+	if err != nil || spendingTx != "bb" {
+		t.Fatalf("before pruning, expected aa:0 spent by bb, got %q, %v", spendingTx, err)
+	}
+
+	vins, vouts, err := PruneBlocks(sdb, 0, 100)
+	if err != nil {
+		t.Fatalf("PruneBlocks failed: %v", err)
+	}
+	// The vouts spent in the pruned blocks are deleted, and the unspent vout
+	// aa:1 and bb:0, spent above the pruned blocks, are kept.
+	if vins != 2 || vouts != 1 {
+		t.Fatalf("pruned %d vins and %d vouts, expected 2 and 1", vins, vouts)
+	}
+	prunedHeight, err := pgb.PrunedHeight()
+	if err != nil || prunedHeight != 99 {
+		t.Fatalf("expected pruned height 99, got %d, %v", prunedHeight, err)
+	}
+
+	// The queries of the pruned transaction fail with ErrPruned.
+	pruned := []struct {
+		name  string
+		query func() error
+	}{
+		{"SpendingTransaction of a spent output", func() error {
+			_, _, _, err := pgb.SpendingTransaction("aa", 0)
+			return err
+		}},
+		{"SpendingTransaction of an unspent output", func() error {
+			_, _, _, err := pgb.SpendingTransaction("aa", 1)
+			return err
+		}},
+		{"SpendingTransactions", func() error {
+			_, _, _, err := pgb.SpendingTransactions("aa")
+			return err
+		}},
+		{"SpendDetailsForFundingTx", func() error {
+			_, err := pgb.SpendDetailsForFundingTx("aa")
+			return err
+		}},
+		{"VinsForTx", func() error {
+			_, _, _, err := pgb.VinsForTx(bb.dbTx)
+			return err
+		}},
+		{"VoutsForTx", func() error {
+			_, err := pgb.VoutsForTx(aa.dbTx)
+			return err
+		}},
+		{"TxGraph", func() error {
+			_, err := pgb.TxGraph("aa", 1, 10)
+			return err
+		}},
+	}
+	for _, test := range pruned {
+		if err := test.query(); err != dbtypes.ErrPruned {
+			t.Errorf("%s: expected ErrPruned, got %v", test.name, err)
+		}
+	}
+
+	// The spends above the pruned blocks, and the inputs and outputs of the
+	// transactions above the pruned blocks, are still found.
+	spendingTx, _, _, err = pgb.SpendingTransaction("bb", 0)
+	if err != nil || spendingTx != "cc" {
+		t.Errorf("expected bb:0 spent by cc, got %q, %v", spendingTx, err)
+	}
+	if _, _, _, err = pgb.SpendingTransaction("cc", 0); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for the unspent cc:0, got %v", err)
+	}
+	ccVins, prevPkScripts, _, err := pgb.VinsForTx(cc.dbTx)
+	if err != nil {
+		t.Fatalf("VinsForTx failed: %v", err)
+	}
+	if len(ccVins) != 1 || ccVins[0].PrevTxHash != "bb" || len(prevPkScripts) != 1 {
+		t.Errorf("unexpected vins of cc: %v", ccVins)
+	}
+	ccVouts, err := pgb.VoutsForTx(cc.dbTx)
+	if err != nil {
+		t.Fatalf("VoutsForTx failed: %v", err)
+	}
+	if len(ccVouts) != 1 || ccVouts[0].Value != 800 {
+		t.Errorf("unexpected vouts of cc: %v", ccVouts)
+	}
+}
@@ -7,10 +7,13 @@ package dcrpg
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -78,6 +81,140 @@ func SetIBDComplete(db SqlExecutor, ibdComplete bool) error {
 	return nil
 }
 
+// SyncState is the recorded phase of an in-progress bulk import.
+type SyncState struct {
+	Phase       string
+	Height      int64
+	BulkReindex bool
+	UpdatedAt   time.Time
+}
+
+// RetrieveSyncState retrieves the recorded sync state. If there is no
+// in-progress import, a nil *SyncState and a nil error are returned.
+func RetrieveSyncState(db *sql.DB) (*SyncState, error) {
+	var state SyncState
+	err := db.QueryRow(internal.SelectSyncState).Scan(&state.Phase,
+		&state.Height, &state.BulkReindex, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SetSyncState records the phase of an in-progress import, replacing any
+// previously recorded state.
+func SetSyncState(db SqlExecutor, phase string, height int64, bulkReindex bool) error {
+	_, err := sqlExec(db, internal.UpsertSyncState,
+		"failed to update sync_state table: ", phase, height, bulkReindex)
+	return err
+}
+
+// ClearSyncState removes the recorded sync state, indicating that no import is
+// in progress.
+func ClearSyncState(db SqlExecutor) error {
+	_, err := sqlExec(db, internal.DeleteSyncState,
+		"failed to clear sync_state table: ")
+	return err
+}
+
+// RetrieveAddressSpendInfoHeight retrieves the height of the last block whose
+// spending transactions have been used to populate the spending info of the
+// addresses table's funding rows. If the spending info has never been
+// populated in bulk, -1 and a nil error are returned.
+func RetrieveAddressSpendInfoHeight(db *sql.DB) (int64, error) {
+	var height int64
+	err := db.QueryRow(internal.SelectAddressSpendInfoHeight).Scan(&height)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	return height, err
+}
+
+// SetAddressSpendInfoHeight records the height of the last block whose spending
+// transactions have been used to populate the addresses table spending info.
+func SetAddressSpendInfoHeight(db SqlExecutor, height int64) error {
+	_, err := sqlExec(db, internal.UpsertAddressSpendInfoHeight,
+		"failed to update address_spend_info table", height)
+	return err
+}
+
+// lowerAddressSpendInfoHeight lowers the recorded address spending info height
+// to the given height if it is higher, as when the blocks above the height are
+// removed or orphaned.
+func lowerAddressSpendInfoHeight(db SqlExecutor, height int64) error {
+	_, err := sqlExec(db, internal.LowerAddressSpendInfoHeight,
+		"failed to update address_spend_info table", height)
+	return err
+}
+
+// RetrievePrunedHeight retrieves the height of the last block whose vins and
+// spent vouts have been pruned. If the DB has never been pruned, -1 and a nil
+// error are returned.
+func RetrievePrunedHeight(db *sql.DB) (int64, error) {
+	var height int64
+	err := db.QueryRow(internal.SelectPrunedHeight).Scan(&height)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	return height, err
+}
+
+// retrieveTxPruned checks if the vins and vouts of the transaction, or of the
+// transactions spending its outputs, may have been pruned, i.e. if it is in a
+// block at or below the pruned height.
+func retrieveTxPruned(ctx context.Context, db *sql.DB, txHash string, prunedHeight int64) (bool, error) {
+	var height sql.NullInt64
+	err := db.QueryRowContext(ctx, internal.SelectTxLowestBlockHeight, txHash).Scan(&height)
+	if err != nil {
+		return false, err
+	}
+	return height.Valid && height.Int64 <= prunedHeight, nil
+}
+
+// PruneBlocks deletes the vins and spent vouts of the blocks with heights in
+// [from, to), after rolling up the atoms minted by the mainchain blocks in the
+// pruned_supply table, and records to-1 as the pruned height. The numbers of
+// deleted vins and vouts are returned.
+func PruneBlocks(db *sql.DB, from, to int64) (vins, vouts int64, err error) {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	_, err = dbTx.Exec(internal.InsertPrunedSupply, from, to,
+		int(txhelpers.TreasuryTxSpend))
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, 0, fmt.Errorf("failed to roll up pruned supply: %v", err)
+	}
+
+	vins, err = sqlExec(dbTx, internal.DeletePrunedVins,
+		"failed to delete pruned vins:", from, to)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, 0, err
+	}
+
+	vouts, err = sqlExec(dbTx, internal.DeletePrunedVouts,
+		"failed to delete pruned vouts:", from, to)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, 0, err
+	}
+
+	_, err = sqlExec(dbTx, internal.UpsertPrunedHeight,
+		"failed to update prune_state table:", to-1)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, 0, err
+	}
+
+	return vins, vouts, dbTx.Commit()
+}
+
 // outputCountType defines the modes of the output count chart data.
 // outputCountByAllBlocks defines count per block i.e. solo and pooled tickets
 // count per block. outputCountByTicketPoolWindow defines the output count per
@@ -1174,6 +1311,51 @@ func RetrieveTicketStatusByHash(ctx context.Context, db *sql.DB, ticketHash stri
 	return
 }
 
+// retrieveTicketLifecycle retrieves the purchase, maturity, and spend of the
+// ticket with the given hash, and the reward of its vote if it voted. The
+// ticket maturity is used to locate the block at which the ticket went live.
+func retrieveTicketLifecycle(ctx context.Context, db *sql.DB, ticketHash string,
+	ticketMaturity int64) (*apitypes.TicketLifecycle, dbtypes.TicketSpendType, dbtypes.TicketPoolStatus, error) {
+	var spendType dbtypes.TicketSpendType
+	var poolStatus dbtypes.TicketPoolStatus
+	var purchaseTime dbtypes.TimeDef
+	var liveTime, spendTime *time.Time
+	var liveHash, spendTxHash, spendBlockHash sql.NullString
+	var spendHeight sql.NullInt64
+	var reward sql.NullFloat64
+	tl := &apitypes.TicketLifecycle{
+		TicketHash:    ticketHash,
+		PurchaseBlock: new(apitypes.TinyBlock),
+	}
+	err := db.QueryRowContext(ctx, internal.SelectTicketLifecycle, ticketHash,
+		ticketMaturity).Scan(&tl.PurchaseBlock.Hash, &tl.PurchaseBlock.Height,
+		&purchaseTime, &tl.Price, &tl.Fee, &spendType, &poolStatus, &liveHash,
+		&liveTime, &spendTxHash, &spendBlockHash, &spendHeight, &spendTime,
+		&reward)
+	if err != nil {
+		return nil, spendType, poolStatus, err
+	}
+
+	tl.PurchaseTime = purchaseTime.UNIX()
+	if liveHash.Valid && liveTime != nil {
+		tl.LiveTime = liveTime.Unix()
+	}
+	if spendTxHash.Valid {
+		tl.Spend = &apitypes.TicketSpend{
+			TxID: spendTxHash.String,
+			Block: apitypes.TinyBlock{
+				Hash:   spendBlockHash.String,
+				Height: uint32(spendHeight.Int64),
+			},
+		}
+		if spendTime != nil {
+			tl.Spend.Time = spendTime.Unix()
+		}
+	}
+	tl.Reward = reward.Float64
+	return tl, spendType, poolStatus, nil
+}
+
 // RetrieveTicketInfoByHash retrieves the ticket spend and pool statuses as well
 // as the purchase and spending block info and spending txid.
 func RetrieveTicketInfoByHash(ctx context.Context, db *sql.DB, ticketHash string) (spendStatus dbtypes.TicketSpendType,
@@ -1507,6 +1689,185 @@ func setSpendingForTickets(dbtx *sql.Tx, ticketDbIDs, spendDbIDs []uint64,
 	return stmt.Close()
 }
 
+// retrieveVoteVersionHistory retrieves the number of mainchain votes with each
+// vote version for each period of the time grouping, and the percentage of the
+// votes with a version below the given version.
+func retrieveVoteVersionHistory(ctx context.Context, db *sql.DB, timeGrouping string,
+	version uint32) (*apitypes.VoteVersionHistory, error) {
+	rows, err := db.QueryContext(ctx, internal.MakeSelectVoteVersionHistory(timeGrouping))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	history := &apitypes.VoteVersionHistory{
+		Version: version,
+		Counts:  make(map[uint32][]int64),
+	}
+	var old []int64
+	var lastPeriod time.Time
+	for rows.Next() {
+		var period time.Time
+		var ver uint32
+		var count int64
+		if err = rows.Scan(&period, &ver, &count); err != nil {
+			return nil, err
+		}
+		// The rows are ordered by period, so each new period is appended to
+		// all of the arrays.
+		if len(history.Time) == 0 || !period.Equal(lastPeriod) {
+			lastPeriod = period
+			history.Time = append(history.Time, dbtypes.NewTimeDef(period))
+			history.Total = append(history.Total, 0)
+			old = append(old, 0)
+			for v := range history.Counts {
+				history.Counts[v] = append(history.Counts[v], 0)
+			}
+		}
+		i := len(history.Time) - 1
+		counts, found := history.Counts[ver]
+		if !found {
+			counts = make([]int64, len(history.Time))
+			history.Counts[ver] = counts
+		}
+		counts[i] = count
+		history.Total[i] += count
+		if ver < version {
+			old[i] += count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history.OldPercent = make([]float64, len(old))
+	for i := range old {
+		history.OldPercent[i] = 100 * float64(old[i]) / float64(history.Total[i])
+	}
+	return history, nil
+}
+
+// retrieveVoteBitsCounts retrieves the number of mainchain votes with the vote
+// version in the blocks with heights in [startHeight, endHeight] for each vote
+// bits value, most common first.
+func retrieveVoteBitsCounts(ctx context.Context, db *sql.DB, version uint32,
+	startHeight, endHeight int64) ([]apitypes.VoteBitsCount, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectVoteBitsCounts, version,
+		startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	counts := []apitypes.VoteBitsCount{}
+	for rows.Next() {
+		var voteBits int16
+		var count int64
+		if err = rows.Scan(&voteBits, &count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, apitypes.VoteBitsCount{
+			VoteBits: uint16(voteBits),
+			Count:    count,
+		})
+	}
+	return counts, rows.Err()
+}
+
+// retrieveStakeROI simulates staking the amount from the start height with the
+// historical ticket prices, pool sizes, and vote rewards. In each cycle, as
+// many tickets as the balance affords are purchased, and each ticket votes
+// after the ticket maturity plus the expected wait in the pool at purchase,
+// i.e. the pool size over the votes per block. The tickets are repurchased with
+// the returned funds once the vote outputs mature. The simulation ends with the
+// last cycle that voted by the best block, or when the balance is less than
+// the ticket price. Transaction fees are not included.
+func retrieveStakeROI(ctx context.Context, db *sql.DB, params *chaincfg.Params,
+	amount dcrutil.Amount, start, bestHeight int64) (*apitypes.StakeROI, error) {
+	if start < params.StakeEnabledHeight {
+		start = params.StakeEnabledHeight
+	}
+
+	roi := &apitypes.StakeROI{
+		Amount:      amount.ToCoin(),
+		StartHeight: start,
+		EndHeight:   start,
+		Cycles:      []*apitypes.StakeROICycle{},
+	}
+
+	balance := amount
+	purchaseHeight := start
+	var startTime, endTime time.Time
+	for purchaseHeight <= bestHeight {
+		var sbits, poolSize int64
+		var purchaseTime time.Time
+		err := db.QueryRowContext(ctx, internal.SelectStakeROIBlock, purchaseHeight).
+			Scan(&sbits, &poolSize, &purchaseTime)
+		if err != nil {
+			return nil, err
+		}
+		if startTime.IsZero() {
+			startTime = purchaseTime
+			endTime = purchaseTime
+		}
+		if sbits <= 0 {
+			return nil, fmt.Errorf("invalid ticket price at height %d", purchaseHeight)
+		}
+		tickets := int64(balance) / sbits
+		if tickets == 0 {
+			break
+		}
+
+		wait := (poolSize + int64(params.TicketsPerBlock) - 1) / int64(params.TicketsPerBlock)
+		expectedVote := purchaseHeight + int64(params.TicketMaturity) + wait
+		if expectedVote > bestHeight {
+			roi.Pending = tickets
+			break
+		}
+		var voteHeight int64
+		var voteReward float64
+		var voteTime time.Time
+		err = db.QueryRowContext(ctx, internal.SelectStakeROIVote, expectedVote).
+			Scan(&voteHeight, &voteReward, &voteTime)
+		if err == sql.ErrNoRows {
+			roi.Pending = tickets
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		reward, err := dcrutil.NewAmount(voteReward)
+		if err != nil {
+			return nil, err
+		}
+		balance += dcrutil.Amount(tickets) * reward
+		roi.Cycles = append(roi.Cycles, &apitypes.StakeROICycle{
+			PurchaseHeight: purchaseHeight,
+			TicketPrice:    dcrutil.Amount(sbits).ToCoin(),
+			PoolSize:       poolSize,
+			Tickets:        tickets,
+			VoteHeight:     voteHeight,
+			VoteReward:     reward.ToCoin(),
+			Balance:        balance.ToCoin(),
+		})
+		roi.EndHeight = voteHeight
+		endTime = voteTime
+
+		purchaseHeight = voteHeight + int64(params.CoinbaseMaturity)
+	}
+
+	roi.StartTime = apitypes.NewTimeAPI(startTime)
+	roi.EndTime = apitypes.NewTimeAPI(endTime)
+	roi.FinalAmount = balance.ToCoin()
+	roi.Reward = (balance - amount).ToCoin()
+	roi.ROI = 100 * float64(balance-amount) / float64(amount)
+	if years := endTime.Sub(startTime).Hours() / (24 * 365); years > 0 {
+		roi.AnnualizedROI = 100 * (math.Pow(float64(balance)/float64(amount), 1/years) - 1)
+	}
+	return roi, nil
+}
+
 // --- addresses table ---
 
 // InsertAddressRow inserts an AddressRow (input or output), returning the row
@@ -2108,6 +2469,26 @@ func RetrieveAddressIDsByOutpoint(ctx context.Context, db *sql.DB, txHash string
 	return ids, addresses, value, err
 }
 
+// retrieveAddressesInBlock retrieves the addresses funded or spent by the
+// transactions in the given block.
+func retrieveAddressesInBlock(ctx context.Context, db *sql.DB, blockHash string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressesInBlock, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var addresses []string
+	for rows.Next() {
+		var addr string
+		if err = rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses, rows.Err()
+}
+
 // retrieveOldestTxBlockTime helps choose the most appropriate address page
 // graph grouping to load by default depending on when the first transaction to
 // the specific address was made.
@@ -2205,11 +2586,11 @@ func InsertVin(db *sql.DB, dbVin dbtypes.VinTxProperty, checked bool, updateOnCo
 	if len(updateOnConflict) > 0 {
 		doUpsert = updateOnConflict[0]
 	}
-	err = db.QueryRow(internal.MakeVinInsertStatement(checked, doUpsert),
+	err = db.QueryRow(internal.MakeVinInsertStatement(checked, doUpsert, false),
 		dbVin.TxID, dbVin.TxIndex, dbVin.TxTree,
 		dbVin.PrevTxHash, dbVin.PrevTxIndex, dbVin.PrevTxTree,
 		dbVin.ValueIn, dbVin.IsValid, dbVin.IsMainchain, dbVin.Time,
-		dbVin.TxType).Scan(&id)
+		dbVin.TxType, dbVin.TxBlockHeight).Scan(&id)
 	return
 }
 
@@ -2222,7 +2603,8 @@ func InsertVinsStmt(stmt *sql.Stmt, dbVins dbtypes.VinTxPropertyARRAY, checked b
 		var id uint64
 		err := stmt.QueryRow(vin.TxID, vin.TxIndex, vin.TxTree,
 			vin.PrevTxHash, vin.PrevTxIndex, vin.PrevTxTree,
-			vin.ValueIn, vin.IsValid, vin.IsMainchain, vin.Time, vin.TxType).Scan(&id)
+			vin.ValueIn, vin.IsValid, vin.IsMainchain, vin.Time, vin.TxType,
+			vin.TxBlockHeight).Scan(&id)
 		if err != nil {
 			return ids, fmt.Errorf("InsertVins INSERT exec failed: %v", err)
 		}
@@ -2236,7 +2618,7 @@ func InsertVinsStmt(stmt *sql.Stmt, dbVins dbtypes.VinTxPropertyARRAY, checked b
 // is required to Commit or Rollback the transaction depending on the returned
 // error value.
 func InsertVinsDbTxn(dbTx *sql.Tx, dbVins dbtypes.VinTxPropertyARRAY, checked bool, doUpsert bool) ([]uint64, error) {
-	stmt, err := dbTx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert))
+	stmt, err := dbTx.Prepare(internal.MakeVinInsertStatement(checked, doUpsert, false))
 	if err != nil {
 		return nil, err
 	}
@@ -2288,14 +2670,15 @@ func InsertVout(db *sql.DB, dbVout *dbtypes.Vout, checked bool, updateOnConflict
 	if len(updateOnConflict) > 0 {
 		doUpsert = updateOnConflict[0]
 	}
-	insertStatement := internal.MakeVoutInsertStatement(checked, doUpsert)
+	insertStatement := internal.MakeVoutInsertStatement(checked, doUpsert, false)
 	var id uint64
 	err := db.QueryRow(insertStatement,
 		dbVout.TxHash, dbVout.TxIndex, dbVout.TxTree,
 		dbVout.Value, int32(dbVout.Version),
 		dbVout.ScriptPubKey, int32(dbVout.ScriptPubKeyData.ReqSigs),
 		dbVout.ScriptPubKeyData.Type,
-		pq.Array(dbVout.ScriptPubKeyData.Addresses)).Scan(&id)
+		pq.Array(dbVout.ScriptPubKeyData.Addresses), dbVout.Mixed,
+		dbVout.BlockHeight).Scan(&id)
 	return id, err
 }
 
@@ -2310,7 +2693,8 @@ func InsertVoutsStmt(stmt *sql.Stmt, dbVouts []*dbtypes.Vout, checked bool, doUp
 			vout.TxHash, vout.TxIndex, vout.TxTree, vout.Value, int32(vout.Version),
 			vout.ScriptPubKey, int32(vout.ScriptPubKeyData.ReqSigs),
 			vout.ScriptPubKeyData.Type,
-			pq.Array(vout.ScriptPubKeyData.Addresses), vout.Mixed).Scan(&id)
+			pq.Array(vout.ScriptPubKeyData.Addresses), vout.Mixed,
+			vout.BlockHeight).Scan(&id)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				continue
@@ -2318,29 +2702,36 @@ func InsertVoutsStmt(stmt *sql.Stmt, dbVouts []*dbtypes.Vout, checked bool, doUp
 			return nil, nil, err
 		}
 
-		for _, addr := range vout.ScriptPubKeyData.Addresses {
-			addressRows = append(addressRows, dbtypes.AddressRow{
-				Address:        addr,
-				TxHash:         vout.TxHash,
-				TxVinVoutIndex: vout.TxIndex,
-				VinVoutDbID:    id,
-				TxType:         vout.TxType,
-				Value:          vout.Value,
-				// Not set here are: ValidMainchain, MatchingTxHash, IsFunding,
-				// AtomsCredit, AtomsDebit, and TxBlockTime.
-			})
-		}
+		addressRows = appendVoutAddressRows(addressRows, vout, id)
 		ids = append(ids, id)
 	}
 
 	return ids, addressRows, nil
 }
 
+// appendVoutAddressRows appends an AddressRow for each address paid by the
+// vout with the given vouts table row id.
+func appendVoutAddressRows(addressRows []dbtypes.AddressRow, vout *dbtypes.Vout, id uint64) []dbtypes.AddressRow {
+	for _, addr := range vout.ScriptPubKeyData.Addresses {
+		addressRows = append(addressRows, dbtypes.AddressRow{
+			Address:        addr,
+			TxHash:         vout.TxHash,
+			TxVinVoutIndex: vout.TxIndex,
+			VinVoutDbID:    id,
+			TxType:         vout.TxType,
+			Value:          vout.Value,
+			// Not set here are: ValidMainchain, MatchingTxHash, IsFunding,
+			// AtomsCredit, AtomsDebit, and TxBlockTime.
+		})
+	}
+	return addressRows
+}
+
 // InsertVoutsDbTxn is like InsertVouts, except that it takes a sql.Tx. The
 // caller is required to Commit or Rollback the transaction depending on the
 // returned error value.
 func InsertVoutsDbTxn(dbTx *sql.Tx, dbVouts []*dbtypes.Vout, checked bool, doUpsert bool) ([]uint64, []dbtypes.AddressRow, error) {
-	stmt, err := dbTx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert))
+	stmt, err := dbTx.Prepare(internal.MakeVoutInsertStatement(checked, doUpsert, false))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -2380,6 +2771,200 @@ func InsertVouts(db *sql.DB, dbVouts []*dbtypes.Vout, checked bool, updateOnConf
 	return ids, addressRows, dbTx.Commit()
 }
 
+// txInOutKey identifies a vin or vout by the columns of its unique index, to
+// match the row ids returned by a bulk insert to the inserted rows.
+type txInOutKey struct {
+	txHash  string
+	txIndex uint32
+	txTree  int8
+}
+
+// copyRowsDbTxn writes rows into the temporary copy table with the COPY
+// protocol, then inserts them into the destination table with the insert
+// statement, which must return the new row ids with the unique index columns.
+// rowAt returns the column values of the ith row. The returned ids map the
+// unique index columns of the inserted rows to their ids.
+func copyRowsDbTxn(dbTx *sql.Tx, copyTable string, columns []string, insertStmt string,
+	numRows int, rowAt func(i int) []interface{}) (map[txInOutKey]uint64, error) {
+	stmt, err := dbTx.Prepare(pq.CopyIn(copyTable, columns...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare COPY into %s: %v", copyTable, err)
+	}
+	for i := 0; i < numRows; i++ {
+		if _, err = stmt.Exec(rowAt(i)...); err != nil {
+			_ = stmt.Close()
+			return nil, fmt.Errorf("COPY into %s failed: %v", copyTable, err)
+		}
+	}
+	// Flush the buffered rows.
+	if _, err = stmt.Exec(); err != nil {
+		_ = stmt.Close()
+		return nil, fmt.Errorf("COPY into %s failed: %v", copyTable, err)
+	}
+	if err = stmt.Close(); err != nil {
+		return nil, err
+	}
+
+	rows, err := dbTx.Query(insertStmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[txInOutKey]uint64, numRows)
+	for rows.Next() {
+		var id uint64
+		var key txInOutKey
+		if err = rows.Scan(&id, &key.txHash, &key.txIndex, &key.txTree); err != nil {
+			return nil, err
+		}
+		ids[key] = id
+	}
+	return ids, rows.Err()
+}
+
+var vinCopyColumns = []string{"tx_hash", "tx_index", "tx_tree", "prev_tx_hash",
+	"prev_tx_index", "prev_tx_tree", "value_in", "is_valid", "is_mainchain",
+	"block_time", "tx_type", "block_height"}
+
+// CopyVinsDbTxn inserts the vins of each transaction with the COPY protocol,
+// in batches of up to batchSize rows, and returns the new row ids arranged like
+// dbVins. There are no checks for unique index conflicts, so this must only be
+// used before the unique indexes are created, like InsertVinsDbTxn with
+// checked=false. The caller is required to Commit or Rollback the transaction
+// depending on the returned error value.
+func CopyVinsDbTxn(dbTx *sql.Tx, dbVins []dbtypes.VinTxPropertyARRAY, batchSize int) ([][]uint64, error) {
+	// Flatten the vins of all transactions for batching.
+	var vins []*dbtypes.VinTxProperty
+	for it := range dbVins {
+		for iv := range dbVins[it] {
+			vins = append(vins, &dbVins[it][iv])
+		}
+	}
+	if len(vins) == 0 {
+		return make([][]uint64, len(dbVins)), nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(vins)
+	}
+
+	if _, err := dbTx.Exec(internal.CreateVinsCopyTable); err != nil {
+		return nil, fmt.Errorf("failed to create vins_copy table: %v", err)
+	}
+
+	ids := make(map[txInOutKey]uint64, len(vins))
+	for start := 0; start < len(vins); start += batchSize {
+		batch := vins[start:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		batchIDs, err := copyRowsDbTxn(dbTx, "vins_copy", vinCopyColumns,
+			internal.InsertVinsFromCopy, len(batch), func(i int) []interface{} {
+				vin := batch[i]
+				return []interface{}{vin.TxID, vin.TxIndex, vin.TxTree,
+					vin.PrevTxHash, vin.PrevTxIndex, vin.PrevTxTree,
+					vin.ValueIn, vin.IsValid, vin.IsMainchain, vin.Time, vin.TxType,
+					vin.TxBlockHeight}
+			})
+		if err != nil {
+			return nil, err
+		}
+		for k, id := range batchIDs {
+			ids[k] = id
+		}
+		if _, err = dbTx.Exec(internal.TruncateVinsCopy); err != nil {
+			return nil, err
+		}
+	}
+
+	vinIDs := make([][]uint64, len(dbVins))
+	for it := range dbVins {
+		vinIDs[it] = make([]uint64, 0, len(dbVins[it]))
+		for iv := range dbVins[it] {
+			vin := &dbVins[it][iv]
+			id, found := ids[txInOutKey{vin.TxID, vin.TxIndex, int8(vin.TxTree)}]
+			if !found {
+				return nil, fmt.Errorf("no vins row id returned for %s:%d",
+					vin.TxID, vin.TxIndex)
+			}
+			vinIDs[it] = append(vinIDs[it], id)
+		}
+	}
+	return vinIDs, nil
+}
+
+var voutCopyColumns = []string{"tx_hash", "tx_index", "tx_tree", "value",
+	"version", "pkscript", "script_req_sigs", "script_type", "script_addresses",
+	"mixed", "block_height"}
+
+// CopyVoutsDbTxn inserts the vouts of each transaction with the COPY protocol,
+// in batches of up to batchSize rows, and returns the new row ids and the
+// AddressRows for each transaction, arranged like dbVouts. There are no checks
+// for unique index conflicts, so this must only be used before the unique
+// indexes are created, like InsertVoutsDbTxn with checked=false. The caller is
+// required to Commit or Rollback the transaction depending on the returned
+// error value.
+func CopyVoutsDbTxn(dbTx *sql.Tx, dbVouts [][]*dbtypes.Vout, batchSize int) ([][]uint64, [][]dbtypes.AddressRow, error) {
+	// Flatten the vouts of all transactions for batching.
+	var vouts []*dbtypes.Vout
+	for it := range dbVouts {
+		vouts = append(vouts, dbVouts[it]...)
+	}
+	if len(vouts) == 0 {
+		return make([][]uint64, len(dbVouts)), make([][]dbtypes.AddressRow, len(dbVouts)), nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(vouts)
+	}
+
+	if _, err := dbTx.Exec(internal.CreateVoutsCopyTable); err != nil {
+		return nil, nil, fmt.Errorf("failed to create vouts_copy table: %v", err)
+	}
+
+	ids := make(map[txInOutKey]uint64, len(vouts))
+	for start := 0; start < len(vouts); start += batchSize {
+		batch := vouts[start:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		batchIDs, err := copyRowsDbTxn(dbTx, "vouts_copy", voutCopyColumns,
+			internal.InsertVoutsFromCopy, len(batch), func(i int) []interface{} {
+				vout := batch[i]
+				return []interface{}{vout.TxHash, vout.TxIndex, vout.TxTree,
+					vout.Value, int32(vout.Version), vout.ScriptPubKey,
+					int32(vout.ScriptPubKeyData.ReqSigs), vout.ScriptPubKeyData.Type,
+					pq.Array(vout.ScriptPubKeyData.Addresses), vout.Mixed,
+					vout.BlockHeight}
+			})
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, id := range batchIDs {
+			ids[k] = id
+		}
+		if _, err = dbTx.Exec(internal.TruncateVoutsCopy); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	voutIDs := make([][]uint64, len(dbVouts))
+	addressRows := make([][]dbtypes.AddressRow, len(dbVouts))
+	for it := range dbVouts {
+		voutIDs[it] = make([]uint64, 0, len(dbVouts[it]))
+		addressRows[it] = make([]dbtypes.AddressRow, 0, len(dbVouts[it]))
+		for _, vout := range dbVouts[it] {
+			id, found := ids[txInOutKey{vout.TxHash, vout.TxIndex, vout.TxTree}]
+			if !found {
+				return nil, nil, fmt.Errorf("no vouts row id returned for %s:%d",
+					vout.TxHash, vout.TxIndex)
+			}
+			voutIDs[it] = append(voutIDs[it], id)
+			addressRows[it] = appendVoutAddressRows(addressRows[it], vout, id)
+		}
+	}
+	return voutIDs, addressRows, nil
+}
+
 func RetrievePkScriptByVinID(ctx context.Context, db *sql.DB, vinID uint64) (pkScript []byte, ver uint16, err error) {
 	err = db.QueryRowContext(ctx, internal.SelectPkScriptByVinID, vinID).Scan(&ver, &pkScript)
 	return
@@ -2615,15 +3200,17 @@ func RetrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]
 	for i, id := range voutDbIDs {
 		vout := &vouts[i]
 		var id0 uint64
-		var spendTxRowID sql.NullInt64 // discarded, but can be NULL
+		var spendTxRowID, blockHeight sql.NullInt64 // can be NULL
 		var reqSigs uint32
 		var scriptType, addresses string
 		err := db.QueryRowContext(ctx, internal.SelectVoutByID, id).Scan(&id0, &vout.TxHash,
 			&vout.TxIndex, &vout.TxTree, &vout.Value, &vout.Version,
-			&vout.ScriptPubKey, &reqSigs, &scriptType, &addresses, &vout.Mixed, &spendTxRowID)
+			&vout.ScriptPubKey, &reqSigs, &scriptType, &addresses, &vout.Mixed,
+			&spendTxRowID, &blockHeight)
 		if err != nil {
 			return nil, err
 		}
+		vout.BlockHeight = blockHeight.Int64
 		// Parse the addresses array
 		replacer := strings.NewReplacer("{", "", "}", "")
 		addresses = replacer.Replace(addresses)
@@ -2698,6 +3285,61 @@ func retrieveUTXOs(ctx context.Context, db *sql.DB, stmt string) ([]dbtypes.UTXO
 	return utxos, nil
 }
 
+// ForEachUTXO calls f with each output of the UTXO set after the mainchain block
+// at the height, in order of the funding transaction's height. The entry passed
+// to f is reused, and must not be retained. Iteration stops at the first error
+// returned by f.
+func ForEachUTXO(ctx context.Context, db *sql.DB, height int64, f func(*dbtypes.UTXOSetEntry) error) error {
+	rows, err := db.QueryContext(ctx, internal.SelectUTXOSetAtHeight, height)
+	if err != nil {
+		return err
+	}
+	defer closeRows(rows)
+
+	var utxo dbtypes.UTXOSetEntry
+	for rows.Next() {
+		utxo.Addresses = nil
+		err = rows.Scan(&utxo.TxHash, &utxo.TxIndex, &utxo.TxTree, &utxo.Height,
+			&utxo.Value, &utxo.ScriptType, pq.Array(&utxo.Addresses))
+		if err != nil {
+			return err
+		}
+		if err = f(&utxo); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RetrieveUTXOSetStats counts and sums the UTXO set after the mainchain block at
+// the height, in total and for each script type.
+func RetrieveUTXOSetStats(ctx context.Context, db *sql.DB, height int64) (*apitypes.UTXOSetStats, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectUTXOSetStatsAtHeight, height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	stats := &apitypes.UTXOSetStats{
+		Height:      height,
+		ScriptTypes: []apitypes.UTXOScriptTypeStats{},
+	}
+	var total int64
+	for rows.Next() {
+		var st apitypes.UTXOScriptTypeStats
+		var value int64
+		if err = rows.Scan(&st.ScriptType, &st.Count, &value); err != nil {
+			return nil, err
+		}
+		st.Value = dcrutil.Amount(value).ToCoin()
+		stats.Count += st.Count
+		total += value
+		stats.ScriptTypes = append(stats.ScriptTypes, st)
+	}
+	stats.TotalValue = dcrutil.Amount(total).ToCoin()
+	return stats, rows.Err()
+}
+
 // SetSpendingForVinDbIDs updates rows of the addresses table with spending
 // information from the rows of the vins table specified by vinDbIDs. This does
 // not insert the spending transaction into the addresses table.
@@ -2978,25 +3620,142 @@ func insertSpendingAddressRow(tx *sql.Tx, fundingTxHash string, fundingTxVoutInd
 	return 0, voutDbID, mixed, nil
 }
 
-// --- agendas table ---
-
-// retrieveAgendaVoteChoices retrieves for the specified agenda the vote counts
-// for each choice and the total number of votes. The interval size is either a
-// single block or a day, as specified by byType, where a value of 1 indicates a
-// block and 0 indicates a day-long interval. For day intervals, the counts
-// accumulate over time (cumulative sum), whereas for block intervals the counts
-// are just for the block. The total length of time over all intervals always
-// spans the locked-in period of the agenda. votingDoneHeight references the
-// height at which the agenda ID voting is considered complete.
-func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int,
-	votingStartHeight, votingDoneHeight int64) (*dbtypes.AgendaVoteChoices, error) {
-	// Query with block or day interval size
-	var query = internal.SelectAgendasVotesByTime
-	if byType == 1 {
-		query = internal.SelectAgendasVotesByHeight
-	}
+// retrieveTxGraph retrieves the graph of the mainchain transactions up to
+// depth hops from the transaction, with up to maxEdges edges in each direction.
+// The depth of an ancestor is negative. The graph is truncated if either
+// direction has maxEdges edges.
+func retrieveTxGraph(ctx context.Context, db *sql.DB, txid string, depth, maxEdges int) (*apitypes.TxGraph, error) {
+	graph := &apitypes.TxGraph{
+		TxID:  txid,
+		Depth: depth,
+		Nodes: []*apitypes.TxGraphNode{},
+		Edges: []*apitypes.TxGraphEdge{},
+	}
+
+	// nodeDepths is the depth of each transaction in the graph. The
+	// recursion may reach an edge by paths of different lengths, so only its
+	// shortest path is kept.
+	nodeDepths := map[string]int{txid: 0}
+	edges := make(map[string]*apitypes.TxGraphEdge)
+	addEdges := func(query string, sign int) error {
+		rows, err := db.QueryContext(ctx, query, txid, depth, maxEdges)
+		if err != nil {
+			return err
+		}
+		defer closeRows(rows)
 
-	rows, err := db.QueryContext(ctx, query, dbtypes.Yes, dbtypes.Abstain, dbtypes.No,
+		var numRows int
+		for rows.Next() {
+			var edge apitypes.TxGraphEdge
+			var value int64
+			var d int
+			err = rows.Scan(&edge.From, &edge.Vout, &edge.To, &edge.Vin, &value, &d)
+			if err != nil {
+				return err
+			}
+			numRows++
+			edge.Value = dcrutil.Amount(value).ToCoin()
+			edge.Depth = sign * d
+
+			// The far end of an ancestor edge is the funding transaction, and
+			// of a descendant edge the spending transaction.
+			node := edge.To
+			if sign < 0 {
+				node = edge.From
+			}
+			if nd, found := nodeDepths[node]; !found || sign*nd > d {
+				nodeDepths[node] = edge.Depth
+			}
+			key := fmt.Sprintf("%s:%d", edge.From, edge.Vout)
+			if e, found := edges[key]; !found || sign*e.Depth > d {
+				edges[key] = &edge
+			}
+		}
+		if numRows >= maxEdges {
+			graph.Truncated = true
+		}
+		return rows.Err()
+	}
+	if err := addEdges(internal.SelectTxGraphAncestors, -1); err != nil {
+		return nil, err
+	}
+	if err := addEdges(internal.SelectTxGraphDescendants, 1); err != nil {
+		return nil, err
+	}
+
+	txids := make([]string, 0, len(nodeDepths))
+	for node := range nodeDepths {
+		txids = append(txids, node)
+	}
+	rows, err := db.QueryContext(ctx, internal.SelectTxGraphNodes, pq.Array(txids))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var node apitypes.TxGraphNode
+		var blockTime time.Time
+		var txType int
+		var sent, fees int64
+		err = rows.Scan(&node.TxID, &node.BlockHeight, &blockTime, &txType, &sent, &fees)
+		if err != nil {
+			return nil, err
+		}
+		node.Depth = nodeDepths[node.TxID]
+		node.Time = apitypes.NewTimeAPI(blockTime)
+		node.Type = txhelpers.TxTypeToString(txType)
+		node.Value = dcrutil.Amount(sent).ToCoin()
+		node.Fees = dcrutil.Amount(fees).ToCoin()
+		graph.Nodes = append(graph.Nodes, &node)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(graph.Nodes) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Depth != graph.Nodes[j].Depth {
+			return graph.Nodes[i].Depth < graph.Nodes[j].Depth
+		}
+		return graph.Nodes[i].TxID < graph.Nodes[j].TxID
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Depth != graph.Edges[j].Depth {
+			return graph.Edges[i].Depth < graph.Edges[j].Depth
+		}
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].Vout < graph.Edges[j].Vout
+	})
+	return graph, nil
+}
+
+// --- agendas table ---
+
+// retrieveAgendaVoteChoices retrieves for the specified agenda the vote counts
+// for each choice and the total number of votes. The interval size is either a
+// single block or a day, as specified by byType, where a value of 1 indicates a
+// block and 0 indicates a day-long interval. For day intervals, the counts
+// accumulate over time (cumulative sum), whereas for block intervals the counts
+// are just for the block. The total length of time over all intervals always
+// spans the locked-in period of the agenda. votingDoneHeight references the
+// height at which the agenda ID voting is considered complete.
+func retrieveAgendaVoteChoices(ctx context.Context, db *sql.DB, agendaID string, byType int,
+	votingStartHeight, votingDoneHeight int64) (*dbtypes.AgendaVoteChoices, error) {
+	// Query with block or day interval size
+	var query = internal.SelectAgendasVotesByTime
+	if byType == 1 {
+		query = internal.SelectAgendasVotesByHeight
+	}
+
+	rows, err := db.QueryContext(ctx, query, dbtypes.Yes, dbtypes.Abstain, dbtypes.No,
 		agendaID, votingStartHeight, votingDoneHeight)
 	if err != nil {
 		return nil, err
@@ -3059,6 +3818,60 @@ func retrieveTotalAgendaVotesCount(ctx context.Context, db *sql.DB, agendaID str
 	return
 }
 
+// --- agenda_vote_intervals table ---
+
+// upsertAgendaVoteInterval counts the agenda votes cast from startHeight to
+// endHeight, and stores them for the interval starting at startHeight.
+func upsertAgendaVoteInterval(ctx context.Context, db *sql.DB, agendaID string,
+	startHeight, endHeight int64) error {
+	_, err := db.ExecContext(ctx, internal.UpsertAgendaVoteInterval, dbtypes.Yes,
+		dbtypes.Abstain, dbtypes.No, agendaID, startHeight, endHeight)
+	return err
+}
+
+// retrieveAgendaVoteIntervalsLastStart retrieves the start height of the last
+// stored interval that starts between votingStarted and votingDone. The
+// returned bool is false if there are no such intervals.
+func retrieveAgendaVoteIntervalsLastStart(ctx context.Context, db *sql.DB,
+	agendaID string, votingStarted, votingDone int64) (int64, bool, error) {
+	var lastStart sql.NullInt64
+	err := db.QueryRowContext(ctx, internal.SelectAgendaVoteIntervalsLastStart,
+		agendaID, votingStarted, votingDone).Scan(&lastStart)
+	return lastStart.Int64, lastStart.Valid, err
+}
+
+// retrieveAgendaVoteIntervals retrieves the stored intervals that start between
+// votingStarted and votingDone, with the cumulative vote counts.
+func retrieveAgendaVoteIntervals(ctx context.Context, db *sql.DB, agendaID string,
+	votingStarted, votingDone int64) ([]apitypes.AgendaVoteInterval, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAgendaVoteIntervals,
+		agendaID, votingStarted, votingDone)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var intervals []apitypes.AgendaVoteInterval
+	var cumulative apitypes.AgendaVoteCounts
+	for rows.Next() {
+		var iv apitypes.AgendaVoteInterval
+		var endTime dbtypes.TimeDef
+		err = rows.Scan(&iv.StartHeight, &iv.EndHeight, &endTime, &iv.Votes.Yes,
+			&iv.Votes.Abstain, &iv.Votes.No, &iv.Votes.Total)
+		if err != nil {
+			return nil, err
+		}
+		iv.EndTime = apitypes.TimeAPI{S: endTime}
+		cumulative.Yes += iv.Votes.Yes
+		cumulative.Abstain += iv.Votes.Abstain
+		cumulative.No += iv.Votes.No
+		cumulative.Total += iv.Votes.Total
+		iv.Cumulative = cumulative
+		intervals = append(intervals, iv)
+	}
+	return intervals, rows.Err()
+}
+
 // --- transactions table ---
 
 func InsertTx(db *sql.DB, dbTx *dbtypes.Tx, checked, updateExistingRecords bool) (uint64, error) {
@@ -3507,6 +4320,14 @@ func appendWindowStats(charts *cache.ChartData, rows *sql.Rows) error {
 	return rows.Err()
 }
 
+// RetrieveCirculatingSupply sums the atoms minted in the main chain.
+func RetrieveCirculatingSupply(ctx context.Context, db *sql.DB) (int64, error) {
+	var supply int64
+	err := db.QueryRowContext(ctx, internal.SelectCirculatingSupply,
+		int(txhelpers.TreasuryTxSpend)).Scan(&supply)
+	return supply, err
+}
+
 // retrieveCoinSupply fetches the coin supply data from the vins table.
 func retrieveCoinSupply(ctx context.Context, db *sql.DB, charts *cache.ChartData) (*sql.Rows, error) {
 	rows, err := db.QueryContext(ctx, internal.SelectCoinSupply, charts.NewAtomsTip())
@@ -3864,28 +4685,58 @@ func retrieveTicketByOutputCount(ctx context.Context, db *sql.DB, interval int64
 
 // --- Proposals and Proposal_votes tables ---
 
-// InsertProposal adds the proposal details per commit to the proposal table.
-func InsertProposal(db *sql.DB, tokenHash, author, commit string,
-	timestamp time.Time, checked bool) (uint64, error) {
-	insertStatement := internal.MakeProposalsInsertStatement(checked)
-	var id uint64
-	err := db.QueryRow(insertStatement, tokenHash, author, commit, timestamp).Scan(&id)
-	return id, err
-}
+// InsertProposalVotes stores the votes cast on the proposal that were retrieved
+// at time t, as a proposals row and the proposal_votes rows referencing it.
+func InsertProposalVotes(db *sql.DB, token string, votes []dbtypes.ProposalCastVote,
+	t time.Time) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
 
-// InsertProposalVote add the proposal votes entries to the proposal_votes table.
-func InsertProposalVote(db *sql.DB, proposalRowID uint64, ticket, choice string,
-	checked bool) (uint64, error) {
 	var id uint64
-	err := db.QueryRow(internal.InsertProposalVotesRow, proposalRowID, ticket, choice).Scan(&id)
-	return id, err
+	err = dbTx.QueryRow(internal.InsertProposalsRow, token, nil, nil, t).Scan(&id)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+
+	stmt, err := dbTx.Prepare(internal.InsertProposalVotesRow)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, vote := range votes {
+		var voteID uint64
+		if err = stmt.QueryRow(id, vote.Ticket, vote.Choice).Scan(&voteID); err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
 }
 
-// retrieveLastCommitTime returns the last commit timestamp whole proposal votes
-// data was fetched and updated in both proposals and proposal_votes table.
-func retrieveLastCommitTime(db *sql.DB) (timestamp time.Time, err error) {
-	err = db.QueryRow(internal.SelectProposalsLastCommitTime).Scan(&timestamp)
-	return
+// retrieveProposalVotedTickets retrieves the tickets whose votes on the
+// proposal are stored.
+func retrieveProposalVotedTickets(db *sql.DB, token string) (map[string]struct{}, error) {
+	rows, err := db.Query(internal.SelectProposalVotedTickets, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	tickets := make(map[string]struct{})
+	for rows.Next() {
+		var ticket string
+		if err = rows.Scan(&ticket); err != nil {
+			return nil, err
+		}
+		tickets[ticket] = struct{}{}
+	}
+	return tickets, rows.Err()
 }
 
 // retrieveProposalVotesData returns the vote data associated with the provided
@@ -3917,152 +4768,1797 @@ func retrieveProposalVotesData(ctx context.Context, db *sql.DB,
 	return data, err
 }
 
-// --- blocks and block_chain tables ---
-
-// InsertBlock inserts the specified dbtypes.Block as with the given
-// valid/mainchain status. If checked is true, an upsert statement is used so
-// that a unique constraint violation will result in an update instead of
-// attempting to insert a duplicate row. If checked is false and there is a
-// duplicate row, an error will be returned.
-func InsertBlock(db *sql.DB, dbBlock *dbtypes.Block, isValid, isMainchain, checked bool) (uint64, error) {
-	insertStatement := internal.BlockInsertStatement(checked)
-	var id uint64
-	err := db.QueryRow(insertStatement,
-		dbBlock.Hash, dbBlock.Height, dbBlock.Size, isValid, isMainchain,
-		int32(dbBlock.Version), dbBlock.NumTx, dbBlock.NumRegTx,
-		pq.Array(dbBlock.Tx), pq.Array(dbBlock.TxDbIDs),
-		dbBlock.NumStakeTx, pq.Array(dbBlock.STx), pq.Array(dbBlock.STxDbIDs),
-		dbBlock.Time, int64(dbBlock.Nonce), int16(dbBlock.VoteBits), dbBlock.Voters,
-		dbBlock.FreshStake, dbBlock.Revocations, dbBlock.PoolSize, int64(dbBlock.Bits),
-		int64(dbBlock.SBits), dbBlock.Difficulty, int32(dbBlock.StakeVersion),
-		dbBlock.PreviousHash, dbBlock.ChainWork, pq.Array(dbBlock.Winners)).Scan(&id)
-	return id, err
-}
+// --- politeia_proposals table ---
 
-// InsertBlockPrevNext inserts a new row of the block_chain table.
-func InsertBlockPrevNext(db *sql.DB, blockDbID uint64,
-	hash, prev, next string) error {
-	rows, err := db.Query(internal.InsertBlockPrevNext, blockDbID, prev, hash, next)
-	if err == nil {
-		return rows.Close()
-	}
+// UpsertPoliteiaProposal stores the metadata and vote summary of the proposal,
+// updated at time t.
+func UpsertPoliteiaProposal(db *sql.DB, p *dbtypes.PoliteiaProposal, t time.Time) error {
+	_, err := db.Exec(internal.UpsertPoliteiaProposal, p.Token, p.Name,
+		p.Username, p.Status, p.VoteStatus, p.NumComments, p.PublishedAt,
+		p.EndHeight, p.EligibleTickets, p.QuorumPercentage, p.PassPercentage,
+		p.Yes, p.No, p.TotalVotes, t)
 	return err
 }
 
-// InsertBlockStats inserts the block stats into the stats table.
-func InsertBlockStats(db *sql.DB, blockDbID uint64, tpi *apitypes.TicketPoolInfo) error {
-	_, err := db.Exec(internal.UpsertStats, blockDbID, tpi.Height, tpi.Size, int64(tpi.Value*dcrToAtoms))
+// --- proposal_vote_snapshots table ---
+
+// UpsertProposalVoteSnapshot stores the snapshot of a proposal's vote tally,
+// replacing any snapshot of the proposal at the same height.
+func UpsertProposalVoteSnapshot(db *sql.DB, snap *dbtypes.ProposalVoteSnapshot) error {
+	_, err := db.Exec(internal.UpsertProposalVoteSnapshot, snap.Token,
+		snap.Height, snap.Time, snap.Yes, snap.No, snap.EligibleTickets,
+		snap.QuorumPercentage, snap.PassPercentage)
 	return err
 }
 
-// RetrieveBestBlockHeight gets the best block height and hash (main chain
-// only). Be sure to check for sql.ErrNoRows.
-func RetrieveBestBlockHeight(ctx context.Context, db *sql.DB) (height uint64, hash string, id uint64, err error) {
-	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeight).Scan(&id, &hash, &height)
-	return
-}
+// RetrieveProposalVoteSnapshots retrieves the snapshots of the proposal's vote
+// tally, oldest first. sql.ErrNoRows is returned if there are none.
+func RetrieveProposalVoteSnapshots(ctx context.Context, db *sql.DB, token string) (*apitypes.ProposalVoteSnapshots, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectProposalVoteSnapshots, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
 
-// RetrieveBestBlock gets the best block height and hash (main chain only). If
-// there are no results from the query, the height is -1 and err is nil.
-func RetrieveBestBlock(ctx context.Context, db *sql.DB) (height int64, hash string, err error) {
-	var bbHeight uint64
-	bbHeight, hash, _, err = RetrieveBestBlockHeight(ctx, db)
-	height = int64(bbHeight)
-	if err != nil && err == sql.ErrNoRows {
-		height = -1
-		err = nil
+	snaps := &apitypes.ProposalVoteSnapshots{Token: token}
+	for rows.Next() {
+		var height, yes, no int64
+		var t time.Time
+		err = rows.Scan(&height, &t, &yes, &no, &snaps.EligibleTickets,
+			&snaps.QuorumPercentage, &snaps.PassPercentage)
+		if err != nil {
+			return nil, err
+		}
+
+		var turnout, quorumProgress, approval float64
+		votes := yes + no
+		if snaps.EligibleTickets > 0 {
+			turnout = 100 * float64(votes) / float64(snaps.EligibleTickets)
+		}
+		if snaps.QuorumPercentage > 0 {
+			quorumProgress = 100 * turnout / float64(snaps.QuorumPercentage)
+		}
+		if votes > 0 {
+			approval = 100 * float64(yes) / float64(votes)
+		}
+
+		snaps.Height = append(snaps.Height, height)
+		snaps.Time = append(snaps.Time, dbtypes.NewTimeDef(t))
+		snaps.Yes = append(snaps.Yes, yes)
+		snaps.No = append(snaps.No, no)
+		snaps.Turnout = append(snaps.Turnout, turnout)
+		snaps.QuorumProgress = append(snaps.QuorumProgress, quorumProgress)
+		snaps.Approval = append(snaps.Approval, approval)
 	}
-	return
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(snaps.Height) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return snaps, nil
 }
 
-// RetrieveBestBlockHeightAny gets the best block height, including side chains.
-func RetrieveBestBlockHeightAny(ctx context.Context, db *sql.DB) (height uint64, hash string, id uint64, err error) {
-	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeightAny).Scan(&id, &hash, &height)
-	return
-}
+// --- treasury and treasury_votes tables ---
 
-// RetrieveBlockHash retrieves the hash of the block at the given height, if it
-// exists (be sure to check error against sql.ErrNoRows!). WARNING: this returns
-// the most recently added block at this height, but there may be others.
-func RetrieveBlockHash(ctx context.Context, db *sql.DB, idx int64) (hash string, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockHashByHeight, idx).Scan(&hash)
-	return
-}
+// InsertTreasuryTxns inserts the treasury adds, spends, and treasurybase of the
+// block's stake tree into the treasury table, and the TSpend votes cast by the
+// block's votes into the treasury_votes table.
+func InsertTreasuryTxns(db *sql.DB, msgBlock *wire.MsgBlock, isMainchain bool) error {
+	blockHash := msgBlock.BlockHash().String()
+	height := int64(msgBlock.Header.Height)
+	blockTime := dbtypes.NewTimeDef(msgBlock.Header.Timestamp)
 
-// RetrieveBlockTimeByHeight retrieves time hash of the main chain block at the
-// given height, if it exists (be sure to check error against sql.ErrNoRows!).
-func RetrieveBlockTimeByHeight(ctx context.Context, db *sql.DB, idx int64) (time dbtypes.TimeDef, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockTimeByHeight, idx).Scan(&time)
-	return
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	for _, tx := range msgBlock.STransactions {
+		txType := txhelpers.DetermineTreasuryTxType(tx)
+		if txType != txhelpers.TreasuryTxNone {
+			_, err = dbTx.Exec(internal.UpsertTreasuryRow, tx.TxHash().String(),
+				int(txType), txhelpers.TreasuryTxValue(tx, txType), blockHash,
+				height, blockTime, isMainchain)
+			if err != nil {
+				_ = dbTx.Rollback()
+				return fmt.Errorf("failed to insert treasury txn: %v", err)
+			}
+			continue
+		}
+
+		if !stake.IsSSGen(tx) {
+			continue
+		}
+		voteHash := tx.TxHash().String()
+		for _, v := range txhelpers.TSpendVotes(tx) {
+			_, err = dbTx.Exec(internal.UpsertTreasuryVoteRow, v.TSpend.String(),
+				voteHash, int(v.Choice), blockHash, height, isMainchain)
+			if err != nil {
+				_ = dbTx.Rollback()
+				return fmt.Errorf("failed to insert TSpend vote: %v", err)
+			}
+		}
+	}
+
+	return dbTx.Commit()
 }
 
-// RetrieveBlockHeight retrieves the height of the block with the given hash, if
-// it exists (be sure to check error against sql.ErrNoRows!).
-func RetrieveBlockHeight(ctx context.Context, db *sql.DB, hash string) (height int64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockHeightByHash, hash).Scan(&height)
-	return
+// UpdateTreasuryMainchain sets the is_mainchain column for the treasury txns
+// and TSpend votes in the specified block.
+func UpdateTreasuryMainchain(db SqlExecutor, blockHash string, isMainchain bool) (int64, error) {
+	numTxns, err := sqlExec(db, internal.UpdateTreasuryMainchainByBlock,
+		"failed to update treasury is_mainchain", isMainchain, blockHash)
+	if err != nil {
+		return 0, err
+	}
+	numVotes, err := sqlExec(db, internal.UpdateTreasuryVotesMainchainByBlock,
+		"failed to update treasury_votes is_mainchain", isMainchain, blockHash)
+	return numTxns + numVotes, err
 }
 
-// RetrieveBlockVoteCount gets the number of votes mined in a block.
-func RetrieveBlockVoteCount(ctx context.Context, db *sql.DB, hash string) (numVotes int16, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockVoteCount, hash).Scan(&numVotes)
+// RetrieveTreasuryBalance retrieves the treasury balance, in atoms, and the
+// number of additions to and spends from the treasury on mainchain.
+func RetrieveTreasuryBalance(ctx context.Context, db *sql.DB) (balance, numAdds, numSpends int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectTreasuryBalance).Scan(&balance,
+		&numAdds, &numSpends)
 	return
 }
 
-// RetrieveBlocksHashesAll retrieve the hash of every block in the blocks table,
-// ordered by their row ID.
-func RetrieveBlocksHashesAll(ctx context.Context, db *sql.DB) ([]string, error) {
-	var hashes []string
-	rows, err := db.QueryContext(ctx, internal.SelectBlocksHashes)
+// retrieveTreasuryBalanceHistory retrieves the amounts added to and spent from
+// the treasury in each period of the time grouping, and the treasury balance
+// at the end of each period.
+func retrieveTreasuryBalanceHistory(ctx context.Context, db *sql.DB,
+	timeGrouping string) (*apitypes.TreasuryBalanceHistory, error) {
+	rows, err := db.QueryContext(ctx, internal.MakeSelectTreasuryBalanceHistory(timeGrouping))
 	if err != nil {
 		return nil, err
 	}
 	defer closeRows(rows)
 
+	history := new(apitypes.TreasuryBalanceHistory)
 	for rows.Next() {
-		var hash string
-		err = rows.Scan(&hash)
-		if err != nil {
+		var period time.Time
+		var added, spent, balance int64
+		if err = rows.Scan(&period, &added, &spent, &balance); err != nil {
 			return nil, err
 		}
-
-		hashes = append(hashes, hash)
+		history.Time = append(history.Time, dbtypes.NewTimeDef(period))
+		history.Added = append(history.Added, dcrutil.Amount(added).ToCoin())
+		history.Spent = append(history.Spent, dcrutil.Amount(spent).ToCoin())
+		history.Balance = append(history.Balance, dcrutil.Amount(balance).ToCoin())
 	}
-	err = rows.Err()
+	return history, rows.Err()
+}
 
-	return hashes, err
+// RetrieveTSpend retrieves the amount spent from the treasury, in atoms, and
+// the mainchain block containing the specified TSpend.
+func RetrieveTSpend(ctx context.Context, db *sql.DB, txHash string) (amount int64,
+	blockHash string, blockHeight int64, blockTime dbtypes.TimeDef, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectTSpendByHash, txHash,
+		int(txhelpers.TreasuryTxSpend)).Scan(&amount, &blockHash, &blockHeight, &blockTime)
+	amount = -amount
+	return
 }
 
-// RetrieveBlockChainDbID retrieves the row id in the block_chain table of the
-// block with the given hash, if it exists (be sure to check error against
-// sql.ErrNoRows!).
-func RetrieveBlockChainDbID(ctx context.Context, db *sql.DB, hash string) (dbID uint64, err error) {
-	err = db.QueryRowContext(ctx, internal.SelectBlockChainRowIDByHash, hash).Scan(&dbID)
+// RetrieveTSpendVoteTally retrieves the number of mainchain yes and no votes on
+// the specified TSpend.
+func RetrieveTSpendVoteTally(ctx context.Context, db *sql.DB, tspendHash string) (yes, no int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectTSpendVoteTally, tspendHash).Scan(&yes, &no)
 	return
 }
 
-// RetrieveSideChainBlocks retrieves the block chain status for all known side
-// chain blocks.
-func RetrieveSideChainBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
-	var rows *sql.Rows
-	rows, err = db.QueryContext(ctx, internal.SelectSideChainBlocks)
+// retrieveInvoicePayments retrieves the amounts paid to the address by the
+// valid mainchain transactions, identifying the TSpends and the spends from
+// the legacy treasury address.
+func retrieveInvoicePayments(ctx context.Context, db *sql.DB, address string,
+	txids []string, legacyTreasury string) ([]apitypes.ProposalPayment, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectInvoicePayments, pq.Array(txids),
+		address, int(txhelpers.TreasuryTxSpend), legacyTreasury)
 	if err != nil {
-		return
+		return nil, err
 	}
-	defer closeRows(rows)
+	defer rows.Close()
 
+	var payments []apitypes.ProposalPayment
 	for rows.Next() {
-		var bs dbtypes.BlockStatus
-		err = rows.Scan(&bs.IsValid, &bs.Height, &bs.PrevHash, &bs.Hash, &bs.NextHash)
+		var p apitypes.ProposalPayment
+		var blockTime dbtypes.TimeDef
+		var amount int64
+		var tspend, legacy bool
+		err = rows.Scan(&p.TxID, &p.BlockHeight, &blockTime, &amount, &tspend, &legacy)
 		if err != nil {
-			return
+			return nil, err
 		}
-
-		blocks = append(blocks, &bs)
+		p.Time = apitypes.TimeAPI{S: blockTime}
+		p.Amount = dcrutil.Amount(amount).ToCoin()
+		switch {
+		case tspend:
+			p.Source = apitypes.PaymentSourceTSpend
+		case legacy:
+			p.Source = apitypes.PaymentSourceLegacyTreasury
+		default:
+			p.Source = apitypes.PaymentSourceOther
+		}
+		payments = append(payments, p)
 	}
-	err = rows.Err()
-
-	return
+	return payments, rows.Err()
+}
+
+// --- reorgs table ---
+
+// InsertReorg records a chain reorganization.
+func InsertReorg(db *sql.DB, reorg *dbtypes.Reorg) error {
+	_, err := db.Exec(internal.InsertReorgRow, reorg.OldTipHash,
+		reorg.OldTipHeight, reorg.NewTipHash, reorg.NewTipHeight,
+		reorg.CommonAncestor, reorg.Depth, reorg.OrphanedTxns, reorg.Time)
+	return err
+}
+
+// UpsertBlockPropagation records the time the block was received from dcrd,
+// which may be the zero time if unknown, and the time it was stored.
+func UpsertBlockPropagation(db *sql.DB, hash string, height int64, blockTime,
+	receivedAt, storedAt time.Time) error {
+	var received pq.NullTime
+	if !receivedAt.IsZero() {
+		received = pq.NullTime{Time: receivedAt, Valid: true}
+	}
+	_, err := db.Exec(internal.UpsertBlockPropagation, hash, height, blockTime,
+		received, storedAt)
+	return err
+}
+
+// RetrieveBlockPropagation retrieves the propagation latency of the N most
+// recent mainchain blocks, oldest first.
+func RetrieveBlockPropagation(ctx context.Context, db *sql.DB, N int64) (*apitypes.BlockPropagation, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectBlockPropagation, N)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	prop := &apitypes.BlockPropagation{
+		Height:  []int64{},
+		Time:    []dbtypes.TimeDef{},
+		Receive: []*float64{},
+		Store:   []*float64{},
+		Total:   []float64{},
+	}
+	for rows.Next() {
+		var height int64
+		var blockTime, storedAt time.Time
+		var receivedAt pq.NullTime
+		if err = rows.Scan(&height, &blockTime, &receivedAt, &storedAt); err != nil {
+			return nil, err
+		}
+		var receive, store *float64
+		if receivedAt.Valid {
+			r := receivedAt.Time.Sub(blockTime).Seconds()
+			s := storedAt.Sub(receivedAt.Time).Seconds()
+			receive, store = &r, &s
+		}
+		prop.Height = append(prop.Height, height)
+		prop.Time = append(prop.Time, dbtypes.NewTimeDef(blockTime))
+		prop.Receive = append(prop.Receive, receive)
+		prop.Store = append(prop.Store, store)
+		prop.Total = append(prop.Total, storedAt.Sub(blockTime).Seconds())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Reverse to put the oldest block first.
+	for i, j := 0, len(prop.Height)-1; i < j; i, j = i+1, j-1 {
+		prop.Height[i], prop.Height[j] = prop.Height[j], prop.Height[i]
+		prop.Time[i], prop.Time[j] = prop.Time[j], prop.Time[i]
+		prop.Receive[i], prop.Receive[j] = prop.Receive[j], prop.Receive[i]
+		prop.Store[i], prop.Store[j] = prop.Store[j], prop.Store[i]
+		prop.Total[i], prop.Total[j] = prop.Total[j], prop.Total[i]
+	}
+	return prop, nil
+}
+
+// RetrieveReorgs retrieves N recorded chain reorganizations, most recent
+// first, after skipping the first offset, and the total number of reorgs.
+func RetrieveReorgs(ctx context.Context, db *sql.DB, N, offset int64) ([]*dbtypes.Reorg, int64, error) {
+	var total int64
+	err := db.QueryRowContext(ctx, internal.SelectReorgsCount).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectReorgs, N, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closeRows(rows)
+
+	var reorgs []*dbtypes.Reorg
+	for rows.Next() {
+		var r dbtypes.Reorg
+		err = rows.Scan(&r.OldTipHash, &r.OldTipHeight, &r.NewTipHash,
+			&r.NewTipHeight, &r.CommonAncestor, &r.Depth, &r.OrphanedTxns, &r.Time)
+		if err != nil {
+			return nil, 0, err
+		}
+		reorgs = append(reorgs, &r)
+	}
+	return reorgs, total, rows.Err()
+}
+
+// --- rich_list and balance_distribution tables ---
+
+// balanceDistributionMins are the minimum balances, in atoms, of the ranges of
+// the balance distribution: under 1 DCR, 1-10 DCR, ..., and 1M DCR and over.
+var balanceDistributionMins = pq.Int64Array{0, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13, 1e14}
+
+// RebuildRichList replaces the rich list with the top N addresses by balance,
+// recording the given best block height, and rebuilds the balance
+// distribution. Both are rebuilt in one transaction so that readers never see
+// partially rebuilt tables.
+func RebuildRichList(db *sql.DB, N int, height int64) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{internal.CreateTempAddressBalances, nil},
+		{internal.DeleteRichList, nil},
+		{internal.InsertRichList, []interface{}{N, height}},
+		{internal.DeleteBalanceDistribution, nil},
+		{internal.InsertBalanceDistribution, []interface{}{balanceDistributionMins}},
+	} {
+		if _, err = dbTx.Exec(stmt.query, stmt.args...); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+// RetrieveRichList retrieves the top N addresses of the rich list, and the
+// balance distribution.
+func RetrieveRichList(ctx context.Context, db *sql.DB, N int) (*apitypes.RichList, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectRichList, N)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	richList := &apitypes.RichList{
+		Addresses:    []apitypes.RichListAddress{},
+		Distribution: []apitypes.BalanceRange{},
+	}
+	for rows.Next() {
+		var a apitypes.RichListAddress
+		var balance int64
+		var updated time.Time
+		if err = rows.Scan(&a.Rank, &a.Address, &balance, &richList.Height, &updated); err != nil {
+			return nil, err
+		}
+		a.Balance = dcrutil.Amount(balance).ToCoin()
+		richList.Updated = apitypes.TimeAPI{S: dbtypes.NewTimeDef(updated)}
+		richList.Addresses = append(richList.Addresses, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, internal.SelectBalanceDistribution)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var minBalance, total int64
+		var r apitypes.BalanceRange
+		if err = rows.Scan(&minBalance, &r.NumAddresses, &total); err != nil {
+			return nil, err
+		}
+		r.MinBalance = dcrutil.Amount(minBalance).ToCoin()
+		r.TotalBalance = dcrutil.Amount(total).ToCoin()
+		richList.Distribution = append(richList.Distribution, r)
+	}
+	return richList, rows.Err()
+}
+
+// --- api_keys table ---
+
+// RetrieveAPIKeyQuota retrieves the rate limit and burst of the API key, which
+// is looked up by its SHA-256 hash.
+func RetrieveAPIKeyQuota(ctx context.Context, db *sql.DB, key string) (rateLimit float64, burst int, err error) {
+	keyHash := sha256.Sum256([]byte(key))
+	err = db.QueryRowContext(ctx, internal.SelectAPIKeyQuota,
+		hex.EncodeToString(keyHash[:])).Scan(&rateLimit, &burst)
+	return
+}
+
+// --- side_chain_blocks table ---
+
+// InsertSideChainBlock stores the serialized side chain block. A block that is
+// already stored is not updated.
+func InsertSideChainBlock(db *sql.DB, msgBlock *wire.MsgBlock) error {
+	raw, err := msgBlock.Bytes()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(internal.InsertSideChainBlockRow, msgBlock.BlockHash().String(),
+		int64(msgBlock.Header.Height), raw)
+	return err
+}
+
+// RetrieveSideChainBlock retrieves the stored block with the given hash, and
+// its validity, if it is not on the main chain. sql.ErrNoRows is returned if
+// the block is on the main chain or its data is not stored.
+func RetrieveSideChainBlock(ctx context.Context, db *sql.DB, hash string) (*wire.MsgBlock, bool, error) {
+	var raw []byte
+	var isValid bool
+	err := db.QueryRowContext(ctx, internal.SelectSideChainBlockRaw, hash).Scan(&raw, &isValid)
+	if err != nil {
+		return nil, false, err
+	}
+	msgBlock := new(wire.MsgBlock)
+	if err = msgBlock.FromBytes(raw); err != nil {
+		return nil, false, err
+	}
+	return msgBlock, isValid, nil
+}
+
+// --- vsp_stats table ---
+
+// InsertVSPStats stores a snapshot of a VSP's statistics.
+func InsertVSPStats(db *sql.DB, stats *dbtypes.VSPStats) error {
+	_, err := db.Exec(internal.InsertVSPStatsRow, stats.VSP, stats.Time,
+		stats.Reachable, stats.Closed, stats.VspdVersion, stats.FeePercentage,
+		stats.Live, stats.Voted, stats.Missed)
+	return err
+}
+
+// RetrieveLatestVSPStats retrieves the most recent statistics of each VSP,
+// ordered by VSP.
+func RetrieveLatestVSPStats(ctx context.Context, db *sql.DB) ([]*dbtypes.VSPStats, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectLatestVSPStats)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	vsps := []*dbtypes.VSPStats{}
+	for rows.Next() {
+		var vs dbtypes.VSPStats
+		err = rows.Scan(&vs.VSP, &vs.Time, &vs.Reachable, &vs.Closed,
+			&vs.VspdVersion, &vs.FeePercentage, &vs.Live, &vs.Voted, &vs.Missed)
+		if err != nil {
+			return nil, err
+		}
+		vsps = append(vsps, &vs)
+	}
+	return vsps, rows.Err()
+}
+
+// --- dcr_prices table ---
+
+// InsertDCRPrices stores the daily DCR prices, replacing any stored prices for
+// the same currency and days.
+func InsertDCRPrices(db *sql.DB, prices []*dbtypes.DCRPrice) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertDCRPrice)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, p := range prices {
+		if _, err = stmt.Exec(p.Day, p.Currency, p.Price, p.Source); err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// RetrieveLastDCRPriceDay retrieves the last day with a DCR price in the
+// currency, or the zero time if there are none.
+func RetrieveLastDCRPriceDay(ctx context.Context, db *sql.DB, currency string) (time.Time, error) {
+	var day pq.NullTime
+	err := db.QueryRowContext(ctx, internal.SelectLastDCRPriceDay, currency).Scan(&day)
+	return day.Time, err
+}
+
+// retrieveDCRPrices retrieves the daily DCR prices in the currency, ordered by
+// day.
+func retrieveDCRPrices(ctx context.Context, db *sql.DB, currency string) (dcrPrices, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectDCRPrices, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var prices dcrPrices
+	for rows.Next() {
+		var p dbtypes.DCRPrice
+		if err = rows.Scan(&p.Day, &p.Currency, &p.Price, &p.Source); err != nil {
+			return nil, err
+		}
+		p.Day = p.Day.UTC()
+		prices = append(prices, &p)
+	}
+	return prices, rows.Err()
+}
+
+// maxDCRPriceAge is the maximum time between the start of the day of a price
+// and the end of a period valued with it. A day's closing price is at most a
+// day old at the end of the following day.
+const maxDCRPriceAge = 48 * time.Hour
+
+// dcrPrices are daily DCR prices in a currency, ordered by day.
+type dcrPrices []*dbtypes.DCRPrice
+
+// closeBefore returns the closing price of the last day starting before t,
+// i.e. the last price known at t. ok is false if there is no such price, or if
+// it is too old to value a period ending at t.
+func (prices dcrPrices) closeBefore(t time.Time) (price float64, ok bool) {
+	i := sort.Search(len(prices), func(i int) bool {
+		return !prices[i].Day.Before(t)
+	})
+	if i == 0 || t.Sub(prices[i-1].Day) > maxDCRPriceAge {
+		return 0, false
+	}
+	return prices[i-1].Price, true
+}
+
+// fiatValues values the DCR amounts at the end of each period of the time
+// grouping, which start at the given times, with the last price known at the
+// end of the period. For AllGrouping, each period is a block and the amounts
+// are valued at the block time. The value is nil for the periods without a
+// price.
+func (prices dcrPrices) fiatValues(grouping dbtypes.TimeBasedGrouping,
+	periods []dbtypes.TimeDef, amounts []float64) []*float64 {
+	now := time.Now()
+	values := make([]*float64, len(amounts))
+	for i, amount := range amounts {
+		start := periods[i].T.UTC()
+		var end time.Time
+		switch grouping {
+		case dbtypes.YearGrouping:
+			end = start.AddDate(1, 0, 0)
+		case dbtypes.MonthGrouping:
+			end = start.AddDate(0, 1, 0)
+		case dbtypes.WeekGrouping:
+			end = start.AddDate(0, 0, 7)
+		case dbtypes.DayGrouping:
+			end = start.AddDate(0, 0, 1)
+		default:
+			end = start
+		}
+		if end.After(now) {
+			end = now
+		}
+		price, ok := prices.closeBefore(end)
+		if !ok {
+			continue
+		}
+		value := amount * price
+		values[i] = &value
+	}
+	return values
+}
+
+// --- market_candles table ---
+
+// InsertMarketPrices adds the price samples to the market candles of every bin
+// containing their times. The samples of an exchange must be in time order.
+func InsertMarketPrices(db *sql.DB, prices []*dbtypes.MarketPrice) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertMarketPriceSample)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, p := range prices {
+		for bin, width := range dbtypes.MarketCandleBins {
+			start := p.Time.UTC().Truncate(width)
+			if _, err = stmt.Exec(p.Exchange, bin, start, p.Price); err != nil {
+				_ = stmt.Close()
+				_ = dbTx.Rollback()
+				return err
+			}
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// InsertMarketCandles stores the market candles, replacing any stored candles
+// of the same exchange, bin and start.
+func InsertMarketCandles(db *sql.DB, candles []*dbtypes.MarketCandle) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertMarketCandle)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, c := range candles {
+		_, err = stmt.Exec(c.Exchange, c.Bin, c.Start, c.Open, c.High, c.Low,
+			c.Close, c.Volume)
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// retrieveMarketCandles retrieves the market candles of the exchange and bin
+// starting in [from, to), ordered by start. The volume of the candles of the
+// AggregatedMarket is the total volume of the exchanges.
+func retrieveMarketCandles(ctx context.Context, db *sql.DB, exchange, bin string,
+	from, to time.Time) ([]*dbtypes.MarketCandle, error) {
+	query := internal.SelectMarketCandles
+	if exchange == dbtypes.AggregatedMarket {
+		query = internal.SelectAggregatedMarketCandles
+	}
+	rows, err := db.QueryContext(ctx, query, exchange, bin, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var candles []*dbtypes.MarketCandle
+	for rows.Next() {
+		c := dbtypes.MarketCandle{
+			Exchange: exchange,
+			Bin:      bin,
+		}
+		err = rows.Scan(&c.Start, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume)
+		if err != nil {
+			return nil, err
+		}
+		c.Start = c.Start.UTC()
+		candles = append(candles, &c)
+	}
+	return candles, rows.Err()
+}
+
+// --- script_type_blocks and nonstandard_outputs tables ---
+
+// InsertScriptTypes stores the script type counts and the nonstandard outputs
+// of a block.
+func InsertScriptTypes(db *sql.DB, blockHash string, blockTime time.Time,
+	counts []*dbtypes.ScriptTypeCount, nonstandard []*dbtypes.NonstandardOutput) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertScriptTypeBlock)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, stc := range counts {
+		_, err = stmt.Exec(blockHash, blockTime, stc.ScriptType, stc.Count, stc.Value)
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	for _, out := range nonstandard {
+		if err = insertNonstandardOutput(dbTx, out); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+// insertNonstandardOutput inserts a nonstandard output unless it is already
+// stored for the block.
+func insertNonstandardOutput(db SqlExecutor, out *dbtypes.NonstandardOutput) error {
+	pkScript, err := hex.DecodeString(out.PkScript)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(internal.InsertNonstandardOutput, out.BlockHash, out.TxHash,
+		out.Vout, out.Tree, out.BlockHeight, out.BlockTime.T, out.Value,
+		out.ScriptVersion, pkScript, out.Disasm)
+	return err
+}
+
+// BackfillScriptTypes classifies the output scripts of the blocks already in
+// the vouts table, storing the script type counts of each block and the
+// nonstandard outputs.
+func BackfillScriptTypes(db *sql.DB) error {
+	if _, err := db.Exec(internal.BackfillScriptTypeBlocks); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(internal.SelectNonstandardVouts)
+	if err != nil {
+		return err
+	}
+	var outputs []*dbtypes.NonstandardOutput
+	for rows.Next() {
+		var out dbtypes.NonstandardOutput
+		var blockTime time.Time
+		var pkScript []byte
+		err = rows.Scan(&out.BlockHash, &out.TxHash, &out.Vout, &out.Tree,
+			&out.BlockHeight, &blockTime, &out.Value, &out.ScriptVersion, &pkScript)
+		if err != nil {
+			closeRows(rows)
+			return err
+		}
+		out.BlockTime = dbtypes.NewTimeDef(blockTime)
+		out.PkScript = hex.EncodeToString(pkScript)
+		// The disassembly of an unparsable script ends with [error].
+		out.Disasm, _ = txscript.DisasmString(pkScript)
+		outputs = append(outputs, &out)
+	}
+	closeRows(rows)
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, out := range outputs {
+		if err = insertNonstandardOutput(db, out); err != nil {
+			return err
+		}
+	}
+	log.Infof("Stored %d nonstandard outputs.", len(outputs))
+	return nil
+}
+
+// retrieveScriptTypeCounts retrieves the number and value of the mainchain
+// outputs of each script type in each period of the time grouping.
+func retrieveScriptTypeCounts(ctx context.Context, db *sql.DB,
+	timeGrouping string) (*apitypes.ScriptTypeCounts, error) {
+	rows, err := db.QueryContext(ctx, internal.MakeSelectScriptTypeCounts(timeGrouping))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	stc := &apitypes.ScriptTypeCounts{
+		Time:   []dbtypes.TimeDef{},
+		Counts: map[string][]int64{},
+		Values: map[string][]float64{},
+	}
+	for rows.Next() {
+		var period time.Time
+		var scriptType string
+		var count, value int64
+		if err = rows.Scan(&period, &scriptType, &count, &value); err != nil {
+			return nil, err
+		}
+		// The rows of each period are consecutive.
+		if n := len(stc.Time); n == 0 || !stc.Time[n-1].T.Equal(period) {
+			stc.Time = append(stc.Time, dbtypes.NewTimeDef(period))
+			for st := range stc.Counts {
+				stc.Counts[st] = append(stc.Counts[st], 0)
+				stc.Values[st] = append(stc.Values[st], 0)
+			}
+		}
+		n := len(stc.Time)
+		if _, found := stc.Counts[scriptType]; !found {
+			stc.Counts[scriptType] = make([]int64, n)
+			stc.Values[scriptType] = make([]float64, n)
+		}
+		stc.Counts[scriptType][n-1] = count
+		stc.Values[scriptType][n-1] = dcrutil.Amount(value).ToCoin()
+	}
+	return stc, rows.Err()
+}
+
+// RetrieveNonstandardOutputs retrieves a page of the mainchain nonstandard
+// outputs, most recent first, and the total number of them.
+func RetrieveNonstandardOutputs(ctx context.Context, db *sql.DB, N, offset int64) ([]*dbtypes.NonstandardOutput, int64, error) {
+	var total int64
+	err := db.QueryRowContext(ctx, internal.SelectNonstandardOutputsCount).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectNonstandardOutputs, N, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closeRows(rows)
+
+	outputs := []*dbtypes.NonstandardOutput{}
+	for rows.Next() {
+		var out dbtypes.NonstandardOutput
+		var blockTime time.Time
+		var pkScript []byte
+		err = rows.Scan(&out.BlockHash, &out.TxHash, &out.Vout, &out.Tree,
+			&out.BlockHeight, &blockTime, &out.Value, &out.ScriptVersion,
+			&pkScript, &out.Disasm)
+		if err != nil {
+			return nil, 0, err
+		}
+		out.BlockTime = dbtypes.NewTimeDef(blockTime)
+		out.PkScript = hex.EncodeToString(pkScript)
+		outputs = append(outputs, &out)
+	}
+	return outputs, total, rows.Err()
+}
+
+// --- address_watches table ---
+
+// InsertAddressWatch inserts an address watch registration, returning its ID.
+func InsertAddressWatch(ctx context.Context, db *sql.DB, watch *dbtypes.AddressWatch) (id int64, err error) {
+	err = db.QueryRowContext(ctx, internal.InsertAddressWatchRow, watch.Address,
+		watch.CallbackURL, watch.Secret, watch.Created).Scan(&id)
+	return
+}
+
+// RetrieveAddressWatches retrieves all address watch registrations, ordered by
+// ID.
+func RetrieveAddressWatches(ctx context.Context, db *sql.DB) ([]*dbtypes.AddressWatch, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressWatches)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var watches []*dbtypes.AddressWatch
+	for rows.Next() {
+		var aw dbtypes.AddressWatch
+		err = rows.Scan(&aw.ID, &aw.Address, &aw.CallbackURL, &aw.Secret, &aw.Created)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, &aw)
+	}
+	return watches, rows.Err()
+}
+
+// DeleteAddressWatch deletes the address watch registration with the given ID
+// and secret, returning sql.ErrNoRows if there is no such registration.
+func DeleteAddressWatch(ctx context.Context, db *sql.DB, id int64, secret string) error {
+	res, err := db.ExecContext(ctx, internal.DeleteAddressWatchRow, id, secret)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// --- faucet_grants table ---
+
+// InsertFaucetGrant inserts a faucet grant, returning its ID.
+func InsertFaucetGrant(ctx context.Context, db *sql.DB, grant *dbtypes.FaucetGrant) (id int64, err error) {
+	err = db.QueryRowContext(ctx, internal.InsertFaucetGrantRow, grant.Address,
+		grant.Amount, grant.TxHash, grant.Time).Scan(&id)
+	return
+}
+
+// RetrieveLastFaucetGrant retrieves the most recent faucet grant to the
+// address, returning sql.ErrNoRows if there are none.
+func RetrieveLastFaucetGrant(ctx context.Context, db *sql.DB, address string) (*dbtypes.FaucetGrant, error) {
+	var fg dbtypes.FaucetGrant
+	err := db.QueryRowContext(ctx, internal.SelectLastFaucetGrant, address).
+		Scan(&fg.ID, &fg.Address, &fg.Amount, &fg.TxHash, &fg.Time)
+	if err != nil {
+		return nil, err
+	}
+	return &fg, nil
+}
+
+// --- address_tags table ---
+
+// UpsertAddressTag inserts an address label, or replaces the address's label.
+func UpsertAddressTag(ctx context.Context, db *sql.DB, tag *dbtypes.AddressTag) error {
+	_, err := db.ExecContext(ctx, internal.UpsertAddressTagRow, tag.Address,
+		tag.Label, tag.Category, tag.Source, tag.Updated)
+	return err
+}
+
+// ImportAddressTags inserts or replaces the address labels in a transaction,
+// except those set with the admin API, returning the number of labels stored.
+func ImportAddressTags(ctx context.Context, db *sql.DB, tags []*dbtypes.AddressTag) (int64, error) {
+	dbtx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbtx.PrepareContext(ctx, internal.UpsertImportedAddressTagRow)
+	if err != nil {
+		_ = dbtx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var numStored int64
+	for _, tag := range tags {
+		res, err := stmt.ExecContext(ctx, tag.Address, tag.Label, tag.Category,
+			tag.Source, tag.Updated)
+		if err != nil {
+			_ = dbtx.Rollback()
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			_ = dbtx.Rollback()
+			return 0, err
+		}
+		numStored += n
+	}
+
+	return numStored, dbtx.Commit()
+}
+
+// RetrieveAddressTags retrieves all address labels, ordered by category and
+// label.
+func RetrieveAddressTags(ctx context.Context, db *sql.DB) ([]*dbtypes.AddressTag, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressTags)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var tags []*dbtypes.AddressTag
+	for rows.Next() {
+		var tag dbtypes.AddressTag
+		err = rows.Scan(&tag.Address, &tag.Label, &tag.Category, &tag.Source,
+			&tag.Updated)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+// DeleteAddressTag deletes the label of the address, returning sql.ErrNoRows if
+// the address has no label.
+func DeleteAddressTag(ctx context.Context, db *sql.DB, address string) error {
+	res, err := db.ExecContext(ctx, internal.DeleteAddressTagRow, address)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// --- swaps table ---
+
+// InsertSwaps inserts the atomic swap contracts redeemed or refunded by the
+// block's regular transactions into the swaps table.
+func InsertSwaps(db *sql.DB, msgBlock *wire.MsgBlock, params *chaincfg.Params, isMainchain bool) error {
+	blockHash := msgBlock.BlockHash().String()
+	height := int64(msgBlock.Header.Height)
+	blockTime := dbtypes.NewTimeDef(msgBlock.Header.Timestamp)
+
+	var dbTx *sql.Tx
+	for _, tx := range msgBlock.Transactions {
+		swaps, err := txhelpers.MsgTxAtomicSwaps(tx, params)
+		if err != nil {
+			log.Warnf("Failed to extract the atomic swaps of txn %v: %v", tx.TxHash(), err)
+			continue
+		}
+		if len(swaps) == 0 {
+			continue
+		}
+
+		// Most blocks have no swaps, so only begin the DB transaction once
+		// there is a swap to insert.
+		if dbTx == nil {
+			if dbTx, err = db.Begin(); err != nil {
+				return fmt.Errorf("unable to begin database transaction: %v", err)
+			}
+		}
+
+		spendHash := tx.TxHash().String()
+		for _, swap := range swaps {
+			_, err = dbTx.Exec(internal.UpsertSwapRow, swap.ContractTx,
+				swap.ContractVout, swap.ContractAddress, swap.RecipientAddress,
+				swap.RefundAddress, swap.Value, swap.SecretHash[:], swap.Secret,
+				swap.LockTime, spendHash, swap.Vin, blockHash, height, blockTime,
+				isMainchain)
+			if err != nil {
+				_ = dbTx.Rollback()
+				return fmt.Errorf("failed to insert atomic swap: %v", err)
+			}
+		}
+	}
+
+	if dbTx == nil {
+		return nil
+	}
+	return dbTx.Commit()
+}
+
+// UpdateSwapsMainchain sets the is_mainchain column for the atomic swaps
+// spent in the specified block.
+func UpdateSwapsMainchain(db SqlExecutor, blockHash string, isMainchain bool) (int64, error) {
+	return sqlExec(db, internal.UpdateSwapsMainchainByBlock,
+		"failed to update swaps is_mainchain", isMainchain, blockHash)
+}
+
+// scanSwapRows scans the rows of a swaps query.
+func scanSwapRows(rows *sql.Rows) ([]*apitypes.AtomicSwap, error) {
+	defer closeRows(rows)
+
+	swaps := []*apitypes.AtomicSwap{}
+	for rows.Next() {
+		var swap apitypes.AtomicSwap
+		var value int64
+		var secretHash, secret []byte
+		var blockTime time.Time
+		err := rows.Scan(&swap.ContractTx, &swap.ContractVout,
+			&swap.ContractAddress, &swap.RecipientAddress, &swap.RefundAddress,
+			&value, &secretHash, &secret, &swap.LockTime, &swap.SpendTx,
+			&swap.SpendVin, &swap.BlockHeight, &blockTime)
+		if err != nil {
+			return nil, err
+		}
+		swap.Value = dcrutil.Amount(value).ToCoin()
+		swap.SecretHash = hex.EncodeToString(secretHash)
+		swap.Secret = hex.EncodeToString(secret)
+		swap.IsRefund = secret == nil
+		swap.Time = apitypes.NewTimeAPI(blockTime)
+		swaps = append(swaps, &swap)
+	}
+	return swaps, rows.Err()
+}
+
+// retrieveRecentSwaps retrieves the N most recent mainchain atomic swap
+// redemptions and refunds, skipping the first offset.
+func retrieveRecentSwaps(ctx context.Context, db *sql.DB, N, offset int64) ([]*apitypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectRecentSwaps, N, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanSwapRows(rows)
+}
+
+// retrieveSwapsForTx retrieves the mainchain atomic swap redemptions and
+// refunds of the contracts created or spent by the transaction.
+func retrieveSwapsForTx(ctx context.Context, db *sql.DB, txid string) ([]*apitypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSwapsForTx, txid)
+	if err != nil {
+		return nil, err
+	}
+	return scanSwapRows(rows)
+}
+
+// --- coin_age_blocks, coin_age_deltas and coin_age_bands tables ---
+
+// RetrieveCoinAgeFlows retrieves the value created by the valid transactions
+// of the mainchain block at the given height, and the value and funding time of
+// each output they spend.
+func RetrieveCoinAgeFlows(ctx context.Context, db *sql.DB, height int64) (*dbtypes.CoinAgeFlows, error) {
+	flows := &dbtypes.CoinAgeFlows{Height: height}
+	err := db.QueryRowContext(ctx, internal.SelectCoinAgeBlockCreated, height).
+		Scan(&flows.Hash, &flows.Time, &flows.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectCoinAgeBlockSpent, height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var in dbtypes.CoinAgeInput
+		if err = rows.Scan(&in.Value, &in.FundingTime); err != nil {
+			return nil, err
+		}
+		flows.Spent = append(flows.Spent, in)
+	}
+	return flows, rows.Err()
+}
+
+// InsertCoinAgeBlock inserts the coin days destroyed by a block and its
+// changes to the unspent value created on each day, in one transaction.
+func InsertCoinAgeBlock(db *sql.DB, block *dbtypes.CoinAgeBlock) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	_, err = dbTx.Exec(internal.InsertCoinAgeBlock, block.Height, block.Hash,
+		block.Time, block.Spent, block.CoinDaysDestroyed)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for day, delta := range block.DayDeltas {
+		_, err = dbTx.Exec(internal.InsertCoinAgeDelta, block.Height,
+			dbtypes.NewTimeDefFromUNIX(day), delta)
+		if err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+// RetrieveCoinAgeTip retrieves the height, hash and time of the last block
+// stored in the coin_age_blocks table. The height is -1 if there are none.
+func RetrieveCoinAgeTip(ctx context.Context, db *sql.DB) (height int64, hash string, blockTime dbtypes.TimeDef, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectCoinAgeTip).Scan(&height, &hash, &blockTime)
+	if err == sql.ErrNoRows {
+		return -1, "", blockTime, nil
+	}
+	return
+}
+
+// RetrieveCoinAgeDays retrieves the unspent value created on each day as of
+// the block at the given height, keyed by the UNIX time of the start of the
+// day.
+func RetrieveCoinAgeDays(ctx context.Context, db *sql.DB, height int64) (map[int64]int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectCoinAgeDays, height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	days := make(map[int64]int64)
+	for rows.Next() {
+		var day dbtypes.TimeDef
+		var value int64
+		if err = rows.Scan(&day, &value); err != nil {
+			return nil, err
+		}
+		days[day.UNIX()] = value
+	}
+	return days, rows.Err()
+}
+
+// UpsertCoinAgeBands stores the coin age bands snapshot of a block.
+func UpsertCoinAgeBands(db *sql.DB, bands *dbtypes.CoinAgeBands) error {
+	_, err := db.Exec(internal.UpsertCoinAgeBands, bands.Height, bands.Time,
+		pq.Int64Array(bands.Bands))
+	return err
+}
+
+// DeleteCoinAgeAbove deletes the coin age records of the blocks above the
+// given height, in one transaction.
+func DeleteCoinAgeAbove(db *sql.DB, height int64) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	for _, stmt := range []string{internal.DeleteCoinAgeBlocksAbove,
+		internal.DeleteCoinAgeDeltasAbove, internal.DeleteCoinAgeBandsAbove} {
+		if _, err = dbTx.Exec(stmt, height); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+// retrieveCoinDaysDestroyed retrieves the value spent and the coin days
+// destroyed in each period of the time grouping.
+func retrieveCoinDaysDestroyed(ctx context.Context, db *sql.DB,
+	timeGrouping string) (*apitypes.CoinDaysDestroyed, error) {
+	rows, err := db.QueryContext(ctx, internal.MakeSelectCoinDaysDestroyed(timeGrouping))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	cdd := &apitypes.CoinDaysDestroyed{
+		Time:              []dbtypes.TimeDef{},
+		Spent:             []float64{},
+		CoinDaysDestroyed: []float64{},
+	}
+	for rows.Next() {
+		var period time.Time
+		var spent int64
+		var coinDays float64
+		if err = rows.Scan(&period, &spent, &coinDays); err != nil {
+			return nil, err
+		}
+		cdd.Time = append(cdd.Time, dbtypes.NewTimeDef(period))
+		cdd.Spent = append(cdd.Spent, dcrutil.Amount(spent).ToCoin())
+		cdd.CoinDaysDestroyed = append(cdd.CoinDaysDestroyed, coinDays)
+	}
+	return cdd, rows.Err()
+}
+
+// retrieveCoinAgeBands retrieves the coin age bands snapshots in ascending
+// height order.
+func retrieveCoinAgeBands(ctx context.Context, db *sql.DB) (*apitypes.CoinAgeBands, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectCoinAgeBands)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	bands := &apitypes.CoinAgeBands{
+		Height: []int64{},
+		Time:   []dbtypes.TimeDef{},
+		Values: [][]float64{},
+	}
+	for rows.Next() {
+		var height int64
+		var snapshotTime dbtypes.TimeDef
+		var atoms pq.Int64Array
+		if err = rows.Scan(&height, &snapshotTime, &atoms); err != nil {
+			return nil, err
+		}
+		values := make([]float64, len(atoms))
+		for i, v := range atoms {
+			values[i] = dcrutil.Amount(v).ToCoin()
+		}
+		bands.Height = append(bands.Height, height)
+		bands.Time = append(bands.Time, snapshotTime)
+		bands.Values = append(bands.Values, values)
+	}
+	return bands, rows.Err()
+}
+
+// --- address_clusters, cluster_addresses and address_cluster_state tables ---
+
+// RetrieveClusterInputs retrieves the distinct input addresses of each valid
+// regular transaction of the mainchain block at the given height, excluding
+// the CSPP mix transactions.
+func RetrieveClusterInputs(ctx context.Context, db *sql.DB, height int64) ([][]string, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectClusterInputs, height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var inputs [][]string
+	var lastTx string
+	for rows.Next() {
+		var txHash, address string
+		if err = rows.Scan(&txHash, &address); err != nil {
+			return nil, err
+		}
+		if txHash != lastTx || len(inputs) == 0 {
+			inputs = append(inputs, nil)
+			lastTx = txHash
+		}
+		inputs[len(inputs)-1] = append(inputs[len(inputs)-1], address)
+	}
+	return inputs, rows.Err()
+}
+
+// RetrieveAddressClusterIDs retrieves the cluster ids of the given addresses.
+// Addresses that are not clustered are not in the map.
+func RetrieveAddressClusterIDs(ctx context.Context, db *sql.DB, addrs []string) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressClusterIDs, pq.Array(addrs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	ids := make(map[string]int64, len(addrs))
+	for rows.Next() {
+		var address string
+		var id int64
+		if err = rows.Scan(&address, &id); err != nil {
+			return nil, err
+		}
+		ids[address] = id
+	}
+	return ids, rows.Err()
+}
+
+// InsertClusterBlock applies the address cluster changes of a block and
+// records the block as the last processed, in one transaction.
+func InsertClusterBlock(db *sql.DB, block *dbtypes.ClusterBlock) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	for i := range block.Merges {
+		if err = applyClusterMerge(dbTx, &block.Merges[i]); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	_, err = dbTx.Exec(internal.UpsertAddressClusterState, block.Height, block.Hash)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// applyClusterMerge creates the target cluster if needed, moves the addresses
+// of the merged clusters and the new addresses to it, and updates its size.
+func applyClusterMerge(dbTx *sql.Tx, merge *dbtypes.ClusterMerge) error {
+	target := merge.Target
+	if target == 0 {
+		if err := dbTx.QueryRow(internal.InsertAddressCluster).Scan(&target); err != nil {
+			return err
+		}
+	}
+	if len(merge.Merged) > 0 {
+		_, err := dbTx.Exec(internal.MergeAddressClusters, target, pq.Int64Array(merge.Merged))
+		if err != nil {
+			return err
+		}
+		_, err = dbTx.Exec(internal.DeleteAddressClusters, pq.Int64Array(merge.Merged))
+		if err != nil {
+			return err
+		}
+	}
+	if len(merge.Addresses) > 0 {
+		_, err := dbTx.Exec(internal.InsertClusterAddresses, target, pq.Array(merge.Addresses))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := dbTx.Exec(internal.UpdateAddressClusterSize, target)
+	return err
+}
+
+// RetrieveAddressClusterTip retrieves the height and hash of the last block
+// processed for address clustering. The height is -1 if there is none.
+func RetrieveAddressClusterTip(ctx context.Context, db *sql.DB) (height int64, hash string, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectAddressClusterState).Scan(&height, &hash)
+	if err == sql.ErrNoRows {
+		return -1, "", nil
+	}
+	return
+}
+
+// retrieveAddressCluster retrieves the cluster of the address, and the unspent
+// value of its addresses. An address that is not clustered is a cluster of
+// one address with id zero.
+func retrieveAddressCluster(ctx context.Context, db *sql.DB, address string) (*apitypes.AddressCluster, error) {
+	cluster := &apitypes.AddressCluster{Address: address}
+	height, _, err := RetrieveAddressClusterTip(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	cluster.Height = height
+
+	var balance int64
+	err = db.QueryRowContext(ctx, internal.SelectAddressCluster, address).
+		Scan(&cluster.ClusterID, &cluster.NumAddresses)
+	switch err {
+	case nil:
+		err = db.QueryRowContext(ctx, internal.SelectAddressClusterBalance,
+			cluster.ClusterID).Scan(&balance)
+	case sql.ErrNoRows:
+		cluster.NumAddresses = 1
+		err = db.QueryRowContext(ctx, internal.SelectAddressUnspentValue,
+			address).Scan(&balance)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cluster.Balance = dcrutil.Amount(balance).ToCoin()
+	return cluster, nil
+}
+
+// --- mempool_history table ---
+
+// UpsertMempoolHistory records the transactions seen in mempool at seenAt,
+// inserting the new transactions and updating the last seen time of the
+// others.
+func UpsertMempoolHistory(db *sql.DB, txs []*dbtypes.MempoolHistoryTx, seenAt time.Time) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertMempoolHistory)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+
+	for _, tx := range txs {
+		_, err = stmt.Exec(tx.TxHash, tx.TxType, time.Unix(tx.FirstSeen, 0),
+			seenAt, tx.Size, tx.Fees, tx.FeeRate)
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	_ = stmt.Close()
+	return dbTx.Commit()
+}
+
+// ResolveMempoolHistory sets the fate of the pending transactions not seen in
+// mempool since the snapshot at seenAt. Those in a mainchain block are mined,
+// and the others not seen since evictBefore are evicted.
+func ResolveMempoolHistory(db *sql.DB, seenAt, evictBefore time.Time) (mined, evicted int64, err error) {
+	mined, err = sqlExec(db, internal.SetMempoolHistoryMined,
+		"failed to set mined mempool_history transactions:", seenAt)
+	if err != nil {
+		return
+	}
+	evicted, err = sqlExec(db, internal.SetMempoolHistoryEvicted,
+		"failed to set evicted mempool_history transactions:", evictBefore)
+	return
+}
+
+// retrieveMempoolTxHistory retrieves the mempool history of the transaction.
+// sql.ErrNoRows is returned if the transaction was not seen in mempool.
+func retrieveMempoolTxHistory(ctx context.Context, db *sql.DB, txHash string) (*apitypes.MempoolTxHistory, error) {
+	var firstSeen, lastSeen time.Time
+	var fees, feeRate int64
+	var minedHeight sql.NullInt64
+	var evicted bool
+	var blockTime pq.NullTime
+	hist := &apitypes.MempoolTxHistory{TxID: txHash}
+	err := db.QueryRowContext(ctx, internal.SelectMempoolHistoryTx, txHash).Scan(
+		&hist.Type, &firstSeen, &lastSeen, &hist.Size, &fees, &feeRate,
+		&minedHeight, &evicted, &blockTime)
+	if err != nil {
+		return nil, err
+	}
+
+	hist.FirstSeen = firstSeen.Unix()
+	hist.LastSeen = lastSeen.Unix()
+	hist.Fees = dcrutil.Amount(fees).ToCoin()
+	hist.FeeRate = dcrutil.Amount(feeRate).ToCoin()
+	switch {
+	case minedHeight.Valid:
+		hist.Fate = apitypes.MempoolTxMined
+		hist.MinedHeight = &minedHeight.Int64
+		if blockTime.Valid {
+			confirmSeconds := blockTime.Time.Unix() - hist.FirstSeen
+			hist.ConfirmSeconds = &confirmSeconds
+		}
+	case evicted:
+		hist.Fate = apitypes.MempoolTxEvicted
+	default:
+		hist.Fate = apitypes.MempoolTxPending
+	}
+	return hist, nil
+}
+
+// retrieveMempoolCongestion retrieves the mempool history aggregated by the
+// period of the time grouping in which the transactions were first seen.
+func retrieveMempoolCongestion(ctx context.Context, db *sql.DB,
+	timeGrouping string) (*apitypes.MempoolCongestion, error) {
+	rows, err := db.QueryContext(ctx, internal.MakeSelectMempoolCongestion(timeGrouping))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	congestion := new(apitypes.MempoolCongestion)
+	for rows.Next() {
+		var period time.Time
+		var count, mined, evicted int64
+		var medianFeeRate, meanConfirmSeconds float64
+		err = rows.Scan(&period, &count, &medianFeeRate, &mined,
+			&meanConfirmSeconds, &evicted)
+		if err != nil {
+			return nil, err
+		}
+		congestion.Time = append(congestion.Time, dbtypes.NewTimeDef(period))
+		congestion.Count = append(congestion.Count, count)
+		congestion.MedianFeeRate = append(congestion.MedianFeeRate,
+			dcrutil.Amount(int64(medianFeeRate)).ToCoin())
+		congestion.Mined = append(congestion.Mined, mined)
+		congestion.MeanConfirmSeconds = append(congestion.MeanConfirmSeconds,
+			meanConfirmSeconds)
+		congestion.Evicted = append(congestion.Evicted, evicted)
+	}
+	return congestion, rows.Err()
+}
+
+// --- search queries and the proposal_titles table ---
+
+// StoreProposalTitles inserts or updates the titles of the proposals in the
+// proposal_titles table.
+func StoreProposalTitles(db *sql.DB, titles []dbtypes.ProposalTitle) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %v", err)
+	}
+
+	stmt, err := dbTx.Prepare(internal.UpsertProposalTitle)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range titles {
+		t := &titles[i]
+		if _, err = stmt.Exec(t.Token, t.RefID, t.Title); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	return dbTx.Commit()
+}
+
+// retrieveSearchProposals retrieves the proposals with titles matching the
+// words of the query, a RefID equal to the query, or a token with the prefix
+// tokenPrefix if it is not empty.
+func retrieveSearchProposals(ctx context.Context, db *sql.DB, query, tokenPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	prefix := sql.NullString{String: tokenPrefix, Valid: tokenPrefix != ""}
+	rows, err := db.QueryContext(ctx, internal.SearchProposalTitles, query, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var results []*apitypes.SearchResult
+	for rows.Next() {
+		r := &apitypes.SearchResult{Type: apitypes.SearchResultProposal}
+		if err = rows.Scan(&r.ID, &r.Title, &r.Rank); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// scanSearchResults scans the rows of (ID, height) pairs into search results
+// of the given type.
+func scanSearchResults(rows *sql.Rows, resultType string) ([]*apitypes.SearchResult, error) {
+	defer closeRows(rows)
+
+	var results []*apitypes.SearchResult
+	for rows.Next() {
+		r := &apitypes.SearchResult{Type: resultType}
+		var height int64
+		if err := rows.Scan(&r.ID, &height); err != nil {
+			return nil, err
+		}
+		r.Height = &height
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// retrieveSearchBlocks retrieves the mainchain blocks with the hash prefix.
+func retrieveSearchBlocks(ctx context.Context, db *sql.DB, hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	rows, err := db.QueryContext(ctx, internal.SearchBlocksByHashPrefix, hashPrefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSearchResults(rows, apitypes.SearchResultBlock)
+}
+
+// retrieveSearchBlockRange retrieves the mainchain blocks in the inclusive
+// height range.
+func retrieveSearchBlockRange(ctx context.Context, db *sql.DB, from, to int64, limit int) ([]*apitypes.SearchResult, error) {
+	rows, err := db.QueryContext(ctx, internal.SearchBlocksByHeightRange, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSearchResults(rows, apitypes.SearchResultBlock)
+}
+
+// retrieveSearchTransactions retrieves the transactions with the hash prefix.
+func retrieveSearchTransactions(ctx context.Context, db *sql.DB, hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	rows, err := db.QueryContext(ctx, internal.SearchTransactionsByHashPrefix, hashPrefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSearchResults(rows, apitypes.SearchResultTx)
+}
+
+// retrieveSearchAddresses retrieves the addresses with the prefix.
+func retrieveSearchAddresses(ctx context.Context, db *sql.DB, prefix string, limit int) ([]*apitypes.SearchResult, error) {
+	rows, err := db.QueryContext(ctx, internal.SearchAddressesByPrefix, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var results []*apitypes.SearchResult
+	for rows.Next() {
+		r := &apitypes.SearchResult{Type: apitypes.SearchResultAddress}
+		if err = rows.Scan(&r.ID); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// --- blocks and block_chain tables ---
+
+// InsertBlock inserts the specified dbtypes.Block as with the given
+// valid/mainchain status. If checked is true, an upsert statement is used so
+// that a unique constraint violation will result in an update instead of
+// attempting to insert a duplicate row. If checked is false and there is a
+// duplicate row, an error will be returned.
+func InsertBlock(db *sql.DB, dbBlock *dbtypes.Block, isValid, isMainchain, checked bool) (uint64, error) {
+	insertStatement := internal.BlockInsertStatement(checked)
+	var id uint64
+	err := db.QueryRow(insertStatement,
+		dbBlock.Hash, dbBlock.Height, dbBlock.Size, isValid, isMainchain,
+		int32(dbBlock.Version), dbBlock.NumTx, dbBlock.NumRegTx,
+		pq.Array(dbBlock.Tx), pq.Array(dbBlock.TxDbIDs),
+		dbBlock.NumStakeTx, pq.Array(dbBlock.STx), pq.Array(dbBlock.STxDbIDs),
+		dbBlock.Time, int64(dbBlock.Nonce), int16(dbBlock.VoteBits), dbBlock.Voters,
+		dbBlock.FreshStake, dbBlock.Revocations, dbBlock.PoolSize, int64(dbBlock.Bits),
+		int64(dbBlock.SBits), dbBlock.Difficulty, int32(dbBlock.StakeVersion),
+		dbBlock.PreviousHash, dbBlock.ChainWork, pq.Array(dbBlock.Winners)).Scan(&id)
+	return id, err
+}
+
+// InsertBlockPrevNext inserts a new row of the block_chain table.
+func InsertBlockPrevNext(db *sql.DB, blockDbID uint64,
+	hash, prev, next string) error {
+	rows, err := db.Query(internal.InsertBlockPrevNext, blockDbID, prev, hash, next)
+	if err == nil {
+		return rows.Close()
+	}
+	return err
+}
+
+// InsertBlockStats inserts the block stats into the stats table.
+func InsertBlockStats(db *sql.DB, blockDbID uint64, tpi *apitypes.TicketPoolInfo) error {
+	_, err := db.Exec(internal.UpsertStats, blockDbID, tpi.Height, tpi.Size, int64(tpi.Value*dcrToAtoms))
+	return err
+}
+
+// RetrieveBestBlockHeight gets the best block height and hash (main chain
+// only). Be sure to check for sql.ErrNoRows.
+func RetrieveBestBlockHeight(ctx context.Context, db *sql.DB) (height uint64, hash string, id uint64, err error) {
+	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeight).Scan(&id, &hash, &height)
+	return
+}
+
+// RetrieveBestBlock gets the best block height and hash (main chain only). If
+// there are no results from the query, the height is -1 and err is nil.
+func RetrieveBestBlock(ctx context.Context, db *sql.DB) (height int64, hash string, err error) {
+	var bbHeight uint64
+	bbHeight, hash, _, err = RetrieveBestBlockHeight(ctx, db)
+	height = int64(bbHeight)
+	if err != nil && err == sql.ErrNoRows {
+		height = -1
+		err = nil
+	}
+	return
+}
+
+// RetrieveBestBlockHeightAny gets the best block height, including side chains.
+func RetrieveBestBlockHeightAny(ctx context.Context, db *sql.DB) (height uint64, hash string, id uint64, err error) {
+	err = db.QueryRowContext(ctx, internal.RetrieveBestBlockHeightAny).Scan(&id, &hash, &height)
+	return
+}
+
+// RetrieveBlockHash retrieves the hash of the block at the given height, if it
+// exists (be sure to check error against sql.ErrNoRows!). WARNING: this returns
+// the most recently added block at this height, but there may be others.
+func RetrieveBlockHash(ctx context.Context, db *sql.DB, idx int64) (hash string, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockHashByHeight, idx).Scan(&hash)
+	return
+}
+
+// RetrieveBlockChainWork retrieves the hash and stored chainwork of the main
+// chain block at the given height, if it exists (be sure to check error against
+// sql.ErrNoRows!).
+func RetrieveBlockChainWork(ctx context.Context, db *sql.DB, idx int64) (hash, chainWork string, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockChainWorkByHeight, idx).Scan(&hash, &chainWork)
+	return
+}
+
+// RetrieveBlockTimeByHeight retrieves time hash of the main chain block at the
+// given height, if it exists (be sure to check error against sql.ErrNoRows!).
+func RetrieveBlockTimeByHeight(ctx context.Context, db *sql.DB, idx int64) (time dbtypes.TimeDef, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockTimeByHeight, idx).Scan(&time)
+	return
+}
+
+// RetrieveBlockHeight retrieves the height of the block with the given hash, if
+// it exists (be sure to check error against sql.ErrNoRows!).
+func RetrieveBlockHeight(ctx context.Context, db *sql.DB, hash string) (height int64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockHeightByHash, hash).Scan(&height)
+	return
+}
+
+// RetrieveBlockVoteCount gets the number of votes mined in a block.
+func RetrieveBlockVoteCount(ctx context.Context, db *sql.DB, hash string) (numVotes int16, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockVoteCount, hash).Scan(&numVotes)
+	return
+}
+
+// RetrieveBlocksHashesAll retrieve the hash of every block in the blocks table,
+// ordered by their row ID.
+func RetrieveBlocksHashesAll(ctx context.Context, db *sql.DB) ([]string, error) {
+	var hashes []string
+	rows, err := db.QueryContext(ctx, internal.SelectBlocksHashes)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var hash string
+		err = rows.Scan(&hash)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+	err = rows.Err()
+
+	return hashes, err
+}
+
+// RetrieveBlockChainDbID retrieves the row id in the block_chain table of the
+// block with the given hash, if it exists (be sure to check error against
+// sql.ErrNoRows!).
+func RetrieveBlockChainDbID(ctx context.Context, db *sql.DB, hash string) (dbID uint64, err error) {
+	err = db.QueryRowContext(ctx, internal.SelectBlockChainRowIDByHash, hash).Scan(&dbID)
+	return
+}
+
+// RetrieveSideChainBlocks retrieves the block chain status for all known side
+// chain blocks.
+func RetrieveSideChainBlocks(ctx context.Context, db *sql.DB) (blocks []*dbtypes.BlockStatus, err error) {
+	var rows *sql.Rows
+	rows, err = db.QueryContext(ctx, internal.SelectSideChainBlocks)
+	if err != nil {
+		return
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var bs dbtypes.BlockStatus
+		err = rows.Scan(&bs.IsValid, &bs.Height, &bs.PrevHash, &bs.Hash, &bs.NextHash)
+		if err != nil {
+			return
+		}
+
+		blocks = append(blocks, &bs)
+	}
+	err = rows.Err()
+
+	return
 }
 
 // RetrieveSideChainTips retrieves the block chain status for all known side
@@ -4378,6 +6874,39 @@ func UpdateLastVins(db *sql.DB, blockHash string, isValid, isMainchain bool) err
 	return nil
 }
 
+// RetrieveAddressBalanceAtHeight retrieves the balance of an address as of the
+// time of the mainchain block at the given height, from the valid mainchain
+// funding and spending rows of the addresses table. The address's pending rows
+// are first materialized. If there is no mainchain block at the height, the
+// error is sql.ErrNoRows.
+func RetrieveAddressBalanceAtHeight(ctx context.Context, db *sql.DB, address string, height int64) (int64, error) {
+	blockTime, err := RetrieveBlockTimeByHeight(ctx, db, height)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = db.ExecContext(ctx, internal.MaterializeAddressBalances, address); err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = db.QueryRowContext(ctx, internal.SelectAddressBalanceAtTime, address,
+		blockTime).Scan(&balance)
+	if err == sql.ErrNoRows {
+		// No activity at or before the block.
+		return 0, nil
+	}
+	return balance, err
+}
+
+// invalidateAddressBalances clears the materialized address balances from the
+// time of the given block, so that they are recomputed when next requested.
+func invalidateAddressBalances(db SqlExecutor, blockHash string) error {
+	_, err := sqlExec(db, internal.InvalidateAddressBalancesFromBlock,
+		"failed to invalidate address balances:", blockHash)
+	return err
+}
+
 // UpdateLastAddressesValid sets valid_mainchain as specified by isValid for
 // addresses table rows pertaining to regular (non-stake) transactions found in
 // the given block.
@@ -4401,7 +6930,10 @@ func UpdateLastAddressesValid(db *sql.DB, blockHash string, isValid bool) error
 	}
 	addrsUpdated := numAddrSpending + numAddrFunding
 	log.Debugf("Rows of addresses table updated: %d", addrsUpdated)
-	return err
+	if err != nil {
+		return err
+	}
+	return invalidateAddressBalances(db, blockHash)
 }
 
 // UpdateBlockNext sets the next block's hash for the specified row of the
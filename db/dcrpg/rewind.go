@@ -55,6 +55,22 @@ func deleteVotesForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err
 	return sqlExec(dbTx, internal.DeleteVotes, "failed to delete votes", hash)
 }
 
+func deleteTreasuryForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
+	rowsDeleted, err = sqlExec(dbTx, internal.DeleteTreasuryTxnsForBlock,
+		"failed to delete treasury txns", hash)
+	if err != nil {
+		return
+	}
+	var numVotes int64
+	numVotes, err = sqlExec(dbTx, internal.DeleteTreasuryVotesForBlock,
+		"failed to delete treasury votes", hash)
+	return rowsDeleted + numVotes, err
+}
+
+func deleteSwapsForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, internal.DeleteSwapsForBlock, "failed to delete swaps", hash)
+}
+
 func deleteTicketsForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
 	return sqlExec(dbTx, internal.DeleteTicketsSimple, "failed to delete tickets", hash)
 }
@@ -253,6 +269,18 @@ func DeleteBlockData(ctx context.Context, db *sql.DB, hash string) (res dbtypes.
 	}
 	res.Timings.Misses = time.Since(start).Nanoseconds()
 
+	if _, err = deleteTreasuryForBlock(dbTx, hash); err != nil {
+		err = fmt.Errorf(`deleteTreasuryForBlock failed with "%v". Rollback: %v`,
+			err, dbTx.Rollback())
+		return
+	}
+
+	if _, err = deleteSwapsForBlock(dbTx, hash); err != nil {
+		err = fmt.Errorf(`deleteSwapsForBlock failed with "%v". Rollback: %v`,
+			err, dbTx.Rollback())
+		return
+	}
+
 	start = time.Now()
 	if res.Blocks, err = deleteBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteBlock failed with "%v". Rollback: %v`,
@@ -311,7 +339,13 @@ func DeleteBestBlock(ctx context.Context, db *sql.DB) (res dbtypes.DeletionSumma
 		return
 	}
 
-	err = SetDBBestBlock(db, hash, height)
+	if err = SetDBBestBlock(db, hash, height); err != nil {
+		return
+	}
+
+	// The spending info for the removed block will need to be populated again
+	// if it is replaced.
+	err = lowerAddressSpendInfoHeight(db, height)
 	return
 }
 
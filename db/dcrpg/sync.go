@@ -485,6 +485,10 @@ func (pgb *ChainDB) SyncChainDB(ctx context.Context, client rpcutils.MasterBlock
 		if err = AnalyzeTable(pgb.db, "addresses", deepStatsTarget); err != nil {
 			return nodeHeight, fmt.Errorf("failed to ANALYZE addresses table: %v", err)
 		}
+
+		// Updating the spending info of every row left a dead tuple for each
+		// updated row of the vouts and addresses tables.
+		pgb.RequestMaintenance("vouts", "addresses")
 	}
 
 	// Quickly ANALYZE all tables if not already done after indexing.
@@ -29,6 +29,39 @@ var createTableStatements = [][2]string{
 	{"proposals", internal.CreateProposalsTable},
 	{"proposal_votes", internal.CreateProposalVotesTable},
 	{"stats", internal.CreateStatsTable},
+	{"treasury", internal.CreateTreasuryTable},
+	{"treasury_votes", internal.CreateTreasuryVotesTable},
+	{"swaps", internal.CreateSwapsTable},
+	{"sync_state", internal.CreateSyncStateTable},
+	{"address_spend_info", internal.CreateAddressSpendInfoTable},
+	{"reorgs", internal.CreateReorgsTable},
+	{"rich_list", internal.CreateRichListTable},
+	{"balance_distribution", internal.CreateBalanceDistributionTable},
+	{"vsp_stats", internal.CreateVSPStatsTable},
+	{"api_keys", internal.CreateAPIKeysTable},
+	{"side_chain_blocks", internal.CreateSideChainBlocksTable},
+	{"agenda_vote_intervals", internal.CreateAgendaVoteIntervalsTable},
+	{"address_watches", internal.CreateAddressWatchesTable},
+	{"address_tags", internal.CreateAddressTagsTable},
+	{"coin_age_blocks", internal.CreateCoinAgeBlocksTable},
+	{"coin_age_deltas", internal.CreateCoinAgeDeltasTable},
+	{"coin_age_bands", internal.CreateCoinAgeBandsTable},
+	{"address_clusters", internal.CreateAddressClustersTable},
+	{"cluster_addresses", internal.CreateClusterAddressesTable},
+	{"address_cluster_state", internal.CreateAddressClusterStateTable},
+	{"proposal_titles", internal.CreateProposalTitlesTable},
+	{"prune_state", internal.CreatePruneStateTable},
+	{"pruned_supply", internal.CreatePrunedSupplyTable},
+	{"block_propagation", internal.CreateBlockPropagationTable},
+	{"mempool_history", internal.CreateMempoolHistoryTable},
+	{"faucet_grants", internal.CreateFaucetGrantsTable},
+	{"proposal_vote_snapshots", internal.CreateProposalVoteSnapshotsTable},
+	{"politeia_proposals", internal.CreatePoliteiaProposalsTable},
+	{"dcr_prices", internal.CreateDCRPricesTable},
+	{"market_candles", internal.CreateMarketCandlesTable},
+	{"script_type_blocks", internal.CreateScriptTypeBlocksTable},
+	{"nonstandard_outputs", internal.CreateNonstandardOutputsTable},
+	{"schema_migrations", internal.CreateSchemaMigrationsTable},
 }
 
 func createTableMap() map[string]string {
@@ -80,6 +113,62 @@ func DropTables(db *sql.DB) {
 	}
 }
 
+// TableNames returns the names of all of the internally recognized tables, in
+// the order they are created.
+func TableNames() []string {
+	names := make([]string, 0, len(createTableStatements))
+	for _, pair := range createTableStatements {
+		names = append(names, pair[0])
+	}
+	return names
+}
+
+// orderTablesForDrop checks that each of the specified table names is
+// internally recognized, and returns the unique names in the reverse of the
+// order in which the tables are created. An error is returned if any name is
+// not recognized.
+func orderTablesForDrop(tableNames []string) ([]string, error) {
+	tableMap := createTableMap()
+	requested := make(map[string]bool, len(tableNames))
+	for _, name := range tableNames {
+		if _, found := tableMap[name]; !found {
+			return nil, fmt.Errorf("table name %q unknown (known tables: %v)",
+				name, TableNames())
+		}
+		requested[name] = true
+	}
+
+	ordered := make([]string, 0, len(requested))
+	for i := len(createTableStatements) - 1; i >= 0; i-- {
+		if name := createTableStatements[i][0]; requested[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered, nil
+}
+
+// DropTablesByName drops only the specified tables, which must all be
+// internally recognized. The names are validated before any table is dropped,
+// and the tables are dropped in the reverse of the order in which they are
+// created. The names of the tables that were dropped are returned, even if an
+// error is encountered.
+func DropTablesByName(db *sql.DB, tableNames []string) ([]string, error) {
+	ordered, err := orderTablesForDrop(tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make([]string, 0, len(ordered))
+	for _, tableName := range ordered {
+		log.Infof("DROPPING the %q table.", tableName)
+		if err = dropTable(db, tableName); err != nil {
+			return dropped, fmt.Errorf("DROP TABLE %q failed: %v", tableName, err)
+		}
+		dropped = append(dropped, tableName)
+	}
+	return dropped, nil
+}
+
 // DropTestingTable drops only the "testing" table.
 func DropTestingTable(db SqlExecutor) error {
 	_, err := db.Exec(`DROP TABLE IF EXISTS testing;`)
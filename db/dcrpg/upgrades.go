@@ -32,7 +32,7 @@ const (
 	// This includes changes such as creating tables, adding/deleting columns,
 	// adding/deleting indexes or any other operations that create, delete, or
 	// modify the definition of any database relation.
-	schemaVersion = 8
+	schemaVersion = 36
 
 	// maintVersion indicates when certain maintenance operations should be
 	// performed for the same compatVersion and schemaVersion. Such operations
@@ -155,13 +155,8 @@ func insertMetaData(db *sql.DB, meta *metaData) error {
 	return err
 }
 
-func updateSchemaVersion(db *sql.DB, schema uint32) error {
-	_, err := db.Exec(internal.SetDBSchemaVersion, schema)
-	return err
-}
-
-func updateMaintVersion(db *sql.DB, maint uint32) error {
-	_, err := db.Exec(internal.SetDBMaintenanceVersion, maint)
+func updateDBVersion(db SqlExecutor, ver DatabaseVersion) error {
+	_, err := db.Exec(internal.SetDBSchemaAndMaintenanceVersions, ver.schema, ver.maint)
 	return err
 }
 
@@ -172,9 +167,12 @@ type Upgrader struct {
 	bg      BlockGetter
 	stakeDB *stakedb.StakeDatabase
 	ctx     context.Context
+	dryRun  bool
+	backups bool
 }
 
-// NewUpgrader is a contructor for an Upgrader.
+// NewUpgrader is a contructor for an Upgrader. Tables modified by a migration
+// are not backed up unless enabled with EnableBackups.
 func NewUpgrader(ctx context.Context, db *sql.DB, bg BlockGetter, stakeDB *stakedb.StakeDatabase) *Upgrader {
 	return &Upgrader{
 		db:      db,
@@ -184,9 +182,25 @@ func NewUpgrader(ctx context.Context, db *sql.DB, bg BlockGetter, stakeDB *stake
 	}
 }
 
+// EnableDryRun specifies whether the Upgrader should only log the migrations
+// that would be applied or reverted, without modifying the database.
+func (u *Upgrader) EnableDryRun(dryRun bool) {
+	u.dryRun = dryRun
+}
+
+// EnableBackups specifies whether the tables modified by a migration are
+// copied before it is applied. Each copy takes as much disk space as the table,
+// e.g. the whole transactions table, so it is off by default. A migration whose
+// backups were not made cannot be reverted.
+func (u *Upgrader) EnableBackups(backups bool) {
+	u.backups = backups
+}
+
 // UpgradeDatabase attempts to upgrade the given sql.DB with help from the
 // BlockGetter. The DB version will be compared against the target version to
-// decide what upgrade type to initiate.
+// decide what upgrade type to initiate. The migrations are applied in order,
+// and each is recorded in the schema_migrations table. In dry run mode, the
+// migrations are only logged and false is returned.
 func (u *Upgrader) UpgradeDatabase() (bool, error) {
 	initVer, upgradeType, err := versionCheck(u.db)
 	if err != nil {
@@ -213,182 +227,12 @@ func (u *Upgrader) UpgradeDatabase() (bool, error) {
 func (u *Upgrader) upgradeDatabase(current, target DatabaseVersion) (bool, error) {
 	switch current.compat {
 	case 1:
-		return u.compatVersion1Upgrades(current, target)
+		return u.migrate(current, target)
 	default:
 		return false, fmt.Errorf("unsupported DB compatibility version %d", current.compat)
 	}
 }
 
-func (u *Upgrader) compatVersion1Upgrades(current, target DatabaseVersion) (bool, error) {
-	upgradeCheck := func() (done bool, err error) {
-		switch current.NeededToReach(&target) {
-		case OK:
-			// No upgrade needed.
-			return true, nil
-		case Upgrade, Maintenance:
-			// Automatic upgrade is supported.
-			return false, nil
-		case TimeTravel:
-			return false, fmt.Errorf("the current table version is newer than supported: "+
-				"%v > %v", current, target)
-		case Unknown, Rebuild:
-			fallthrough
-		default:
-			return false, fmt.Errorf("rebuild of entire database required")
-		}
-	}
-
-	// Initial upgrade status check.
-	done, err := upgradeCheck()
-	if done || err != nil {
-		return done, err
-	}
-
-	// Process schema upgrades and table maintenance.
-	initSchema := current.schema
-	switch current.schema {
-	case 0: // legacyDatabaseVersion
-		// Remove table comments where the versions were stored.
-		log.Infof("Performing database upgrade 1.0.0 -> 1.1.0")
-		removeTableComments(u.db)
-
-		// Bump schema version.
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-
-		// Continue to upgrades for the next schema version.
-		fallthrough
-	case 1:
-		// Upgrade to schema v2.
-		err = u.upgrade110to120()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.1.0 to 1.2.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		fallthrough
-	case 2:
-		// Upgrade to schema v3.
-		err = u.upgrade120to130()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.2.0 to 1.3.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		fallthrough
-
-	case 3:
-		// Upgrade to schema v4.
-		err = u.upgrade130to140()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.3.0 to 1.4.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		fallthrough
-
-	case 4:
-		// Upgrade to schema v5.
-		err = u.upgrade140to150()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.4.0 to 1.5.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		fallthrough
-
-	case 5:
-		// Perform schema v5 maintenance.
-		switch current.maint {
-		case 0:
-			// The maint 0 -> 1 upgrade is only needed if the user had upgraded
-			// to 1.5.0 before 1.5.1 was defined.
-			log.Infof("Performing database upgrade 1.5.0 -> 1.5.1")
-			if initSchema == 5 {
-				err = u.setTxMixData()
-				if err != nil {
-					return false, fmt.Errorf("failed to upgrade 1.5.0 to 1.5.1: %v", err)
-				}
-			}
-			current.maint++
-			if err = updateMaintVersion(u.db, current.maint); err != nil {
-				return false, fmt.Errorf("failed to update maintenance version: %v", err)
-			}
-			fallthrough
-		case 1:
-			// all ready
-		default:
-			return false, fmt.Errorf("unsupported maint version %d", current.maint)
-		}
-
-		// Upgrade to schema v6.
-		err = u.upgrade151to160()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.5.1 to 1.6.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		current.maint = 0
-		if err = updateMaintVersion(u.db, current.maint); err != nil {
-			return false, fmt.Errorf("failed to update maintenance version: %v", err)
-		}
-		fallthrough
-
-	case 6:
-		err = u.upgrade160to170()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.6.0 to 1.7.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		current.maint = 0
-		if err = updateMaintVersion(u.db, current.maint); err != nil {
-			return false, fmt.Errorf("failed to update maintenance version: %v", err)
-		}
-		fallthrough
-
-	case 7:
-		err = u.upgrade170to180()
-		if err != nil {
-			return false, fmt.Errorf("failed to upgrade 1.7.0 to 1.8.0: %v", err)
-		}
-		current.schema++
-		if err = updateSchemaVersion(u.db, current.schema); err != nil {
-			return false, fmt.Errorf("failed to update schema version: %v", err)
-		}
-		current.maint = 0
-		if err = updateMaintVersion(u.db, current.maint); err != nil {
-			return false, fmt.Errorf("failed to update maintenance version: %v", err)
-		}
-		fallthrough
-
-	case 8:
-		// Perform schema v8 maintenance.
-
-		// No further upgrades.
-		return upgradeCheck()
-
-		// Or continue to upgrades for the next schema version.
-		// fallthrough
-	default:
-		return false, fmt.Errorf("unsupported schema version %d", current.schema)
-	}
-}
-
 func removeTableComments(db *sql.DB) {
 	for _, pair := range createTableStatements {
 		tableName := pair[0]
@@ -399,23 +243,8 @@ func removeTableComments(db *sql.DB) {
 	}
 }
 
-func (u *Upgrader) upgrade170to180() error {
-	log.Infof("Performing database upgrade 1.7.0 -> 1.8.0")
-	// Index the transactions table on block height. This drastically
-	// accelerates several queries including those for the following charts
-	// updaters: fees, coin supply, privacy participation, and anonymity set.
-	return IndexTransactionTableOnBlockHeight(u.db)
-}
-
-func (u *Upgrader) upgrade160to170() error {
-	log.Infof("Performing database upgrade 1.6.0 -> 1.7.0")
-	// Create the missing vouts.spend_tx_row_id index.
-	return IndexVoutTableOnSpendTxID(u.db)
-}
-
 func (u *Upgrader) upgrade151to160() error {
 	// Add the mixed column to vouts table.
-	log.Infof("Performing database upgrade 1.5.1 -> 1.6.0")
 	_, err := u.db.Exec(`ALTER TABLE vouts
 		ADD COLUMN mixed BOOLEAN DEFAULT FALSE,
 		ADD COLUMN spend_tx_row_id INT8;`)
@@ -520,17 +349,21 @@ func (u *Upgrader) upgrade151to160() error {
 	return nil
 }
 
-func (u *Upgrader) upgrade140to150() error {
-	// Add the mix_count and mix_denom columns to the transactions table.
-	log.Infof("Performing database upgrade 1.4.0 -> 1.5.0")
-	_, err := u.db.Exec(`ALTER TABLE transactions
-		ADD COLUMN IF NOT EXISTS mix_count INT4 DEFAULT 0,
-		ADD COLUMN IF NOT EXISTS mix_denom INT8 DEFAULT 0;`)
+func (u *Upgrader) setVinsVoutsBlockHeight() error {
+	log.Infof("Setting vins.block_height (INT8) column. This will take a while...")
+	N, err := sqlExec(u.db, internal.SetVinsBlockHeight, "UPDATE vins.block_height error")
 	if err != nil {
-		return fmt.Errorf("ALTER TABLE transactions error: %v", err)
+		return err
 	}
+	log.Debugf("Updated %d rows of vins table.", N)
 
-	return u.setTxMixData()
+	log.Infof("Setting vouts.block_height (INT8) column. This will take a while...")
+	N, err = sqlExec(u.db, internal.SetVoutsBlockHeight, "UPDATE vouts.block_height error")
+	if err != nil {
+		return err
+	}
+	log.Debugf("Updated %d rows of vouts table.", N)
+	return nil
 }
 
 func (u *Upgrader) setTxMixData() error {
@@ -606,19 +439,15 @@ func (u *Upgrader) setTxMixData() error {
 	return err
 }
 
-// This changes the data type of votes.version from INT2 to INT4.
-func (u *Upgrader) upgrade130to140() error {
-	// Change the data type of votes.version.
-	log.Infof("Performing database upgrade 1.3.0 -> 1.4.0")
-	_, err := u.db.Exec(`ALTER TABLE votes ALTER COLUMN version TYPE INT4`)
-	return err
+// This classifies the output scripts of the blocks already stored, filling the
+// script_type_blocks and nonstandard_outputs tables.
+func (u *Upgrader) backfillScriptTypes() error {
+	log.Infof("Classifying the output scripts of the stored blocks. This may take a while...")
+	return BackfillScriptTypes(u.db)
 }
 
 // This indexes the blocks table on the "time" column.
 func (u *Upgrader) upgrade120to130() error {
-	// Create the stats table and height index.
-	log.Infof("Performing database upgrade 1.2.0 -> 1.3.0")
-
 	existsIdx, err := ExistsIndex(u.db, internal.IndexBlocksTableOnTime)
 	if err != nil {
 		return err
@@ -636,8 +465,10 @@ func (u *Upgrader) upgrade120to130() error {
 // dropped. As part of the upgrade, the entire blockchain must be requested and
 // the ticket pool evolved appropriately.
 func (u *Upgrader) upgrade110to120() error {
+	if u.stakeDB == nil {
+		return fmt.Errorf("a stake DB is required for this upgrade")
+	}
 	// Create the stats table and height index.
-	log.Infof("Performing database upgrade 1.1.0 -> 1.2.0")
 	exists, err := TableExists(u.db, "stats")
 	if err != nil {
 		return err
@@ -982,7 +982,7 @@ func (pgb *ChainDB) handleUpgrades(client BlockGetter, tableUpgrade tableUpgrade
 	case proposalsTableTokensUpdate:
 		// Handles update for proposals and proposal_votes table.
 		log.Info("Syncing Politeia's proposals votes data. This might take a while...")
-		rowsUpdated, err = pgb.PiProposalsHistory()
+		rowsUpdated, err = pgb.SyncProposals()
 
 	default:
 		return false, fmt.Errorf(`upgrade "%v" unknown`, tableUpgrade)
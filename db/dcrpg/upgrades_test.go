@@ -1,6 +1,7 @@
 package dcrpg
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -14,3 +15,86 @@ func TestMakeDeleteColumnsStmt(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, dropStmt)
 	}
 }
+
+func TestPendingMigrations(t *testing.T) {
+	last := migrations[len(migrations)-1].Version
+	if last != *targetDatabaseVersion {
+		t.Fatalf("last migration is to %v, not the target version %v", last, targetDatabaseVersion)
+	}
+	for i := 1; i < len(migrations); i++ {
+		prev, ver := migrations[i-1].Version, migrations[i].Version
+		if a := prev.NeededToReach(&ver); a != Upgrade && a != Maintenance {
+			t.Errorf("migration to %v follows migration to %v", ver, prev)
+		}
+	}
+
+	pending, err := pendingMigrations(*legacyDatabaseVersion, *targetDatabaseVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(migrations) {
+		t.Errorf("%d migrations from the legacy version, expected %d", len(pending), len(migrations))
+	}
+
+	pending, err = pendingMigrations(NewDatabaseVersion(1, 5, 0), *targetDatabaseVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending[0].Version != NewDatabaseVersion(1, 5, 1) {
+		t.Errorf("first migration from 1.5.0 is to %v, expected 1.5.1", pending[0].Version)
+	}
+
+	pending, err = pendingMigrations(*targetDatabaseVersion, *targetDatabaseVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d migrations from the target version, expected none", len(pending))
+	}
+
+	if _, err = pendingMigrations(*legacyDatabaseVersion, NewDatabaseVersion(1, 99, 0)); err == nil {
+		t.Error("found migrations to an unknown version")
+	}
+}
+
+func TestMakeDropTablesStmt(t *testing.T) {
+	tests := []struct {
+		tables []string
+		want   string
+	}{
+		{[]string{"swaps"}, "DROP TABLE IF EXISTS swaps;"},
+		{[]string{"treasury_votes", "treasury"}, "DROP TABLE IF EXISTS treasury_votes, treasury;"},
+	}
+
+	for _, test := range tests {
+		if got := makeDropTablesStmt(test.tables...); got != test.want {
+			t.Errorf("expected %s, got %s", test.want, got)
+		}
+	}
+}
+
+func TestTableMigrations(t *testing.T) {
+	// Each table is either created with the initial schema, or by a migration
+	// that drops it when reverted.
+	initial := map[string]bool{"meta": true, "blocks": true, "transactions": true,
+		"vins": true, "vouts": true, "block_chain": true, "addresses": true,
+		"tickets": true, "votes": true, "misses": true, "agendas": true,
+		"agenda_votes": true, "testing": true, "proposals": true,
+		"proposal_votes": true, "stats": true, "schema_migrations": true}
+	for _, pair := range createTableStatements {
+		table := pair[0]
+		if initial[table] {
+			continue
+		}
+		var found bool
+		for _, mg := range migrations {
+			if strings.Contains(mg.SQL, pair[1]) && strings.Contains(mg.Down, table) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no migration creates the %s table", table)
+		}
+	}
+}
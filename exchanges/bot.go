@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,9 +30,18 @@ const (
 	// DefaultRequestExpiry : Any data older than RequestExpiry will be discarded.
 	DefaultRequestExpiry = "60m"
 
+	// DefaultCurrencies are the fiat currencies requested from the Bitcoin
+	// index sources that do not provide every currency by default.
+	DefaultCurrencies = "USD,EUR,JPY,GBP"
+
 	defaultDCRRatesPort = "7778"
 
+	// liveDepthInterval is the time between collections of the depth data of
+	// the orderbooks that are synced over a websocket.
+	liveDepthInterval = 5 * time.Second
+
 	aggregatedOrderbookKey = "aggregated"
+	consolidatedDepthKey   = "consolidated"
 	orderbookKey           = "depth"
 )
 
@@ -41,6 +51,9 @@ var grpcClient dcrrates.DCRRatesClient
 // DataExpiry must be less than RequestExpiry.
 // Recommend RequestExpiry > 2*DataExpiry, which will permit the exchange API
 // request to fail a couple of times before the exchange's data is discarded.
+// Sources are the per-source settings, in the format parsed by
+// ParseSourceConfig. Currencies are the fiat currency codes to request from the
+// Bitcoin index sources, DefaultCurrencies if empty.
 type ExchangeBotConfig struct {
 	Disabled       []string
 	DataExpiry     string
@@ -49,6 +62,61 @@ type ExchangeBotConfig struct {
 	Indent         bool
 	MasterBot      string
 	MasterCertFile string
+	Sources        []string
+	Currencies     []string
+}
+
+// SourceConfig is the configuration of an individual exchange or Bitcoin index
+// source. Weight scales the source's contribution to the weighted median
+// exchange rate, and Interval is the time between calls to the source's API,
+// overriding the ExchangeBot's DataExpiry.
+type SourceConfig struct {
+	Disabled bool
+	Weight   float64
+	Interval time.Duration
+}
+
+// ParseSourceConfig parses the settings of a source given as the source's token
+// followed by a colon and a comma-separated list of key=value pairs, e.g.
+// "coinbase:weight=2,interval=10m" or "huobi:enabled=false". Settings that are
+// not given are left at their defaults: enabled, a weight of 1, and an interval
+// of DataExpiry.
+func ParseSourceConfig(s string) (string, *SourceConfig, error) {
+	parts := strings.SplitN(s, ":", 2)
+	token := strings.ToLower(strings.TrimSpace(parts[0]))
+	if !IsBtcIndex(token) && !IsDcrExchange(token) {
+		return "", nil, fmt.Errorf("unknown exchange %q", token)
+	}
+	cfg := &SourceConfig{Weight: 1}
+	if len(parts) == 1 {
+		return token, cfg, nil
+	}
+	for _, setting := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+		if len(kv) != 2 {
+			return "", nil, fmt.Errorf("invalid setting %q for exchange %s", setting, token)
+		}
+		var err error
+		switch kv[0] {
+		case "enabled":
+			var enabled bool
+			enabled, err = strconv.ParseBool(kv[1])
+			cfg.Disabled = !enabled
+		case "weight":
+			cfg.Weight, err = strconv.ParseFloat(kv[1], 64)
+			if err == nil && cfg.Weight < 0 {
+				err = fmt.Errorf("weight must not be negative")
+			}
+		case "interval":
+			cfg.Interval, err = time.ParseDuration(kv[1])
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid setting %q for exchange %s: %v", setting, token, err)
+		}
+	}
+	return token, cfg, nil
 }
 
 // ExchangeBot monitors exchanges and processes updates. When an update is
@@ -83,13 +151,20 @@ type ExchangeBot struct {
 	// Channels requested by the user.
 	updateChans []chan *ExchangeUpdate
 	indexChans  []chan *IndexUpdate
+	depthChans  []chan *DepthUpdate
 	quitChans   []chan struct{}
+	// depthTimes are the times of the last websocket orderbook updates
+	// collected, by token.
+	depthTimes map[string]time.Time
 	// exchangeChan and indexChan are passed to the individual exchanges and
 	// receive updates after a refresh is triggered.
 	exchangeChan chan *ExchangeUpdate
 	indexChan    chan *IndexUpdate
 	client       *http.Client
 	config       *ExchangeBotConfig
+	// sources holds the per-source settings, by token. Sources without
+	// settings have a weight of 1 and are refreshed every DataExpiry.
+	sources map[string]*SourceConfig
 	// The failed flag is set when there are either no up-to-date Bitcoin-fiat
 	// exchanges or no up-to-date Decred exchanges. IsFailed is a getter for failed.
 	failed bool
@@ -202,6 +277,20 @@ type IndexUpdate struct {
 	Indices FiatIndices
 }
 
+// DepthUpdate is sent to the UpdateChannels when the websocket-synced
+// orderbooks of the Decred exchanges have changed. Tokens are the exchanges
+// with new depth data.
+type DepthUpdate struct {
+	Tokens []string
+	Time   int64
+}
+
+// liveDepthSource is satisfied by the exchanges, via CommonExchange, and
+// provides the depth data of an orderbook that is synced over a websocket.
+type liveDepthSource interface {
+	liveDepth(since time.Time) (*DepthData, time.Time)
+}
+
 // BotChannels is passed to exchanges for communication with the Start loop.
 type BotChannels struct {
 	index    chan *IndexUpdate
@@ -213,6 +302,7 @@ type BotChannels struct {
 type UpdateChannels struct {
 	Exchange chan *ExchangeUpdate
 	Index    chan *IndexUpdate
+	Depth    chan *DepthUpdate
 	Quit     chan struct{}
 }
 
@@ -221,6 +311,7 @@ func NewUpdateChannels() *UpdateChannels {
 	return &UpdateChannels{
 		Exchange: make(chan *ExchangeUpdate, 16),
 		Index:    make(chan *IndexUpdate, 16),
+		Depth:    make(chan *DepthUpdate, 16),
 		Quit:     make(chan struct{}),
 	}
 }
@@ -282,6 +373,25 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 	if config.Disabled == nil {
 		config.Disabled = []string{}
 	}
+	if len(config.Currencies) == 0 {
+		config.Currencies = strings.Split(DefaultCurrencies, ",")
+	}
+
+	sources := make(map[string]*SourceConfig, len(config.Sources))
+	for _, s := range config.Sources {
+		token, sourceCfg, err := ParseSourceConfig(s)
+		if err != nil {
+			return nil, err
+		}
+		if sourceCfg.Interval == 0 {
+			sourceCfg.Interval = dataExpiry
+		}
+		if sourceCfg.Interval < time.Minute || sourceCfg.Interval > requestExpiry {
+			return nil, fmt.Errorf("Interval for exchange %s must be at least one minute "+
+				"and no longer than the request expiration", token)
+		}
+		sources[token] = sourceCfg
+	}
 
 	bot := &ExchangeBot{
 		DcrBtcExchanges: make(map[string]Exchange),
@@ -304,11 +414,14 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 		minTick:           5 * time.Second,
 		updateChans:       []chan *ExchangeUpdate{},
 		indexChans:        []chan *IndexUpdate{},
+		depthChans:        []chan *DepthUpdate{},
 		quitChans:         []chan struct{}{},
+		depthTimes:        make(map[string]time.Time),
 		exchangeChan:      make(chan *ExchangeUpdate, 16),
 		indexChan:         make(chan *IndexUpdate, 16),
 		client:            new(http.Client),
 		config:            config,
+		sources:           sources,
 		failed:            false,
 	}
 
@@ -336,6 +449,9 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 	}
 
 	isDisabled := func(token string) bool {
+		if sourceCfg, found := sources[token]; found && sourceCfg.Disabled {
+			return true
+		}
 		for _, tkn := range config.Disabled {
 			if tkn == token {
 				return true
@@ -361,6 +477,11 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 		if err != nil {
 			return
 		}
+		if requester, ok := xc.(currencyRequester); ok {
+			if err = requester.RequestCurrencies(config.Currencies); err != nil {
+				log.Warnf("Failed to request currencies %v from %s: %v", config.Currencies, token, err)
+			}
+		}
 		xcMap[token] = xc
 		bot.Exchanges[token] = xc
 	}
@@ -388,6 +509,8 @@ func NewExchangeBot(config *ExchangeBotConfig) (*ExchangeBot, error) {
 // and scheduling refresh cycles.
 func (bot *ExchangeBot) Start(ctx context.Context, wg *sync.WaitGroup) {
 	tick := time.NewTimer(time.Second)
+	depthTicker := time.NewTicker(liveDepthInterval)
+	defer depthTicker.Stop()
 
 	config := bot.config
 
@@ -486,6 +609,17 @@ out:
 			bot.signalIndexUpdate(update)
 		case <-tick.C:
 			bot.Cycle()
+		case <-depthTicker.C:
+			// The refresh cycle is not affected by the live depth updates.
+			update, err := bot.updateLiveDepths()
+			if err != nil {
+				log.Warnf("Error encountered in live depth update: %v", err)
+				continue
+			}
+			if update != nil {
+				bot.signalDepthUpdate(update)
+			}
+			continue
 		case <-ctx.Done():
 			break out
 		}
@@ -550,15 +684,18 @@ func (bot *ExchangeBot) subscribedExchanges() []string {
 func (bot *ExchangeBot) UpdateChannels() *UpdateChannels {
 	update := make(chan *ExchangeUpdate, 16)
 	index := make(chan *IndexUpdate, 16)
+	depth := make(chan *DepthUpdate, 16)
 	quit := make(chan struct{})
 	bot.mtx.Lock()
 	defer bot.mtx.Unlock()
 	bot.updateChans = append(bot.updateChans, update)
 	bot.indexChans = append(bot.indexChans, index)
+	bot.depthChans = append(bot.depthChans, depth)
 	bot.quitChans = append(bot.quitChans, quit)
 	return &UpdateChannels{
 		Exchange: update,
 		Index:    index,
+		Depth:    depth,
 		Quit:     quit,
 	}
 }
@@ -583,6 +720,15 @@ func (bot *ExchangeBot) signalIndexUpdate(update *IndexUpdate) {
 	}
 }
 
+func (bot *ExchangeBot) signalDepthUpdate(update *DepthUpdate) {
+	for _, ch := range bot.depthChans {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
 // State is a copy of the current ExchangeBotState. A JSON-encoded byte array
 // of the current state can be accessed through StateBytes().
 func (bot *ExchangeBot) State() *ExchangeBotState {
@@ -648,6 +794,119 @@ func (bot *ExchangeBot) ConvertedStateBytes(symbol string) ([]byte, error) {
 	return bot.encodeJSON(state)
 }
 
+// SourceRate is the latest price reported by a source, and the source's weight
+// in the weighted median.
+type SourceRate struct {
+	Token  string  `json:"token"`
+	Price  float64 `json:"price"`
+	Weight float64 `json:"weight"`
+	Stamp  int64   `json:"timestamp"`
+}
+
+// ExchangeRate is the DCR price in a fiat currency aggregated across the
+// sources. The DCR-BTC price is the weighted median of the Decred exchanges,
+// each weighted by its volume and configured weight, and the BTC price is the
+// weighted median of the Bitcoin index sources, weighted by their configured
+// weights.
+type ExchangeRate struct {
+	Currency   string        `json:"currency"`
+	Price      float64       `json:"price"`
+	BtcPrice   float64       `json:"btc_fiat_price"`
+	DcrBtc     float64       `json:"dcr_btc_price"`
+	DcrSources []*SourceRate `json:"dcr_btc_exchanges"`
+	BtcSources []*SourceRate `json:"btc_indices"`
+}
+
+// weightedMedian is the price at which the cumulative weight of the rates,
+// ordered by price, reaches half of the total weight. If the cumulative weight
+// is exactly half at a rate, the median is the mean of that rate's price and
+// the next. Rates without weight are ignored. The rates are sorted in place.
+func weightedMedian(rates []*SourceRate) float64 {
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Price < rates[j].Price
+	})
+	var total float64
+	for _, rate := range rates {
+		total += rate.Weight
+	}
+	if total <= 0 {
+		return 0
+	}
+	var cumulative float64
+	for i, rate := range rates {
+		if rate.Weight <= 0 {
+			continue
+		}
+		cumulative += rate.Weight
+		if cumulative < total/2 {
+			continue
+		}
+		if cumulative == total/2 {
+			for _, next := range rates[i+1:] {
+				if next.Weight > 0 {
+					return (rate.Price + next.Price) / 2
+				}
+			}
+		}
+		return rate.Price
+	}
+	return 0
+}
+
+// ExchangeRate computes the weighted median exchange rate for the currency
+// code from the sources with up-to-date data.
+func (bot *ExchangeBot) ExchangeRate(code string) (*ExchangeRate, error) {
+	bot.mtx.RLock()
+	defer bot.mtx.RUnlock()
+	oldestValid := time.Now().Add(-bot.RequestExpiry)
+
+	dcrSources := make([]*SourceRate, 0, len(bot.currentState.DcrBtc))
+	for token, state := range bot.currentState.DcrBtc {
+		if bot.Exchanges[token].LastUpdate().Before(oldestValid) {
+			continue
+		}
+		dcrSources = append(dcrSources, &SourceRate{
+			Token:  token,
+			Price:  state.Price,
+			Weight: state.Volume * bot.sourceWeight(token),
+			Stamp:  state.Stamp,
+		})
+	}
+
+	btcSources := make([]*SourceRate, 0, len(bot.indexMap))
+	for token, indices := range bot.indexMap {
+		price, found := indices[code]
+		if !found {
+			continue
+		}
+		lastUpdate := bot.Exchanges[token].LastUpdate()
+		if lastUpdate.Before(oldestValid) {
+			continue
+		}
+		btcSources = append(btcSources, &SourceRate{
+			Token:  token,
+			Price:  price,
+			Weight: bot.sourceWeight(token),
+			Stamp:  lastUpdate.Unix(),
+		})
+	}
+
+	dcrPrice := weightedMedian(dcrSources)
+	btcPrice := weightedMedian(btcSources)
+	if dcrPrice == 0 || btcPrice == 0 {
+		return nil, fmt.Errorf("Unable to process price for currency %s", code)
+	}
+
+	return &ExchangeRate{
+		Currency:   code,
+		Price:      dcrPrice * btcPrice,
+		BtcPrice:   btcPrice,
+		DcrBtc:     dcrPrice,
+		DcrSources: dcrSources,
+		BtcSources: btcSources,
+	}, nil
+}
+
 // AvailableIndices creates a fresh slice of all available index currency codes.
 func (bot *ExchangeBot) AvailableIndices() []string {
 	bot.mtx.RLock()
@@ -736,13 +995,60 @@ func (bot *ExchangeBot) updateExchange(update *ExchangeUpdate) error {
 		}
 	}
 	if update.State.Depth != nil {
-		bot.incrementChart(genCacheID(update.Token, orderbookKey))
-		bot.incrementChart(genCacheID(aggregatedOrderbookKey, orderbookKey))
+		bot.incrementDepthCharts(update.Token)
 	}
 	bot.currentState.DcrBtc[update.Token] = update.State
 	return bot.updateState()
 }
 
+// incrementDepthCharts increments the versions of the exchange's depth chart
+// and of the combined depth charts.
+func (bot *ExchangeBot) incrementDepthCharts(token string) {
+	bot.incrementChart(genCacheID(token, orderbookKey))
+	bot.incrementChart(genCacheID(aggregatedOrderbookKey, orderbookKey))
+	bot.incrementChart(genCacheID(consolidatedDepthKey, orderbookKey))
+}
+
+// updateLiveDepths replaces the depth data of the Decred exchanges whose
+// websocket-synced orderbooks have changed since the last collection. The
+// returned DepthUpdate is nil if none have changed. The states are replaced
+// rather than modified, since the state copies are read-only.
+func (bot *ExchangeBot) updateLiveDepths() (*DepthUpdate, error) {
+	bot.mtx.Lock()
+	defer bot.mtx.Unlock()
+	var tokens []string
+	for token, xc := range bot.DcrBtcExchanges {
+		source, ok := xc.(liveDepthSource)
+		if !ok {
+			continue
+		}
+		// An exchange's state is added with the first price update.
+		state, found := bot.currentState.DcrBtc[token]
+		if !found {
+			continue
+		}
+		depth, updated := source.liveDepth(bot.depthTimes[token])
+		if depth == nil {
+			continue
+		}
+		bot.depthTimes[token] = updated
+		newState := *state
+		newState.Depth = depth
+		bot.currentState.DcrBtc[token] = &newState
+		bot.incrementDepthCharts(token)
+		tokens = append(tokens, token)
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	sort.Strings(tokens)
+	update := &DepthUpdate{
+		Tokens: tokens,
+		Time:   time.Now().Unix(),
+	}
+	return update, bot.updateState()
+}
+
 // updateIndices processes an update from an Bitcoin index source, essentially
 // a map pairing currency codes to bitcoin prices.
 func (bot *ExchangeBot) updateIndices(update *IndexUpdate) error {
@@ -805,26 +1111,42 @@ func (bot *ExchangeBot) IsFailed() bool {
 // when the next Cycle should run.
 func (bot *ExchangeBot) nextTick() *time.Timer {
 	tNow := time.Now()
-	tOldest := tNow
-	for _, xc := range bot.Exchanges {
-		t := xc.LastTry()
-		if t.Before(tOldest) {
-			tOldest = t
+	tilNext := bot.DataExpiry
+	for token, xc := range bot.Exchanges {
+		tilXc := bot.refreshInterval(token) - tNow.Sub(xc.LastTry())
+		if tilXc < tilNext {
+			tilNext = tilXc
 		}
 	}
-	tSince := tNow.Sub(tOldest)
-	tilNext := bot.DataExpiry - tSince
 	if tilNext < bot.minTick {
 		tilNext = bot.minTick
 	}
 	return time.NewTimer(tilNext)
 }
 
+// refreshInterval is the time between calls to the API of the source, which is
+// DataExpiry unless configured otherwise.
+func (bot *ExchangeBot) refreshInterval(token string) time.Duration {
+	if sourceCfg, found := bot.sources[token]; found {
+		return sourceCfg.Interval
+	}
+	return bot.DataExpiry
+}
+
+// sourceWeight is the configured weight of the source in the weighted median
+// exchange rate, 1 by default.
+func (bot *ExchangeBot) sourceWeight(token string) float64 {
+	if sourceCfg, found := bot.sources[token]; found {
+		return sourceCfg.Weight
+	}
+	return 1
+}
+
 // Cycle refreshes all expired exchanges.
 func (bot *ExchangeBot) Cycle() {
 	tNow := time.Now()
-	for _, xc := range bot.Exchanges {
-		if tNow.Sub(xc.LastTry()) > bot.DataExpiry {
+	for token, xc := range bot.Exchanges {
+		if tNow.Sub(xc.LastTry()) > bot.refreshInterval(token) {
 			go xc.Refresh()
 		}
 	}
@@ -938,6 +1260,25 @@ func (bot *ExchangeBot) QuickSticks(token string, rawBin string) ([]byte, error)
 	return vChart.chart, nil
 }
 
+// EncodeSticks encodes candlesticks from another source, e.g. the stored
+// market candles, like the QuickSticks data for the bin width.
+func (bot *ExchangeBot) EncodeSticks(rawBin string, sticks Candlesticks) ([]byte, error) {
+	if len(sticks) == 0 {
+		return nil, fmt.Errorf("Empty candlesticks for bin %s", rawBin)
+	}
+	bin := candlestickKey(rawBin)
+	expiration := sticks[len(sticks)-1].Start.Add(2 * bin.duration())
+
+	bot.mtx.RLock()
+	defer bot.mtx.RUnlock()
+	return bot.encodeJSON(&candlestickResponse{
+		BtcIndex:   bot.BtcIndex,
+		Price:      bot.currentState.Price,
+		Sticks:     sticks,
+		Expiration: expiration.Unix(),
+	})
+}
+
 // Move the DepthPoint array into a map whose entries are agBookPt, inserting
 // the (DepthPoint).Quantity values at xcIndex of Volumes. Creates Volumes
 // if it does not yet exist.
@@ -1030,6 +1371,89 @@ func (bot *ExchangeBot) aggOrderbook() *aggregateOrderbook {
 	}
 }
 
+// ConsolidatedDepth is the combined orderbook of the Decred exchanges with
+// depth data. The quantities of the orders at the same price are summed across
+// the exchanges. The prices are in BTC, and Price is the DCR price in the
+// BtcIndex currency.
+type ConsolidatedDepth struct {
+	BtcIndex   string       `json:"btc_index"`
+	Price      float64      `json:"price"`
+	Tokens     []string     `json:"tokens"`
+	Time       int64        `json:"time"`
+	Bids       []DepthPoint `json:"bids"`
+	Asks       []DepthPoint `json:"asks"`
+	Expiration int64        `json:"expiration"`
+}
+
+// Sum the volumes of each price bin of the aggregated orderbook.
+func consolidateOrders(pts []agBookPt) []DepthPoint {
+	orders := make([]DepthPoint, 0, len(pts))
+	for _, pt := range pts {
+		var quantity float64
+		for _, vol := range pt.Volumes {
+			quantity += vol
+		}
+		orders = append(orders, DepthPoint{
+			Quantity: quantity,
+			Price:    pt.Price,
+		})
+	}
+	return orders
+}
+
+// consolidateDepth combines the exchanges' orders in the aggregated orderbook.
+func consolidateDepth(agDepth *aggregateOrderbook) *ConsolidatedDepth {
+	return &ConsolidatedDepth{
+		BtcIndex:   agDepth.BtcIndex,
+		Price:      agDepth.Price,
+		Tokens:     agDepth.Tokens,
+		Time:       agDepth.Data.Time,
+		Bids:       consolidateOrders(agDepth.Data.Bids),
+		Asks:       consolidateOrders(agDepth.Data.Asks),
+		Expiration: agDepth.Expiration,
+	}
+}
+
+// ConsolidatedDepth is the current combined orderbook of the Decred exchanges,
+// or nil if there is no exchange state.
+func (bot *ExchangeBot) ConsolidatedDepth() *ConsolidatedDepth {
+	agDepth := bot.aggOrderbook()
+	if agDepth == nil {
+		return nil
+	}
+	return consolidateDepth(agDepth)
+}
+
+// QuickConsolidatedDepth returns the up-to-date consolidated depth data,
+// pulling from the cache if appropriate.
+func (bot *ExchangeBot) QuickConsolidatedDepth() ([]byte, error) {
+	chartID := genCacheID(consolidatedDepthKey, orderbookKey)
+	data, bestVersion, isGood := bot.fetchFromCache(chartID)
+	if isGood {
+		return data, nil
+	}
+
+	depth := bot.ConsolidatedDepth()
+	if depth == nil || len(depth.Tokens) == 0 {
+		return nil, fmt.Errorf("Failed to find depth for any exchange")
+	}
+	chart, err := bot.encodeJSON(depth)
+	if err != nil {
+		return nil, fmt.Errorf("JSON encode error for consolidated depth chart")
+	}
+
+	vChart := &versionedChart{
+		chartID: chartID,
+		dataID:  bestVersion,
+		chart:   chart,
+	}
+
+	bot.mtx.Lock()
+	defer bot.mtx.Unlock()
+	bot.versionedCharts[chartID] = vChart
+	return vChart.chart, nil
+}
+
 // QuickDepth returns the up-to-date depth chart data for the specified
 // exchange, pulling from the cache if appropriate.
 func (bot *ExchangeBot) QuickDepth(token string) (chart []byte, err error) {
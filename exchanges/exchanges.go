@@ -76,6 +76,9 @@ func newRequests() requests {
 	}
 }
 
+// CoindeskCurrencyURL is the Coindesk price URL for a single currency code.
+const CoindeskCurrencyURL = "https://api.coindesk.com/v1/bpi/currentprice/%s.json"
+
 // Prepare the URLs.
 var (
 	CoinbaseURLs = URLs{
@@ -94,6 +97,9 @@ var (
 			dayKey:   "https://api.binance.com/api/v1/klines?symbol=DCRBTC&interval=1d",
 			monthKey: "https://api.binance.com/api/v1/klines?symbol=DCRBTC&interval=1M",
 		},
+		// The diff. depth stream, whose updates are merged into an orderbook
+		// snapshot retrieved from the Depth URL.
+		Websocket: "wss://stream.binance.com:9443/ws/dcrbtc@depth",
 	}
 	BittrexURLs = URLs{
 		Price: "https://bittrex.com/api/v1.1/public/getmarketsummary?market=btc-dcr",
@@ -393,6 +399,13 @@ type Exchange interface {
 	UpdateIndices(FiatIndices)
 }
 
+// currencyRequester is implemented by the Bitcoin index sources that only
+// provide some currencies by default, and need to be told which others to
+// request.
+type currencyRequester interface {
+	RequestCurrencies(codes []string) error
+}
+
 // CommonExchange is embedded in all of the exchange types and handles some
 // state tracking and token handling for ExchangeBot communications. The
 // http.Request must be created individually for each exchange.
@@ -748,6 +761,20 @@ func (xc *CommonExchange) wsDepths() *DepthData {
 	return xc.wsDepthSnapshot()
 }
 
+// liveDepth is the depth data of the websocket-synced orderbook, if it has
+// been updated since the given time. The time of the latest update is returned
+// for the next call, or since if there is no new depth data.
+func (xc *CommonExchange) liveDepth(since time.Time) (*DepthData, time.Time) {
+	if !xc.wsListening() {
+		return nil, since
+	}
+	updated := xc.wsLastUpdate()
+	if !updated.After(since) {
+		return nil, since
+	}
+	return xc.wsDepths(), updated
+}
+
 // For exchanges that have a websocket-synced orderbook, wsDepthStatus will
 // return the DepthData. tryHttp will be true if the websocket is in a
 // questionable state. The value of initializing will be true if this is the
@@ -860,9 +887,10 @@ func (coinbase *CoinbaseExchange) Refresh() {
 }
 
 // CoindeskExchange provides Bitcoin indices for USD, GBP, and EUR by default.
-// Others are available, but custom requests would need to be implemented.
+// Others are requested individually, see RequestCurrencies.
 type CoindeskExchange struct {
 	*CommonExchange
+	currencyReqs map[string]*http.Request
 }
 
 // NewCoindesk constructs a CoindeskExchange.
@@ -874,10 +902,25 @@ func NewCoindesk(client *http.Client, channels *BotChannels) (coindesk Exchange,
 	}
 	coindesk = &CoindeskExchange{
 		CommonExchange: newCommonExchange(Coindesk, client, reqs, channels),
+		currencyReqs:   make(map[string]*http.Request),
 	}
 	return
 }
 
+// RequestCurrencies prepares the requests for the currency codes, which are
+// only made for the codes not provided by default.
+func (coindesk *CoindeskExchange) RequestCurrencies(codes []string) error {
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(CoindeskCurrencyURL, code), nil)
+		if err != nil {
+			return err
+		}
+		coindesk.currencyReqs[code] = req
+	}
+	return nil
+}
+
 // CoindeskResponse models the JSON data returned from the Coindesk API.
 type CoindeskResponse struct {
 	Time       CoindeskResponseTime           `json:"time"`
@@ -916,12 +959,35 @@ func (coindesk *CoindeskExchange) Refresh() {
 	for code, bpi := range response.Bpi {
 		indices[code] = bpi.RateFloat
 	}
+
+	for code, req := range coindesk.currencyReqs {
+		if _, found := indices[code]; found {
+			continue
+		}
+		response := new(CoindeskResponse)
+		err := coindesk.fetch(req, response)
+		if err != nil {
+			// The indices of the other currencies are still good.
+			log.Warnf("%s: Failed to fetch index %s: %v", coindesk.token, code, err)
+			continue
+		}
+		if bpi, found := response.Bpi[code]; found {
+			indices[code] = bpi.RateFloat
+		}
+	}
 	coindesk.UpdateIndices(indices)
 }
 
 // BinanceExchange is a high-volume and well-respected crypto exchange.
 type BinanceExchange struct {
 	*CommonExchange
+	// The websocket orderbook is synced by merging the depth updates into a
+	// snapshot. updateID is the ID of the last update merged, and the updates
+	// received before the snapshot are queued. These fields are protected by
+	// orderMtx.
+	updateID int64
+	synced   bool
+	queue    []*BinanceDepthUpdate
 }
 
 // NewBinance constructs a BinanceExchange.
@@ -1067,7 +1133,7 @@ func (r BinanceCandlestickResponse) translate() Candlesticks {
 
 // BinanceDepthResponse models the response for Binance depth chart data.
 type BinanceDepthResponse struct {
-	UpdateID int64
+	UpdateID int64 `json:"lastUpdateId"`
 	Bids     [][2]string
 	Asks     [][2]string
 }
@@ -1113,6 +1179,172 @@ func (r *BinanceDepthResponse) translate() *DepthData {
 	return depth
 }
 
+// BinanceDepthUpdate models a message of the Binance diff. depth stream. The
+// quantities are the new totals at each price, and a zero quantity removes the
+// price from the orderbook.
+type BinanceDepthUpdate struct {
+	Event         string      `json:"e"`
+	EventTime     int64       `json:"E"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID int64       `json:"U"`
+	FinalUpdateID int64       `json:"u"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+const binanceDepthUpdateEvent = "depthUpdate"
+
+// Set the quantities of the Binance depth points in the orderbook, deleting
+// the prices with a zero quantity.
+func mergeBinanceDepthPoints(pts [][2]string, book wsOrders) error {
+	orders, err := parseBinanceDepthPoints(pts)
+	if err != nil {
+		return err
+	}
+	for _, pt := range orders {
+		bin := eightPtKey(pt.Price)
+		if pt.Quantity == 0 {
+			delete(book, bin)
+			continue
+		}
+		book[bin] = &wsOrder{
+			price:  pt.Price,
+			volume: pt.Quantity,
+		}
+	}
+	return nil
+}
+
+// Merge a depth update into the synced orderbook. The updates that are already
+// reflected in the orderbook are skipped, and an error is returned if updates
+// were missed. This method should be called under orderMtx lock.
+func (binance *BinanceExchange) mergeDepthUpdate(update *BinanceDepthUpdate) error {
+	if update.FinalUpdateID <= binance.updateID {
+		return nil
+	}
+	if update.FirstUpdateID > binance.updateID+1 {
+		return fmt.Errorf("missed binance depth updates %d to %d",
+			binance.updateID+1, update.FirstUpdateID-1)
+	}
+	err := mergeBinanceDepthPoints(update.Bids, binance.buys)
+	if err != nil {
+		return err
+	}
+	err = mergeBinanceDepthPoints(update.Asks, binance.asks)
+	if err != nil {
+		return err
+	}
+	binance.updateID = update.FinalUpdateID
+	return nil
+}
+
+// processWsMessage merges a depth update into the orderbook, or queues it if
+// the orderbook snapshot has not been received yet.
+func (binance *BinanceExchange) processWsMessage(raw []byte) {
+	update := new(BinanceDepthUpdate)
+	err := json.Unmarshal(raw, update)
+	if err != nil {
+		binance.setWsFail(err)
+		return
+	}
+	if update.Event != binanceDepthUpdateEvent {
+		log.Debugf("Ignoring binance websocket event %q", update.Event)
+		return
+	}
+
+	binance.orderMtx.Lock()
+	defer binance.orderMtx.Unlock()
+	if !binance.synced {
+		binance.queue = append(binance.queue, update)
+		return
+	}
+	err = binance.mergeDepthUpdate(update)
+	if err != nil {
+		binance.setWsFail(err)
+		return
+	}
+	binance.wsUpdated()
+}
+
+// processSnapshot replaces the orderbook with the snapshot, and merges the
+// queued depth updates.
+func (binance *BinanceExchange) processSnapshot(snapshot *BinanceDepthResponse) {
+	binance.orderMtx.Lock()
+	defer binance.orderMtx.Unlock()
+	binance.buys = make(wsOrders)
+	binance.asks = make(wsOrders)
+	err := mergeBinanceDepthPoints(snapshot.Bids, binance.buys)
+	if err != nil {
+		binance.setWsFail(err)
+		return
+	}
+	err = mergeBinanceDepthPoints(snapshot.Asks, binance.asks)
+	if err != nil {
+		binance.setWsFail(err)
+		return
+	}
+	binance.updateID = snapshot.UpdateID
+
+	queue := binance.queue
+	binance.queue = nil
+	for _, update := range queue {
+		err = binance.mergeDepthUpdate(update)
+		if err != nil {
+			binance.setWsFail(err)
+			return
+		}
+	}
+	binance.synced = true
+	binance.wsInitialized()
+}
+
+// syncOrderbook retrieves the orderbook snapshot that the websocket depth
+// updates are merged into, and sends the initial depth data.
+func (binance *BinanceExchange) syncOrderbook() {
+	snapshot := new(BinanceDepthResponse)
+	err := binance.fetch(binance.requests.depth, snapshot)
+	if err != nil {
+		binance.setWsFail(fmt.Errorf("binance orderbook snapshot error: %v", err))
+		return
+	}
+	binance.processSnapshot(snapshot)
+	if !binance.wsListening() {
+		return
+	}
+	state := binance.state()
+	if state != nil && state.Price > 0 { // Only send update if price has been fetched
+		binance.Update(&ExchangeState{
+			Price:        state.Price,
+			BaseVolume:   state.BaseVolume,
+			Volume:       state.Volume,
+			Change:       state.Change,
+			Stamp:        state.Stamp,
+			Depth:        binance.wsDepths(),
+			Candlesticks: state.Candlesticks,
+		})
+	}
+}
+
+// Create a websocket connection to the diff. depth stream, and sync the
+// orderbook once the updates are being received.
+func (binance *BinanceExchange) connectWs() {
+	binance.orderMtx.Lock()
+	binance.synced = false
+	binance.queue = nil
+	binance.orderMtx.Unlock()
+
+	err := binance.connectWebsocket(binance.processWsMessage, &socketConfig{
+		address: BinanceURLs.Websocket,
+	})
+	if err != nil {
+		// Fail the websocket so that the orderbook is retrieved over HTTP
+		// until the next connection attempt.
+		binance.setWsFail(fmt.Errorf("connectWs: %v", err))
+		return
+	}
+	go binance.syncOrderbook()
+}
+
 // Refresh retrieves and parses API data from Binance.
 func (binance *BinanceExchange) Refresh() {
 	binance.LogRequest()
@@ -1144,13 +1376,26 @@ func (binance *BinanceExchange) Refresh() {
 		return
 	}
 
-	// Get the depth chart
-	depthResponse := new(BinanceDepthResponse)
-	err = binance.fetch(binance.requests.depth, depthResponse)
-	if err != nil {
-		log.Errorf("Error retrieving depth chart data from Binance: %v", err)
+	// Check for a depth chart from the websocket orderbook.
+	tryHttp, wsStarting, depth := binance.wsDepthStatus(binance.connectWs)
+
+	// If not expecting depth data from the websocket, grab it from HTTP
+	if tryHttp {
+		depthResponse := new(BinanceDepthResponse)
+		err = binance.fetch(binance.requests.depth, depthResponse)
+		if err != nil {
+			log.Errorf("Error retrieving depth chart data from Binance: %v", err)
+		}
+		depth = depthResponse.translate()
+	}
+
+	if !wsStarting {
+		sinceLast := time.Since(binance.wsLastUpdate())
+		log.Tracef("last binance websocket update %.3f seconds ago", sinceLast.Seconds())
+		if sinceLast > depthDataExpiration && !binance.wsFailed() {
+			binance.setWsFail(fmt.Errorf("lost connection detected. binance websocket will reconnect during next refresh"))
+		}
 	}
-	depth := depthResponse.translate()
 
 	// Grab the current state to check if candlesticks need updating
 	state := binance.state()
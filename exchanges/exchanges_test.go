@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -413,3 +414,151 @@ func TestBittrexWebsocket(t *testing.T) {
 		t.Fatalf("bittrex not in failed state as expected")
 	}
 }
+
+func newTestBinanceExchange() *BinanceExchange {
+	return &BinanceExchange{
+		CommonExchange: &CommonExchange{
+			token: Binance,
+			currentState: &ExchangeState{
+				Price: 1,
+			},
+			channels: &BotChannels{
+				exchange: make(chan *ExchangeUpdate, 2),
+			},
+			asks: make(wsOrders),
+			buys: make(wsOrders),
+		},
+	}
+}
+
+func TestBinanceWebsocket(t *testing.T) {
+	enableTestLog()
+
+	binance := newTestBinanceExchange()
+	depthUpdate := func(first, final int64, bids, asks string) []byte {
+		return []byte(fmt.Sprintf(`{"e": "depthUpdate", "E": 1586000000000, "s": "DCRBTC",
+			"U": %d, "u": %d, "b": %s, "a": %s}`, first, final, bids, asks))
+	}
+	checkLengths := func(askLen, buyLen int) {
+		depth := binance.wsDepths()
+		if len(depth.Asks) != askLen || len(depth.Bids) != buyLen {
+			t.Errorf("unexpected depth data lengths (%d, %d). expected (%d, %d)",
+				len(depth.Asks), len(depth.Bids), askLen, buyLen)
+		}
+	}
+
+	// The updates are queued until the snapshot is received. The first is
+	// already reflected in the snapshot.
+	binance.processWsMessage(depthUpdate(99, 100, `[["0.00300000", "5.0"]]`, `[]`))
+	binance.processWsMessage(depthUpdate(101, 102, `[["0.00290000", "0.0"]]`, `[["0.00320000", "7.0"]]`))
+	if len(binance.queue) != 2 || binance.wsListening() {
+		t.Fatalf("expected 2 queued updates before the snapshot, got %d", len(binance.queue))
+	}
+
+	snapshot := new(BinanceDepthResponse)
+	err := json.Unmarshal([]byte(`{"lastUpdateId": 100,
+		"bids": [["0.00300000", "5.0"], ["0.00290000", "3.0"]],
+		"asks": [["0.00310000", "4.0"]]}`), snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binance.processSnapshot(snapshot)
+	if !binance.wsListening() {
+		t.Fatalf("binance websocket not listening after the snapshot")
+	}
+	checkLengths(2, 1)
+
+	// A zero quantity removes the price.
+	binance.processWsMessage(depthUpdate(103, 103, `[["0.00300000", "6.0"]]`, `[["0.00310000", "0.0"]]`))
+	checkLengths(1, 1)
+	depth := binance.wsDepths()
+	if depth.Bids[0].Quantity != 6 || depth.Asks[0].Quantity != 7 {
+		t.Errorf("unexpected depth data %+v", depth)
+	}
+
+	// Missed updates trigger a failed state.
+	binance.processWsMessage(depthUpdate(105, 106, `[]`, `[]`))
+	if !binance.wsFailed() {
+		t.Fatalf("binance not in failed state as expected")
+	}
+}
+
+func TestConsolidateDepth(t *testing.T) {
+	agDepth := &aggregateOrderbook{
+		BtcIndex: "USD",
+		Price:    20,
+		Tokens:   []string{Binance, Bittrex},
+		Data: aggregateData{
+			Time: 1586000000,
+			Bids: []agBookPt{
+				{Price: 0.003, Volumes: []float64{1, 2}},
+				{Price: 0.0029, Volumes: []float64{0, 4}},
+			},
+			Asks: []agBookPt{
+				{Price: 0.0031, Volumes: []float64{3, 0}},
+			},
+		},
+	}
+	depth := consolidateDepth(agDepth)
+	expectedBids := []DepthPoint{{Quantity: 3, Price: 0.003}, {Quantity: 4, Price: 0.0029}}
+	expectedAsks := []DepthPoint{{Quantity: 3, Price: 0.0031}}
+	if !reflect.DeepEqual(depth.Bids, expectedBids) || !reflect.DeepEqual(depth.Asks, expectedAsks) {
+		t.Errorf("unexpected consolidated orders, bids %v, asks %v", depth.Bids, depth.Asks)
+	}
+	if depth.Time != agDepth.Data.Time || !reflect.DeepEqual(depth.Tokens, agDepth.Tokens) {
+		t.Errorf("unexpected consolidated depth %+v", depth)
+	}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	rates := func(pts ...float64) []*SourceRate {
+		rates := make([]*SourceRate, 0, len(pts)/2)
+		for i := 0; i < len(pts); i += 2 {
+			rates = append(rates, &SourceRate{Price: pts[i], Weight: pts[i+1]})
+		}
+		return rates
+	}
+	tests := []struct {
+		name   string
+		rates  []*SourceRate
+		median float64
+	}{
+		{"none", rates(), 0},
+		{"single", rates(5, 1), 5},
+		{"equal weights, odd", rates(3, 1, 1, 1, 2, 1), 2},
+		{"equal weights, even", rates(4, 1, 1, 1, 3, 1, 2, 1), 2.5},
+		{"heavy outlier", rates(1, 1, 2, 1, 100, 5), 100},
+		{"zero weight", rates(1, 1, 2, 0, 3, 1), 2},
+		{"no weight", rates(1, 0, 2, 0), 0},
+	}
+	for _, test := range tests {
+		if median := weightedMedian(test.rates); median != test.median {
+			t.Errorf("%s: expected median %f, got %f", test.name, test.median, median)
+		}
+	}
+}
+
+func TestParseSourceConfig(t *testing.T) {
+	token, cfg, err := ParseSourceConfig("Coinbase:weight=2.5,interval=10m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != Coinbase || cfg.Disabled || cfg.Weight != 2.5 || cfg.Interval != 10*time.Minute {
+		t.Errorf("unexpected config for %s: %+v", token, cfg)
+	}
+
+	token, cfg, err = ParseSourceConfig("huobi:enabled=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != Huobi || !cfg.Disabled || cfg.Weight != 1 || cfg.Interval != 0 {
+		t.Errorf("unexpected config for %s: %+v", token, cfg)
+	}
+
+	for _, s := range []string{"nonexchange", "binance:weight", "binance:weight=-1",
+		"binance:interval=soon", "binance:speed=fast"} {
+		if _, _, err = ParseSourceConfig(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
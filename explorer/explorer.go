@@ -36,7 +36,6 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
-	"github.com/rs/cors"
 )
 
 const (
@@ -77,7 +76,9 @@ type explorerDataSource interface {
 	BlockMissedVotes(blockHash string) ([]string, error)
 	TicketMiss(ticketHash string) (string, int64, error)
 	SideChainBlocks() ([]*dbtypes.BlockStatus, error)
+	SideChainBlock(hash string) (*apitypes.SideChainBlock, error)
 	DisapprovedBlocks() ([]*dbtypes.BlockStatus, error)
+	NonstandardOutputs(N, offset int64) (*apitypes.NonstandardOutputs, error)
 	BlockStatus(hash string) (dbtypes.BlockStatus, error)
 	BlockFlags(hash string) (bool, bool, error)
 	TicketPoolVisualization(interval dbtypes.TimeBasedGrouping) (*dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, int64, error)
@@ -89,7 +90,8 @@ type explorerDataSource interface {
 	TimeBasedIntervals(timeGrouping dbtypes.TimeBasedGrouping, limit, offset uint64) ([]*dbtypes.BlocksGroupedInfo, error)
 	AgendasVotesSummary(agendaID string) (summary *dbtypes.AgendaSummary, err error)
 	BlockTimeByHeight(height int64) (int64, error)
-	LastPiParserSync() time.Time
+	LastProposalVotesSync() time.Time
+	StoreProposalVoteSnapshot(snap *dbtypes.ProposalVoteSnapshot) error
 	GetChainParams() *chaincfg.Params
 	GetExplorerBlock(hash string) *types.BlockInfo
 	GetExplorerBlocks(start int, end int) []*types.BlockBasic
@@ -106,6 +108,7 @@ type explorerDataSource interface {
 	GetExplorerFullBlocks(start int, end int) []*types.BlockInfo
 	CurrentDifficulty() (float64, error)
 	Difficulty(timestamp int64) float64
+	AddressTag(address string) *dbtypes.AddressTag
 }
 
 // PoliteiaBackend implements methods that manage proposals db data.
@@ -117,6 +120,12 @@ type PoliteiaBackend interface {
 	ProposalByRefID(RefID string) (*pitypes.ProposalInfo, error)
 }
 
+// Searcher finds the blocks, transactions, addresses and proposals matching a
+// partial query.
+type Searcher interface {
+	Search(query string, limit int) (*apitypes.SearchResults, error)
+}
+
 // agendaBackend implements methods that manage agendas db data.
 type agendaBackend interface {
 	AgendaInfo(agendaID string) (*agendas.AgendaTagged, error)
@@ -197,6 +206,7 @@ type explorerUI struct {
 	agendasSource    agendaBackend
 	voteTracker      *agendas.VoteTracker
 	proposalsSource  PoliteiaBackend
+	searcher         Searcher
 	dbsSyncing       atomic.Value
 	devPrefetch      bool
 	templates        templates
@@ -275,6 +285,7 @@ type ExplorerConfig struct {
 	AgendasSource   agendaBackend
 	Tracker         *agendas.VoteTracker
 	ProposalsSource PoliteiaBackend
+	Searcher        Searcher
 	PoliteiaURL     string
 	MainnetLink     string
 	TestnetLink     string
@@ -296,6 +307,7 @@ func New(cfg *ExplorerConfig) *explorerUI {
 	exp.agendasSource = cfg.AgendasSource
 	exp.voteTracker = cfg.Tracker
 	exp.proposalsSource = cfg.ProposalsSource
+	exp.searcher = cfg.Searcher
 	exp.politeiaAPIURL = cfg.PoliteiaURL
 	explorerLinks.Mainnet = cfg.MainnetLink
 	explorerLinks.Testnet = cfg.TestnetLink
@@ -352,9 +364,9 @@ func New(cfg *ExplorerConfig) *explorerUI {
 
 	tmpls := []string{"home", "explorer", "mempool", "block", "tx", "address",
 		"rawtx", "status", "parameters", "agenda", "agendas", "charts",
-		"sidechains", "disapproved", "ticketpool", "visualblocks", "statistics",
+		"sidechains", "sidechainblock", "disapproved", "ticketpool", "visualblocks", "statistics",
 		"windows", "timelisting", "addresstable", "proposals", "proposal",
-		"market", "insight_root", "attackcost"}
+		"market", "insight_root", "attackcost", "nonstandard"}
 
 	for _, name := range tmpls {
 		if err := exp.templates.addTemplate(name); err != nil {
@@ -404,6 +416,11 @@ func (exp *explorerUI) LastBlock() (lastBlockHash string, lastBlock int64, lastB
 	return
 }
 
+// NumClients returns the number of clients connected to the websocket hub.
+func (exp *explorerUI) NumClients() int {
+	return exp.wsHub.NumClients()
+}
+
 // MempoolInventory safely retrieves the current mempool inventory.
 func (exp *explorerUI) MempoolInventory() *types.MempoolInfo {
 	exp.invsMtx.RLock()
@@ -570,11 +587,14 @@ func (exp *explorerUI) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgB
 		// Update the proposal DB. This is run asynchronously since it involves
 		// a query to Politeia (a remote system) and we do not want to block
 		// execution.
+		height, blockTime := newBlockData.Height, newBlockData.BlockTime.T
 		go func() {
 			err := exp.proposalsSource.CheckProposalsUpdates()
 			if err != nil {
 				log.Errorf("(PoliteiaBackend).CheckProposalsUpdates: %v", err)
+				return
 			}
+			exp.snapshotProposalVotes(height, blockTime)
 		}()
 	}
 
@@ -591,6 +611,37 @@ func (exp *explorerUI) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgB
 	return nil
 }
 
+// snapshotProposalVotes stores the vote tallies of the proposals whose votes
+// are live, as of the block, so their progress through the vote may be charted.
+func (exp *explorerUI) snapshotProposalVotes(height int64, blockTime time.Time) {
+	proposals, _, err := exp.proposalsSource.AllProposals(0, math.MaxInt32)
+	if err != nil {
+		log.Errorf("(PoliteiaBackend).AllProposals: %v", err)
+		return
+	}
+
+	for _, p := range proposals {
+		if p.VoteStatus.ShortDesc() != "Started" {
+			continue
+		}
+		meta := p.Metadata(height, int64(exp.ChainParams.TargetTimePerBlock/time.Second))
+		err = exp.dataSource.StoreProposalVoteSnapshot(&dbtypes.ProposalVoteSnapshot{
+			Token:            p.TokenVal,
+			Height:           height,
+			Time:             dbtypes.NewTimeDef(blockTime),
+			Yes:              meta.Yes,
+			No:               meta.No,
+			EligibleTickets:  p.NumOfEligibleVotes,
+			QuorumPercentage: p.QuorumPercentage,
+			PassPercentage:   p.PassPercentage,
+		})
+		if err != nil {
+			log.Errorf("Failed to store the vote snapshot of proposal %s: %v",
+				p.TokenVal, err)
+		}
+	}
+}
+
 func (exp *explorerUI) updateDevFundBalance() {
 	// yield processor to other goroutines
 	runtime.Gosched()
@@ -605,18 +656,9 @@ func (exp *explorerUI) updateDevFundBalance() {
 	}
 }
 
-type loggerFunc func(string, ...interface{})
-
-func (lw loggerFunc) Printf(str string, args ...interface{}) {
-	lw(str, args...)
-}
-
 func (exp *explorerUI) addRoutes() {
 	exp.Mux.Use(middleware.Logger)
 	exp.Mux.Use(middleware.Recoverer)
-	corsMW := cors.Default()
-	corsMW.Log = loggerFunc(log.Tracef)
-	exp.Mux.Use(corsMW.Handler)
 
 	redirect := func(url string) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -789,6 +831,18 @@ func (exp *explorerUI) watchExchanges() {
 				continue
 			}
 			sendXcUpdate(true, update.Token, indexState)
+		case update := <-xcChans.Depth:
+			// The market page only needs to know which depth charts to
+			// refresh, so the orders are not sent.
+			depthChart := &pstypes.DepthChart{
+				Time:   update.Time,
+				Tokens: update.Tokens,
+			}
+			select {
+			case exp.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigDepthChart, Msg: depthChart}:
+			case <-exp.xcDone:
+				return
+			}
 		case <-xcChans.Quit:
 			log.Warnf("ExchangeBot has quit.")
 			return
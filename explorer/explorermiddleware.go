@@ -27,6 +27,7 @@ const (
 	ctxAddress
 	ctxAgendaId
 	ctxProposalRefID
+	ctxHeadless
 )
 
 const (
@@ -81,3 +81,34 @@ func TestMenuFormParser(t *testing.T) {
 		t.Errorf("Location should not have a host, but it was %s", loc.Host)
 	}
 }
+
+func TestHeadlessPage(t *testing.T) {
+	exp := new(explorerUI)
+	// Dummy page handler that renders its data like the explorer pages do.
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		str, err := exp.execTemplate(r, "blah", struct {
+			Height int64 `json:"height"`
+		}{12})
+		if err != nil {
+			t.Fatalf("execTemplate failed: %v", err)
+		}
+		if p := pagePath(r, "/block/12"); p != "/api/page/block/12" {
+			t.Errorf(`pagePath should have been "/api/page/block/12", got "%s".`, p)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, str)
+	})
+
+	r := httptest.NewRequest("GET", "/api/page/blah", nil)
+	w := httptest.NewRecorder()
+	HeadlessPage(page).ServeHTTP(w, r)
+
+	if w.Body.String() != `{"height":12}` {
+		t.Errorf("HeadlessPage failed to respond with the page data. Got: %v.",
+			w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("HeadlessPage failed to set the JSON content type. Got: %v.", ct)
+	}
+}
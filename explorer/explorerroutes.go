@@ -23,6 +23,7 @@ import (
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
 	"github.com/decred/dcrd/txscript/v2"
 
+	apitypes "github.com/decred/dcrdata/api/types/v5"
 	"github.com/decred/dcrdata/db/dbtypes/v2"
 	"github.com/decred/dcrdata/exchanges/v2"
 	"github.com/decred/dcrdata/explorer/types/v2"
@@ -183,7 +184,7 @@ func (exp *explorerUI) Home(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	str, err := exp.templates.exec("home", struct {
+	str, err := exp.execTemplate(r, "home", struct {
 		*CommonPageData
 		Info          *types.HomeInfo
 		Mempool       *types.MempoolInfo
@@ -231,7 +232,7 @@ func (exp *explorerUI) SideChains(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str, err := exp.templates.exec("sidechains", struct {
+	str, err := exp.execTemplate(r, "sidechains", struct {
 		*CommonPageData
 		Data []*dbtypes.BlockStatus
 	}{
@@ -251,7 +252,7 @@ func (exp *explorerUI) SideChains(w http.ResponseWriter, r *http.Request) {
 
 // InsightRootPage is the page for the "/insight" path.
 func (exp *explorerUI) InsightRootPage(w http.ResponseWriter, r *http.Request) {
-	str, err := exp.templates.exec("insight_root", struct {
+	str, err := exp.execTemplate(r, "insight_root", struct {
 		*CommonPageData
 	}{
 		CommonPageData: exp.commonData(r),
@@ -267,6 +268,45 @@ func (exp *explorerUI) InsightRootPage(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// SideChainBlock is the page handler for the "/side/{blockhash}" path, listing
+// the transactions of a stored side chain block.
+func (exp *explorerUI) SideChainBlock(w http.ResponseWriter, r *http.Request) {
+	hash := getBlockHashCtx(r)
+	block, err := exp.dataSource.SideChainBlock(hash)
+	if exp.timeoutErrorPage(w, err, "SideChainBlock") {
+		return
+	}
+	if err == sql.ErrNoRows {
+		exp.StatusPage(w, defaultErrorCode,
+			"the transactions of that side chain block are not stored", hash,
+			ExpStatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Errorf("Unable to get side chain block %s: %v", hash, err)
+		exp.StatusPage(w, defaultErrorCode,
+			"failed to retrieve side chain block", "", ExpStatusError)
+		return
+	}
+
+	str, err := exp.execTemplate(r, "sidechainblock", struct {
+		*CommonPageData
+		Data *apitypes.SideChainBlock
+	}{
+		CommonPageData: exp.commonData(r),
+		Data:           block,
+	})
+
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
 // DisapprovedBlocks is the page handler for the "/disapproved" path.
 func (exp *explorerUI) DisapprovedBlocks(w http.ResponseWriter, r *http.Request) {
 	disapprovedBlocks, err := exp.dataSource.DisapprovedBlocks()
@@ -280,7 +320,7 @@ func (exp *explorerUI) DisapprovedBlocks(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	str, err := exp.templates.exec("disapproved", struct {
+	str, err := exp.execTemplate(r, "disapproved", struct {
 		*CommonPageData
 		Data []*dbtypes.BlockStatus
 	}{
@@ -298,6 +338,71 @@ func (exp *explorerUI) DisapprovedBlocks(w http.ResponseWriter, r *http.Request)
 	io.WriteString(w, str)
 }
 
+// NonstandardOutputs is the page handler for the "/nonstandard" path, which
+// lists the outputs with nonstandard scripts, most recent first.
+func (exp *explorerUI) NonstandardOutputs(w http.ResponseWriter, r *http.Request) {
+	var offset int64
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		o, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || o < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		offset = o
+	}
+
+	var rows int64
+	if rowsStr := r.URL.Query().Get("rows"); rowsStr != "" {
+		o, err := strconv.ParseInt(rowsStr, 10, 64)
+		if err != nil || o < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		rows = o
+	}
+	if rows == 0 {
+		rows = minExplorerRows
+	} else if rows > maxExplorerRows {
+		rows = maxExplorerRows
+	}
+
+	outputs, err := exp.dataSource.NonstandardOutputs(rows, offset)
+	if exp.timeoutErrorPage(w, err, "NonstandardOutputs") {
+		return
+	}
+	if err != nil {
+		log.Errorf("Unable to get nonstandard outputs: %v", err)
+		exp.StatusPage(w, defaultErrorCode,
+			"failed to retrieve nonstandard outputs", "", ExpStatusError)
+		return
+	}
+
+	linkTemplate := "/nonstandard?offset=%d&rows=" + strconv.FormatInt(rows, 10)
+
+	str, err := exp.execTemplate(r, "nonstandard", struct {
+		*CommonPageData
+		Data   *apitypes.NonstandardOutputs
+		Offset int64
+		Limit  int64
+		Pages  pageNumbers
+	}{
+		CommonPageData: exp.commonData(r),
+		Data:           outputs,
+		Offset:         offset,
+		Limit:          rows,
+		Pages:          calcPages(int(outputs.Total), int(rows), int(offset), linkTemplate),
+	})
+
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
 // VisualBlocks is the page handler for the "/visualblocks" path.
 func (exp *explorerUI) VisualBlocks(w http.ResponseWriter, r *http.Request) {
 	// Get top N blocks and trim each block to have just the fields required for
@@ -337,7 +442,7 @@ func (exp *explorerUI) VisualBlocks(w http.ResponseWriter, r *http.Request) {
 	exp.pageData.RLock()
 	mempoolInfo.Subsidy = exp.pageData.HomeInfo.NBlockSubsidy
 
-	str, err := exp.templates.exec("visualblocks", struct {
+	str, err := exp.execTemplate(r, "visualblocks", struct {
 		*CommonPageData
 		Info    *types.HomeInfo
 		Mempool *types.TrimmedMempoolInfo
@@ -407,7 +512,7 @@ func (exp *explorerUI) StakeDiffWindows(w http.ResponseWriter, r *http.Request)
 
 	linkTemplate := "/ticketpricewindows?offset=%d&rows=" + strconv.FormatUint(rows, 10)
 
-	str, err := exp.templates.exec("windows", struct {
+	str, err := exp.execTemplate(r, "windows", struct {
 		*CommonPageData
 		Data         []*dbtypes.BlocksGroupedInfo
 		WindowSize   int64
@@ -538,7 +643,7 @@ func (exp *explorerUI) timeBasedBlocksListing(val string, w http.ResponseWriter,
 
 	linkTemplate := "/" + strings.ToLower(val) + "?offset=%d&rows=" + strconv.FormatUint(rows, 10)
 
-	str, err := exp.templates.exec("timelisting", struct {
+	str, err := exp.execTemplate(r, "timelisting", struct {
 		*CommonPageData
 		Data         []*dbtypes.BlocksGroupedInfo
 		TimeGrouping string
@@ -640,7 +745,7 @@ func (exp *explorerUI) Blocks(w http.ResponseWriter, r *http.Request) {
 
 	oldestHeight := bestBlockHeight % rows
 
-	str, err := exp.templates.exec("explorer", struct {
+	str, err := exp.execTemplate(r, "explorer", struct {
 		*CommonPageData
 		Data         []*types.BlockBasic
 		BestBlock    int64
@@ -730,7 +835,7 @@ func (exp *explorerUI) Block(w http.ResponseWriter, r *http.Request) {
 		pageData.FiatConversion = exp.xcBot.Conversion(data.TotalSent)
 	}
 
-	str, err := exp.templates.exec("block", pageData)
+	str, err := exp.execTemplate(r, "block", pageData)
 	if err != nil {
 		log.Errorf("Template execute failure: %v", err)
 		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
@@ -750,7 +855,7 @@ func (exp *explorerUI) Mempool(w http.ResponseWriter, r *http.Request) {
 	// Prevent modifications to the shared inventory struct (e.g. in the
 	// MempoolMonitor) while marshaling the inventory.
 	inv.RLock()
-	str, err := exp.templates.exec("mempool", struct {
+	str, err := exp.execTemplate(r, "mempool", struct {
 		*CommonPageData
 		Mempool *types.MempoolInfo
 	}{
@@ -771,7 +876,7 @@ func (exp *explorerUI) Mempool(w http.ResponseWriter, r *http.Request) {
 
 // Ticketpool is the page handler for the "/ticketpool" path.
 func (exp *explorerUI) Ticketpool(w http.ResponseWriter, r *http.Request) {
-	str, err := exp.templates.exec("ticketpool", exp.commonData(r))
+	str, err := exp.execTemplate(r, "ticketpool", exp.commonData(r))
 
 	if err != nil {
 		log.Errorf("Template execute failure: %v", err)
@@ -861,6 +966,11 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		if exp.timeoutErrorPage(w, err, "VoutsForTx") {
 			return
 		}
+		if err == dbtypes.ErrPruned {
+			exp.StatusPage(w, defaultErrorCode, "the inputs and outputs of that "+
+				"transaction have been pruned", "", ExpStatusNotFound)
+			return
+		}
 		if err != nil {
 			log.Errorf("Failed to retrieve all vout details for transaction %s: %v",
 				dbTx0.TxID, err)
@@ -881,7 +991,7 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 			if exp.timeoutErrorPage(w, err, "SpendingTransaction") {
 				return
 			}
-			if err != nil && err != sql.ErrNoRows {
+			if err != nil && err != sql.ErrNoRows && err != dbtypes.ErrPruned {
 				log.Warnf("SpendingTransaction failed for outpoint %s:%d: %v",
 					hash, vouts[iv].TxIndex, err)
 			}
@@ -1085,6 +1195,12 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 	if exp.timeoutErrorPage(w, err, "SpendingTransactions") {
 		return
 	}
+	// The spending transactions of the outputs of a transaction in a pruned
+	// block are not shown.
+	if err == dbtypes.ErrPruned {
+		log.Debugf("The spending transactions of %s have been pruned.", hash)
+		err = nil
+	}
 	if err != nil {
 		log.Errorf("Unable to retrieve spending transactions for %s: %v", hash, err)
 		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, hash, ExpStatusError)
@@ -1249,7 +1365,7 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		pageData.Conversions.Fees = exp.xcBot.Conversion(tx.Fee.ToCoin())
 	}
 
-	str, err := exp.templates.exec("tx", pageData)
+	str, err := exp.execTemplate(r, "tx", pageData)
 	if err != nil {
 		log.Errorf("Template execute failure: %v", err)
 		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
@@ -1268,6 +1384,7 @@ func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 	type AddressPageData struct {
 		*CommonPageData
 		Data         *dbtypes.AddressInfo
+		Tag          *dbtypes.AddressTag
 		CRLFDownload bool
 		FiatBalance  *exchanges.Conversion
 		Pages        []pageNumber
@@ -1362,11 +1479,12 @@ func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 	pageData := AddressPageData{
 		CommonPageData: exp.commonData(r),
 		Data:           addrData,
+		Tag:            exp.dataSource.AddressTag(addrData.Address),
 		CRLFDownload:   UseCRLF,
 		FiatBalance:    conversion,
 		Pages:          calcPages(int(addrData.TxnCount), int(limitN), int(offsetAddrOuts), linkTemplate),
 	}
-	str, err := exp.templates.exec("address", pageData)
+	str, err := exp.execTemplate(r, "address", pageData)
 	if err != nil {
 		log.Errorf("Template execute failure: %v", err)
 		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
@@ -1513,7 +1631,7 @@ func (exp *explorerUI) AddressListData(address string, txnType dbtypes.AddrTxnVi
 // DecodeTxPage handles the "decode/broadcast transaction" page. The actual
 // decoding or broadcasting is handled by the websocket hub.
 func (exp *explorerUI) DecodeTxPage(w http.ResponseWriter, r *http.Request) {
-	str, err := exp.templates.exec("rawtx", struct {
+	str, err := exp.execTemplate(r, "rawtx", struct {
 		*CommonPageData
 	}{
 		CommonPageData: exp.commonData(r),
@@ -1534,7 +1652,7 @@ func (exp *explorerUI) Charts(w http.ResponseWriter, r *http.Request) {
 	tpSize := exp.pageData.HomeInfo.PoolInfo.Target
 	exp.pageData.RUnlock()
 
-	str, err := exp.templates.exec("charts", struct {
+	str, err := exp.execTemplate(r, "charts", struct {
 		*CommonPageData
 		Premine        int64
 		TargetPoolSize uint32
@@ -1556,7 +1674,9 @@ func (exp *explorerUI) Charts(w http.ResponseWriter, r *http.Request) {
 
 // Search implements a primitive search algorithm by checking if the value in
 // question is a block index, block hash, address hash or transaction hash and
-// redirects to the appropriate page or displays an error.
+// redirects to the appropriate page. If there is no exact match, it redirects
+// to the best partial match found by the Searcher, if any, or displays an
+// error.
 func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	// The ?search= query.
 	searchStr := r.URL.Query().Get("search")
@@ -1577,12 +1697,12 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	if err == nil {
 		_, err = exp.dataSource.GetBlockHash(idx)
 		if err == nil {
-			http.Redirect(w, r, "/block/"+searchStr, http.StatusPermanentRedirect)
+			http.Redirect(w, r, pagePath(r, "/block/"+searchStr), http.StatusPermanentRedirect)
 			return
 		}
 		_, err = exp.dataSource.BlockHash(idx)
 		if err == nil {
-			http.Redirect(w, r, "/block/"+searchStr, http.StatusPermanentRedirect)
+			http.Redirect(w, r, pagePath(r, "/block/"+searchStr), http.StatusPermanentRedirect)
 			return
 		}
 		exp.StatusPage(w, "search failed", "Block "+searchStr+
@@ -1602,7 +1722,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 			proposalInfo, err = exp.proposalsSource.ProposalByRefID(searchStr)
 		}
 		if err == nil && proposalInfo.RefID != "" {
-			http.Redirect(w, r, "/proposal/"+proposalInfo.RefID, http.StatusPermanentRedirect)
+			http.Redirect(w, r, pagePath(r, "/proposal/"+proposalInfo.RefID), http.StatusPermanentRedirect)
 			return
 		}
 	}
@@ -1612,7 +1732,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	address, _, addrErr := exp.dataSource.GetExplorerAddress(searchStr, 1, 0)
 	switch addrErr {
 	case txhelpers.AddressErrorNoError, txhelpers.AddressErrorZeroAddress:
-		http.Redirect(w, r, "/address/"+searchStr, http.StatusPermanentRedirect)
+		http.Redirect(w, r, pagePath(r, "/address/"+searchStr), http.StatusPermanentRedirect)
 		return
 	case txhelpers.AddressErrorWrongNet:
 		// Status page will provide a link, but the address page can too.
@@ -1627,7 +1747,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	addrHist, _, _ := exp.dataSource.AddressHistory(searchStr,
 		1, 0, dbtypes.AddrTxnAll)
 	if len(addrHist) > 0 {
-		http.Redirect(w, r, "/address/"+searchStr, http.StatusPermanentRedirect)
+		http.Redirect(w, r, pagePath(r, "/address/"+searchStr), http.StatusPermanentRedirect)
 		return
 	}
 
@@ -1652,6 +1772,9 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 
 	// Remaining possibilities are hashes, so verify the string is a hash.
 	if _, err = chainhash.NewHashFromStr(searchStrSplit[0]); err != nil {
+		if exp.redirectToBestMatch(w, r, searchStr) {
+			return
+		}
 		exp.StatusPage(w, "search failed",
 			"Search string is not a valid hash or address: "+searchStr,
 			"", ExpStatusNotFound)
@@ -1662,7 +1785,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	// value is a block hash and then redirect to the block page if it is.
 	_, err = exp.dataSource.GetBlockHeight(searchStrSplit[0])
 	if err == nil {
-		http.Redirect(w, r, "/block/"+searchStrSplit[0], http.StatusPermanentRedirect)
+		http.Redirect(w, r, pagePath(r, "/block/"+searchStrSplit[0]), http.StatusPermanentRedirect)
 		return
 	}
 
@@ -1670,7 +1793,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	// redirect to the tx page if it is.
 	tx := exp.dataSource.GetExplorerTx(searchStrSplit[0])
 	if tx != nil {
-		http.Redirect(w, r, "/tx/"+searchStrRewritten, http.StatusPermanentRedirect)
+		http.Redirect(w, r, pagePath(r, "/tx/"+searchStrRewritten), http.StatusPermanentRedirect)
 		return
 	}
 
@@ -1680,7 +1803,11 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 		log.Errorf("Searching for transaction failed: %v", err)
 	}
 	if dbTxs != nil {
-		http.Redirect(w, r, "/tx/"+searchStrRewritten, http.StatusPermanentRedirect)
+		http.Redirect(w, r, pagePath(r, "/tx/"+searchStrRewritten), http.StatusPermanentRedirect)
+		return
+	}
+
+	if exp.redirectToBestMatch(w, r, searchStr) {
 		return
 	}
 
@@ -1688,10 +1815,46 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 	exp.StatusPage(w, "search failed", message, "", ExpStatusNotFound)
 }
 
+// redirectToBestMatch redirects to the page of the top ranked partial match of
+// the search string, such as a hash or address prefix, or a proposal title.
+// The return value indicates if a match was found.
+func (exp *explorerUI) redirectToBestMatch(w http.ResponseWriter, r *http.Request, searchStr string) bool {
+	if exp.searcher == nil {
+		return false
+	}
+	results, err := exp.searcher.Search(searchStr, 1)
+	if err != nil {
+		log.Errorf("Search for %q failed: %v", searchStr, err)
+		return false
+	}
+	if len(results.Results) == 0 {
+		return false
+	}
+	var path string
+	switch best := results.Results[0]; best.Type {
+	case apitypes.SearchResultBlock:
+		path = "/block/" + best.ID
+	case apitypes.SearchResultTx:
+		path = "/tx/" + best.ID
+	case apitypes.SearchResultAddress:
+		path = "/address/" + best.ID
+	case apitypes.SearchResultProposal:
+		path = "/proposal/" + best.ID
+	default:
+		return false
+	}
+	http.Redirect(w, r, pagePath(r, path), http.StatusFound)
+	return true
+}
+
 // StatusPage provides a page for displaying status messages and exception
 // handling without redirecting. Be sure to return after calling StatusPage if
 // this completes the processing of the calling http handler.
 func (exp *explorerUI) StatusPage(w http.ResponseWriter, code, message, additionalInfo string, sType expStatus) {
+	if _, ok := w.(*headlessWriter); ok {
+		headlessStatus(w, code, message, additionalInfo, sType)
+		return
+	}
 	commonPageData := exp.commonData(dummyRequest)
 	if commonPageData == nil {
 		// exp.blockData.GetTip likely failed due to empty DB.
@@ -1718,28 +1881,33 @@ func (exp *explorerUI) StatusPage(w http.ResponseWriter, code, message, addition
 	}
 
 	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(statusPageCode(sType))
+	io.WriteString(w, str)
+}
+
+// statusPageCode is the HTTP status code of a status page of the given type.
+func statusPageCode(sType expStatus) int {
 	switch sType {
 	case ExpStatusDBTimeout:
-		w.WriteHeader(http.StatusServiceUnavailable)
+		return http.StatusServiceUnavailable
 	case ExpStatusNotFound:
-		w.WriteHeader(http.StatusNotFound)
+		return http.StatusNotFound
 	case ExpStatusFutureBlock:
-		w.WriteHeader(http.StatusOK)
+		return http.StatusOK
 	case ExpStatusError:
-		w.WriteHeader(http.StatusInternalServerError)
+		return http.StatusInternalServerError
 	// When blockchain sync is running, status 202 is used to imply that the
 	// other requests apart from serving the status sync page have been received
 	// and accepted but cannot be processed now till the sync is complete.
 	case ExpStatusSyncing:
-		w.WriteHeader(http.StatusAccepted)
+		return http.StatusAccepted
 	case ExpStatusNotSupported:
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		return http.StatusUnprocessableEntity
 	case ExpStatusBadRequest:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusBadRequest
 	default:
-		w.WriteHeader(http.StatusServiceUnavailable)
+		return http.StatusServiceUnavailable
 	}
-	io.WriteString(w, str)
 }
 
 // NotFound wraps StatusPage to display a 404 page.
@@ -1768,7 +1936,7 @@ func (exp *explorerUI) ParametersPage(w http.ResponseWriter, r *http.Request) {
 		AddressPrefix        []types.AddrPrefix
 	}
 
-	str, err := exp.templates.exec("parameters", struct {
+	str, err := exp.execTemplate(r, "parameters", struct {
 		*CommonPageData
 		ExtendedParams
 	}{
@@ -1845,7 +2013,7 @@ func (exp *explorerUI) AgendaPage(w http.ResponseWriter, r *http.Request) {
 		blocksLeft = 0
 	}
 
-	str, err := exp.templates.exec("agenda", struct {
+	str, err := exp.execTemplate(r, "agenda", struct {
 		*CommonPageData
 		Ai            *agendas.AgendaTagged
 		QuorumVotes   uint32
@@ -1892,7 +2060,7 @@ func (exp *explorerUI) AgendasPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str, err := exp.templates.exec("agendas", struct {
+	str, err := exp.execTemplate(r, "agendas", struct {
 		*CommonPageData
 		Agendas       []*agendas.AgendaTagged
 		VotingSummary *agendas.VoteSummary
@@ -1930,7 +2098,7 @@ func (exp *explorerUI) ProposalPage(w http.ResponseWriter, r *http.Request) {
 		proposalInfo, newErr := exp.proposalsSource.ProposalByToken(param)
 		if newErr == nil && proposalInfo != nil && proposalInfo.RefID != "" {
 			// redirect to a human readable url (replace the token with the RefID)
-			http.Redirect(w, r, "/proposal/"+proposalInfo.RefID, http.StatusPermanentRedirect)
+			http.Redirect(w, r, pagePath(r, "/proposal/"+proposalInfo.RefID), http.StatusPermanentRedirect)
 			return
 		}
 
@@ -1941,7 +2109,7 @@ func (exp *explorerUI) ProposalPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	commonData := exp.commonData(r)
-	str, err := exp.templates.exec("proposal", struct {
+	str, err := exp.execTemplate(r, "proposal", struct {
 		*CommonPageData
 		Data        *pitypes.ProposalInfo
 		PoliteiaURL string
@@ -2022,7 +2190,7 @@ func (exp *explorerUI) ProposalsPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str, err := exp.templates.exec("proposals", struct {
+	str, err := exp.execTemplate(r, "proposals", struct {
 		*CommonPageData
 		Proposals     []*pitypes.ProposalInfo
 		VotesStatus   map[pitypes.VoteStatusType]string
@@ -2043,7 +2211,7 @@ func (exp *explorerUI) ProposalsPage(w http.ResponseWriter, r *http.Request) {
 		VStatusFilter:  int(filterBy),
 		TotalCount:     int64(count),
 		PoliteiaURL:    exp.politeiaAPIURL,
-		LastVotesSync:  exp.dataSource.LastPiParserSync().UTC().Unix(),
+		LastVotesSync:  exp.dataSource.LastProposalVotesSync().UTC().Unix(),
 		LastPropSync:   exp.proposalsSource.LastProposalsSync(),
 		TimePerBlock:   int64(exp.ChainParams.TargetTimePerBlock.Seconds()),
 	})
@@ -2166,7 +2334,7 @@ func (exp *explorerUI) StatsPage(w http.ResponseWriter, r *http.Request) {
 	}
 	exp.pageData.RUnlock()
 
-	str, err := exp.templates.exec("statistics", struct {
+	str, err := exp.execTemplate(r, "statistics", struct {
 		*CommonPageData
 		Stats types.StatsInfo
 	}{
@@ -2186,7 +2354,7 @@ func (exp *explorerUI) StatsPage(w http.ResponseWriter, r *http.Request) {
 
 // MarketPage is the page handler for the "/agendas" path.
 func (exp *explorerUI) MarketPage(w http.ResponseWriter, r *http.Request) {
-	str, err := exp.templates.exec("market", struct {
+	str, err := exp.execTemplate(r, "market", struct {
 		*CommonPageData
 		DepthMarkets []string
 		StickMarkets map[string]string
@@ -2369,7 +2537,7 @@ func (exp *explorerUI) AttackCost(w http.ResponseWriter, r *http.Request) {
 
 	exp.pageData.RUnlock()
 
-	str, err := exp.templates.execTemplateToString("attackcost", struct {
+	str, err := exp.execTemplate(r, "attackcost", struct {
 		*CommonPageData
 		HashRate        float64
 		Height          int64
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2019, The Decred-Next developers
+// See LICENSE for details.
+
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// headlessWriter is the http.ResponseWriter of the headless page routes. The
+// page handlers set an HTML content type, which is replaced with JSON when the
+// header is written.
+type headlessWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader sets the JSON content type before writing the status code.
+func (hw *headlessWriter) WriteHeader(code int) {
+	hw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	hw.ResponseWriter.WriteHeader(code)
+}
+
+// HeadlessPage is middleware for the /api/page routes, which run the regular
+// page handlers but respond with the data they would have rendered with the
+// page's template, encoded as JSON.
+func HeadlessPage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ctxHeadless, true)
+		next.ServeHTTP(&headlessWriter{w}, r.WithContext(ctx))
+	})
+}
+
+// isHeadless checks if the request is for a page's data rather than its HTML.
+func isHeadless(r *http.Request) bool {
+	headless, ok := r.Context().Value(ctxHeadless).(bool)
+	return ok && headless
+}
+
+// execTemplate executes the named template with the page data, or encodes the
+// page data as JSON for a headless request.
+func (exp *explorerUI) execTemplate(r *http.Request, name string, data interface{}) (string, error) {
+	if !isHeadless(r) {
+		return exp.templates.exec(name, data)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// headlessStatus writes the status page data as JSON.
+func headlessStatus(w http.ResponseWriter, code, message, additionalInfo string, sType expStatus) {
+	w.WriteHeader(statusPageCode(sType))
+	json.NewEncoder(w).Encode(struct {
+		StatusType     expStatus `json:"status_type"`
+		Code           string    `json:"code"`
+		Message        string    `json:"message"`
+		AdditionalInfo string    `json:"additional_info,omitempty"`
+	}{
+		StatusType:     sType,
+		Code:           code,
+		Message:        message,
+		AdditionalInfo: additionalInfo,
+	})
+}
+
+// pagePath is the path of the page to which a request is redirected, which is
+// under /api/page for a headless request.
+func pagePath(r *http.Request, path string) string {
+	if isHeadless(r) {
+		return "/api/page" + path
+	}
+	return path
+}
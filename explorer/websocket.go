@@ -40,6 +40,7 @@ var (
 	sigNewTxs           = pstypes.SigNewTxs
 	sigAddressTx        = pstypes.SigAddressTx
 	sigSyncStatus       = pstypes.SigSyncStatus
+	sigDepthChart       = pstypes.SigDepthChart
 )
 
 // WebSocketMessage represents the JSON object used to send and received typed
@@ -245,6 +246,8 @@ func (wsh *WebsocketHub) run() {
 				// so do not relay address signals to any clients.
 				break events
 			case sigSyncStatus:
+			case sigDepthChart:
+				log.Tracef("Signaling depth chart update to %d websocket clients.", clientsCount)
 			default:
 				log.Errorf("Unknown hub signal: %v", hubMsg.Signal)
 				break events
@@ -259,9 +262,13 @@ func (wsh *WebsocketHub) run() {
 				}
 
 				// Signal to the client's PubSubHub send loop, or unregister the
-				// client.
+				// client. Only the depth chart signal carries its message.
+				clientMsg := pstypes.HubMessage{Signal: hubMsg.Signal}
+				if hubMsg.Signal == sigDepthChart {
+					clientMsg.Msg = hubMsg.Msg
+				}
 				select {
-				case *client <- pstypes.HubMessage{Signal: hubMsg.Signal}:
+				case *client <- clientMsg:
 				default:
 					wsh.unregisterClient(client)
 				}
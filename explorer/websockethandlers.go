@@ -313,6 +313,14 @@ func (exp *explorerUI) RootWebsocket(w http.ResponseWriter, r *http.Request) {
 						log.Errorf("json.Encode([]SyncStatusInfo) failed: %v", err)
 					}
 
+				case sigDepthChart:
+					err := enc.Encode(sig.Msg)
+					if err == nil {
+						webData.Message = buff.String()
+					} else {
+						log.Errorf("json.Encode(*DepthChart) failed: %v", err)
+					}
+
 				default:
 					log.Errorf("RootWebsocket: Unhandled signal: %v", sig)
 				}
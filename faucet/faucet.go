@@ -0,0 +1,238 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package faucet sends coins from a wallet to the addresses requested by the
+// clients of a testnet or simnet explorer. Each grant is recorded, and an
+// address may only be granted coins once per interval.
+package faucet
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/txhelpers/v4"
+)
+
+const (
+	// DefaultAmount is the amount granted by each request.
+	DefaultAmount = 10 * dcrutil.AtomsPerCoin
+
+	// DefaultInterval is the time that must elapse before an address may be
+	// granted coins again.
+	DefaultInterval = 24 * time.Hour
+
+	// requestTimeout limits the time taken by each wallet RPC.
+	requestTimeout = 30 * time.Second
+)
+
+// Store is the storage for the faucet grants.
+type Store interface {
+	StoreFaucetGrant(grant *dbtypes.FaucetGrant) error
+	// LastFaucetGrant returns sql.ErrNoRows if the address has never been
+	// granted coins.
+	LastFaucetGrant(address string) (*dbtypes.FaucetGrant, error)
+}
+
+// InvalidAddressError is returned by Request for an address that is not valid
+// on the faucet's network.
+type InvalidAddressError struct {
+	Address string
+	Err     error
+}
+
+func (e *InvalidAddressError) Error() string {
+	return fmt.Sprintf("invalid address %q: %v", e.Address, e.Err)
+}
+
+// RateLimitError is returned by Request for an address that was granted coins
+// within the interval.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("address was granted coins recently, retry in %v",
+		e.RetryAfter.Round(time.Second))
+}
+
+// Config is the configuration of a Faucet. The wallet RPC server is dcrwallet's
+// JSON-RPC server, e.g. https://127.0.0.1:19110 for testnet.
+type Config struct {
+	WalletRPC  string
+	WalletUser string
+	WalletPass string
+	// WalletCert is the path of the wallet RPC server's TLS certificate. The
+	// system's certificate pool is used if not set.
+	WalletCert string
+	Amount     dcrutil.Amount
+	Interval   time.Duration
+	Params     *chaincfg.Params
+}
+
+// Faucet grants coins from the wallet, limited to one grant per address in
+// each interval.
+type Faucet struct {
+	cfg    Config
+	store  Store
+	client *http.Client
+
+	// mtx serializes the requests, so that concurrent requests for an address
+	// are not all granted.
+	mtx sync.Mutex
+}
+
+// New creates a Faucet. The faucet refuses to send mainnet coins.
+func New(cfg *Config, store Store) (*Faucet, error) {
+	if cfg.Params.Net == wire.MainNet {
+		return nil, fmt.Errorf("the faucet may not be used on mainnet")
+	}
+	if cfg.WalletRPC == "" {
+		return nil, fmt.Errorf("no wallet RPC server specified")
+	}
+	if cfg.Amount <= 0 {
+		return nil, fmt.Errorf("invalid faucet amount %v", cfg.Amount)
+	}
+
+	transport := &http.Transport{}
+	if cfg.WalletCert != "" {
+		pem, err := ioutil.ReadFile(cfg.WalletCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wallet certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.WalletCert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &Faucet{
+		cfg:   *cfg,
+		store: store,
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// Amount is the amount granted by each request.
+func (f *Faucet) Amount() dcrutil.Amount {
+	return f.cfg.Amount
+}
+
+// Request sends the faucet amount to the address, unless the address was
+// granted coins within the interval, in which case a *RateLimitError is
+// returned. An *InvalidAddressError is returned for an address that is not
+// valid on the faucet's network.
+func (f *Faucet) Request(address string) (*dbtypes.FaucetGrant, error) {
+	if _, _, addrErr := txhelpers.AddressValidation(address, f.cfg.Params); addrErr != nil {
+		return nil, &InvalidAddressError{address, addrErr}
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	now := time.Now()
+	last, err := f.store.LastFaucetGrant(address)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		log.Errorf("Failed to retrieve the last grant to %s: %v", address, err)
+		return nil, fmt.Errorf("failed to retrieve the last grant")
+	default:
+		if wait := last.Time.T.Add(f.cfg.Interval).Sub(now); wait > 0 {
+			return nil, &RateLimitError{RetryAfter: wait}
+		}
+	}
+
+	txid, err := f.sendToAddress(address, f.cfg.Amount)
+	if err != nil {
+		log.Errorf("Failed to send %v to %s: %v", f.cfg.Amount, address, err)
+		return nil, fmt.Errorf("the faucet wallet failed to send coins")
+	}
+
+	grant := &dbtypes.FaucetGrant{
+		Address: address,
+		Amount:  int64(f.cfg.Amount),
+		TxHash:  txid,
+		Time:    dbtypes.NewTimeDef(now),
+	}
+	// The coins are sent, so a storage failure is only logged.
+	if err = f.store.StoreFaucetGrant(grant); err != nil {
+		log.Errorf("Failed to store the grant of %v to %s in %s: %v",
+			f.cfg.Amount, address, txid, err)
+	}
+	log.Infof("Sent %v to %s in %s.", f.cfg.Amount, address, txid)
+	return grant, nil
+}
+
+// rpcRequest and rpcResponse are the JSON-RPC 1.0 messages of the wallet RPC
+// server.
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// sendToAddress calls the wallet's sendtoaddress method, returning the txid.
+func (f *Faucet) sendToAddress(address string, amount dcrutil.Amount) (string, error) {
+	body, err := json.Marshal(&rpcRequest{
+		JSONRPC: "1.0",
+		ID:      1,
+		Method:  "sendtoaddress",
+		Params:  []interface{}{address, amount.ToCoin()},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.cfg.WalletRPC, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(f.cfg.WalletUser, f.cfg.WalletPass)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp rpcResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&rpcResp)
+	if err != nil {
+		return "", fmt.Errorf("wallet responded with status %d: %v", resp.StatusCode, err)
+	}
+	if rpcResp.Error != nil {
+		return "", fmt.Errorf("wallet error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	var txid string
+	if err = json.Unmarshal(rpcResp.Result, &txid); err != nil {
+		return "", fmt.Errorf("unexpected sendtoaddress result %s", rpcResp.Result)
+	}
+	return txid, nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package faucet
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const (
+	testAddr = "TsfDLrRkk9ciUuwfp2b8PawwnukYD7yAjGd"
+	testTxID = "8d4bd4a9a1b9a3d3f8e0c1b2a3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6"
+)
+
+type memStore struct {
+	grants []*dbtypes.FaucetGrant
+}
+
+func (s *memStore) StoreFaucetGrant(grant *dbtypes.FaucetGrant) error {
+	grant.ID = int64(len(s.grants) + 1)
+	s.grants = append(s.grants, grant)
+	return nil
+}
+
+func (s *memStore) LastFaucetGrant(address string) (*dbtypes.FaucetGrant, error) {
+	for i := len(s.grants) - 1; i >= 0; i-- {
+		if s.grants[i].Address == address {
+			return s.grants[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// walletServer is a wallet RPC server that responds to sendtoaddress with
+// testTxID, counting the calls.
+func walletServer(t *testing.T, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			http.Error(w, "401 Unauthorized.", http.StatusUnauthorized)
+			return
+		}
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Method != "sendtoaddress" || len(req.Params) != 2 ||
+			req.Params[0] != testAddr || req.Params[1] != float64(10) {
+			t.Errorf("unexpected request %+v", req)
+		}
+		*calls++
+		w.Write([]byte(`{"result":"` + testTxID + `","error":null,"id":1}`))
+	}))
+}
+
+func TestNewMainnet(t *testing.T) {
+	_, err := New(&Config{
+		WalletRPC: "https://127.0.0.1:9110",
+		Amount:    DefaultAmount,
+		Interval:  DefaultInterval,
+		Params:    chaincfg.MainNetParams(),
+	}, &memStore{})
+	if err == nil {
+		t.Fatal("expected the faucet to refuse mainnet")
+	}
+}
+
+func TestRequest(t *testing.T) {
+	var calls int
+	srv := walletServer(t, &calls)
+	defer srv.Close()
+
+	store := &memStore{}
+	f, err := New(&Config{
+		WalletRPC:  srv.URL,
+		WalletUser: "user",
+		WalletPass: "pass",
+		Amount:     DefaultAmount,
+		Interval:   time.Hour,
+		Params:     chaincfg.TestNet3Params(),
+	}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A mainnet address is invalid on testnet.
+	_, err = f.Request("Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx")
+	if _, ok := err.(*InvalidAddressError); !ok {
+		t.Errorf("expected an InvalidAddressError, got %v", err)
+	}
+
+	grant, err := f.Request(testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grant.TxHash != testTxID || grant.Amount != int64(DefaultAmount) || grant.ID != 1 {
+		t.Errorf("unexpected grant %+v", grant)
+	}
+
+	// A second request within the interval is rate limited.
+	_, err = f.Request(testAddr)
+	rlErr, ok := err.(*RateLimitError)
+	if !ok {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if rlErr.RetryAfter <= 0 || rlErr.RetryAfter > time.Hour {
+		t.Errorf("unexpected retry after %v", rlErr.RetryAfter)
+	}
+
+	// Once the interval has elapsed, the address may be granted coins again.
+	store.grants[0].Time = dbtypes.NewTimeDef(time.Now().Add(-2 * time.Hour))
+	if _, err = f.Request(testAddr); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 wallet calls, got %d", calls)
+	}
+}
+
+func TestRequestWalletError(t *testing.T) {
+	var calls int
+	srv := walletServer(t, &calls)
+	defer srv.Close()
+
+	store := &memStore{}
+	f, err := New(&Config{
+		WalletRPC:  srv.URL,
+		WalletUser: "user",
+		WalletPass: "wrong",
+		Amount:     DefaultAmount,
+		Interval:   time.Hour,
+		Params:     chaincfg.TestNet3Params(),
+	}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Request(testAddr); err == nil {
+		t.Fatal("expected an error for a failed wallet call")
+	}
+	if len(store.grants) != 0 {
+		t.Errorf("expected no grants, got %d", len(store.grants))
+	}
+}
@@ -31,12 +31,13 @@ require (
 	github.com/decred/dcrdata/stakedb/v3 v3.1.1
 	github.com/decred/dcrdata/txhelpers/v4 v4.0.1
 	github.com/decred/slog v1.0.0
-	github.com/dmigwi/go-piparser/proposals v0.0.0-20191219171828-ae8cbf4067e1
 	github.com/dustin/go-humanize v1.0.0
 	github.com/go-chi/chi v4.1.0+incompatible
+	github.com/golang/protobuf v1.3.2
 	github.com/google/gops v0.3.7-0.20190802051910-59c8be2eaddf
 	github.com/googollee/go-engine.io v1.4.3-0.20190924125625-798118fc0dd2
 	github.com/googollee/go-socket.io v1.4.3-0.20191016204530-42fe90fa9ed0
+	github.com/graph-gophers/graphql-go v0.0.0-20191115155744-f33e81362277
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/jrick/logrotate v1.0.0
 	github.com/konsorten/go-windows-terminal-sequences v1.0.2 // indirect
@@ -47,7 +48,9 @@ require (
 	github.com/shiena/ansicolor v0.0.0-20151119151921-a422bbe96644
 	github.com/sirupsen/logrus v1.3.0
 	github.com/x-cray/logrus-prefixed-formatter v0.5.2 // indirect
+	golang.org/x/crypto v0.0.0-20190829043050-9756ffdc2472
 	golang.org/x/net v0.0.0-20191028085509-fe3aa8a45271
+	google.golang.org/grpc v1.24.0
 )
 
 replace (
@@ -5,11 +5,13 @@
 package piclient
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"time"
 
 	pitypes "github.com/decred/dcrdata/gov/v3/politeia/types"
 	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
@@ -43,6 +45,38 @@ func HandleGetRequests(client *http.Client, URLPath string) ([]byte, error) {
 	return ioutil.ReadAll(response.Body)
 }
 
+// HandlePostRequests is like HandleGetRequests, except that it makes a POST
+// request with the JSON encoding of reqBody.
+func HandlePostRequests(client *http.Client, URLPath string, reqBody interface{}) ([]byte, error) {
+	if client == nil {
+		return nil, fmt.Errorf("invalid http client was passed")
+	}
+
+	if URLPath == "" {
+		return nil, fmt.Errorf("empty API URL is not supported")
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Post(URLPath, "application/json", bytes.NewReader(body))
+	if err != nil || response == nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+
+	defer response.Body.Close()
+
+	// Check if valid status code (200 Ok) was returned.
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request (%s) failed with status code: %s",
+			URLPath, response.Status)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
 // DropURLRegex replaces "{token:[A-z0-9]{64}}" in a URL with provided the parameter.
 func DropURLRegex(URLPath, param string) string {
 	r := regexp.MustCompile(`\{token:\[A-z0-9]\{64\}}`)
@@ -128,3 +162,111 @@ func RetrieveProposalByToken(client *http.Client, APIRootPath, token string) (*p
 
 	return &proposal, nil
 }
+
+// RetrieveVoteResults returns the vote options and all the votes cast on the
+// proposal identified by the token. Data returned is queried from Politeia API.
+func RetrieveVoteResults(client *http.Client, APIRootPath, token string) (*pitypes.VoteResults, error) {
+	voteResultsRoute := APIRootPath + DropURLRegex(piapi.RouteVoteResults, token)
+	data, err := HandleGetRequests(client, voteResultsRoute)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving %s proposal vote results failed: %v", token, err)
+	}
+
+	var results pitypes.VoteResults
+	err = json.Unmarshal(data, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// RouteProposalBillingDetails is the CMS API route of the invoices billed to a
+// proposal.
+const RouteProposalBillingDetails = "/proposals/spendingdetails"
+
+// The CMS API types of the proposal billing details, as documented here
+// https://github.com/decred/politeia/blob/master/politeiawww/api/cms/v1/api.md#proposal-billing-details.
+// Only the fields used are decoded.
+type billingDetailsReply struct {
+	Details struct {
+		Token       string          `json:"token"`
+		Title       string          `json:"title"`
+		TotalBilled int64           `json:"totalbilled"`
+		Invoices    []invoiceRecord `json:"invoices"`
+	} `json:"details"`
+}
+
+type invoiceRecord struct {
+	Username string `json:"username"`
+	Input    struct {
+		Month          uint   `json:"month"`
+		Year           uint   `json:"year"`
+		ExchangeRate   uint   `json:"exchangerate"`
+		ContractorRate uint   `json:"contractorrate"`
+		PaymentAddress string `json:"paymentaddress"`
+		LineItems      []struct {
+			ProposalToken string `json:"proposaltoken"`
+			SubRate       uint   `json:"subrate"`
+			Labor         uint   `json:"labor"`    // minutes
+			Expenses      uint   `json:"expenses"` // US cents
+		} `json:"lineitems"`
+	} `json:"input"`
+	Payment struct {
+		TxIDs []string `json:"txids"`
+	} `json:"payment"`
+	CensorshipRecord struct {
+		Token string `json:"token"`
+	} `json:"censorshiprecord"`
+}
+
+// RetrieveProposalBilling returns the billing of the proposal identified by
+// the token, from the invoices with line items for the proposal. Data returned
+// is queried from the CMS API at CMSRootPath.
+func RetrieveProposalBilling(client *http.Client, CMSRootPath, token string) (*pitypes.ProposalBilling, error) {
+	data, err := HandlePostRequests(client, CMSRootPath+RouteProposalBillingDetails,
+		map[string]string{"token": token})
+	if err != nil {
+		return nil, fmt.Errorf("retrieving %s proposal billing failed: %v", token, err)
+	}
+
+	var reply billingDetailsReply
+	if err = json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+
+	billing := &pitypes.ProposalBilling{
+		Token:       token,
+		Title:       reply.Details.Title,
+		TotalBilled: reply.Details.TotalBilled,
+		Invoices:    make([]pitypes.BillingInvoice, 0, len(reply.Details.Invoices)),
+		UpdatedAt:   time.Now().Unix(),
+	}
+	for _, inv := range reply.Details.Invoices {
+		// Sum the line items billed to the proposal. Labor is billed at the
+		// subcontractor's hourly rate if set, otherwise the contractor's.
+		var billed int64
+		for _, li := range inv.Input.LineItems {
+			if li.ProposalToken != token {
+				continue
+			}
+			rate := inv.Input.ContractorRate
+			if li.SubRate > 0 {
+				rate = li.SubRate
+			}
+			billed += int64(li.Labor)*int64(rate)/60 + int64(li.Expenses)
+		}
+		billing.Invoices = append(billing.Invoices, pitypes.BillingInvoice{
+			Token:          inv.CensorshipRecord.Token,
+			Month:          inv.Input.Month,
+			Year:           inv.Input.Year,
+			Contractor:     inv.Username,
+			Billed:         billed,
+			ExchangeRate:   inv.Input.ExchangeRate,
+			PaymentAddress: inv.Input.PaymentAddress,
+			TxIDs:          inv.Payment.TxIDs,
+		})
+	}
+
+	return billing, nil
+}
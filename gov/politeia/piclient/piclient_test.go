@@ -56,3 +56,41 @@ func TestHandleGetRequests(t *testing.T) {
 		})
 	}
 }
+
+// TestRetrieveProposalBilling tests that the line items billed to a proposal
+// are summed for each invoice.
+func TestRetrieveProposalBilling(t *testing.T) {
+	const token = "27f87171d98b7923a1bd2bee6affed929fa2d2a6e178b5c80a9971a92a5c7f50"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != RouteProposalBillingDetails {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"details":{"token":"%[1]s","title":"Marketing","totalbilled":152500,
+			"invoices":[{"username":"contractor","input":{"month":3,"year":2020,
+			"exchangerate":1600,"contractorrate":6000,"paymentaddress":"DsTest",
+			"lineitems":[{"proposaltoken":"%[1]s","labor":120,"expenses":2500},
+			{"proposaltoken":"%[1]s","subrate":3000,"labor":60,"expenses":0},
+			{"proposaltoken":"","labor":600,"expenses":0}]},
+			"payment":{"txids":["abcd"]},"censorshiprecord":{"token":"inv1"}}]}}`, token)
+	}))
+	defer server.Close()
+
+	billing, err := RetrieveProposalBilling(server.Client(), server.URL, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if billing.Title != "Marketing" || billing.TotalBilled != 152500 || len(billing.Invoices) != 1 {
+		t.Fatalf("unexpected billing %+v", billing)
+	}
+	inv := billing.Invoices[0]
+	// 2 hours at $60 plus $25 expenses, and 1 hour at the $30 subcontractor
+	// rate. The line item of another proposal is not billed.
+	if inv.Billed != 12000+2500+3000 {
+		t.Errorf("expected 17500 cents billed, got %d", inv.Billed)
+	}
+	if inv.Token != "inv1" || inv.Month != 3 || inv.Year != 2020 || inv.Contractor != "contractor" ||
+		inv.ExchangeRate != 1600 || inv.PaymentAddress != "DsTest" || len(inv.TxIDs) != 1 {
+		t.Errorf("unexpected invoice %+v", inv)
+	}
+}
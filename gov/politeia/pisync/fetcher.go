@@ -0,0 +1,188 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package pisync retrieves the Politeia proposals and the votes cast on them
+// from the politeiawww API for storage in the auxiliary DB. A Fetcher signals
+// periodically that the proposals should be updated, and the votes cast on a
+// proposal only need to be retrieved when its vote count has changed, so the
+// updates are incremental.
+package pisync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/gov/v3/politeia/piclient"
+	pitypes "github.com/decred/dcrdata/gov/v3/politeia/types"
+	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+const (
+	// DefaultURL is the root URL of the mainnet Politeia API.
+	DefaultURL = "https://proposals.decred.org"
+
+	// DefaultInterval is the default time between the update signals.
+	DefaultInterval = 10 * time.Minute
+)
+
+// Fetcher retrieves the proposals and their cast votes from the Politeia API.
+type Fetcher struct {
+	client     *http.Client
+	APIURLpath string
+	interval   time.Duration
+	signal     chan struct{}
+}
+
+// NewFetcher creates a Fetcher for the Politeia API at politeiaURL, which
+// should just be the domain part of the url without the API versioning, e.g.
+// https://proposals.decred.org. An update is signaled every interval once Run
+// is called.
+func NewFetcher(politeiaURL string, interval time.Duration) (*Fetcher, error) {
+	if politeiaURL == "" {
+		return nil, fmt.Errorf("missing politeia API URL")
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	// Create the http client used to query the API endpoints.
+	c := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:       10,
+			IdleConnTimeout:    5 * time.Second,
+			DisableCompression: false,
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	return &Fetcher{
+		client:     c,
+		APIURLpath: fmt.Sprintf("%s/api/v%d", politeiaURL, piapi.PoliteiaWWWAPIVersion),
+		interval:   interval,
+		// The buffered channel allows a signal to wait for the previous update
+		// to complete, while further signals are dropped.
+		signal: make(chan struct{}, 1),
+	}, nil
+}
+
+// Run signals an update immediately and then every interval, until the context
+// is canceled, at which point the update signal channel is closed.
+func (f *Fetcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	defer close(f.signal)
+
+	for {
+		select {
+		case f.signal <- struct{}{}:
+		default:
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// UpdateSignal returns the channel on which the updates are signaled.
+func (f *Fetcher) UpdateSignal() <-chan struct{} {
+	return f.signal
+}
+
+// Proposals retrieves the metadata and vote summaries of all the public
+// proposals.
+func (f *Fetcher) Proposals() ([]*dbtypes.PoliteiaProposal, error) {
+	pageSize := int(piapi.ProposalListPageSize)
+	var proposals []*dbtypes.PoliteiaProposal
+	var URLParams string
+
+	// Since Politeia sets page the limit as piapi.ProposalListPageSize, keep
+	// fetching the proposals till the count of fetched proposals is less than
+	// piapi.ProposalListPageSize.
+	for {
+		data, err := piclient.RetrieveAllProposals(f.client, f.APIURLpath, URLParams)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil || len(data.Data) == 0 {
+			break
+		}
+
+		for _, p := range data.Data {
+			proposals = append(proposals, proposalRecord(p))
+		}
+
+		if len(data.Data) != pageSize {
+			break
+		}
+		URLParams = fmt.Sprintf("?after=%v", data.Data[pageSize-1].TokenVal)
+	}
+
+	return proposals, nil
+}
+
+// CastVotes retrieves all the votes cast on the proposal.
+func (f *Fetcher) CastVotes(token string) ([]dbtypes.ProposalCastVote, error) {
+	results, err := piclient.RetrieveVoteResults(f.client, f.APIURLpath, token)
+	if err != nil {
+		return nil, err
+	}
+	return castVotes(results)
+}
+
+// proposalRecord converts the proposal as returned by the Politeia API for
+// storage.
+func proposalRecord(p *pitypes.ProposalInfo) *dbtypes.PoliteiaProposal {
+	endHeight, _ := strconv.ParseInt(p.Endheight, 10, 64)
+	meta := p.Metadata(0, 0)
+	return &dbtypes.PoliteiaProposal{
+		Token:            p.TokenVal,
+		Name:             p.Name,
+		Username:         p.Username,
+		Status:           p.Status.String(),
+		VoteStatus:       p.VoteStatus.ShortDesc(),
+		NumComments:      p.NumComments,
+		PublishedAt:      dbtypes.NewTimeDef(time.Unix(int64(p.PublishedDate), 0)),
+		EndHeight:        endHeight,
+		EligibleTickets:  p.NumOfEligibleVotes,
+		QuorumPercentage: p.QuorumPercentage,
+		PassPercentage:   p.PassPercentage,
+		Yes:              meta.Yes,
+		No:               meta.No,
+		TotalVotes:       p.TotalVotes,
+	}
+}
+
+// castVotes matches the bits of each cast vote with the vote options. The
+// choices are the capitalized option IDs, e.g. "Yes" and "No".
+func castVotes(results *pitypes.VoteResults) ([]dbtypes.ProposalCastVote, error) {
+	choices := make(map[uint64]string, len(results.StartVote.Vote.Options))
+	for _, opt := range results.StartVote.Vote.Options {
+		choices[uint64(opt.Bits)] = strings.Title(opt.OptionID)
+	}
+
+	votes := make([]dbtypes.ProposalCastVote, 0, len(results.CastVotes))
+	for _, v := range results.CastVotes {
+		bits, err := strconv.ParseUint(v.VoteBit, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vote bit %q of ticket %s: %v",
+				v.VoteBit, v.Ticket, err)
+		}
+		choice, ok := choices[bits]
+		if !ok {
+			return nil, fmt.Errorf("unknown vote bit %q of ticket %s", v.VoteBit, v.Ticket)
+		}
+		votes = append(votes, dbtypes.ProposalCastVote{
+			Ticket: v.Ticket,
+			Choice: choice,
+		})
+	}
+	return votes, nil
+}
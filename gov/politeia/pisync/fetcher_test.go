@@ -0,0 +1,54 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package pisync
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const testToken = "27f87171d98b7923a1bd2bee6affed929fa2d2a6e178b5c80a9971a92a5c7f50"
+
+func TestCastVotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/proposals/"+testToken+"/votes" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"startvote": {"vote": {"options": [
+				{"id": "no", "description": "Don't approve proposal", "bits": 1},
+				{"id": "yes", "description": "Approve proposal", "bits": 2}]}},
+			"castvotes": [
+				{"token": "%[1]s", "ticket": "ticket1", "votebit": "2"},
+				{"token": "%[1]s", "ticket": "ticket2", "votebit": "1"}]}`, testToken)
+	}))
+	defer server.Close()
+
+	f, err := NewFetcher(server.URL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.client = server.Client()
+
+	votes, err := f.CastVotes(testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []dbtypes.ProposalCastVote{
+		{Ticket: "ticket1", Choice: "Yes"},
+		{Ticket: "ticket2", Choice: "No"},
+	}
+	if !reflect.DeepEqual(votes, expected) {
+		t.Errorf("expected votes %v, got %v", expected, votes)
+	}
+
+	if _, err = f.CastVotes("unknown"); err == nil {
+		t.Error("expected an error for an unknown proposal")
+	}
+}
@@ -42,6 +42,9 @@ type ProposalDB struct {
 	client     *http.Client
 	lastSync   int64
 	APIURLpath string
+	// billingURLpath is the versioned CMS API path from which the billing of
+	// the approved proposals is retrieved, if set.
+	billingURLpath string
 }
 
 // NewProposalsDB opens an exiting database or creates a new DB instance with
@@ -113,6 +116,15 @@ func NewProposalsDB(politeiaURL, dbPath string) (*ProposalDB, error) {
 	return proposalDB, nil
 }
 
+// EnableBilling enables the retrieval of the billing of the approved proposals
+// from the CMS API at cmsURL, which should just be the domain part of the url
+// without the API versioning, e.g. https://cms.decred.org.
+func (db *ProposalDB) EnableBilling(cmsURL string) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	db.billingURLpath = cmsURL + "/api/v1"
+}
+
 // Close closes the proposal DB instance created passed if it not nil.
 func (db *ProposalDB) Close() error {
 	if db == nil || db.dbP == nil {
@@ -403,9 +415,74 @@ func (db *ProposalDB) CheckProposalsUpdates() error {
 
 	log.Infof("%d politeia proposal DB records were updated", numRecords)
 
+	if db.billingURLpath != "" {
+		n, err = db.updateProposalBilling()
+		if err != nil {
+			return fmt.Errorf("updateProposalBilling failed: %v", err)
+		}
+		log.Infof("The billing of %d approved proposals was updated", n)
+	}
+
 	return nil
 }
 
+// ProposalBilling returns the billing of the approved proposal identified by
+// the token, or nil if it has not been retrieved.
+func (db *ProposalDB) ProposalBilling(token string) (*pitypes.ProposalBilling, error) {
+	if db == nil || db.dbP == nil {
+		return nil, errDef
+	}
+
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+
+	var billing pitypes.ProposalBilling
+	err := db.dbP.One("Token", token, &billing)
+	if err == storm.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &billing, nil
+}
+
+// updateProposalBilling retrieves and saves the billing of the proposals that
+// were approved by the stakeholders. Invoices are billed to approved proposals
+// every month, so the billing of all approved proposals is updated.
+func (db *ProposalDB) updateProposalBilling() (int, error) {
+	var finished []*pitypes.ProposalInfo
+	err := db.dbP.Select(q.Eq("VoteStatus",
+		pitypes.VoteStatusType(piapi.PropVoteStatusFinished))).Find(&finished)
+	if err != nil && err != storm.ErrNotFound {
+		return 0, err
+	}
+
+	var count int
+	for _, val := range finished {
+		meta := val.Metadata(0, 0)
+		if !meta.QuorumAchieved || !meta.IsPassing {
+			continue
+		}
+
+		billing, err := piclient.RetrieveProposalBilling(db.client, db.billingURLpath, val.TokenVal)
+		if err != nil {
+			// The billing will be retrieved on the next update.
+			log.Errorf("RetrieveProposalBilling failed: %v", err)
+			continue
+		}
+		if billing.Title == "" {
+			billing.Title = val.Name
+		}
+
+		if err = db.dbP.Save(billing); err != nil {
+			return count, fmt.Errorf("saving the billing of %s failed: %v", val.TokenVal, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
 func (db *ProposalDB) lastSavedProposal() (lastP []*pitypes.ProposalInfo, err error) {
 	err = db.dbP.Select().Limit(1).OrderBy("Timestamp").Reverse().Find(&lastP)
 	return
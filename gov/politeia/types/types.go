@@ -104,6 +104,32 @@ type VoteOption struct {
 	Bits        int32  `json:"bits"`
 }
 
+// VoteResults defines the vote options and the votes cast on a proposal as
+// returned by RouteVoteResults route.
+// https://github.com/decred/politeia/blob/master/politeiawww/api/www/v1/api.md#proposal-votes
+type VoteResults struct {
+	StartVote StartVote  `json:"startvote"`
+	CastVotes []CastVote `json:"castvotes"`
+}
+
+// StartVote defines the vote started on a proposal.
+type StartVote struct {
+	Vote VoteParams `json:"vote"`
+}
+
+// VoteParams defines the options of a proposal vote.
+type VoteParams struct {
+	Options []VoteOption `json:"options"`
+}
+
+// CastVote defines a ticket's vote on a proposal. VoteBit is the hex encoding
+// of the bits of the vote option chosen.
+type CastVote struct {
+	Token   string `json:"token"`
+	Ticket  string `json:"ticket"`
+	VoteBit string `json:"votebit"`
+}
+
 // ProposalStatusType defines the various proposal statuses available as referenced
 // in https://github.com/decred/politeia/blob/master/politeiawww/api/www/v1/v1.go
 type ProposalStatusType piapi.PropStatusT
@@ -216,6 +242,32 @@ func (pi *ProposalInfo) IsEqual(b *ProposalInfo) bool {
 	return true
 }
 
+// ProposalBilling is the billing of an approved proposal, from the contractor
+// invoices with line items for the proposal in Politeia's contractor management
+// system (CMS). The amounts billed are in US cents.
+type ProposalBilling struct {
+	Token       string           `json:"token" storm:"id"`
+	Title       string           `json:"title"`
+	TotalBilled int64            `json:"totalbilled"`
+	Invoices    []BillingInvoice `json:"invoices"`
+	// UpdatedAt is the time the billing was retrieved.
+	UpdatedAt int64 `json:"updatedat"`
+}
+
+// BillingInvoice is a contractor invoice for a month of work, of which Billed
+// US cents were billed to the proposal. The invoice was paid in TxIDs, at the
+// exchange rate in US cents per DCR.
+type BillingInvoice struct {
+	Token          string   `json:"token"`
+	Month          uint     `json:"month"`
+	Year           uint     `json:"year"`
+	Contractor     string   `json:"contractor"`
+	Billed         int64    `json:"billed"`
+	ExchangeRate   uint     `json:"exchangerate"`
+	PaymentAddress string   `json:"paymentaddress"`
+	TxIDs          []string `json:"txids"`
+}
+
 // ProposalMetadata contains some status-dependent data representations for
 // display purposes.
 type ProposalMetadata struct {
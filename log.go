@@ -20,10 +20,18 @@ import (
 	"github.com/decred/dcrdata/pubsub/v4"
 	"github.com/decred/dcrdata/rpcutils/v3"
 	"github.com/decred/dcrdata/stakedb/v3"
+	"github.com/decred/dcrdata/v5/analytics"
 	"github.com/decred/dcrdata/v5/api"
 	"github.com/decred/dcrdata/v5/api/insight"
+	"github.com/decred/dcrdata/v5/candles"
 	"github.com/decred/dcrdata/v5/explorer"
+	"github.com/decred/dcrdata/v5/faucet"
 	notify "github.com/decred/dcrdata/v5/notification"
+	"github.com/decred/dcrdata/v5/pricehistory"
+	"github.com/decred/dcrdata/v5/rawpub"
+	"github.com/decred/dcrdata/v5/search"
+	"github.com/decred/dcrdata/v5/vsp"
+	"github.com/decred/dcrdata/v5/webhooks"
 	"github.com/decred/slog"
 	"github.com/jrick/logrotate/rotator"
 )
@@ -70,6 +78,14 @@ var (
 	xcBotLog      = backendLog.Logger("XBOT")
 	agendasLog    = backendLog.Logger("AGDB")
 	proposalsLog  = backendLog.Logger("PRDB")
+	vspLog        = backendLog.Logger("VSPS")
+	webhooksLog   = backendLog.Logger("HOOK")
+	faucetLog     = backendLog.Logger("FCET")
+	rawpubLog     = backendLog.Logger("RPUB")
+	analyticsLog  = backendLog.Logger("ANLY")
+	searchLog     = backendLog.Logger("SRCH")
+	priceLog      = backendLog.Logger("PRCE")
+	candlesLog    = backendLog.Logger("CNDL")
 )
 
 // Initialize package-global logger variables.
@@ -89,6 +105,14 @@ func init() {
 	exchanges.UseLogger(xcBotLog)
 	agendas.UseLogger(agendasLog)
 	politeia.UseLogger(proposalsLog)
+	vsp.UseLogger(vspLog)
+	webhooks.UseLogger(webhooksLog)
+	faucet.UseLogger(faucetLog)
+	rawpub.UseLogger(rawpubLog)
+	analytics.UseLogger(analyticsLog)
+	search.UseLogger(searchLog)
+	pricehistory.UseLogger(priceLog)
+	candles.UseLogger(candlesLog)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -107,6 +131,14 @@ var subsystemLoggers = map[string]slog.Logger{
 	"XBOT": xcBotLog,
 	"AGDB": agendasLog,
 	"PRDB": proposalsLog,
+	"VSPS": vspLog,
+	"HOOK": webhooksLog,
+	"FCET": faucetLog,
+	"RPUB": rawpubLog,
+	"ANLY": analyticsLog,
+	"SRCH": searchLog,
+	"PRCE": priceLog,
+	"CNDL": candlesLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
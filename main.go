@@ -6,6 +6,9 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"database/sql"
 	"fmt"
 	"net"
 	"net/http"
@@ -21,8 +24,11 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
 	"github.com/decred/dcrd/rpcclient/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/cors"
+	"golang.org/x/crypto/acme/autocert"
 
 	"github.com/decred/dcrdata/blockdata/v5"
 	"github.com/decred/dcrdata/db/cache/v3"
@@ -31,6 +37,7 @@ import (
 	"github.com/decred/dcrdata/exchanges/v2"
 	"github.com/decred/dcrdata/gov/v3/agendas"
 	"github.com/decred/dcrdata/gov/v3/politeia"
+	"github.com/decred/dcrdata/gov/v3/politeia/pisync"
 	"github.com/decred/dcrdata/mempool/v5"
 	m "github.com/decred/dcrdata/middleware/v3"
 	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
@@ -38,15 +45,26 @@ import (
 	"github.com/decred/dcrdata/rpcutils/v3"
 	"github.com/decred/dcrdata/semver"
 	"github.com/decred/dcrdata/stakedb/v3"
+	"github.com/decred/dcrdata/v5/analytics"
 	"github.com/decred/dcrdata/v5/api"
+	"github.com/decred/dcrdata/v5/api/dcrdatarpc"
 	"github.com/decred/dcrdata/v5/api/insight"
+	"github.com/decred/dcrdata/v5/candles"
 	"github.com/decred/dcrdata/v5/explorer"
+	"github.com/decred/dcrdata/v5/faucet"
+	"github.com/decred/dcrdata/v5/metrics"
 	notify "github.com/decred/dcrdata/v5/notification"
+	"github.com/decred/dcrdata/v5/pricehistory"
+	"github.com/decred/dcrdata/v5/rawpub"
+	"github.com/decred/dcrdata/v5/search"
 	"github.com/decred/dcrdata/v5/version"
+	"github.com/decred/dcrdata/v5/vsp"
+	"github.com/decred/dcrdata/v5/webhooks"
 
-	"github.com/dmigwi/go-piparser/proposals"
 	"github.com/go-chi/chi"
 	"github.com/google/gops/agent"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -56,10 +74,21 @@ func main() {
 	// Listen for both interrupt signals and shutdown requests.
 	go shutdownListener()
 
-	if err := _main(ctx); err != nil {
-		if logRotator != nil {
-			log.Error(err)
-		}
+	err := _main(ctx)
+	if err != nil && logRotator != nil {
+		log.Error(err)
+	}
+
+	// Ensure shutdown is signaled, even if _main returned with an error, and
+	// wait for the shutdown hooks to complete before exiting. A shutdown that
+	// is already signaled is not requested again, since repeated requests
+	// count toward a forced exit.
+	if !shutdownRequested(ctx) {
+		requestShutdown()
+	}
+	WaitForShutdown()
+
+	if err != nil {
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -74,6 +103,7 @@ func _main(ctx context.Context) error {
 		fmt.Printf("Failed to load dcrdata config: %s\n", err.Error())
 		return err
 	}
+	SetForceExitSignalCount(cfg.ForceExitSignals)
 	defer func() {
 		if logRotator != nil {
 			logRotator.Close()
@@ -110,16 +140,16 @@ func _main(ctx context.Context) error {
 	notifier := notify.NewNotifier(ctx)
 
 	// Connect to dcrd RPC server using a websocket.
-	dcrdClient, nodeVer, err := connectNodeRPC(cfg, notifier.DcrdHandlers())
-	if err != nil || dcrdClient == nil {
+	nodeClient, nodeVer, err := connectNodeRPC(cfg, notifier.DcrdHandlers())
+	if err != nil || nodeClient == nil {
 		return fmt.Errorf("Connection to dcrd failed: %v", err)
 	}
+	dcrdClient := nodeClient.Client
 
 	defer func() {
-		if dcrdClient != nil {
+		if nodeClient != nil {
 			log.Infof("Closing connection to dcrd.")
-			dcrdClient.Shutdown()
-			dcrdClient.WaitForShutdown()
+			nodeClient.Shutdown()
 		}
 		log.Infof("Bye!")
 		time.Sleep(250 * time.Millisecond)
@@ -159,19 +189,18 @@ func _main(ctx context.Context) error {
 
 	log.Infof("Loaded StakeDatabase at height %d", stakeDBHeight)
 
-	var piParser dcrpg.ProposalsFetcher
+	// The proposals fetcher retrieves the proposals and their votes from the
+	// Politeia API for the auxiliary DB. It is started after the initial sync.
+	var piFetcher *pisync.Fetcher
+	var proposalsFetcher dcrpg.ProposalsFetcher
 	if !cfg.DisablePiParser {
-		log.Infof("Setting up the Politeia's proposals clone repository. Please wait...")
-		// If repoName and repoOwner are set to empty strings the defaults are used.
-		parser, err := proposals.NewParser(cfg.PiPropRepoOwner, cfg.PiPropRepoName, cfg.DataDir)
+		piFetcher, err = pisync.NewFetcher(cfg.PoliteiaAPIURL, cfg.PiPollInterval)
 		if err != nil {
-			// since this piparser isn't a requirement to run the explorer, its
-			// failure should not block the system from running.
-			log.Error(err)
-		}
-
-		if parser != nil {
-			piParser = parser
+			// Since the proposals fetcher isn't a requirement to run the
+			// explorer, its failure should not block the system from running.
+			log.Errorf("failed to create the proposals fetcher: %v", err)
+		} else {
+			proposalsFetcher = piFetcher
 		}
 	}
 
@@ -190,6 +219,8 @@ func _main(ctx context.Context) error {
 		User:         cfg.PGUser,
 		Pass:         cfg.PGPass,
 		DBName:       cfg.PGDBName,
+		DSN:          cfg.PGDSN,
+		ReplicaDSNs:  cfg.PGReplicas,
 		QueryTimeout: cfg.PGQueryTimeout,
 	}
 
@@ -211,11 +242,15 @@ func _main(ctx context.Context) error {
 		AddrCacheAddrCap:     cfg.AddrCacheLimit,
 		AddrCacheRowCap:      rowCap,
 		AddrCacheUTXOByteCap: cfg.AddrCacheUXTOCap,
+		VinBatchSize:         cfg.PGVinBatch,
+		VoutBatchSize:        cfg.PGVoutBatch,
+		CopyThreshold:        cfg.PGCopyThreshold,
+		Partition:            cfg.PGPartition,
 	}
 
 	mpChecker := rpcutils.NewMempoolAddressChecker(dcrdClient, activeChain)
 	chainDB, err := dcrpg.NewChainDBWithCancel(ctx, &dbCfg,
-		stakeDB, mpChecker, piParser, dcrdClient, requestShutdown)
+		stakeDB, mpChecker, proposalsFetcher, dcrdClient, requestShutdown)
 	if chainDB != nil {
 		defer chainDB.Close()
 	}
@@ -411,7 +446,8 @@ func _main(ctx context.Context) error {
 	}
 
 	// Build a slice of each required saver type for each data source.
-	blockDataSavers := []blockdata.BlockDataSaver{chainDB}
+	// The chainDB saver's Store latency is recorded by the metrics exporter.
+	blockDataSavers := []blockdata.BlockDataSaver{metrics.NewTimedSaver("chaindb", chainDB)}
 
 	mempoolSavers := []mempool.MempoolDataSaver{chainDB.MPC} // mempool.MempoolDataCache
 
@@ -434,10 +470,14 @@ func _main(ctx context.Context) error {
 			BtcIndex:       cfg.ExchangeCurrency,
 			MasterBot:      cfg.RateMaster,
 			MasterCertFile: cfg.RateCertificate,
+			Sources:        cfg.ExchangeSources,
 		}
 		if cfg.DisabledExchanges != "" {
 			botCfg.Disabled = strings.Split(cfg.DisabledExchanges, ",")
 		}
+		if cfg.ExchangeFiats != "" {
+			botCfg.Currencies = strings.Split(cfg.ExchangeFiats, ",")
+		}
 		xcBot, err = exchanges.NewExchangeBot(&botCfg)
 		if err != nil {
 			log.Errorf("Could not create exchange monitor. Exchange info will be disabled: %v", err)
@@ -470,13 +510,21 @@ func _main(ctx context.Context) error {
 	// creates a new http client needed to query Politeia API endpoints.
 	// When piparser is disabled, disable the API calls too.
 	var proposalsInstance explorer.PoliteiaBackend
+	// The billing of the approved proposals is tracked when a CMS API URL is
+	// set. nil is a sentinel value indicating billing is disabled.
+	var proposalBilling api.ProposalBillingSource
 
 	if !cfg.DisablePiParser {
-		proposalsInstance, err = politeia.NewProposalsDB(cfg.PoliteiaAPIURL,
+		proposalsDB, err := politeia.NewProposalsDB(cfg.PoliteiaAPIURL,
 			filepath.Join(cfg.DataDir, cfg.ProposalsFileName))
 		if err != nil {
 			return fmt.Errorf("failed to create new proposals db instance: %v", err)
 		}
+		if cfg.PoliteiaCMSURL != "" {
+			proposalsDB.EnableBilling(cfg.PoliteiaCMSURL)
+			proposalBilling = proposalsDB
+		}
+		proposalsInstance = proposalsDB
 	} else {
 		log.Info("Piparser is disabled. Proposals API has been disabled too")
 	}
@@ -493,6 +541,13 @@ func _main(ctx context.Context) error {
 		}
 	}
 
+	// Search blocks, transactions, addresses and proposal titles by prefix or
+	// by the words of the titles, for the explorer and the API.
+	searcher := search.NewService(chainDB, proposalsInstance, activeChain)
+	if err = searcher.SyncProposals(); err != nil {
+		log.Warnf("Failed to store proposal titles for search: %v", err)
+	}
+
 	// Create the explorer system.
 	explore := explorer.New(&explorer.ExplorerConfig{
 		DataSource:      chainDB,
@@ -504,6 +559,7 @@ func _main(ctx context.Context) error {
 		AgendasSource:   agendaDB,
 		Tracker:         tracker,
 		ProposalsSource: proposalsInstance,
+		Searcher:        searcher,
 		PoliteiaURL:     cfg.PoliteiaAPIURL,
 		MainnetLink:     cfg.MainnetLink,
 		TestnetLink:     cfg.TestnetLink,
@@ -527,6 +583,186 @@ func _main(ctx context.Context) error {
 	blockDataSavers = append(blockDataSavers, psHub)
 	mempoolSavers = append(mempoolSavers, psHub) // individual transactions are from mempool monitor
 
+	// Sample the DB and node heights for the sync progress served on /api/sync
+	// and sent to the pubsubhub's syncstatus subscribers.
+	go psHub.MonitorSyncProgress(ctx, func() (int64, int64, error) {
+		nodeHeight, err := dcrdClient.GetBlockCount()
+		if err != nil {
+			return 0, 0, err
+		}
+		return chainDB.Height(), nodeHeight, nil
+	})
+
+	// Collect the statistics of the configured VSPs, signaling status changes
+	// to the pubsubhub's vspstatus subscribers.
+	if len(cfg.VSPs) > 0 {
+		vspCollector, err := vsp.NewCollector(cfg.VSPs, chainDB, func(vs *dbtypes.VSPStats) {
+			psHub.SignalVSPStatus(&pstypes.VSPStatus{
+				VSP:           vs.VSP,
+				Time:          vs.Time.UNIX(),
+				Reachable:     vs.Reachable,
+				Closed:        vs.Closed,
+				VspdVersion:   vs.VspdVersion,
+				FeePercentage: vs.FeePercentage,
+				Live:          vs.Live,
+				Voted:         vs.Voted,
+				Missed:        vs.Missed,
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create VSP statistics collector: %v", err)
+		}
+		go vspCollector.Run(ctx, cfg.VSPInterval)
+	}
+
+	// Relay the changes of the exchanges' websocket-synced orderbooks to the
+	// pubsubhub's depthchart subscribers, with the consolidated orderbook.
+	if xcBot != nil {
+		xcChans := xcBot.UpdateChannels()
+		go func() {
+			for {
+				select {
+				case update := <-xcChans.Depth:
+					depth := xcBot.ConsolidatedDepth()
+					if depth == nil {
+						continue
+					}
+					depthChart := &pstypes.DepthChart{
+						Time:     update.Time,
+						Tokens:   update.Tokens,
+						BtcIndex: depth.BtcIndex,
+						Price:    depth.Price,
+						Bids:     make([]pstypes.DepthPoint, 0, len(depth.Bids)),
+						Asks:     make([]pstypes.DepthPoint, 0, len(depth.Asks)),
+					}
+					for _, pt := range depth.Bids {
+						depthChart.Bids = append(depthChart.Bids, pstypes.DepthPoint{
+							Price:    pt.Price,
+							Quantity: pt.Quantity,
+						})
+					}
+					for _, pt := range depth.Asks {
+						depthChart.Asks = append(depthChart.Asks, pstypes.DepthPoint{
+							Price:    pt.Price,
+							Quantity: pt.Quantity,
+						})
+					}
+					psHub.SignalDepthChart(depthChart)
+				case <-xcChans.Quit:
+					return
+				}
+			}
+		}()
+	}
+
+	// Store the candles of the exchanges' DCR-BTC markets so the market charts
+	// retain their history across restarts.
+	if xcBot != nil {
+		go candles.NewRecorder(xcBot, chainDB).Run(ctx)
+	}
+
+	// Import the historical DCR prices for the fiat-valued chart series.
+	if cfg.PriceSource != "" && activeChain.Name != "mainnet" {
+		log.Warnf("Disabling the historical price import. Only available on mainnet.")
+		cfg.PriceSource = ""
+	}
+	if cfg.PriceSource != "" {
+		priceImporter, err := pricehistory.NewImporter(cfg.PriceSource, chainDB,
+			activeChain.GenesisBlock.Header.Timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to create historical price importer: %v", err)
+		}
+		go priceImporter.Run(ctx, cfg.PriceInterval)
+	}
+
+	// Import the curated labels of known addresses.
+	if cfg.AddressTagsFile != "" {
+		numTags, err := chainDB.ImportAddressTags(cfg.AddressTagsFile)
+		if err != nil {
+			return fmt.Errorf("failed to import the address labels: %v", err)
+		}
+		log.Infof("Imported %d address labels from %s.", numTags, cfg.AddressTagsFile)
+	}
+
+	// Notify the callback URLs registered with the address watch API of the
+	// transactions involving the watched addresses.
+	var addrWatcher *webhooks.Watcher
+	var apiWatcher api.AddressWatcher
+	if cfg.Webhooks {
+		addrWatcher, err = webhooks.NewWatcher(chainDB, dcrdClient, activeChain)
+		if err != nil {
+			return fmt.Errorf("failed to create address watcher: %v", err)
+		}
+		go addrWatcher.Run(ctx)
+		blockDataSavers = append(blockDataSavers, addrWatcher)
+		apiWatcher = addrWatcher
+	}
+
+	// Send testnet or simnet coins from the configured wallet to the addresses
+	// requested with the faucet API.
+	var apiFaucet api.Faucet
+	if cfg.FaucetWalletRPC != "" {
+		amount, err := dcrutil.NewAmount(cfg.FaucetAmount)
+		if err != nil {
+			return fmt.Errorf("invalid faucet amount: %v", err)
+		}
+		apiFaucet, err = faucet.New(&faucet.Config{
+			WalletRPC:  cfg.FaucetWalletRPC,
+			WalletUser: cfg.FaucetWalletUser,
+			WalletPass: cfg.FaucetWalletPass,
+			WalletCert: cfg.FaucetWalletCert,
+			Amount:     amount,
+			Interval:   cfg.FaucetInterval,
+			Params:     activeChain,
+		}, chainDB)
+		if err != nil {
+			return fmt.Errorf("failed to create faucet: %v", err)
+		}
+		log.Infof("Faucet enabled, sending %v per request.", amount)
+	}
+
+	// Materialize the coin age analytics as blocks are connected.
+	var coinAge api.CoinAgeCharts
+	if cfg.CoinAge {
+		coinAgeAnalytics := analytics.NewCoinAge(chainDB)
+		go coinAgeAnalytics.Run(ctx)
+		blockDataSavers = append(blockDataSavers, coinAgeAnalytics)
+		coinAge = coinAgeAnalytics
+	}
+
+	// Link addresses into clusters as blocks are confirmed.
+	var addrClusters api.AddressClusters
+	if cfg.AddrClusters {
+		clusterAnalytics := analytics.NewAddressClusters(chainDB)
+		go clusterAnalytics.Run(ctx)
+		blockDataSavers = append(blockDataSavers, clusterAnalytics)
+		addrClusters = clusterAnalytics
+	}
+
+	// Record the history of the transactions seen in mempool.
+	var mpHistory api.MempoolHistory
+	if cfg.MempoolHistory {
+		mempoolHistory := analytics.NewMempoolHistory(chainDB)
+		mempoolSavers = append(mempoolSavers, mempoolHistory)
+		mpHistory = mempoolHistory
+	}
+
+	// Copy the proposal titles for full-text search after each proposals sync.
+	blockDataSavers = append(blockDataSavers, searcher)
+
+	// Publish raw blocks, mempool transactions, and stake events for
+	// subscribers using ZeroMQ or NATS.
+	var rawPublisher *rawpub.Publisher
+	if cfg.RawPub != "" {
+		rawPublisher, err = rawpub.NewPublisher(cfg.RawPub, cfg.RawPubAddr)
+		if err != nil {
+			return fmt.Errorf("failed to create %s raw event publisher: %v", cfg.RawPub, err)
+		}
+		defer rawPublisher.Close()
+		log.Infof("Publishing raw events with %s at %s", cfg.RawPub, cfg.RawPubAddr)
+		blockDataSavers = append(blockDataSavers, rawPublisher)
+	}
+
 	// Store explorerUI data after pubsubhub.
 	blockDataSavers = append(blockDataSavers, explore)
 	mempoolSavers = append(mempoolSavers, explore)
@@ -552,14 +788,20 @@ func _main(ctx context.Context) error {
 
 	// Ensure the initial collect/store succeeded.
 	if err != nil {
-		// Shutdown goroutines.
-		requestShutdown()
+		// Shutdown goroutines, unless the error is due to shutdown.
+		if !shutdownRequested(ctx) {
+			requestShutdown()
+		}
 		return fmt.Errorf("NewMempoolMonitor: %v", err)
 	}
 
 	// Use the MempoolMonitor in aux DB to get unconfirmed transaction data.
 	chainDB.UseMempoolChecker(mpm)
 
+	// Record the receipt times of the block notifications with the block
+	// propagation latencies.
+	chainDB.UseBlockReceiptTimer(notifier)
+
 	// Prepare for sync by setting up the channels for status/progress updates
 	// (barLoad) or full explorer page updates (latestBlockHash).
 
@@ -659,6 +901,17 @@ func _main(ctx context.Context) error {
 		AgendasDBInstance:  agendaDB,
 		MaxAddrs:           cfg.MaxCSVAddrs,
 		Charts:             charts,
+		FeeRates:           mpm,
+		Watcher:            apiWatcher,
+		Faucet:             apiFaucet,
+		FaucetToken:        cfg.FaucetToken,
+		CoinAge:            coinAge,
+		Clusters:           addrClusters,
+		MempoolHistory:     mpHistory,
+		StakeDiffProjector: psHub,
+		SyncProgressor:     psHub,
+		Searcher:           searcher,
+		ProposalBilling:    proposalBilling,
 		IsPiparserDisabled: cfg.DisablePiParser,
 	})
 	// Start the notification hander for keeping /status up-to-date.
@@ -670,22 +923,119 @@ func _main(ctx context.Context) error {
 		chainDB.SignalHeight(uint32(chainDBHeight))
 	}
 
+	// Start the gRPC API, if enabled, and stream new blocks to its
+	// subscribers.
+	if cfg.GRPCListen != "" {
+		grpcServer := api.NewGRPCServer(chainDB, agendaDB)
+		blockDataSavers = append(blockDataSavers, grpcServer)
+		if err = listenAndServeGRPC(ctx, &wg, cfg.GRPCListen, grpcServer); err != nil {
+			return err
+		}
+	}
+
+	// Register the dcrdata metrics served on /metrics.
+	err = metrics.Register(&metrics.Sources{
+		SyncHeight:       chainDB.Height,
+		NodeHeight:       func() int64 { return int64(app.Status.Height()) },
+		AddressCache:     chainDB.AddressCache,
+		DB:               chainDB.SqlDB(),
+		MempoolInventory: psHub.MempoolInventory,
+		WebsocketClients: map[string]func() int{
+			"explorer": explore.NumClients,
+			"pubsub":   psHub.NumClients,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register metrics: %v", err)
+	}
+
+	// Rate limit the API by client IP address, or by the quotas of the API keys
+	// in the database.
+	var apiLimiter *m.RateLimiter
+	if cfg.APIReqRateLimit > 0 {
+		apiLimiter = m.NewRateLimiter(cfg.APIReqRateLimit, cfg.APIReqRateBurst,
+			func(key string) (*m.APIKeyQuota, error) {
+				rateLimit, burst, err := chainDB.APIKeyQuota(key)
+				if err == sql.ErrNoRows {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				return &m.APIKeyQuota{Rate: rateLimit, Burst: burst}, nil
+			})
+	}
+
 	// Configure the URL path to http handler router for the API.
-	apiMux := api.NewAPIRouter(app, cfg.IndentJSON, cfg.UseRealIP, cfg.CompressAPI)
+	apiMux := api.NewAPIRouter(app, cfg.IndentJSON, cfg.UseRealIP, cfg.CompressAPI,
+		apiLimiter)
+
+	// The v2 API translates requests to and responses from the v1 API, which is
+	// deprecated.
+	apiV2Mux := api.NewAPIV2Router(apiMux, cfg.IndentJSON)
+	var apiV1Sunset time.Time
+	if cfg.APIV1Sunset != "" {
+		// Validated by loadConfig.
+		apiV1Sunset, _ = time.Parse(apiSunsetLayout, cfg.APIV1Sunset)
+	}
+
+	// The API responses are signed if a signing key is set.
+	apiSigner := m.Next
+	if cfg.APISigningKey != "" {
+		key, created, err := m.LoadSigningKey(cfg.APISigningKey)
+		if err != nil {
+			return fmt.Errorf("failed to load the API signing key: %v", err)
+		}
+		if created {
+			log.Infof("Created a new API signing key in %s.", cfg.APISigningKey)
+		}
+		pub := key.Public().(ed25519.PublicKey)
+		log.Infof("Signing API responses with the Ed25519 public key %x (key ID %s).",
+			[]byte(pub), m.SigningKeyID(pub))
+		apiSigner = m.SignResponses(key)
+	}
+
+	// The admin endpoints are enabled by setting a token.
+	var adminMux *chi.Mux
+	if cfg.AdminToken != "" {
+		adminMux = api.NewAdminRouter(app, chainDB, cfg.AdminToken,
+			cfg.IndentJSON, cfg.UseRealIP)
+	}
 
 	// File downloads piggy-back on the API.
 	fileMux := api.NewFileRouter(app, cfg.UseRealIP)
 
+	// The GraphQL endpoint shares the API's data source.
+	graphQLHandler, err := api.NewGraphQLHandler(app)
+	if err != nil {
+		return err
+	}
+
 	// Configure the explorer web pages router.
 	webMux := chi.NewRouter()
+	if len(cfg.TrustedProxies) > 0 {
+		trusted, err := m.ParseTrustedProxies(cfg.TrustedProxies)
+		if err != nil {
+			return err
+		}
+		log.Infof("Using client IP address headers from trusted proxies %v", cfg.TrustedProxies)
+		webMux.Use(m.TrustedRealIP(trusted))
+	}
+	// CORS for all routes. With no configured origins, all are allowed.
+	corsMW := cors.New(cors.Options{
+		AllowedOrigins: cfg.CORSOrigins,
+		AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		AllowedHeaders: []string{"Origin", "Accept", "Content-Type",
+			"X-Requested-With", m.APIKeyHeader},
+		ExposedHeaders: []string{m.ResponseSignatureHeader, m.SigningKeyIDHeader,
+			"Deprecation", "Sunset", "Link", "Retry-After"},
+	})
+	corsMW.Log = loggerFunc(log.Tracef)
+	webMux.Use(corsMW.Handler)
 	if cfg.ServerHeader != "" {
 		log.Debugf("Using Server HTTP response header %q", cfg.ServerHeader)
 		webMux.Use(m.Server(cfg.ServerHeader))
 	}
-	webMux.With(explore.SyncStatusPageIntercept).Group(func(r chi.Router) {
-		r.Get("/", explore.Home)
-		r.Get("/visualblocks", explore.VisualBlocks)
-	})
 	webMux.Get("/ws", explore.RootWebsocket)
 	webMux.Get("/ps", psHub.WebSocketHandler)
 
@@ -722,7 +1072,9 @@ func _main(ctx context.Context) error {
 	// enabled (no the full explorer while syncing).
 	webMux.With(explore.SyncStatusAPIIntercept).Group(func(r chi.Router) {
 		// Mount the dcrdata's REST API.
-		r.Mount("/api", apiMux.Mux)
+		r.With(apiSigner, api.DeprecationHeaders("/api", apiV1Sunset)).Mount("/api", apiMux.Mux)
+		r.With(apiSigner).Mount("/api/v2", apiV2Mux)
+		r.Handle("/graphql", graphQLHandler)
 		// Setup and mount the Insight API.
 		insightApp := insight.NewInsightApi(dcrdClient, chainDB,
 			activeChain, mpm, cfg.IndentJSON, app.Status)
@@ -736,15 +1088,23 @@ func _main(ctx context.Context) error {
 		}
 	})
 
+	// The sync progress is available during the sync.
+	webMux.Get("/api/sync", app.SyncProgress)
+
+	// The admin endpoints are available during the sync.
+	if adminMux != nil {
+		webMux.Mount("/admin", adminMux)
+	}
+
 	// HTTP Error 503 StatusServiceUnavailable for file requests before sync.
 	webMux.With(explore.SyncStatusFileIntercept).Group(func(r chi.Router) {
 		r.Mount("/download", fileMux.Mux)
 	})
 
-	webMux.With(explore.SyncStatusPageIntercept).Group(func(r chi.Router) {
-		r.NotFound(explore.NotFound)
-
-		r.Mount("/explorer", explore.Mux)
+	// The explorer pages, which are also served as JSON in headless mode.
+	explorerPages := func(r chi.Router) {
+		r.Get("/", explore.Home)
+		r.Get("/visualblocks", explore.VisualBlocks)
 		r.Get("/days", explore.DayBlocksListing)
 		r.Get("/weeks", explore.WeekBlocksListing)
 		r.Get("/months", explore.MonthBlocksListing)
@@ -752,17 +1112,15 @@ func _main(ctx context.Context) error {
 		r.Get("/blocks", explore.Blocks)
 		r.Get("/ticketpricewindows", explore.StakeDiffWindows)
 		r.Get("/side", explore.SideChains)
-		r.Get("/rejects", func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, "/disapproved", http.StatusPermanentRedirect)
-		})
+		r.With(explore.BlockHashPathOrIndexCtx).Get("/side/{blockhash}", explore.SideChainBlock)
 		r.Get("/disapproved", explore.DisapprovedBlocks)
+		r.Get("/nonstandard", explore.NonstandardOutputs)
 		r.Get("/mempool", explore.Mempool)
 		r.Get("/parameters", explore.ParametersPage)
 		r.With(explore.BlockHashPathOrIndexCtx).Get("/block/{blockhash}", explore.Block)
 		r.With(explorer.TransactionHashCtx).Get("/tx/{txid}", explore.TxPage)
 		r.With(explorer.TransactionHashCtx, explorer.TransactionIoIndexCtx).Get("/tx/{txid}/{inout}/{inoutid}", explore.TxPage)
 		r.With(explorer.AddressPathCtx).Get("/address/{address}", explore.AddressPage)
-		r.With(explorer.AddressPathCtx).Get("/addresstable/{address}", explore.AddressTable)
 		r.Get("/agendas", explore.AgendasPage)
 		r.With(explorer.AgendaPathCtx).Get("/agenda/{agendaid}", explore.AgendaPage)
 		r.Get("/proposals", explore.ProposalsPage)
@@ -773,14 +1131,33 @@ func _main(ctx context.Context) error {
 		r.Get("/ticketpool", explore.Ticketpool)
 		r.Get("/stats", explore.StatsPage)
 		r.Get("/market", explore.MarketPage)
+		r.Get("/attack-cost", explore.AttackCost)
+	}
+
+	webMux.With(explore.SyncStatusPageIntercept).Group(func(r chi.Router) {
+		r.NotFound(explore.NotFound)
+
+		explorerPages(r)
+		r.Mount("/explorer", explore.Mux)
+		r.Get("/rejects", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/disapproved", http.StatusPermanentRedirect)
+		})
+		r.With(explorer.AddressPathCtx).Get("/addresstable/{address}", explore.AddressTable)
 		r.Get("/statistics", func(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/stats", http.StatusPermanentRedirect)
 		})
 		// MenuFormParser will typically redirect, but going to the homepage as a
 		// fallback.
 		r.With(explorer.MenuFormParser).Post("/set", explore.Home)
-		r.Get("/attack-cost", explore.AttackCost)
 	})
+
+	// In headless mode, the data of each explorer page is served as JSON under
+	// /api/page, e.g. /api/page/block/{blockhash} for /block/{blockhash}.
+	if cfg.Headless {
+		log.Infof("Serving explorer page data under /api/page.")
+		webMux.With(explore.SyncStatusAPIIntercept, explorer.HeadlessPage).
+			Route("/api/page", explorerPages)
+	}
 	webMux.Handle("/metrics", promhttp.Handler())
 	// Configure a page for the bare "/insight" path. This mounts the static
 	// assets under /insight (e.g. /insight/js) to support the page's complete
@@ -791,8 +1168,38 @@ func _main(ctx context.Context) error {
 	// all requests with "/insight". (e.g. /insight/js, /insight/css, etc.).
 	mountAssetPaths("/insight")
 
+	// Configure TLS, with certificates from Let's Encrypt if autocert hosts
+	// are set, or else from the configured certificate and key files.
+	var tlsConfig *tls.Config
+	if len(cfg.AutocertHosts) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cfg.AutocertDir),
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertHosts...),
+			Email:      cfg.AutocertEmail,
+		}
+		tlsConfig = manager.TLSConfig()
+		log.Infof("Using Let's Encrypt certificates for %v, cached in %s",
+			cfg.AutocertHosts, cfg.AutocertDir)
+		if cfg.AutocertHTTPListen != "" {
+			// Answer the http-01 challenges, and redirect other requests to
+			// https.
+			listenAndServeProto(ctx, &wg, cfg.AutocertHTTPListen, "http",
+				manager.HTTPHandler(nil), nil)
+		}
+	} else if cfg.APIProto == "https" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate and key: %v", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
 	// Start the web server.
-	listenAndServeProto(ctx, &wg, cfg.APIListen, cfg.APIProto, webMux)
+	listenAndServeProto(ctx, &wg, cfg.APIListen, cfg.APIProto, webMux, tlsConfig)
 
 	// Last chance to quit before syncing if the web server could not start.
 	if shutdownRequested(ctx) {
@@ -843,7 +1250,9 @@ func _main(ctx context.Context) error {
 
 	chainDBHeight, err = getSyncd(updateAllAddresses, newPGIndexes)
 	if err != nil {
-		requestShutdown()
+		if !shutdownRequested(ctx) {
+			requestShutdown()
+		}
 		return err
 	}
 
@@ -865,7 +1274,9 @@ func _main(ctx context.Context) error {
 		for chainDBHeight < height {
 			chainDBHeight, err = getSyncd(updateAllAddresses, newPGIndexes)
 			if err != nil {
-				requestShutdown()
+				if !shutdownRequested(ctx) {
+					requestShutdown()
+				}
 				return err
 			}
 			_, height, err = dcrdClient.GetBestBlock()
@@ -1026,10 +1437,34 @@ func _main(ctx context.Context) error {
 		return fmt.Errorf("updating agendas db failed: %v", err)
 	}
 
-	// Piparser should run updates only after the initial sync
+	// The rich list is materialized from the addresses table, so updates start
+	// after the initial sync and indexing.
+	if cfg.RichListInterval > 0 {
+		go chainDB.RunRichListUpdater(ctx, cfg.RichListInterval, cfg.RichListSize)
+	}
+
+	// The table maintenance scheduler starts after the initial sync, which
+	// analyzes the tables itself, and handles the VACUUM requests made by it.
+	if cfg.PGMaintInterval > 0 {
+		go chainDB.RunMaintenance(ctx, cfg.PGMaintInterval, cfg.PGMaintRatio)
+	}
+
+	// Pruning mode deletes the vins and vouts used by the initial sync's
+	// spending info updates, so pruning starts after the initial sync.
+	if cfg.PruneDepth > 0 {
+		go chainDB.RunPruner(ctx, cfg.PruneInterval, cfg.PruneDepth)
+	}
+
+	// The proposals fetcher should run updates only after the initial sync.
 	if !cfg.DisablePiParser {
-		// Initiate the piparser handler here.
-		chainDB.StartPiparserHandler()
+		// Handle the fetcher's update signals, the first of which is sent as
+		// soon as it starts. An error in fetching the updates should not stop
+		// the system functionality since it could be attributed to the Politeia
+		// API being down.
+		chainDB.StartProposalsHandler()
+		if piFetcher != nil {
+			go piFetcher.Run(ctx)
+		}
 
 		// Retrieve newly added proposals and add them to the proposals db(storm).
 		// Proposal db update is made asynchronously to ensure that the system works
@@ -1039,21 +1474,6 @@ func _main(ctx context.Context) error {
 				log.Errorf("updating proposals db failed: %v", err)
 			}
 		}()
-
-		// It initiates the updates fetch process for the proposal votes data after
-		// sync for the other tables is complete. It is only run if the system is on
-		// full mode. An error in fetching the updates should not stop the system
-		// functionality since it could be attributed to the external systems used.
-		log.Info("Running updates retrieval for Politeia's Proposals. Please wait...")
-
-		// Fetch updates for Politiea's Proposal history(votes) data via the parser.
-		commitsCount, err := chainDB.PiProposalsHistory()
-		if err != nil {
-			log.Errorf("chainDB.PiProposalsHistory failed : %v", err)
-		} else {
-			log.Infof("%d politeia's proposal (auxiliary db) commits were processed",
-				commitsCount)
-		}
 	}
 
 	// Monitors for new blocks, transactions, and reorgs should not run before
@@ -1100,6 +1520,11 @@ func _main(ctx context.Context) error {
 	// Update the current chain state in the ChainDB.
 	chainDB.UpdateChainState(blockData.BlockchainInfo)
 
+	// Count the agenda votes in the blocks stored since the last update.
+	if err = chainDB.UpdateAgendaVoteIntervals(chainDB.Height()); err != nil {
+		log.Warnf("Failed to update agenda vote intervals: %v", err)
+	}
+
 	if err = explore.Store(blockData, msgBlock); err != nil {
 		return fmt.Errorf("Failed to store initial block data for explorer pages: %v", err.Error())
 	}
@@ -1129,7 +1554,14 @@ func _main(ctx context.Context) error {
 	notifier.RegisterReorgHandlerGroup(sdbChainMonitor.ReorgHandler)
 	notifier.RegisterReorgHandlerGroup(wsChainMonitor.ReorgHandler, chainDBChainMonitor.ReorgHandler)
 	notifier.RegisterReorgHandlerGroup(charts.ReorgHandler) // snip charts data
-	notifier.RegisterTxHandlerGroup(mpm.TxHandler, insightSocketServer.SendNewTx)
+	txHandlers := []notify.TxHandler{mpm.TxHandler, insightSocketServer.SendNewTx}
+	if addrWatcher != nil {
+		txHandlers = append(txHandlers, addrWatcher.TxHandler)
+	}
+	if rawPublisher != nil {
+		txHandlers = append(txHandlers, rawPublisher.TxHandler)
+	}
+	notifier.RegisterTxHandlerGroup(txHandlers...)
 
 	// Register for notifications from dcrd. This also sets the daemon RPC
 	// client used by other functions in the notify/notification package (i.e.
@@ -1163,16 +1595,30 @@ func waitForSync(ctx context.Context, aux chan dbtypes.SyncResult) (int64, error
 	return chainDBHeight, nil
 }
 
-func connectNodeRPC(cfg *config, ntfnHandlers *rpcclient.NotificationHandlers) (*rpcclient.Client, semver.Semver, error) {
-	return rpcutils.ConnectNodeRPC(cfg.DcrdServ, cfg.DcrdUser, cfg.DcrdPass,
-		cfg.DcrdCert, cfg.DisableDaemonTLS, true, ntfnHandlers)
+// connectNodeRPC connects to dcrdserv, with failover to the dcrdfallbackserv
+// nodes if any are configured.
+func connectNodeRPC(cfg *config, ntfnHandlers *rpcclient.NotificationHandlers) (*rpcutils.NodeClient, semver.Semver, error) {
+	nodes := []rpcutils.NodeEndpoint{{Host: cfg.DcrdServ, Cert: cfg.DcrdCert}}
+	for i, host := range cfg.DcrdFallbackServ {
+		cert := cfg.DcrdCert
+		if i < len(cfg.DcrdFallbackCert) {
+			cert = cfg.DcrdFallbackCert[i]
+		}
+		nodes = append(nodes, rpcutils.NodeEndpoint{Host: host, Cert: cert})
+	}
+	return rpcutils.ConnectNodeRPCFailover(nodes, cfg.DcrdUser, cfg.DcrdPass,
+		cfg.DisableDaemonTLS, ntfnHandlers)
 }
 
-func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto string, mux http.Handler) {
+// listenAndServeProto starts a web server for mux on the listen address, and
+// shuts it down gracefully when ctx is cancelled. The https proto requires a
+// non-nil tlsConfig with the server's certificates.
+func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto string, mux http.Handler, tlsConfig *tls.Config) {
 	// Try to bind web server
 	server := http.Server{
 		Addr:         listen,
 		Handler:      mux,
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  5 * time.Second,  // slow requests should not hold connections opened
 		WriteTimeout: 60 * time.Second, // hung responses must die
 	}
@@ -1199,7 +1645,8 @@ func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto
 	go func() {
 		var err error
 		if proto == "https" {
-			err = server.ListenAndServeTLS("dcrdata.cert", "dcrdata.key")
+			// The certificates are provided by the TLSConfig.
+			err = server.ListenAndServeTLS("", "")
 		} else {
 			err = server.ListenAndServe()
 		}
@@ -1218,6 +1665,48 @@ func listenAndServeProto(ctx context.Context, wg *sync.WaitGroup, listen, proto
 	time.Sleep(250 * time.Millisecond)
 }
 
+// listenAndServeGRPC starts a gRPC server for the DCRData service with server
+// reflection enabled, and stops it gracefully when ctx is cancelled. An error
+// is returned if the listen address cannot be bound.
+func listenAndServeGRPC(ctx context.Context, wg *sync.WaitGroup, listen string, srv *api.GRPCServer) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %v", listen, err)
+	}
+
+	server := grpc.NewServer()
+	dcrdatarpc.RegisterDCRDataServer(server, srv)
+	reflection.Register(server)
+
+	// Add the graceful shutdown to the waitgroup.
+	wg.Add(1)
+	go func() {
+		<-ctx.Done()
+
+		log.Infof("Gracefully shutting down gRPC server...")
+		// End the block subscription streams, which would otherwise hold up
+		// GracefulStop.
+		srv.Close()
+		server.GracefulStop()
+		wg.Done()
+	}()
+
+	log.Infof("Now serving the gRPC API on %v", listen)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Errorf("gRPC server failed: %v", err)
+			requestShutdown()
+		}
+	}()
+	return nil
+}
+
+type loggerFunc func(string, ...interface{})
+
+func (lw loggerFunc) Printf(str string, args ...interface{}) {
+	lw(str, args...)
+}
+
 // FileServer conveniently sets up a http.FileServer handler to serve static
 // files from path on the file system. Directory listings are denied, as are URL
 // paths containing "..".
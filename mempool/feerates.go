@@ -0,0 +1,153 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+)
+
+// minRelayFeeRate is the default minimum fee rate in DCR/kB for transactions
+// relayed by dcrd, and the lowest fee rate estimate.
+const minRelayFeeRate = 0.0001
+
+// feeRatePercentiles are the fee rate percentiles reported by FeeRates.
+var feeRatePercentiles = []int{10, 25, 50, 75, 90}
+
+type feeRateTx struct {
+	feeRate float64 // DCR/kB
+	size    int32
+}
+
+// feeRateTracker tracks the fee rates and sizes of the regular and ticket
+// purchase transactions in mempool. Votes and revocations do not compete for
+// block space by fee rate, and are not tracked.
+type feeRateTracker struct {
+	mtx          sync.Mutex
+	maxBlockSize int64
+	txs          map[string]feeRateTx
+	// sorted is the txs by descending fee rate, or nil if it must be rebuilt.
+	sorted  []feeRateTx
+	size    int64
+	updated time.Time
+}
+
+func newFeeRateTracker(params *chaincfg.Params) *feeRateTracker {
+	return &feeRateTracker{
+		maxBlockSize: int64(params.MaximumBlockSizes[0]),
+		txs:          make(map[string]feeRateTx),
+		updated:      time.Now(),
+	}
+}
+
+// tracked checks if the transaction's fee rate is tracked.
+func tracked(tx *exptypes.MempoolTx) bool {
+	return tx.Type == "Regular" || tx.Type == "Ticket"
+}
+
+// reset replaces the tracked transactions with the transactions in txs, such
+// as after the mempool is collected for a new block.
+func (t *feeRateTracker) reset(txs []exptypes.MempoolTx) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.txs = make(map[string]feeRateTx, len(txs))
+	t.size = 0
+	for i := range txs {
+		if tracked(&txs[i]) {
+			t.txs[txs[i].Hash] = feeRateTx{txs[i].FeeRate, txs[i].Size}
+			t.size += int64(txs[i].Size)
+		}
+	}
+	t.sorted = nil
+	t.updated = time.Now()
+}
+
+// add tracks a transaction that entered mempool.
+func (t *feeRateTracker) add(tx *exptypes.MempoolTx) {
+	if !tracked(tx) {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if _, found := t.txs[tx.Hash]; found {
+		return
+	}
+	t.txs[tx.Hash] = feeRateTx{tx.FeeRate, tx.Size}
+	t.size += int64(tx.Size)
+	t.sorted = nil
+	t.updated = time.Now()
+}
+
+// sortedTxs returns the tracked transactions by descending fee rate, sorting
+// them if they changed since the last call. The mutex must be held for writes.
+func (t *feeRateTracker) sortedTxs() []feeRateTx {
+	if t.sorted != nil {
+		return t.sorted
+	}
+	t.sorted = make([]feeRateTx, 0, len(t.txs))
+	for _, tx := range t.txs {
+		t.sorted = append(t.sorted, tx)
+	}
+	sort.Slice(t.sorted, func(i, j int) bool {
+		return t.sorted[i].feeRate > t.sorted[j].feeRate
+	})
+	return t.sorted
+}
+
+// estimate is the fee rate required for confirmation within the target number
+// of blocks, assuming that blocks are filled by fee rate up to the maximum
+// block size. It is the fee rate of the first transaction that does not fit in
+// the target blocks, and no less than the minimum relay fee rate.
+func estimate(sorted []feeRateTx, space int64) float64 {
+	var used int64
+	for _, tx := range sorted {
+		used += int64(tx.size)
+		if used > space {
+			if tx.feeRate > minRelayFeeRate {
+				return tx.feeRate
+			}
+			break
+		}
+	}
+	return minRelayFeeRate
+}
+
+// feeRates computes the fee rate percentiles of the tracked transactions, and
+// the fee rate estimates for confirmation within each of the target numbers of
+// blocks.
+func (t *feeRateTracker) feeRates(targets []int) *apitypes.MempoolFeeRates {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	sorted := t.sortedTxs()
+	rates := &apitypes.MempoolFeeRates{
+		Time:        t.updated.Unix(),
+		Count:       len(sorted),
+		Size:        t.size,
+		Percentiles: make([]apitypes.FeeRatePercentile, 0, len(feeRatePercentiles)),
+		Estimates:   make([]apitypes.FeeRateEstimate, 0, len(targets)),
+	}
+	if len(sorted) > 0 {
+		// Percentiles are in ascending order of fee rate.
+		for _, p := range feeRatePercentiles {
+			idx := len(sorted) - 1 - (len(sorted)-1)*p/100
+			rates.Percentiles = append(rates.Percentiles, apitypes.FeeRatePercentile{
+				Percentile: p,
+				FeeRate:    sorted[idx].feeRate,
+			})
+		}
+	}
+	for _, n := range targets {
+		rates.Estimates = append(rates.Estimates, apitypes.FeeRateEstimate{
+			Blocks:  n,
+			FeeRate: estimate(sorted, int64(n)*t.maxBlockSize),
+		})
+	}
+	return rates
+}
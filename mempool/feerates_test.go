@@ -0,0 +1,142 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+)
+
+func TestFeeRateTrackerTxs(t *testing.T) {
+	tracker := newFeeRateTracker(&chaincfg.Params{MaximumBlockSizes: []int{1000}})
+
+	// Votes and revocations are not tracked.
+	tracker.reset([]exptypes.MempoolTx{
+		{Hash: "regular", Type: "Regular", FeeRate: 0.001, Size: 300},
+		{Hash: "ticket", Type: "Ticket", FeeRate: 0.002, Size: 250},
+		{Hash: "vote", Type: "Vote", FeeRate: 0.5, Size: 300},
+		{Hash: "revocation", Type: "Revocation", FeeRate: 0.5, Size: 200},
+	})
+
+	tests := []struct {
+		name      string
+		tx        *exptypes.MempoolTx
+		wantCount int
+		wantSize  int64
+	}{
+		{"reset", nil, 2, 550},
+		{"duplicate", &exptypes.MempoolTx{Hash: "regular", Type: "Regular",
+			FeeRate: 0.001, Size: 300}, 2, 550},
+		{"vote", &exptypes.MempoolTx{Hash: "vote2", Type: "Vote",
+			FeeRate: 0.5, Size: 300}, 2, 550},
+		{"regular", &exptypes.MempoolTx{Hash: "regular2", Type: "Regular",
+			FeeRate: 0.003, Size: 400}, 3, 950},
+	}
+
+	for _, test := range tests {
+		if test.tx != nil {
+			tracker.add(test.tx)
+		}
+		rates := tracker.feeRates(nil)
+		if rates.Count != test.wantCount || rates.Size != test.wantSize {
+			t.Errorf("%s: expected %d transactions of %d bytes, got %d of %d bytes",
+				test.name, test.wantCount, test.wantSize, rates.Count, rates.Size)
+		}
+	}
+
+	// A new block resets the tracked transactions.
+	tracker.reset(nil)
+	if rates := tracker.feeRates(nil); rates.Count != 0 || rates.Size != 0 {
+		t.Errorf("expected no transactions after reset, got %d of %d bytes",
+			rates.Count, rates.Size)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	sorted := []feeRateTx{
+		{0.005, 400},
+		{0.004, 400},
+		{0.003, 400},
+		{0.002, 400},
+		{0.00005, 400},
+	}
+
+	tests := []struct {
+		name   string
+		sorted []feeRateTx
+		space  int64
+		want   float64
+	}{
+		{"empty", nil, 1000, minRelayFeeRate},
+		{"first tx too large", sorted, 300, 0.005},
+		{"partial", sorted, 1000, 0.003},
+		{"exact fit", sorted, 1200, 0.002},
+		{"below relay fee rate", sorted, 1600, minRelayFeeRate},
+		{"all fit", sorted, 2000, minRelayFeeRate},
+	}
+
+	for _, test := range tests {
+		if got := estimate(test.sorted, test.space); got != test.want {
+			t.Errorf("%s: expected fee rate %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestFeeRates(t *testing.T) {
+	tracker := newFeeRateTracker(&chaincfg.Params{MaximumBlockSizes: []int{800}})
+
+	// An empty mempool has no percentiles, and the minimum relay fee rate
+	// estimates.
+	rates := tracker.feeRates([]int{1, 2})
+	if rates.Count != 0 || len(rates.Percentiles) != 0 {
+		t.Errorf("expected no percentiles, got %v", rates.Percentiles)
+	}
+	wantEstimates := []apitypes.FeeRateEstimate{
+		{Blocks: 1, FeeRate: minRelayFeeRate},
+		{Blocks: 2, FeeRate: minRelayFeeRate},
+	}
+	if !reflect.DeepEqual(rates.Estimates, wantEstimates) {
+		t.Errorf("expected estimates %v, got %v", wantEstimates, rates.Estimates)
+	}
+
+	tracker.reset([]exptypes.MempoolTx{
+		{Hash: "a", Type: "Regular", FeeRate: 0.002, Size: 400},
+		{Hash: "b", Type: "Ticket", FeeRate: 0.005, Size: 400},
+		{Hash: "c", Type: "Regular", FeeRate: 0.00005, Size: 400},
+		{Hash: "d", Type: "Regular", FeeRate: 0.001, Size: 400},
+		{Hash: "e", Type: "Ticket", FeeRate: 0.004, Size: 400},
+		{Hash: "f", Type: "Regular", FeeRate: 0.003, Size: 400},
+	})
+	rates = tracker.feeRates([]int{1, 2, 3})
+
+	// The percentiles are in ascending order of fee rate.
+	wantPercentiles := []apitypes.FeeRatePercentile{
+		{Percentile: 10, FeeRate: 0.00005},
+		{Percentile: 25, FeeRate: 0.001},
+		{Percentile: 50, FeeRate: 0.002},
+		{Percentile: 75, FeeRate: 0.003},
+		{Percentile: 90, FeeRate: 0.004},
+	}
+	if !reflect.DeepEqual(rates.Percentiles, wantPercentiles) {
+		t.Errorf("expected percentiles %v, got %v", wantPercentiles, rates.Percentiles)
+	}
+
+	// Two 400 byte transactions fit in each 800 byte block.
+	wantEstimates = []apitypes.FeeRateEstimate{
+		{Blocks: 1, FeeRate: 0.003},
+		{Blocks: 2, FeeRate: 0.001},
+		{Blocks: 3, FeeRate: minRelayFeeRate},
+	}
+	if !reflect.DeepEqual(rates.Estimates, wantEstimates) {
+		t.Errorf("expected estimates %v, got %v", wantEstimates, rates.Estimates)
+	}
+	if rates.Count != 6 || rates.Size != 2400 {
+		t.Errorf("expected 6 transactions of 2400 bytes, got %d of %d bytes",
+			rates.Count, rates.Size)
+	}
+}
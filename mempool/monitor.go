@@ -16,6 +16,7 @@ import (
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v2"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
 	exptypes "github.com/decred/dcrdata/explorer/types/v2"
 	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
 	"github.com/decred/dcrdata/txhelpers/v4"
@@ -27,6 +28,13 @@ type MempoolDataSaver interface {
 	StoreMPData(*StakeData, []exptypes.MempoolTx, *exptypes.MempoolInfo)
 }
 
+// MempoolTxSaver is a MempoolDataSaver that also stores each new transaction as
+// it enters mempool, between the collections of the full mempool.
+type MempoolTxSaver interface {
+	MempoolDataSaver
+	StoreMPTx(*exptypes.MempoolTx)
+}
+
 // MempoolAddressStore wraps txhelpers.MempoolAddressStore with a Mutex.
 type MempoolAddressStore struct {
 	mtx   sync.Mutex
@@ -55,6 +63,7 @@ type MempoolMonitor struct {
 	collector  *MempoolDataCollector
 	dataSavers []MempoolDataSaver
 	client     txhelpers.VerboseTransactionGetter
+	feeRates   *feeRateTracker
 
 	// Outgoing message
 	signalOuts []chan<- pstypes.HubMessage
@@ -76,6 +85,7 @@ func NewMempoolMonitor(ctx context.Context, collector *MempoolDataCollector,
 		collector:  collector,
 		dataSavers: savers,
 		client:     client,
+		feeRates:   newFeeRateTracker(params),
 		signalOuts: signalOuts,
 	}
 
@@ -322,6 +332,8 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 			p.inventory.LatestTransactions...)
 	}
 
+	p.feeRates.add(&tx)
+
 	// Store totals.
 	p.inventory.NumAll++
 	p.inventory.TotalOut += tx.TotalOut
@@ -336,12 +348,30 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 	p.inventory.Unlock()
 	p.mtx.RUnlock()
 
+	// Store the new transaction with the savers of individual transactions.
+	for _, s := range p.dataSavers {
+		if txSaver, ok := s.(MempoolTxSaver); ok {
+			go txSaver.StoreMPTx(tx.DeepCopy())
+		}
+	}
+
 	// Broadcast the new transaction.
 	log.Tracef("Signaling new tx to hub relays...")
 	p.hubSend(pstypes.SigNewTx, &tx, time.Second*10)
 	return nil
 }
 
+// FeeRates returns the fee rate percentiles of the regular and ticket purchase
+// transactions in mempool, and the fee rates estimated to be required for
+// confirmation within each of the target numbers of blocks. The estimates are
+// computed from this node's view of mempool, which is updated as transactions
+// are received.
+func (p *MempoolMonitor) FeeRates(targets []int) *apitypes.MempoolFeeRates {
+	rates := p.feeRates.feeRates(targets)
+	rates.Height = uint32(p.LastBlockHeight())
+	return rates
+}
+
 func (p *MempoolMonitor) hubSend(sig pstypes.HubSignal, msg interface{}, timeout time.Duration) {
 	for _, sigout := range p.signalOuts {
 		select {
@@ -394,6 +424,8 @@ func (p *MempoolMonitor) Refresh() (*StakeData, []exptypes.MempoolTx, *exptypes.
 	p.addrMap.store = addrOuts
 	p.addrMap.mtx.Unlock()
 
+	p.feeRates.reset(txs)
+
 	// Insert new ticket counter into stakeData structure.
 	stakeData.NewTickets = uint32(newTickets)
 
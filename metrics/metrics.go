@@ -0,0 +1,181 @@
+// Copyright (c) 2019, The Decred-Next developers
+// See LICENSE for details.
+
+// Package metrics defines the dcrdata Prometheus metrics. The metrics are
+// registered with the default Prometheus registry, which is served on the
+// /metrics path along with the Go runtime and process metrics.
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/cache/v3"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "dcrdata"
+
+// storeLatency records the time taken by each block data saver to store a new
+// block, labeled by the saver's name.
+var storeLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "block_store_seconds",
+	Help:      "Time taken to store a new block, by block data saver.",
+	// 5ms to about 40s.
+	Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+}, []string{"saver"})
+
+func init() {
+	prometheus.MustRegister(storeLatency)
+}
+
+// TimedSaver is a blockdata.BlockDataSaver that records the latency of the
+// wrapped saver's Store in the block_store_seconds histogram.
+type TimedSaver struct {
+	saver    blockdata.BlockDataSaver
+	observer prometheus.Observer
+}
+
+// NewTimedSaver wraps the BlockDataSaver so that the latency of its Store
+// method is recorded with the provided saver name.
+func NewTimedSaver(name string, saver blockdata.BlockDataSaver) *TimedSaver {
+	return &TimedSaver{
+		saver:    saver,
+		observer: storeLatency.WithLabelValues(name),
+	}
+}
+
+// Store stores the block with the wrapped saver, recording the time taken
+// regardless of the outcome. Store satisfies blockdata.BlockDataSaver.
+func (ts *TimedSaver) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	start := time.Now()
+	err := ts.saver.Store(blockData, msgBlock)
+	ts.observer.Observe(time.Since(start).Seconds())
+	return err
+}
+
+// Sources are the data sources queried for the dcrdata gauges each time the
+// metrics are scraped. The metrics for nil sources are not registered.
+type Sources struct {
+	// SyncHeight is the height of the best block stored in the database.
+	SyncHeight func() int64
+	// NodeHeight is the height of the node's best block.
+	NodeHeight func() int64
+	// AddressCache provides the address cache hit and miss counts.
+	AddressCache *cache.AddressCache
+	// DB provides the PostgreSQL connection pool statistics.
+	DB *sql.DB
+	// MempoolInventory is the current mempool inventory.
+	MempoolInventory func() *exptypes.MempoolInfo
+	// WebsocketClients are the client counts of the websocket hubs, keyed by
+	// the hub name.
+	WebsocketClients map[string]func() int
+}
+
+// Register registers the metrics for the provided sources with the default
+// Prometheus registry.
+func Register(src *Sources) error {
+	var collectors []prometheus.Collector
+	gauge := func(name, help string, labels prometheus.Labels, f func() float64) {
+		collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: labels,
+		}, f))
+	}
+	counter := func(name, help string, f func() float64) {
+		collectors = append(collectors, prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      help,
+		}, f))
+	}
+
+	if src.SyncHeight != nil {
+		gauge("sync_height", "Height of the best block stored in the database.",
+			nil, func() float64 { return float64(src.SyncHeight()) })
+	}
+	if src.NodeHeight != nil {
+		gauge("node_height", "Height of the node's best block.",
+			nil, func() float64 { return float64(src.NodeHeight()) })
+	}
+	if src.SyncHeight != nil && src.NodeHeight != nil {
+		gauge("blocks_behind_tip", "Number of node blocks not yet stored in the database.",
+			nil, func() float64 {
+				behind := src.NodeHeight() - src.SyncHeight()
+				if behind < 0 {
+					return 0
+				}
+				return float64(behind)
+			})
+	}
+
+	if ac := src.AddressCache; ac != nil {
+		for item, stats := range map[string]func() (int, int){
+			"rows":    ac.RowStats,
+			"balance": ac.BalanceStats,
+			"utxos":   ac.UtxoStats,
+			"history": ac.HistoryStats,
+		} {
+			stats := stats
+			gauge("address_cache_hit_ratio", "Fraction of address cache lookups that were hits.",
+				prometheus.Labels{"item": item}, func() float64 {
+					hits, misses := stats()
+					if hits+misses == 0 {
+						return 0
+					}
+					return float64(hits) / float64(hits+misses)
+				})
+		}
+	}
+
+	if db := src.DB; db != nil {
+		gauge("pg_open_connections", "Number of open PostgreSQL connections.",
+			nil, func() float64 { return float64(db.Stats().OpenConnections) })
+		gauge("pg_in_use_connections", "Number of PostgreSQL connections in use.",
+			nil, func() float64 { return float64(db.Stats().InUse) })
+		gauge("pg_idle_connections", "Number of idle PostgreSQL connections.",
+			nil, func() float64 { return float64(db.Stats().Idle) })
+		counter("pg_wait_count_total", "Total number of waits for a PostgreSQL connection.",
+			func() float64 { return float64(db.Stats().WaitCount) })
+		counter("pg_wait_seconds_total", "Total time spent waiting for a PostgreSQL connection.",
+			func() float64 { return db.Stats().WaitDuration.Seconds() })
+	}
+
+	if src.MempoolInventory != nil {
+		// The inventory is nil until the first mempool refresh.
+		mempoolStat := func(f func(*exptypes.MempoolInfo) float64) func() float64 {
+			return func() float64 {
+				inv := src.MempoolInventory()
+				if inv == nil {
+					return 0
+				}
+				inv.RLock()
+				defer inv.RUnlock()
+				return f(inv)
+			}
+		}
+		gauge("mempool_transactions", "Number of transactions in mempool.",
+			nil, mempoolStat(func(inv *exptypes.MempoolInfo) float64 { return float64(inv.NumAll) }))
+		gauge("mempool_bytes", "Total size of the transactions in mempool.",
+			nil, mempoolStat(func(inv *exptypes.MempoolInfo) float64 { return float64(inv.TotalSize) }))
+	}
+
+	for hub, numClients := range src.WebsocketClients {
+		numClients := numClients
+		gauge("websocket_clients", "Number of connected websocket clients.",
+			prometheus.Labels{"hub": hub}, func() float64 { return float64(numClients()) })
+	}
+
+	for _, c := range collectors {
+		if err := prometheus.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/cache/v3"
+	exptypes "github.com/decred/dcrdata/explorer/types/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrape gets the current metric values from the /metrics handler, keyed by
+// the metric name with its labels.
+func scrape(t *testing.T) map[string]float64 {
+	rr := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	values := make(map[string]float64)
+	for _, line := range strings.Split(rr.Body.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		val, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("invalid metric %q: %v", line, err)
+		}
+		values[line[:i]] = val
+	}
+	return values
+}
+
+type saverStub struct {
+	err error
+}
+
+func (s *saverStub) Store(*blockdata.BlockData, *wire.MsgBlock) error {
+	return s.err
+}
+
+func TestTimedSaver(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"stored", nil},
+		{"failed", errors.New("store failed")},
+	}
+
+	for _, test := range tests {
+		saver := NewTimedSaver(test.name, &saverStub{test.err})
+		if err := saver.Store(nil, nil); err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+		count := scrape(t)[`dcrdata_block_store_seconds_count{saver="`+test.name+`"}`]
+		if count != 1 {
+			t.Errorf("%s: expected 1 recorded store, got %v", test.name, count)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// One hit and one miss of the address rows.
+	addrCache := cache.NewAddressCache(100, 10, 1e4)
+	addrCache.StoreRowsCompact("Dsaddr", nil, cache.NewBlockID(&chainhash.Hash{1}, 1))
+	addrCache.Rows("Dsaddr")
+	addrCache.Rows("Dsother")
+
+	var syncHeight, nodeHeight int64 = 95, 100
+	var mempool atomic.Value
+	inv := new(exptypes.MempoolInfo)
+	inv.NumAll = 7
+	inv.TotalSize = 2048
+	mempool.Store(inv)
+
+	err := Register(&Sources{
+		SyncHeight:   func() int64 { return atomic.LoadInt64(&syncHeight) },
+		NodeHeight:   func() int64 { return atomic.LoadInt64(&nodeHeight) },
+		AddressCache: addrCache,
+		MempoolInventory: func() *exptypes.MempoolInfo {
+			return mempool.Load().(*exptypes.MempoolInfo)
+		},
+		WebsocketClients: map[string]func() int{
+			"insight": func() int { return 3 },
+			"pubsub":  func() int { return 0 },
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		syncHeight int64
+		mempool    *exptypes.MempoolInfo
+		want       map[string]float64
+	}{{
+		name:       "syncing",
+		syncHeight: 95,
+		mempool:    inv,
+		want: map[string]float64{
+			"dcrdata_sync_height":                           95,
+			"dcrdata_node_height":                           100,
+			"dcrdata_blocks_behind_tip":                     5,
+			`dcrdata_address_cache_hit_ratio{item="rows"}`:  0.5,
+			`dcrdata_address_cache_hit_ratio{item="utxos"}`: 0,
+			"dcrdata_mempool_transactions":                  7,
+			"dcrdata_mempool_bytes":                         2048,
+			`dcrdata_websocket_clients{hub="insight"}`:      3,
+			`dcrdata_websocket_clients{hub="pubsub"}`:       0,
+		},
+	}, {
+		name:       "ahead of node without mempool",
+		syncHeight: 101,
+		want: map[string]float64{
+			"dcrdata_sync_height":          101,
+			"dcrdata_blocks_behind_tip":    0,
+			"dcrdata_mempool_transactions": 0,
+			"dcrdata_mempool_bytes":        0,
+		},
+	}}
+
+	for _, test := range tests {
+		atomic.StoreInt64(&syncHeight, test.syncHeight)
+		mempool.Store(test.mempool)
+
+		values := scrape(t)
+		for metric, want := range test.want {
+			got, ok := values[metric]
+			if !ok {
+				t.Errorf("%s: metric %s not found", test.name, metric)
+				continue
+			}
+			if got != want {
+				t.Errorf("%s: expected %s to be %v, got %v", test.name, metric, want, got)
+			}
+		}
+		// The metrics of the DB source are not registered without a DB.
+		if _, ok := values["dcrdata_pg_open_connections"]; ok {
+			t.Errorf("%s: unexpected PostgreSQL metrics without a DB", test.name)
+		}
+	}
+}
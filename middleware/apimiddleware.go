@@ -60,6 +60,7 @@ const (
 	ctxXcToken
 	ctxStickWidth
 	ctxIndent
+	ctxRawTxns
 )
 
 type DataSource interface {
@@ -351,6 +352,40 @@ func PostTxnsCtx(next http.Handler) http.Handler {
 	})
 }
 
+// PostRawTxnsCtx extracts serialized transaction hex strings from the POST
+// body, which has the same form as the body for PostTxnsCtx.
+func PostRawTxnsCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := apitypes.Txns{}
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			apiLog.Debugf("No/invalid raw txns: %v", err)
+			http.Error(w, "error reading JSON message", http.StatusBadRequest)
+			return
+		}
+		err = json.Unmarshal(body, &req)
+		if err != nil {
+			apiLog.Debugf("failed to unmarshal JSON request to apitypes.Txns: %v", err)
+			http.Error(w, "failed to unmarshal JSON request", http.StatusBadRequest)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ctxRawTxns, req.Transactions)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRawTxnsCtx retrieves the ctxRawTxns data from the request context. The
+// hex strings are not validated.
+func GetRawTxnsCtx(r *http.Request) ([]string, error) {
+	hexes, ok := r.Context().Value(ctxRawTxns).([]string)
+	if !ok || len(hexes) == 0 {
+		apiLog.Trace("ctxRawTxns not set")
+		return nil, fmt.Errorf("ctxRawTxns not set")
+	}
+	return hexes, nil
+}
+
 // ValidateTxnsPostCtx will confirm Post content length is valid.
 func ValidateTxnsPostCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -14,7 +14,7 @@ require (
 	github.com/go-chi/chi v4.1.0+incompatible
 	github.com/go-chi/docgen v1.0.5
 	github.com/patrickmn/go-cache v2.1.0+incompatible // indirect
-	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4 // indirect
+	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4
 )
 replace (
 	github.com/decred/dcrd/wire v1.3.0 => ../../dcrnd/wire
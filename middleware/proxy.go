@@ -0,0 +1,98 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses the addresses of trusted reverse proxies, each an
+// IP address or a CIDR network such as 10.0.0.0/8.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address %q", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy network %q: %v", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// isTrusted checks if the IP address is in one of the trusted networks.
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClientIP finds the client's IP address from the X-Forwarded-For
+// header, which each proxy appends the address of its peer to. The addresses
+// are read from the right, skipping those of trusted proxies, since a client
+// may send any X-Forwarded-For header of its own. If every address is trusted,
+// the leftmost is the client.
+func forwardedClientIP(xff string, trusted []*net.IPNet) string {
+	hops := strings.Split(xff, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			return ""
+		}
+		if i == 0 || !isTrusted(ip, trusted) {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// TrustedRealIP is like chi's RealIP middleware, setting the request's
+// RemoteAddr to the client's IP address from the X-Forwarded-For or X-Real-IP
+// headers, in that order, but only for requests from the trusted proxies. The
+// headers of requests from other peers are ignored, so that clients cannot
+// spoof their address to evade rate limits. The headers are removed from all
+// requests, so that handlers and rate limiters further down the chain that
+// also read them see only the RemoteAddr.
+func TrustedRealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hf := func(w http.ResponseWriter, r *http.Request) {
+			if isTrusted(net.ParseIP(clientIP(r)), trusted) {
+				var ip string
+				if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+					ip = forwardedClientIP(xff, trusted)
+				} else if xrip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); xrip != nil {
+					ip = xrip.String()
+				}
+				if ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			r.Header.Del("X-Forwarded-For")
+			r.Header.Del("X-Real-IP")
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hf)
+	}
+}
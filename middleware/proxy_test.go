@@ -0,0 +1,71 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets, err := ParseTrustedProxies([]string{"127.0.0.1", "10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"127.0.0.1/32", "10.0.0.0/8", "::1/128"}
+	for i, ipNet := range nets {
+		if ipNet.String() != want[i] {
+			t.Errorf("network %d is %s, want %s", i, ipNet, want[i])
+		}
+	}
+
+	if _, err = ParseTrustedProxies([]string{"localhost"}); err == nil {
+		t.Error("parsed a host name as a trusted proxy")
+	}
+}
+
+func TestTrustedRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remoteAddr string
+	handler := TrustedRealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+		if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("X-Real-IP") != "" {
+			t.Errorf("client IP headers were not removed")
+		}
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		xRealIP    string
+		want       string
+	}{
+		{"untrusted peer", "1.2.3.4:1234", "5.6.7.8", "", "1.2.3.4:1234"},
+		{"trusted proxy", "10.0.0.1:1234", "5.6.7.8", "", "5.6.7.8"},
+		{"spoofed hop", "10.0.0.1:1234", "9.9.9.9, 5.6.7.8", "", "5.6.7.8"},
+		{"proxy chain", "10.0.0.1:1234", "5.6.7.8, 10.0.0.2", "", "5.6.7.8"},
+		{"only proxies", "10.0.0.1:1234", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"x-real-ip", "10.0.0.1:1234", "", "5.6.7.8", "5.6.7.8"},
+		{"invalid hop", "10.0.0.1:1234", "bogus", "", "10.0.0.1:1234"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.xff != "" {
+			req.Header.Set("X-Forwarded-For", tt.xff)
+		}
+		if tt.xRealIP != "" {
+			req.Header.Set("X-Real-IP", tt.xRealIP)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if remoteAddr != tt.want {
+			t.Errorf("%s: RemoteAddr %q, want %q", tt.name, remoteAddr, tt.want)
+		}
+	}
+}
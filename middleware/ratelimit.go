@@ -0,0 +1,205 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// APIKeyHeader is the request header with the optional API key.
+	APIKeyHeader = "X-API-Key"
+
+	// apiKeyCacheTTL is how long the quota of an API key, or the absence of
+	// one for an unknown key, is cached before it is looked up again.
+	apiKeyCacheTTL = 5 * time.Minute
+
+	// bucketIdleTimeout is how long a client's token bucket is kept after its
+	// last request. An idle bucket is full for any reasonable quota.
+	bucketIdleTimeout = 10 * time.Minute
+
+	// pruneInterval is how often the idle buckets and expired API keys are
+	// removed.
+	pruneInterval = time.Minute
+)
+
+// APIKeyQuota is the rate limit of the requests made with an API key, in
+// requests per second with bursts of up to Burst requests. A Rate of zero or
+// less does not limit the key's requests.
+type APIKeyQuota struct {
+	Rate  float64
+	Burst int
+}
+
+// APIKeyLookup retrieves the quota of an API key. A nil quota with a nil error
+// indicates an unknown or disabled key.
+type APIKeyLookup func(key string) (*APIKeyQuota, error)
+
+type cachedQuota struct {
+	quota   *APIKeyQuota
+	fetched time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter is a token bucket rate limiter with a bucket for each client IP
+// address, or for each API key of requests with a key. Use NewRateLimiter to
+// create a RateLimiter, and RateLimit to create the middleware.
+type RateLimiter struct {
+	rate   rate.Limit
+	burst  int
+	lookup APIKeyLookup
+
+	mtx       sync.Mutex
+	buckets   map[string]*bucket
+	keys      map[string]*cachedQuota
+	lastPrune time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing each client IP address the
+// given rate in requests per second, with bursts of up to burst requests. The
+// burst is at least one request. If lookup is not nil, requests with an API
+// key in the X-API-Key header are limited by the key's quota instead.
+func NewRateLimiter(reqPerSec float64, burst int, lookup APIKeyLookup) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:      rate.Limit(reqPerSec),
+		burst:     burst,
+		lookup:    lookup,
+		buckets:   make(map[string]*bucket),
+		keys:      make(map[string]*cachedQuota),
+		lastPrune: time.Now(),
+	}
+}
+
+// keyQuota retrieves the quota of the API key, from the cache if it has not
+// expired.
+func (rl *RateLimiter) keyQuota(key string, now time.Time) (*APIKeyQuota, error) {
+	rl.mtx.Lock()
+	cached, ok := rl.keys[key]
+	rl.mtx.Unlock()
+	if ok && now.Sub(cached.fetched) < apiKeyCacheTTL {
+		return cached.quota, nil
+	}
+
+	quota, err := rl.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	rl.mtx.Lock()
+	rl.keys[key] = &cachedQuota{quota: quota, fetched: now}
+	rl.mtx.Unlock()
+	return quota, nil
+}
+
+// reserve takes a token from the client's bucket, creating the bucket with the
+// limit and burst if required. If the bucket is empty, no token is taken, and
+// the time until a token is available is returned.
+func (rl *RateLimiter) reserve(client string, limit rate.Limit, burst int, now time.Time) time.Duration {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	if now.Sub(rl.lastPrune) > pruneInterval {
+		rl.prune(now)
+	}
+
+	// A new bucket is also created if the API key's quota changed.
+	b, ok := rl.buckets[client]
+	if !ok || b.limiter.Limit() != limit || b.limiter.Burst() != burst {
+		b = &bucket{limiter: rate.NewLimiter(limit, burst)}
+		rl.buckets[client] = b
+	}
+	b.lastSeen = now
+
+	res := b.limiter.ReserveN(now, 1)
+	if !res.OK() {
+		return time.Second
+	}
+	delay := res.DelayFrom(now)
+	if delay > 0 {
+		res.CancelAt(now)
+	}
+	return delay
+}
+
+// prune removes the idle buckets and expired API keys. The mutex must be held.
+func (rl *RateLimiter) prune(now time.Time) {
+	for client, b := range rl.buckets {
+		if now.Sub(b.lastSeen) > bucketIdleTimeout {
+			delete(rl.buckets, client)
+		}
+	}
+	for key, cached := range rl.keys {
+		if now.Sub(cached.fetched) > apiKeyCacheTTL {
+			delete(rl.keys, key)
+		}
+	}
+	rl.lastPrune = now
+}
+
+// clientIP returns the host of the request's RemoteAddr, which is the client's
+// real IP address when the RealIP middleware is used before the rate limiter.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit creates a rate limiting middleware using the provided RateLimiter.
+// Requests exceeding the quota of the client receive a 429 Too Many Requests
+// response with a Retry-After header, in seconds. Requests with an unknown API
+// key receive a 401 Unauthorized response. If the API key lookup fails, the
+// request is limited by client IP address.
+func RateLimit(rl *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hf := func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			client, limit, burst := "ip:"+clientIP(r), rl.rate, rl.burst
+
+			if key := r.Header.Get(APIKeyHeader); key != "" && rl.lookup != nil {
+				quota, err := rl.keyQuota(key, now)
+				switch {
+				case err != nil:
+					apiLog.Errorf("Failed to look up API key quota: %v", err)
+				case quota == nil:
+					http.Error(w, "Invalid API key.", http.StatusUnauthorized)
+					return
+				default:
+					client, limit, burst = "key:"+key, rate.Limit(quota.Rate), quota.Burst
+					if quota.Rate <= 0 {
+						limit = rate.Inf
+					}
+					if burst < 1 {
+						burst = 1
+					}
+				}
+			}
+
+			if delay := rl.reserve(client, limit, burst, now); delay > 0 {
+				retryAfter := int64(math.Ceil(delay.Seconds()))
+				w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+				http.Error(w, "You have reached the maximum request limit.",
+					http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hf)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	lookup := func(key string) (*APIKeyQuota, error) {
+		if key != "goodkey" {
+			return nil, nil
+		}
+		return &APIKeyQuota{Rate: 1, Burst: 3}, nil
+	}
+	rl := NewRateLimiter(0.5, 2, lookup)
+	handler := RateLimit(rl)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remoteAddr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The burst is allowed, and then the client must wait.
+	for i := 0; i < 2; i++ {
+		if code := request("10.0.0.1:1234", "").Code; code != http.StatusOK {
+			t.Fatalf("request %d: got status %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	rec := request("10.0.0.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("got Retry-After %q, want \"2\"", retryAfter)
+	}
+
+	// Other clients have their own buckets.
+	if code := request("10.0.0.2:1234", "").Code; code != http.StatusOK {
+		t.Errorf("got status %d for another IP, want %d", code, http.StatusOK)
+	}
+
+	// The API key's quota applies instead of the IP address's.
+	for i := 0; i < 3; i++ {
+		if code := request("10.0.0.1:1234", "goodkey").Code; code != http.StatusOK {
+			t.Fatalf("keyed request %d: got status %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	if code := request("10.0.0.1:1234", "goodkey").Code; code != http.StatusTooManyRequests {
+		t.Errorf("got status %d for keyed request, want %d", code, http.StatusTooManyRequests)
+	}
+
+	if code := request("10.0.0.3:1234", "badkey").Code; code != http.StatusUnauthorized {
+		t.Errorf("got status %d for unknown key, want %d", code, http.StatusUnauthorized)
+	}
+}
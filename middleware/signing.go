@@ -0,0 +1,108 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// ResponseSignatureHeader is the response header with the hex encoded
+	// Ed25519 signature of the response body.
+	ResponseSignatureHeader = "X-Dcrdata-Response-Signature"
+
+	// SigningKeyIDHeader is the response header with the ID of the key that
+	// signed the response, as given by SigningKeyID.
+	SigningKeyIDHeader = "X-Dcrdata-Key-ID"
+)
+
+// SigningKeyID is the ID of the Ed25519 public key, the hex encoded first 8
+// bytes of the SHA-256 hash of the key. Clients use it to select the key with
+// which to verify a response, for instance when an operator rotates keys.
+func SigningKeyID(pub ed25519.PublicKey) string {
+	hash := sha256.Sum256(pub)
+	return hex.EncodeToString(hash[:8])
+}
+
+// LoadSigningKey loads the Ed25519 private key from the hex encoded 32-byte
+// seed in the file at path. If the file does not exist, a new key is generated
+// and its seed is written to the file, which is readable only by the owner.
+// created indicates if a new key was generated.
+func LoadSigningKey(path string) (key ed25519.PrivateKey, created bool, err error) {
+	seedHex, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		var seed [ed25519.SeedSize]byte
+		if _, err = rand.Read(seed[:]); err != nil {
+			return nil, false, err
+		}
+		err = ioutil.WriteFile(path, []byte(hex.EncodeToString(seed[:])+"\n"), 0600)
+		if err != nil {
+			return nil, false, err
+		}
+		return ed25519.NewKeyFromSeed(seed[:]), true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	seed, err := hex.DecodeString(strings.TrimSpace(string(seedHex)))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid signing key file %s: %v", path, err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, false, fmt.Errorf("invalid signing key file %s: seed is %d "+
+			"bytes, expected %d", path, len(seed), ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), false, nil
+}
+
+// signingRecorder buffers a response so that its body may be signed before the
+// headers are written.
+type signingRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *signingRecorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
+
+func (rec *signingRecorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// SignResponses is middleware that signs the response bodies with the Ed25519
+// key, so that clients with the public key may detect responses tampered with
+// in transit. The signature of the body and the key's ID are set in the
+// ResponseSignatureHeader and SigningKeyIDHeader headers. The response is
+// buffered until the handler returns, and it is not compressed so that the
+// signature is of the body as decoded by the client.
+func SignResponses(key ed25519.PrivateKey) func(http.Handler) http.Handler {
+	keyID := SigningKeyID(key.Public().(ed25519.PublicKey))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("Accept-Encoding")
+
+			rec := &signingRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			body := rec.body.Bytes()
+			h := w.Header()
+			h.Set(ResponseSignatureHeader, hex.EncodeToString(ed25519.Sign(key, body)))
+			h.Set(SigningKeyIDHeader, keyID)
+			w.WriteHeader(rec.status)
+			w.Write(body)
+		})
+	}
+}
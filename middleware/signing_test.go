@@ -0,0 +1,102 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSigningKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signingkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "api-signing.key")
+
+	key, created, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Fatal("expected a new key to be created")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file mode %v, want 0600", perm)
+	}
+
+	loaded, created, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Error("expected the existing key to be loaded")
+	}
+	if !bytes.Equal(key, loaded) {
+		t.Error("loaded key differs from the created key")
+	}
+
+	if err = ioutil.WriteFile(path, []byte("abcd\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = LoadSigningKey(path); err == nil {
+		t.Error("expected an error for a short seed")
+	}
+}
+
+func TestSignResponses(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"height":123}`
+	handler := SignResponses(key)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "" {
+			t.Error("Accept-Encoding was not removed")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/block/best", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec.Body.String() != body {
+		t.Errorf("got body %q, want %q", rec.Body.String(), body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	if keyID := rec.Header().Get(SigningKeyIDHeader); keyID != SigningKeyID(pub) {
+		t.Errorf("got key ID %q, want %q", keyID, SigningKeyID(pub))
+	}
+	sig, err := hex.DecodeString(rec.Header().Get(ResponseSignatureHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, []byte(body), sig) {
+		t.Error("signature does not verify")
+	}
+	if ed25519.Verify(pub, []byte(`{"height":124}`), sig) {
+		t.Error("signature verifies a modified body")
+	}
+}
@@ -37,6 +37,10 @@ import (
 // an error is logged.
 const SyncHandlerDeadline = time.Minute * 5
 
+// receiptTimeExpiry is how long the receipt time of a block notification is
+// kept for BlockReceived.
+const receiptTimeExpiry = time.Hour
+
 // BranchTips describes the old and new chain tips involved in a reorganization.
 type BranchTips struct {
 	OldChainHead   chainhash.Hash
@@ -75,11 +79,15 @@ type Notifier struct {
 		hash   chainhash.Hash
 		height uint32
 	}
+	receipts struct {
+		sync.Mutex
+		times map[chainhash.Hash]time.Time
+	}
 }
 
 // NewNotifier is the constructor for a Notifier.
 func NewNotifier(ctx context.Context) *Notifier {
-	return &Notifier{
+	notifier := &Notifier{
 		ctx: ctx,
 		// anyQ can cause deadlocks if it gets full. All mempool transactions pass
 		// through here, so the size should stay pretty big to accommodate for the
@@ -89,6 +97,30 @@ func NewNotifier(ctx context.Context) *Notifier {
 		block: make([][]BlockHandler, 0),
 		reorg: make([][]ReorgHandler, 0),
 	}
+	notifier.receipts.times = make(map[chainhash.Hash]time.Time)
+	return notifier
+}
+
+// BlockReceived returns the time at which the block connected notification of
+// the block was received, if it was received within the last hour.
+func (notifier *Notifier) BlockReceived(hash chainhash.Hash) (time.Time, bool) {
+	notifier.receipts.Lock()
+	defer notifier.receipts.Unlock()
+	t, ok := notifier.receipts.times[hash]
+	return t, ok
+}
+
+// recordReceipt records the receipt time of the block connected notification
+// of the block, and forgets any expired receipt times.
+func (notifier *Notifier) recordReceipt(hash chainhash.Hash, t time.Time) {
+	notifier.receipts.Lock()
+	defer notifier.receipts.Unlock()
+	for h, received := range notifier.receipts.times {
+		if t.Sub(received) > receiptTimeExpiry {
+			delete(notifier.receipts.times, h)
+		}
+	}
+	notifier.receipts.times[hash] = t
 }
 
 // DCRDNode is an interface to wrap a dcrd rpcclient.Client. The interface
@@ -177,6 +209,7 @@ out:
 // rpcclient.NotificationHandlers.OnBlockConnected
 // TODO: considering using txns [][]byte to save on downstream RPCs.
 func (notifier *Notifier) onBlockConnected(blockHeaderSerialized []byte, _ [][]byte) {
+	received := time.Now()
 	blockHeader := new(wire.BlockHeader)
 	err := blockHeader.FromBytes(blockHeaderSerialized)
 	if err != nil {
@@ -190,6 +223,7 @@ func (notifier *Notifier) onBlockConnected(blockHeaderSerialized []byte, _ [][]b
 
 	log.Debugf("OnBlockConnected: %d / %v (previous: %v)", height, hash, prevHash)
 
+	notifier.recordReceipt(hash, received)
 	notifier.anyQ <- blockHeader
 }
 
@@ -6,6 +6,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
@@ -135,3 +136,22 @@ func TestNotifier(t *testing.T) {
 
 	shutdown()
 }
+
+func TestBlockReceived(t *testing.T) {
+	n := NewNotifier(context.Background())
+	hash, oldHash := *newHash(), *newHash()
+
+	now := time.Now()
+	n.recordReceipt(oldHash, now.Add(-2*receiptTimeExpiry))
+	n.recordReceipt(hash, now)
+
+	if received, ok := n.BlockReceived(hash); !ok || !received.Equal(now) {
+		t.Errorf("BlockReceived = %v, %v, expected %v, true", received, ok, now)
+	}
+	if _, ok := n.BlockReceived(oldHash); ok {
+		t.Error("expired receipt time not forgotten")
+	}
+	if _, ok := n.BlockReceived(*newHash()); ok {
+		t.Error("receipt time of an unknown block")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package pricehistory imports the daily closing prices of DCR from a public
+// historical price API, backfilling the days since a start date and then
+// adding each completed day.
+package pricehistory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+// requestTimeout limits the time taken by each price API request.
+const requestTimeout = 30 * time.Second
+
+// Store is the storage for the prices imported by an Importer.
+type Store interface {
+	StoreDCRPrices(prices []*dbtypes.DCRPrice) error
+	LastDCRPriceDay(currency string) (time.Time, error)
+}
+
+// priceSource retrieves the daily closing prices of DCR in the currency for the
+// days (UTC) in [start, end). Only the Day and Price of the prices are set.
+type priceSource func(ctx context.Context, client *http.Client, currency string,
+	start, end time.Time) ([]*dbtypes.DCRPrice, error)
+
+// Sources are the historical price sources by name.
+var Sources = map[string]priceSource{
+	CoinGecko:     coinGeckoPrices,
+	CryptoCompare: cryptoComparePrices,
+}
+
+// SourceNames is a sorted list of the names of the historical price sources.
+func SourceNames() []string {
+	names := make([]string, 0, len(Sources))
+	for name := range Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Importer imports the daily DCR prices in dbtypes.DCRPriceCurrency from a
+// historical price source into a Store.
+type Importer struct {
+	source   string
+	prices   priceSource
+	store    Store
+	client   *http.Client
+	currency string
+	since    time.Time
+}
+
+// NewImporter creates an Importer for the named source, e.g. "coingecko". If
+// no prices are stored yet, the prices are backfilled from the day of since,
+// which is typically the genesis block time.
+func NewImporter(source string, store Store, since time.Time) (*Importer, error) {
+	prices, ok := Sources[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown price source %q, available sources are %s",
+			source, strings.Join(SourceNames(), ", "))
+	}
+	return &Importer{
+		source:   source,
+		prices:   prices,
+		store:    store,
+		client:   &http.Client{Timeout: requestTimeout},
+		currency: dbtypes.DCRPriceCurrency,
+		since:    since.UTC().Truncate(24 * time.Hour),
+	}, nil
+}
+
+// Run imports the prices of the days completed since the last import
+// immediately, and then at the given interval until the context is canceled.
+func (imp *Importer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := imp.importPrices(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Failed to import DCR prices from %s: %v", imp.source, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// importPrices retrieves and stores the prices of the completed days after the
+// last stored day, or since the start day if none are stored.
+func (imp *Importer) importPrices(ctx context.Context) error {
+	last, err := imp.store.LastDCRPriceDay(imp.currency)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the last stored price: %v", err)
+	}
+	start := imp.since
+	if !last.IsZero() {
+		start = last.UTC().AddDate(0, 0, 1)
+	}
+	// Only the days before the current one have a closing price.
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if !start.Before(end) {
+		return nil
+	}
+
+	retrieved, err := imp.prices(ctx, imp.client, imp.currency, start, end)
+	if err != nil {
+		return err
+	}
+	prices := make([]*dbtypes.DCRPrice, 0, len(retrieved))
+	for _, p := range retrieved {
+		if p.Day.Before(start) || !p.Day.Before(end) || p.Price <= 0 {
+			continue
+		}
+		p.Currency = imp.currency
+		p.Source = imp.source
+		prices = append(prices, p)
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+
+	if err = imp.store.StoreDCRPrices(prices); err != nil {
+		return fmt.Errorf("failed to store prices: %v", err)
+	}
+	log.Infof("Imported %d daily DCR prices in %s from %s.", len(prices),
+		imp.currency, imp.source)
+	return nil
+}
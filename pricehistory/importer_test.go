@@ -0,0 +1,89 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package pricehistory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+type memStore struct {
+	stored []*dbtypes.DCRPrice
+}
+
+func (s *memStore) StoreDCRPrices(prices []*dbtypes.DCRPrice) error {
+	s.stored = append(s.stored, prices...)
+	return nil
+}
+
+func (s *memStore) LastDCRPriceDay(string) (time.Time, error) {
+	var last time.Time
+	for _, p := range s.stored {
+		if p.Day.After(last) {
+			last = p.Day
+		}
+	}
+	return last, nil
+}
+
+func TestImportCoinGecko(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	ms := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/decred/market_chart/range" ||
+			r.URL.Query().Get("vs_currency") != "usd" {
+			http.NotFound(w, r)
+			return
+		}
+		// Hourly prices of the last two days, and the current price.
+		twoDays, yesterday := today.AddDate(0, 0, -2), today.AddDate(0, 0, -1)
+		fmt.Fprintf(w, `{"prices": [[%d, 10], [%d, 11], [%d, 20], [%d, 21], [%d, 30]]}`,
+			ms(twoDays.Add(time.Hour)), ms(twoDays.Add(23*time.Hour)),
+			ms(yesterday.Add(time.Hour)), ms(today), ms(today.Add(time.Hour)))
+	}))
+	defer srv.Close()
+	CoinGeckoURL = srv.URL
+
+	store := new(memStore)
+	imp, err := NewImporter(CoinGecko, store, today.AddDate(0, 0, -2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = imp.importPrices(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The price at midnight closes the previous day, and the current day is
+	// not complete.
+	if len(store.stored) != 2 {
+		t.Fatalf("got %d prices, want 2", len(store.stored))
+	}
+	for i, want := range []float64{11, 21} {
+		p := store.stored[i]
+		if !p.Day.Equal(today.AddDate(0, 0, i-2)) || p.Price != want ||
+			p.Currency != "USD" || p.Source != CoinGecko {
+			t.Errorf("unexpected price %+v", p)
+		}
+	}
+
+	// Nothing is imported until the current day completes.
+	if err = imp.importPrices(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.stored) != 2 {
+		t.Errorf("got %d prices after reimport, want 2", len(store.stored))
+	}
+}
+
+func TestNewImporterUnknownSource(t *testing.T) {
+	if _, err := NewImporter("nosuchsource", new(memStore), time.Now()); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
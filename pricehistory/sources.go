@@ -0,0 +1,147 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package pricehistory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+// The names of the historical price sources.
+const (
+	CoinGecko     = "coingecko"
+	CryptoCompare = "cryptocompare"
+)
+
+// The root URLs of the price APIs.
+var (
+	CoinGeckoURL     = "https://api.coingecko.com/api/v3"
+	CryptoCompareURL = "https://min-api.cryptocompare.com"
+)
+
+// cryptoCompareMaxDays is the maximum number of days of a CryptoCompare
+// histoday response.
+const cryptoCompareMaxDays = 2000
+
+// getJSON requests the URL and decodes the JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %v", u, err)
+	}
+	return nil
+}
+
+// coinGeckoRange models the response of the CoinGecko market_chart/range API.
+// Each price is a [milliseconds, price] pair.
+type coinGeckoRange struct {
+	Prices [][2]float64 `json:"prices"`
+}
+
+// coinGeckoPrices retrieves the DCR prices from the CoinGecko market chart. The
+// granularity of the chart depends on the length of the range, so the closing
+// price of a day is the last price at or before its end, e.g. the daily price
+// at midnight closes the previous day.
+func coinGeckoPrices(ctx context.Context, client *http.Client, currency string,
+	start, end time.Time) ([]*dbtypes.DCRPrice, error) {
+	u := fmt.Sprintf("%s/coins/decred/market_chart/range?vs_currency=%s&from=%d&to=%d",
+		CoinGeckoURL, strings.ToLower(currency), start.Unix(), end.Unix())
+	resp := new(coinGeckoRange)
+	if err := getJSON(ctx, client, u, resp); err != nil {
+		return nil, err
+	}
+
+	closes := make(map[time.Time]*dbtypes.DCRPrice)
+	var days []time.Time
+	for _, pt := range resp.Prices {
+		t := time.Unix(0, int64(pt[0])*int64(time.Millisecond)).UTC()
+		day := t.Add(-time.Nanosecond).Truncate(24 * time.Hour)
+		p, found := closes[day]
+		if !found {
+			p = &dbtypes.DCRPrice{Day: day}
+			closes[day] = p
+			days = append(days, day)
+		}
+		// The prices are in chronological order.
+		p.Price = pt[1]
+	}
+
+	prices := make([]*dbtypes.DCRPrice, 0, len(days))
+	for _, day := range days {
+		prices = append(prices, closes[day])
+	}
+	return prices, nil
+}
+
+// cryptoCompareHistoday models the response of the CryptoCompare histoday API.
+// The time of each day is its start.
+type cryptoCompareHistoday struct {
+	Response string `json:"Response"`
+	Message  string `json:"Message"`
+	Data     struct {
+		Data []struct {
+			Time  int64   `json:"time"`
+			Close float64 `json:"close"`
+		} `json:"Data"`
+	} `json:"Data"`
+}
+
+// cryptoComparePrices retrieves the DCR prices from the CryptoCompare daily
+// history, paging backward from the end until the start, or until the days
+// before DCR was traded, which have no price.
+func cryptoComparePrices(ctx context.Context, client *http.Client, currency string,
+	start, end time.Time) ([]*dbtypes.DCRPrice, error) {
+	var prices []*dbtypes.DCRPrice
+	lastDay := end.AddDate(0, 0, -1)
+	for !lastDay.Before(start) {
+		u := fmt.Sprintf("%s/data/v2/histoday?fsym=DCR&tsym=%s&limit=%d&toTs=%d",
+			CryptoCompareURL, strings.ToUpper(currency), cryptoCompareMaxDays, lastDay.Unix())
+		resp := new(cryptoCompareHistoday)
+		if err := getJSON(ctx, client, u, resp); err != nil {
+			return nil, err
+		}
+		if resp.Response != "Success" {
+			return nil, fmt.Errorf("histoday request failed: %s", resp.Message)
+		}
+
+		days := resp.Data.Data
+		if len(days) == 0 {
+			break
+		}
+		var priced bool
+		for _, d := range days {
+			if d.Close <= 0 {
+				continue
+			}
+			priced = true
+			prices = append(prices, &dbtypes.DCRPrice{
+				Day:   time.Unix(d.Time, 0).UTC(),
+				Price: d.Close,
+			})
+		}
+		prevDay := time.Unix(days[0].Time, 0).UTC().AddDate(0, 0, -1)
+		if !priced || !prevDay.Before(lastDay) {
+			break
+		}
+		lastDay = prevDay
+	}
+	return prices, nil
+}
@@ -49,6 +49,9 @@ async function createWebSocket (loc) {
   ws.registerEvtHandler('exchange', e => {
     globalEventBus.publish('EXCHANGE_UPDATE', JSON.parse(e))
   })
+  ws.registerEvtHandler('depthchart', e => {
+    globalEventBus.publish('DEPTH_UPDATE', JSON.parse(e))
+  })
 }
 
 // Debug logging can be enabled by entering logDebug(true) in the console.
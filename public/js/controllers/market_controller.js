@@ -611,6 +611,8 @@ export default class extends Controller {
     globalEventBus.on('NIGHT_MODE', this.processNightMode)
     this.processXcUpdate = this._processXcUpdate.bind(this)
     globalEventBus.on('EXCHANGE_UPDATE', this.processXcUpdate)
+    this.processDepthUpdate = this._processDepthUpdate.bind(this)
+    globalEventBus.on('DEPTH_UPDATE', this.processDepthUpdate)
     if (darkEnabled()) chartStroke = darkStroke
 
     this.setNameDisplay()
@@ -623,6 +625,7 @@ export default class extends Controller {
     document.removeEventListener(visibilityChange, this.tabVis)
     globalEventBus.off('NIGHT_MODE', this.processNightMode)
     globalEventBus.off('EXCHANGE_UPDATE', this.processXcUpdate)
+    globalEventBus.off('DEPTH_UPDATE', this.processDepthUpdate)
   }
 
   _resize () {
@@ -1203,4 +1206,13 @@ export default class extends Controller {
       }
     }
   }
+
+  _processDepthUpdate (update) {
+    // Refresh the orderbook chart when the live orderbook of the displayed
+    // exchange, or of any exchange for the aggregated chart, has changed.
+    if (!usesOrderbook(settings.chart)) return
+    if (settings.xc !== aggregatedKey && update.tokens.indexOf(settings.xc) === -1) return
+    clearCache(this.lastUrl)
+    this.refreshChart()
+  }
 }
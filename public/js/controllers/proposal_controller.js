@@ -103,6 +103,34 @@ var hourlyVotesConfig = {
   fillColors: ['rgb(150,235,209)', 'rgb(246,182,163)']
 }
 
+// turnoutQuorum is the percentage of the eligible tickets required for a
+// quorum, set from the snapshots of the vote tally.
+let turnoutQuorum = 20
+
+let turnoutConfig = {
+  ...common,
+  legendFormatter: null,
+  labels: ['Date', 'Turnout', 'Approval'],
+  ylabel: 'Percent',
+  colors: ['#2971FF', '#2DD8A3'],
+  labelsSeparateLines: true,
+  underlayCallback: function (context, area, g) {
+    let quorum = turnoutQuorum
+    let xVals = g.xAxisExtremes()
+    let xl = g.toDomCoords(xVals[0], quorum)
+    let xr = g.toDomCoords(xVals[1], quorum)
+
+    context.beginPath()
+    context.strokeStyle = '#ED6D47'
+    context.moveTo(xl[0], xl[1])
+    context.lineTo(xr[0], xr[1])
+    context.fillStyle = '#ED6D47'
+    context.fillText(quorum + '% Quorum', xl[0] + 20, xl[1] + 10)
+    context.closePath()
+    context.stroke()
+  }
+}
+
 let gs = []
 let Dygraph
 let chartData = {}
@@ -110,10 +138,12 @@ let chartData = {}
 let percentData
 let cumulativeData
 let hourlyVotesData
+let turnoutData
 export default class extends Controller {
   static get targets () {
     return ['token', 'approvalMeter', 'cumulative', 'cumulativeLegend',
-      'approval', 'approvalLegend', 'log', 'logLegend'
+      'approval', 'approvalLegend', 'log', 'logLegend', 'turnoutWrap',
+      'turnout', 'turnoutLegend'
     ]
   }
 
@@ -133,11 +163,22 @@ export default class extends Controller {
     let response = await axios.get('/api/proposal/' + this.tokenTarget.dataset.hash)
     chartData = response.data
 
+    // The snapshots of the vote tally are only recorded while the vote is
+    // live, so older proposals have none.
+    let snapshots = null
+    try {
+      let snapResponse = await axios.get('/api/proposal/' + this.tokenTarget.dataset.hash + '/snapshots')
+      snapshots = snapResponse.data
+    } catch (err) {
+      if (!err.response || err.response.status !== 404) console.error(err)
+    }
+
     Dygraph = await getDefault(
       import(/* webpackChunkName: "dygraphs" */ '../vendor/dygraphs.min.js')
     )
 
     this.setChartsData()
+    this.setTurnoutData(snapshots)
     this.plotGraph()
     this.setNightMode = this._setNightMode.bind(this)
     globalEventBus.on('NIGHT_MODE', this.setNightMode)
@@ -178,6 +219,15 @@ export default class extends Controller {
     hourlyVotesData.push([lastDate, 0, 0])
   }
 
+  setTurnoutData (snapshots) {
+    turnoutData = null
+    if (!snapshots || !snapshots.time || snapshots.time.length === 0) return
+    turnoutData = snapshots.time.map((t, i) => {
+      return [new Date(t), snapshots.turnout[i], snapshots.approval[i]]
+    })
+    turnoutQuorum = snapshots.quorum_percentage
+  }
+
   plotGraph () {
     percentConfig.labelsDiv = this.approvalLegendTarget
     cumulativeConfig.labelsDiv = this.cumulativeLegendTarget
@@ -200,6 +250,17 @@ export default class extends Controller {
       )
     ]
 
+    if (turnoutData) {
+      this.turnoutWrapTarget.classList.remove('d-none')
+      this.turnoutWrapTarget.classList.add('d-flex')
+      turnoutConfig.labelsDiv = this.turnoutLegendTarget
+      gs.push(new Dygraph(
+        this.turnoutTarget,
+        turnoutData,
+        turnoutConfig
+      ))
+    }
+
     var options = {
       zoom: true,
       selection: true
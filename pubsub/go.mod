@@ -16,6 +16,7 @@ require (
 	github.com/decred/dcrdata/semver v1.0.0
 	github.com/decred/dcrdata/txhelpers/v4 v4.0.1
 	github.com/decred/slog v1.0.0
+	github.com/gorilla/websocket v1.4.1
 	golang.org/x/net v0.0.0-20191028085509-fe3aa8a45271
 )
 
@@ -295,6 +295,18 @@ func (c *Client) receiver() {
 		case *pstypes.AddressMessage:
 			log.Debugf("Message (%s): AddressMessage(address=%s, txHash=%s)",
 				resp.EventId, m.Address, m.TxHash)
+		case *pstypes.StakeDiffProjection:
+			log.Debugf("Message (%s): StakeDiffProjection(height=%d, projected=%f)",
+				resp.EventId, m.Height, m.Projected)
+		case *pstypes.VSPStatus:
+			log.Debugf("Message (%s): VSPStatus(vsp=%s, reachable=%v, closed=%v)",
+				resp.EventId, m.VSP, m.Reachable, m.Closed)
+		case *pstypes.SyncProgress:
+			log.Debugf("Message (%s): SyncProgress(db=%d, node=%d, eta=%d)",
+				resp.EventId, m.DBHeight, m.NodeHeight, m.ETA)
+		case *pstypes.DepthChart:
+			log.Debugf("Message (%s): DepthChart(tokens=%v, bids=%d, asks=%d)",
+				resp.EventId, m.Tokens, len(m.Bids), len(m.Asks))
 		default:
 			log.Debugf("Message of type %v unhandled.", resp.EventId)
 			continue
@@ -510,6 +522,22 @@ func DecodeMsg(msg *pstypes.WebSocketMessage) (interface{}, error) {
 		var mpshort exptypes.MempoolShort
 		err := json.Unmarshal(msg.Message, &mpshort)
 		return &mpshort, err
+	case "stakediff":
+		var sdiff pstypes.StakeDiffProjection
+		err := json.Unmarshal(msg.Message, &sdiff)
+		return &sdiff, err
+	case "vspstatus":
+		var vs pstypes.VSPStatus
+		err := json.Unmarshal(msg.Message, &vs)
+		return &vs, err
+	case "syncstatus":
+		var sp pstypes.SyncProgress
+		err := json.Unmarshal(msg.Message, &sp)
+		return &sp, err
+	case "depthchart":
+		var dc pstypes.DepthChart
+		err := json.Unmarshal(msg.Message, &dc)
+		return &dc, err
 	default:
 		return nil, fmt.Errorf("unrecognized event type")
 	}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"sync"
 	"time"
@@ -25,20 +26,26 @@ import (
 	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
 	"github.com/decred/dcrdata/semver"
 	"github.com/decred/dcrdata/txhelpers/v4"
-	"golang.org/x/net/websocket"
+	"github.com/gorilla/websocket"
 )
 
-var version = semver.NewSemver(3, 2, 0)
+var version = semver.NewSemver(3, 5, 0)
 
 // Version indicates the semantic version of the pubsub module.
 func Version() semver.Semver {
 	return version
 }
 
-const (
-	wsWriteTimeout = 5 * time.Second
-	wsReadTimeout  = 7 * time.Second
-)
+const wsWriteTimeout = 5 * time.Second
+
+// upgrader upgrades the HTTP connections of new websocket clients. The
+// permessage-deflate extension is negotiated with clients that offer it, but
+// messages are only compressed for clients that opt in. The Origin is not
+// checked.
+var upgrader = websocket.Upgrader{
+	EnableCompression: true,
+	CheckOrigin:       func(*http.Request) bool { return true },
+}
 
 // wsDataSource defines the interface for collecting required data.
 type wsDataSource interface {
@@ -50,6 +57,7 @@ type wsDataSource interface {
 	GetMempool() []exptypes.MempoolTx
 	BlockSubsidy(height int64, voters uint16) *chainjson.GetBlockSubsidyResult
 	Difficulty(timestamp int64) float64
+	EstimateStakeDiff(tickets *uint32) (*chainjson.EstimateStakeDiffResult, error)
 }
 
 // State represents the current state of block chain.
@@ -76,6 +84,20 @@ type connection struct {
 	sync.WaitGroup
 	ws     *websocket.Conn
 	client *clientHubSpoke
+	// wsMtx serializes writes to ws since both the send and receive loops
+	// write to it.
+	wsMtx sync.Mutex
+}
+
+// send writes the message to the websocket connection with a write deadline.
+func (conn *connection) send(msg *pstypes.WebSocketMessage) error {
+	conn.wsMtx.Lock()
+	defer conn.wsMtx.Unlock()
+	err := conn.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err != nil && !pstypes.IsWSClosedErr(err) {
+		log.Warnf("SetWriteDeadline failed: %v", err)
+	}
+	return conn.ws.WriteJSON(msg)
 }
 
 // PubSubHub manages the collection and distribution of block chain and mempool
@@ -87,6 +109,10 @@ type PubSubHub struct {
 	params     *chaincfg.Params
 	invsMtx    sync.RWMutex
 	invs       *exptypes.MempoolInfo
+	sdiffMtx   sync.RWMutex
+	sdiff      *pstypes.StakeDiffProjection
+	syncMtx    sync.RWMutex
+	syncProg   *pstypes.SyncProgress
 	ver        pstypes.Ver
 }
 
@@ -160,6 +186,11 @@ func (psh *PubSubHub) HubRelay() chan pstypes.HubMessage {
 	return psh.wsHub.HubRelay
 }
 
+// NumClients returns the number of clients connected to the websocket hub.
+func (psh *PubSubHub) NumClients() int {
+	return psh.wsHub.NumClients()
+}
+
 // MempoolInventory safely retrieves the current mempool inventory.
 func (psh *PubSubHub) MempoolInventory() *exptypes.MempoolInfo {
 	psh.invsMtx.RLock()
@@ -167,6 +198,83 @@ func (psh *PubSubHub) MempoolInventory() *exptypes.MempoolInfo {
 	return psh.invs
 }
 
+// StakeDiffProjection safely retrieves the last ticket price projection. This
+// is nil until the first block is stored.
+func (psh *PubSubHub) StakeDiffProjection() *pstypes.StakeDiffProjection {
+	psh.sdiffMtx.RLock()
+	defer psh.sdiffMtx.RUnlock()
+	return psh.sdiff
+}
+
+// updateStakeDiffProjection estimates the next window's ticket price with the
+// tickets in mempool assumed to be mined in the current window. If the
+// projection changed, the stakediff subscribers are signaled.
+func (psh *PubSubHub) updateStakeDiffProjection(height int64, idxInWindow int,
+	current float64, mempoolTickets uint32) {
+	est, err := psh.sourceBase.EstimateStakeDiff(&mempoolTickets)
+	if err != nil {
+		log.Warnf("EstimateStakeDiff failed: %v", err)
+		return
+	}
+
+	sdiff := &pstypes.StakeDiffProjection{
+		Height:           height,
+		IdxBlockInWindow: idxInWindow,
+		WindowSize:       psh.params.StakeDiffWindowSize,
+		Current:          current,
+		Min:              est.Min,
+		Max:              est.Max,
+		Expected:         est.Expected,
+		MempoolTickets:   mempoolTickets,
+		Projected:        est.Expected,
+	}
+	if est.User != nil {
+		sdiff.Projected = *est.User
+	}
+
+	psh.sdiffMtx.Lock()
+	changed := psh.sdiff == nil || *psh.sdiff != *sdiff
+	psh.sdiff = sdiff
+	psh.sdiffMtx.Unlock()
+	if !changed {
+		return
+	}
+
+	go func() {
+		select {
+		case psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigStakeDiff}:
+		case <-time.After(time.Second * 10):
+			log.Errorf("sigStakeDiff send failed: Timeout waiting for WebsocketHub.")
+		}
+	}()
+}
+
+// SignalVSPStatus sends the status of a voting service provider (VSP) to the
+// "vspstatus" subscribers. This is intended to be called by the VSP statistics
+// collector when a VSP's status changes.
+func (psh *PubSubHub) SignalVSPStatus(vs *pstypes.VSPStatus) {
+	go func() {
+		select {
+		case psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigVSPStatus, Msg: vs}:
+		case <-time.After(time.Second * 10):
+			log.Errorf("sigVSPStatus send failed: Timeout waiting for WebsocketHub.")
+		}
+	}()
+}
+
+// SignalDepthChart sends the consolidated orderbook of the Decred exchanges to
+// the "depthchart" subscribers. This is intended to be called when the
+// ExchangeBot signals that the websocket-synced orderbooks have changed.
+func (psh *PubSubHub) SignalDepthChart(dc *pstypes.DepthChart) {
+	go func() {
+		select {
+		case psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigDepthChart, Msg: dc}:
+		case <-time.After(time.Second * 10):
+			log.Errorf("sigDepthChart send failed: Timeout waiting for WebsocketHub.")
+		}
+	}()
+}
+
 // closeWS attempts to close a websocket.Conn, logging errors other than those
 // with messages containing ErrWsClosed.
 func closeWS(ws *websocket.Conn) {
@@ -187,25 +295,20 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 	// receiveLoop should be started after conn.Add(1) and before a conn.Wait().
 	defer conn.Done()
 
-	// Receive messages on the websocket.Conn until it is closed.
+	// Receive messages on the websocket.Conn until it is closed. There is no
+	// read deadline since a timed out read fails the connection. A lost client
+	// is instead detected when sending it the periodic ping fails, and the send
+	// loop closes the connection.
 	ws := conn.ws
 	for {
-		// Set this Conn's read deadline.
-		err := ws.SetReadDeadline(time.Now().Add(wsReadTimeout))
-		if err != nil && !pstypes.IsWSClosedErr(err) {
-			log.Warnf("SetReadDeadline: %v", err)
-		}
-
 		// Wait to receive a message on the websocket
 		msg := new(pstypes.WebSocketMessage)
-		if err := websocket.JSON.Receive(ws, &msg); err != nil {
-			// Keep listening for new messages if the read deadline has passed.
-			if pstypes.IsIOTimeoutErr(err) {
-				//log.Tracef("No data read from client in %v. Trying again.", wsReadTimeout)
-				continue
-			}
-			// EOF is a common client disconnected error.
-			if err.Error() != "EOF" && !pstypes.IsWSClosedErr(err) {
+		err := ws.ReadJSON(msg)
+		if err != nil {
+			// EOF and close frames are common client disconnected errors.
+			if err.Error() != "EOF" && !pstypes.IsWSClosedErr(err) &&
+				!websocket.IsCloseError(err, websocket.CloseNormalClosure,
+					websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
 				log.Warnf("websocket client receive error: %v", err)
 			}
 			return
@@ -365,11 +468,7 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 		}
 
 		// Send the response.
-		err = ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
-		if err != nil && !pstypes.IsWSClosedErr(err) {
-			log.Warnf("SetWriteDeadline: %v", err)
-		}
-		if err := websocket.JSON.Send(ws, resp); err != nil {
+		if err := conn.send(&resp); err != nil {
 			// Do not log the error if the connection is just closed.
 			if !pstypes.IsWSClosedErr(err) {
 				log.Debugf("Failed to encode WebSocketMessage (reply) %s: %v",
@@ -399,14 +498,37 @@ func (psh *PubSubHub) sendLoop(conn *connection) {
 	// If returning because the WebSocketHub sent a quit signal, the receive
 	// loop may still be waiting for a message, so it is necessary to close the
 	// websocket.Conn in this case.
-	ws := conn.ws
-	defer closeWS(ws)
+	defer closeWS(conn.ws)
+
+	// A batching client sends its new transactions at its own interval, so
+	// that each frame coalesces the transactions received since the last one.
+	var batchTicks <-chan time.Time
+	if clientData.batching() {
+		ticker := time.NewTicker(clientData.opts.batch)
+		defer ticker.Stop()
+		batchTicks = ticker.C
+	}
 
 loop:
-	for sig := range updateSigChan {
-		log.Tracef("(*PubSubHub)sendLoop: updateSigChan received %v for client %d",
-			sig, clientData.id)
-		// If the update channel is closed, the loop terminates.
+	for {
+		var sig pstypes.HubMessage
+		select {
+		case hubMsg, ok := <-updateSigChan:
+			// If the update channel is closed, the loop terminates.
+			if !ok {
+				return
+			}
+			sig = hubMsg
+			log.Tracef("(*PubSubHub)sendLoop: updateSigChan received %v for client %d",
+				sig, clientData.id)
+		case <-batchTicks:
+			sig = pstypes.HubMessage{Signal: sigNewTxs}
+			if !clientData.isSubscribed(sig) {
+				// Discard the transactions buffered for all clients.
+				clientData.newTxs.reset()
+				continue loop
+			}
+		}
 
 		if !sig.IsValid() {
 			log.Errorf("invalid signal to send: %s / %d", sig.Signal, int(sig.Signal))
@@ -487,6 +609,57 @@ loop:
 
 			pushMsg.Message = buff.Bytes()
 
+		case sigStakeDiff:
+			sdiff := psh.StakeDiffProjection()
+			if sdiff == nil {
+				break // from switch to send empty message
+			}
+			err := enc.Encode(sdiff)
+			if err != nil {
+				log.Warnf("Encode(StakeDiffProjection) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
+		case sigVSPStatus:
+			vs, ok := sig.Msg.(*pstypes.VSPStatus)
+			if !ok {
+				log.Errorf("sigVSPStatus did not store a *VSPStatus in Msg.")
+				continue loop
+			}
+			err := enc.Encode(vs)
+			if err != nil {
+				log.Warnf("Encode(VSPStatus) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
+		case sigSyncProgress:
+			sp, ok := sig.Msg.(*pstypes.SyncProgress)
+			if !ok {
+				log.Errorf("sigSyncProgress did not store a *SyncProgress in Msg.")
+				continue loop
+			}
+			err := enc.Encode(sp)
+			if err != nil {
+				log.Warnf("Encode(SyncProgress) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
+		case sigDepthChart:
+			dc, ok := sig.Msg.(*pstypes.DepthChart)
+			if !ok {
+				log.Errorf("sigDepthChart did not store a *DepthChart in Msg.")
+				continue loop
+			}
+			err := enc.Encode(dc)
+			if err != nil {
+				log.Warnf("Encode(DepthChart) failed: %v", err)
+			}
+
+			pushMsg.Message = buff.Bytes()
+
 		case sigPingAndUserCount:
 			// ping and send user count
 			pushMsg.Message = json.RawMessage(strconv.Itoa(psh.wsHub.NumClients())) // No quotes as this is a JSON integer
@@ -526,72 +699,115 @@ loop:
 		} // switch sig
 
 		// Send the message.
-		err := ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
-		if err != nil && !pstypes.IsWSClosedErr(err) {
-			log.Warnf("SetWriteDeadline failed: %v", err)
-		}
-		if err = websocket.JSON.Send(ws, pushMsg); err != nil {
+		if err := conn.send(&pushMsg); err != nil {
 			// Do not log the error if the connection is just closed.
 			if !pstypes.IsWSClosedErr(err) {
 				log.Debugf("Failed to encode WebSocketMessage (push) %v: %v", sig, err)
 			}
 			// If the send failed, the client is probably gone, quit the
 			// send loop, unregistering the client from the websocket hub.
-			log.Errorf("websocket.JSON.Send of %v type message failed: %v", sig, err)
+			log.Errorf("WriteJSON of %v type message failed: %v", sig, err)
 			return
 		}
-	} // for range { a.k.a. loop:
+	} // for { a.k.a. loop:
+}
+
+// parseClientOptions parses the transport options requested with the URL query
+// of a new websocket connection. "compress" is a boolean opting in to
+// permessage-deflate compression of the messages sent to the client. "batch" is
+// either "true" to coalesce the client's new transactions into frames sent
+// every DefaultBatchInterval, or the interval in milliseconds.
+func parseClientOptions(query url.Values) (opts clientOptions, err error) {
+	if compress := query.Get("compress"); compress != "" {
+		opts.compress, err = strconv.ParseBool(compress)
+		if err != nil {
+			return opts, fmt.Errorf("invalid compress option %q", compress)
+		}
+	}
+
+	batch := query.Get("batch")
+	if batch == "" {
+		return
+	}
+	if b, errB := strconv.ParseBool(batch); errB == nil {
+		if b {
+			opts.batch = DefaultBatchInterval
+		}
+		return
+	}
+	ms, err := strconv.ParseInt(batch, 10, 64)
+	if err != nil || ms < 0 {
+		return opts, fmt.Errorf("invalid batch option %q", batch)
+	}
+	if ms == 0 {
+		return opts, nil
+	}
+	opts.batch = time.Duration(ms) * time.Millisecond
+	if opts.batch < minBatchInterval {
+		opts.batch = minBatchInterval
+	} else if opts.batch > maxBatchInterval {
+		opts.batch = maxBatchInterval
+	}
+	return opts, nil
 }
 
 // WebSocketHandler is the http.HandlerFunc for new websocket connections. The
 // connection is registered with the WebSocketHub, and the send/receive loops
-// are launched.
+// are launched. Clients may opt in to compression and batching of the new
+// transactions with the URL query, e.g. "/ps?compress=1&batch=250". See
+// parseClientOptions.
 func (psh *PubSubHub) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseClientOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The Upgrader responds to the client with an HTTP error on failure.
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debugf("websocket upgrade failed: %v", err)
+		return
+	}
+	// Set the max payload size for this connection.
+	ws.SetReadLimit(int64(psh.wsHub.requestLimit))
+	// Compression is only applied if the client negotiated permessage-deflate.
+	ws.EnableWriteCompression(opts.compress)
+
 	// Register websocket client.
-	ch := psh.wsHub.NewClientHubSpoke()
+	ch := psh.wsHub.newClientHubSpoke(opts)
 	defer close(ch.cl.killed)
 
-	wsHandler := websocket.Handler(func(ws *websocket.Conn) {
-		// Set the max payload size for this connection.
-		ws.MaxPayloadBytes = psh.wsHub.requestLimit
-
-		// The receive loop will be sitting on websocket.JSON.Receive, while the
-		// send loop will be waiting for signals from the WebSocketHub. One must
-		// close the other depending on whether the connection was closed/lost,
-		// or the WebSocketHub quit or forcibly unregistered the client. The
-		// receive loop unregisters the client (thus closing the update signal
-		// channel) when the connection is closed and it returns. The send loop
-		// closes the websocket.Conn on return, which will interrupt the receive
-		// loop from its waiting to receive data on the connection.
-
-		conn := &connection{
-			client: ch,
-			ws:     ws,
-		}
-
-		// Start listening for websocket messages from client, returning when
-		// the connection is closed. The connection will be forcibly closed when
-		// sendLoop returns if it is still opened.
-		conn.Add(1)
-		go psh.receiveLoop(conn)
+	// The receive loop will be sitting on ReadJSON, while the send loop will
+	// be waiting for signals from the WebSocketHub. One must close the other
+	// depending on whether the connection was closed/lost, or the WebSocketHub
+	// quit or forcibly unregistered the client. The receive loop unregisters
+	// the client (thus closing the update signal channel) when the connection
+	// is closed and it returns. The send loop closes the websocket.Conn on
+	// return, which will interrupt the receive loop from its waiting to
+	// receive data on the connection.
+
+	conn := &connection{
+		client: ch,
+		ws:     ws,
+	}
 
-		// Send loop (ping, new tx, block, etc. update loop). sendLoop returns
-		// when the client's signaling channel, conn.ch.cl.c, is closed.
-		conn.Add(1)
-		go psh.sendLoop(conn)
+	// Start listening for websocket messages from client, returning when the
+	// connection is closed. The connection will be forcibly closed when
+	// sendLoop returns if it is still opened.
+	conn.Add(1)
+	go psh.receiveLoop(conn)
 
-		// Hang out until the send and receive loops have quit.
-		conn.Wait()
+	// Send loop (ping, new tx, block, etc. update loop). sendLoop returns when
+	// the client's signaling channel, conn.ch.cl.c, is closed.
+	conn.Add(1)
+	go psh.sendLoop(conn)
 
-		// Clean up the client's subscriptions.
-		ch.cl.unsubscribeAll()
-	})
+	// Hang out until the send and receive loops have quit.
+	conn.Wait()
 
-	// Use a websocket.Server to avoid checking Origin.
-	wsServer := websocket.Server{
-		Handler: wsHandler,
-	}
-	wsServer.ServeHTTP(w, r)
+	// Clean up the client's subscriptions.
+	ch.cl.unsubscribeAll()
 }
 
 // StoreMPData stores mempool data. It is advisable to pass a copy of the
@@ -604,6 +820,20 @@ func (psh *PubSubHub) StoreMPData(_ *mempool.StakeData, _ []exptypes.MempoolTx,
 	psh.invs = inv
 	psh.invsMtx.Unlock()
 	log.Debugf("Updated mempool details for the pubsubhub.")
+
+	// Update the ticket price projection if the number of tickets in mempool
+	// changed since the last projection.
+	sdiff := psh.StakeDiffProjection()
+	if sdiff == nil || inv == nil {
+		return
+	}
+	inv.RLock()
+	mempoolTickets := uint32(inv.NumTickets)
+	inv.RUnlock()
+	if mempoolTickets != sdiff.MempoolTickets {
+		psh.updateStakeDiffProjection(sdiff.Height, sdiff.IdxBlockInWindow,
+			sdiff.Current, mempoolTickets)
+	}
 }
 
 // Store processes and stores new block data, then signals to the WebSocketHub
@@ -705,6 +935,17 @@ func (psh *PubSubHub) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBl
 
 	log.Debugf("Got new block %d for the pubsubhub.", newBlockData.Height)
 
+	// Project the next window's ticket price for the new block, with the
+	// tickets in mempool that have not been mined.
+	var mempoolTickets uint32
+	if inv := psh.MempoolInventory(); inv != nil {
+		inv.RLock()
+		mempoolTickets = uint32(inv.NumTickets)
+		inv.RUnlock()
+	}
+	psh.updateStakeDiffProjection(newBlockData.Height, blockData.IdxBlockInWindow,
+		blockData.CurrentStakeDiff.CurrentStakeDifficulty, mempoolTickets)
+
 	// Since the coinbase transaction is generated by the miner, it will never
 	// hit mempool. It must be processed now, with the new block.
 	coinbaseTx := msgBlock.Transactions[0]
@@ -0,0 +1,123 @@
+// Copyright (c) 2019, The Decred-Next developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"context"
+	"math"
+	"time"
+
+	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
+)
+
+const (
+	// syncProgressInterval is the time between samples of the DB and node
+	// heights.
+	syncProgressInterval = 5 * time.Second
+	// syncRateWindow is the time over which the sync rate is measured.
+	syncRateWindow = time.Minute
+)
+
+type heightSample struct {
+	time   time.Time
+	height int64
+}
+
+// syncRateTracker computes the sync progress from recent samples of the DB
+// height.
+type syncRateTracker struct {
+	samples []heightSample
+}
+
+// progress records the sample of the DB and node heights, and computes the
+// sync progress using the samples within syncRateWindow of it.
+func (t *syncRateTracker) progress(now time.Time, dbHeight, nodeHeight int64) *pstypes.SyncProgress {
+	// Drop the samples outside of the window, and any from before a reorg or a
+	// DB rewind.
+	cutoff := now.Add(-syncRateWindow)
+	var keep int
+	for i, s := range t.samples {
+		if s.time.Before(cutoff) || s.height > dbHeight {
+			keep = i + 1
+		}
+	}
+	t.samples = append(t.samples[keep:], heightSample{now, dbHeight})
+
+	sp := &pstypes.SyncProgress{
+		Time:       now.Unix(),
+		DBHeight:   dbHeight,
+		NodeHeight: nodeHeight,
+		ETA:        -1,
+		Synced:     dbHeight >= nodeHeight,
+	}
+
+	if sp.Synced {
+		sp.ETA = 0
+	}
+
+	first := t.samples[0]
+	if elapsed := now.Sub(first.time).Seconds(); elapsed > 0 {
+		sp.BlocksPerSec = float64(dbHeight-first.height) / elapsed
+	}
+	if !sp.Synced && sp.BlocksPerSec > 0 {
+		sp.ETA = int64(math.Ceil(float64(nodeHeight-dbHeight) / sp.BlocksPerSec))
+	}
+	return sp
+}
+
+// SyncProgress safely retrieves the last sync progress. This is nil until
+// MonitorSyncProgress takes the first sample.
+func (psh *PubSubHub) SyncProgress() *pstypes.SyncProgress {
+	psh.syncMtx.RLock()
+	defer psh.syncMtx.RUnlock()
+	return psh.syncProg
+}
+
+// MonitorSyncProgress samples the DB and node heights with the heights
+// function until the context is cancelled. The sync progress is updated with
+// each sample, and the "syncstatus" subscribers are signaled when the heights
+// change. This should be run as a goroutine.
+func (psh *PubSubHub) MonitorSyncProgress(ctx context.Context,
+	heights func() (dbHeight, nodeHeight int64, err error)) {
+	var tracker syncRateTracker
+	var last *pstypes.SyncProgress
+
+	update := func() {
+		dbHeight, nodeHeight, err := heights()
+		if err != nil {
+			log.Warnf("Unable to sample the sync progress: %v", err)
+			return
+		}
+		sp := tracker.progress(time.Now(), dbHeight, nodeHeight)
+
+		psh.syncMtx.Lock()
+		psh.syncProg = sp
+		psh.syncMtx.Unlock()
+
+		if last != nil && last.DBHeight == sp.DBHeight && last.NodeHeight == sp.NodeHeight {
+			return
+		}
+		last = sp
+
+		select {
+		case psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigSyncProgress, Msg: sp}:
+		case <-time.After(time.Second * 10):
+			log.Errorf("sigSyncProgress send failed: Timeout waiting for WebsocketHub.")
+		case <-ctx.Done():
+		}
+	}
+
+	ticker := time.NewTicker(syncProgressInterval)
+	defer ticker.Stop()
+
+	update()
+	for {
+		select {
+		case <-ticker.C:
+			update()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
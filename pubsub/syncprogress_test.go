@@ -0,0 +1,51 @@
+// Copyright (c) 2019, The Decred-Next developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_syncRateTracker_progress(t *testing.T) {
+	var tracker syncRateTracker
+	start := time.Unix(1500000000, 0)
+
+	// A single sample has no rate.
+	sp := tracker.progress(start, 1000, 2000)
+	if sp.BlocksPerSec != 0 || sp.ETA != -1 || sp.Synced {
+		t.Errorf("first sample: got rate %f, ETA %d, synced %v", sp.BlocksPerSec, sp.ETA, sp.Synced)
+	}
+
+	// 100 blocks in 10 seconds, with 900 remaining.
+	sp = tracker.progress(start.Add(10*time.Second), 1100, 2000)
+	if sp.BlocksPerSec != 10 {
+		t.Errorf("expected 10 blocks/s, got %f", sp.BlocksPerSec)
+	}
+	if sp.ETA != 90 {
+		t.Errorf("expected ETA of 90 s, got %d", sp.ETA)
+	}
+
+	// The first samples are dropped from the window a minute later, leaving
+	// 600 blocks in 60 seconds.
+	tracker.progress(start.Add(40*time.Second), 1400, 2000)
+	sp = tracker.progress(start.Add(100*time.Second), 1700, 2000)
+	if sp.BlocksPerSec != 5 {
+		t.Errorf("expected 5 blocks/s, got %f", sp.BlocksPerSec)
+	}
+	if len(tracker.samples) != 2 {
+		t.Errorf("expected 2 samples in the window, got %d", len(tracker.samples))
+	}
+
+	// Samples above a lower DB height, as after a rewind, are dropped.
+	sp = tracker.progress(start.Add(105*time.Second), 1500, 2000)
+	if sp.BlocksPerSec != 0 || sp.ETA != -1 {
+		t.Errorf("after rewind: got rate %f, ETA %d", sp.BlocksPerSec, sp.ETA)
+	}
+
+	sp = tracker.progress(start.Add(110*time.Second), 2000, 2000)
+	if !sp.Synced || sp.ETA != 0 {
+		t.Errorf("expected synced with ETA 0, got synced %v, ETA %d", sp.Synced, sp.ETA)
+	}
+}
@@ -84,6 +84,87 @@ func (am AddressMessage) String() string {
 
 type TxList []*exptypes.MempoolTx
 
+// StakeDiffProjection is the projected ticket price of the next stake
+// difficulty window, sent to "stakediff" subscribers. Projected is the
+// estimate assuming the tickets currently in mempool are mined in this window.
+type StakeDiffProjection struct {
+	Height           int64   `json:"height"`
+	IdxBlockInWindow int     `json:"window_block_index"`
+	WindowSize       int64   `json:"window_size"`
+	Current          float64 `json:"current"`
+	Min              float64 `json:"min"`
+	Max              float64 `json:"max"`
+	Expected         float64 `json:"expected"`
+	MempoolTickets   uint32  `json:"mempool_tickets"`
+	Projected        float64 `json:"projected"`
+}
+
+// VSPStatus is the status of a voting service provider (VSP), sent to
+// "vspstatus" subscribers when a VSP becomes reachable or unreachable, opens
+// or closes, or changes its vspd version or fee.
+type VSPStatus struct {
+	VSP           string  `json:"vsp"`
+	Time          int64   `json:"time"`
+	Reachable     bool    `json:"reachable"`
+	Closed        bool    `json:"closed"`
+	VspdVersion   string  `json:"vspd_version"`
+	FeePercentage float64 `json:"fee_percentage"`
+	Live          int64   `json:"live"`
+	Voted         int64   `json:"voted"`
+	Missed        int64   `json:"missed"`
+}
+
+// String satisfies the Stringer interface.
+func (vs VSPStatus) String() string {
+	return fmt.Sprintf("VSPStatus{VSP: %s, Reachable: %v, Closed: %v}",
+		vs.VSP, vs.Reachable, vs.Closed)
+}
+
+// SyncProgress is the progress of the DB sync with the node, sent to
+// "syncstatus" subscribers when it changes. BlocksPerSec is the rate over the
+// last minute, and ETA is the estimated number of seconds to reach the node's
+// best block at that rate, or -1 if it cannot be estimated.
+type SyncProgress struct {
+	Time         int64   `json:"time"`
+	DBHeight     int64   `json:"db_height"`
+	NodeHeight   int64   `json:"node_height"`
+	BlocksPerSec float64 `json:"blocks_per_sec"`
+	ETA          int64   `json:"eta"`
+	Synced       bool    `json:"synced"`
+}
+
+// String satisfies the Stringer interface.
+func (sp SyncProgress) String() string {
+	return fmt.Sprintf("SyncProgress{DBHeight: %d, NodeHeight: %d, ETA: %d}",
+		sp.DBHeight, sp.NodeHeight, sp.ETA)
+}
+
+// DepthChart is the consolidated orderbook of the Decred exchanges, sent to
+// "depthchart" subscribers when the websocket-synced orderbook of an exchange
+// changes. Tokens are the exchanges with new depth data. The order prices are
+// in BTC, and the quantities are summed across the exchanges. Price is the DCR
+// price in the BtcIndex currency.
+type DepthChart struct {
+	Time     int64        `json:"time"`
+	Tokens   []string     `json:"tokens"`
+	BtcIndex string       `json:"btc_index,omitempty"`
+	Price    float64      `json:"price,omitempty"`
+	Bids     []DepthPoint `json:"bids,omitempty"`
+	Asks     []DepthPoint `json:"asks,omitempty"`
+}
+
+// DepthPoint is the total quantity of the orders at a price.
+type DepthPoint struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// String satisfies the Stringer interface.
+func (dc DepthChart) String() string {
+	return fmt.Sprintf("DepthChart{Tokens: %v, Bids: %d, Asks: %d}",
+		dc.Tokens, len(dc.Bids), len(dc.Asks))
+}
+
 type HangUp struct{}
 
 type HubSignal int
@@ -104,6 +185,10 @@ const (
 	SigNewTxs
 	SigAddressTx
 	SigSyncStatus
+	SigStakeDiff
+	SigVSPStatus
+	SigSyncProgress
+	SigDepthChart
 	SigByeNow
 	SigUnknown
 )
@@ -115,6 +200,10 @@ var Subscriptions = map[string]HubSignal{
 	"newtxs":         SigNewTxs,
 	"address":        SigAddressTx,
 	"blockchainSync": SigSyncStatus,
+	"stakediff":      SigStakeDiff,
+	"vspstatus":      SigVSPStatus,
+	"syncstatus":     SigSyncProgress,
+	"depthchart":     SigDepthChart,
 }
 
 // Event type field for an event.
@@ -132,6 +221,10 @@ var eventIDs = map[HubSignal]string{
 	SigNewTxs:           "newtxs",
 	SigAddressTx:        "address",
 	SigSyncStatus:       "blockchainSync",
+	SigStakeDiff:        "stakediff",
+	SigVSPStatus:        "vspstatus",
+	SigSyncProgress:     "syncstatus",
+	SigDepthChart:       "depthchart",
 	SigByeNow:           "bye",
 	SigUnknown:          "unknown",
 }
@@ -202,6 +295,12 @@ func (m HubMessage) IsValid() bool {
 		_, ok = m.Msg.(*exptypes.MempoolTx)
 	case SigNewTxs:
 		_, ok = m.Msg.([]*exptypes.MempoolTx)
+	case SigVSPStatus:
+		_, ok = m.Msg.(*VSPStatus)
+	case SigSyncProgress:
+		_, ok = m.Msg.(*SyncProgress)
+	case SigDepthChart:
+		_, ok = m.Msg.(*DepthChart)
 	}
 
 	return ok
@@ -224,6 +323,15 @@ func (m HubMessage) String() string {
 	case SigNewTxs:
 		txs := m.Msg.([]*exptypes.MempoolTx)
 		sigStr += ":len=" + strconv.Itoa(len(txs))
+	case SigVSPStatus:
+		vs := m.Msg.(*VSPStatus)
+		sigStr += ":" + vs.VSP
+	case SigSyncProgress:
+		sp := m.Msg.(*SyncProgress)
+		sigStr += ":" + strconv.FormatInt(sp.DBHeight, 10)
+	case SigDepthChart:
+		dc := m.Msg.(*DepthChart)
+		sigStr += ":" + strings.Join(dc.Tokens, ",")
 	}
 
 	return sigStr
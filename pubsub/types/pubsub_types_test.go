@@ -14,6 +14,9 @@ func TestHubSignal_String(t *testing.T) {
 	}{
 		{"ok", SigNewTx, "newtx"},
 		{"ok", SigNewTxs, "newtxs"},
+		{"ok", SigStakeDiff, "stakediff"},
+		{"ok", SigVSPStatus, "vspstatus"},
+		{"ok", SigDepthChart, "depthchart"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -56,6 +59,11 @@ func TestHubMessage_String(t *testing.T) {
 			HubMessage{Signal: SigNewTxs, Msg: []*exptypes.MempoolTx{{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}}},
 			"newtxs:len=1",
 		},
+		{
+			"ok depthchart",
+			HubMessage{Signal: SigDepthChart, Msg: &DepthChart{Tokens: []string{"binance", "bittrex"}}},
+			"depthchart:binance,bittrex",
+		},
 		{
 			"wrong Msg type newtx",
 			HubMessage{Signal: SigNewTx, Msg: exptypes.MempoolTx{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}},
@@ -30,6 +30,14 @@ const (
 	bufferTickerInterval = 3
 
 	maxPayloadBytes = 1 << 20
+
+	// DefaultBatchInterval is the interval at which the new transactions are
+	// sent to clients that opt in to batching without specifying an interval.
+	DefaultBatchInterval = 250 * time.Millisecond
+	// minBatchInterval and maxBatchInterval bound the batch interval requested
+	// by a client.
+	minBatchInterval = 50 * time.Millisecond
+	maxBatchInterval = bufferTickerInterval * time.Second
 )
 
 // Type aliases for the different HubSignals.
@@ -45,6 +53,10 @@ var (
 	sigNewTxs           = pstypes.SigNewTxs
 	sigAddressTx        = pstypes.SigAddressTx
 	sigSyncStatus       = pstypes.SigSyncStatus
+	sigStakeDiff        = pstypes.SigStakeDiff
+	sigVSPStatus        = pstypes.SigVSPStatus
+	sigSyncProgress     = pstypes.SigSyncProgress
+	sigDepthChart       = pstypes.SigDepthChart
 	sigByeNow           = pstypes.SigByeNow
 )
 
@@ -106,6 +118,24 @@ func (wsh *WebsocketHub) SetReady(ready bool) {
 	wsh.ready.Store(ready)
 }
 
+func (tl *txList) reset() {
+	tl.Lock()
+	tl.t = make(pstypes.TxList, 0, NewTxBufferSize)
+	tl.Unlock()
+}
+
+// clientOptions are the per-client transport options requested by a client
+// when it connects.
+type clientOptions struct {
+	// compress enables permessage-deflate compression of the messages sent to
+	// the client, if the client negotiated the extension.
+	compress bool
+	// batch is the interval at which the client's new transactions are sent.
+	// When zero, they are sent when NewTxBufferSize transactions are buffered
+	// or every bufferTickerInterval seconds, whichever comes first.
+	batch time.Duration
+}
+
 type client struct {
 	mtx    sync.RWMutex
 	id     uint64
@@ -113,6 +143,7 @@ type client struct {
 	addrs  map[string]struct{}
 	killed chan struct{}
 	newTxs *txList
+	opts   clientOptions
 }
 
 func newClient() *client {
@@ -125,6 +156,12 @@ func newClient() *client {
 	}
 }
 
+// batching indicates if the client sends its own new transaction buffer at the
+// requested batch interval rather than when signaled by the WebsocketHub.
+func (c *client) batching() bool {
+	return c.opts.batch > 0
+}
+
 func (c *client) isSubscribed(msg pstypes.HubMessage) bool {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
@@ -234,9 +271,17 @@ func newClientID() uint64 {
 // to the new client data object. Use UnregisterClient on this object to stop
 // signaling messages, and close the signal channel.
 func (wsh *WebsocketHub) NewClientHubSpoke() *clientHubSpoke {
+	return wsh.newClientHubSpoke(clientOptions{})
+}
+
+// newClientHubSpoke is like NewClientHubSpoke, but sets the client's transport
+// options before the client is registered with the hub.
+func (wsh *WebsocketHub) newClientHubSpoke(opts clientOptions) *clientHubSpoke {
 	c := make(hubSpoke, 16)
+	cl := newClient()
+	cl.opts = opts
 	ch := &clientHubSpoke{
-		cl: newClient(),
+		cl: cl,
 		c:  &c,
 	}
 	wsh.Register <- ch
@@ -443,6 +488,29 @@ func (wsh *WebsocketHub) Run() {
 				continue // break events
 			case sigMempoolUpdate:
 				log.Infof("Signaling mempool inventory refresh to %d websocket clients.", clientsCount)
+			case sigStakeDiff:
+				log.Debugf("Signaling ticket price projection to %d websocket clients.", clientsCount)
+			case sigVSPStatus:
+				vs, ok := hubMsg.Msg.(*pstypes.VSPStatus)
+				if !ok || vs == nil {
+					log.Errorf("sigVSPStatus did not store a *VSPStatus in Msg.")
+					continue
+				}
+				log.Debugf("Signaling status of VSP %s to %d websocket clients.", vs.VSP, clientsCount)
+			case sigSyncProgress:
+				sp, ok := hubMsg.Msg.(*pstypes.SyncProgress)
+				if !ok || sp == nil {
+					log.Errorf("sigSyncProgress did not store a *SyncProgress in Msg.")
+					continue
+				}
+				log.Tracef("Signaling sync progress to %d websocket clients.", clientsCount)
+			case sigDepthChart:
+				dc, ok := hubMsg.Msg.(*pstypes.DepthChart)
+				if !ok || dc == nil {
+					log.Errorf("sigDepthChart did not store a *DepthChart in Msg.")
+					continue
+				}
+				log.Tracef("Signaling depth chart of %v to %d websocket clients.", dc.Tokens, clientsCount)
 			case sigAddressTx:
 				// AddressMessage already validated, but check again.
 				addrMsg, ok := hubMsg.Msg.(*pstypes.AddressMessage)
@@ -496,6 +564,11 @@ func (wsh *WebsocketHub) Run() {
 				}
 				log.Tracef("Client %d is subscribed to %s.", client.id, hubMsg)
 
+				// Batching clients send their tx buffers on their own.
+				if hubMsg.Signal == sigNewTxs && client.batching() {
+					continue
+				}
+
 				// Signal or unregister the client.
 				sendMsg(spoke, client, hubMsg)
 			}
@@ -536,10 +609,13 @@ func (wsh *WebsocketHub) maybeSendTxns(tx *exptypes.MempoolTx) (someReadyToSend
 }
 
 // addTxToBuffer adds a tx to each client's tx buffer. The return boolean value
-// indicates if at least one buffer is ready to be sent.
+// indicates if at least one buffer of a client that is not batching is ready to
+// be sent.
 func (wsh *WebsocketHub) addTxToBuffer(tx *exptypes.MempoolTx) (someReadyToSend bool) {
 	for _, client := range wsh.clients {
-		someReadyToSend = client.newTxs.addTxToBuffer(tx)
+		if client.newTxs.addTxToBuffer(tx) && !client.batching() {
+			someReadyToSend = true
+		}
 	}
 	return
 }
@@ -6,7 +6,9 @@ package pubsub
 
 import (
 	"errors"
+	"net/url"
 	"testing"
+	"time"
 
 	pstypes "github.com/decred/dcrdata/pubsub/types/v3"
 )
@@ -48,3 +50,34 @@ func Test_client_subscribe(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseClientOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    clientOptions
+		wantErr bool
+	}{
+		{"none", "", clientOptions{}, false},
+		{"compress", "compress=1", clientOptions{compress: true}, false},
+		{"bad compress", "compress=zip", clientOptions{}, true},
+		{"batch default", "batch=true", clientOptions{batch: DefaultBatchInterval}, false},
+		{"batch off", "batch=0", clientOptions{}, false},
+		{"batch ms", "compress=true&batch=500", clientOptions{true, 500 * time.Millisecond}, false},
+		{"batch min", "batch=10", clientOptions{batch: minBatchInterval}, false},
+		{"batch max", "batch=60000", clientOptions{batch: maxBatchInterval}, false},
+		{"bad batch", "batch=-5", clientOptions{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			opts, err := parseClientOptions(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseClientOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && opts != tt.want {
+				t.Errorf("parseClientOptions() = %+v, want %+v", opts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package rawpub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The NATS publisher implements the publishing subset of the NATS client
+// protocol. Messages are published on the subject NATSSubjectPrefix + topic.
+
+// NATSSubjectPrefix prefixes the topics of the NATS subjects.
+const NATSSubjectPrefix = "dcrdata."
+
+const (
+	natsDefaultPort  = "4222"
+	natsDialTimeout  = 5 * time.Second
+	natsWriteTimeout = 5 * time.Second
+)
+
+// natsConnect is the CONNECT message options.
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+// NATSPublisher publishes messages to a NATS server. If the connection is lost,
+// it is reestablished by the next Publish.
+type NATSPublisher struct {
+	addr    string
+	connect []byte
+
+	mtx    sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewNATSPublisher creates a NATSPublisher connected to the server at the URL,
+// e.g. nats://127.0.0.1:4222. Credentials may be given in the URL.
+func NewNATSPublisher(serverURL string) (*NATSPublisher, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS server URL %q", serverURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+
+	opts := natsConnect{Name: "dcrdata", Lang: "go"}
+	if u.User != nil {
+		opts.User = u.User.Username()
+		opts.Pass, _ = u.User.Password()
+	}
+	connect, err := json.Marshal(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &NATSPublisher{
+		addr:    addr,
+		connect: []byte("CONNECT " + string(connect) + "\r\n"),
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if err = p.dial(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// dial connects to the server and starts reading from the connection. The
+// mutex must be held.
+func (p *NATSPublisher) dial() error {
+	conn, err := net.DialTimeout("tcp", p.addr, natsDialTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(natsDialTimeout))
+	r := bufio.NewReader(conn)
+	info, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected message from NATS server: %q", strings.TrimSpace(info))
+	}
+	if _, err = conn.Write(p.connect); err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	p.conn = conn
+	go p.read(conn, r)
+	return nil
+}
+
+// read responds to the server's pings, and logs its errors, until the
+// connection is closed.
+func (p *NATSPublisher) read(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			p.mtx.Lock()
+			if p.conn == conn {
+				p.conn = nil
+			}
+			p.mtx.Unlock()
+			conn.Close()
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			p.mtx.Lock()
+			conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+			conn.Write([]byte("PONG\r\n"))
+			p.mtx.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			log.Errorf("NATS server error: %s", line)
+		}
+	}
+}
+
+// Publish publishes the payload on the topic's subject, reconnecting to the
+// server if needed.
+func (p *NATSPublisher) Publish(topic string, payload []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed {
+		return fmt.Errorf("publisher closed")
+	}
+	if p.conn == nil {
+		if err := p.dial(); err != nil {
+			return err
+		}
+	}
+
+	msg := make([]byte, 0, len(payload)+64)
+	msg = append(msg, fmt.Sprintf("PUB %s%s %d\r\n", NATSSubjectPrefix, topic, len(payload))...)
+	msg = append(msg, payload...)
+	msg = append(msg, "\r\n"...)
+	p.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+	if _, err := p.conn.Write(msg); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server.
+func (p *NATSPublisher) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.closed = true
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package rawpub
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNATSPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type received struct {
+		connect, pub string
+		payload      []byte
+		err          error
+	}
+	done := make(chan received, 1)
+	go func() {
+		var rcv received
+		defer func() { done <- rcv }()
+		conn, err := ln.Accept()
+		if err != nil {
+			rcv.err = err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, rcv.err = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n")); rcv.err != nil {
+			return
+		}
+		r := bufio.NewReader(conn)
+		if rcv.connect, rcv.err = r.ReadString('\n'); rcv.err != nil {
+			return
+		}
+		if rcv.pub, rcv.err = r.ReadString('\n'); rcv.err != nil {
+			return
+		}
+		rcv.payload = make([]byte, 5)
+		_, rcv.err = io.ReadFull(r, rcv.payload)
+	}()
+
+	p, err := NewNATSPublisher("nats://user:pass@" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Publish(TopicRawTx, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	rcv := <-done
+	if rcv.err != nil {
+		t.Fatal(rcv.err)
+	}
+	if !strings.HasPrefix(rcv.connect, "CONNECT {") ||
+		!strings.Contains(rcv.connect, `"user":"user","pass":"pass"`) {
+		t.Errorf("unexpected CONNECT %q", rcv.connect)
+	}
+	if rcv.pub != "PUB dcrdata.rawtx 5\r\n" {
+		t.Errorf("unexpected PUB %q", rcv.pub)
+	}
+	if string(rcv.payload) != "hello" {
+		t.Errorf("unexpected payload %q", rcv.payload)
+	}
+}
+
+func TestNewNATSPublisherURL(t *testing.T) {
+	for _, u := range []string{"", "tcp://127.0.0.1:4222", "nats://"} {
+		if _, err := NewNATSPublisher(u); err == nil {
+			t.Errorf("expected error for URL %q", u)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package rawpub publishes the raw blocks, raw mempool transactions, and stake
+// events processed by dcrdata on a ZeroMQ PUB socket or to a NATS server, so
+// that consumers in any language may integrate without websockets or polling.
+package rawpub
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/txhelpers/v4"
+)
+
+// Topics of the published messages.
+const (
+	// TopicRawBlock messages are serialized blocks.
+	TopicRawBlock = "rawblock"
+	// TopicRawTx messages are serialized mempool transactions.
+	TopicRawTx = "rawtx"
+	// TopicStakeEvent messages are the JSON encoded StakeEvent of each block.
+	TopicStakeEvent = "stakeevent"
+)
+
+// Transports of a Publisher.
+const (
+	TransportZMQ  = "zmq"
+	TransportNATS = "nats"
+)
+
+// Transport publishes messages on a topic.
+type Transport interface {
+	Publish(topic string, payload []byte) error
+	Close() error
+}
+
+// StakeEvent is the stake activity of a block: the votes, ticket purchases
+// and revocations it includes, and the resulting ticket price and pool size.
+type StakeEvent struct {
+	Height      uint32   `json:"height"`
+	Hash        string   `json:"hash"`
+	Votes       []string `json:"votes"`
+	Tickets     []string `json:"tickets"`
+	Revocations []string `json:"revocations"`
+	TicketPrice float64  `json:"ticket_price"`
+	PoolSize    uint32   `json:"pool_size"`
+}
+
+// Publisher publishes the events processed by dcrdata with a Transport.
+type Publisher struct {
+	transport Transport
+}
+
+// NewPublisher creates a Publisher with the given transport, TransportZMQ or
+// TransportNATS. For ZMQ, address is the endpoint on which the PUB socket
+// listens (e.g. tcp://127.0.0.1:28900). For NATS, address is the URL of the
+// server (e.g. nats://127.0.0.1:4222).
+func NewPublisher(transport, address string) (*Publisher, error) {
+	var t Transport
+	var err error
+	switch transport {
+	case TransportZMQ:
+		t, err = NewZMQPublisher(address)
+	case TransportNATS:
+		t, err = NewNATSPublisher(address)
+	default:
+		return nil, fmt.Errorf("unknown transport %q", transport)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{transport: t}, nil
+}
+
+// Close closes the transport.
+func (p *Publisher) Close() error {
+	return p.transport.Close()
+}
+
+// Store publishes the serialized block on the rawblock topic and the block's
+// StakeEvent on the stakeevent topic. Store satisfies blockdata.BlockDataSaver.
+func (p *Publisher) Store(blockData *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	var buf bytes.Buffer
+	buf.Grow(msgBlock.SerializeSize())
+	if err := msgBlock.Serialize(&buf); err != nil {
+		return err
+	}
+	if err := p.transport.Publish(TopicRawBlock, buf.Bytes()); err != nil {
+		log.Warnf("Failed to publish %s: %v", TopicRawBlock, err)
+	}
+
+	event := &StakeEvent{
+		Height:      msgBlock.Header.Height,
+		Hash:        msgBlock.BlockHash().String(),
+		Votes:       []string{},
+		Tickets:     []string{},
+		Revocations: []string{},
+	}
+	for _, stx := range msgBlock.STransactions {
+		txid := stx.TxHash().String()
+		switch txhelpers.DetermineTxTypeString(stx) {
+		case "Vote":
+			event.Votes = append(event.Votes, txid)
+		case "Ticket":
+			event.Tickets = append(event.Tickets, txid)
+		case "Revocation":
+			event.Revocations = append(event.Revocations, txid)
+		}
+	}
+	if blockData != nil {
+		event.TicketPrice = blockData.CurrentStakeDiff.CurrentStakeDifficulty
+		if blockData.PoolInfo != nil {
+			event.PoolSize = blockData.PoolInfo.Size
+		}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err = p.transport.Publish(TopicStakeEvent, payload); err != nil {
+		log.Warnf("Failed to publish %s: %v", TopicStakeEvent, err)
+	}
+	return nil
+}
+
+// TxHandler publishes a new mempool transaction on the rawtx topic. TxHandler
+// satisfies notification.TxHandler.
+func (p *Publisher) TxHandler(rawTx *chainjson.TxRawResult) error {
+	serialized, err := hex.DecodeString(rawTx.Hex)
+	if err != nil {
+		return fmt.Errorf("failed to decode transaction %s: %v", rawTx.Txid, err)
+	}
+	if err = p.transport.Publish(TopicRawTx, serialized); err != nil {
+		log.Warnf("Failed to publish %s: %v", TopicRawTx, err)
+	}
+	return nil
+}
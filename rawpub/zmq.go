@@ -0,0 +1,295 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package rawpub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The ZMQ publisher implements the PUB side of ZMTP 3.0 with the NULL security
+// mechanism, which is all that is needed for libzmq SUB sockets to connect and
+// subscribe. As with dcrd and bitcoind, each message has three frames: the
+// topic, the payload, and the topic's 4-byte little endian sequence number.
+
+const (
+	zmqHandshakeTimeout = 5 * time.Second
+	zmqWriteTimeout     = 5 * time.Second
+
+	// Frame flags.
+	zmqFlagMore    = 0x01
+	zmqFlagLong    = 0x02
+	zmqFlagCommand = 0x04
+)
+
+// zmqGreeting is the ZMTP 3.0 greeting of a server with the NULL mechanism.
+var zmqGreeting = func() []byte {
+	g := make([]byte, 64)
+	g[0], g[9] = 0xff, 0x7f // signature
+	g[10], g[11] = 3, 0     // version 3.0
+	copy(g[12:32], "NULL")  // mechanism
+	g[32] = 0               // as-server
+	return g
+}()
+
+// ZMQPublisher is a ZeroMQ PUB socket accepting connections from SUB sockets.
+type ZMQPublisher struct {
+	ln net.Listener
+
+	mtx  sync.Mutex
+	subs map[*zmqSubscriber]struct{}
+	seq  map[string]uint32
+}
+
+// zmqSubscriber is a connected SUB socket and its topic subscriptions.
+type zmqSubscriber struct {
+	conn net.Conn
+	// writeMtx prevents the messages of concurrent publishes from interleaving.
+	writeMtx sync.Mutex
+
+	mtx      sync.Mutex
+	prefixes [][]byte
+}
+
+// NewZMQPublisher creates a ZMQPublisher listening on the TCP endpoint, e.g.
+// tcp://127.0.0.1:28900.
+func NewZMQPublisher(endpoint string) (*ZMQPublisher, error) {
+	addr := strings.TrimPrefix(endpoint, "tcp://")
+	if addr == endpoint {
+		return nil, fmt.Errorf("unsupported ZMQ endpoint %q, expected tcp://host:port", endpoint)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &ZMQPublisher{
+		ln:   ln,
+		subs: make(map[*zmqSubscriber]struct{}),
+		seq:  make(map[string]uint32),
+	}
+	go p.accept()
+	return p, nil
+}
+
+// Addr is the address on which the publisher is listening.
+func (p *ZMQPublisher) Addr() net.Addr {
+	return p.ln.Addr()
+}
+
+// accept accepts connections until the listener is closed.
+func (p *ZMQPublisher) accept() {
+	for {
+		conn, err := p.ln.Accept()
+		if err != nil {
+			return
+		}
+		go p.serve(conn)
+	}
+}
+
+// serve completes the handshake with a SUB socket, and then reads its
+// subscriptions until the connection is closed.
+func (p *ZMQPublisher) serve(conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(zmqHandshakeTimeout))
+	if err := zmqHandshake(conn); err != nil {
+		log.Debugf("ZMQ handshake with %v failed: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	sub := &zmqSubscriber{conn: conn}
+	p.mtx.Lock()
+	p.subs[sub] = struct{}{}
+	p.mtx.Unlock()
+	log.Debugf("ZMQ subscriber connected from %v", conn.RemoteAddr())
+
+	defer p.remove(sub)
+	for {
+		flags, body, err := zmqReadFrame(conn)
+		if err != nil {
+			return
+		}
+		// A ZMTP 3.0 subscription is a message whose first byte is 1 to
+		// subscribe or 0 to unsubscribe, followed by the topic prefix.
+		if flags&zmqFlagCommand != 0 || len(body) == 0 {
+			continue
+		}
+		sub.update(body[0] == 1, body[1:])
+	}
+}
+
+// remove closes the subscriber's connection and stops publishing to it.
+func (p *ZMQPublisher) remove(sub *zmqSubscriber) {
+	p.mtx.Lock()
+	delete(p.subs, sub)
+	p.mtx.Unlock()
+	sub.conn.Close()
+}
+
+// update adds or removes a subscription to the topic prefix.
+func (s *zmqSubscriber) update(subscribe bool, prefix []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if subscribe {
+		s.prefixes = append(s.prefixes, append([]byte(nil), prefix...))
+		return
+	}
+	for i := range s.prefixes {
+		if bytes.Equal(s.prefixes[i], prefix) {
+			s.prefixes = append(s.prefixes[:i], s.prefixes[i+1:]...)
+			return
+		}
+	}
+}
+
+// subscribed checks if the subscriber is subscribed to the topic.
+func (s *zmqSubscriber) subscribed(topic string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(topic, string(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish sends the message to the subscribers of the topic. Subscribers that
+// cannot keep up are disconnected.
+func (p *ZMQPublisher) Publish(topic string, payload []byte) error {
+	p.mtx.Lock()
+	seq := p.seq[topic]
+	p.seq[topic] = seq + 1
+	subs := make([]*zmqSubscriber, 0, len(p.subs))
+	for sub := range p.subs {
+		if sub.subscribed(topic) {
+			subs = append(subs, sub)
+		}
+	}
+	p.mtx.Unlock()
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	var seqBytes [4]byte
+	binary.LittleEndian.PutUint32(seqBytes[:], seq)
+	var msg bytes.Buffer
+	zmqWriteFrame(&msg, zmqFlagMore, []byte(topic))
+	zmqWriteFrame(&msg, zmqFlagMore, payload)
+	zmqWriteFrame(&msg, 0, seqBytes[:])
+
+	for _, sub := range subs {
+		sub.writeMtx.Lock()
+		sub.conn.SetWriteDeadline(time.Now().Add(zmqWriteTimeout))
+		_, err := sub.conn.Write(msg.Bytes())
+		sub.writeMtx.Unlock()
+		if err != nil {
+			log.Debugf("Dropping ZMQ subscriber %v: %v", sub.conn.RemoteAddr(), err)
+			p.remove(sub)
+		}
+	}
+	return nil
+}
+
+// Close stops listening and disconnects the subscribers.
+func (p *ZMQPublisher) Close() error {
+	err := p.ln.Close()
+	p.mtx.Lock()
+	for sub := range p.subs {
+		sub.conn.Close()
+	}
+	p.mtx.Unlock()
+	return err
+}
+
+// zmqHandshake exchanges greetings and READY commands with the peer.
+func zmqHandshake(conn net.Conn) error {
+	if _, err := conn.Write(zmqGreeting); err != nil {
+		return err
+	}
+	greeting := make([]byte, 64)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return err
+	}
+	if greeting[0] != 0xff || greeting[9] != 0x7f || greeting[10] < 3 {
+		return fmt.Errorf("unsupported greeting")
+	}
+	if mech := string(bytes.TrimRight(greeting[12:32], "\x00")); mech != "NULL" {
+		return fmt.Errorf("unsupported security mechanism %q", mech)
+	}
+
+	var ready bytes.Buffer
+	ready.WriteByte(5)
+	ready.WriteString("READY")
+	ready.WriteByte(byte(len("Socket-Type")))
+	ready.WriteString("Socket-Type")
+	binary.Write(&ready, binary.BigEndian, uint32(len("PUB")))
+	ready.WriteString("PUB")
+	var frame bytes.Buffer
+	zmqWriteFrame(&frame, zmqFlagCommand, ready.Bytes())
+	if _, err := conn.Write(frame.Bytes()); err != nil {
+		return err
+	}
+
+	flags, body, err := zmqReadFrame(conn)
+	if err != nil {
+		return err
+	}
+	if flags&zmqFlagCommand == 0 || !bytes.HasPrefix(body, []byte("\x05READY")) {
+		return fmt.Errorf("expected READY command")
+	}
+	return nil
+}
+
+// zmqWriteFrame writes a frame with the flags and body.
+func zmqWriteFrame(w *bytes.Buffer, flags byte, body []byte) {
+	if len(body) > 255 {
+		w.WriteByte(flags | zmqFlagLong)
+		binary.Write(w, binary.BigEndian, uint64(len(body)))
+	} else {
+		w.WriteByte(flags)
+		w.WriteByte(byte(len(body)))
+	}
+	w.Write(body)
+}
+
+// zmqMaxFrameSize limits the size of the frames read from subscribers, which
+// only send commands and subscriptions.
+const zmqMaxFrameSize = 1 << 16
+
+// zmqReadFrame reads a frame, returning its flags and body.
+func zmqReadFrame(r io.Reader) (byte, []byte, error) {
+	var hdr [1]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	flags := hdr[0]
+	var size uint64
+	if flags&zmqFlagLong != 0 {
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(hdr[0])
+	}
+	if size > zmqMaxFrameSize {
+		return 0, nil, fmt.Errorf("frame size %d too large", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package rawpub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// dialSub connects a minimal SUB socket to the publisher and subscribes to the
+// topic.
+func dialSub(t *testing.T, p *ZMQPublisher, topic string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", p.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err = conn.Write(zmqGreeting); err != nil {
+		t.Fatal(err)
+	}
+	greeting := make([]byte, 64)
+	if _, err = conn.Read(greeting); err != nil {
+		t.Fatal(err)
+	}
+
+	var ready bytes.Buffer
+	ready.WriteByte(5)
+	ready.WriteString("READY")
+	ready.WriteByte(byte(len("Socket-Type")))
+	ready.WriteString("Socket-Type")
+	binary.Write(&ready, binary.BigEndian, uint32(len("SUB")))
+	ready.WriteString("SUB")
+	var frames bytes.Buffer
+	zmqWriteFrame(&frames, zmqFlagCommand, ready.Bytes())
+	zmqWriteFrame(&frames, 0, append([]byte{1}, topic...))
+	if _, err = conn.Write(frames.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, body, err := zmqReadFrame(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags&zmqFlagCommand == 0 || !bytes.Contains(body, []byte("PUB")) {
+		t.Fatalf("unexpected READY command %q", body)
+	}
+	return conn
+}
+
+// waitSubscribed waits for the publisher to process a subscription to the
+// topic.
+func waitSubscribed(t *testing.T, p *ZMQPublisher, topic string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mtx.Lock()
+		for sub := range p.subs {
+			if sub.subscribed(topic) {
+				p.mtx.Unlock()
+				return
+			}
+		}
+		p.mtx.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("subscription to %s not received", topic)
+}
+
+func TestZMQPublish(t *testing.T) {
+	p, err := NewZMQPublisher("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	conn := dialSub(t, p, TopicRawTx)
+	defer conn.Close()
+	waitSubscribed(t, p, TopicRawTx)
+
+	// Not subscribed, so the first message received is the rawtx.
+	if err = p.Publish(TopicRawBlock, []byte{0xaa}); err != nil {
+		t.Fatal(err)
+	}
+	payload := bytes.Repeat([]byte{0xbb}, 300) // long frame
+	for i := 0; i < 2; i++ {
+		if err = p.Publish(TopicRawTx, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for seq := uint32(0); seq < 2; seq++ {
+		want := []struct {
+			flags byte
+			body  []byte
+		}{
+			{zmqFlagMore, []byte(TopicRawTx)},
+			{zmqFlagMore | zmqFlagLong, payload},
+			{0, []byte{byte(seq), 0, 0, 0}},
+		}
+		for i, w := range want {
+			flags, body, err := zmqReadFrame(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if flags != w.flags || !bytes.Equal(body, w.body) {
+				t.Errorf("message %d frame %d: flags %x body %x, expected flags %x body %x",
+					seq, i, flags, body, w.flags, w.body)
+			}
+		}
+	}
+}
+
+func TestNewZMQPublisherEndpoint(t *testing.T) {
+	if _, err := NewZMQPublisher("ipc:///tmp/dcrdata"); err == nil {
+		t.Error("expected error for non-TCP endpoint")
+	}
+}
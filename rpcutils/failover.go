@@ -0,0 +1,420 @@
+// Copyright (c) 2019, The Decred-Next developers
+// See LICENSE for details.
+
+package rpcutils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/rpcclient/v5"
+	"github.com/decred/dcrdata/semver"
+)
+
+const (
+	// nodeHealthCheckInterval is how often the active node is probed, along
+	// with the primary node if the active node is a fallback.
+	nodeHealthCheckInterval = 10 * time.Second
+
+	// nodeDialTimeout limits the time taken to connect to a node, including
+	// the TLS handshake.
+	nodeDialTimeout = 5 * time.Second
+)
+
+// NodeEndpoint is a dcrd RPC server. Cert is the path to the server's RPC
+// certificate, which is not used when TLS is disabled.
+type NodeEndpoint struct {
+	Host string
+	Cert string
+}
+
+// NodeClient is a dcrd RPC websocket client that fails over between several
+// nodes. The embedded *rpcclient.Client is connected to a local proxy that
+// forwards the connection to the first reachable node, in order of preference.
+// When the active node becomes unreachable, the proxy drops the connection,
+// and the client's automatic reconnect lands on the next available node. The
+// client re-registers for its notifications after reconnecting, so the
+// notification handlers keep receiving notifications from the new node. The
+// client authenticates with the proxy using random credentials, which the
+// proxy replaces with the nodes' RPC credentials, so that other local
+// processes can not use the proxy, and the RPC credentials are only sent to
+// the nodes.
+type NodeClient struct {
+	*rpcclient.Client
+	proxy *failoverProxy
+	host  string // without a proxy
+}
+
+// ActiveNode returns the host of the node that the client is connected to. An
+// empty string is returned if the client is between nodes.
+func (nc *NodeClient) ActiveNode() string {
+	if nc.proxy == nil {
+		return nc.host
+	}
+	return nc.proxy.activeNode()
+}
+
+// Shutdown shuts down the RPC client, waiting for it to finish, and then the
+// failover proxy.
+func (nc *NodeClient) Shutdown() {
+	nc.Client.Shutdown()
+	nc.Client.WaitForShutdown()
+	if nc.proxy != nil {
+		nc.proxy.stop()
+	}
+}
+
+// ConnectNodeRPCFailover creates a websocket client for the first reachable
+// dcrd node in the list, failing over to the others as required. The nodes are
+// in order of preference, and the client fails back to the first node when it
+// is reachable again. All nodes must accept the same RPC credentials. With a
+// single node, there is nothing to fail over to, and the client is connected
+// directly with automatic reconnection disabled, as with ConnectNodeRPC.
+func ConnectNodeRPCFailover(nodes []NodeEndpoint, user, pass string, disableTLS bool,
+	ntfnHandlers ...*rpcclient.NotificationHandlers) (*NodeClient, semver.Semver, error) {
+	switch len(nodes) {
+	case 0:
+		return nil, semver.Semver{}, fmt.Errorf("no dcrd nodes specified")
+	case 1:
+		client, nodeVer, err := ConnectNodeRPC(nodes[0].Host, user, pass,
+			nodes[0].Cert, disableTLS, true, ntfnHandlers...)
+		if err != nil {
+			return nil, nodeVer, err
+		}
+		return &NodeClient{Client: client, host: nodes[0].Host}, nodeVer, nil
+	}
+
+	proxy, err := newFailoverProxy(nodes, user, pass, disableTLS)
+	if err != nil {
+		return nil, semver.Semver{}, err
+	}
+
+	// The proxy handles TLS with the nodes, so the client connects to it on
+	// the loopback interface without TLS. The client must reconnect to reach
+	// the next node after the proxy drops the connection to a failed node.
+	client, nodeVer, err := ConnectNodeRPC(proxy.addr(), proxy.user, proxy.pass,
+		"", true, false, ntfnHandlers...)
+	if err != nil {
+		proxy.stop()
+		return nil, nodeVer, err
+	}
+	return &NodeClient{Client: client, proxy: proxy}, nodeVer, nil
+}
+
+// nodeDialer connects to a node, with TLS unless tlsConfig is nil.
+type nodeDialer struct {
+	host      string
+	tlsConfig *tls.Config
+}
+
+func (nd *nodeDialer) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: nodeDialTimeout}
+	if nd.tlsConfig == nil {
+		return dialer.Dial("tcp", nd.host)
+	}
+	return tls.DialWithDialer(dialer, "tcp", nd.host, nd.tlsConfig)
+}
+
+// reachable checks that a connection to the node can be established.
+func (nd *nodeDialer) reachable() bool {
+	conn, err := nd.dial()
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// proxySession is a client connection and the node connection to which it is
+// forwarded.
+type proxySession struct {
+	client, node net.Conn
+	nodeIdx      int
+	once         sync.Once
+}
+
+func (s *proxySession) close() {
+	s.once.Do(func() {
+		s.client.Close()
+		s.node.Close()
+	})
+}
+
+// basicAuth is the value of the Authorization header of an HTTP request with
+// the basic authentication credentials.
+func basicAuth(user, pass string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+}
+
+// failoverProxy accepts connections on the loopback interface, forwarding each
+// to the first reachable node. Only one session is maintained at a time, since
+// the proxy serves a single websocket client. The client's websocket upgrade
+// request must be authenticated with the proxy's credentials, which are
+// replaced with the nodes' credentials when the request is forwarded.
+type failoverProxy struct {
+	listener net.Listener
+	nodes    []*nodeDialer
+
+	user, pass string // the credentials of the proxy's client
+	clientAuth string // the Authorization header of the client
+	nodeAuth   string // the Authorization header for the nodes
+
+	mtx     sync.Mutex
+	session *proxySession
+	lastIdx int
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newFailoverProxy(nodes []NodeEndpoint, user, pass string, disableTLS bool) (*failoverProxy, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate dcrd failover proxy credentials: %v", err)
+	}
+	p := &failoverProxy{
+		user:     "dcrdata",
+		pass:     hex.EncodeToString(secret),
+		nodeAuth: basicAuth(user, pass),
+		lastIdx:  -1,
+		quit:     make(chan struct{}),
+	}
+	p.clientAuth = basicAuth(p.user, p.pass)
+	for _, node := range nodes {
+		nd := &nodeDialer{host: node.Host}
+		if !disableTLS {
+			cert, err := ioutil.ReadFile(node.Cert)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read dcrd cert file at %s: %v",
+					node.Cert, err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(cert) {
+				return nil, fmt.Errorf("invalid dcrd cert file at %s", node.Cert)
+			}
+			nd.tlsConfig = &tls.Config{RootCAs: pool}
+		}
+		p.nodes = append(p.nodes, nd)
+	}
+
+	var err error
+	p.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start dcrd failover proxy: %v", err)
+	}
+
+	p.wg.Add(2)
+	go p.serve()
+	go p.monitor()
+	return p, nil
+}
+
+// addr is the address on which the proxy accepts connections.
+func (p *failoverProxy) addr() string {
+	return p.listener.Addr().String()
+}
+
+// activeNode returns the host of the node of the current session, if any.
+func (p *failoverProxy) activeNode() string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.session == nil {
+		return ""
+	}
+	return p.nodes[p.session.nodeIdx].host
+}
+
+// dialNode connects to the first reachable node, returning its index.
+func (p *failoverProxy) dialNode() (net.Conn, int, error) {
+	var errs []string
+	for i, nd := range p.nodes {
+		conn, err := nd.dial()
+		if err == nil {
+			return conn, i, nil
+		}
+		log.Debugf("Unable to connect to dcrd at %s: %v", nd.host, err)
+		errs = append(errs, fmt.Sprintf("%s: %v", nd.host, err))
+	}
+	return nil, -1, errors.New(strings.Join(errs, "; "))
+}
+
+// serve accepts client connections until the proxy is stopped, starting a new
+// session with the preferred reachable node for each.
+func (p *failoverProxy) serve() {
+	defer p.wg.Done()
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			select {
+			case <-p.quit:
+			default:
+				log.Errorf("dcrd failover proxy failed to accept connection: %v", err)
+			}
+			return
+		}
+
+		p.wg.Add(1)
+		go p.startSession(client)
+	}
+}
+
+// startSession authenticates the client's request, and forwards it to the
+// preferred reachable node with the node credentials. The connection is
+// closed if the request is not authenticated or no node is reachable.
+func (p *failoverProxy) startSession(client net.Conn) {
+	defer p.wg.Done()
+
+	_ = client.SetReadDeadline(time.Now().Add(nodeDialTimeout))
+	clientReader := bufio.NewReader(client)
+	req, err := http.ReadRequest(clientReader)
+	if err != nil {
+		log.Debugf("dcrd failover proxy failed to read request: %v", err)
+		client.Close()
+		return
+	}
+	_ = client.SetReadDeadline(time.Time{})
+
+	auth := req.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(auth), []byte(p.clientAuth)) != 1 {
+		log.Warnf("dcrd failover proxy rejected an unauthenticated connection from %v.",
+			client.RemoteAddr())
+		_, _ = io.WriteString(client, "HTTP/1.1 401 Unauthorized\r\n"+
+			"Content-Length: 0\r\nConnection: close\r\n\r\n")
+		client.Close()
+		return
+	}
+	req.Header.Set("Authorization", p.nodeAuth)
+
+	node, idx, err := p.dialNode()
+	if err != nil {
+		log.Errorf("Unable to connect to any dcrd node: %v", err)
+		client.Close()
+		return
+	}
+	if err = req.Write(node); err != nil {
+		log.Errorf("Failed to forward request to dcrd at %s: %v", p.nodes[idx].host, err)
+		client.Close()
+		node.Close()
+		return
+	}
+
+	s := &proxySession{client: client, node: node, nodeIdx: idx}
+	p.mtx.Lock()
+	select {
+	case <-p.quit:
+		// stop has already ended the sessions.
+		p.mtx.Unlock()
+		s.close()
+		return
+	default:
+	}
+	if p.session != nil {
+		p.session.close()
+	}
+	p.session = s
+	lastIdx := p.lastIdx
+	p.lastIdx = idx
+	p.mtx.Unlock()
+
+	switch {
+	case lastIdx == -1:
+		log.Infof("Connected to dcrd at %s.", p.nodes[idx].host)
+	case lastIdx != idx:
+		log.Warnf("Failed over from dcrd at %s to %s.", p.nodes[lastIdx].host,
+			p.nodes[idx].host)
+	default:
+		log.Infof("Reconnected to dcrd at %s.", p.nodes[idx].host)
+	}
+
+	// The client reader may have buffered data following the request.
+	p.wg.Add(2)
+	go p.pipe(s, s.node, clientReader)
+	go p.pipe(s, s.client, s.node)
+}
+
+// pipe copies from src to dst until either connection fails, and then ends the
+// session.
+func (p *failoverProxy) pipe(s *proxySession, dst io.Writer, src io.Reader) {
+	defer p.wg.Done()
+	_, _ = io.Copy(dst, src)
+	p.endSession(s)
+}
+
+// endSession closes the session's connections, prompting the client to
+// reconnect.
+func (p *failoverProxy) endSession(s *proxySession) {
+	s.close()
+	p.mtx.Lock()
+	if p.session == s {
+		p.session = nil
+	}
+	p.mtx.Unlock()
+}
+
+// monitor periodically checks that the active node is reachable, ending the
+// session if it is not, so that the client fails over. When a fallback node is
+// active, the session is also ended when the primary node is reachable again,
+// so that the client fails back to it.
+func (p *failoverProxy) monitor() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(nodeHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+		}
+
+		p.checkNodes()
+	}
+}
+
+// checkNodes ends the session if its node is unreachable, or if it is with a
+// fallback node and the primary node is reachable.
+func (p *failoverProxy) checkNodes() {
+	p.mtx.Lock()
+	s := p.session
+	p.mtx.Unlock()
+	if s == nil {
+		return
+	}
+
+	if !p.nodes[s.nodeIdx].reachable() {
+		log.Warnf("dcrd at %s is unreachable. Failing over.", p.nodes[s.nodeIdx].host)
+		p.endSession(s)
+		return
+	}
+	if s.nodeIdx != 0 && p.nodes[0].reachable() {
+		log.Infof("dcrd at %s is reachable again. Failing back.", p.nodes[0].host)
+		p.endSession(s)
+	}
+}
+
+// stop closes the listener and any session, and waits for the proxy goroutines
+// to return.
+func (p *failoverProxy) stop() {
+	close(p.quit)
+	p.listener.Close()
+	p.mtx.Lock()
+	s := p.session
+	p.mtx.Unlock()
+	if s != nil {
+		p.endSession(s)
+	}
+	p.wg.Wait()
+}
@@ -0,0 +1,267 @@
+package rpcutils
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNode is a TCP server standing in for a dcrd node. It answers a websocket
+// upgrade request with its name, and then echoes the client's data.
+type fakeNode struct {
+	name string
+	addr string
+
+	mtx      sync.Mutex
+	listener net.Listener
+	conns    []net.Conn
+	auths    []string // Authorization headers of the requests
+}
+
+func newFakeNode(t *testing.T, name string) *fakeNode {
+	n := &fakeNode{name: name, addr: "127.0.0.1:0"}
+	n.start(t)
+	return n
+}
+
+// start listens on the node's address, which is reused after a stop.
+func (n *fakeNode) start(t *testing.T) {
+	listener, err := net.Listen("tcp", n.addr)
+	if err != nil {
+		t.Fatalf("%s failed to listen: %v", n.name, err)
+	}
+	n.mtx.Lock()
+	n.listener = listener
+	n.addr = listener.Addr().String()
+	n.mtx.Unlock()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			n.mtx.Lock()
+			n.conns = append(n.conns, conn)
+			n.mtx.Unlock()
+			go n.handle(conn)
+		}
+	}()
+}
+
+func (n *fakeNode) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return // e.g. a reachability check
+	}
+	n.mtx.Lock()
+	n.auths = append(n.auths, req.Header.Get("Authorization"))
+	n.mtx.Unlock()
+
+	_, err = io.WriteString(conn, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n\r\n"+n.name+"\n")
+	if err != nil {
+		return
+	}
+	_, _ = io.Copy(conn, reader)
+}
+
+// stop closes the listener and the connections, making the node unreachable.
+func (n *fakeNode) stop() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.listener.Close()
+	for _, conn := range n.conns {
+		conn.Close()
+	}
+	n.conns = nil
+}
+
+func (n *fakeNode) requestAuths() []string {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return append([]string(nil), n.auths...)
+}
+
+// proxyConn is a client connection to the proxy.
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// dialProxy sends a websocket upgrade request with the Authorization header, if
+// any, to the proxy, and returns the connection and the response status.
+func dialProxy(t *testing.T, addr, auth string) (*proxyConn, int) {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect to the proxy: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req := "GET /ws HTTP/1.1\r\nHost: " + addr + "\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n"
+	if auth != "" {
+		req += "Authorization: " + auth + "\r\n"
+	}
+	if _, err = io.WriteString(conn, req+"\r\n"); err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	pc := &proxyConn{Conn: conn, reader: bufio.NewReader(conn)}
+	resp, err := http.ReadResponse(pc.reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	return pc, resp.StatusCode
+}
+
+// readLine reads a line of the node's data, without the newline.
+func (pc *proxyConn) readLine(t *testing.T) string {
+	line, err := pc.reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read from the proxy: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+// connectNode makes an authenticated connection through the proxy, and checks
+// that it is forwarded to the expected node.
+func connectNode(t *testing.T, p *failoverProxy, want *fakeNode) *proxyConn {
+	pc, status := dialProxy(t, p.addr(), p.clientAuth)
+	if status != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status %d, got %d", http.StatusSwitchingProtocols, status)
+	}
+	if name := pc.readLine(t); name != want.name {
+		t.Fatalf("connected to the %s node, expected the %s node", name, want.name)
+	}
+	if active := p.activeNode(); active != want.addr {
+		t.Errorf("active node %s, expected %s", active, want.addr)
+	}
+	if _, err := io.WriteString(pc, "ping\n"); err != nil {
+		t.Fatal(err)
+	}
+	if echo := pc.readLine(t); echo != "ping" {
+		t.Errorf("expected echo ping, got %s", echo)
+	}
+	return pc
+}
+
+// waitClosed checks that the proxy closes the connection.
+func (pc *proxyConn) waitClosed(t *testing.T) {
+	if _, err := pc.reader.ReadString('\n'); err != io.EOF {
+		t.Fatalf("expected the proxy to close the connection, got %v", err)
+	}
+	pc.Close()
+}
+
+func TestFailoverProxyAuth(t *testing.T) {
+	node := newFakeNode(t, "primary")
+	defer node.stop()
+
+	p, err := newFailoverProxy([]NodeEndpoint{{Host: node.addr}}, "user", "pass", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	if p.pass == "" || p.pass == "pass" {
+		t.Errorf("the proxy credentials are not random")
+	}
+
+	tests := []struct {
+		name string
+		auth string
+	}{
+		{"no credentials", ""},
+		{"node credentials", basicAuth("user", "pass")},
+		{"wrong password", basicAuth(p.user, "pass")},
+	}
+	for _, test := range tests {
+		pc, status := dialProxy(t, p.addr(), test.auth)
+		if status != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d, got %d", test.name,
+				http.StatusUnauthorized, status)
+		}
+		pc.waitClosed(t)
+	}
+	if auths := node.requestAuths(); len(auths) != 0 {
+		t.Fatalf("unauthenticated requests were forwarded to the node: %v", auths)
+	}
+
+	// An authenticated request is forwarded with the node credentials.
+	pc := connectNode(t, p, node)
+	defer pc.Close()
+	auths := node.requestAuths()
+	if len(auths) != 1 || auths[0] != basicAuth("user", "pass") {
+		t.Errorf("expected the node credentials, got %v", auths)
+	}
+}
+
+func TestFailoverProxyFailover(t *testing.T) {
+	primary, fallback := newFakeNode(t, "primary"), newFakeNode(t, "fallback")
+	defer fallback.stop()
+
+	p, err := newFailoverProxy([]NodeEndpoint{{Host: primary.addr},
+		{Host: fallback.addr}}, "user", "pass", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	pc := connectNode(t, p, primary)
+
+	// The session ends when the primary node fails, and the client's
+	// reconnection is forwarded to the fallback node.
+	primary.stop()
+	pc.waitClosed(t)
+	pc = connectNode(t, p, fallback)
+
+	// The health check keeps the session with the fallback node while the
+	// primary node is unreachable.
+	p.checkNodes()
+	if _, err = io.WriteString(pc, "still there\n"); err != nil {
+		t.Fatal(err)
+	}
+	if echo := pc.readLine(t); echo != "still there" {
+		t.Errorf("expected echo, got %s", echo)
+	}
+
+	// Once the primary node is reachable again, the health check ends the
+	// session, and the client fails back to the primary node.
+	primary.start(t)
+	defer primary.stop()
+	p.checkNodes()
+	pc.waitClosed(t)
+	pc = connectNode(t, p, primary)
+
+	// The session also ends when the active node is unreachable, e.g. if it
+	// stops accepting connections without closing them.
+	primary.mtx.Lock()
+	primary.listener.Close()
+	primary.mtx.Unlock()
+	p.checkNodes()
+	pc.waitClosed(t)
+	connectNode(t, p, fallback).Close()
+
+	// With no reachable node, the connection is closed.
+	fallback.stop()
+	conn, err := net.Dial("tcp", p.addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: proxy\r\n"+
+		"Authorization: "+p.clientAuth+"\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bufio.NewReader(conn).ReadByte(); err != io.EOF {
+		t.Errorf("expected the proxy to close the connection, got %v", err)
+	}
+	conn.Close()
+}
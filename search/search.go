@@ -0,0 +1,261 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package search finds the blocks, transactions, addresses and proposals
+// matching a partial query: block heights and height ranges, block and
+// transaction hash prefixes, address prefixes, and the words of proposal
+// titles.
+package search
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/wire"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	pitypes "github.com/decred/dcrdata/gov/v3/politeia/types"
+)
+
+const (
+	// DefaultLimit is the number of results returned when no limit is given.
+	DefaultLimit = 20
+	// MaxLimit is the maximum number of results returned.
+	MaxLimit = 100
+
+	// minHashPrefix is the minimum length of a block, transaction or proposal
+	// token hash prefix. Shorter prefixes match too many records to be
+	// useful.
+	minHashPrefix = 8
+	// minAddressPrefix is the minimum length of an address prefix, including
+	// the network and address type characters.
+	minAddressPrefix = 8
+	// hashLength is the length of a hex encoded block or transaction hash.
+	hashLength = 64
+)
+
+// ErrEmptyQuery is returned by Search for a blank query.
+var ErrEmptyQuery = errors.New("empty search query")
+
+var (
+	heightRE      = regexp.MustCompile(`^\d+$`)
+	heightRangeRE = regexp.MustCompile(`^(\d+)\s*(?:-|\.\.)\s*(\d+)$`)
+	hexRE         = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	base58RE      = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]+$`)
+)
+
+// Store is the database searched.
+type Store interface {
+	SearchBlocks(hashPrefix string, limit int) ([]*apitypes.SearchResult, error)
+	SearchBlockRange(from, to int64, limit int) ([]*apitypes.SearchResult, error)
+	SearchTransactions(hashPrefix string, limit int) ([]*apitypes.SearchResult, error)
+	SearchAddresses(prefix string, limit int) ([]*apitypes.SearchResult, error)
+	SearchProposals(query, tokenPrefix string, limit int) ([]*apitypes.SearchResult, error)
+	StoreProposalTitles(titles []dbtypes.ProposalTitle) error
+}
+
+// ProposalSource is the source of the proposal titles, the Politeia proposals
+// DB.
+type ProposalSource interface {
+	LastProposalsSync() int64
+	AllProposals(offset, rowsCount int, filterByVoteStatus ...int) ([]*pitypes.ProposalInfo, int, error)
+}
+
+// Service searches the Store. The proposal titles are copied from the
+// ProposalSource to the Store after each proposals sync.
+type Service struct {
+	store     Store
+	proposals ProposalSource
+	params    *chaincfg.Params
+
+	mtx      sync.Mutex
+	synced   bool
+	lastSync int64
+}
+
+// NewService creates a Service for the Store of the network. proposals may be
+// nil if Politeia is disabled.
+func NewService(store Store, proposals ProposalSource, params *chaincfg.Params) *Service {
+	return &Service{
+		store:     store,
+		proposals: proposals,
+		params:    params,
+	}
+}
+
+// Store syncs the proposal titles if the proposals have been synced since the
+// last new block. Store satisfies blockdata.BlockDataSaver.
+func (s *Service) Store(_ *blockdata.BlockData, _ *wire.MsgBlock) error {
+	return s.SyncProposals()
+}
+
+// SyncProposals stores the titles of the proposals in the Store on the first
+// call, and then whenever the ProposalSource has been synced since the last
+// call.
+func (s *Service) SyncProposals() error {
+	if s.proposals == nil {
+		return nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	lastSync := s.proposals.LastProposalsSync()
+	if s.synced && lastSync == s.lastSync {
+		return nil
+	}
+
+	proposals, _, err := s.proposals.AllProposals(0, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	titles := make([]dbtypes.ProposalTitle, 0, len(proposals))
+	for _, p := range proposals {
+		if p.TokenVal == "" {
+			continue
+		}
+		titles = append(titles, dbtypes.ProposalTitle{
+			Token: p.TokenVal,
+			RefID: p.RefID,
+			Title: p.Name,
+		})
+	}
+	if err = s.store.StoreProposalTitles(titles); err != nil {
+		return err
+	}
+	log.Debugf("Stored %d proposal titles.", len(titles))
+
+	s.synced = true
+	s.lastSync = lastSync
+	return nil
+}
+
+// Search finds the records matching the query, up to limit results in
+// descending order of rank. A limit outside of (0, MaxLimit] is replaced with
+// DefaultLimit or MaxLimit. The query is matched as each of the following
+// that it could be:
+//
+//   - a block height, or a height range "from-to" or "from..to"
+//   - a block hash, transaction hash or proposal token prefix of at least
+//     minHashPrefix hex characters
+//   - an address prefix of at least minAddressPrefix characters
+//   - the words of a proposal title, or a proposal's RefID
+//
+// Exact matches have rank 1. Hash and address prefix matches are ranked by the
+// fraction of the ID matched, and title matches by the full-text search rank.
+func (s *Service) Search(query string, limit int) (*apitypes.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	} else if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	var results []*apitypes.SearchResult
+
+	if m := heightRangeRE.FindStringSubmatch(query); m != nil {
+		from, errFrom := strconv.ParseInt(m[1], 10, 64)
+		to, errTo := strconv.ParseInt(m[2], 10, 64)
+		if errFrom == nil && errTo == nil {
+			if from > to {
+				from, to = to, from
+			}
+			blocks, err := s.store.SearchBlockRange(from, to, limit)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, rankExact(blocks)...)
+		}
+	} else if heightRE.MatchString(query) {
+		if height, err := strconv.ParseInt(query, 10, 64); err == nil {
+			blocks, err := s.store.SearchBlockRange(height, height, 1)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, rankExact(blocks)...)
+		}
+	}
+
+	var tokenPrefix string
+	if hexRE.MatchString(query) && len(query) >= minHashPrefix {
+		tokenPrefix = strings.ToLower(query)
+		if len(query) <= hashLength {
+			blocks, err := s.store.SearchBlocks(tokenPrefix, limit)
+			if err != nil {
+				return nil, err
+			}
+			txns, err := s.store.SearchTransactions(tokenPrefix, limit)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, rankPrefix(blocks, len(query))...)
+			results = append(results, rankPrefix(txns, len(query))...)
+		}
+	}
+
+	if s.isAddressPrefix(query) {
+		addrs, err := s.store.SearchAddresses(query, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rankPrefix(addrs, len(query))...)
+	}
+
+	proposals, err := s.store.SearchProposals(query, tokenPrefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, proposals...)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []*apitypes.SearchResult{}
+	}
+	return &apitypes.SearchResults{
+		Query:   query,
+		Results: results,
+	}, nil
+}
+
+// isAddressPrefix checks if the query could be a prefix of an address on the
+// network.
+func (s *Service) isAddressPrefix(query string) bool {
+	return len(query) >= minAddressPrefix &&
+		strings.HasPrefix(query, s.params.NetworkAddressPrefix) &&
+		base58RE.MatchString(query)
+}
+
+// rankExact sets the rank of each result to 1.
+func rankExact(results []*apitypes.SearchResult) []*apitypes.SearchResult {
+	for _, r := range results {
+		r.Rank = 1
+	}
+	return results
+}
+
+// rankPrefix ranks each result by the fraction of its ID matched by a prefix
+// of length n.
+func rankPrefix(results []*apitypes.SearchResult, n int) []*apitypes.SearchResult {
+	for _, r := range results {
+		r.Rank = 1
+		if len(r.ID) > n {
+			r.Rank = float64(n) / float64(len(r.ID))
+		}
+	}
+	return results
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	apitypes "github.com/decred/dcrdata/api/types/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	pitypes "github.com/decred/dcrdata/gov/v3/politeia/types"
+)
+
+var (
+	blockHash = "000000000000000010c0dd0dbec2ac2fbd4af2c1e1a3fb13c1ec6d3a27fef7b9"
+	txHash    = "0000000078a71a68b6b0d9ad7de1e62ee4a2a9a7bdef2c2c92b6d2c1b8b9e4c1"
+	address   = "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu"
+)
+
+// memStore is a Store of one block, transaction and address, recording the
+// searches made.
+type memStore struct {
+	calls  []string
+	titles []dbtypes.ProposalTitle
+}
+
+func (s *memStore) SearchBlocks(hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	s.calls = append(s.calls, "blocks")
+	if !strings.HasPrefix(blockHash, hashPrefix) {
+		return nil, nil
+	}
+	height := int64(10)
+	return []*apitypes.SearchResult{{Type: apitypes.SearchResultBlock, ID: blockHash, Height: &height}}, nil
+}
+
+func (s *memStore) SearchBlockRange(from, to int64, limit int) ([]*apitypes.SearchResult, error) {
+	s.calls = append(s.calls, "range")
+	var results []*apitypes.SearchResult
+	for h := from; h <= to && len(results) < limit; h++ {
+		height := h
+		results = append(results, &apitypes.SearchResult{Type: apitypes.SearchResultBlock,
+			ID: strings.Repeat("a", hashLength), Height: &height})
+	}
+	return results, nil
+}
+
+func (s *memStore) SearchTransactions(hashPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	s.calls = append(s.calls, "txns")
+	if !strings.HasPrefix(txHash, hashPrefix) {
+		return nil, nil
+	}
+	height := int64(10)
+	return []*apitypes.SearchResult{{Type: apitypes.SearchResultTx, ID: txHash, Height: &height}}, nil
+}
+
+func (s *memStore) SearchAddresses(prefix string, limit int) ([]*apitypes.SearchResult, error) {
+	s.calls = append(s.calls, "addresses")
+	if !strings.HasPrefix(address, prefix) {
+		return nil, nil
+	}
+	return []*apitypes.SearchResult{{Type: apitypes.SearchResultAddress, ID: address}}, nil
+}
+
+func (s *memStore) SearchProposals(query, tokenPrefix string, limit int) ([]*apitypes.SearchResult, error) {
+	s.calls = append(s.calls, "proposals")
+	var results []*apitypes.SearchResult
+	for _, t := range s.titles {
+		if strings.Contains(strings.ToLower(t.Title), strings.ToLower(query)) {
+			results = append(results, &apitypes.SearchResult{Type: apitypes.SearchResultProposal,
+				ID: t.Token, Title: t.Title, Rank: 0.1})
+		}
+	}
+	return results, nil
+}
+
+func (s *memStore) StoreProposalTitles(titles []dbtypes.ProposalTitle) error {
+	s.titles = titles
+	return nil
+}
+
+type memProposals struct {
+	lastSync  int64
+	proposals []*pitypes.ProposalInfo
+	calls     int
+}
+
+func (p *memProposals) LastProposalsSync() int64 { return p.lastSync }
+
+func (p *memProposals) AllProposals(offset, rowsCount int, _ ...int) ([]*pitypes.ProposalInfo, int, error) {
+	p.calls++
+	return p.proposals, len(p.proposals), nil
+}
+
+func TestSearchClassification(t *testing.T) {
+	tests := []struct {
+		query string
+		calls []string
+		types []string
+	}{
+		{"10", []string{"range", "proposals"}, []string{"block"}},
+		{"10-12", []string{"range", "proposals"}, []string{"block", "block", "block"}},
+		{"12..10", []string{"range", "proposals"}, []string{"block", "block", "block"}},
+		{"00000000", []string{"range", "blocks", "txns", "proposals"}, []string{"block", "block", "tx"}},
+		{"0000000078A7", []string{"blocks", "txns", "proposals"}, []string{"tx"}},
+		{txHash, []string{"blocks", "txns", "proposals"}, []string{"tx"}},
+		{"000000", []string{"range", "proposals"}, []string{"block"}},
+		{"DsUZxxoH", []string{"addresses", "proposals"}, []string{"address"}},
+		{"DsUZxxo", []string{"proposals"}, nil},
+		{"TsUZxxoH", []string{"proposals"}, nil},
+		{"treasury", []string{"proposals"}, nil},
+	}
+	for _, tt := range tests {
+		store := new(memStore)
+		s := NewService(store, nil, chaincfg.MainNetParams())
+		res, err := s.Search(tt.query, 0)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(store.calls, tt.calls) {
+			t.Errorf("Search(%q) searched %v, expected %v", tt.query, store.calls, tt.calls)
+		}
+		var types []string
+		for _, r := range res.Results {
+			types = append(types, r.Type)
+		}
+		if !reflect.DeepEqual(types, tt.types) {
+			t.Errorf("Search(%q) found %v, expected %v", tt.query, types, tt.types)
+		}
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	store := new(memStore)
+	s := NewService(store, nil, chaincfg.MainNetParams())
+	res, err := s.Search("000000000", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The block at height 0 is an exact match. The block and transaction hash
+	// prefix matches are 9 of 64 characters, so the order of the searches is
+	// kept.
+	want := []struct {
+		typ  string
+		rank float64
+	}{
+		{apitypes.SearchResultBlock, 1},
+		{apitypes.SearchResultBlock, 9.0 / 64},
+		{apitypes.SearchResultTx, 9.0 / 64},
+	}
+	if len(res.Results) != len(want) {
+		t.Fatalf("%d results, expected %d", len(res.Results), len(want))
+	}
+	for i, w := range want {
+		if r := res.Results[i]; r.Type != w.typ || r.Rank != w.rank {
+			t.Errorf("result %d is %s with rank %v, expected %s with rank %v",
+				i, r.Type, r.Rank, w.typ, w.rank)
+		}
+	}
+
+	res, err = s.Search(blockHash, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 1 || res.Results[0].Rank != 1 {
+		t.Errorf("exact hash match not ranked 1: %v", res.Results)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	s := NewService(new(memStore), nil, chaincfg.MainNetParams())
+	res, err := s.Search("0-1000", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 5 {
+		t.Errorf("%d results, expected 5", len(res.Results))
+	}
+	res, err = s.Search("0-1000", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != MaxLimit {
+		t.Errorf("%d results, expected %d", len(res.Results), MaxLimit)
+	}
+	if _, err = s.Search("  ", 0); err != ErrEmptyQuery {
+		t.Errorf("expected ErrEmptyQuery, got %v", err)
+	}
+}
+
+func TestSyncProposals(t *testing.T) {
+	store := new(memStore)
+	proposals := &memProposals{
+		lastSync: 100,
+		proposals: []*pitypes.ProposalInfo{{
+			Name:             "Decred Treasury Audit",
+			RefID:            "decred-treasury-audit",
+			CensorshipRecord: pitypes.CensorshipRecord{TokenVal: "abcdef0123456789"},
+		}, {
+			Name: "No token",
+		}},
+	}
+	s := NewService(store, proposals, chaincfg.MainNetParams())
+	if err := s.SyncProposals(); err != nil {
+		t.Fatal(err)
+	}
+	want := []dbtypes.ProposalTitle{{
+		Token: "abcdef0123456789",
+		RefID: "decred-treasury-audit",
+		Title: "Decred Treasury Audit",
+	}}
+	if !reflect.DeepEqual(store.titles, want) {
+		t.Errorf("stored %v, expected %v", store.titles, want)
+	}
+
+	// Not synced again until the proposals DB syncs.
+	if err := s.Store(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if proposals.calls != 1 {
+		t.Errorf("%d proposals retrievals, expected 1", proposals.calls)
+	}
+	proposals.lastSync = 200
+	if err := s.Store(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if proposals.calls != 2 {
+		t.Errorf("%d proposals retrievals, expected 2", proposals.calls)
+	}
+
+	res, err := s.Search("treasury", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 1 || res.Results[0].ID != "abcdef0123456789" {
+		t.Errorf("unexpected results %v", res.Results)
+	}
+}
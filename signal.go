@@ -7,8 +7,13 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // shutdownRequested checks if the Done channel of the given context has been
@@ -24,44 +29,265 @@ func shutdownRequested(ctx context.Context) bool {
 	}
 }
 
-// shutdownRequest is used to initiate shutdown from one of the
+// shutdownRequests is the number of calls to requestShutdown not yet handled
+// by shutdownListener. It is accessed atomically. shutdownRequest notifies
+// shutdownListener of new requests, to initiate shutdown from one of the
 // subsystems using the same code paths as when an interrupt signal is received.
-var shutdownRequest = make(chan struct{})
+var (
+	shutdownRequests int32
+	shutdownRequest  = make(chan struct{}, 1)
+)
 
 // shutdownSignal is closed whenever shutdown is invoked through an interrupt
 // signal or from an JSON-RPC stop request.  Any contexts created using
 // withShutdownChannel are cancelled when this is closed.
 var shutdownSignal = make(chan struct{})
 
+// shutdownComplete is closed by shutdownListener after shutdownSignal is closed
+// and all of the registered shutdown hooks have returned.
+var shutdownComplete = make(chan struct{})
+
 // signals defines the signals that are handled to do a clean shutdown.
 // Conditional compilation is used to also include SIGTERM on Unix.
 var signals = []os.Signal{os.Interrupt}
 
+// forceExitSignals is the number of interrupt signals and shutdown requests,
+// received within forceExitWindow, at which shutdownListener stops waiting for
+// a clean shutdown and exits the process immediately. Values less than 2
+// disable the forced exit. It is accessed atomically.
+var forceExitSignals int32 = 2
+
+// forceExitWindow is the period within which forceExitSignals interrupt
+// signals and shutdown requests must be received to force an exit.
+var forceExitWindow = 5 * time.Second
+
+// SetForceExitSignalCount sets the number of interrupt signals and shutdown
+// requests, received within a few seconds, that will force the process to exit
+// immediately, bypassing a clean shutdown that may be stalled. The first signal
+// or request always begins the normal clean shutdown. A count less than 2
+// disables the forced exit.
+func SetForceExitSignalCount(n int) {
+	atomic.StoreInt32(&forceExitSignals, int32(n))
+}
+
+// shutdownHook is a named cleanup function that is run after shutdownSignal is
+// closed.
+type shutdownHook struct {
+	name string
+	fn   func()
+}
+
+// shutdownHooks are the registered shutdown hooks, in order of registration.
+// outstandingHooks are the names of the hooks that are running or have yet to
+// run during shutdown, in the order they will run.
+var (
+	shutdownHooks    []shutdownHook
+	outstandingHooks []string
+	shutdownHooksMtx sync.Mutex
+)
+
+// shutdownGracePeriod is the maximum duration, in nanoseconds, that shutdown
+// processing may take after shutdown is signaled before the process is forced
+// to exit. Zero disables the forced exit. It is accessed atomically.
+var shutdownGracePeriod int64
+
+// SetShutdownGracePeriod sets the maximum duration that shutdown processing,
+// including the shutdown hooks, may take after shutdown is signaled. If
+// shutdown has not completed within this period, the names of the outstanding
+// shutdown hooks are logged and the process exits immediately. This bounds the
+// time an orchestrator waits on a stuck cleanup. A zero duration disables the
+// forced exit.
+func SetShutdownGracePeriod(d time.Duration) {
+	atomic.StoreInt64(&shutdownGracePeriod, int64(d))
+}
+
+// AddShutdownHook registers a function to be run when shutdown is signaled.
+// Hooks are run by shutdownListener after shutdownSignal is closed, in the
+// reverse order of registration so that subsystems initialized later are torn
+// down first. It is safe to call AddShutdownHook concurrently.
+func AddShutdownHook(name string, fn func()) {
+	shutdownHooksMtx.Lock()
+	defer shutdownHooksMtx.Unlock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name, fn})
+}
+
+// runShutdownHooks runs the registered shutdown hooks in LIFO order.
+func runShutdownHooks() {
+	shutdownHooksMtx.Lock()
+	hooks := make([]shutdownHook, len(shutdownHooks))
+	copy(hooks, shutdownHooks)
+	outstandingHooks = make([]string, 0, len(hooks))
+	for i := len(hooks) - 1; i >= 0; i-- {
+		outstandingHooks = append(outstandingHooks, hooks[i].name)
+	}
+	shutdownHooksMtx.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		log.Infof("Running shutdown hook %q...", hooks[i].name)
+		hooks[i].fn()
+
+		shutdownHooksMtx.Lock()
+		outstandingHooks = outstandingHooks[1:]
+		shutdownHooksMtx.Unlock()
+	}
+}
+
+// outstandingShutdownHooks returns the names of the shutdown hooks that are
+// running or have yet to run, in the order they will run.
+func outstandingShutdownHooks() []string {
+	shutdownHooksMtx.Lock()
+	defer shutdownHooksMtx.Unlock()
+	names := make([]string, len(outstandingHooks))
+	copy(names, outstandingHooks)
+	return names
+}
+
+// shutdownWatchdog forces the process to exit if shutdown processing does not
+// complete within the grace period d. This function is intended to be spawned
+// in a new goroutine after shutdownSignal is closed.
+func shutdownWatchdog(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-shutdownComplete:
+	case <-timer.C:
+		outstanding := "none"
+		if names := outstandingShutdownHooks(); len(names) > 0 {
+			outstanding = strings.Join(names, ", ")
+		}
+		log.Errorf("Shutdown did not complete within %v (outstanding shutdown "+
+			"hooks: %s). Forcing exit!", d, outstanding)
+		os.Exit(1)
+	}
+}
+
+// ShutdownChannel returns a receive-only view of the channel that is closed
+// when shutdown is signaled. This allows a goroutine to watch for shutdown in
+// its own select loop without creating a context with withShutdownCancel.
+func ShutdownChannel() <-chan struct{} {
+	return shutdownSignal
+}
+
+// WaitForShutdown blocks until shutdown has been signaled and processed. The
+// shutdown hooks registered with AddShutdownHook before shutdown was signaled
+// are guaranteed to have returned when WaitForShutdown returns.
+func WaitForShutdown() {
+	<-shutdownComplete
+}
+
+// reloadSignals defines the signals that invoke the reload handler rather than
+// initiating shutdown. Conditional compilation is used to include SIGHUP on
+// platforms that support it.
+var reloadSignals []os.Signal
+
+// reloadHandler is the function set by SetReloadHandler.
+var (
+	reloadHandler    func()
+	reloadHandlerMtx sync.Mutex
+)
+
+// SetReloadHandler sets the function to be called each time a reload signal
+// (SIGHUP) is received, such as to reload configuration or rotate logs. A
+// reload signal does not initiate shutdown. On platforms without SIGHUP, the
+// handler is never called.
+func SetReloadHandler(fn func()) {
+	reloadHandlerMtx.Lock()
+	reloadHandler = fn
+	reloadHandlerMtx.Unlock()
+}
+
+// reloadListener calls the reload handler each time a reload signal is
+// received, until shutdown is signaled. This function is intended to be
+// spawned in a new goroutine.
+func reloadListener() {
+	if len(reloadSignals) == 0 {
+		return
+	}
+	reloadChannel := make(chan os.Signal, 1)
+	signal.Notify(reloadChannel, reloadSignals...)
+
+	for {
+		select {
+		case sig := <-reloadChannel:
+			reloadHandlerMtx.Lock()
+			fn := reloadHandler
+			reloadHandlerMtx.Unlock()
+			if fn == nil {
+				log.Infof("Received signal (%s). No reload handler is set.", sig)
+				continue
+			}
+			log.Infof("Received signal (%s). Reloading...", sig)
+			fn()
+		case <-shutdownSignal:
+			return
+		}
+	}
+}
+
 // withShutdownCancel creates a copy of a context that is cancelled whenever
 // shutdown is invoked through an interrupt signal or from an JSON-RPC stop
 // request.
 func withShutdownCancel(ctx context.Context) context.Context {
+	ctx, _ = shutdownContext(ctx)
+	return ctx
+}
+
+// withShutdownTimeout creates a copy of a context that is cancelled whenever
+// shutdown is invoked or when the timeout elapses, whichever comes first. If
+// the timeout elapses, the context's Err method returns
+// context.DeadlineExceeded, while a shutdown results in context.Canceled. The
+// returned CancelFunc should be called to release resources as soon as the
+// operation using the context completes.
+func withShutdownTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, shutdownCancel := shutdownContext(ctx)
+	ctx, timeoutCancel := context.WithTimeout(ctx, d)
+	return ctx, func() {
+		timeoutCancel()
+		shutdownCancel()
+	}
+}
+
+// shutdownContext creates a copy of a context that is cancelled whenever
+// shutdown is invoked, or when the returned CancelFunc is called. The goroutine
+// watching for shutdown returns as soon as the new context is done, so it does
+// not outlive a context that is cancelled independently of shutdown.
+func shutdownContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(ctx)
 	go func() {
-		<-shutdownSignal
-		cancel()
+		select {
+		case <-shutdownSignal:
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
-	return ctx
+	return ctx, cancel
 }
 
 // requestShutdown signals for starting the clean shutdown of the process
 // through an internal component (such as through the JSON-RPC stop request).
+// It never blocks, and may be called any number of times from any goroutine,
+// even before shutdownListener is started. The calls made before shutdown is
+// signaled start a single clean shutdown. Like interrupt signals, the calls
+// made after that count toward a forced exit (see SetForceExitSignalCount).
 func requestShutdown() {
-	shutdownRequest <- struct{}{}
+	atomic.AddInt32(&shutdownRequests, 1)
+	select {
+	case shutdownRequest <- struct{}{}:
+	default:
+	}
 }
 
 // shutdownListener listens for shutdown requests and cancels all contexts
-// created from withShutdownCancel.  This function never returns and is intended
-// to be spawned in a new goroutine.
+// created from withShutdownCancel. It also starts the reload signal listener.
+// This function never returns and is intended to be spawned in a new goroutine.
 func shutdownListener() {
 	interruptChannel := make(chan os.Signal, 1)
 	signal.Notify(interruptChannel, signals...)
 
+	// Reload signals are handled separately, without initiating shutdown.
+	go reloadListener()
+
 	// Listen for the initial shutdown signal
 	select {
 	case sig := <-interruptChannel:
@@ -73,13 +299,56 @@ func shutdownListener() {
 	// Cancel all contexts created from withShutdownCancel.
 	close(shutdownSignal)
 
+	// The shutdown requests made so far are handled by this shutdown.
+	atomic.StoreInt32(&shutdownRequests, 0)
+
+	// signalTimes are the times of the recent shutdown signals and requests,
+	// including the initial one, for the forced exit.
+	signalTimes := []time.Time{time.Now()}
+
+	// Bound the time allowed for shutdown processing if a grace period is set.
+	if d := time.Duration(atomic.LoadInt64(&shutdownGracePeriod)); d > 0 {
+		go shutdownWatchdog(d)
+	}
+
+	// Run the registered shutdown hooks without blocking the listener, and
+	// signal completion of shutdown processing when they have returned.
+	go func() {
+		runShutdownHooks()
+		close(shutdownComplete)
+	}()
+
 	// Listen for any more shutdown signals and log that shutdown has already
-	// been signaled.
+	// been signaled. Repeated interrupt signals and shutdown requests within
+	// forceExitWindow escalate to an immediate exit.
 	for {
+		var source string
+		numNew := 1
 		select {
-		case <-interruptChannel:
+		case sig := <-interruptChannel:
+			source = fmt.Sprintf("signal (%s)", sig)
 		case <-shutdownRequest:
+			source = "shutdown request"
+			numNew = int(atomic.SwapInt32(&shutdownRequests, 0))
+			if numNew == 0 {
+				// Only requests handled by the initial shutdown.
+				continue
+			}
 		}
 		log.Info("Shutdown signaled. Already shutting down...")
+
+		now := time.Now()
+		for i := 0; i < numNew; i++ {
+			signalTimes = append(signalTimes, now)
+		}
+		for len(signalTimes) > 0 && now.Sub(signalTimes[0]) > forceExitWindow {
+			signalTimes = signalTimes[1:]
+		}
+		if n := int(atomic.LoadInt32(&forceExitSignals)); n > 1 && len(signalTimes) >= n {
+			log.Warnf("Received %s: %d shutdown signals and requests within "+
+				"%v. Forcing exit without completing clean shutdown!", source,
+				len(signalTimes), forceExitWindow)
+			os.Exit(1)
+		}
 	}
 }
@@ -0,0 +1,265 @@
+// Copyright (c) 2018, The Decred-Next developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/slog"
+)
+
+// The signal tests run shutdown listeners that outlive the individual tests, so
+// the logger is disabled once rather than reassigned by each test.
+func init() {
+	log = slog.Disabled
+}
+
+// shutdownTestEnv is set to the name of the test run by a test process started
+// by isolatedShutdownTest.
+const shutdownTestEnv = "DCRDATA_SHUTDOWN_TEST"
+
+// runShutdownTestProcess runs the calling test in a new test process, in which
+// isolatedShutdownTest runs the test function, and returns its output.
+func runShutdownTestProcess(t *testing.T) ([]byte, error) {
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(), shutdownTestEnv+"="+t.Name())
+	return cmd.CombinedOutput()
+}
+
+// isolatedShutdownTest runs a test that signals shutdown in its own test
+// process. The shutdown channels are closed only once in a process, and the
+// goroutines started by shutdownListener never return, so each such test
+// requires the package-level shutdown state of a new process.
+func isolatedShutdownTest(t *testing.T, test func(t *testing.T)) {
+	if os.Getenv(shutdownTestEnv) == t.Name() {
+		test(t)
+		return
+	}
+	if out, err := runShutdownTestProcess(t); err != nil {
+		t.Fatalf("test process failed: %v\n%s", err, out)
+	}
+}
+
+func TestShutdownHooksLIFO(t *testing.T) {
+	defer func() { shutdownHooks = nil }()
+
+	var mtx sync.Mutex
+	var order []string
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("hook%d", i)
+		AddShutdownHook(name, func() {
+			mtx.Lock()
+			order = append(order, name)
+			mtx.Unlock()
+		})
+	}
+
+	runShutdownHooks()
+
+	want := []string{"hook2", "hook1", "hook0"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("shutdown hooks ran in order %v, expected %v", order, want)
+	}
+}
+
+func TestWithShutdownTimeout(t *testing.T) {
+	ctx, cancel := withShutdownTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled by the timeout")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", ctx.Err())
+	}
+}
+
+func TestWithShutdownCancelNoLeak(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 1000; i++ {
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := withShutdownCancel(parent)
+		cancel()
+		<-ctx.Done()
+	}
+
+	// The watcher goroutines exit asynchronously, so allow them some time.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutine count %d did not return to baseline %d",
+				runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRequestShutdownNonBlocking(t *testing.T) {
+	isolatedShutdownTest(t, testRequestShutdownNonBlocking)
+}
+
+func testRequestShutdownNonBlocking(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		// None of these calls may block, even without a listener.
+		for i := 0; i < 3; i++ {
+			requestShutdown()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("requestShutdown blocked")
+	}
+
+	select {
+	case <-shutdownRequest:
+	default:
+		t.Error("shutdown request was not triggered")
+	}
+}
+
+func TestRequestShutdownBeforeListener(t *testing.T) {
+	isolatedShutdownTest(t, testRequestShutdownBeforeListener)
+}
+
+func testRequestShutdownBeforeListener(t *testing.T) {
+	// The requests made before shutdown is signaled start a single clean
+	// shutdown rather than forcing an exit.
+	for i := 0; i < 3; i++ {
+		requestShutdown()
+	}
+	go shutdownListener()
+
+	done := make(chan struct{})
+	go func() {
+		WaitForShutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForShutdown did not return")
+	}
+	// Allow the listener to handle any remaining requests.
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWaitForShutdown(t *testing.T) {
+	isolatedShutdownTest(t, testWaitForShutdown)
+}
+
+func testWaitForShutdown(t *testing.T) {
+	var hookDone bool
+	AddShutdownHook("test", func() {
+		time.Sleep(50 * time.Millisecond)
+		hookDone = true
+	})
+
+	go shutdownListener()
+	requestShutdown()
+
+	done := make(chan struct{})
+	go func() {
+		WaitForShutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForShutdown did not return")
+	}
+	if !hookDone {
+		t.Error("WaitForShutdown returned before the shutdown hook completed")
+	}
+}
+
+func TestShutdownChannel(t *testing.T) {
+	isolatedShutdownTest(t, testShutdownChannel)
+}
+
+func testShutdownChannel(t *testing.T) {
+	quit := ShutdownChannel()
+	select {
+	case <-quit:
+		t.Fatal("shutdown channel closed before shutdown")
+	default:
+	}
+
+	close(shutdownSignal)
+	select {
+	case <-quit:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown channel not closed after shutdown")
+	}
+}
+
+func TestOutstandingShutdownHooks(t *testing.T) {
+	defer func() { shutdownHooks = nil }()
+
+	// The second hook to run blocks until released.
+	release := make(chan struct{})
+	running := make(chan struct{})
+	AddShutdownHook("db", func() {})
+	AddShutdownHook("stuck", func() {
+		close(running)
+		<-release
+	})
+	AddShutdownHook("api", func() {})
+
+	done := make(chan struct{})
+	go func() {
+		runShutdownHooks()
+		close(done)
+	}()
+
+	<-running
+	want := []string{"stuck", "db"}
+	if got := outstandingShutdownHooks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("outstanding hooks %v, expected %v", got, want)
+	}
+
+	close(release)
+	<-done
+	if got := outstandingShutdownHooks(); len(got) != 0 {
+		t.Errorf("outstanding hooks %v after all hooks returned", got)
+	}
+}
+
+func TestShutdownWatchdogCompletes(t *testing.T) {
+	isolatedShutdownTest(t, testShutdownWatchdogCompletes)
+}
+
+func testShutdownWatchdogCompletes(t *testing.T) {
+	// The watchdog must return without forcing an exit when shutdown
+	// completes within the grace period.
+	done := make(chan struct{})
+	go func() {
+		shutdownWatchdog(time.Minute)
+		close(done)
+	}()
+	close(shutdownComplete)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdownWatchdog did not return after shutdown completed")
+	}
+}
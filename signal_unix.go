@@ -3,7 +3,8 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
-// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+//go:build !windows
+// +build !windows
 
 package main
 
@@ -13,5 +14,6 @@ import (
 )
 
 func init() {
-	signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	signals = append(signals, syscall.SIGTERM)
+	reloadSignals = []os.Signal{syscall.SIGHUP}
 }
@@ -0,0 +1,182 @@
+// Copyright (c) 2018, The Decred-Next developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownListenerSIGTERM(t *testing.T) {
+	isolatedShutdownTest(t, testShutdownListenerSIGTERM)
+}
+
+func testShutdownListenerSIGTERM(t *testing.T) {
+	go shutdownListener()
+	// Allow the listener to register for the signals.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case <-shutdownSignal:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdownListener did not react to SIGTERM")
+	}
+}
+
+func TestShutdownListenerForceExit(t *testing.T) {
+	tests := []struct {
+		name     string        // test description
+		count    int           // forced exit signal count
+		window   time.Duration // forced exit window
+		triggers []string      // "signal" or "request", in order
+		pause    time.Duration // pause between the triggers
+		wantExit bool          // whether the process is forced to exit
+	}{{
+		name:     "second_signal",
+		count:    2,
+		window:   5 * time.Second,
+		triggers: []string{"signal", "signal"},
+		pause:    50 * time.Millisecond,
+		wantExit: true,
+	}, {
+		name:     "signal_then_request",
+		count:    2,
+		window:   5 * time.Second,
+		triggers: []string{"signal", "request"},
+		pause:    50 * time.Millisecond,
+		wantExit: true,
+	}, {
+		name:     "request_then_signal",
+		count:    2,
+		window:   5 * time.Second,
+		triggers: []string{"request", "signal"},
+		pause:    50 * time.Millisecond,
+		wantExit: true,
+	}, {
+		name:     "second_request",
+		count:    2,
+		window:   5 * time.Second,
+		triggers: []string{"request", "request"},
+		pause:    50 * time.Millisecond,
+		wantExit: true,
+	}, {
+		name:     "below_count",
+		count:    3,
+		window:   5 * time.Second,
+		triggers: []string{"signal", "request"},
+		pause:    50 * time.Millisecond,
+		wantExit: false,
+	}, {
+		name:     "third_signal",
+		count:    3,
+		window:   5 * time.Second,
+		triggers: []string{"signal", "request", "signal"},
+		pause:    50 * time.Millisecond,
+		wantExit: true,
+	}, {
+		name:     "disabled",
+		count:    0,
+		window:   5 * time.Second,
+		triggers: []string{"signal", "signal", "request"},
+		pause:    50 * time.Millisecond,
+		wantExit: false,
+	}, {
+		name:     "outside_window",
+		count:    2,
+		window:   100 * time.Millisecond,
+		triggers: []string{"signal", "signal"},
+		pause:    300 * time.Millisecond,
+		wantExit: false,
+	}}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if os.Getenv(shutdownTestEnv) == t.Name() {
+				SetForceExitSignalCount(test.count)
+				forceExitWindow = test.window
+
+				go shutdownListener()
+				// Allow the listener to register for the signals.
+				time.Sleep(100 * time.Millisecond)
+
+				for i, trigger := range test.triggers {
+					if i > 0 {
+						time.Sleep(test.pause)
+					}
+					switch trigger {
+					case "signal":
+						if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+							t.Fatalf("failed to send SIGTERM: %v", err)
+						}
+					case "request":
+						requestShutdown()
+					}
+					if i == 0 {
+						<-shutdownSignal
+					}
+				}
+				// Allow the listener to handle the last trigger.
+				time.Sleep(200 * time.Millisecond)
+				return
+			}
+
+			out, err := runShutdownTestProcess(t)
+			if !test.wantExit {
+				if err != nil {
+					t.Fatalf("test process failed: %v\n%s", err, out)
+				}
+				return
+			}
+			// A forced exit ends the test process with status 1 before the
+			// test either passes or fails.
+			exitErr, ok := err.(*exec.ExitError)
+			if !ok || exitErr.ExitCode() != 1 || bytes.Contains(out, []byte("--- FAIL")) {
+				t.Fatalf("test process was not forced to exit: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestReloadHandlerSIGHUP(t *testing.T) {
+	isolatedShutdownTest(t, testReloadHandlerSIGHUP)
+}
+
+func testReloadHandlerSIGHUP(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	SetReloadHandler(func() { reloaded <- struct{}{} })
+	defer SetReloadHandler(nil)
+
+	go reloadListener()
+	// Allow the listener to register for the signals.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("failed to send SIGHUP: %v", err)
+	}
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload handler was not called on SIGHUP")
+	}
+
+	select {
+	case <-shutdownSignal:
+		t.Error("SIGHUP initiated shutdown")
+	default:
+	}
+}
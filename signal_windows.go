@@ -0,0 +1,9 @@
+// Copyright (c) 2018, The Decred-Next developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+// On Windows, only os.Interrupt is handled to do a clean shutdown, so signals
+// is left unmodified. There is no SIGHUP, so reloadSignals is left empty and
+// the reload handler is never invoked.
@@ -0,0 +1,399 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package stakedb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/database/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// A snapshot is a gzip compressed stream of:
+//
+//   - the magic bytes and version
+//   - the network, and the height and hash of the best block
+//   - the ticket pool diffs of the blocks from height 1 to the best block
+//   - the stake database's metadata buckets and key/value pairs, as records
+//     of an op byte followed by varint length prefixed byte strings
+//
+// The block undo data and new ticket hashes of all but the last
+// SnapshotUndoDepth blocks are omitted, since they are only needed to
+// disconnect blocks.
+
+// SnapshotUndoDepth is the number of blocks that may be disconnected from an
+// imported snapshot.
+const SnapshotUndoDepth = 1024
+
+const (
+	snapshotMagic   = "DCRDSTKS"
+	snapshotVersion = 1
+
+	// Stake database record ops.
+	snapshotOpKeyValue byte = 'k' // key, value
+	snapshotOpBucket   byte = 'b' // name, then the bucket's records
+	snapshotOpEnd      byte = 'e' // end of the current bucket
+)
+
+var (
+	// ffldbPrefix is the prefix of the ffldb driver's internal buckets and
+	// keys in the metadata, which are created with the database.
+	ffldbPrefix = []byte("ffldb-")
+
+	// heightKeyedBuckets are the stake database buckets keyed by block height
+	// that are trimmed to SnapshotUndoDepth blocks.
+	heightKeyedBuckets = [][]byte{[]byte("stakeblockundo"), []byte("ticketsinblock")}
+)
+
+// ExportSnapshot writes a snapshot of the stake and ticket pool databases at
+// the given height to w. If height is below the current height, the databases
+// are first rewound to height, and the rewind is not undone. The snapshot can
+// be imported on another machine with ImportSnapshot.
+func (db *StakeDatabase) ExportSnapshot(w io.Writer, height int64) error {
+	current := int64(db.Height())
+	if height > current {
+		return fmt.Errorf("snapshot height %d is above the stake DB height %d",
+			height, current)
+	}
+	if height < current {
+		log.Infof("Rewinding stake DB from %d to %d for the snapshot...", current, height)
+		if err := db.Rewind(height, false); err != nil {
+			return err
+		}
+	}
+
+	db.nodeMtx.RLock()
+	defer db.nodeMtx.RUnlock()
+
+	dbHeight, hash, err := db.dbState()
+	if err != nil {
+		return err
+	}
+
+	db.PoolDB.mtx.RLock()
+	defer db.PoolDB.mtx.RUnlock()
+	if db.PoolDB.tip != int64(dbHeight) {
+		return fmt.Errorf("stake DB height (%d) != ticket pool height (%d)",
+			dbHeight, db.PoolDB.tip)
+	}
+
+	log.Infof("Exporting stake DB snapshot at height %d (%v)...", dbHeight, hash)
+	return writeSnapshot(w, db.params.Net, dbHeight, hash, db.PoolDB.diffs, db.StakeDB)
+}
+
+// ImportSnapshot creates the stake and ticket pool databases in dataDir from a
+// snapshot written by ExportSnapshot. The databases must not already exist.
+// The height and hash of the snapshot's best block are returned. The imported
+// databases are opened with NewStakeDatabase.
+func ImportSnapshot(r io.Reader, params *chaincfg.Params, dataDir string) (int64, *chainhash.Hash, error) {
+	stakeDBPath := filepath.Join(dataDir, DefaultStakeDbName)
+	poolDBPath := filepath.Join(dataDir, DefaultTicketPoolDbFolder)
+	for _, path := range []string{stakeDBPath, poolDBPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			return -1, nil, fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return -1, nil, err
+	}
+	sr := &snapshotReader{r: bufio.NewReader(zr)}
+
+	net, height, hash, diffs, err := sr.readHeaderAndDiffs()
+	if err != nil {
+		return -1, nil, err
+	}
+	if net != params.Net {
+		return -1, nil, fmt.Errorf("snapshot is for network %v, not %v", net, params.Net)
+	}
+	log.Infof("Importing stake DB snapshot at height %d (%v)...", height, hash)
+
+	// Remove the partially created databases on failure.
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(stakeDBPath)
+			_ = os.RemoveAll(poolDBPath)
+		}
+	}()
+
+	if err = os.MkdirAll(dataDir, 0700); err != nil {
+		return -1, nil, fmt.Errorf("unable to create DB folder: %v", err)
+	}
+
+	poolDB, err := NewTicketPool(dataDir, DefaultTicketPoolDbFolder)
+	if err != nil {
+		return -1, nil, fmt.Errorf("unable to create ticket pool DB: %v", err)
+	}
+	diffPtrs := make([]*PoolDiff, len(diffs))
+	heights := make([]int64, len(diffs))
+	for i := range diffs {
+		diffPtrs[i] = &diffs[i]
+		heights[i] = int64(i) + 1
+	}
+	err = storeDiffs(poolDB.diffDB, diffPtrs, heights)
+	if errClose := poolDB.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return -1, nil, fmt.Errorf("failed to store ticket pool diffs: %v", err)
+	}
+
+	stakeDB, err := database.Create(dbType, stakeDBPath, params.Net)
+	if err != nil {
+		return -1, nil, fmt.Errorf("error creating database.DB: %v", err)
+	}
+	err = stakeDB.Update(func(dbTx database.Tx) error {
+		return sr.readBucket(dbTx.Metadata())
+	})
+	if errClose := stakeDB.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return -1, nil, fmt.Errorf("failed to import stake DB: %v", err)
+	}
+
+	return int64(height), hash, nil
+}
+
+// writeSnapshot writes the snapshot of the stake DB and the ticket pool diffs
+// of the blocks up to height.
+func writeSnapshot(w io.Writer, net wire.CurrencyNet, height uint32, hash *chainhash.Hash,
+	diffs []PoolDiff, stakeDB database.DB) error {
+	zw := gzip.NewWriter(w)
+	sw := &snapshotWriter{w: bufio.NewWriter(zw)}
+
+	sw.write([]byte(snapshotMagic))
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], snapshotVersion)
+	sw.write(b[:])
+	binary.LittleEndian.PutUint32(b[:], uint32(net))
+	sw.write(b[:])
+	binary.LittleEndian.PutUint32(b[:], height)
+	sw.write(b[:])
+	sw.write(hash[:])
+
+	sw.writeUvarint(uint64(height))
+	for i := range diffs[:height] {
+		sw.writeHashes(diffs[i].In)
+		sw.writeHashes(diffs[i].Out)
+	}
+	if sw.err != nil {
+		return sw.err
+	}
+
+	var minUndoHeight uint32
+	if height > SnapshotUndoDepth {
+		minUndoHeight = height - SnapshotUndoDepth
+	}
+	err := stakeDB.View(func(dbTx database.Tx) error {
+		return sw.writeBucket(dbTx.Metadata(), nil, minUndoHeight)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = sw.w.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// snapshotWriter writes the snapshot encoding, saving the first error.
+type snapshotWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *snapshotWriter) write(b []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(b)
+	}
+}
+
+func (sw *snapshotWriter) writeUvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	sw.write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (sw *snapshotWriter) writeBytes(b []byte) {
+	sw.writeUvarint(uint64(len(b)))
+	sw.write(b)
+}
+
+func (sw *snapshotWriter) writeHashes(hashes []chainhash.Hash) {
+	sw.writeUvarint(uint64(len(hashes)))
+	for i := range hashes {
+		sw.write(hashes[i][:])
+	}
+}
+
+// writeBucket writes the key/value pairs and nested buckets of the bucket
+// named name, followed by snapshotOpEnd. The root bucket has no name.
+func (sw *snapshotWriter) writeBucket(bucket database.Bucket, name []byte, minUndoHeight uint32) error {
+	isHeightKeyed := name != nil && isHeightKeyedBucket(name)
+	err := bucket.ForEach(func(k, v []byte) error {
+		// Nested buckets are not included, and are written by ForEachBucket.
+		if bytes.HasPrefix(k, ffldbPrefix) {
+			return nil
+		}
+		if isHeightKeyed && len(k) == 4 && binary.LittleEndian.Uint32(k) < minUndoHeight {
+			return nil
+		}
+		sw.write([]byte{snapshotOpKeyValue})
+		sw.writeBytes(k)
+		sw.writeBytes(v)
+		return sw.err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = bucket.ForEachBucket(func(k []byte) error {
+		if bytes.HasPrefix(k, ffldbPrefix) {
+			return nil
+		}
+		sw.write([]byte{snapshotOpBucket})
+		sw.writeBytes(k)
+		return sw.writeBucket(bucket.Bucket(k), k, minUndoHeight)
+	})
+	if err != nil {
+		return err
+	}
+
+	sw.write([]byte{snapshotOpEnd})
+	return sw.err
+}
+
+func isHeightKeyedBucket(name []byte) bool {
+	for _, b := range heightKeyedBuckets {
+		if bytes.Equal(name, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotReader reads the snapshot encoding.
+type snapshotReader struct {
+	r *bufio.Reader
+}
+
+func (sr *snapshotReader) readHeaderAndDiffs() (wire.CurrencyNet, uint32, *chainhash.Hash, []PoolDiff, error) {
+	header := make([]byte, len(snapshotMagic)+12+chainhash.HashSize)
+	if _, err := io.ReadFull(sr.r, header); err != nil {
+		return 0, 0, nil, nil, fmt.Errorf("failed to read snapshot header: %v", err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return 0, 0, nil, nil, fmt.Errorf("not a stake DB snapshot")
+	}
+	header = header[len(snapshotMagic):]
+	if version := binary.LittleEndian.Uint32(header); version != snapshotVersion {
+		return 0, 0, nil, nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	net := wire.CurrencyNet(binary.LittleEndian.Uint32(header[4:]))
+	height := binary.LittleEndian.Uint32(header[8:])
+	var hash chainhash.Hash
+	copy(hash[:], header[12:])
+
+	numDiffs, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	if numDiffs != uint64(height) {
+		return 0, 0, nil, nil, fmt.Errorf("snapshot has %d ticket pool diffs, expected %d",
+			numDiffs, height)
+	}
+	diffs := make([]PoolDiff, numDiffs)
+	for i := range diffs {
+		if diffs[i].In, err = sr.readHashes(); err != nil {
+			return 0, 0, nil, nil, err
+		}
+		if diffs[i].Out, err = sr.readHashes(); err != nil {
+			return 0, 0, nil, nil, err
+		}
+	}
+	return net, height, &hash, diffs, nil
+}
+
+func (sr *snapshotReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return nil, err
+	}
+	// Guard against allocating for a corrupt length.
+	if n > 1<<24 {
+		return nil, fmt.Errorf("invalid snapshot record length %d", n)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(sr.r, b)
+	return b, err
+}
+
+func (sr *snapshotReader) readHashes() ([]chainhash.Hash, error) {
+	n, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > 1<<16 {
+		return nil, fmt.Errorf("invalid ticket pool diff length %d", n)
+	}
+	hashes := make([]chainhash.Hash, n)
+	for i := range hashes {
+		if _, err = io.ReadFull(sr.r, hashes[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// readBucket reads the records of the bucket up to its snapshotOpEnd into the
+// bucket.
+func (sr *snapshotReader) readBucket(bucket database.Bucket) error {
+	for {
+		op, err := sr.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case snapshotOpKeyValue:
+			k, err := sr.readBytes()
+			if err != nil {
+				return err
+			}
+			v, err := sr.readBytes()
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put(k, v); err != nil {
+				return err
+			}
+		case snapshotOpBucket:
+			name, err := sr.readBytes()
+			if err != nil {
+				return err
+			}
+			nested, err := bucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+			if err = sr.readBucket(nested); err != nil {
+				return err
+			}
+		case snapshotOpEnd:
+			return nil
+		default:
+			return fmt.Errorf("invalid snapshot record op %q", op)
+		}
+	}
+}
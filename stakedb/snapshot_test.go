@@ -0,0 +1,155 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package stakedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/database/v2"
+)
+
+func heightKey(height uint32) []byte {
+	k := make([]byte, 4)
+	binary.LittleEndian.PutUint32(k, height)
+	return k
+}
+
+// dumpBucket flattens the key/value pairs of the bucket and its nested buckets
+// into a map keyed by the bucket path and key.
+func dumpBucket(bucket database.Bucket, path string, m map[string]string) {
+	_ = bucket.ForEach(func(k, v []byte) error {
+		if !bytes.HasPrefix(k, ffldbPrefix) {
+			m[path+"/"+string(k)] = string(v)
+		}
+		return nil
+	})
+	_ = bucket.ForEachBucket(func(k []byte) error {
+		if !bytes.HasPrefix(k, ffldbPrefix) {
+			dumpBucket(bucket.Bucket(k), path+"/"+string(k), m)
+		}
+		return nil
+	})
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stakedb-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := chaincfg.SimNetParams()
+	height := uint32(SnapshotUndoDepth + 2)
+	minUndoHeight := height - SnapshotUndoDepth
+
+	stakeDB, err := database.Create(dbType, filepath.Join(dir, "src"), params.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stakeDB.Close()
+	err = stakeDB.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if err := meta.Put([]byte("stakechainstate"), []byte("state")); err != nil {
+			return err
+		}
+		live, err := meta.CreateBucket([]byte("livetickets"))
+		if err != nil {
+			return err
+		}
+		if err = live.Put([]byte("ticket"), []byte("data")); err != nil {
+			return err
+		}
+		undo, err := meta.CreateBucket([]byte("stakeblockundo"))
+		if err != nil {
+			return err
+		}
+		for _, h := range []uint32{minUndoHeight - 1, minUndoHeight, height} {
+			if err = undo.Put(heightKey(h), []byte{byte(h)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs := make([]PoolDiff, height)
+	diffs[0].In = randomHashSlice(5)
+	diffs[height-1].Out = diffs[0].In[:2]
+	hash := randomHash()
+
+	var buf bytes.Buffer
+	if err = writeSnapshot(&buf, params.Net, height, &hash, diffs, stakeDB); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+
+	// The network must match.
+	importDir := filepath.Join(dir, "dst")
+	_, _, err = ImportSnapshot(bytes.NewReader(snapshot), chaincfg.MainNetParams(), importDir)
+	if err == nil {
+		t.Fatal("expected error importing a simnet snapshot on mainnet")
+	}
+
+	gotHeight, gotHash, err := ImportSnapshot(bytes.NewReader(snapshot), params, importDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHeight != int64(height) || *gotHash != hash {
+		t.Errorf("imported %d (%v), expected %d (%v)", gotHeight, gotHash, height, hash)
+	}
+
+	// Importing over the existing databases fails.
+	if _, _, err = ImportSnapshot(bytes.NewReader(snapshot), params, importDir); err == nil {
+		t.Error("expected error importing over existing databases")
+	}
+
+	imported, err := database.Open(dbType, filepath.Join(importDir, DefaultStakeDbName), params.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer imported.Close()
+	got := make(map[string]string)
+	_ = imported.View(func(dbTx database.Tx) error {
+		dumpBucket(dbTx.Metadata(), "", got)
+		return nil
+	})
+	want := map[string]string{
+		"/stakechainstate":    "state",
+		"/livetickets/ticket": "data",
+		"/stakeblockundo/" + string(heightKey(minUndoHeight)): string([]byte{byte(minUndoHeight)}),
+		"/stakeblockundo/" + string(heightKey(height)):        string([]byte{byte(height)}),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("imported stake DB %q, expected %q", got, want)
+	}
+
+	pool, err := NewTicketPool(importDir, DefaultTicketPoolDbFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if pool.Tip() != int64(height) {
+		t.Fatalf("ticket pool tip %d, expected %d", pool.Tip(), height)
+	}
+	if !reflect.DeepEqual(pool.diffs[0].In, diffs[0].In) ||
+		!reflect.DeepEqual(pool.diffs[height-1].Out, diffs[height-1].Out) {
+		t.Error("imported ticket pool diffs differ")
+	}
+	poolAtTip, err := pool.Pool(int64(height))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poolAtTip) != 3 {
+		t.Errorf("pool size %d at tip, expected 3", len(poolAtTip))
+	}
+}
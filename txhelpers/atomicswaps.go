@@ -0,0 +1,140 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// AtomicSwapContract is the data of an atomic swap contract script. The
+// contract pays to the recipient when redeemed with the secret whose SHA-256
+// hash is SecretHash, or to the refund address after the lock time.
+type AtomicSwapContract struct {
+	ContractAddress  string
+	RecipientAddress string
+	RefundAddress    string
+	SecretHash       [32]byte
+	LockTime         int64
+}
+
+// AtomicSwapSpend is the redemption or refund of an atomic swap contract by a
+// transaction input. The contract script is only revealed by the spend, since
+// the contract output pays to the script hash.
+type AtomicSwapSpend struct {
+	AtomicSwapContract
+	// ContractTx and ContractVout identify the contract output.
+	ContractTx   string
+	ContractVout uint32
+	// Value is the amount in atoms of the contract output.
+	Value int64
+	// Vin is the index of the spending input.
+	Vin uint32
+	// Secret is nil for a refund.
+	Secret   []byte
+	IsRefund bool
+}
+
+// ExtractAtomicSwapContract extracts the data of an atomic swap contract
+// script. A nil contract is returned if the script is not an atomic swap
+// contract.
+func ExtractAtomicSwapContract(contract []byte, params *chaincfg.Params) (*AtomicSwapContract, error) {
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil || pushes == nil {
+		return nil, err
+	}
+
+	contractAddr, err := dcrutil.NewAddressScriptHash(contract, params)
+	if err != nil {
+		return nil, err
+	}
+	recipientAddr, err := dcrutil.NewAddressPubKeyHash(pushes.RecipientHash160[:], params, 0)
+	if err != nil {
+		return nil, err
+	}
+	refundAddr, err := dcrutil.NewAddressPubKeyHash(pushes.RefundHash160[:], params, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AtomicSwapContract{
+		ContractAddress:  contractAddr.Address(),
+		RecipientAddress: recipientAddr.Address(),
+		RefundAddress:    refundAddr.Address(),
+		SecretHash:       pushes.SecretHash,
+		LockTime:         pushes.LockTime,
+	}, nil
+}
+
+// ExtractAtomicSwapSpend extracts the atomic swap contract redeemed or refunded
+// by the signature script of an input. A redemption's signature script is
+// <sig> <pubkey> <secret> OP_TRUE <contract>, and a refund's is <sig> <pubkey>
+// OP_FALSE <contract>. A nil spend is returned if the input does not spend an
+// atomic swap contract.
+func ExtractAtomicSwapSpend(txIn *wire.TxIn, params *chaincfg.Params) (*AtomicSwapSpend, error) {
+	var opcodes []byte
+	var pushes [][]byte
+	tokenizer := txscript.MakeScriptTokenizer(0, txIn.SignatureScript)
+	for tokenizer.Next() {
+		opcodes = append(opcodes, tokenizer.Opcode())
+		pushes = append(pushes, tokenizer.Data())
+	}
+	if tokenizer.Err() != nil {
+		return nil, nil
+	}
+
+	var secret []byte
+	switch n := len(opcodes); {
+	case n == 5 && opcodes[3] == txscript.OP_TRUE && len(pushes[2]) > 0:
+		secret = pushes[2]
+	case n == 4 && opcodes[2] == txscript.OP_FALSE:
+	default:
+		return nil, nil
+	}
+
+	contract, err := ExtractAtomicSwapContract(pushes[len(pushes)-1], params)
+	if err != nil || contract == nil {
+		return nil, err
+	}
+	// A redemption must reveal the secret of the contract.
+	if secret != nil {
+		secretHash := sha256.Sum256(secret)
+		if !bytes.Equal(secretHash[:], contract.SecretHash[:]) {
+			return nil, nil
+		}
+	}
+
+	prevOut := &txIn.PreviousOutPoint
+	return &AtomicSwapSpend{
+		AtomicSwapContract: *contract,
+		ContractTx:         prevOut.Hash.String(),
+		ContractVout:       prevOut.Index,
+		Value:              txIn.ValueIn,
+		Secret:             secret,
+		IsRefund:           secret == nil,
+	}, nil
+}
+
+// MsgTxAtomicSwaps extracts the atomic swap contracts redeemed or refunded by
+// the inputs of a transaction.
+func MsgTxAtomicSwaps(tx *wire.MsgTx, params *chaincfg.Params) ([]*AtomicSwapSpend, error) {
+	var swaps []*AtomicSwapSpend
+	for i, txIn := range tx.TxIn {
+		swap, err := ExtractAtomicSwapSpend(txIn, params)
+		if err != nil {
+			return nil, err
+		}
+		if swap == nil {
+			continue
+		}
+		swap.Vin = uint32(i)
+		swaps = append(swaps, swap)
+	}
+	return swaps, nil
+}
@@ -0,0 +1,87 @@
+package txhelpers
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+func atomicSwapTestContract(t *testing.T, secretHash []byte, lockTime int64) []byte {
+	recipient := make([]byte, 20)
+	refund := make([]byte, 20)
+	refund[0] = 1
+	contract, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_IF).
+		AddOp(txscript.OP_SIZE).AddInt64(32).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).AddData(secretHash).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(recipient).
+		AddOp(txscript.OP_ELSE).
+		AddInt64(lockTime).AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(refund).
+		AddOp(txscript.OP_ENDIF).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("failed to build contract: %v", err)
+	}
+	return contract
+}
+
+func TestMsgTxAtomicSwaps(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	secret := make([]byte, 32)
+	secret[31] = 7
+	secretHash := sha256.Sum256(secret)
+	const lockTime = 1600000000
+	contract := atomicSwapTestContract(t, secretHash[:], lockTime)
+
+	sig := make([]byte, 71)
+	pubKey := make([]byte, 33)
+	redeemScript, _ := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).
+		AddData(secret).AddOp(txscript.OP_TRUE).AddData(contract).Script()
+	refundScript, _ := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).
+		AddOp(txscript.OP_FALSE).AddData(contract).Script()
+	wrongSecret := make([]byte, 32)
+	wrongSecretScript, _ := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).
+		AddData(wrongSecret).AddOp(txscript.OP_TRUE).AddData(contract).Script()
+	p2pkhScript, _ := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).Script()
+
+	tx := wire.NewMsgTx()
+	tx.TxIn = []*wire.TxIn{
+		{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{1}}, SignatureScript: p2pkhScript},
+		{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1}, SignatureScript: redeemScript, ValueIn: 5e8},
+		{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{3}}, SignatureScript: wrongSecretScript},
+		{PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{4}, Index: 2}, SignatureScript: refundScript, ValueIn: 3e8},
+	}
+
+	swaps, err := MsgTxAtomicSwaps(tx, params)
+	if err != nil {
+		t.Fatalf("MsgTxAtomicSwaps: %v", err)
+	}
+	if len(swaps) != 2 {
+		t.Fatalf("expected 2 swaps, got %d", len(swaps))
+	}
+
+	redeem, refund := swaps[0], swaps[1]
+	if redeem.Vin != 1 || redeem.IsRefund || redeem.Value != 5e8 ||
+		redeem.ContractTx != (chainhash.Hash{2}).String() || redeem.ContractVout != 1 {
+		t.Errorf("unexpected redemption %+v", redeem)
+	}
+	if string(redeem.Secret) != string(secret) || redeem.SecretHash != secretHash {
+		t.Errorf("unexpected redemption secret %x / hash %x", redeem.Secret, redeem.SecretHash)
+	}
+	if redeem.LockTime != lockTime {
+		t.Errorf("expected lock time %d, got %d", lockTime, redeem.LockTime)
+	}
+	if refund.Vin != 3 || !refund.IsRefund || refund.Secret != nil || refund.Value != 3e8 {
+		t.Errorf("unexpected refund %+v", refund)
+	}
+	if redeem.ContractAddress != refund.ContractAddress || redeem.ContractAddress == "" ||
+		redeem.RecipientAddress == redeem.RefundAddress {
+		t.Errorf("unexpected addresses %+v", redeem.AtomicSwapContract)
+	}
+}
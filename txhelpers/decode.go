@@ -0,0 +1,64 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"github.com/decred/dcrd/blockchain/stake/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// ScriptClassStakeCommitment is the script class name of the commitment outputs
+// of a ticket purchase, as used by dcrd's decoderawtransaction.
+const ScriptClassStakeCommitment = "sstxcommitment"
+
+// OutputScript describes the script of a transaction output.
+type OutputScript struct {
+	Class     string
+	Addresses []string
+	ReqSigs   int
+	// CommitAmt is the amount committed by a ticket commitment output, and nil
+	// for other outputs.
+	CommitAmt *dcrutil.Amount
+}
+
+// DecodeOutputScript classifies the script of the output of msgTx with index
+// idx, and extracts the addresses it pays, in the manner of dcrd's
+// decoderawtransaction. The commitment outputs of a ticket purchase are
+// classified as ScriptClassStakeCommitment, with the commitment address and
+// amount.
+func DecodeOutputScript(msgTx *wire.MsgTx, idx int, params *chaincfg.Params) *OutputScript {
+	txOut := msgTx.TxOut[idx]
+	if idx%2 != 0 && stake.IsSStx(msgTx) {
+		script := &OutputScript{Class: ScriptClassStakeCommitment}
+		if addr, err := stake.AddrFromSStxPkScrCommitment(txOut.PkScript, params); err == nil {
+			script.Addresses = []string{addr.Address()}
+		}
+		if amt, err := stake.AmountFromSStxPkScrCommitment(txOut.PkScript); err == nil {
+			script.CommitAmt = &amt
+		}
+		return script
+	}
+
+	class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(txOut.Version,
+		txOut.PkScript, params)
+	script := &OutputScript{
+		Class:   class.String(),
+		ReqSigs: reqSigs,
+	}
+	for _, addr := range addrs {
+		script.Addresses = append(script.Addresses, addr.Address())
+	}
+	return script
+}
+
+// IsGeneratedInput indicates if the transaction input creates new coins rather
+// than spending a previous output, as with the inputs of coinbase, stakebase
+// and treasurybase transactions.
+func IsGeneratedInput(txIn *wire.TxIn) bool {
+	return txIn.PreviousOutPoint.Hash == chainhash.Hash{}
+}
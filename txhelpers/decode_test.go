@@ -0,0 +1,78 @@
+package txhelpers
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestDecodeOutputScript(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	block, sstxs := LoadTestBlockAndSSTX(t)
+
+	for _, tx := range sstxs {
+		msgTx := tx.MsgTx()
+		for i := range msgTx.TxOut {
+			script := DecodeOutputScript(msgTx, i, params)
+			switch {
+			case i == 0:
+				if script.Class != "stakesubmission" {
+					t.Errorf("ticket %v output %d class %q, expected stakesubmission",
+						tx.Hash(), i, script.Class)
+				}
+				if len(script.Addresses) != 1 {
+					t.Errorf("ticket %v output %d has %d addresses, expected 1",
+						tx.Hash(), i, len(script.Addresses))
+				}
+			case i%2 != 0:
+				if script.Class != ScriptClassStakeCommitment {
+					t.Errorf("ticket %v output %d class %q, expected %s",
+						tx.Hash(), i, script.Class, ScriptClassStakeCommitment)
+				}
+				if script.CommitAmt == nil || len(script.Addresses) != 1 {
+					t.Errorf("ticket %v output %d commitment not decoded", tx.Hash(), i)
+				}
+			default:
+				if script.Class != "sstxchange" {
+					t.Errorf("ticket %v output %d class %q, expected sstxchange",
+						tx.Hash(), i, script.Class)
+				}
+				if script.CommitAmt != nil {
+					t.Errorf("ticket %v change output %d has a commitment amount",
+						tx.Hash(), i)
+				}
+			}
+		}
+	}
+
+	coinbase := block.Transactions()[0].MsgTx()
+	for i := range coinbase.TxOut {
+		script := DecodeOutputScript(coinbase, i, params)
+		if script.CommitAmt != nil {
+			t.Errorf("coinbase output %d has a commitment amount", i)
+		}
+	}
+}
+
+func TestIsGeneratedInput(t *testing.T) {
+	block, sstxs := LoadTestBlockAndSSTX(t)
+
+	coinbase := block.Transactions()[0].MsgTx()
+	if !IsGeneratedInput(coinbase.TxIn[0]) {
+		t.Errorf("coinbase input not identified as generated")
+	}
+
+	for _, tx := range sstxs {
+		for i, txIn := range tx.MsgTx().TxIn {
+			if IsGeneratedInput(txIn) {
+				t.Errorf("ticket %v input %d identified as generated", tx.Hash(), i)
+			}
+		}
+	}
+
+	if IsGeneratedInput(wire.NewTxIn(wire.NewOutPoint(&block.MsgBlock().Header.PrevBlock,
+		0, wire.TxTreeRegular), 0, nil)) {
+		t.Errorf("input spending a previous output identified as generated")
+	}
+}
@@ -0,0 +1,90 @@
+package txhelpers
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/standalone"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// TxInclusionProof is a merkle proof that a transaction is committed to by a
+// block header. The proof verifies the transaction hash at LeafIndex against
+// Root with standalone.VerifyInclusionProof.
+type TxInclusionProof struct {
+	// Root is the header field that the proof is verified against. This is
+	// the header's merkle root, unless the header predates the combined
+	// transaction tree commitment of DCP0005, in which case it is the stake
+	// root for stake tree transactions.
+	Root      chainhash.Hash
+	Tree      int8
+	LeafIndex uint32
+	Proof     []chainhash.Hash
+}
+
+// txTreeLeaves returns the transaction hashes of a transaction tree, which are
+// the leaves of its merkle tree.
+func txTreeLeaves(txns []*wire.MsgTx) []chainhash.Hash {
+	leaves := make([]chainhash.Hash, len(txns))
+	for i, tx := range txns {
+		leaves[i] = tx.TxHash()
+	}
+	return leaves
+}
+
+// GenerateTxInclusionProof creates the inclusion proof for the transaction in
+// the block. When the header commits to the combined regular and stake
+// transaction trees, the proof for the transaction within its tree is extended
+// with the root of the other tree.
+func GenerateTxInclusionProof(block *wire.MsgBlock, txHash *chainhash.Hash) (*TxInclusionProof, error) {
+	regular := txTreeLeaves(block.Transactions)
+	stake := txTreeLeaves(block.STransactions)
+
+	find := func(leaves []chainhash.Hash) int {
+		for i := range leaves {
+			if leaves[i] == *txHash {
+				return i
+			}
+		}
+		return -1
+	}
+	tree, leaves := wire.TxTreeRegular, regular
+	idx := find(regular)
+	if idx == -1 {
+		tree, leaves = wire.TxTreeStake, stake
+		if idx = find(stake); idx == -1 {
+			return nil, fmt.Errorf("transaction %v not found in block %v",
+				txHash, block.BlockHash())
+		}
+	}
+
+	ip := &TxInclusionProof{
+		Tree:      tree,
+		LeafIndex: uint32(idx),
+		Proof:     standalone.GenerateInclusionProof(leaves, uint32(idx)),
+	}
+
+	// Prior to DCP0005, the merkle root commits to the regular tree alone,
+	// and the stake root to the stake tree.
+	regularRoot := standalone.CalcMerkleRoot(regular)
+	if block.Header.MerkleRoot == regularRoot {
+		ip.Root = block.Header.MerkleRoot
+		if tree == wire.TxTreeStake {
+			ip.Root = block.Header.StakeRoot
+		}
+		return ip, nil
+	}
+
+	// The combined merkle root is the root of a two leaf tree of the regular
+	// and stake tree roots, so the tree root's sibling is the final proof
+	// hash, and the leaf index gains a bit for the side of the combined tree.
+	stakeRoot := standalone.CalcMerkleRoot(stake)
+	if tree == wire.TxTreeRegular {
+		ip.Proof = append(ip.Proof, stakeRoot)
+	} else {
+		ip.Proof = append(ip.Proof, regularRoot)
+		ip.LeafIndex |= 1 << uint(len(ip.Proof)-1)
+	}
+	ip.Root = block.Header.MerkleRoot
+	return ip, nil
+}
@@ -0,0 +1,50 @@
+package txhelpers
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/blockchain/standalone"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestGenerateTxInclusionProof(t *testing.T) {
+	block, _ := LoadTestBlockAndSSTX(t)
+	msgBlock := block.MsgBlock()
+
+	// The test block predates DCP0005, so its stake transactions are committed
+	// to by the stake root. Check the proofs against a header with the
+	// combined merkle root too.
+	combined := *msgBlock
+	combined.Header.MerkleRoot = standalone.CalcCombinedTxTreeMerkleRoot(
+		msgBlock.Transactions, msgBlock.STransactions)
+
+	for _, blk := range []*wire.MsgBlock{msgBlock, &combined} {
+		isCombined := blk == &combined
+		for _, txns := range [][]*wire.MsgTx{blk.Transactions, blk.STransactions} {
+			for i, tx := range txns {
+				txHash := tx.TxHash()
+				ip, err := GenerateTxInclusionProof(blk, &txHash)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantRoot := blk.Header.MerkleRoot
+				if !isCombined && ip.Tree == wire.TxTreeStake {
+					wantRoot = blk.Header.StakeRoot
+				}
+				if ip.Root != wantRoot {
+					t.Errorf("tx %d (tree %d, combined %v): got root %v, want %v",
+						i, ip.Tree, isCombined, ip.Root, wantRoot)
+				}
+				if !standalone.VerifyInclusionProof(&ip.Root, &txHash, ip.LeafIndex, ip.Proof) {
+					t.Errorf("tx %d (tree %d, combined %v): proof does not verify",
+						i, ip.Tree, isCombined)
+				}
+			}
+		}
+	}
+
+	if _, err := GenerateTxInclusionProof(msgBlock, &chainhash.Hash{1}); err == nil {
+		t.Errorf("expected an error for a transaction not in the block")
+	}
+}
@@ -82,3 +82,78 @@ func RewardsAtBlock(blockIdx int64, votes uint16, p *chaincfg.Params) (work, sta
 	tax = subsidyCache.CalcTreasurySubsidy(blockIdx, votes)
 	return
 }
+
+// SubsidyRange is a range of block heights over which the block subsidy is
+// constant, assuming that every block has the maximum number of votes. Work,
+// Stake, and Treasury are the subsidies of each block, where Stake is the
+// total of all votes. Supply is the total coin supply once the block at
+// EndHeight is mined.
+type SubsidyRange struct {
+	StartHeight int64
+	EndHeight   int64
+	Work        int64
+	Stake       int64
+	Treasury    int64
+	Supply      int64
+}
+
+// SubsidySchedule computes the block subsidy of the heights from startHeight to
+// endHeight, inclusive, assuming that every block has the maximum number of
+// votes. The heights are grouped into ranges of constant subsidy, which are the
+// subsidy reduction intervals, with the first interval split at the height at
+// which voting begins. Block 1 is a range of its own for the initial coin
+// distribution. The ranges are clipped to the requested heights, and the
+// subsidy schedule ends with a range of zero subsidy up to endHeight.
+func SubsidySchedule(params *chaincfg.Params, startHeight, endHeight int64) []SubsidyRange {
+	votesPerBlock := params.VotesPerBlock()
+	stakeValidationHeight := params.StakeValidationBeginHeight()
+	reductionInterval := params.SubsidyReductionIntervalBlocks()
+	subsidyCache := standalone.NewSubsidyCache(params)
+
+	var schedule []SubsidyRange
+	var supply int64
+	for height := int64(0); height <= endHeight; {
+		r := SubsidyRange{StartHeight: height}
+		switch {
+		case height <= 1:
+			// Block 0 does not produce any subsidy, and block 1 is the
+			// initial coin distribution.
+			r.EndHeight = height
+			if height == 1 {
+				r.Work = params.BlockOneSubsidy()
+			}
+		default:
+			r.EndHeight = (height/reductionInterval+1)*reductionInterval - 1
+			if height < stakeValidationHeight && r.EndHeight >= stakeValidationHeight {
+				r.EndHeight = stakeValidationHeight - 1
+			}
+			r.Work = subsidyCache.CalcWorkSubsidy(height, votesPerBlock)
+			r.Treasury = subsidyCache.CalcTreasurySubsidy(height, votesPerBlock)
+			// Votes do not produce subsidy until voting begins.
+			if height >= stakeValidationHeight {
+				r.Stake = subsidyCache.CalcStakeVoteSubsidy(height) * int64(votesPerBlock)
+			}
+		}
+
+		perBlock := r.Work + r.Stake + r.Treasury
+		exhausted := height >= stakeValidationHeight && perBlock == 0
+		if exhausted || r.EndHeight > endHeight {
+			r.EndHeight = endHeight
+		}
+		supply += perBlock * (r.EndHeight - height + 1)
+		r.Supply = supply
+
+		if r.EndHeight >= startHeight {
+			if r.StartHeight < startHeight {
+				// Clip the range, with the supply as of its end unchanged.
+				r.StartHeight = startHeight
+			}
+			schedule = append(schedule, r)
+		}
+		if exhausted {
+			break
+		}
+		height = r.EndHeight + 1
+	}
+	return schedule
+}
@@ -5,6 +5,7 @@
 package txhelpers
 
 import (
+	"math"
 	"testing"
 
 	"github.com/decred/dcrd/chaincfg/v2"
@@ -70,3 +71,35 @@ func BenchmarkUltimateSubsidy(b *testing.B) {
 			totalSubsidy, totalSubsidy2)
 	}
 }
+
+func TestSubsidySchedule(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	svh := params.StakeValidationBeginHeight()
+
+	// The supply at the end of the full schedule is the ultimate subsidy.
+	schedule := SubsidySchedule(params, 0, math.MaxInt64)
+	last := schedule[len(schedule)-1]
+	if last.EndHeight != math.MaxInt64 || last.Work+last.Stake+last.Treasury != 0 {
+		t.Errorf("Schedule does not end with zero subsidy: %+v", last)
+	}
+	if ultimate := UltimateSubsidy(params); last.Supply != ultimate {
+		t.Errorf("Bad final supply; want %d, got %d", ultimate, last.Supply)
+	}
+
+	// A clipped range straddling the height at which voting begins.
+	schedule = SubsidySchedule(params, svh-10, svh+10)
+	if len(schedule) != 2 {
+		t.Fatalf("Expected 2 ranges, got %d", len(schedule))
+	}
+	pre, post := schedule[0], schedule[1]
+	if pre.StartHeight != svh-10 || pre.EndHeight != svh-1 || pre.Stake != 0 {
+		t.Errorf("Bad range before voting: %+v", pre)
+	}
+	if post.StartHeight != svh || post.EndHeight != svh+10 || post.Stake == 0 {
+		t.Errorf("Bad range after voting begins: %+v", post)
+	}
+	wantSupply := pre.Supply + 11*(post.Work+post.Stake+post.Treasury)
+	if post.Supply != wantSupply {
+		t.Errorf("Bad supply; want %d, got %d", wantSupply, post.Supply)
+	}
+}
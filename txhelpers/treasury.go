@@ -0,0 +1,236 @@
+package txhelpers
+
+import (
+	"encoding/binary"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+// Treasury transactions, introduced by DCP0006, are identified by the
+// transaction version and the treasury opcodes in their scripts.
+const (
+	// TxVersionTreasury is the transaction version of treasury transactions.
+	TxVersionTreasury = 3
+
+	opTAdd      = 0xc1
+	opTSpend    = 0xc2
+	opTGen      = 0xc3
+	opReturn    = 0x6a
+	opData32    = 0x20
+	opData12    = 0x0c
+	opPushData1 = 0x4c
+	opPushData2 = 0x4d
+
+	// tspendSigScriptLen is the length of a TSpend signature script:
+	// OP_DATA_64 <signature> OP_DATA_33 <pubkey> OP_TSPEND.
+	tspendSigScriptLen = 1 + 64 + 1 + 33 + 1
+
+	// maxTSpendVotes is the most TSpends that may be voted on by one vote.
+	maxTSpendVotes = 7
+)
+
+// TreasuryTxType indicates the type of a treasury transaction.
+type TreasuryTxType int
+
+// These are the treasury transaction types.
+const (
+	TreasuryTxNone TreasuryTxType = iota
+	TreasuryTxAdd
+	TreasuryTxSpend
+	TreasuryTxBase
+)
+
+// String returns the name of the treasury transaction type.
+func (t TreasuryTxType) String() string {
+	switch t {
+	case TreasuryTxAdd:
+		return "TAdd"
+	case TreasuryTxSpend:
+		return "TSpend"
+	case TreasuryTxBase:
+		return "Treasurybase"
+	default:
+		return "None"
+	}
+}
+
+// DetermineTreasuryTxType identifies a treasury add (TAdd), treasury spend
+// (TSpend), or treasurybase transaction. TreasuryTxNone is returned for all
+// other transactions.
+func DetermineTreasuryTxType(tx *wire.MsgTx) TreasuryTxType {
+	if tx.Version != TxVersionTreasury || len(tx.TxIn) == 0 || len(tx.TxOut) == 0 {
+		return TreasuryTxNone
+	}
+
+	switch {
+	case isTreasurybase(tx):
+		return TreasuryTxBase
+	case isTSpend(tx):
+		return TreasuryTxSpend
+	case len(tx.TxOut) <= 2 && isTAddScript(tx.TxOut[0].PkScript):
+		return TreasuryTxAdd
+	}
+	return TreasuryTxNone
+}
+
+func isTAddScript(script []byte) bool {
+	return len(script) == 1 && script[0] == opTAdd
+}
+
+// isTreasurybase checks for a single null input, an OP_TADD output, and an
+// OP_RETURN output with the 4-byte height and 8 random bytes.
+func isTreasurybase(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) != 1 || len(tx.TxOut) != 2 {
+		return false
+	}
+	prevOut := &tx.TxIn[0].PreviousOutPoint
+	if prevOut.Index != wire.MaxPrevOutIndex || prevOut.Hash != (chainhash.Hash{}) {
+		return false
+	}
+	if !isTAddScript(tx.TxOut[0].PkScript) {
+		return false
+	}
+	script := tx.TxOut[1].PkScript
+	return len(script) == 2+12 && script[0] == opReturn && script[1] == opData12
+}
+
+// isTSpend checks for a single input signed by a Pi key with OP_TSPEND, an
+// OP_RETURN output committing to 32 bytes, and OP_TGEN tagged payouts.
+func isTSpend(tx *wire.MsgTx) bool {
+	if len(tx.TxIn) != 1 || len(tx.TxOut) < 2 {
+		return false
+	}
+	sigScript := tx.TxIn[0].SignatureScript
+	if len(sigScript) != tspendSigScriptLen || sigScript[len(sigScript)-1] != opTSpend {
+		return false
+	}
+	script := tx.TxOut[0].PkScript
+	if len(script) != 2+32 || script[0] != opReturn || script[1] != opData32 {
+		return false
+	}
+	for _, txOut := range tx.TxOut[1:] {
+		if len(txOut.PkScript) == 0 || txOut.PkScript[0] != opTGen {
+			return false
+		}
+	}
+	return true
+}
+
+// TreasuryTxValue is the amount, in atoms, that a treasury transaction adds to
+// (positive) or spends from (negative) the treasury. For a TSpend, this is the
+// input amount, which includes the fee.
+func TreasuryTxValue(tx *wire.MsgTx, txType TreasuryTxType) int64 {
+	switch txType {
+	case TreasuryTxAdd, TreasuryTxBase:
+		return tx.TxOut[0].Value
+	case TreasuryTxSpend:
+		return -tx.TxIn[0].ValueIn
+	}
+	return 0
+}
+
+// TGenPayoutAddress returns the address paid by an OP_TGEN tagged TSpend output
+// script, or an empty string if the tagged script is not a standard P2PKH or
+// P2SH script.
+func TGenPayoutAddress(pkScript []byte, params *chaincfg.Params) string {
+	if len(pkScript) < 2 || pkScript[0] != opTGen {
+		return ""
+	}
+	class, addrs, _, err := txscript.ExtractPkScriptAddrs(0, pkScript[1:], params)
+	if err != nil || len(addrs) != 1 ||
+		(class != txscript.PubKeyHashTy && class != txscript.ScriptHashTy) {
+		return ""
+	}
+	return addrs[0].Address()
+}
+
+// TSpendVoteChoice is a stakeholder's choice on a TSpend.
+type TSpendVoteChoice uint8
+
+// These are the TSpend vote choices.
+const (
+	TSpendVoteYes TSpendVoteChoice = 0x01
+	TSpendVoteNo  TSpendVoteChoice = 0x02
+)
+
+// String returns "yes" or "no", or "invalid" for an unknown choice.
+func (c TSpendVoteChoice) String() string {
+	switch c {
+	case TSpendVoteYes:
+		return "yes"
+	case TSpendVoteNo:
+		return "no"
+	default:
+		return "invalid"
+	}
+}
+
+// TSpendVote is a vote on a TSpend, cast in a vote transaction.
+type TSpendVote struct {
+	TSpend chainhash.Hash
+	Choice TSpendVoteChoice
+}
+
+// TSpendVotes extracts the TSpend votes from the last output of a vote, which
+// is an OP_RETURN pushing 'T','V' followed by up to seven 33-byte
+// (TSpend hash, choice) pairs. Nil is returned if the vote has no valid TSpend
+// votes.
+func TSpendVotes(vote *wire.MsgTx) []TSpendVote {
+	if len(vote.TxOut) == 0 {
+		return nil
+	}
+	data := opReturnData(vote.TxOut[len(vote.TxOut)-1].PkScript)
+	if len(data) < 2 || data[0] != 'T' || data[1] != 'V' {
+		return nil
+	}
+	data = data[2:]
+	const entryLen = chainhash.HashSize + 1
+	if len(data) == 0 || len(data)%entryLen != 0 || len(data)/entryLen > maxTSpendVotes {
+		return nil
+	}
+
+	votes := make([]TSpendVote, 0, len(data)/entryLen)
+	for ; len(data) > 0; data = data[entryLen:] {
+		var v TSpendVote
+		copy(v.TSpend[:], data[:chainhash.HashSize])
+		v.Choice = TSpendVoteChoice(data[chainhash.HashSize])
+		if v.Choice != TSpendVoteYes && v.Choice != TSpendVoteNo {
+			return nil
+		}
+		votes = append(votes, v)
+	}
+	return votes
+}
+
+// opReturnData returns the data pushed by a script consisting of OP_RETURN and
+// a single data push, or nil for any other script.
+func opReturnData(script []byte) []byte {
+	if len(script) < 2 || script[0] != opReturn {
+		return nil
+	}
+	op, script := script[1], script[2:]
+	var n int
+	switch {
+	case op >= 0x01 && op <= 0x4b:
+		n = int(op)
+	case op == opPushData1:
+		if len(script) < 1 {
+			return nil
+		}
+		n, script = int(script[0]), script[1:]
+	case op == opPushData2:
+		if len(script) < 2 {
+			return nil
+		}
+		n, script = int(binary.LittleEndian.Uint16(script)), script[2:]
+	default:
+		return nil
+	}
+	if len(script) != n {
+		return nil
+	}
+	return script
+}
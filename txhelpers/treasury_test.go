@@ -0,0 +1,98 @@
+package txhelpers
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+func treasuryTestTx(version uint16, ins []*wire.TxIn, outs ...*wire.TxOut) *wire.MsgTx {
+	tx := wire.NewMsgTx()
+	tx.Version = version
+	tx.TxIn = ins
+	tx.TxOut = outs
+	return tx
+}
+
+func TestDetermineTreasuryTxType(t *testing.T) {
+	regularIn := &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{1}},
+		ValueIn:          2e8,
+	}
+	nullIn := &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+	}
+	tspendSig := append(append([]byte{0x40}, make([]byte, 64)...), 0x21)
+	tspendSig = append(append(tspendSig, make([]byte, 33)...), opTSpend)
+	tspendIn := &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		ValueIn:          5e8,
+		SignatureScript:  tspendSig,
+	}
+
+	tadd := &wire.TxOut{Value: 1e8, PkScript: []byte{opTAdd}}
+	change := &wire.TxOut{Value: 9e7, PkScript: []byte{0xbd, 0x76}}
+	tbaseData := &wire.TxOut{PkScript: append([]byte{opReturn, opData12}, make([]byte, 12)...)}
+	tspendData := &wire.TxOut{PkScript: append([]byte{opReturn, opData32}, make([]byte, 32)...)}
+	payout := &wire.TxOut{Value: 4e8, PkScript: []byte{opTGen, 0x76}}
+	p2pkh := &wire.TxOut{Value: 1e8, PkScript: []byte{0x76, 0xa9}}
+
+	tests := []struct {
+		name      string
+		tx        *wire.MsgTx
+		wantType  TreasuryTxType
+		wantValue int64
+	}{
+		{"tadd", treasuryTestTx(3, []*wire.TxIn{regularIn}, tadd), TreasuryTxAdd, 1e8},
+		{"tadd with change", treasuryTestTx(3, []*wire.TxIn{regularIn}, tadd, change), TreasuryTxAdd, 1e8},
+		{"treasurybase", treasuryTestTx(3, []*wire.TxIn{nullIn}, tadd, tbaseData), TreasuryTxBase, 1e8},
+		{"tspend", treasuryTestTx(3, []*wire.TxIn{tspendIn}, tspendData, payout), TreasuryTxSpend, -5e8},
+		{"tspend untagged payout", treasuryTestTx(3, []*wire.TxIn{tspendIn}, tspendData, p2pkh), TreasuryTxNone, 0},
+		{"tadd wrong version", treasuryTestTx(1, []*wire.TxIn{regularIn}, tadd), TreasuryTxNone, 0},
+		{"regular", treasuryTestTx(3, []*wire.TxIn{regularIn}, p2pkh), TreasuryTxNone, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txType := DetermineTreasuryTxType(tt.tx)
+			if txType != tt.wantType {
+				t.Fatalf("got type %v, want %v", txType, tt.wantType)
+			}
+			if value := TreasuryTxValue(tt.tx, txType); value != tt.wantValue {
+				t.Errorf("got value %d, want %d", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestTSpendVotes(t *testing.T) {
+	tspend1, tspend2 := chainhash.Hash{1}, chainhash.Hash{2}
+	payload := []byte{'T', 'V'}
+	payload = append(append(payload, tspend1[:]...), byte(TSpendVoteYes))
+	payload = append(append(payload, tspend2[:]...), byte(TSpendVoteNo))
+	voteScript := append([]byte{opReturn, opPushData1, byte(len(payload))}, payload...)
+
+	vote := wire.NewMsgTx()
+	vote.AddTxOut(&wire.TxOut{PkScript: []byte{opReturn, 0x24}})
+	vote.AddTxOut(&wire.TxOut{PkScript: voteScript})
+
+	votes := TSpendVotes(vote)
+	if len(votes) != 2 {
+		t.Fatalf("got %d votes, want 2", len(votes))
+	}
+	if votes[0].TSpend != tspend1 || votes[0].Choice != TSpendVoteYes {
+		t.Errorf("unexpected first vote %v %v", votes[0].TSpend, votes[0].Choice)
+	}
+	if votes[1].TSpend != tspend2 || votes[1].Choice != TSpendVoteNo {
+		t.Errorf("unexpected second vote %v %v", votes[1].TSpend, votes[1].Choice)
+	}
+
+	// An invalid choice invalidates all of the votes.
+	bad := append([]byte(nil), voteScript...)
+	bad[len(bad)-1] = 0x03
+	vote.TxOut[1].PkScript = bad
+	if votes := TSpendVotes(vote); votes != nil {
+		t.Errorf("expected no votes with an invalid choice, got %d", len(votes))
+	}
+}
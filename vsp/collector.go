@@ -0,0 +1,199 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package vsp collects the statistics of voting service providers (VSPs) from
+// the vspinfo API of their vspd instances.
+package vsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+// requestTimeout limits the time taken by each vspinfo request.
+const requestTimeout = 10 * time.Second
+
+// vspInfo is the subset of the vspd /api/v3/vspinfo response used by the
+// collector. Older vspd versions report missed and expired tickets together as
+// revoked tickets.
+type vspInfo struct {
+	FeePercentage float64 `json:"feepercentage"`
+	VspClosed     bool    `json:"vspclosed"`
+	VspdVersion   string  `json:"vspdversion"`
+	Voting        int64   `json:"voting"`
+	Voted         int64   `json:"voted"`
+	Revoked       int64   `json:"revoked"`
+	Expired       int64   `json:"expired"`
+	Missed        int64   `json:"missed"`
+}
+
+// missed is the number of the VSP's tickets that missed their vote or expired.
+func (vi *vspInfo) missed() int64 {
+	if vi.Expired+vi.Missed > 0 {
+		return vi.Expired + vi.Missed
+	}
+	return vi.Revoked
+}
+
+// Store is the storage for the VSP statistics collected by a Collector.
+type Store interface {
+	StoreVSPStats(stats *dbtypes.VSPStats) error
+	VSPs() ([]*dbtypes.VSPStats, error)
+}
+
+// Collector polls the vspinfo API of each VSP, storing a snapshot of the VSP's
+// statistics for each poll. The status change handler, if set, is called when
+// a VSP becomes reachable or unreachable, opens or closes, or changes its vspd
+// version or fee.
+type Collector struct {
+	urls     []string
+	store    Store
+	client   *http.Client
+	onChange func(*dbtypes.VSPStats)
+
+	mtx  sync.Mutex
+	last map[string]*dbtypes.VSPStats
+}
+
+// NewCollector creates a Collector for the VSPs with the given base URLs (e.g.
+// https://vsp.example.com). The last stored statistics of each VSP are loaded
+// from the Store so that status changes are detected across restarts.
+func NewCollector(urls []string, store Store, onChange func(*dbtypes.VSPStats)) (*Collector, error) {
+	c := &Collector{
+		store:    store,
+		client:   &http.Client{Timeout: requestTimeout},
+		onChange: onChange,
+		last:     make(map[string]*dbtypes.VSPStats, len(urls)),
+	}
+	for _, u := range urls {
+		c.urls = append(c.urls, strings.TrimRight(u, "/"))
+	}
+
+	stored, err := store.VSPs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load VSP statistics: %v", err)
+	}
+	for _, stats := range stored {
+		c.last[stats.VSP] = stats
+	}
+	return c, nil
+}
+
+// Run polls each VSP immediately, and then at the given interval until the
+// context is canceled.
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.pollAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollAll polls the VSPs concurrently, waiting for all of the polls to finish.
+func (c *Collector) pollAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, u := range c.urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			c.poll(ctx, u)
+		}(u)
+	}
+	wg.Wait()
+}
+
+// poll retrieves and stores the statistics of the VSP, calling the status
+// change handler if the VSP's status changed since the last poll.
+func (c *Collector) poll(ctx context.Context, u string) {
+	stats := &dbtypes.VSPStats{
+		VSP:  u,
+		Time: dbtypes.NewTimeDef(time.Now()),
+	}
+
+	c.mtx.Lock()
+	last := c.last[u]
+	c.mtx.Unlock()
+
+	info, err := c.vspInfo(ctx, u)
+	switch {
+	case err == nil:
+		stats.Reachable = true
+		stats.Closed = info.VspClosed
+		stats.VspdVersion = info.VspdVersion
+		stats.FeePercentage = info.FeePercentage
+		stats.Live = info.Voting
+		stats.Voted = info.Voted
+		stats.Missed = info.missed()
+	case ctx.Err() != nil:
+		return
+	default:
+		log.Warnf("Failed to retrieve statistics of VSP %s: %v", u, err)
+		if last != nil {
+			stats.Closed = last.Closed
+			stats.VspdVersion = last.VspdVersion
+			stats.FeePercentage = last.FeePercentage
+			stats.Live = last.Live
+			stats.Voted = last.Voted
+			stats.Missed = last.Missed
+		}
+	}
+
+	if err = c.store.StoreVSPStats(stats); err != nil {
+		log.Errorf("Failed to store statistics of VSP %s: %v", u, err)
+	}
+
+	c.mtx.Lock()
+	c.last[u] = stats
+	c.mtx.Unlock()
+
+	if c.onChange != nil && statusChanged(last, stats) {
+		log.Infof("VSP %s status changed (reachable: %v, closed: %v, vspd %s).",
+			u, stats.Reachable, stats.Closed, stats.VspdVersion)
+		c.onChange(stats)
+	}
+}
+
+// statusChanged checks if the VSP's reachability, closure, vspd version or fee
+// changed. The first statistics of a VSP are a change only if it is reachable.
+func statusChanged(last, stats *dbtypes.VSPStats) bool {
+	if last == nil {
+		return stats.Reachable
+	}
+	return last.Reachable != stats.Reachable || last.Closed != stats.Closed ||
+		last.VspdVersion != stats.VspdVersion ||
+		last.FeePercentage != stats.FeePercentage
+}
+
+// vspInfo requests the vspinfo of the VSP at the base URL u.
+func (c *Collector) vspInfo(ctx context.Context, u string) (*vspInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, u+"/api/v3/vspinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	info := new(vspInfo)
+	if err = json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("failed to decode vspinfo: %v", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package vsp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+type memStore struct {
+	stored []*dbtypes.VSPStats
+}
+
+func (s *memStore) StoreVSPStats(stats *dbtypes.VSPStats) error {
+	s.stored = append(s.stored, stats)
+	return nil
+}
+
+func (s *memStore) VSPs() ([]*dbtypes.VSPStats, error) {
+	return nil, nil
+}
+
+func TestCollectorPoll(t *testing.T) {
+	var closed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/vspinfo" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"feepercentage":2,"vspclosed":%v,"vspdversion":"1.1.0",`+
+			`"voting":10,"voted":20,"revoked":3}`, atomic.LoadInt32(&closed) == 1)
+	}))
+
+	store := new(memStore)
+	var changes []*dbtypes.VSPStats
+	c, err := NewCollector([]string{srv.URL + "/"}, store, func(stats *dbtypes.VSPStats) {
+		changes = append(changes, stats)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	c.pollAll(ctx)
+	if len(store.stored) != 1 || len(changes) != 1 {
+		t.Fatalf("got %d stored and %d changes, want 1 and 1", len(store.stored), len(changes))
+	}
+	stats := store.stored[0]
+	if stats.VSP != srv.URL || !stats.Reachable || stats.Live != 10 ||
+		stats.Voted != 20 || stats.Missed != 3 || stats.FeePercentage != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// An unchanged status is stored but not signaled.
+	c.pollAll(ctx)
+	if len(store.stored) != 2 || len(changes) != 1 {
+		t.Fatalf("got %d stored and %d changes, want 2 and 1", len(store.stored), len(changes))
+	}
+
+	atomic.StoreInt32(&closed, 1)
+	c.pollAll(ctx)
+	if len(changes) != 2 || !changes[1].Closed {
+		t.Fatalf("closing the VSP was not signaled")
+	}
+
+	// An unreachable VSP keeps its last ticket counts.
+	srv.Close()
+	c.pollAll(ctx)
+	if len(changes) != 3 {
+		t.Fatalf("unreachable VSP was not signaled")
+	}
+	if stats = changes[2]; stats.Reachable || !stats.Closed || stats.Live != 10 {
+		t.Errorf("unexpected stats of unreachable VSP %+v", stats)
+	}
+}
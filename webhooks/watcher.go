@@ -0,0 +1,387 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+// Package webhooks notifies the callback URLs registered for an address of the
+// mempool and confirmed transactions involving the address. Each notification
+// is a JSON POST request signed with the registration's secret.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrutil/v2"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/blockdata/v5"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+	"github.com/decred/dcrdata/txhelpers/v4"
+)
+
+const (
+	// SignatureHeader is the HTTP header of a notification with the hex encoded
+	// HMAC-SHA256 of the request body, keyed by the registration's secret.
+	SignatureHeader = "X-Dcrdata-Signature"
+
+	// requestTimeout limits the time taken by each notification request.
+	requestTimeout = 10 * time.Second
+
+	// maxAttempts is the number of times delivery of a notification is
+	// attempted, with the delay between attempts doubling from
+	// initialRetryDelay.
+	maxAttempts       = 6
+	initialRetryDelay = 5 * time.Second
+
+	// queueSize is the number of notifications that may be waiting for
+	// delivery. Notifications are dropped when the queue is full.
+	queueSize = 1024
+
+	// numWorkers is the number of concurrent notification requests.
+	numWorkers = 4
+
+	// maxWatchesPerAddress limits the registrations for an address.
+	maxWatchesPerAddress = 16
+)
+
+// Notification statuses.
+const (
+	StatusMempool   = "mempool"
+	StatusConfirmed = "confirmed"
+)
+
+// Notification is the body of the request to a registration's callback URL.
+// Received is the amount paid to the address by the transaction's outputs, and
+// Sent is the amount spent from the address by its inputs, both in DCR.
+type Notification struct {
+	WatchID     int64   `json:"watch_id"`
+	Address     string  `json:"address"`
+	TxID        string  `json:"txid"`
+	Status      string  `json:"status"`
+	BlockHash   string  `json:"block_hash,omitempty"`
+	BlockHeight int64   `json:"block_height,omitempty"`
+	Received    float64 `json:"received"`
+	Sent        float64 `json:"sent"`
+	Time        int64   `json:"time"`
+}
+
+// Store is the storage for the address watch registrations.
+type Store interface {
+	StoreAddressWatch(watch *dbtypes.AddressWatch) error
+	AddressWatches() ([]*dbtypes.AddressWatch, error)
+	DeleteAddressWatch(id int64, secret string) error
+}
+
+// delivery is a notification to be POSTed to a callback URL.
+type delivery struct {
+	url      string
+	secret   string
+	body     []byte
+	attempts int
+}
+
+// Watcher notifies the registered callback URLs of the transactions involving
+// the watched addresses. The addresses spent by a transaction's inputs are
+// determined from the previous outputs, which are retrieved with the
+// RawTransactionGetter (e.g. dcrd's RPC client).
+type Watcher struct {
+	params     *chaincfg.Params
+	store      Store
+	prevTxns   txhelpers.RawTransactionGetter
+	client     *http.Client
+	queue      chan *delivery
+	retryDelay time.Duration
+
+	mtx     sync.RWMutex
+	watches map[string][]*dbtypes.AddressWatch
+}
+
+// NewWatcher creates a Watcher, loading the registrations from the Store. Run
+// must be called to deliver the notifications.
+func NewWatcher(store Store, prevTxns txhelpers.RawTransactionGetter, params *chaincfg.Params) (*Watcher, error) {
+	w := &Watcher{
+		params:     params,
+		store:      store,
+		prevTxns:   prevTxns,
+		client:     &http.Client{Timeout: requestTimeout},
+		queue:      make(chan *delivery, queueSize),
+		retryDelay: initialRetryDelay,
+		watches:    make(map[string][]*dbtypes.AddressWatch),
+	}
+
+	stored, err := store.AddressWatches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load address watches: %v", err)
+	}
+	for _, watch := range stored {
+		w.watches[watch.Address] = append(w.watches[watch.Address], watch)
+	}
+	return w, nil
+}
+
+// Register validates and stores a new registration of the callback URL for
+// notifications of the transactions involving the address. The returned
+// registration includes the secret that signs the notifications.
+func (w *Watcher) Register(address, callbackURL string) (*dbtypes.AddressWatch, error) {
+	if _, _, addrErr := txhelpers.AddressValidation(address, w.params); addrErr != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", address, addrErr)
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid callback URL %q", callbackURL)
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	watch := &dbtypes.AddressWatch{
+		Address:     address,
+		CallbackURL: u.String(),
+		Secret:      hex.EncodeToString(secret),
+		Created:     dbtypes.NewTimeDef(time.Now()),
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if len(w.watches[address]) >= maxWatchesPerAddress {
+		return nil, fmt.Errorf("maximum of %d watches per address", maxWatchesPerAddress)
+	}
+	if err = w.store.StoreAddressWatch(watch); err != nil {
+		log.Errorf("Failed to store address watch: %v", err)
+		return nil, fmt.Errorf("failed to store registration")
+	}
+	w.watches[address] = append(w.watches[address], watch)
+	return watch, nil
+}
+
+// Unregister deletes the registration with the given ID and secret.
+func (w *Watcher) Unregister(id int64, secret string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err := w.store.DeleteAddressWatch(id, secret); err != nil {
+		return err
+	}
+	for addr, watches := range w.watches {
+		for i, watch := range watches {
+			if watch.ID != id {
+				continue
+			}
+			watches = append(watches[:i], watches[i+1:]...)
+			if len(watches) == 0 {
+				delete(w.watches, addr)
+			} else {
+				w.watches[addr] = watches
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// watching checks if any addresses are watched.
+func (w *Watcher) watching() bool {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	return len(w.watches) > 0
+}
+
+// addressAmounts are the amounts received and sent by an address in a
+// transaction.
+type addressAmounts struct {
+	received, sent int64
+}
+
+// txAddresses determines the addresses involved in the transaction. The
+// addresses spent by the inputs are only determined if the previous output can
+// be retrieved.
+func (w *Watcher) txAddresses(msgTx *wire.MsgTx) map[string]*addressAmounts {
+	addrs := make(map[string]*addressAmounts)
+	amounts := func(addr string) *addressAmounts {
+		a := addrs[addr]
+		if a == nil {
+			a = new(addressAmounts)
+			addrs[addr] = a
+		}
+		return a
+	}
+
+	for i, txOut := range msgTx.TxOut {
+		script := txhelpers.DecodeOutputScript(msgTx, i, w.params)
+		for _, addr := range script.Addresses {
+			if script.CommitAmt == nil {
+				amounts(addr).received += txOut.Value
+			} else {
+				amounts(addr)
+			}
+		}
+	}
+
+	for _, txIn := range msgTx.TxIn {
+		if txhelpers.IsGeneratedInput(txIn) {
+			continue
+		}
+		prevAddrs, value, err := txhelpers.OutPointAddresses(&txIn.PreviousOutPoint,
+			w.prevTxns, w.params)
+		if err != nil {
+			log.Debugf("Unable to get addresses of previous outpoint %v: %v",
+				txIn.PreviousOutPoint, err)
+			continue
+		}
+		for _, addr := range prevAddrs {
+			amounts(addr).sent += int64(value)
+		}
+	}
+	return addrs
+}
+
+// notify queues the notifications of the transaction to the registrations of
+// the addresses it involves.
+func (w *Watcher) notify(msgTx *wire.MsgTx, status, blockHash string, blockHeight int64) {
+	addrs := w.txAddresses(msgTx)
+	txid := msgTx.TxHash().String()
+	now := time.Now().Unix()
+
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	for addr, amts := range addrs {
+		for _, watch := range w.watches[addr] {
+			body, err := json.Marshal(&Notification{
+				WatchID:     watch.ID,
+				Address:     addr,
+				TxID:        txid,
+				Status:      status,
+				BlockHash:   blockHash,
+				BlockHeight: blockHeight,
+				Received:    dcrutil.Amount(amts.received).ToCoin(),
+				Sent:        dcrutil.Amount(amts.sent).ToCoin(),
+				Time:        now,
+			})
+			if err != nil {
+				log.Errorf("Failed to encode notification: %v", err)
+				continue
+			}
+			w.enqueue(&delivery{
+				url:    watch.CallbackURL,
+				secret: watch.Secret,
+				body:   body,
+			})
+		}
+	}
+}
+
+// enqueue queues the delivery without blocking, dropping it if the queue is
+// full.
+func (w *Watcher) enqueue(d *delivery) {
+	select {
+	case w.queue <- d:
+	default:
+		log.Warnf("Notification queue full. Dropping notification to %s.", d.url)
+	}
+}
+
+// TxHandler notifies the registrations of the addresses involved in a new
+// mempool transaction. TxHandler satisfies notification.TxHandler.
+func (w *Watcher) TxHandler(rawTx *chainjson.TxRawResult) error {
+	if !w.watching() {
+		return nil
+	}
+	msgTx, err := txhelpers.MsgTxFromHex(rawTx.Hex)
+	if err != nil {
+		return fmt.Errorf("failed to decode transaction %s: %v", rawTx.Txid, err)
+	}
+	w.notify(msgTx, StatusMempool, "", 0)
+	return nil
+}
+
+// Store notifies the registrations of the addresses involved in the
+// transactions of a new block. Store satisfies blockdata.BlockDataSaver.
+func (w *Watcher) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	if !w.watching() {
+		return nil
+	}
+	blockHash := msgBlock.BlockHash().String()
+	blockHeight := int64(msgBlock.Header.Height)
+	for _, msgTx := range msgBlock.Transactions {
+		w.notify(msgTx, StatusConfirmed, blockHash, blockHeight)
+	}
+	for _, msgTx := range msgBlock.STransactions {
+		w.notify(msgTx, StatusConfirmed, blockHash, blockHeight)
+	}
+	return nil
+}
+
+// Sign computes the signature of a notification body with the registration's
+// secret, as sent in the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers the queued notifications until the context is canceled. A
+// failed delivery is retried after a delay that doubles with each attempt.
+func (w *Watcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.deliver(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver posts queued notifications until the context is canceled.
+func (w *Watcher) deliver(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-w.queue:
+			err := w.post(ctx, d)
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			d.attempts++
+			if d.attempts >= maxAttempts {
+				log.Warnf("Giving up on notification to %s after %d attempts: %v",
+					d.url, d.attempts, err)
+				continue
+			}
+			delay := w.retryDelay << uint(d.attempts-1)
+			log.Debugf("Notification to %s failed (%v). Retrying in %v.", d.url, err, delay)
+			time.AfterFunc(delay, func() { w.enqueue(d) })
+		}
+	}
+}
+
+// post sends the notification, which succeeds with any 2xx status code.
+func (w *Watcher) post(ctx context.Context, d *delivery) error {
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(d.secret, d.body))
+	resp, err := w.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020, The Decred-Next developers
+// See LICENSE for details.
+
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrdata/db/dbtypes/v2"
+)
+
+const testAddr = "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx"
+
+type memStore struct {
+	watches []*dbtypes.AddressWatch
+}
+
+func (s *memStore) StoreAddressWatch(watch *dbtypes.AddressWatch) error {
+	watch.ID = int64(len(s.watches) + 1)
+	s.watches = append(s.watches, watch)
+	return nil
+}
+
+func (s *memStore) AddressWatches() ([]*dbtypes.AddressWatch, error) {
+	return s.watches, nil
+}
+
+func (s *memStore) DeleteAddressWatch(id int64, secret string) error {
+	for i, watch := range s.watches {
+		if watch.ID == id && watch.Secret == secret {
+			s.watches = append(s.watches[:i], s.watches[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+type noTxns struct{}
+
+func (noTxns) GetRawTransaction(txHash *chainhash.Hash) (*dcrutil.Tx, error) {
+	return nil, fmt.Errorf("unknown transaction %v", txHash)
+}
+
+func TestRegister(t *testing.T) {
+	w, err := NewWatcher(&memStore{}, noTxns{}, chaincfg.MainNetParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, addr, url string
+		wantErr         bool
+	}{
+		{"ok", testAddr, "https://example.com/hook", false},
+		{"bad address", "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkz", "https://example.com/hook", true},
+		{"testnet address", "TsfDLrRkk9ciUuwfp2b8PawwnukYD7yAjGd", "https://example.com/hook", true},
+		{"bad scheme", testAddr, "ftp://example.com/hook", true},
+		{"no host", testAddr, "https:///hook", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watch, err := w.Register(tt.addr, tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (watch.ID == 0 || len(watch.Secret) != 64) {
+				t.Errorf("Register() = %+v, expected ID and secret", watch)
+			}
+		})
+	}
+
+	if err = w.Unregister(1, "wrong"); err != sql.ErrNoRows {
+		t.Errorf("Unregister with the wrong secret: %v", err)
+	}
+	if err = w.Unregister(1, w.watches[testAddr][0].Secret); err != nil {
+		t.Fatalf("Unregister: %v", err)
+	}
+	if w.watching() {
+		t.Errorf("address still watched after Unregister")
+	}
+}
+
+func TestStoreNotifies(t *testing.T) {
+	var requests int32
+	notes := make(chan *Notification, 1)
+	var secret string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to exercise the retry.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			http.Error(rw, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if sig := r.Header.Get(SignatureHeader); sig != Sign(secret, body) {
+			t.Errorf("bad signature %q", sig)
+		}
+		var note Notification
+		if err := json.Unmarshal(body, &note); err != nil {
+			t.Errorf("bad notification: %v", err)
+		}
+		notes <- &note
+	}))
+	defer srv.Close()
+
+	params := chaincfg.MainNetParams()
+	w, err := NewWatcher(&memStore{}, noTxns{}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.retryDelay = 10 * time.Millisecond
+	watch, err := w.Register(testAddr, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret = watch.Secret
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	addr, err := dcrutil.DecodeAddress(testAddr, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgTx := wire.NewMsgTx()
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0,
+		wire.TxTreeRegular), 2e8, nil))
+	msgTx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+	msgBlock := &wire.MsgBlock{
+		Header:       wire.BlockHeader{Height: 12345},
+		Transactions: []*wire.MsgTx{msgTx},
+	}
+	if err = w.Store(nil, msgBlock); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case note := <-notes:
+		if note.WatchID != watch.ID || note.Address != testAddr ||
+			note.TxID != msgTx.TxHash().String() || note.Status != StatusConfirmed ||
+			note.BlockHeight != 12345 || note.Received != 1 || note.Sent != 0 {
+			t.Errorf("unexpected notification %+v", note)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not delivered")
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests, expected 2", n)
+	}
+}